@@ -13,10 +13,12 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	_ "github.com/ClickHouse/clickhouse-go/v2"
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/mikrocloud/mikrocloud/assets"
 	"github.com/mikrocloud/mikrocloud/internal/config"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
 	"github.com/mikrocloud/mikrocloud/internal/server"
 )
 
@@ -167,6 +169,23 @@ func migrateMainDatabase(cfg *config.Config) error {
 }
 
 func migrateAnalyticsDatabase(cfg *config.Config) error {
+	if cfg.Analytics.Type == "clickhouse" {
+		db, err := sql.Open("clickhouse", cfg.Analytics.ConnectionString())
+		if err != nil {
+			return fmt.Errorf("failed to open analytics database: %w", err)
+		}
+		defer db.Close()
+
+		goose.SetDialect("clickhouse")
+
+		if err := goose.Up(db, "./migrations/clickhouse"); err != nil {
+			return fmt.Errorf("failed to run analytics database migrations: %w", err)
+		}
+
+		slog.Info("Analytics database migrations completed successfully", "database", "clickhouse")
+		return nil
+	}
+
 	// Ensure database directory exists
 	dbDir := filepath.Dir(cfg.Analytics.URL)
 
@@ -174,12 +193,11 @@ func migrateAnalyticsDatabase(cfg *config.Config) error {
 		return fmt.Errorf("failed to create analytics database directory: %w", err)
 	}
 
-	if cfg.Analytics.Type == "duckdb" {
-		slog.Info("Analytics database schema managed by application code", "database", cfg.Analytics.URL)
-		return nil
+	if err := analyticsdb.Migrate(analyticsdb.DatabaseType(cfg.Analytics.Type), cfg.Analytics.ConnectionString(), "./migrations/analytics"); err != nil {
+		return fmt.Errorf("failed to run analytics database migrations: %w", err)
 	}
 
-	// TODO: For ClickHouse analytics, use goose migrations
+	slog.Info("Analytics database migrations completed successfully", "database", cfg.Analytics.URL)
 	return nil
 }
 
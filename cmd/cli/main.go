@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -112,6 +113,11 @@ func startMikrocloud() error {
 	}
 
 	// Pull Postgres or Clickhouse if needed by the config
+	if cfg.Analytics.Type == "clickhouse" && cfg.Analytics.Container.AutoStart {
+		if err := startAnalytics(runtime, cfg); err != nil {
+			return fmt.Errorf("failed to start analytics database: %w", err)
+		}
+	}
 
 	if !isContainerExists(runtime, containerName) {
 		slog.Info("Pulling Mikrocloud container image...", "image", containerImage)
@@ -191,6 +197,7 @@ func stopMikrocloud() error {
 	stopContainer(runtime, containerName)
 	stopContainer(runtime, tunnelName)
 	stopContainer(runtime, metricsName)
+	stopContainer(runtime, analyticsName)
 	stopContainer(runtime, proxyName)
 	stopContainer(runtime, queueName)
 
@@ -363,11 +370,74 @@ func startProxy(runtime string, cfg *config.Config) error {
 	return waitForHealthy(runtime, proxyName, 30)
 }
 
+func startAnalytics(runtime string, cfg *config.Config) error {
+	if isContainerRunning(runtime, analyticsName) {
+		fmt.Println("✅ Analytics database is already running")
+		return nil
+	}
+
+	container := cfg.Analytics.Container
+
+	if !isContainerExists(runtime, analyticsName) {
+		fmt.Printf("⬇️  Pulling %s...\n", container.Image)
+		pullCmd := exec.Command(runtime, "pull", container.Image)
+		pullCmd.Stdout = os.Stdout
+		pullCmd.Stderr = os.Stderr
+		if err := pullCmd.Run(); err != nil {
+			return fmt.Errorf("failed to pull analytics database: %w", err)
+		}
+	}
+
+	dataDir := filepath.Join(cfg.Server.DataDir, "analytics")
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create analytics data directory: %w", err)
+	}
+
+	fmt.Printf("🚀 Starting analytics database container...\n")
+	runCmd := exec.Command(runtime, "run",
+		"-d",
+		"--name", analyticsName,
+		"--network", networkName,
+		"--restart", "unless-stopped",
+		"--ulimit", "nofile=262144:262144",
+		"-p", fmt.Sprintf("127.0.0.1:%d:8123", container.HTTPPort),
+		"-p", fmt.Sprintf("127.0.0.1:%d:9000", container.NativePort),
+		"-e", "CLICKHOUSE_DB="+container.Database,
+		"-e", "CLICKHOUSE_USER="+container.User,
+		"-e", "CLICKHOUSE_PASSWORD="+container.Password,
+		"-v", fmt.Sprintf("%s:/var/lib/clickhouse", dataDir),
+		container.Image,
+	)
+
+	output, err := runCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to start analytics database: %w\n%s", err, string(output))
+	}
+
+	return waitForHealthy(runtime, analyticsName, 30)
+}
+
 func startMetrics(runtime string, cfg *config.Config) error {
 	if isContainerRunning(runtime, metricsName) {
 		return nil
 	}
 
+	// Prometheus scrapes the mikrocloud container over the shared network
+	scrapeConfig, err := cfg.Metrics.PrometheusScrapeConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	metricsDir := filepath.Join(cfg.Server.DataDir, "metrics")
+	if err := os.MkdirAll(metricsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	scrapeConfigPath := filepath.Join(metricsDir, "prometheus.yml")
+	if err := os.WriteFile(scrapeConfigPath, scrapeConfig, 0o644); err != nil {
+		return fmt.Errorf("failed to write scrape config: %w", err)
+	}
+
 	fmt.Printf("⬇️  Pulling %s...\n", cfg.Metrics.Image)
 	pullCmd := exec.Command(runtime, "pull", cfg.Metrics.Image)
 	pullCmd.Stdout = os.Stdout
@@ -383,10 +453,11 @@ func startMetrics(runtime string, cfg *config.Config) error {
 		"--network", networkName,
 		"--restart", "unless-stopped",
 		"-p", fmt.Sprintf("%d:9090", cfg.Metrics.Port),
+		"-v", fmt.Sprintf("%s:/etc/prometheus/prometheus.yml:ro", scrapeConfigPath),
 		cfg.Metrics.Image,
 	)
 
-	_, err := runCmd.CombinedOutput()
+	_, err = runCmd.CombinedOutput()
 	return err
 }
 
@@ -487,6 +558,7 @@ const (
 	queueName      = "mikrocloud-queue"
 	proxyName      = "mikrocloud-proxy"
 	metricsName    = "mikrocloud-metrics"
+	analyticsName  = config.AnalyticsContainerName
 	tunnelName     = "mikrocloud-cloudflared"
 )
 
@@ -9,21 +9,28 @@ import (
 	"github.com/mikrocloud/mikrocloud/internal/config"
 	"github.com/mikrocloud/mikrocloud/internal/database"
 	activitiesService "github.com/mikrocloud/mikrocloud/internal/domain/activities/service"
+	alertsService "github.com/mikrocloud/mikrocloud/internal/domain/alerts/service"
+	analyticsService "github.com/mikrocloud/mikrocloud/internal/domain/analytics/service"
 	applicationsService "github.com/mikrocloud/mikrocloud/internal/domain/applications/service"
 	authService "github.com/mikrocloud/mikrocloud/internal/domain/auth/service"
+	backupService "github.com/mikrocloud/mikrocloud/internal/domain/backups/service"
 	databaseService "github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
 	deploymentService "github.com/mikrocloud/mikrocloud/internal/domain/deployments/service"
 	diskService "github.com/mikrocloud/mikrocloud/internal/domain/disks/service"
 	environmentService "github.com/mikrocloud/mikrocloud/internal/domain/environments/service"
 	gitService "github.com/mikrocloud/mikrocloud/internal/domain/git/service"
+	logsService "github.com/mikrocloud/mikrocloud/internal/domain/logs/service"
 	organizationsService "github.com/mikrocloud/mikrocloud/internal/domain/organizations/service"
 	projectService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
 	proxyService "github.com/mikrocloud/mikrocloud/internal/domain/proxy/service"
+	queriesService "github.com/mikrocloud/mikrocloud/internal/domain/queries/service"
 	serversService "github.com/mikrocloud/mikrocloud/internal/domain/servers/service"
 	"github.com/mikrocloud/mikrocloud/internal/domain/services/repository"
 	templatesService "github.com/mikrocloud/mikrocloud/internal/domain/services/service"
 	settingsService "github.com/mikrocloud/mikrocloud/internal/domain/settings/service"
+	telemetryService "github.com/mikrocloud/mikrocloud/internal/domain/telemetry/service"
 	tunnelService "github.com/mikrocloud/mikrocloud/internal/domain/tunnels/service"
+	uptimeService "github.com/mikrocloud/mikrocloud/internal/domain/uptime/service"
 	buildService "github.com/mikrocloud/mikrocloud/pkg/containers/build"
 	containerService "github.com/mikrocloud/mikrocloud/pkg/containers/service"
 
@@ -44,14 +51,18 @@ type Dependencies struct {
 	ActivitiesService   *activitiesService.ActivitiesService
 	ProjectService      *projectService.ProjectService
 	DatabaseService     *databaseService.DatabaseService
+	BackupService       *backupService.BackupService
+	QueryService        *queriesService.QueryService
 	OrganizationService *organizationsService.OrganizationService
 	ServerService       *serversService.ServersService
 	ApplicationService  *applicationsService.ApplicationService
 	DeploymentService   *deploymentService.DeploymentService
+	InsightsService     *deploymentService.InsightsService
 	EnvironmentService  *environmentService.EnvironmentService
 	GitService          *gitService.GitService
 	ProxyService        *proxyService.ProxyService
 	TraefikService      *proxyContainers.TraefikService
+	ErrorPagesService   *proxyContainers.ErrorPagesService
 
 	BuildService    *buildService.BuildService
 	DiskService     *diskService.DiskService
@@ -59,8 +70,24 @@ type Dependencies struct {
 	SettingsService *settingsService.SettingsService
 	TunnelService   *tunnelService.TunnelService
 
+	AnalyticsService *analyticsService.AnalyticsService
+	LogService       *logsService.LogService
+	AlertService     *alertsService.AlertService
+	TelemetryService *telemetryService.TelemetryService
+	UptimeService    *uptimeService.UptimeService
+	DashboardService *analyticsService.DashboardService
+	ReportService    *analyticsService.ReportService
+
 	// Sync services
 	DatabaseStatusSyncService *databaseService.StatusSyncService
+	AccessLogCollector        *analyticsService.AccessLogCollector
+	ContainerLogCollector     *logsService.ContainerLogCollector
+	MetricSampler             *analyticsService.MetricSampler
+	AlertEvaluator            *alertsService.AlertEvaluator
+	BackupScheduler           *backupService.BackupScheduler
+	SpanPruner                *telemetryService.SpanPruner
+	UptimeChecker             *uptimeService.UptimeChecker
+	ReportScheduler           *analyticsService.ReportScheduler
 }
 
 func NewDependencies(cfg *config.Config, db *database.Database) (*Dependencies, error) {
@@ -85,24 +112,71 @@ func NewDependencies(cfg *config.Config, db *database.Database) (*Dependencies,
 	gitSvc := gitService.NewGitService(db.GitRepository)
 	settingsSvc := settingsService.NewSettingsService(db.SettingsRepository)
 
-	proxySvc := proxyService.New(db.ProxyRepository, db.TraefikConfigRepository)
+	proxySvc := proxyService.New(db.ProxyRepository, db.TraefikConfigRepository, db.MiddlewareRepository, db.PageSettingsRepository, db.RedirectRuleRepository)
 	traefikConfigDir := filepath.Join(cfg.Server.DataDir, "traefik")
 	traefikSvc := proxyContainers.NewTraefikService(*containerService, traefikConfigDir, cfg.Docker.NetworkMode)
+	errorPagesSvc := proxyContainers.NewErrorPagesService(*containerService, filepath.Join(cfg.Server.DataDir, "errorpages"), cfg.Docker.NetworkMode)
+
+	analyticsSvc := analyticsService.NewAnalyticsService(db.MetricRepository, db.RequestRepository)
+	logSvc := logsService.NewLogService(db.LogRepository)
+	telemetrySvc := telemetryService.NewTelemetryService(db.IngestTokenRepository, db.SpanRepository, analyticsSvc, logSvc, cfg.Telemetry.Enabled, cfg.OTLPEndpoint(), cfg.Auth.JWTSecret)
+	spanPruner := telemetryService.NewSpanPruner(telemetrySvc, time.Hour)
 
-	deploymentSvc := deploymentService.NewDeploymentService(db.DeploymentRepository, containerService)
+	deploymentSvc := deploymentService.NewDeploymentService(db.DeploymentRepository, containerService, proxySvc, telemetrySvc)
 	diskSvc := diskService.NewDiskService(db.DiskRepository, db.DiskBackupRepository)
-	dbDeploymentSvc := databaseContainers.NewDatabaseDeploymentService(containerService, diskSvc)
+	walArchiveDir := filepath.Join(cfg.Server.DataDir, "wal-archive")
+	dbDeploymentSvc := databaseContainers.NewDatabaseDeploymentService(containerService, diskSvc, walArchiveDir)
 
 	appSvc := applicationsService.NewApplicationService(db.ApplicationRepository, domainGenerator, deploymentSvc)
-	databaseSvc := databaseService.NewDatabaseService(db.DatabaseRepository, dbDeploymentSvc, diskSvc)
+	insightsSvc := deploymentService.NewInsightsService(db.DeploymentRepository, appSvc)
+	databaseSvc := databaseService.NewDatabaseService(
+		db.DatabaseRepository,
+		dbDeploymentSvc,
+		diskSvc,
+		databaseService.NewApplicationDependents(appSvc, deploymentSvc, containerService),
+		activitiesService,
+		filepath.Join(cfg.Server.DataDir, "restore-staging"),
+		filepath.Join(cfg.Server.DataDir, "upgrade-snapshots"),
+	)
 	quickDeployService := repository.NewQuickDeployService(db.TemplateRepository, appSvc)
 	templateSvc := templatesService.NewTemplateService(db.TemplateRepository, quickDeployService)
 
 	dbStatusSyncSvc := databaseService.NewStatusSyncService(databaseSvc, containerService, 29*time.Second)
 
+	backupSvc := backupService.NewBackupService(
+		db.BackupScheduleRepository,
+		db.BackupRunRepository,
+		db.WALArchiveRepository,
+		db.WALSegmentRepository,
+		databaseSvc,
+		dbDeploymentSvc,
+		filepath.Join(cfg.Server.DataDir, "backups"),
+		filepath.Join(cfg.Server.DataDir, "backup-staging"),
+		walArchiveDir,
+	)
+	backupScheduler := backupService.NewBackupScheduler(backupSvc, 30*time.Second)
+
+	querySvc := queriesService.NewQueryService(db.QueryHistoryRepository, db.SavedQueryRepository)
+
+	accessLogCollector := analyticsService.NewAccessLogCollector(traefikSvc, appSvc, analyticsSvc)
+	metricSampler := analyticsService.NewMetricSampler(containerService, deploymentSvc, appSvc, databaseSvc, analyticsSvc, 30*time.Second)
+
+	containerLogCollector := logsService.NewContainerLogCollector(logSvc, containerService, deploymentSvc, appSvc, databaseSvc, projService)
+
 	cloudflaredMgr := tunnelContainers.NewCloudflaredManager(containerService.GetManager())
 	tunnelSvc := tunnelService.NewTunnelService(db.TunnelRepository, cloudflaredMgr)
 
+	notifier := alertsService.NewNotifier(settingsSvc)
+	alertSvc := alertsService.NewAlertService(db.AlertRuleRepository, db.ChannelRepository, db.AlertRepository, notifier)
+	alertEvaluator := alertsService.NewAlertEvaluator(alertSvc, appSvc, deploymentSvc, databaseSvc, containerService, analyticsSvc, tunnelSvc, 30*time.Second)
+
+	uptimeSvc := uptimeService.NewUptimeService(db.MonitorRepository, db.IncidentRepository, db.StatusPageRepository, appSvc, projService, cfg.ControlPlaneUpstream())
+	uptimeChecker := uptimeService.NewUptimeChecker(uptimeSvc, appSvc, analyticsSvc, 10*time.Second)
+
+	dashboardSvc := analyticsService.NewDashboardService(db.DashboardRepository, analyticsSvc)
+	reportSvc := analyticsService.NewReportService(db.ReportRepository, db.ReportRunRepository, analyticsSvc, projService, appSvc, databaseSvc, deploymentSvc, notifier)
+	reportScheduler := analyticsService.NewReportScheduler(reportSvc, time.Minute)
+
 	return &Dependencies{
 		DB:                  db,
 		Config:              cfg,
@@ -111,20 +185,39 @@ func NewDependencies(cfg *config.Config, db *database.Database) (*Dependencies,
 		ActivitiesService:   activitiesService,
 		ProjectService:      projService,
 		DatabaseService:     databaseSvc,
+		BackupService:       backupSvc,
+		QueryService:        querySvc,
 		OrganizationService: organizationsSvc,
 		ServerService:       serversSvc,
 		ApplicationService:  appSvc,
 		DeploymentService:   deploymentSvc,
+		InsightsService:     insightsSvc,
 		EnvironmentService:  envService,
 		GitService:          gitSvc,
 		ProxyService:        proxySvc,
 		TraefikService:      traefikSvc,
+		ErrorPagesService:   errorPagesSvc,
 		DiskService:         diskSvc,
 		TemplateService:     templateSvc,
 		SettingsService:     settingsSvc,
 		TunnelService:       tunnelSvc,
+		AnalyticsService:    analyticsSvc,
+		LogService:          logSvc,
+		AlertService:        alertSvc,
+		TelemetryService:    telemetrySvc,
+		UptimeService:       uptimeSvc,
+		DashboardService:    dashboardSvc,
+		ReportService:       reportSvc,
 
 		DatabaseStatusSyncService: dbStatusSyncSvc,
+		AccessLogCollector:        accessLogCollector,
+		ContainerLogCollector:     containerLogCollector,
+		MetricSampler:             metricSampler,
+		AlertEvaluator:            alertEvaluator,
+		BackupScheduler:           backupScheduler,
+		SpanPruner:                spanPruner,
+		UptimeChecker:             uptimeChecker,
+		ReportScheduler:           reportScheduler,
 		JwtKeys:                   tokenAuthSecret,
 	}, nil
 }
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type timeoutCtxKey struct{}
+
+// requestTimeout lets NoTimeout lift the deadline Timeout put on a request
+type requestTimeout struct {
+	parent context.Context
+	lifted bool
+}
+
+// Timeout cancels the context of a request after timeout and answers
+// 504 Gateway Timeout when the deadline was hit, like chi's Timeout.
+// Routes mounted with NoTimeout are exempt.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			state := &requestTimeout{parent: r.Context()}
+			ctx = context.WithValue(ctx, timeoutCtxKey{}, state)
+
+			defer func() {
+				if !state.lifted && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					w.WriteHeader(http.StatusGatewayTimeout)
+				}
+			}()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// NoTimeout exempts a route from Timeout, for live streams, exports and
+// uploads that run longer than other requests. Its context is still
+// cancelled when the client goes away.
+func NoTimeout() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state, ok := r.Context().Value(timeoutCtxKey{}).(*requestTimeout)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			state.lifted = true
+			ctx := untimedContext{Context: r.Context(), parent: state.parent}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// untimedContext keeps the values of a request context, which middlewares
+// added after Timeout, but is only cancelled along with the request itself
+type untimedContext struct {
+	context.Context
+	parent context.Context
+}
+
+func (c untimedContext) Deadline() (time.Time, bool) {
+	return c.parent.Deadline()
+}
+
+func (c untimedContext) Done() <-chan struct{} {
+	return c.parent.Done()
+}
+
+func (c untimedContext) Err() error {
+	return c.parent.Err()
+}
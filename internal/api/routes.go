@@ -16,7 +16,9 @@ import (
 	serversHandler "github.com/mikrocloud/mikrocloud/internal/domain/servers/handlers"
 	templatesHandler "github.com/mikrocloud/mikrocloud/internal/domain/services/handlers"
 	settingsHandler "github.com/mikrocloud/mikrocloud/internal/domain/settings/handlers"
+	telemetryHandler "github.com/mikrocloud/mikrocloud/internal/domain/telemetry/handlers"
 	tunnelsHandler "github.com/mikrocloud/mikrocloud/internal/domain/tunnels/handlers"
+	uptimeHandler "github.com/mikrocloud/mikrocloud/internal/domain/uptime/handlers"
 )
 
 func SetupRoutes(api chi.Router, dependencies *deps.Dependencies) {
@@ -31,8 +33,10 @@ func SetupRoutes(api chi.Router, dependencies *deps.Dependencies) {
 	projectsHandler.RegisterProjectRoutes(api, dependencies)
 	serversHandler.RegisterServersRoutes(api, dependencies)
 	settingsHandler.RegisterSettingsRoutes(api, dependencies)
+	telemetryHandler.RegisterOTLPRoutes(api, dependencies)
 	templatesHandler.RegisterTemplatesRoutes(api, dependencies)
 	tunnelsHandler.RegisterTunnelRoutes(api, dependencies)
+	uptimeHandler.RegisterPublicStatusPageRoutes(api, dependencies)
 
 	// Serve storage files (public access)
 	storageDir := "./storage"
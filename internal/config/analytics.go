@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AnalyticsContainerName is the name of the ClickHouse container started by the
+// CLI, the mikrocloud container reaches it under that name on the network
+const AnalyticsContainerName = "mikrocloud-analytics"
+
+// clickHouseNativePort is the port ClickHouse listens on inside its container,
+// Container.NativePort is only where it is published on the host
+const clickHouseNativePort = 9000
+
+// clickHouseSchemes are the URL schemes understood by the ClickHouse driver
+var clickHouseSchemes = []string{"clickhouse://", "tcp://", "http://", "https://"}
+
+// ConnectionString returns the connection string of the analytics database.
+// When ClickHouse is selected but url still holds a file path, as in the
+// default config, it points at the auto-started container instead.
+func (c AnalyticsConfig) ConnectionString() string {
+	if c.Type != "clickhouse" {
+		return c.URL
+	}
+
+	for _, scheme := range clickHouseSchemes {
+		if strings.HasPrefix(c.URL, scheme) {
+			return c.URL
+		}
+	}
+
+	dsn := url.URL{
+		Scheme: "clickhouse",
+		User:   url.UserPassword(c.Container.User, c.Container.Password),
+		Host:   fmt.Sprintf("%s:%d", AnalyticsContainerName, clickHouseNativePort),
+		Path:   "/" + c.Container.Database,
+	}
+
+	return dsn.String()
+}
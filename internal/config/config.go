@@ -19,6 +19,7 @@ type Config struct {
 	Proxy     ProxyConfig     `mapstructure:"proxy"`
 	Metrics   MetricsConfig   `mapstructure:"metrics"`
 	Tunnel    TunnelConfig    `mapstructure:"tunnel"`
+	Telemetry TelemetryConfig `mapstructure:"telemetry"`
 }
 
 type ServerConfig struct {
@@ -69,6 +70,10 @@ type ProxyConfig struct {
 	HTTPPort      int    `mapstructure:"http_port"`
 	HTTPSPort     int    `mapstructure:"https_port"`
 	DashboardPort int    `mapstructure:"dashboard_port"`
+	// ControlPlaneURL is where Traefik reaches the mikrocloud API to serve
+	// status pages on their custom domain. It defaults to the mikrocloud
+	// container on the mikrocloud network, or localhost in host network mode.
+	ControlPlaneURL string `mapstructure:"control_plane_url"`
 }
 
 type MetricsConfig struct {
@@ -76,6 +81,11 @@ type MetricsConfig struct {
 	AutoStart bool   `mapstructure:"auto_start"`
 	Image     string `mapstructure:"image"`
 	Port      int    `mapstructure:"port"`
+	// ExporterPort serves the Prometheus /metrics endpoint of mikrocloud. It is
+	// unauthenticated and meant to be reached by the metrics container over the
+	// mikrocloud network only, never published on the host.
+	ExporterPort   int    `mapstructure:"exporter_port"`
+	ScrapeInterval string `mapstructure:"scrape_interval"`
 }
 
 type TunnelConfig struct {
@@ -84,6 +94,15 @@ type TunnelConfig struct {
 	Token     string `mapstructure:"token"`
 }
 
+// TelemetryConfig configures the OTLP receiver applications export their
+// traces, metrics and logs to
+type TelemetryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the base URL injected as OTEL_EXPORTER_OTLP_ENDPOINT, it
+	// defaults to the OTLP routes under the public URL
+	Endpoint string `mapstructure:"endpoint"`
+}
+
 type DockerConfig struct {
 	Runtime     string `mapstructure:"runtime"` // "docker" or "podman"
 	SocketPath  string `mapstructure:"socket_path"`
@@ -211,18 +230,25 @@ func setDefaults() {
 	viper.SetDefault("proxy.http_port", 80)
 	viper.SetDefault("proxy.https_port", 443)
 	viper.SetDefault("proxy.dashboard_port", 8080)
+	viper.SetDefault("proxy.control_plane_url", "")
 
 	// Metrics defaults
 	viper.SetDefault("metrics.enabled", false)
 	viper.SetDefault("metrics.auto_start", false)
 	viper.SetDefault("metrics.image", "prom/prometheus:latest")
 	viper.SetDefault("metrics.port", 9090)
+	viper.SetDefault("metrics.exporter_port", 9091)
+	viper.SetDefault("metrics.scrape_interval", "15s")
 
 	// Tunnel defaults
 	viper.SetDefault("tunnel.enabled", false)
 	viper.SetDefault("tunnel.auto_start", false)
 	viper.SetDefault("tunnel.token", "")
 
+	// Telemetry defaults
+	viper.SetDefault("telemetry.enabled", true)
+	viper.SetDefault("telemetry.endpoint", "")
+
 	// Docker defaults
 	viper.SetDefault("docker.runtime", "docker")
 	viper.SetDefault("docker.socket_path", "/var/run/docker.sock")
@@ -279,6 +305,26 @@ func expandEnvVars(path string) string {
 	return path
 }
 
+// OTLPEndpoint returns the OTLP/HTTP base URL application containers export
+// to, SDKs append the /v1/traces, /v1/metrics and /v1/logs paths
+func (c *Config) OTLPEndpoint() string {
+	if c.Telemetry.Endpoint != "" {
+		return strings.TrimSuffix(c.Telemetry.Endpoint, "/")
+	}
+	return c.GetPublicURL() + "/api/otlp"
+}
+
+// ControlPlaneUpstream returns the URL Traefik proxies to the mikrocloud API at
+func (c *Config) ControlPlaneUpstream() string {
+	if c.Proxy.ControlPlaneURL != "" {
+		return strings.TrimSuffix(c.Proxy.ControlPlaneURL, "/")
+	}
+	if c.Docker.NetworkMode == "host" {
+		return fmt.Sprintf("http://127.0.0.1:%d", c.Server.Port)
+	}
+	return fmt.Sprintf("http://mikrocloud:%d", c.Server.Port)
+}
+
 func (c *Config) GetPublicURL() string {
 	if c.Server.PublicURL != "" {
 		return strings.TrimSuffix(c.Server.PublicURL, "/")
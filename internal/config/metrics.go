@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+type prometheusConfig struct {
+	Global        prometheusGlobal         `yaml:"global"`
+	ScrapeConfigs []prometheusScrapeConfig `yaml:"scrape_configs"`
+}
+
+type prometheusGlobal struct {
+	ScrapeInterval     string `yaml:"scrape_interval"`
+	EvaluationInterval string `yaml:"evaluation_interval"`
+}
+
+type prometheusScrapeConfig struct {
+	JobName       string                   `yaml:"job_name"`
+	MetricsPath   string                   `yaml:"metrics_path,omitempty"`
+	StaticConfigs []prometheusStaticConfig `yaml:"static_configs"`
+}
+
+type prometheusStaticConfig struct {
+	Targets []string `yaml:"targets"`
+}
+
+// PrometheusScrapeConfig renders the prometheus.yml of the metrics container.
+// host is where the metrics container reaches mikrocloud, its container name
+// on the mikrocloud network.
+func (c MetricsConfig) PrometheusScrapeConfig(host string) ([]byte, error) {
+	interval := c.ScrapeInterval
+	if interval == "" {
+		interval = "15s"
+	}
+
+	cfg := prometheusConfig{
+		Global: prometheusGlobal{
+			ScrapeInterval:     interval,
+			EvaluationInterval: interval,
+		},
+		ScrapeConfigs: []prometheusScrapeConfig{
+			{
+				JobName:     "mikrocloud",
+				MetricsPath: "/metrics",
+				StaticConfigs: []prometheusStaticConfig{
+					{Targets: []string{fmt.Sprintf("%s:%d", host, c.ExporterPort)}},
+				},
+			},
+			{
+				JobName: "prometheus",
+				StaticConfigs: []prometheusStaticConfig{
+					{Targets: []string{"localhost:9090"}},
+				},
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render scrape config: %w", err)
+	}
+
+	return append([]byte("# Generated by mikrocloud, changes are overwritten on start\n"), data...), nil
+}
@@ -0,0 +1,406 @@
+package analytics_db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"golang.org/x/exp/slog"
+)
+
+// ClickHouseAnalyticsDatabase implements AnalyticsDatabase interface for
+// ClickHouse. The schema is owned by the migrations in migrations/clickhouse
+// rather than created on connect.
+type ClickHouseAnalyticsDatabase struct {
+	db *sql.DB
+}
+
+// NewClickHouseDatabase creates a new ClickHouse analytics database instance
+func NewClickHouseDatabase(connectionString string) (AnalyticsDatabase, error) {
+	db, err := sql.Open("clickhouse", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	slog.Info("Analytics database connection established (ClickHouse)")
+
+	return &ClickHouseAnalyticsDatabase{db: db}, nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) Close() error {
+	if err := c.db.Close(); err != nil {
+		slog.Error("Error closing analytics database", "error", err)
+		return err
+	}
+	slog.Info("Analytics database connection closed")
+	return nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// DB returns the underlying database connection
+func (c *ClickHouseAnalyticsDatabase) DB() interface{} {
+	return c.db
+}
+
+func (c *ClickHouseAnalyticsDatabase) StoreMetric(ctx context.Context, metric Metric) error {
+	tagsJSON, err := json.Marshal(metric.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := `INSERT INTO metrics (id, name, value, tags, timestamp) VALUES (?, ?, ?, ?, ?)`
+	_, err = c.db.ExecContext(ctx, query, metric.ID, metric.Name, metric.Value, string(tagsJSON), metric.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to store metric: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) StoreEvent(ctx context.Context, event Event) error {
+	dataJSON, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	tagsJSON, err := json.Marshal(event.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := `INSERT INTO events (id, type, source, data, tags, timestamp) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = c.db.ExecContext(ctx, query, event.ID, event.Type, event.Source, string(dataJSON), string(tagsJSON), event.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to store event: %w", err)
+	}
+
+	return nil
+}
+
+// StoreLogs sends the entries as a single native insert block, ClickHouse
+// prefers few large inserts over many small ones
+func (c *ClickHouseAnalyticsDatabase) StoreLogs(ctx context.Context, logs []LogEntry) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO logs (id, level, message, source, fields, tags, timestamp)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, log := range logs {
+		fieldsJSON, err := json.Marshal(log.Fields)
+		if err != nil {
+			return fmt.Errorf("failed to marshal fields for log %s: %w", log.ID, err)
+		}
+
+		tagsJSON, err := json.Marshal(log.Tags)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tags for log %s: %w", log.ID, err)
+		}
+
+		if _, err := stmt.ExecContext(ctx, log.ID, log.Level, log.Message, log.Source, string(fieldsJSON), string(tagsJSON), log.Timestamp); err != nil {
+			return fmt.Errorf("failed to append log %s: %w", log.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store logs: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) GetMetrics(ctx context.Context, filter MetricFilter) ([]Metric, error) {
+	conditions := []string{"timestamp >= ?", "timestamp <= ?"}
+	args := []any{filter.TimeRange.Start, filter.TimeRange.End}
+
+	if len(filter.Names) > 0 {
+		conditions = append(conditions, "has(?, name)")
+		args = append(args, filter.Names)
+	}
+
+	conditions, args = appendTagConditions(conditions, args, filter.Tags)
+
+	query := `SELECT id, name, value, tags, timestamp FROM metrics WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []Metric
+	for rows.Next() {
+		var metric Metric
+		var tagsJSON string
+
+		if err := rows.Scan(&metric.ID, &metric.Name, &metric.Value, &tagsJSON, &metric.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan metric: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(tagsJSON), &metric.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return metrics, nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) GetEvents(ctx context.Context, filter EventFilter) ([]Event, error) {
+	conditions := []string{"timestamp >= ?", "timestamp <= ?"}
+	args := []any{filter.TimeRange.Start, filter.TimeRange.End}
+
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, "has(?, type)")
+		args = append(args, filter.Types)
+	}
+
+	if len(filter.Sources) > 0 {
+		conditions = append(conditions, "has(?, source)")
+		args = append(args, filter.Sources)
+	}
+
+	conditions, args = appendTagConditions(conditions, args, filter.Tags)
+
+	query := `SELECT id, type, source, data, tags, timestamp FROM events WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var dataJSON, tagsJSON string
+
+		if err := rows.Scan(&event.ID, &event.Type, &event.Source, &dataJSON, &tagsJSON, &event.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(dataJSON), &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(tagsJSON), &event.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) GetLogs(ctx context.Context, filter LogFilter) ([]LogEntry, error) {
+	conditions := []string{"timestamp >= ?", "timestamp <= ?"}
+	args := []any{filter.TimeRange.Start, filter.TimeRange.End}
+
+	if len(filter.Levels) > 0 {
+		conditions = append(conditions, "has(?, level)")
+		args = append(args, filter.Levels)
+	}
+
+	if len(filter.Sources) > 0 {
+		conditions = append(conditions, "has(?, source)")
+		args = append(args, filter.Sources)
+	}
+
+	conditions, args = appendTagConditions(conditions, args, filter.Tags)
+
+	query := `SELECT id, level, message, source, fields, tags, timestamp FROM logs WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []LogEntry
+	for rows.Next() {
+		var log LogEntry
+		var fieldsJSON, tagsJSON string
+
+		if err := rows.Scan(&log.ID, &log.Level, &log.Message, &log.Source, &fieldsJSON, &tagsJSON, &log.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan log: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(fieldsJSON), &log.Fields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fields: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(tagsJSON), &log.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return logs, nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) GetMetricAggregation(ctx context.Context, aggregation MetricAggregation) ([]AggregationResult, error) {
+	var function string
+	switch aggregation.Function {
+	case Sum, Avg, Min, Max, Count:
+		function = string(aggregation.Function)
+	default:
+		return nil, fmt.Errorf("unsupported aggregation function: %s", aggregation.Function)
+	}
+
+	conditions := []string{"name = ?", "timestamp >= ?", "timestamp <= ?"}
+	args := []any{aggregation.MetricName, aggregation.TimeRange.Start, aggregation.TimeRange.End}
+	conditions, args = appendTagConditions(conditions, args, aggregation.Tags)
+
+	// Tags live in a JSON column, group keys are extracted from it
+	selectColumns := make([]string, 0, len(aggregation.GroupBy)+1)
+	groupBy := make([]string, 0, len(aggregation.GroupBy)+1)
+	var groupArgs []any
+
+	interval := int64(aggregation.Interval.Seconds())
+	if interval > 0 {
+		selectColumns = append(selectColumns, fmt.Sprintf("toStartOfInterval(timestamp, INTERVAL %d SECOND) AS bucket", interval))
+		groupBy = append(groupBy, "bucket")
+	} else {
+		selectColumns = append(selectColumns, "min(timestamp) AS bucket")
+	}
+
+	for i, tag := range aggregation.GroupBy {
+		selectColumns = append(selectColumns, fmt.Sprintf("JSONExtractString(tags, ?) AS group_%d", i))
+		groupBy = append(groupBy, fmt.Sprintf("group_%d", i))
+		groupArgs = append(groupArgs, tag)
+	}
+
+	query := fmt.Sprintf("SELECT %s, toFloat64(%s(value)) FROM metrics WHERE %s",
+		strings.Join(selectColumns, ", "), function, strings.Join(conditions, " AND "))
+	if len(groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(groupBy, ", ") + " ORDER BY " + strings.Join(groupBy, ", ")
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, append(groupArgs, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var results []AggregationResult
+	for rows.Next() {
+		var result AggregationResult
+		groups := make([]string, len(aggregation.GroupBy))
+
+		dest := []any{&result.Timestamp}
+		for i := range groups {
+			dest = append(dest, &groups[i])
+		}
+		dest = append(dest, &result.Value)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregation: %w", err)
+		}
+
+		if len(groups) > 0 {
+			result.GroupBy = make(map[string]string, len(groups))
+			for i, tag := range aggregation.GroupBy {
+				result.GroupBy[tag] = groups[i]
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return results, nil
+}
+
+func (c *ClickHouseAnalyticsDatabase) GetEventStats(ctx context.Context, timeRange TimeRange) (EventStats, error) {
+	stats := EventStats{
+		TimeRange:      timeRange,
+		EventsByType:   make(map[string]int64),
+		EventsBySource: make(map[string]int64),
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT type, source, count()
+		FROM events
+		WHERE timestamp >= ? AND timestamp <= ?
+		GROUP BY type, source`,
+		timeRange.Start, timeRange.End,
+	)
+	if err != nil {
+		return stats, fmt.Errorf("failed to query event stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventType, source string
+		var count uint64
+
+		if err := rows.Scan(&eventType, &source, &count); err != nil {
+			return stats, fmt.Errorf("failed to scan event stats: %w", err)
+		}
+
+		stats.TotalEvents += int64(count)
+		stats.EventsByType[eventType] += int64(count)
+		stats.EventsBySource[source] += int64(count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return stats, nil
+}
+
+// appendTagConditions filters on the JSON tags column
+func appendTagConditions(conditions []string, args []any, tags map[string]string) ([]string, []any) {
+	for key, value := range tags {
+		conditions = append(conditions, "JSONExtractString(tags, ?) = ?")
+		args = append(args, key, value)
+	}
+	return conditions, args
+}
@@ -0,0 +1,195 @@
+package analytics_db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/database/analytics/clickhousetest"
+	"github.com/pressly/goose/v3"
+)
+
+func TestClickHouseMigrations(t *testing.T) {
+	db := clickhousetest.Open(t)
+	ctx := context.Background()
+
+	version, err := goose.GetDBVersion(db)
+	if err != nil {
+		t.Fatalf("GetDBVersion: %v", err)
+	}
+	if version != 2 {
+		t.Errorf("migration version = %d, want 2", version)
+	}
+
+	// The TTLs are the retention of each table, see 001_analytics_schema.sql
+	ttls := map[string]string{
+		"metrics":  "toIntervalDay(30)",
+		"events":   "toIntervalDay(90)",
+		"logs":     "toIntervalDay(365)",
+		"requests": "toIntervalDay(30)",
+		"spans":    "toIntervalDay(7)",
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT name, engine, engine_full FROM system.tables WHERE database = currentDatabase()")
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool)
+	for rows.Next() {
+		var name, engine, engineFull string
+		if err := rows.Scan(&name, &engine, &engineFull); err != nil {
+			t.Fatalf("failed to scan table: %v", err)
+		}
+
+		ttl, ok := ttls[name]
+		if !ok {
+			continue
+		}
+		found[name] = true
+
+		if engine != "MergeTree" {
+			t.Errorf("%s engine = %s, want MergeTree", name, engine)
+		}
+		if !strings.Contains(engineFull, "TTL ") || !strings.Contains(engineFull, ttl) {
+			t.Errorf("%s does not expire after %s: %s", name, ttl, engineFull)
+		}
+		if !strings.Contains(engineFull, "ttl_only_drop_parts = 1") {
+			t.Errorf("%s does not drop whole parts: %s", name, engineFull)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("row iteration error: %v", err)
+	}
+
+	for name := range ttls {
+		if !found[name] {
+			t.Errorf("table %s was not created", name)
+		}
+	}
+}
+
+func TestClickHouseTTLExpiresMetrics(t *testing.T) {
+	db := clickhousetest.Open(t)
+	ctx := context.Background()
+	analytics := &ClickHouseAnalyticsDatabase{db: db}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, metric := range []Metric{
+		{ID: "expired", Name: "cpu", Value: 1, Tags: map[string]string{}, Timestamp: now.AddDate(0, 0, -40)},
+		{ID: "kept", Name: "cpu", Value: 2, Tags: map[string]string{}, Timestamp: now},
+	} {
+		if err := analytics.StoreMetric(ctx, metric); err != nil {
+			t.Fatalf("StoreMetric: %v", err)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "ALTER TABLE metrics MATERIALIZE TTL SETTINGS mutations_sync = 2"); err != nil {
+		t.Fatalf("failed to materialize TTL: %v", err)
+	}
+
+	metrics, err := analytics.GetMetrics(ctx, MetricFilter{
+		TimeRange: TimeRange{Start: now.AddDate(0, 0, -60), End: now.Add(time.Minute)},
+	})
+	if err != nil {
+		t.Fatalf("GetMetrics: %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].ID != "kept" {
+		t.Fatalf("metrics after TTL = %+v, want only kept", metrics)
+	}
+}
+
+func TestClickHouseMetrics(t *testing.T) {
+	db := clickhousetest.Open(t)
+	ctx := context.Background()
+	analytics := &ClickHouseAnalyticsDatabase{db: db}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, metric := range []Metric{
+		{ID: "1", Name: "requests", Value: 1, Tags: map[string]string{"host": "a"}},
+		{ID: "2", Name: "requests", Value: 2, Tags: map[string]string{"host": "a"}},
+		{ID: "3", Name: "requests", Value: 4, Tags: map[string]string{"host": "b"}},
+		{ID: "4", Name: "memory", Value: 8, Tags: map[string]string{"host": "a"}},
+	} {
+		metric.Timestamp = now.Add(time.Duration(i-4) * time.Second)
+		if err := analytics.StoreMetric(ctx, metric); err != nil {
+			t.Fatalf("StoreMetric: %v", err)
+		}
+	}
+	timeRange := TimeRange{Start: now.Add(-time.Minute), End: now.Add(time.Minute)}
+
+	metrics, err := analytics.GetMetrics(ctx, MetricFilter{
+		Names:     []string{"requests"},
+		Tags:      map[string]string{"host": "a"},
+		TimeRange: timeRange,
+	})
+	if err != nil {
+		t.Fatalf("GetMetrics: %v", err)
+	}
+	if len(metrics) != 2 || metrics[0].ID != "2" || metrics[1].ID != "1" {
+		t.Fatalf("GetMetrics = %+v, want 2 then 1", metrics)
+	}
+	if metrics[0].Tags["host"] != "a" || !metrics[0].Timestamp.Equal(now.Add(-3*time.Second)) {
+		t.Errorf("metric = %+v, want host a at %s", metrics[0], now.Add(-3*time.Second))
+	}
+
+	results, err := analytics.GetMetricAggregation(ctx, MetricAggregation{
+		MetricName: "requests",
+		Function:   Sum,
+		GroupBy:    []string{"host"},
+		TimeRange:  timeRange,
+	})
+	if err != nil {
+		t.Fatalf("GetMetricAggregation: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("GetMetricAggregation returned %d groups, want 2", len(results))
+	}
+	if results[0].GroupBy["host"] != "a" || results[0].Value != 3 {
+		t.Errorf("host a = %+v, want a sum of 3", results[0])
+	}
+	if results[1].GroupBy["host"] != "b" || results[1].Value != 4 {
+		t.Errorf("host b = %+v, want a sum of 4", results[1])
+	}
+}
+
+func TestClickHouseLogs(t *testing.T) {
+	db := clickhousetest.Open(t)
+	ctx := context.Background()
+	analytics := &ClickHouseAnalyticsDatabase{db: db}
+
+	now := time.Now().UTC()
+	err := analytics.StoreLogs(ctx, []LogEntry{
+		{ID: "1", Level: "info", Message: "started", Source: "api", Fields: map[string]any{"port": 8080.0}, Tags: map[string]string{}, Timestamp: now.Add(-2 * time.Second)},
+		{ID: "2", Level: "error", Message: "failed", Source: "api", Fields: map[string]any{}, Tags: map[string]string{"region": "eu"}, Timestamp: now.Add(-time.Second)},
+		{ID: "3", Level: "error", Message: "failed", Source: "worker", Fields: map[string]any{}, Tags: map[string]string{}, Timestamp: now},
+	})
+	if err != nil {
+		t.Fatalf("StoreLogs: %v", err)
+	}
+
+	logs, err := analytics.GetLogs(ctx, LogFilter{
+		Levels:    []string{"error"},
+		Sources:   []string{"api"},
+		TimeRange: TimeRange{Start: now.Add(-time.Minute), End: now.Add(time.Minute)},
+	})
+	if err != nil {
+		t.Fatalf("GetLogs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].ID != "2" || logs[0].Tags["region"] != "eu" {
+		t.Fatalf("GetLogs = %+v, want log 2", logs)
+	}
+
+	logs, err = analytics.GetLogs(ctx, LogFilter{
+		TimeRange: TimeRange{Start: now.Add(-time.Minute), End: now.Add(time.Minute)},
+		Limit:     2,
+	})
+	if err != nil {
+		t.Fatalf("GetLogs: %v", err)
+	}
+	if len(logs) != 2 || logs[0].ID != "3" || logs[1].ID != "2" {
+		t.Fatalf("GetLogs = %+v, want the newest two", logs)
+	}
+}
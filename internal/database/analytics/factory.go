@@ -20,7 +20,7 @@ func (f *AnalyticsFactory) Create(dbType DatabaseType, connectionString string)
 	case DuckDB:
 		return NewDuckDBDatabase(connectionString)
 	case ClickHouse:
-		return nil, fmt.Errorf("ClickHouse implementation not yet available")
+		return NewClickHouseDatabase(connectionString)
 	default:
 		return nil, fmt.Errorf("unsupported analytics database type: %s", dbType)
 	}
@@ -0,0 +1,65 @@
+package analytics_db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+// duckDBVersionTable is the goose version table of DuckDB databases. Goose has
+// no DuckDB dialect, so the table is created up front, with the initial version
+// goose would have inserted, in a form its SQLite queries work with.
+const duckDBVersionTable = `CREATE SEQUENCE IF NOT EXISTS goose_db_version_id;
+CREATE TABLE IF NOT EXISTS goose_db_version (
+	id INTEGER PRIMARY KEY DEFAULT nextval('goose_db_version_id'),
+	version_id BIGINT NOT NULL,
+	is_applied BOOLEAN NOT NULL,
+	tstamp TIMESTAMP DEFAULT current_timestamp
+);
+INSERT INTO goose_db_version (version_id, is_applied)
+SELECT 0, true WHERE NOT EXISTS (SELECT 1 FROM goose_db_version)`
+
+// Migrate runs the migrations in dir on a SQLite or DuckDB analytics database.
+// ClickHouse has migrations of its own.
+func Migrate(dbType DatabaseType, connectionString, dir string) error {
+	var db *sql.DB
+	var err error
+
+	switch dbType {
+	case SQLite:
+		db, err = sql.Open("sqlite3", connectionString)
+	case DuckDB:
+		db, err = sql.Open("duckdb", connectionString+"?access_mode=read_write")
+	default:
+		return fmt.Errorf("unsupported analytics database type: %s", dbType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// The logs table of the first schema predates the migrations, which would
+	// otherwise keep it as is
+	switch dbType {
+	case SQLite:
+		err = (&SQLiteAnalyticsDatabase{db: db}).dropLegacyLogsTable()
+	case DuckDB:
+		err = (&DuckDBAnalyticsDatabase{db: db}).dropLegacyLogsTable()
+		if err == nil {
+			_, err = db.Exec(duckDBVersionTable)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		return fmt.Errorf("failed to set migration dialect: %w", err)
+	}
+	if err := goose.Up(db, dir); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return nil
+}
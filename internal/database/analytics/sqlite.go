@@ -27,13 +27,7 @@ func NewSQLiteDatabase(connectionString string) (AnalyticsDatabase, error) {
 
 	slog.Info("Analytics database connection established (SQLite)", "connection", connectionString)
 
-	instance := &SQLiteAnalyticsDatabase{db: db}
-
-	if err := instance.initSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
-	}
-
-	return instance, nil
+	return &SQLiteAnalyticsDatabase{db: db}, nil
 }
 
 func (s *SQLiteAnalyticsDatabase) Close() error {
@@ -53,47 +47,23 @@ func (s *SQLiteAnalyticsDatabase) DB() interface{} {
 	return s.db
 }
 
-func (s *SQLiteAnalyticsDatabase) initSchema() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS metrics (
-			id TEXT PRIMARY KEY,
-			project_id TEXT NOT NULL,
-			service_id TEXT,
-			name TEXT NOT NULL,
-			value REAL NOT NULL,
-			unit TEXT NOT NULL,
-			tags TEXT NOT NULL DEFAULT '{}',
-			timestamp BIGINT NOT NULL,
-			created_at BIGINT NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS events (
-			id TEXT PRIMARY KEY,
-			type TEXT NOT NULL,
-			source TEXT NOT NULL,
-			data TEXT,
-			tags TEXT,
-			timestamp DATETIME NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS logs (
-			id TEXT PRIMARY KEY,
-			level TEXT NOT NULL,
-			message TEXT NOT NULL,
-			source TEXT NOT NULL,
-			fields TEXT,
-			tags TEXT,
-			timestamp DATETIME NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_metrics_project_id ON metrics(project_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_metrics_name ON metrics(name)`,
-		`CREATE INDEX IF NOT EXISTS idx_metrics_name_timestamp ON metrics(name, timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_events_type_timestamp ON events(type, timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_logs_level_timestamp ON logs(level, timestamp)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute schema query: %w", err)
-		}
+// dropLegacyLogsTable removes the logs table of the first schema, which had no
+// project or container columns and was never written to
+func (s *SQLiteAnalyticsDatabase) dropLegacyLogsTable() error {
+	var columns, containerColumns int
+	err := s.db.QueryRow(
+		`SELECT COUNT(*), COUNT(CASE WHEN name = 'container_id' THEN 1 END) FROM pragma_table_info('logs')`,
+	).Scan(&columns, &containerColumns)
+	if err != nil {
+		return fmt.Errorf("failed to inspect logs table: %w", err)
+	}
+
+	if columns == 0 || containerColumns > 0 {
+		return nil
+	}
+
+	if _, err := s.db.Exec(`DROP TABLE logs`); err != nil {
+		return fmt.Errorf("failed to drop legacy logs table: %w", err)
 	}
 
 	return nil
@@ -155,7 +125,7 @@ func (s *SQLiteAnalyticsDatabase) StoreLogs(ctx context.Context, logs []LogEntry
 		}
 
 		values[i] = "(?, ?, ?, ?, ?, ?, ?)"
-		args = append(args, log.ID, log.Level, log.Message, log.Source, string(fieldsJSON), string(tagsJSON), log.Timestamp)
+		args = append(args, log.ID, log.Level, log.Message, log.Source, string(fieldsJSON), string(tagsJSON), log.Timestamp.UnixNano())
 	}
 
 	query += strings.Join(values, ", ")
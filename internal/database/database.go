@@ -8,9 +8,11 @@ import (
 	"golang.org/x/exp/slog"
 
 	activitiesRepo "github.com/mikrocloud/mikrocloud/internal/domain/activities/repository"
+	alertsRepo "github.com/mikrocloud/mikrocloud/internal/domain/alerts/repository"
 	analyticsRepo "github.com/mikrocloud/mikrocloud/internal/domain/analytics/repository"
 	applicationsRepo "github.com/mikrocloud/mikrocloud/internal/domain/applications/repository"
 	authRepo "github.com/mikrocloud/mikrocloud/internal/domain/auth/repository"
+	backupsRepo "github.com/mikrocloud/mikrocloud/internal/domain/backups/repository"
 	databasesRepo "github.com/mikrocloud/mikrocloud/internal/domain/databases/repository"
 	deploymentsRepo "github.com/mikrocloud/mikrocloud/internal/domain/deployments/repository"
 	disksRepo "github.com/mikrocloud/mikrocloud/internal/domain/disks/repository"
@@ -20,10 +22,13 @@ import (
 	organizationsRepo "github.com/mikrocloud/mikrocloud/internal/domain/organizations/repository"
 	projectsRepo "github.com/mikrocloud/mikrocloud/internal/domain/projects/repository"
 	proxyRepo "github.com/mikrocloud/mikrocloud/internal/domain/proxy/repository"
+	queriesRepo "github.com/mikrocloud/mikrocloud/internal/domain/queries/repository"
 	serversRepo "github.com/mikrocloud/mikrocloud/internal/domain/servers/repository"
 	servicesRepo "github.com/mikrocloud/mikrocloud/internal/domain/services/repository"
 	settingsRepo "github.com/mikrocloud/mikrocloud/internal/domain/settings/repository"
+	telemetryRepo "github.com/mikrocloud/mikrocloud/internal/domain/telemetry/repository"
 	tunnelsRepo "github.com/mikrocloud/mikrocloud/internal/domain/tunnels/repository"
+	uptimeRepo "github.com/mikrocloud/mikrocloud/internal/domain/uptime/repository"
 	usersRepo "github.com/mikrocloud/mikrocloud/internal/domain/users/repository"
 
 	"github.com/mikrocloud/mikrocloud/internal/config"
@@ -37,27 +42,48 @@ type Database struct {
 	analyticsDB analyticsdb.AnalyticsDatabase
 	queueDB     queuedb.QueueDatabase
 
-	ProjectRepository       projectsRepo.Repository
-	ApplicationRepository   applicationsRepo.Repository
-	DatabaseRepository      databasesRepo.DatabaseRepository
-	EnvironmentRepository   environmentsRepo.Repository
-	TemplateRepository      servicesRepo.TemplateRepository
-	UserRepository          usersRepo.Repository
-	SessionRepository       authRepo.SessionRepository
-	AuthRepository          authRepo.AuthRepository
-	DeploymentRepository    deploymentsRepo.DeploymentRepository
-	ProxyRepository         proxyRepo.ProxyRepository
-	TraefikConfigRepository proxyRepo.TraefikConfigRepository
-	DiskRepository          disksRepo.DiskRepository
-	DiskBackupRepository    disksRepo.DiskBackupRepository
-	OrganizationRepository  organizationsRepo.Repository
-	MetricRepository        analyticsRepo.MetricRepository
-	LogRepository           logsRepo.LogRepository
-	SettingsRepository      *settingsRepo.SettingsRepository
-	ActivitiesRepository    *activitiesRepo.ActivitiesRepository
-	ServersRepository       *serversRepo.ServersRepository
-	GitRepository           gitRepo.GitRepository
-	TunnelRepository        tunnelsRepo.TunnelRepository
+	ProjectRepository        projectsRepo.Repository
+	ApplicationRepository    applicationsRepo.Repository
+	DatabaseRepository       databasesRepo.DatabaseRepository
+	EnvironmentRepository    environmentsRepo.Repository
+	TemplateRepository       servicesRepo.TemplateRepository
+	UserRepository           usersRepo.Repository
+	SessionRepository        authRepo.SessionRepository
+	AuthRepository           authRepo.AuthRepository
+	DeploymentRepository     deploymentsRepo.DeploymentRepository
+	ProxyRepository          proxyRepo.ProxyRepository
+	TraefikConfigRepository  proxyRepo.TraefikConfigRepository
+	MiddlewareRepository     proxyRepo.RouteMiddlewareRepository
+	PageSettingsRepository   proxyRepo.ApplicationPageSettingsRepository
+	RedirectRuleRepository   proxyRepo.RedirectRuleRepository
+	BackupScheduleRepository backupsRepo.BackupScheduleRepository
+	BackupRunRepository      backupsRepo.BackupRunRepository
+	WALArchiveRepository     backupsRepo.WALArchiveRepository
+	WALSegmentRepository     backupsRepo.WALSegmentRepository
+	QueryHistoryRepository   queriesRepo.QueryHistoryRepository
+	SavedQueryRepository     queriesRepo.SavedQueryRepository
+	AlertRuleRepository      alertsRepo.AlertRuleRepository
+	ChannelRepository        alertsRepo.NotificationChannelRepository
+	AlertRepository          alertsRepo.AlertRepository
+	DiskRepository           disksRepo.DiskRepository
+	DiskBackupRepository     disksRepo.DiskBackupRepository
+	OrganizationRepository   organizationsRepo.Repository
+	MetricRepository         analyticsRepo.MetricRepository
+	RequestRepository        analyticsRepo.RequestRepository
+	LogRepository            logsRepo.LogRepository
+	SpanRepository           telemetryRepo.SpanRepository
+	IngestTokenRepository    telemetryRepo.IngestTokenRepository
+	MonitorRepository        uptimeRepo.MonitorRepository
+	IncidentRepository       uptimeRepo.IncidentRepository
+	StatusPageRepository     uptimeRepo.StatusPageRepository
+	DashboardRepository      analyticsRepo.DashboardRepository
+	ReportRepository         analyticsRepo.ReportRepository
+	ReportRunRepository      analyticsRepo.ReportRunRepository
+	SettingsRepository       *settingsRepo.SettingsRepository
+	ActivitiesRepository     *activitiesRepo.ActivitiesRepository
+	ServersRepository        *serversRepo.ServersRepository
+	GitRepository            gitRepo.GitRepository
+	TunnelRepository         tunnelsRepo.TunnelRepository
 }
 
 func New(cfg *config.Config) (*Database, error) {
@@ -71,7 +97,7 @@ func New(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to initialize main database: %w", err)
 	}
 
-	analyticsDB, err := analyticsFactory.Create(analyticsdb.DatabaseType(cfg.Analytics.Type), cfg.Analytics.URL)
+	analyticsDB, err := analyticsFactory.Create(analyticsdb.DatabaseType(cfg.Analytics.Type), cfg.Analytics.ConnectionString())
 	if err != nil {
 		slog.Warn("Failed to initialize analytics database, continuing without analytics", "error", err)
 		return nil, fmt.Errorf("failed to initialize analytics database: %w", err)
@@ -89,39 +115,72 @@ func New(cfg *config.Config) (*Database, error) {
 	// Create analytics metric repository
 	// TODO: Send it inside like for maindb
 	var metricRepo analyticsRepo.MetricRepository
+	var requestRepo analyticsRepo.RequestRepository
 	var logRepo logsRepo.LogRepository
-	if sqlDB, ok := analyticsDB.DB().(*sql.DB); ok {
+	var spanRepo telemetryRepo.SpanRepository
+	sqlDB, ok := analyticsDB.DB().(*sql.DB)
+	if !ok {
+		return nil, fmt.Errorf("analytics database does not provide SQL DB interface")
+	}
+
+	if analyticsdb.DatabaseType(cfg.Analytics.Type) == analyticsdb.ClickHouse {
+		metricRepo = analyticsRepo.NewClickHouseMetricRepository(sqlDB)
+		requestRepo = analyticsRepo.NewClickHouseRequestRepository(sqlDB)
+		logRepo = logsRepo.NewClickHouseLogRepository(sqlDB)
+		spanRepo = telemetryRepo.NewClickHouseSpanRepository(sqlDB)
+	} else {
 		metricRepo = analyticsRepo.NewSQLiteMetricRepository(sqlDB)
+		requestRepo = analyticsRepo.NewSQLiteRequestRepository(sqlDB)
 		logRepo = logsRepo.NewAnalyticsLogRepository(sqlDB)
-	} else {
-		return nil, fmt.Errorf("analytics database does not provide SQL DB interface")
+		spanRepo = telemetryRepo.NewAnalyticsSpanRepository(sqlDB)
 	}
 
 	return &Database{
-		mainDB:                  mainDB,
-		analyticsDB:             analyticsDB,
-		queueDB:                 queueDB,
-		ProjectRepository:       mainDB.ProjectRepository(),
-		ApplicationRepository:   mainDB.ApplicationRepository(),
-		DatabaseRepository:      mainDB.DatabaseRepository(),
-		EnvironmentRepository:   mainDB.EnvironmentRepository(),
-		TemplateRepository:      mainDB.TemplateRepository(),
-		UserRepository:          mainDB.UserRepository(),
-		SessionRepository:       mainDB.SessionRepository(),
-		AuthRepository:          mainDB.AuthRepository(),
-		DeploymentRepository:    mainDB.DeploymentRepository(),
-		ProxyRepository:         mainDB.ProxyRepository(),
-		TraefikConfigRepository: mainDB.TraefikConfigRepository(),
-		DiskRepository:          mainDB.DiskRepository(),
-		DiskBackupRepository:    mainDB.DiskBackupRepository(),
-		OrganizationRepository:  mainDB.OrganizationRepository(),
-		GitRepository:           mainDB.GitRepository(),
-		TunnelRepository:        mainDB.TunnelRepository(),
-		MetricRepository:        metricRepo,
-		LogRepository:           logRepo,
-		SettingsRepository:      settingsRepo.NewSettingsRepository(mainDB.DB()),
-		ActivitiesRepository:    activitiesRepo.NewActivitiesRepository(mainDB.DB()),
-		ServersRepository:       serversRepo.NewServersRepository(mainDB.DB()),
+		mainDB:                   mainDB,
+		analyticsDB:              analyticsDB,
+		queueDB:                  queueDB,
+		ProjectRepository:        mainDB.ProjectRepository(),
+		ApplicationRepository:    mainDB.ApplicationRepository(),
+		DatabaseRepository:       mainDB.DatabaseRepository(),
+		EnvironmentRepository:    mainDB.EnvironmentRepository(),
+		TemplateRepository:       mainDB.TemplateRepository(),
+		UserRepository:           mainDB.UserRepository(),
+		SessionRepository:        mainDB.SessionRepository(),
+		AuthRepository:           mainDB.AuthRepository(),
+		DeploymentRepository:     mainDB.DeploymentRepository(),
+		ProxyRepository:          mainDB.ProxyRepository(),
+		TraefikConfigRepository:  mainDB.TraefikConfigRepository(),
+		DiskRepository:           mainDB.DiskRepository(),
+		DiskBackupRepository:     mainDB.DiskBackupRepository(),
+		OrganizationRepository:   mainDB.OrganizationRepository(),
+		GitRepository:            mainDB.GitRepository(),
+		TunnelRepository:         mainDB.TunnelRepository(),
+		MetricRepository:         metricRepo,
+		RequestRepository:        requestRepo,
+		LogRepository:            logRepo,
+		SpanRepository:           spanRepo,
+		SettingsRepository:       settingsRepo.NewSettingsRepository(mainDB.DB()),
+		ActivitiesRepository:     activitiesRepo.NewActivitiesRepository(mainDB.DB()),
+		ServersRepository:        serversRepo.NewServersRepository(mainDB.DB()),
+		MiddlewareRepository:     proxyRepo.NewSQLiteRouteMiddlewareRepository(mainDB.DB()),
+		PageSettingsRepository:   proxyRepo.NewSQLiteApplicationPageSettingsRepository(mainDB.DB()),
+		RedirectRuleRepository:   proxyRepo.NewSQLiteRedirectRuleRepository(mainDB.DB()),
+		BackupScheduleRepository: backupsRepo.NewSQLiteBackupScheduleRepository(mainDB.DB()),
+		BackupRunRepository:      backupsRepo.NewSQLiteBackupRunRepository(mainDB.DB()),
+		WALArchiveRepository:     backupsRepo.NewSQLiteWALArchiveRepository(mainDB.DB()),
+		WALSegmentRepository:     backupsRepo.NewSQLiteWALSegmentRepository(mainDB.DB()),
+		QueryHistoryRepository:   queriesRepo.NewSQLiteQueryHistoryRepository(mainDB.DB()),
+		SavedQueryRepository:     queriesRepo.NewSQLiteSavedQueryRepository(mainDB.DB()),
+		AlertRuleRepository:      alertsRepo.NewSQLiteAlertRuleRepository(mainDB.DB()),
+		ChannelRepository:        alertsRepo.NewSQLiteNotificationChannelRepository(mainDB.DB()),
+		AlertRepository:          alertsRepo.NewSQLiteAlertRepository(mainDB.DB()),
+		IngestTokenRepository:    telemetryRepo.NewSQLiteIngestTokenRepository(mainDB.DB()),
+		MonitorRepository:        uptimeRepo.NewSQLiteMonitorRepository(mainDB.DB()),
+		IncidentRepository:       uptimeRepo.NewSQLiteIncidentRepository(mainDB.DB()),
+		StatusPageRepository:     uptimeRepo.NewSQLiteStatusPageRepository(mainDB.DB()),
+		DashboardRepository:      analyticsRepo.NewSQLiteDashboardRepository(mainDB.DB()),
+		ReportRepository:         analyticsRepo.NewSQLiteReportRepository(mainDB.DB()),
+		ReportRunRepository:      analyticsRepo.NewSQLiteReportRunRepository(mainDB.DB()),
 	}, nil
 }
 
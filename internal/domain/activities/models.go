@@ -52,40 +52,43 @@ func (id ActivityID) UUID() uuid.UUID {
 type EventType string
 
 const (
-	EventTypeProjectCreated     EventType = "project.created"
-	EventTypeProjectUpdated     EventType = "project.updated"
-	EventTypeProjectDeleted     EventType = "project.deleted"
-	EventTypeAppCreated         EventType = "app.created"
-	EventTypeAppUpdated         EventType = "app.updated"
-	EventTypeAppDeleted         EventType = "app.deleted"
-	EventTypeAppDeployed        EventType = "app.deployed"
-	EventTypeAppStarted         EventType = "app.started"
-	EventTypeAppStopped         EventType = "app.stopped"
-	EventTypeAppRestarted       EventType = "app.restarted"
-	EventTypeDatabaseCreated    EventType = "database.created"
-	EventTypeDatabaseUpdated    EventType = "database.updated"
-	EventTypeDatabaseDeleted    EventType = "database.deleted"
-	EventTypeDatabaseStarted    EventType = "database.started"
-	EventTypeDatabaseStopped    EventType = "database.stopped"
-	EventTypeDatabaseRestarted  EventType = "database.restarted"
-	EventTypeEnvironmentCreated EventType = "environment.created"
-	EventTypeEnvironmentUpdated EventType = "environment.updated"
-	EventTypeEnvironmentDeleted EventType = "environment.deleted"
-	EventTypeDiskCreated        EventType = "disk.created"
-	EventTypeDiskResized        EventType = "disk.resized"
-	EventTypeDiskDeleted        EventType = "disk.deleted"
-	EventTypeDiskAttached       EventType = "disk.attached"
-	EventTypeDiskDetached       EventType = "disk.detached"
-	EventTypeProxyCreated       EventType = "proxy.created"
-	EventTypeProxyUpdated       EventType = "proxy.updated"
-	EventTypeProxyDeleted       EventType = "proxy.deleted"
-	EventTypeUserRegistered     EventType = "user.registered"
-	EventTypeUserLogin          EventType = "user.login"
-	EventTypeSettingsUpdated    EventType = "settings.updated"
-	EventTypeBackupCreated      EventType = "backup.created"
-	EventTypeBackupRestored     EventType = "backup.restored"
-	EventTypeSystemStarted      EventType = "system.started"
-	EventTypeSystemStopped      EventType = "system.stopped"
+	EventTypeProjectCreated       EventType = "project.created"
+	EventTypeProjectUpdated       EventType = "project.updated"
+	EventTypeProjectDeleted       EventType = "project.deleted"
+	EventTypeAppCreated           EventType = "app.created"
+	EventTypeAppUpdated           EventType = "app.updated"
+	EventTypeAppDeleted           EventType = "app.deleted"
+	EventTypeAppDeployed          EventType = "app.deployed"
+	EventTypeAppStarted           EventType = "app.started"
+	EventTypeAppStopped           EventType = "app.stopped"
+	EventTypeAppRestarted         EventType = "app.restarted"
+	EventTypeDatabaseCreated      EventType = "database.created"
+	EventTypeDatabaseUpdated      EventType = "database.updated"
+	EventTypeDatabaseDeleted      EventType = "database.deleted"
+	EventTypeDatabaseStarted      EventType = "database.started"
+	EventTypeDatabaseStopped      EventType = "database.stopped"
+	EventTypeDatabaseRestarted    EventType = "database.restarted"
+	EventTypeDatabaseImporting    EventType = "database.importing"
+	EventTypeDatabaseImported     EventType = "database.imported"
+	EventTypeDatabaseImportFailed EventType = "database.import_failed"
+	EventTypeEnvironmentCreated   EventType = "environment.created"
+	EventTypeEnvironmentUpdated   EventType = "environment.updated"
+	EventTypeEnvironmentDeleted   EventType = "environment.deleted"
+	EventTypeDiskCreated          EventType = "disk.created"
+	EventTypeDiskResized          EventType = "disk.resized"
+	EventTypeDiskDeleted          EventType = "disk.deleted"
+	EventTypeDiskAttached         EventType = "disk.attached"
+	EventTypeDiskDetached         EventType = "disk.detached"
+	EventTypeProxyCreated         EventType = "proxy.created"
+	EventTypeProxyUpdated         EventType = "proxy.updated"
+	EventTypeProxyDeleted         EventType = "proxy.deleted"
+	EventTypeUserRegistered       EventType = "user.registered"
+	EventTypeUserLogin            EventType = "user.login"
+	EventTypeSettingsUpdated      EventType = "settings.updated"
+	EventTypeBackupCreated        EventType = "backup.created"
+	EventTypeBackupRestored       EventType = "backup.restored"
+	EventTypeSystemStarted        EventType = "system.started"
+	EventTypeSystemStopped        EventType = "system.stopped"
 )
 
 type ActivityLevel string
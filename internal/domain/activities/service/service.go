@@ -64,9 +64,13 @@ func (s *ActivitiesService) determineLevel(eventType activities.EventType) activ
 		return activities.ActivityLevelWarn
 
 	case activities.EventTypeSystemStarted,
-		activities.EventTypeSystemStopped:
+		activities.EventTypeSystemStopped,
+		activities.EventTypeDatabaseImporting:
 		return activities.ActivityLevelInfo
 
+	case activities.EventTypeDatabaseImportFailed:
+		return activities.ActivityLevelError
+
 	default:
 		return activities.ActivityLevelSuccess
 	}
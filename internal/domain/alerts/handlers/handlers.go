@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts/service"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type AlertHandler struct {
+	alertService   *service.AlertService
+	projectService *projectsService.ProjectService
+	validator      *validator.Validate
+}
+
+func NewAlertHandler(alertService *service.AlertService, projectService *projectsService.ProjectService) *AlertHandler {
+	return &AlertHandler{
+		alertService:   alertService,
+		projectService: projectService,
+		validator:      validator.New(),
+	}
+}
+
+type CreateRuleRequest struct {
+	Name      string          `json:"name" validate:"required,max=255"`
+	Type      alerts.RuleType `json:"type" validate:"required"`
+	ServiceID *string         `json:"service_id,omitempty" validate:"omitempty,uuid"`
+	// Threshold defaults per type: 3 restarts, 90 percent memory, 14 days
+	// before a certificate expires
+	Threshold  float64  `json:"threshold" validate:"min=0"`
+	ForSeconds int      `json:"for_seconds" validate:"min=0,max=604800"`
+	ChannelIDs []string `json:"channel_ids,omitempty"`
+}
+
+type UpdateRuleRequest struct {
+	Name       string   `json:"name" validate:"required,max=255"`
+	ServiceID  *string  `json:"service_id,omitempty" validate:"omitempty,uuid"`
+	Threshold  float64  `json:"threshold" validate:"min=0"`
+	ForSeconds int      `json:"for_seconds" validate:"min=0,max=604800"`
+	ChannelIDs []string `json:"channel_ids,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+type CreateChannelRequest struct {
+	Name       string             `json:"name" validate:"required,max=255"`
+	Type       alerts.ChannelType `json:"type" validate:"required"`
+	URL        string             `json:"url,omitempty"`
+	Secret     string             `json:"secret,omitempty"`
+	Recipients []string           `json:"recipients,omitempty"`
+}
+
+type UpdateChannelRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+	URL  string `json:"url,omitempty"`
+	// Secret is kept when omitted
+	Secret     string   `json:"secret,omitempty"`
+	Recipients []string `json:"recipients,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+type RuleResponse struct {
+	ID         string          `json:"id"`
+	ProjectID  string          `json:"project_id"`
+	Name       string          `json:"name"`
+	Type       alerts.RuleType `json:"type"`
+	ServiceID  *string         `json:"service_id,omitempty"`
+	Threshold  float64         `json:"threshold"`
+	ForSeconds int64           `json:"for_seconds"`
+	ChannelIDs []string        `json:"channel_ids"`
+	Enabled    bool            `json:"enabled"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+type ChannelResponse struct {
+	ID         string             `json:"id"`
+	ProjectID  string             `json:"project_id"`
+	Name       string             `json:"name"`
+	Type       alerts.ChannelType `json:"type"`
+	URL        string             `json:"url,omitempty"`
+	HasSecret  bool               `json:"has_secret"`
+	Recipients []string           `json:"recipients,omitempty"`
+	Enabled    bool               `json:"enabled"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+type AlertResponse struct {
+	ID         string             `json:"id"`
+	RuleID     string             `json:"rule_id"`
+	Subject    string             `json:"subject"`
+	Message    string             `json:"message"`
+	Status     alerts.AlertStatus `json:"status"`
+	StartedAt  time.Time          `json:"started_at"`
+	ResolvedAt *time.Time         `json:"resolved_at,omitempty"`
+}
+
+type ListRulesResponse struct {
+	Rules []RuleResponse `json:"rules"`
+}
+
+type ListChannelsResponse struct {
+	Channels []ChannelResponse `json:"channels"`
+}
+
+type ListAlertsResponse struct {
+	Alerts []AlertResponse `json:"alerts"`
+}
+
+func toRuleResponse(rule *alerts.AlertRule) RuleResponse {
+	response := RuleResponse{
+		ID:         rule.ID().String(),
+		ProjectID:  rule.ProjectID().String(),
+		Name:       rule.Name(),
+		Type:       rule.Type(),
+		Threshold:  rule.Threshold(),
+		ForSeconds: int64(rule.For() / time.Second),
+		ChannelIDs: make([]string, 0, len(rule.ChannelIDs())),
+		Enabled:    rule.Enabled(),
+		CreatedAt:  rule.CreatedAt(),
+		UpdatedAt:  rule.UpdatedAt(),
+	}
+
+	if rule.ServiceID() != nil {
+		serviceID := rule.ServiceID().String()
+		response.ServiceID = &serviceID
+	}
+
+	for _, channelID := range rule.ChannelIDs() {
+		response.ChannelIDs = append(response.ChannelIDs, channelID.String())
+	}
+
+	return response
+}
+
+func toChannelResponse(channel *alerts.NotificationChannel) ChannelResponse {
+	config := channel.Config()
+	return ChannelResponse{
+		ID:         channel.ID().String(),
+		ProjectID:  channel.ProjectID().String(),
+		Name:       channel.Name(),
+		Type:       channel.Type(),
+		URL:        config.URL,
+		HasSecret:  config.Secret != "",
+		Recipients: config.Recipients,
+		Enabled:    channel.Enabled(),
+		CreatedAt:  channel.CreatedAt(),
+		UpdatedAt:  channel.UpdatedAt(),
+	}
+}
+
+func toAlertResponse(alert *alerts.Alert) AlertResponse {
+	return AlertResponse{
+		ID:         alert.ID().String(),
+		RuleID:     alert.RuleID().String(),
+		Subject:    alert.Subject(),
+		Message:    alert.Message(),
+		Status:     alert.Status(),
+		StartedAt:  alert.StartedAt(),
+		ResolvedAt: alert.ResolvedAt(),
+	}
+}
+
+// ListAlerts returns the alerts of a project, newest first. The status query
+// parameter filters on firing or resolved.
+func (h *AlertHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var status *alerts.AlertStatus
+	if value := r.URL.Query().Get("status"); value != "" {
+		parsed := alerts.AlertStatus(value)
+		if parsed != alerts.AlertStatusFiring && parsed != alerts.AlertStatusResolved {
+			utils.SendError(w, http.StatusBadRequest, "invalid_status", "status must be firing or resolved")
+			return
+		}
+		status = &parsed
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	result, err := h.alertService.ListAlerts(r.Context(), projectID, status, limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list alerts")
+		return
+	}
+
+	response := ListAlertsResponse{Alerts: make([]AlertResponse, 0, len(result))}
+	for _, alert := range result {
+		response.Alerts = append(response.Alerts, toAlertResponse(alert))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *AlertHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.alertService.ListRules(r.Context(), projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list alert rules")
+		return
+	}
+
+	response := ListRulesResponse{Rules: make([]RuleResponse, 0, len(rules))}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, toRuleResponse(rule))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *AlertHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	rule, err := h.alertService.CreateRule(r.Context(), service.CreateRuleCommand{
+		ProjectID:  projectID,
+		Name:       req.Name,
+		Type:       req.Type,
+		ServiceID:  parseOptionalUUID(req.ServiceID),
+		Threshold:  req.Threshold,
+		For:        time.Duration(req.ForSeconds) * time.Second,
+		ChannelIDs: req.ChannelIDs,
+	})
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create alert rule: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toRuleResponse(rule))
+}
+
+func (h *AlertHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.alertService.GetRule(r.Context(), projectID, chi.URLParam(r, "rule_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toRuleResponse(rule))
+}
+
+func (h *AlertHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	rule, err := h.alertService.UpdateRule(r.Context(), projectID, chi.URLParam(r, "rule_id"), service.UpdateRuleCommand{
+		Name:       req.Name,
+		ServiceID:  parseOptionalUUID(req.ServiceID),
+		Threshold:  req.Threshold,
+		For:        time.Duration(req.ForSeconds) * time.Second,
+		ChannelIDs: req.ChannelIDs,
+		Enabled:    enabled,
+	})
+	if errors.Is(err, service.ErrRuleNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update alert rule: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toRuleResponse(rule))
+}
+
+func (h *AlertHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.DeleteRule(r.Context(), projectID, chi.URLParam(r, "rule_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AlertHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	channels, err := h.alertService.ListChannels(r.Context(), projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list notification channels")
+		return
+	}
+
+	response := ListChannelsResponse{Channels: make([]ChannelResponse, 0, len(channels))}
+	for _, channel := range channels {
+		response.Channels = append(response.Channels, toChannelResponse(channel))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *AlertHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	channel, err := h.alertService.CreateChannel(r.Context(), projectID, req.Name, req.Type, alerts.ChannelConfig{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Recipients: req.Recipients,
+	})
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create notification channel: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toChannelResponse(channel))
+}
+
+func (h *AlertHandler) GetChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	channel, err := h.alertService.GetChannel(r.Context(), projectID, chi.URLParam(r, "channel_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toChannelResponse(channel))
+}
+
+func (h *AlertHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	channel, err := h.alertService.UpdateChannel(r.Context(), projectID, chi.URLParam(r, "channel_id"), req.Name, alerts.ChannelConfig{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Recipients: req.Recipients,
+	}, enabled)
+	if errors.Is(err, service.ErrChannelNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update notification channel: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toChannelResponse(channel))
+}
+
+func (h *AlertHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.DeleteChannel(r.Context(), projectID, chi.URLParam(r, "channel_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestChannel sends a sample notification through a channel
+func (h *AlertHandler) TestChannel(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.alertService.TestChannel(r.Context(), projectID, chi.URLParam(r, "channel_id"))
+	if errors.Is(err, service.ErrChannelNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadGateway, "notification_failed", "Failed to send test notification: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Test notification sent"})
+}
+
+func (h *AlertHandler) getProject(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return uuid.Nil, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return uuid.Nil, false
+	}
+
+	return projectID, true
+}
+
+func (h *AlertHandler) sendLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrRuleNotFound):
+		utils.SendError(w, http.StatusNotFound, "rule_not_found", "Alert rule not found")
+	case errors.Is(err, service.ErrChannelNotFound):
+		utils.SendError(w, http.StatusNotFound, "channel_not_found", "Notification channel not found")
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
+
+// parseOptionalUUID parses a UUID the validator already checked
+func parseOptionalUUID(value *string) *uuid.UUID {
+	if value == nil || *value == "" {
+		return nil
+	}
+	parsed := uuid.MustParse(*value)
+	return &parsed
+}
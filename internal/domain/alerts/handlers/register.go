@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+)
+
+// RegisterAlertRoutes registers the alert, alert rule and notification channel
+// routes of a project
+func RegisterAlertRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewAlertHandler(deps.AlertService, deps.ProjectService)
+
+	r.Route("/alerts", func(r chi.Router) {
+		r.Get("/", handler.ListAlerts)
+		r.Route("/rules", func(r chi.Router) {
+			r.Get("/", handler.ListRules)
+			r.Post("/", handler.CreateRule)
+			r.Get("/{rule_id}", handler.GetRule)
+			r.Put("/{rule_id}", handler.UpdateRule)
+			r.Delete("/{rule_id}", handler.DeleteRule)
+		})
+		r.Route("/channels", func(r chi.Router) {
+			r.Get("/", handler.ListChannels)
+			r.Post("/", handler.CreateChannel)
+			r.Get("/{channel_id}", handler.GetChannel)
+			r.Put("/{channel_id}", handler.UpdateChannel)
+			r.Delete("/{channel_id}", handler.DeleteChannel)
+			r.Post("/{channel_id}/test", handler.TestChannel)
+		})
+	})
+}
@@ -0,0 +1,532 @@
+package alerts
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlertRuleID struct {
+	value string
+}
+
+func NewAlertRuleID() AlertRuleID {
+	return AlertRuleID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func AlertRuleIDFromString(s string) (AlertRuleID, error) {
+	if s == "" {
+		return AlertRuleID{}, fmt.Errorf("alert rule ID cannot be empty")
+	}
+	return AlertRuleID{value: s}, nil
+}
+
+func (id AlertRuleID) String() string {
+	return id.value
+}
+
+type ChannelID struct {
+	value string
+}
+
+func NewChannelID() ChannelID {
+	return ChannelID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func ChannelIDFromString(s string) (ChannelID, error) {
+	if s == "" {
+		return ChannelID{}, fmt.Errorf("channel ID cannot be empty")
+	}
+	return ChannelID{value: s}, nil
+}
+
+func (id ChannelID) String() string {
+	return id.value
+}
+
+type AlertID struct {
+	value string
+}
+
+func NewAlertID() AlertID {
+	return AlertID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func AlertIDFromString(s string) (AlertID, error) {
+	if s == "" {
+		return AlertID{}, fmt.Errorf("alert ID cannot be empty")
+	}
+	return AlertID{value: s}, nil
+}
+
+func (id AlertID) String() string {
+	return id.value
+}
+
+// RuleType is the condition an alert rule watches for
+type RuleType string
+
+const (
+	// RuleTypeDeploymentFailed fires while the latest deployment of an
+	// application has failed
+	RuleTypeDeploymentFailed RuleType = "deployment_failed"
+	// RuleTypeContainerRestartLoop fires when a container restarted at least
+	// threshold times within RestartLoopWindow
+	RuleTypeContainerRestartLoop RuleType = "container_restart_loop"
+	// RuleTypeMemoryUsage fires when a container uses more than threshold
+	// percent of its memory limit
+	RuleTypeMemoryUsage RuleType = "memory_usage"
+	// RuleTypeCertificateExpiring fires when the certificate served for an
+	// application domain expires within threshold days
+	RuleTypeCertificateExpiring RuleType = "certificate_expiring"
+	// RuleTypeTunnelUnhealthy fires while a tunnel of the project fails its
+	// health checks
+	RuleTypeTunnelUnhealthy RuleType = "tunnel_unhealthy"
+)
+
+// RestartLoopWindow is the window container restarts are counted in
+const RestartLoopWindow = 10 * time.Minute
+
+func (t RuleType) IsValid() bool {
+	switch t {
+	case RuleTypeDeploymentFailed, RuleTypeContainerRestartLoop, RuleTypeMemoryUsage,
+		RuleTypeCertificateExpiring, RuleTypeTunnelUnhealthy:
+		return true
+	}
+	return false
+}
+
+// DefaultThreshold is used when a rule is created without a threshold. Rule
+// types that don't compare against a value return 0.
+func (t RuleType) DefaultThreshold() float64 {
+	switch t {
+	case RuleTypeContainerRestartLoop:
+		return 3
+	case RuleTypeMemoryUsage:
+		return 90
+	case RuleTypeCertificateExpiring:
+		return 14
+	}
+	return 0
+}
+
+// AlertRule describes a condition that raises an alert for a project. A rule
+// scoped to a service only watches that application or database. Alerts of a
+// rule are sent to its channels, or to every enabled channel of the project
+// when it has none.
+type AlertRule struct {
+	id          AlertRuleID
+	projectID   uuid.UUID
+	name        string
+	ruleType    RuleType
+	serviceID   *uuid.UUID
+	threshold   float64
+	forDuration time.Duration
+	channelIDs  []ChannelID
+	enabled     bool
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func NewAlertRule(
+	projectID uuid.UUID,
+	name string,
+	ruleType RuleType,
+	serviceID *uuid.UUID,
+	threshold float64,
+	forDuration time.Duration,
+	channelIDs []ChannelID,
+) (*AlertRule, error) {
+	if !ruleType.IsValid() {
+		return nil, fmt.Errorf("invalid rule type: %s", ruleType)
+	}
+
+	if threshold == 0 {
+		threshold = ruleType.DefaultThreshold()
+	}
+
+	now := time.Now()
+	rule := &AlertRule{
+		id:        NewAlertRuleID(),
+		projectID: projectID,
+		ruleType:  ruleType,
+		enabled:   true,
+		createdAt: now,
+		updatedAt: now,
+	}
+
+	if err := rule.Update(name, serviceID, threshold, forDuration, channelIDs, true); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *AlertRule) ID() AlertRuleID {
+	return r.id
+}
+
+func (r *AlertRule) ProjectID() uuid.UUID {
+	return r.projectID
+}
+
+func (r *AlertRule) Name() string {
+	return r.name
+}
+
+func (r *AlertRule) Type() RuleType {
+	return r.ruleType
+}
+
+func (r *AlertRule) ServiceID() *uuid.UUID {
+	return r.serviceID
+}
+
+func (r *AlertRule) Threshold() float64 {
+	return r.threshold
+}
+
+// For is how long the condition must hold before the rule fires
+func (r *AlertRule) For() time.Duration {
+	return r.forDuration
+}
+
+func (r *AlertRule) ChannelIDs() []ChannelID {
+	return r.channelIDs
+}
+
+func (r *AlertRule) Enabled() bool {
+	return r.enabled
+}
+
+func (r *AlertRule) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+func (r *AlertRule) UpdatedAt() time.Time {
+	return r.updatedAt
+}
+
+// Update changes everything but the rule type, which would make the alerts
+// raised so far meaningless
+func (r *AlertRule) Update(name string, serviceID *uuid.UUID, threshold float64, forDuration time.Duration, channelIDs []ChannelID, enabled bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("rule name cannot be empty")
+	}
+
+	if forDuration < 0 {
+		return fmt.Errorf("for duration cannot be negative")
+	}
+
+	switch r.ruleType {
+	case RuleTypeContainerRestartLoop:
+		if threshold < 1 {
+			return fmt.Errorf("restart threshold must be at least 1")
+		}
+	case RuleTypeMemoryUsage:
+		if threshold <= 0 || threshold > 100 {
+			return fmt.Errorf("memory threshold must be a percentage between 0 and 100")
+		}
+	case RuleTypeCertificateExpiring:
+		if threshold < 1 {
+			return fmt.Errorf("certificate threshold must be at least 1 day")
+		}
+	}
+
+	r.name = name
+	r.serviceID = serviceID
+	r.threshold = threshold
+	r.forDuration = forDuration
+	r.channelIDs = channelIDs
+	r.enabled = enabled
+	r.updatedAt = time.Now()
+	return nil
+}
+
+// AppliesTo reports whether the rule watches a service
+func (r *AlertRule) AppliesTo(serviceID uuid.UUID) bool {
+	return r.serviceID == nil || *r.serviceID == serviceID
+}
+
+func ReconstructAlertRule(
+	id AlertRuleID,
+	projectID uuid.UUID,
+	name string,
+	ruleType RuleType,
+	serviceID *uuid.UUID,
+	threshold float64,
+	forDuration time.Duration,
+	channelIDs []ChannelID,
+	enabled bool,
+	createdAt, updatedAt time.Time,
+) *AlertRule {
+	return &AlertRule{
+		id:          id,
+		projectID:   projectID,
+		name:        name,
+		ruleType:    ruleType,
+		serviceID:   serviceID,
+		threshold:   threshold,
+		forDuration: forDuration,
+		channelIDs:  channelIDs,
+		enabled:     enabled,
+		createdAt:   createdAt,
+		updatedAt:   updatedAt,
+	}
+}
+
+type ChannelType string
+
+const (
+	ChannelTypeEmail   ChannelType = "email"
+	ChannelTypeWebhook ChannelType = "webhook"
+	// ChannelTypeSlack posts to a Slack incoming webhook, or any service
+	// accepting the same {"text": ...} payload
+	ChannelTypeSlack ChannelType = "slack"
+	// ChannelTypeDiscord posts to a Discord webhook
+	ChannelTypeDiscord ChannelType = "discord"
+)
+
+func (t ChannelType) IsValid() bool {
+	switch t {
+	case ChannelTypeEmail, ChannelTypeWebhook, ChannelTypeSlack, ChannelTypeDiscord:
+		return true
+	}
+	return false
+}
+
+// ChannelConfig holds where a channel delivers to. Email channels use
+// Recipients, the other types URL. Webhook channels sign their payload with
+// Secret when it is set.
+type ChannelConfig struct {
+	URL        string   `json:"url,omitempty"`
+	Secret     string   `json:"secret,omitempty"`
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+func (c ChannelConfig) validate(channelType ChannelType) error {
+	if channelType == ChannelTypeEmail {
+		if len(c.Recipients) == 0 {
+			return fmt.Errorf("email channels need at least one recipient")
+		}
+		for _, recipient := range c.Recipients {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+			}
+		}
+		return nil
+	}
+
+	parsed, err := url.Parse(c.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s channels need an http or https URL", channelType)
+	}
+	return nil
+}
+
+// NotificationChannel is a destination for the alerts of a project
+type NotificationChannel struct {
+	id          ChannelID
+	projectID   uuid.UUID
+	name        string
+	channelType ChannelType
+	config      ChannelConfig
+	enabled     bool
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func NewNotificationChannel(projectID uuid.UUID, name string, channelType ChannelType, config ChannelConfig) (*NotificationChannel, error) {
+	if !channelType.IsValid() {
+		return nil, fmt.Errorf("invalid channel type: %s", channelType)
+	}
+
+	now := time.Now()
+	channel := &NotificationChannel{
+		id:          NewChannelID(),
+		projectID:   projectID,
+		channelType: channelType,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+
+	if err := channel.Update(name, config, true); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+func (c *NotificationChannel) ID() ChannelID {
+	return c.id
+}
+
+func (c *NotificationChannel) ProjectID() uuid.UUID {
+	return c.projectID
+}
+
+func (c *NotificationChannel) Name() string {
+	return c.name
+}
+
+func (c *NotificationChannel) Type() ChannelType {
+	return c.channelType
+}
+
+func (c *NotificationChannel) Config() ChannelConfig {
+	return c.config
+}
+
+func (c *NotificationChannel) Enabled() bool {
+	return c.enabled
+}
+
+func (c *NotificationChannel) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+func (c *NotificationChannel) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+
+func (c *NotificationChannel) Update(name string, config ChannelConfig, enabled bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("channel name cannot be empty")
+	}
+
+	if err := config.validate(c.channelType); err != nil {
+		return err
+	}
+
+	c.name = name
+	c.config = config
+	c.enabled = enabled
+	c.updatedAt = time.Now()
+	return nil
+}
+
+func ReconstructNotificationChannel(
+	id ChannelID,
+	projectID uuid.UUID,
+	name string,
+	channelType ChannelType,
+	config ChannelConfig,
+	enabled bool,
+	createdAt, updatedAt time.Time,
+) *NotificationChannel {
+	return &NotificationChannel{
+		id:          id,
+		projectID:   projectID,
+		name:        name,
+		channelType: channelType,
+		config:      config,
+		enabled:     enabled,
+		createdAt:   createdAt,
+		updatedAt:   updatedAt,
+	}
+}
+
+type AlertStatus string
+
+const (
+	AlertStatusFiring   AlertStatus = "firing"
+	AlertStatusResolved AlertStatus = "resolved"
+)
+
+// Alert is one occurrence of a rule firing for a subject, such as a container
+// or a domain. The fingerprint identifies the subject so that a condition that
+// keeps holding stays a single alert instead of notifying on every evaluation.
+type Alert struct {
+	id          AlertID
+	ruleID      AlertRuleID
+	projectID   uuid.UUID
+	fingerprint string
+	subject     string
+	message     string
+	status      AlertStatus
+	startedAt   time.Time
+	resolvedAt  *time.Time
+}
+
+func NewAlert(rule *AlertRule, fingerprint, subject, message string) *Alert {
+	return &Alert{
+		id:          NewAlertID(),
+		ruleID:      rule.ID(),
+		projectID:   rule.ProjectID(),
+		fingerprint: fingerprint,
+		subject:     subject,
+		message:     message,
+		status:      AlertStatusFiring,
+		startedAt:   time.Now(),
+	}
+}
+
+func (a *Alert) ID() AlertID {
+	return a.id
+}
+
+func (a *Alert) RuleID() AlertRuleID {
+	return a.ruleID
+}
+
+func (a *Alert) ProjectID() uuid.UUID {
+	return a.projectID
+}
+
+func (a *Alert) Fingerprint() string {
+	return a.fingerprint
+}
+
+func (a *Alert) Subject() string {
+	return a.subject
+}
+
+func (a *Alert) Message() string {
+	return a.message
+}
+
+func (a *Alert) Status() AlertStatus {
+	return a.status
+}
+
+func (a *Alert) StartedAt() time.Time {
+	return a.startedAt
+}
+
+func (a *Alert) ResolvedAt() *time.Time {
+	return a.resolvedAt
+}
+
+func (a *Alert) Resolve() {
+	now := time.Now()
+	a.status = AlertStatusResolved
+	a.resolvedAt = &now
+}
+
+func ReconstructAlert(
+	id AlertID,
+	ruleID AlertRuleID,
+	projectID uuid.UUID,
+	fingerprint, subject, message string,
+	status AlertStatus,
+	startedAt time.Time,
+	resolvedAt *time.Time,
+) *Alert {
+	return &Alert{
+		id:          id,
+		ruleID:      ruleID,
+		projectID:   projectID,
+		fingerprint: fingerprint,
+		subject:     subject,
+		message:     message,
+		status:      status,
+		startedAt:   startedAt,
+		resolvedAt:  resolvedAt,
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts"
+)
+
+type AlertRuleRepository interface {
+	Create(ctx context.Context, rule *alerts.AlertRule) error
+	GetByID(ctx context.Context, id alerts.AlertRuleID) (*alerts.AlertRule, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*alerts.AlertRule, error)
+	// ListEnabled returns the enabled rules of every project
+	ListEnabled(ctx context.Context) ([]*alerts.AlertRule, error)
+	Update(ctx context.Context, rule *alerts.AlertRule) error
+	Delete(ctx context.Context, id alerts.AlertRuleID) error
+}
+
+type NotificationChannelRepository interface {
+	Create(ctx context.Context, channel *alerts.NotificationChannel) error
+	GetByID(ctx context.Context, id alerts.ChannelID) (*alerts.NotificationChannel, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*alerts.NotificationChannel, error)
+	Update(ctx context.Context, channel *alerts.NotificationChannel) error
+	Delete(ctx context.Context, id alerts.ChannelID) error
+}
+
+type AlertRepository interface {
+	Create(ctx context.Context, alert *alerts.Alert) error
+	// ListFiring returns the firing alerts of every project
+	ListFiring(ctx context.Context) ([]*alerts.Alert, error)
+	// ListByProject returns the newest alerts first, status filters when set
+	ListByProject(ctx context.Context, projectID uuid.UUID, status *alerts.AlertStatus, limit int) ([]*alerts.Alert, error)
+	Update(ctx context.Context, alert *alerts.Alert) error
+}
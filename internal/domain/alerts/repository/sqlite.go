@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts"
+)
+
+const alertRuleColumns = `id, project_id, name, type, service_id, threshold, for_seconds, channel_ids, enabled, created_at, updated_at`
+
+const channelColumns = `id, project_id, name, type, config, enabled, created_at, updated_at`
+
+const alertColumns = `id, rule_id, project_id, fingerprint, subject, message, status, started_at, resolved_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type SQLiteAlertRuleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAlertRuleRepository(db *sql.DB) *SQLiteAlertRuleRepository {
+	return &SQLiteAlertRuleRepository{db: db}
+}
+
+func (r *SQLiteAlertRuleRepository) Create(ctx context.Context, rule *alerts.AlertRule) error {
+	channelIDs, err := marshalChannelIDs(rule.ChannelIDs())
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO alert_rules (` + alertRuleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		rule.ID().String(),
+		rule.ProjectID().String(),
+		rule.Name(),
+		string(rule.Type()),
+		nullableUUID(rule.ServiceID()),
+		rule.Threshold(),
+		int64(rule.For()/time.Second),
+		channelIDs,
+		rule.Enabled(),
+		rule.CreatedAt(),
+		rule.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteAlertRuleRepository) GetByID(ctx context.Context, id alerts.AlertRuleID) (*alerts.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id = ?`
+	return r.scanRule(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteAlertRuleRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*alerts.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE project_id = ? ORDER BY name ASC, created_at ASC`
+	return r.list(ctx, query, projectID.String())
+}
+
+func (r *SQLiteAlertRuleRepository) ListEnabled(ctx context.Context) ([]*alerts.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE enabled = TRUE ORDER BY project_id, created_at ASC`
+	return r.list(ctx, query)
+}
+
+func (r *SQLiteAlertRuleRepository) Update(ctx context.Context, rule *alerts.AlertRule) error {
+	channelIDs, err := marshalChannelIDs(rule.ChannelIDs())
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE alert_rules SET name = ?, service_id = ?, threshold = ?, for_seconds = ?, channel_ids = ?, enabled = ?, updated_at = ? WHERE id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		rule.Name(),
+		nullableUUID(rule.ServiceID()),
+		rule.Threshold(),
+		int64(rule.For()/time.Second),
+		channelIDs,
+		rule.Enabled(),
+		rule.UpdatedAt(),
+		rule.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteAlertRuleRepository) Delete(ctx context.Context, id alerts.AlertRuleID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteAlertRuleRepository) list(ctx context.Context, query string, args ...any) ([]*alerts.AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*alerts.AlertRule
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *SQLiteAlertRuleRepository) scanRule(row rowScanner) (*alerts.AlertRule, error) {
+	var (
+		id, projectIDStr, name, ruleType, channelIDsJSON string
+		serviceIDStr                                     sql.NullString
+		threshold                                        float64
+		forSeconds                                       int64
+		enabled                                          bool
+		createdAt, updatedAt                             time.Time
+	)
+
+	err := row.Scan(&id, &projectIDStr, &name, &ruleType, &serviceIDStr, &threshold, &forSeconds, &channelIDsJSON, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleID, err := alerts.AlertRuleIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var serviceID *uuid.UUID
+	if serviceIDStr.Valid && serviceIDStr.String != "" {
+		parsed, err := uuid.Parse(serviceIDStr.String)
+		if err != nil {
+			return nil, err
+		}
+		serviceID = &parsed
+	}
+
+	var rawChannelIDs []string
+	if err := json.Unmarshal([]byte(channelIDsJSON), &rawChannelIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal channel IDs: %w", err)
+	}
+
+	channelIDs := make([]alerts.ChannelID, 0, len(rawChannelIDs))
+	for _, raw := range rawChannelIDs {
+		channelID, err := alerts.ChannelIDFromString(raw)
+		if err != nil {
+			return nil, err
+		}
+		channelIDs = append(channelIDs, channelID)
+	}
+
+	return alerts.ReconstructAlertRule(
+		ruleID,
+		projectID,
+		name,
+		alerts.RuleType(ruleType),
+		serviceID,
+		threshold,
+		time.Duration(forSeconds)*time.Second,
+		channelIDs,
+		enabled,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+type SQLiteNotificationChannelRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteNotificationChannelRepository(db *sql.DB) *SQLiteNotificationChannelRepository {
+	return &SQLiteNotificationChannelRepository{db: db}
+}
+
+func (r *SQLiteNotificationChannelRepository) Create(ctx context.Context, channel *alerts.NotificationChannel) error {
+	config, err := json.Marshal(channel.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel config: %w", err)
+	}
+
+	query := `INSERT INTO notification_channels (` + channelColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		channel.ID().String(),
+		channel.ProjectID().String(),
+		channel.Name(),
+		string(channel.Type()),
+		string(config),
+		channel.Enabled(),
+		channel.CreatedAt(),
+		channel.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteNotificationChannelRepository) GetByID(ctx context.Context, id alerts.ChannelID) (*alerts.NotificationChannel, error) {
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE id = ?`
+	return r.scanChannel(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteNotificationChannelRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*alerts.NotificationChannel, error) {
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE project_id = ? ORDER BY name ASC, created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []*alerts.NotificationChannel
+	for rows.Next() {
+		channel, err := r.scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, rows.Err()
+}
+
+func (r *SQLiteNotificationChannelRepository) Update(ctx context.Context, channel *alerts.NotificationChannel) error {
+	config, err := json.Marshal(channel.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel config: %w", err)
+	}
+
+	query := `UPDATE notification_channels SET name = ?, config = ?, enabled = ?, updated_at = ? WHERE id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		channel.Name(),
+		string(config),
+		channel.Enabled(),
+		channel.UpdatedAt(),
+		channel.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteNotificationChannelRepository) Delete(ctx context.Context, id alerts.ChannelID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteNotificationChannelRepository) scanChannel(row rowScanner) (*alerts.NotificationChannel, error) {
+	var (
+		id, projectIDStr, name, channelType, configJSON string
+		enabled                                         bool
+		createdAt, updatedAt                            time.Time
+	)
+
+	err := row.Scan(&id, &projectIDStr, &name, &channelType, &configJSON, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	channelID, err := alerts.ChannelIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var config alerts.ChannelConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal channel config: %w", err)
+	}
+
+	return alerts.ReconstructNotificationChannel(
+		channelID,
+		projectID,
+		name,
+		alerts.ChannelType(channelType),
+		config,
+		enabled,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+type SQLiteAlertRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAlertRepository(db *sql.DB) *SQLiteAlertRepository {
+	return &SQLiteAlertRepository{db: db}
+}
+
+func (r *SQLiteAlertRepository) Create(ctx context.Context, alert *alerts.Alert) error {
+	query := `INSERT INTO alerts (` + alertColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		alert.ID().String(),
+		alert.RuleID().String(),
+		alert.ProjectID().String(),
+		alert.Fingerprint(),
+		alert.Subject(),
+		alert.Message(),
+		string(alert.Status()),
+		alert.StartedAt(),
+		alert.ResolvedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteAlertRepository) ListFiring(ctx context.Context) ([]*alerts.Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE status = ? ORDER BY started_at ASC`
+	return r.list(ctx, query, string(alerts.AlertStatusFiring))
+}
+
+func (r *SQLiteAlertRepository) ListByProject(ctx context.Context, projectID uuid.UUID, status *alerts.AlertStatus, limit int) ([]*alerts.Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE project_id = ?`
+	args := []any{projectID.String()}
+
+	if status != nil {
+		query += ` AND status = ?`
+		args = append(args, string(*status))
+	}
+
+	query += ` ORDER BY started_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return r.list(ctx, query, args...)
+}
+
+func (r *SQLiteAlertRepository) Update(ctx context.Context, alert *alerts.Alert) error {
+	query := `UPDATE alerts SET message = ?, status = ?, resolved_at = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query,
+		alert.Message(),
+		string(alert.Status()),
+		alert.ResolvedAt(),
+		alert.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteAlertRepository) list(ctx context.Context, query string, args ...any) ([]*alerts.Alert, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*alerts.Alert
+	for rows.Next() {
+		alert, err := r.scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, alert)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *SQLiteAlertRepository) scanAlert(row rowScanner) (*alerts.Alert, error) {
+	var (
+		id, ruleIDStr, projectIDStr, fingerprint, subject, message, status string
+		startedAt                                                          time.Time
+		resolvedAt                                                         sql.NullTime
+	)
+
+	err := row.Scan(&id, &ruleIDStr, &projectIDStr, &fingerprint, &subject, &message, &status, &startedAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	alertID, err := alerts.AlertIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleID, err := alerts.AlertRuleIDFromString(ruleIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved *time.Time
+	if resolvedAt.Valid {
+		resolved = &resolvedAt.Time
+	}
+
+	return alerts.ReconstructAlert(
+		alertID,
+		ruleID,
+		projectID,
+		fingerprint,
+		subject,
+		message,
+		alerts.AlertStatus(status),
+		startedAt,
+		resolved,
+	), nil
+}
+
+func marshalChannelIDs(channelIDs []alerts.ChannelID) (string, error) {
+	raw := make([]string, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		raw = append(raw, channelID.String())
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal channel IDs: %w", err)
+	}
+	return string(data), nil
+}
+
+func nullableUUID(id *uuid.UUID) any {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
@@ -0,0 +1,630 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	"github.com/mikrocloud/mikrocloud/internal/domain/domains"
+	"github.com/mikrocloud/mikrocloud/internal/domain/tunnels"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/manager"
+)
+
+const (
+	// memoryLookback is how far back the latest memory sample is looked for,
+	// a few intervals of the metric sampler
+	memoryLookback = 2 * time.Minute
+
+	// certificateProbeInterval is how long the expiry read from a domain is
+	// reused before the domain is dialed again
+	certificateProbeInterval = 6 * time.Hour
+	certificateProbeTimeout  = 10 * time.Second
+)
+
+type ApplicationLister interface {
+	ListApplicationsByProject(ctx context.Context, projectID uuid.UUID) ([]*applications.Application, error)
+}
+
+type DeploymentSource interface {
+	GetLatestDeploymentByApplication(ctx context.Context, applicationID applications.ApplicationID) (*deployments.Deployment, error)
+}
+
+type DatabaseLister interface {
+	ListDatabases(ctx context.Context, projectID uuid.UUID) ([]*databases.Database, error)
+}
+
+type ContainerInspector interface {
+	InspectContainer(ctx context.Context, containerID string) (*manager.ContainerInfo, error)
+}
+
+type MetricSource interface {
+	GetMetricSeries(ctx context.Context, aggregation analyticsdb.MetricAggregation) ([]analyticsdb.AggregationResult, error)
+}
+
+type TunnelLister interface {
+	ListTunnelsByProject(ctx context.Context, projectID uuid.UUID) ([]*tunnels.CloudflareTunnel, error)
+}
+
+// condition is a subject a rule currently matches
+type condition struct {
+	fingerprint string
+	subject     string
+	message     string
+}
+
+// projectContainer is a running application or database container
+type projectContainer struct {
+	serviceID   uuid.UUID
+	name        string
+	containerID string
+}
+
+type restartSample struct {
+	at    time.Time
+	count int
+}
+
+type certificateProbe struct {
+	certificate *domains.Certificate
+	checkedAt   time.Time
+}
+
+// AlertEvaluator periodically evaluates the enabled alert rules of every
+// project. A condition must hold for the For duration of its rule before an
+// alert fires. A subject that keeps matching stays one firing alert, and the
+// alert resolves, with a notification, once the subject no longer matches.
+type AlertEvaluator struct {
+	alertService *AlertService
+	apps         ApplicationLister
+	deployments  DeploymentSource
+	databases    DatabaseLister
+	containers   ContainerInspector
+	metrics      MetricSource
+	tunnels      TunnelLister
+	interval     time.Duration
+
+	// pending holds when each rule and subject was first seen matching
+	pending map[string]time.Time
+	// restarts holds the restart counts of a container within RestartLoopWindow
+	restarts     map[string][]restartSample
+	certificates map[string]certificateProbe
+	stopCh       chan struct{}
+
+	// cycle caches lookups shared by the rules of one evaluation
+	cycle *evaluationCycle
+}
+
+type evaluationCycle struct {
+	containers map[uuid.UUID][]projectContainer
+	inspected  map[string]*manager.ContainerInfo
+}
+
+func NewAlertEvaluator(
+	alertService *AlertService,
+	appLister ApplicationLister,
+	deploymentSource DeploymentSource,
+	databaseLister DatabaseLister,
+	containerInspector ContainerInspector,
+	metricSource MetricSource,
+	tunnelLister TunnelLister,
+	interval time.Duration,
+) *AlertEvaluator {
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	return &AlertEvaluator{
+		alertService: alertService,
+		apps:         appLister,
+		deployments:  deploymentSource,
+		databases:    databaseLister,
+		containers:   containerInspector,
+		metrics:      metricSource,
+		tunnels:      tunnelLister,
+		interval:     interval,
+		pending:      make(map[string]time.Time),
+		restarts:     make(map[string][]restartSample),
+		certificates: make(map[string]certificateProbe),
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start evaluates the rules every interval until ctx is cancelled
+func (e *AlertEvaluator) Start(ctx context.Context) {
+	slog.Info("Starting alert evaluator", "interval", e.interval)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Alert evaluator stopped due to context cancellation")
+			return
+		case <-e.stopCh:
+			slog.Info("Alert evaluator stopped")
+			return
+		case <-ticker.C:
+			e.evaluate(ctx)
+		}
+	}
+}
+
+// Stop stops the evaluator
+func (e *AlertEvaluator) Stop() {
+	close(e.stopCh)
+}
+
+func (e *AlertEvaluator) evaluate(ctx context.Context) {
+	rules, err := e.alertService.ruleRepo.ListEnabled(ctx)
+	if err != nil {
+		slog.Error("Failed to list alert rules", "error", err)
+		return
+	}
+
+	firingAlerts, err := e.alertService.alertRepo.ListFiring(ctx)
+	if err != nil {
+		slog.Error("Failed to list firing alerts", "error", err)
+		return
+	}
+
+	firing := make(map[string]map[string]*alerts.Alert)
+	for _, alert := range firingAlerts {
+		ruleID := alert.RuleID().String()
+		if firing[ruleID] == nil {
+			firing[ruleID] = make(map[string]*alerts.Alert)
+		}
+		firing[ruleID][alert.Fingerprint()] = alert
+	}
+
+	e.cycle = &evaluationCycle{
+		containers: make(map[uuid.UUID][]projectContainer),
+		inspected:  make(map[string]*manager.ContainerInfo),
+	}
+	defer func() { e.cycle = nil }()
+
+	now := time.Now()
+	matched := make(map[string]bool)
+	evaluated := make(map[string]bool, len(rules))
+
+	for _, rule := range rules {
+		ruleID := rule.ID().String()
+
+		conditions, err := e.conditions(ctx, rule)
+		if err != nil {
+			// Keep the alerts of the rule as they are rather than resolving
+			// them because the state couldn't be read
+			slog.Warn("Failed to evaluate alert rule", "rule_id", ruleID, "type", rule.Type(), "error", err)
+			evaluated[ruleID] = true
+			for fingerprint := range firing[ruleID] {
+				matched[ruleID+"/"+fingerprint] = true
+			}
+			continue
+		}
+		evaluated[ruleID] = true
+
+		active := make(map[string]bool, len(conditions))
+		for _, cond := range conditions {
+			key := ruleID + "/" + cond.fingerprint
+			matched[key] = true
+			active[cond.fingerprint] = true
+
+			since, ok := e.pending[key]
+			if !ok {
+				since = now
+				e.pending[key] = now
+			}
+
+			if firing[ruleID][cond.fingerprint] != nil || now.Sub(since) < rule.For() {
+				continue
+			}
+
+			alert := alerts.NewAlert(rule, cond.fingerprint, cond.subject, cond.message)
+			if err := e.alertService.alertRepo.Create(ctx, alert); err != nil {
+				slog.Error("Failed to record alert", "rule_id", ruleID, "subject", cond.subject, "error", err)
+				continue
+			}
+			slog.Info("Alert firing", "rule_id", ruleID, "subject", cond.subject)
+			e.alertService.notify(ctx, rule, alert)
+		}
+
+		for fingerprint, alert := range firing[ruleID] {
+			if active[fingerprint] {
+				continue
+			}
+			alert.Resolve()
+			if err := e.alertService.alertRepo.Update(ctx, alert); err != nil {
+				slog.Error("Failed to resolve alert", "alert_id", alert.ID().String(), "error", err)
+				continue
+			}
+			slog.Info("Alert resolved", "rule_id", ruleID, "subject", alert.Subject())
+			e.alertService.notify(ctx, rule, alert)
+		}
+	}
+
+	// Alerts of rules that were disabled or deleted resolve without notifying
+	for ruleID, byFingerprint := range firing {
+		if evaluated[ruleID] {
+			continue
+		}
+		for _, alert := range byFingerprint {
+			alert.Resolve()
+			if err := e.alertService.alertRepo.Update(ctx, alert); err != nil {
+				slog.Error("Failed to resolve alert", "alert_id", alert.ID().String(), "error", err)
+			}
+		}
+	}
+
+	for key := range e.pending {
+		if !matched[key] {
+			delete(e.pending, key)
+		}
+	}
+
+	e.forgetStoppedContainers(now)
+}
+
+func (e *AlertEvaluator) conditions(ctx context.Context, rule *alerts.AlertRule) ([]condition, error) {
+	switch rule.Type() {
+	case alerts.RuleTypeDeploymentFailed:
+		return e.failedDeployments(ctx, rule)
+	case alerts.RuleTypeContainerRestartLoop:
+		return e.restartLoops(ctx, rule)
+	case alerts.RuleTypeMemoryUsage:
+		return e.memoryUsage(ctx, rule)
+	case alerts.RuleTypeCertificateExpiring:
+		return e.expiringCertificates(ctx, rule)
+	case alerts.RuleTypeTunnelUnhealthy:
+		return e.unhealthyTunnels(ctx, rule)
+	default:
+		return nil, fmt.Errorf("unsupported rule type: %s", rule.Type())
+	}
+}
+
+func (e *AlertEvaluator) failedDeployments(ctx context.Context, rule *alerts.AlertRule) ([]condition, error) {
+	apps, err := e.apps.ListApplicationsByProject(ctx, rule.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []condition
+	for _, app := range apps {
+		appID, err := uuid.Parse(app.ID().String())
+		if err != nil || !rule.AppliesTo(appID) {
+			continue
+		}
+
+		// Applications that were never deployed have no latest deployment
+		deployment, err := e.deployments.GetLatestDeploymentByApplication(ctx, app.ID())
+		if err != nil {
+			continue
+		}
+		if deployment.Status() != deployments.DeploymentStatusFailed {
+			continue
+		}
+
+		message := fmt.Sprintf("Deployment #%d of %s failed.", deployment.DeploymentNumber(), app.Name().String())
+		if deployment.ErrorMessage() != "" {
+			message += " " + deployment.ErrorMessage()
+		}
+
+		conditions = append(conditions, condition{
+			fingerprint: appID.String(),
+			subject:     app.Name().String(),
+			message:     message,
+		})
+	}
+
+	return conditions, nil
+}
+
+func (e *AlertEvaluator) restartLoops(ctx context.Context, rule *alerts.AlertRule) ([]condition, error) {
+	containers, err := e.projectContainers(ctx, rule.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []condition
+	for _, container := range containers {
+		if !rule.AppliesTo(container.serviceID) {
+			continue
+		}
+
+		samples := e.restartSamples(ctx, container.containerID)
+		if len(samples) == 0 {
+			continue
+		}
+
+		restarts := samples[len(samples)-1].count - samples[0].count
+		if float64(restarts) < rule.Threshold() {
+			continue
+		}
+
+		conditions = append(conditions, condition{
+			fingerprint: container.serviceID.String(),
+			subject:     container.name,
+			message:     fmt.Sprintf("%s restarted %d times in the last %s.", container.name, restarts, alerts.RestartLoopWindow),
+		})
+	}
+
+	return conditions, nil
+}
+
+// restartSamples inspects a container once per evaluation and returns its
+// restart counts within RestartLoopWindow, oldest first
+func (e *AlertEvaluator) restartSamples(ctx context.Context, containerID string) []restartSample {
+	if _, ok := e.cycle.inspected[containerID]; !ok {
+		info, err := e.containers.InspectContainer(ctx, containerID)
+		e.cycle.inspected[containerID] = info
+		if err != nil {
+			slog.Debug("Failed to inspect container for alerts", "container_id", containerID, "error", err)
+			return e.restarts[containerID]
+		}
+
+		now := time.Now()
+		samples := append(e.restarts[containerID], restartSample{at: now, count: info.RestartCount})
+		for len(samples) > 0 && now.Sub(samples[0].at) > alerts.RestartLoopWindow {
+			samples = samples[1:]
+		}
+		e.restarts[containerID] = samples
+	}
+
+	return e.restarts[containerID]
+}
+
+// forgetStoppedContainers drops the restart history of containers that were
+// not sampled for a whole window
+func (e *AlertEvaluator) forgetStoppedContainers(now time.Time) {
+	for containerID, samples := range e.restarts {
+		if len(samples) == 0 || now.Sub(samples[len(samples)-1].at) > alerts.RestartLoopWindow {
+			delete(e.restarts, containerID)
+		}
+	}
+}
+
+func (e *AlertEvaluator) memoryUsage(ctx context.Context, rule *alerts.AlertRule) ([]condition, error) {
+	containers, err := e.projectContainers(ctx, rule.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := e.latestMetric(ctx, rule, analytics.MetricContainerMemoryUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := e.latestMetric(ctx, rule, analytics.MetricContainerMemoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []condition
+	for _, container := range containers {
+		if !rule.AppliesTo(container.serviceID) {
+			continue
+		}
+
+		serviceID := container.serviceID.String()
+		used, limit := usage[serviceID], limits[serviceID]
+		if limit <= 0 {
+			continue
+		}
+
+		percent := used / limit * 100
+		if percent < rule.Threshold() {
+			continue
+		}
+
+		conditions = append(conditions, condition{
+			fingerprint: serviceID,
+			subject:     container.name,
+			message: fmt.Sprintf("%s uses %.1f%% of its memory limit (%s of %s), above the %.0f%% threshold.",
+				container.name, percent, formatBytes(used), formatBytes(limit), rule.Threshold()),
+		})
+	}
+
+	return conditions, nil
+}
+
+// latestMetric returns the most recent value of a container metric per service
+func (e *AlertEvaluator) latestMetric(ctx context.Context, rule *alerts.AlertRule, name string) (map[string]float64, error) {
+	now := time.Now()
+	points, err := e.metrics.GetMetricSeries(ctx, analyticsdb.MetricAggregation{
+		MetricName: name,
+		Function:   analyticsdb.Max,
+		GroupBy:    []string{"service_id"},
+		Tags:       map[string]string{"project_id": rule.ProjectID().String()},
+		TimeRange:  analyticsdb.TimeRange{Start: now.Add(-memoryLookback), End: now},
+		Interval:   time.Minute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Points are ordered by bucket, later buckets overwrite earlier ones
+	latest := make(map[string]float64)
+	for _, point := range points {
+		latest[point.GroupBy["service_id"]] = point.Value
+	}
+	return latest, nil
+}
+
+func (e *AlertEvaluator) expiringCertificates(ctx context.Context, rule *alerts.AlertRule) ([]condition, error) {
+	apps, err := e.apps.ListApplicationsByProject(ctx, rule.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(rule.Threshold())
+
+	var conditions []condition
+	for _, app := range apps {
+		appID, err := uuid.Parse(app.ID().String())
+		if err != nil || !rule.AppliesTo(appID) || app.Domain() == "" {
+			continue
+		}
+
+		certificate := e.certificate(ctx, app.Domain())
+		if certificate == nil || !certificate.ExpiresWithinDays(days) {
+			continue
+		}
+
+		message := fmt.Sprintf("The certificate of %s expires on %s.", app.Domain(), certificate.ExpiresAt().UTC().Format("2006-01-02"))
+		if certificate.IsExpired() {
+			message = fmt.Sprintf("The certificate of %s expired on %s.", app.Domain(), certificate.ExpiresAt().UTC().Format("2006-01-02"))
+		}
+
+		conditions = append(conditions, condition{
+			fingerprint: app.Domain(),
+			subject:     app.Domain(),
+			message:     message,
+		})
+	}
+
+	return conditions, nil
+}
+
+// certificate reads the certificate a domain serves. Certificates are issued
+// by the proxy rather than stored, so the expiry comes from the handshake.
+// Domains without a certificate of their own, such as those still served
+// the proxy's default certificate, return nil.
+func (e *AlertEvaluator) certificate(ctx context.Context, domain string) *domains.Certificate {
+	if probe, ok := e.certificates[domain]; ok && time.Since(probe.checkedAt) < certificateProbeInterval {
+		return probe.certificate
+	}
+
+	probe := certificateProbe{checkedAt: time.Now()}
+	defer func() { e.certificates[domain] = probe }()
+
+	dialCtx, cancel := context.WithTimeout(ctx, certificateProbeTimeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName: domain,
+		// Expired certificates must still be read to be reported
+		InsecureSkipVerify: true,
+	}}
+	conn, err := dialer.DialContext(dialCtx, "tcp", net.JoinHostPort(domain, "443"))
+	if err != nil {
+		slog.Debug("Failed to read certificate", "domain", domain, "error", err)
+		return nil
+	}
+	defer conn.Close()
+
+	peers := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(peers) == 0 || peers[0].VerifyHostname(domain) != nil {
+		return nil
+	}
+
+	issuer := domains.CertificateIssuerCustom
+	if peers[0].Issuer.String() == peers[0].Subject.String() {
+		issuer = domains.CertificateIssuerSelfSigned
+	}
+
+	probe.certificate = domains.NewCertificate(domains.NewDomainID(), issuer, peers[0].NotAfter)
+	return probe.certificate
+}
+
+func (e *AlertEvaluator) unhealthyTunnels(ctx context.Context, rule *alerts.AlertRule) ([]condition, error) {
+	projectTunnels, err := e.tunnels.ListTunnelsByProject(ctx, rule.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []condition
+	for _, tun := range projectTunnels {
+		// Stopped tunnels are expected to be down
+		if tun.Status() != tunnels.TunnelStatusRunning && tun.Status() != tunnels.TunnelStatusError {
+			continue
+		}
+		if tun.Status() != tunnels.TunnelStatusError && tun.HealthStatus() != tunnels.HealthStatusUnhealthy {
+			continue
+		}
+
+		message := fmt.Sprintf("Tunnel %s is unhealthy.", tun.Name().String())
+		if tun.ErrorMessage() != "" {
+			message += " " + tun.ErrorMessage()
+		}
+
+		conditions = append(conditions, condition{
+			fingerprint: tun.ID().String(),
+			subject:     tun.Name().String(),
+			message:     message,
+		})
+	}
+
+	return conditions, nil
+}
+
+// projectContainers lists the running application and database containers of
+// a project once per evaluation
+func (e *AlertEvaluator) projectContainers(ctx context.Context, projectID uuid.UUID) ([]projectContainer, error) {
+	if containers, ok := e.cycle.containers[projectID]; ok {
+		return containers, nil
+	}
+
+	apps, err := e.apps.ListApplicationsByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []projectContainer
+	for _, app := range apps {
+		appID, err := uuid.Parse(app.ID().String())
+		if err != nil {
+			continue
+		}
+
+		deployment, err := e.deployments.GetLatestDeploymentByApplication(ctx, app.ID())
+		if err != nil || deployment.Status() != deployments.DeploymentStatusRunning || deployment.ContainerID() == "" {
+			continue
+		}
+
+		containers = append(containers, projectContainer{
+			serviceID:   appID,
+			name:        app.Name().String(),
+			containerID: deployment.ContainerID(),
+		})
+	}
+
+	dbs, err := e.databases.ListDatabases(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, db := range dbs {
+		databaseID, err := uuid.Parse(db.ID().String())
+		if err != nil || db.Status() != databases.DatabaseStatusRunning || db.ContainerID() == "" {
+			continue
+		}
+
+		containers = append(containers, projectContainer{
+			serviceID:   databaseID,
+			name:        db.Name().String(),
+			containerID: db.ContainerID(),
+		})
+	}
+
+	e.cycle.containers[projectID] = containers
+	return containers, nil
+}
+
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[unit])
+}
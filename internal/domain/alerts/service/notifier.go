@@ -0,0 +1,277 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts"
+	"github.com/mikrocloud/mikrocloud/internal/domain/settings"
+)
+
+// notifyTimeout bounds a single delivery so a slow channel can't hold up the
+// evaluation of the other rules
+const notifyTimeout = 15 * time.Second
+
+// SMTPSettingsProvider returns the SMTP server email channels are sent through
+type SMTPSettingsProvider interface {
+	GetSMTPSettings() (*settings.SMTPSettings, error)
+}
+
+// Notification is what a channel receives when an alert fires or resolves
+type Notification struct {
+	AlertID    string             `json:"alert_id"`
+	Status     alerts.AlertStatus `json:"status"`
+	ProjectID  string             `json:"project_id"`
+	RuleID     string             `json:"rule_id"`
+	RuleName   string             `json:"rule_name"`
+	RuleType   alerts.RuleType    `json:"rule_type"`
+	Subject    string             `json:"subject"`
+	Message    string             `json:"message"`
+	StartedAt  time.Time          `json:"started_at"`
+	ResolvedAt *time.Time         `json:"resolved_at,omitempty"`
+}
+
+func newNotification(rule *alerts.AlertRule, alert *alerts.Alert) Notification {
+	return Notification{
+		AlertID:    alert.ID().String(),
+		Status:     alert.Status(),
+		ProjectID:  alert.ProjectID().String(),
+		RuleID:     rule.ID().String(),
+		RuleName:   rule.Name(),
+		RuleType:   rule.Type(),
+		Subject:    alert.Subject(),
+		Message:    alert.Message(),
+		StartedAt:  alert.StartedAt(),
+		ResolvedAt: alert.ResolvedAt(),
+	}
+}
+
+// Title is a one line summary such as "[FIRING] High memory: api"
+func (n Notification) Title() string {
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(n.Status)), n.RuleName, n.Subject)
+}
+
+// Text is the plain text body used by email and chat channels
+func (n Notification) Text() string {
+	var b strings.Builder
+	b.WriteString(n.Title())
+	b.WriteString("\n\n")
+	b.WriteString(n.Message)
+	b.WriteString("\n\nStarted: ")
+	b.WriteString(n.StartedAt.UTC().Format(time.RFC1123))
+	if n.ResolvedAt != nil {
+		b.WriteString("\nResolved: ")
+		b.WriteString(n.ResolvedAt.UTC().Format(time.RFC1123))
+	}
+	return b.String()
+}
+
+// Notifier delivers notifications to email, generic webhook, Slack and
+// Discord channels
+type Notifier struct {
+	smtp   SMTPSettingsProvider
+	client *http.Client
+}
+
+func NewNotifier(smtpSettings SMTPSettingsProvider) *Notifier {
+	return &Notifier{
+		smtp:   smtpSettings,
+		client: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+func (n *Notifier) Send(ctx context.Context, channel *alerts.NotificationChannel, notification Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	config := channel.Config()
+
+	switch channel.Type() {
+	case alerts.ChannelTypeEmail:
+		return n.sendEmail(ctx, config.Recipients, notification)
+	case alerts.ChannelTypeWebhook:
+		return n.sendWebhook(ctx, config, notification)
+	case alerts.ChannelTypeSlack:
+		return n.postJSON(ctx, config.URL, map[string]string{"text": notification.Text()}, nil)
+	case alerts.ChannelTypeDiscord:
+		// Discord rejects messages longer than 2000 characters
+		text := notification.Text()
+		if runes := []rune(text); len(runes) > 2000 {
+			text = string(runes[:1997]) + "..."
+		}
+		return n.postJSON(ctx, config.URL, map[string]string{"content": text}, nil)
+	default:
+		return fmt.Errorf("unsupported channel type: %s", channel.Type())
+	}
+}
+
+// sendWebhook posts the notification as JSON. With a secret the body is signed
+// with HMAC-SHA256 in the X-Mikrocloud-Signature header, formatted like the
+// GitHub webhook signatures ("sha256=<hex>").
+func (n *Notifier) sendWebhook(ctx context.Context, config alerts.ChannelConfig, notification Notification) error {
+	payload := struct {
+		Event string `json:"event"`
+		Notification
+	}{
+		Event:        "alert." + string(notification.Status),
+		Notification: notification,
+	}
+
+	var sign func([]byte) map[string]string
+	if config.Secret != "" {
+		sign = func(body []byte) map[string]string {
+			mac := hmac.New(sha256.New, []byte(config.Secret))
+			mac.Write(body)
+			return map[string]string{"X-Mikrocloud-Signature": "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+		}
+	}
+
+	return n.postJSON(ctx, config.URL, payload, sign)
+}
+
+func (n *Notifier) postJSON(ctx context.Context, url string, payload any, sign func([]byte) map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mikrocloud-Alerts")
+	if sign != nil {
+		for key, value := range sign(body) {
+			req.Header.Set(key, value)
+		}
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification rejected with status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
+
+func (n *Notifier) sendEmail(ctx context.Context, recipients []string, notification Notification) error {
+	return n.SendEmail(ctx, recipients, notification.Title(), "text/plain; charset=utf-8", notification.Text())
+}
+
+// SendEmail delivers a message through the SMTP server of the instance
+// settings. Port 465 uses implicit TLS, other ports upgrade with STARTTLS
+// when offered.
+func (n *Notifier) SendEmail(ctx context.Context, recipients []string, subject, contentType, body string) error {
+	cfg, err := n.smtp.GetSMTPSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get SMTP settings: %w", err)
+	}
+	if !cfg.Enabled || cfg.Host == "" || cfg.FromEmail == "" {
+		return fmt.Errorf("SMTP is not configured, enable it in the instance settings")
+	}
+
+	from := mail.Address{Name: cfg.FromName, Address: cfg.FromEmail}
+
+	// Recipients may carry a display name, "Ops <ops@example.com>", which
+	// only belongs in the header
+	to := make([]*mail.Address, 0, len(recipients))
+	header := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		to = append(to, address)
+		header = append(header, address.String())
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(header, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", contentType)
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	var conn net.Conn
+	if cfg.Port == 465 {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if cfg.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient.Address, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts"
+	"github.com/mikrocloud/mikrocloud/internal/domain/alerts/repository"
+)
+
+// maxAlertsListed caps the alert history returned for a project
+const maxAlertsListed = 500
+
+var (
+	ErrRuleNotFound    = errors.New("alert rule not found")
+	ErrChannelNotFound = errors.New("notification channel not found")
+)
+
+// AlertService manages the alert rules and notification channels of projects
+// and routes the alerts raised by the evaluator to their channels
+type AlertService struct {
+	ruleRepo    repository.AlertRuleRepository
+	channelRepo repository.NotificationChannelRepository
+	alertRepo   repository.AlertRepository
+	notifier    *Notifier
+}
+
+func NewAlertService(
+	ruleRepo repository.AlertRuleRepository,
+	channelRepo repository.NotificationChannelRepository,
+	alertRepo repository.AlertRepository,
+	notifier *Notifier,
+) *AlertService {
+	return &AlertService{
+		ruleRepo:    ruleRepo,
+		channelRepo: channelRepo,
+		alertRepo:   alertRepo,
+		notifier:    notifier,
+	}
+}
+
+type CreateRuleCommand struct {
+	ProjectID  uuid.UUID
+	Name       string
+	Type       alerts.RuleType
+	ServiceID  *uuid.UUID
+	Threshold  float64
+	For        time.Duration
+	ChannelIDs []string
+}
+
+func (s *AlertService) CreateRule(ctx context.Context, cmd CreateRuleCommand) (*alerts.AlertRule, error) {
+	channelIDs, err := s.projectChannelIDs(ctx, cmd.ProjectID, cmd.ChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := alerts.NewAlertRule(cmd.ProjectID, cmd.Name, cmd.Type, cmd.ServiceID, cmd.Threshold, cmd.For, channelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetRule returns a rule of a project, ErrRuleNotFound when it belongs to
+// another project
+func (s *AlertService) GetRule(ctx context.Context, projectID uuid.UUID, ruleID string) (*alerts.AlertRule, error) {
+	id, err := alerts.AlertRuleIDFromString(ruleID)
+	if err != nil {
+		return nil, ErrRuleNotFound
+	}
+
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+
+	if rule.ProjectID() != projectID {
+		return nil, ErrRuleNotFound
+	}
+
+	return rule, nil
+}
+
+func (s *AlertService) ListRules(ctx context.Context, projectID uuid.UUID) ([]*alerts.AlertRule, error) {
+	rules, err := s.ruleRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+type UpdateRuleCommand struct {
+	Name       string
+	ServiceID  *uuid.UUID
+	Threshold  float64
+	For        time.Duration
+	ChannelIDs []string
+	Enabled    bool
+}
+
+func (s *AlertService) UpdateRule(ctx context.Context, projectID uuid.UUID, ruleID string, cmd UpdateRuleCommand) (*alerts.AlertRule, error) {
+	rule, err := s.GetRule(ctx, projectID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	channelIDs, err := s.projectChannelIDs(ctx, projectID, cmd.ChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := cmd.Threshold
+	if threshold == 0 {
+		threshold = rule.Type().DefaultThreshold()
+	}
+
+	if err := rule.Update(cmd.Name, cmd.ServiceID, threshold, cmd.For, channelIDs, cmd.Enabled); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// DeleteRule removes a rule together with its alerts
+func (s *AlertService) DeleteRule(ctx context.Context, projectID uuid.UUID, ruleID string) error {
+	rule, err := s.GetRule(ctx, projectID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.Delete(ctx, rule.ID()); err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return nil
+}
+
+func (s *AlertService) CreateChannel(ctx context.Context, projectID uuid.UUID, name string, channelType alerts.ChannelType, config alerts.ChannelConfig) (*alerts.NotificationChannel, error) {
+	channel, err := alerts.NewNotificationChannel(projectID, name, channelType, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.channelRepo.Create(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to create notification channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// GetChannel returns a channel of a project, ErrChannelNotFound when it
+// belongs to another project
+func (s *AlertService) GetChannel(ctx context.Context, projectID uuid.UUID, channelID string) (*alerts.NotificationChannel, error) {
+	id, err := alerts.ChannelIDFromString(channelID)
+	if err != nil {
+		return nil, ErrChannelNotFound
+	}
+
+	channel, err := s.channelRepo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrChannelNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channel: %w", err)
+	}
+
+	if channel.ProjectID() != projectID {
+		return nil, ErrChannelNotFound
+	}
+
+	return channel, nil
+}
+
+func (s *AlertService) ListChannels(ctx context.Context, projectID uuid.UUID) ([]*alerts.NotificationChannel, error) {
+	channels, err := s.channelRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification channels: %w", err)
+	}
+	return channels, nil
+}
+
+// UpdateChannel changes a channel. An empty secret keeps the current one, the
+// API never returns it so clients can't send it back.
+func (s *AlertService) UpdateChannel(ctx context.Context, projectID uuid.UUID, channelID, name string, config alerts.ChannelConfig, enabled bool) (*alerts.NotificationChannel, error) {
+	channel, err := s.GetChannel(ctx, projectID, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Secret == "" {
+		config.Secret = channel.Config().Secret
+	}
+
+	if err := channel.Update(name, config, enabled); err != nil {
+		return nil, err
+	}
+
+	if err := s.channelRepo.Update(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to update notification channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// DeleteChannel removes a channel and drops it from the rules routing to it
+func (s *AlertService) DeleteChannel(ctx context.Context, projectID uuid.UUID, channelID string) error {
+	channel, err := s.GetChannel(ctx, projectID, channelID)
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.ruleRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if !slices.Contains(rule.ChannelIDs(), channel.ID()) {
+			continue
+		}
+
+		remaining := slices.DeleteFunc(slices.Clone(rule.ChannelIDs()), func(id alerts.ChannelID) bool {
+			return id == channel.ID()
+		})
+		if err := rule.Update(rule.Name(), rule.ServiceID(), rule.Threshold(), rule.For(), remaining, rule.Enabled()); err != nil {
+			return err
+		}
+		if err := s.ruleRepo.Update(ctx, rule); err != nil {
+			return fmt.Errorf("failed to update alert rule: %w", err)
+		}
+	}
+
+	if err := s.channelRepo.Delete(ctx, channel.ID()); err != nil {
+		return fmt.Errorf("failed to delete notification channel: %w", err)
+	}
+	return nil
+}
+
+// TestChannel sends a sample notification so a channel can be checked before
+// a real alert relies on it
+func (s *AlertService) TestChannel(ctx context.Context, projectID uuid.UUID, channelID string) error {
+	channel, err := s.GetChannel(ctx, projectID, channelID)
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, channel, Notification{
+		AlertID:   "test",
+		Status:    alerts.AlertStatusFiring,
+		ProjectID: projectID.String(),
+		RuleName:  "Test notification",
+		Subject:   channel.Name(),
+		Message:   "This is a test notification from Mikrocloud. Alerts routed to this channel will arrive like this.",
+		StartedAt: time.Now(),
+	})
+}
+
+// ListAlerts returns the alerts of a project, newest first
+func (s *AlertService) ListAlerts(ctx context.Context, projectID uuid.UUID, status *alerts.AlertStatus, limit int) ([]*alerts.Alert, error) {
+	if limit <= 0 || limit > maxAlertsListed {
+		limit = maxAlertsListed
+	}
+
+	result, err := s.alertRepo.ListByProject(ctx, projectID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return result, nil
+}
+
+// notify sends an alert to the channels of its rule, or to every enabled
+// channel of the project when the rule doesn't pick any. Failures are logged
+// so one broken channel doesn't keep the others from being notified.
+func (s *AlertService) notify(ctx context.Context, rule *alerts.AlertRule, alert *alerts.Alert) {
+	channels, err := s.channelRepo.ListByProject(ctx, rule.ProjectID())
+	if err != nil {
+		slog.Error("Failed to list notification channels", "project_id", rule.ProjectID(), "error", err)
+		return
+	}
+
+	notification := newNotification(rule, alert)
+	for _, channel := range channels {
+		if !channel.Enabled() {
+			continue
+		}
+		if len(rule.ChannelIDs()) > 0 && !slices.Contains(rule.ChannelIDs(), channel.ID()) {
+			continue
+		}
+
+		if err := s.notifier.Send(ctx, channel, notification); err != nil {
+			slog.Warn("Failed to send alert notification",
+				"alert_id", alert.ID().String(),
+				"channel_id", channel.ID().String(),
+				"channel_type", channel.Type(),
+				"error", err)
+		}
+	}
+}
+
+// projectChannelIDs parses channel IDs and checks they belong to the project
+func (s *AlertService) projectChannelIDs(ctx context.Context, projectID uuid.UUID, raw []string) ([]alerts.ChannelID, error) {
+	channelIDs := make([]alerts.ChannelID, 0, len(raw))
+	for _, id := range raw {
+		channel, err := s.GetChannel(ctx, projectID, id)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", id, err)
+		}
+		if !slices.Contains(channelIDs, channel.ID()) {
+			channelIDs = append(channelIDs, channel.ID())
+		}
+	}
+	return channelIDs, nil
+}
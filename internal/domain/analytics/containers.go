@@ -0,0 +1,25 @@
+package analytics
+
+// Metrics sampled from the application and database containers. Network and
+// block IO are recorded as the bytes transferred since the previous sample,
+// so summing them over a bucket gives the traffic of the bucket.
+const (
+	MetricContainerCPU          = "container_cpu_percent"
+	MetricContainerMemoryUsage  = "container_memory_usage_bytes"
+	MetricContainerMemoryLimit  = "container_memory_limit_bytes"
+	MetricContainerNetworkRx    = "container_network_rx_bytes"
+	MetricContainerNetworkTx    = "container_network_tx_bytes"
+	MetricContainerBlockRead    = "container_block_read_bytes"
+	MetricContainerBlockWritten = "container_block_write_bytes"
+)
+
+// ContainerMetrics lists the container metric names
+var ContainerMetrics = []string{
+	MetricContainerCPU,
+	MetricContainerMemoryUsage,
+	MetricContainerMemoryLimit,
+	MetricContainerNetworkRx,
+	MetricContainerNetworkTx,
+	MetricContainerBlockRead,
+	MetricContainerBlockWritten,
+}
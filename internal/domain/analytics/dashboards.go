@@ -0,0 +1,164 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// WidgetSourceMetric binds a widget to a MetricQuery, the query of the data
+	// source is the metric name
+	WidgetSourceMetric = "metric"
+
+	// DefaultWidgetRange is how far back a widget looks without a "range" param
+	DefaultWidgetRange = 24 * time.Hour
+	// MaxWidgetRange matches how long metrics are kept
+	MaxWidgetRange = 30 * 24 * time.Hour
+
+	maxDashboardWidgets = 50
+	maxDashboardColumns = 24
+)
+
+// Update replaces the name, layout and widgets of a dashboard. Widgets sent
+// without an ID are given one.
+func (d *Dashboard) Update(name DashboardName, layout DashboardLayout, widgets []DashboardWidget) error {
+	if layout.Columns == 0 {
+		layout.Columns = 12
+	}
+	if layout.Type == "" {
+		layout.Type = "grid"
+	}
+	if layout.Columns < 1 || layout.Columns > maxDashboardColumns {
+		return fmt.Errorf("layout columns must be between 1 and %d", maxDashboardColumns)
+	}
+
+	if len(widgets) > maxDashboardWidgets {
+		return fmt.Errorf("a dashboard cannot have more than %d widgets", maxDashboardWidgets)
+	}
+
+	ids := make(map[string]bool, len(widgets))
+	for i := range widgets {
+		widget := &widgets[i]
+		if widget.ID == "" {
+			widget.ID = uuid.Must(uuid.NewV7()).String()
+		}
+		if ids[widget.ID] {
+			return fmt.Errorf("duplicate widget ID: %s", widget.ID)
+		}
+		ids[widget.ID] = true
+
+		if err := widget.validate(layout); err != nil {
+			return fmt.Errorf("invalid widget %q: %w", widget.Title, err)
+		}
+	}
+
+	d.name = name
+	d.layout = layout
+	d.widgets = widgets
+	d.updatedAt = time.Now()
+	return nil
+}
+
+func (w *DashboardWidget) validate(layout DashboardLayout) error {
+	switch w.Type {
+	case WidgetTypeChart, WidgetTypeGauge, WidgetTypeCounter, WidgetTypeTable, WidgetTypeText:
+	default:
+		return fmt.Errorf("unknown widget type: %s", w.Type)
+	}
+
+	if len(w.Title) > 128 {
+		return fmt.Errorf("title cannot exceed 128 characters")
+	}
+	if w.Size.Width < 1 || w.Size.Height < 1 {
+		return fmt.Errorf("size must be at least 1x1")
+	}
+	if w.Position.X < 0 || w.Position.Y < 0 || w.Position.X+w.Size.Width > layout.Columns {
+		return fmt.Errorf("widget does not fit the %d columns of the layout", layout.Columns)
+	}
+
+	if w.Type == WidgetTypeText {
+		return nil
+	}
+
+	if w.DataSource.Type == "" {
+		w.DataSource.Type = WidgetSourceMetric
+	}
+	if w.DataSource.Type != WidgetSourceMetric {
+		return fmt.Errorf("unsupported data source: %s", w.DataSource.Type)
+	}
+	if w.DataSource.Refresh != "" {
+		if refresh, err := time.ParseDuration(w.DataSource.Refresh); err != nil || refresh < 5*time.Second {
+			return fmt.Errorf("refresh must be a duration of at least 5s")
+		}
+	}
+
+	if _, err := w.DataSource.MetricQuery(uuid.Nil, time.Now()); err != nil {
+		return err
+	}
+	_, err := w.DataSource.Function()
+	return err
+}
+
+// MetricQuery binds a metric data source to the metrics of a project ending
+// at end. Params: service_id, range (a duration, defaults to 24h) and limit
+// (rows of table widgets).
+func (s WidgetDataSource) MetricQuery(projectID uuid.UUID, end time.Time) (MetricQuery, error) {
+	name, err := NewMetricName(s.Query)
+	if err != nil {
+		return MetricQuery{}, err
+	}
+
+	query := MetricQuery{
+		ProjectID: projectID,
+		Name:      &name,
+		EndTime:   &end,
+	}
+
+	if value, ok := s.Params["service_id"]; ok {
+		raw, _ := value.(string)
+		serviceID, err := uuid.Parse(raw)
+		if err != nil {
+			return MetricQuery{}, fmt.Errorf("invalid service_id param")
+		}
+		query.ServiceID = &serviceID
+	}
+
+	period := DefaultWidgetRange
+	if value, ok := s.Params["range"]; ok {
+		raw, _ := value.(string)
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < time.Minute || parsed > MaxWidgetRange {
+			return MetricQuery{}, fmt.Errorf("range must be a duration between 1m and %s", MaxWidgetRange)
+		}
+		period = parsed
+	}
+	start := end.Add(-period)
+	query.StartTime = &start
+
+	if value, ok := s.Params["limit"]; ok {
+		limit, _ := value.(float64)
+		if limit < 1 || limit > 1000 {
+			return MetricQuery{}, fmt.Errorf("limit must be between 1 and 1000")
+		}
+		query.Limit = int(limit)
+	}
+
+	return query, nil
+}
+
+// Function returns the aggregation of the "function" param, avg by default
+func (s WidgetDataSource) Function() (string, error) {
+	value, ok := s.Params["function"]
+	if !ok {
+		return "avg", nil
+	}
+
+	function, _ := value.(string)
+	switch function {
+	case "avg", "sum", "min", "max", "count":
+		return function, nil
+	}
+	return "", fmt.Errorf("function must be one of avg, sum, min, max or count")
+}
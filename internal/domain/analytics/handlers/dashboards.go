@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics/service"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type DashboardHandler struct {
+	dashboardService *service.DashboardService
+	projectService   *projectsService.ProjectService
+	validator        *validator.Validate
+}
+
+func NewDashboardHandler(dashboardService *service.DashboardService, projectService *projectsService.ProjectService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+		projectService:   projectService,
+		validator:        validator.New(),
+	}
+}
+
+type DashboardRequest struct {
+	Name string `json:"name" validate:"required,max=128"`
+	// Layout defaults to a 12 column grid
+	Layout    analytics.DashboardLayout   `json:"layout"`
+	Widgets   []analytics.DashboardWidget `json:"widgets"`
+	IsDefault bool                        `json:"is_default"`
+}
+
+type DashboardResponse struct {
+	ID        string                      `json:"id"`
+	ProjectID string                      `json:"project_id"`
+	Name      string                      `json:"name"`
+	Layout    analytics.DashboardLayout   `json:"layout"`
+	Widgets   []analytics.DashboardWidget `json:"widgets"`
+	IsDefault bool                        `json:"is_default"`
+	CreatedAt time.Time                   `json:"created_at"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+}
+
+type ListDashboardsResponse struct {
+	Dashboards []DashboardResponse `json:"dashboards"`
+}
+
+type MetricSampleResponse struct {
+	ServiceID *string           `json:"service_id,omitempty"`
+	Value     float64           `json:"value"`
+	Unit      string            `json:"unit"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+type WidgetDataResponse struct {
+	WidgetID string                          `json:"widget_id"`
+	Value    *float64                        `json:"value,omitempty"`
+	Points   []analyticsdb.AggregationResult `json:"points,omitempty"`
+	Rows     []MetricSampleResponse          `json:"rows,omitempty"`
+	Error    string                          `json:"error,omitempty"`
+}
+
+type DashboardDataResponse struct {
+	DashboardID string               `json:"dashboard_id"`
+	To          string               `json:"to"`
+	Widgets     []WidgetDataResponse `json:"widgets"`
+}
+
+func toDashboardResponse(dashboard *analytics.Dashboard) DashboardResponse {
+	widgets := dashboard.Widgets()
+	if widgets == nil {
+		widgets = []analytics.DashboardWidget{}
+	}
+
+	return DashboardResponse{
+		ID:        dashboard.ID().String(),
+		ProjectID: dashboard.ProjectID().String(),
+		Name:      dashboard.Name().String(),
+		Layout:    dashboard.Layout(),
+		Widgets:   widgets,
+		IsDefault: dashboard.IsDefault(),
+		CreatedAt: dashboard.CreatedAt(),
+		UpdatedAt: dashboard.UpdatedAt(),
+	}
+}
+
+func (h *DashboardHandler) ListDashboards(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	dashboards, err := h.dashboardService.ListDashboards(r.Context(), projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list dashboards")
+		return
+	}
+
+	response := ListDashboardsResponse{Dashboards: make([]DashboardResponse, 0, len(dashboards))}
+	for _, dashboard := range dashboards {
+		response.Dashboards = append(response.Dashboards, toDashboardResponse(dashboard))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *DashboardHandler) CreateDashboard(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	cmd, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.dashboardService.CreateDashboard(r.Context(), projectID, cmd)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create dashboard: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toDashboardResponse(dashboard))
+}
+
+func (h *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(r.Context(), projectID, chi.URLParam(r, "dashboard_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toDashboardResponse(dashboard))
+}
+
+func (h *DashboardHandler) UpdateDashboard(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	cmd, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.dashboardService.UpdateDashboard(r.Context(), projectID, chi.URLParam(r, "dashboard_id"), cmd)
+	if errors.Is(err, service.ErrDashboardNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update dashboard: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toDashboardResponse(dashboard))
+}
+
+func (h *DashboardHandler) DeleteDashboard(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.dashboardService.DeleteDashboard(r.Context(), projectID, chi.URLParam(r, "dashboard_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDashboardData resolves the metric queries of the widgets of a dashboard.
+// Widgets look back from the RFC3339 "to" query parameter, now by default.
+func (h *DashboardHandler) GetDashboardData(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(r.Context(), projectID, chi.URLParam(r, "dashboard_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	end := time.Now()
+	if to := r.URL.Query().Get("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'to' time, expected RFC3339")
+			return
+		}
+		end = parsed
+	}
+
+	data := h.dashboardService.GetDashboardData(r.Context(), dashboard, end)
+
+	response := DashboardDataResponse{
+		DashboardID: dashboard.ID().String(),
+		To:          end.Format(time.RFC3339),
+		Widgets:     make([]WidgetDataResponse, 0, len(data)),
+	}
+	for _, widget := range data {
+		item := WidgetDataResponse{
+			WidgetID: widget.WidgetID,
+			Value:    widget.Value,
+			Points:   widget.Points,
+			Error:    widget.Error,
+		}
+		for _, metric := range widget.Rows {
+			sample := MetricSampleResponse{
+				Value:     metric.Value(),
+				Unit:      string(metric.Unit()),
+				Tags:      metric.Tags(),
+				Timestamp: metric.Timestamp(),
+			}
+			if metric.ServiceID() != nil {
+				serviceID := metric.ServiceID().String()
+				sample.ServiceID = &serviceID
+			}
+			item.Rows = append(item.Rows, sample)
+		}
+		response.Widgets = append(response.Widgets, item)
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *DashboardHandler) decodeRequest(w http.ResponseWriter, r *http.Request) (service.DashboardCommand, bool) {
+	var req DashboardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return service.DashboardCommand{}, false
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return service.DashboardCommand{}, false
+	}
+
+	return service.DashboardCommand{
+		Name:      req.Name,
+		Layout:    req.Layout,
+		Widgets:   req.Widgets,
+		IsDefault: req.IsDefault,
+	}, true
+}
+
+func (h *DashboardHandler) getProject(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return uuid.Nil, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return uuid.Nil, false
+	}
+
+	return projectID, true
+}
+
+func (h *DashboardHandler) sendLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrDashboardNotFound):
+		utils.SendError(w, http.StatusNotFound, "dashboard_not_found", "Dashboard not found")
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	applicationsService "github.com/mikrocloud/mikrocloud/internal/domain/applications/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+const (
+	defaultStatsRange = 24 * time.Hour
+
+	// maxSeriesPoints caps the buckets of a metric series, the interval
+	// defaults to the range divided by it
+	maxSeriesPoints   = 300
+	minSeriesInterval = time.Minute
+)
+
+type AnalyticsHandler struct {
+	analyticsService *service.AnalyticsService
+	appService       *applicationsService.ApplicationService
+}
+
+func NewAnalyticsHandler(analyticsService *service.AnalyticsService, appService *applicationsService.ApplicationService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		appService:       appService,
+	}
+}
+
+// GetRequestStats returns the request analytics of an application.
+// The time range is given by the RFC3339 "from" and "to" query parameters and
+// defaults to the last 24 hours.
+func (h *AnalyticsHandler) GetRequestStats(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	end := time.Now()
+	if to := r.URL.Query().Get("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'to' time, expected RFC3339")
+			return
+		}
+		end = parsed
+	}
+
+	start := end.Add(-defaultStatsRange)
+	if from := r.URL.Query().Get("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'from' time, expected RFC3339")
+			return
+		}
+		start = parsed
+	}
+
+	topPaths := 10
+	if top := r.URL.Query().Get("top"); top != "" {
+		parsed, err := strconv.Atoi(top)
+		if err != nil || parsed < 1 || parsed > 100 {
+			utils.SendError(w, http.StatusBadRequest, "invalid_top", "top must be between 1 and 100")
+			return
+		}
+		topPaths = parsed
+	}
+
+	appID, err := uuid.Parse(app.ID().String())
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_application_id", "Invalid application ID")
+		return
+	}
+
+	stats, err := h.analyticsService.GetRequestStats(r.Context(), appID, start, end, topPaths)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "stats_failed", "Failed to get request analytics: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, stats)
+}
+
+type MetricSeriesResponse struct {
+	Metric          string                          `json:"metric"`
+	Function        analyticsdb.AggregationFunc     `json:"function"`
+	IntervalSeconds int64                           `json:"interval_seconds"`
+	From            string                          `json:"from"`
+	To              string                          `json:"to"`
+	Points          []analyticsdb.AggregationResult `json:"points"`
+}
+
+// GetMetricSeries returns a container metric of a project downsampled to a
+// time series.
+//
+// Query parameters: name (one of the container metrics), service_id (an
+// application or database), from and to (RFC3339, defaults to the last 24
+// hours), interval (a duration such as "5m", defaults to the range split in
+// 300 buckets), function (avg, sum, min, max or count; network and block IO
+// default to sum, other metrics to avg) and group_by=service_id.
+func (h *AnalyticsHandler) GetMetricSeries(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return
+	}
+
+	params := r.URL.Query()
+
+	name := params.Get("name")
+	if !slices.Contains(analytics.ContainerMetrics, name) {
+		utils.SendError(w, http.StatusBadRequest, "invalid_metric", "Unknown metric name")
+		return
+	}
+
+	aggregation := analyticsdb.MetricAggregation{
+		MetricName: name,
+		Function:   analyticsdb.Avg,
+		Tags:       map[string]string{"project_id": projectID.String()},
+	}
+
+	switch name {
+	case analytics.MetricContainerNetworkRx, analytics.MetricContainerNetworkTx,
+		analytics.MetricContainerBlockRead, analytics.MetricContainerBlockWritten:
+		aggregation.Function = analyticsdb.Sum
+	}
+
+	if function := params.Get("function"); function != "" {
+		parsed := analyticsdb.AggregationFunc(function)
+		switch parsed {
+		case analyticsdb.Sum, analyticsdb.Avg, analyticsdb.Min, analyticsdb.Max, analyticsdb.Count:
+			aggregation.Function = parsed
+		default:
+			utils.SendError(w, http.StatusBadRequest, "invalid_function", "function must be one of avg, sum, min, max or count")
+			return
+		}
+	}
+
+	if serviceID := params.Get("service_id"); serviceID != "" {
+		parsed, err := uuid.Parse(serviceID)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_service_id", "Invalid service ID")
+			return
+		}
+		aggregation.Tags["service_id"] = parsed.String()
+	}
+
+	if groupBy := params.Get("group_by"); groupBy != "" {
+		if groupBy != "service_id" {
+			utils.SendError(w, http.StatusBadRequest, "invalid_group_by", "Series can only be grouped by service_id")
+			return
+		}
+		aggregation.GroupBy = []string{groupBy}
+	}
+
+	end := time.Now()
+	if to := params.Get("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'to' time, expected RFC3339")
+			return
+		}
+		end = parsed
+	}
+
+	start := end.Add(-defaultStatsRange)
+	if from := params.Get("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'from' time, expected RFC3339")
+			return
+		}
+		start = parsed
+	}
+	aggregation.TimeRange = analyticsdb.TimeRange{Start: start, End: end}
+
+	aggregation.Interval = max(end.Sub(start)/maxSeriesPoints, minSeriesInterval).Truncate(time.Second)
+	if interval := params.Get("interval"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed < time.Second {
+			utils.SendError(w, http.StatusBadRequest, "invalid_interval", "interval must be a duration of at least 1s")
+			return
+		}
+		if end.Sub(start)/parsed > 10*maxSeriesPoints {
+			utils.SendError(w, http.StatusBadRequest, "invalid_interval", "interval is too small for the time range")
+			return
+		}
+		aggregation.Interval = parsed
+	}
+
+	points, err := h.analyticsService.GetMetricSeries(r.Context(), aggregation)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "metrics_failed", "Failed to get metrics: "+err.Error())
+		return
+	}
+
+	if points == nil {
+		points = []analyticsdb.AggregationResult{}
+	}
+
+	utils.SendJSON(w, http.StatusOK, MetricSeriesResponse{
+		Metric:          name,
+		Function:        aggregation.Function,
+		IntervalSeconds: int64(aggregation.Interval / time.Second),
+		From:            start.Format(time.RFC3339),
+		To:              end.Format(time.RFC3339),
+		Points:          points,
+	})
+}
+
+func (h *AnalyticsHandler) getApplication(w http.ResponseWriter, r *http.Request) (*applications.Application, bool) {
+	appID, err := applications.ApplicationIDFromString(chi.URLParam(r, "application_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_application_id", "Invalid application ID")
+		return nil, false
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return nil, false
+	}
+
+	app, err := h.appService.GetApplication(r.Context(), appID)
+	if err != nil || app.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "application_not_found", "Application not found")
+		return nil, false
+	}
+
+	return app, true
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+)
+
+// RegisterApplicationAnalyticsRoutes registers the analytics routes of an application
+func RegisterApplicationAnalyticsRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewAnalyticsHandler(deps.AnalyticsService, deps.ApplicationService)
+
+	r.Route("/analytics", func(r chi.Router) {
+		r.Get("/requests", handler.GetRequestStats)
+	})
+}
+
+// RegisterProjectMetricsRoutes registers the container metric routes of a project
+func RegisterProjectMetricsRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewAnalyticsHandler(deps.AnalyticsService, deps.ApplicationService)
+
+	r.Get("/metrics", handler.GetMetricSeries)
+}
+
+// RegisterDashboardRoutes registers the dashboard and scheduled report routes
+// of a project
+func RegisterDashboardRoutes(r chi.Router, deps *deps.Dependencies) {
+	dashboardHandler := NewDashboardHandler(deps.DashboardService, deps.ProjectService)
+	reportHandler := NewReportHandler(deps.ReportService, deps.ProjectService)
+
+	r.Route("/dashboards", func(r chi.Router) {
+		r.Get("/", dashboardHandler.ListDashboards)
+		r.Post("/", dashboardHandler.CreateDashboard)
+		r.Get("/{dashboard_id}", dashboardHandler.GetDashboard)
+		r.Put("/{dashboard_id}", dashboardHandler.UpdateDashboard)
+		r.Delete("/{dashboard_id}", dashboardHandler.DeleteDashboard)
+		r.Get("/{dashboard_id}/data", dashboardHandler.GetDashboardData)
+	})
+
+	r.Route("/reports", func(r chi.Router) {
+		r.Get("/", reportHandler.ListReports)
+		r.Post("/", reportHandler.CreateReport)
+		r.Get("/{report_id}", reportHandler.GetReport)
+		r.Put("/{report_id}", reportHandler.UpdateReport)
+		r.Delete("/{report_id}", reportHandler.DeleteReport)
+		r.Post("/{report_id}/generate", reportHandler.GenerateReport)
+		r.Get("/{report_id}/runs", reportHandler.ListReportRuns)
+		r.Get("/{report_id}/runs/{run_id}/download", reportHandler.DownloadReportRun)
+	})
+}
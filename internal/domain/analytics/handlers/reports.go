@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics/service"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type ReportHandler struct {
+	reportService  *service.ReportService
+	projectService *projectsService.ProjectService
+	validator      *validator.Validate
+}
+
+func NewReportHandler(reportService *service.ReportService, projectService *projectsService.ProjectService) *ReportHandler {
+	return &ReportHandler{
+		reportService:  reportService,
+		projectService: projectService,
+		validator:      validator.New(),
+	}
+}
+
+type ReportRequest struct {
+	Name        string `json:"name" validate:"required,max=128"`
+	Description string `json:"description" validate:"max=1024"`
+	// Type is usage (resource usage), performance (requests and error rates),
+	// deployments (deployment frequency) or custom (all of them)
+	Type   analytics.ReportType   `json:"type" validate:"required"`
+	Period analytics.ReportPeriod `json:"period" validate:"required"`
+	Config analytics.ReportConfig `json:"config"`
+}
+
+type ReportResponse struct {
+	ID          string                 `json:"id"`
+	ProjectID   string                 `json:"project_id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        analytics.ReportType   `json:"type"`
+	Period      analytics.ReportPeriod `json:"period"`
+	Config      analytics.ReportConfig `json:"config"`
+	Status      analytics.ReportStatus `json:"status"`
+	GeneratedAt *time.Time             `json:"generated_at,omitempty"`
+	NextRunAt   time.Time              `json:"next_run_at"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+type ListReportsResponse struct {
+	Reports []ReportResponse `json:"reports"`
+}
+
+type ReportRunResponse struct {
+	ID          string                 `json:"id"`
+	ReportID    string                 `json:"report_id"`
+	PeriodStart time.Time              `json:"period_start"`
+	PeriodEnd   time.Time              `json:"period_end"`
+	Status      analytics.ReportStatus `json:"status"`
+	Error       string                 `json:"error,omitempty"`
+	EmailedTo   []string               `json:"emailed_to"`
+	CreatedAt   time.Time              `json:"created_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+}
+
+type ListReportRunsResponse struct {
+	Runs []ReportRunResponse `json:"runs"`
+}
+
+func toReportResponse(report *analytics.Report) ReportResponse {
+	return ReportResponse{
+		ID:          report.ID().String(),
+		ProjectID:   report.ProjectID().String(),
+		Name:        report.Name().String(),
+		Description: report.Description(),
+		Type:        report.Type(),
+		Period:      report.Period(),
+		Config:      report.Config(),
+		Status:      report.Status(),
+		GeneratedAt: report.GeneratedAt(),
+		NextRunAt:   report.NextRunAt(),
+		CreatedAt:   report.CreatedAt(),
+		UpdatedAt:   report.UpdatedAt(),
+	}
+}
+
+func toReportRunResponse(run *analytics.ReportRun) ReportRunResponse {
+	return ReportRunResponse{
+		ID:          run.ID().String(),
+		ReportID:    run.ReportID().String(),
+		PeriodStart: run.PeriodStart(),
+		PeriodEnd:   run.PeriodEnd(),
+		Status:      run.Status(),
+		Error:       run.ErrorMessage(),
+		EmailedTo:   run.EmailedTo(),
+		CreatedAt:   run.CreatedAt(),
+		CompletedAt: run.CompletedAt(),
+	}
+}
+
+func (h *ReportHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	reports, err := h.reportService.ListReports(r.Context(), projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list reports")
+		return
+	}
+
+	response := ListReportsResponse{Reports: make([]ReportResponse, 0, len(reports))}
+	for _, report := range reports {
+		response.Reports = append(response.Reports, toReportResponse(report))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *ReportHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	cmd, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.CreateReport(r.Context(), projectID, cmd)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create report: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toReportResponse(report))
+}
+
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetReport(r.Context(), projectID, chi.URLParam(r, "report_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toReportResponse(report))
+}
+
+func (h *ReportHandler) UpdateReport(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	cmd, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.UpdateReport(r.Context(), projectID, chi.URLParam(r, "report_id"), cmd)
+	if errors.Is(err, service.ErrReportNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update report: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toReportResponse(report))
+}
+
+func (h *ReportHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.reportService.DeleteReport(r.Context(), projectID, chi.URLParam(r, "report_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GenerateReport generates a report now for the period ending now, outside of
+// its schedule
+func (h *ReportHandler) GenerateReport(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetReport(r.Context(), projectID, chi.URLParam(r, "report_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	run, err := h.reportService.GenerateReport(r.Context(), report, time.Now())
+	if err != nil && run == nil {
+		utils.SendError(w, http.StatusInternalServerError, "generate_failed", "Failed to generate report: "+err.Error())
+		return
+	}
+	if err != nil {
+		slog.Warn("Failed to generate report", "report_id", report.ID().String(), "error", err)
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toReportRunResponse(run))
+}
+
+func (h *ReportHandler) ListReportRuns(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetReport(r.Context(), projectID, chi.URLParam(r, "report_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	runs, err := h.reportService.ListRuns(r.Context(), report)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list report runs")
+		return
+	}
+
+	response := ListReportRunsResponse{Runs: make([]ReportRunResponse, 0, len(runs))}
+	for _, run := range runs {
+		response.Runs = append(response.Runs, toReportRunResponse(run))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+// DownloadReportRun returns the HTML of a generated report as an attachment
+func (h *ReportHandler) DownloadReportRun(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetReport(r.Context(), projectID, chi.URLParam(r, "report_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	run, err := h.reportService.GetRun(r.Context(), report, chi.URLParam(r, "run_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	if run.Status() != analytics.ReportStatusCompleted {
+		utils.SendError(w, http.StatusConflict, "report_not_generated", "This run did not generate a report")
+		return
+	}
+
+	filename := fmt.Sprintf("report-%s.html", run.PeriodEnd().UTC().Format("2006-01-02-1504"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(run.Content()))
+}
+
+func (h *ReportHandler) decodeRequest(w http.ResponseWriter, r *http.Request) (service.ReportCommand, bool) {
+	var req ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return service.ReportCommand{}, false
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return service.ReportCommand{}, false
+	}
+
+	return service.ReportCommand{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		Period:      req.Period,
+		Config:      req.Config,
+	}, true
+}
+
+func (h *ReportHandler) getProject(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return uuid.Nil, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return uuid.Nil, false
+	}
+
+	return projectID, true
+}
+
+func (h *ReportHandler) sendLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrReportNotFound):
+		utils.SendError(w, http.StatusNotFound, "report_not_found", "Report not found")
+	case errors.Is(err, service.ErrReportRunNotFound):
+		utils.SendError(w, http.StatusNotFound, "report_run_not_found", "Report run not found")
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
@@ -87,6 +87,10 @@ func NewReportID() ReportID {
 	return ReportID{value: uuid.Must(uuid.NewV7()).String()}
 }
 
+func ReportIDFromString(id string) ReportID {
+	return ReportID{value: id}
+}
+
 func (id ReportID) String() string {
 	return id.value
 }
@@ -115,6 +119,7 @@ const (
 	ReportTypeUsage       ReportType = "usage"
 	ReportTypePerformance ReportType = "performance"
 	ReportTypeCost        ReportType = "cost"
+	ReportTypeDeployments ReportType = "deployments"
 	ReportTypeCustom      ReportType = "custom"
 )
 
@@ -133,6 +138,8 @@ type ReportConfig struct {
 	StartTime *time.Time        `json:"start_time,omitempty"`
 	EndTime   *time.Time        `json:"end_time,omitempty"`
 	Filters   map[string]string `json:"filters,omitempty"`
+	// Recipients are emailed the report as HTML every time it is generated
+	Recipients []string `json:"recipients,omitempty"`
 }
 
 type ReportStatus string
@@ -163,6 +170,10 @@ func NewDashboardID() DashboardID {
 	return DashboardID{value: uuid.Must(uuid.NewV7()).String()}
 }
 
+func DashboardIDFromString(id string) DashboardID {
+	return DashboardID{value: id}
+}
+
 func (id DashboardID) String() string {
 	return id.value
 }
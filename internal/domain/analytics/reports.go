@@ -0,0 +1,281 @@
+package analytics
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxReportRecipients caps the addresses a report is emailed to
+const maxReportRecipients = 20
+
+// ValidateReport checks the settings of a report before it is saved. Cost
+// reports need billing data this instance does not have.
+func ValidateReport(reportType ReportType, period ReportPeriod, config ReportConfig) error {
+	switch reportType {
+	case ReportTypeUsage, ReportTypePerformance, ReportTypeDeployments, ReportTypeCustom:
+	case ReportTypeCost:
+		return fmt.Errorf("cost reports are not supported")
+	default:
+		return fmt.Errorf("invalid report type: %s", reportType)
+	}
+
+	switch period {
+	case ReportPeriodHourly, ReportPeriodDaily, ReportPeriodWeekly, ReportPeriodMonthly:
+	default:
+		return fmt.Errorf("invalid report period: %s", period)
+	}
+
+	for _, metric := range config.Metrics {
+		if !slices.Contains(ContainerMetrics, metric) {
+			return fmt.Errorf("unknown metric: %s", metric)
+		}
+	}
+
+	for _, service := range config.Services {
+		if _, err := uuid.Parse(service); err != nil {
+			return fmt.Errorf("invalid service ID: %s", service)
+		}
+	}
+
+	if config.StartTime != nil && config.EndTime != nil && !config.EndTime.After(*config.StartTime) {
+		return fmt.Errorf("end time must be after start time")
+	}
+
+	if len(config.Recipients) > maxReportRecipients {
+		return fmt.Errorf("a report cannot be emailed to more than %d recipients", maxReportRecipients)
+	}
+	for _, recipient := range config.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid recipient email: %s", recipient)
+		}
+	}
+
+	return nil
+}
+
+// Start returns the beginning of the period ending at end
+func (p ReportPeriod) Start(end time.Time) time.Time {
+	switch p {
+	case ReportPeriodHourly:
+		return end.Add(-time.Hour)
+	case ReportPeriodDaily:
+		return end.AddDate(0, 0, -1)
+	case ReportPeriodWeekly:
+		return end.AddDate(0, 0, -7)
+	case ReportPeriodMonthly:
+		return end.AddDate(0, -1, 0)
+	}
+	return end
+}
+
+// Next returns the end of the period starting at start
+func (p ReportPeriod) Next(start time.Time) time.Time {
+	switch p {
+	case ReportPeriodHourly:
+		return start.Add(time.Hour)
+	case ReportPeriodDaily:
+		return start.AddDate(0, 0, 1)
+	case ReportPeriodWeekly:
+		return start.AddDate(0, 0, 7)
+	case ReportPeriodMonthly:
+		return start.AddDate(0, 1, 0)
+	}
+	return start
+}
+
+func (r *Report) Update(name ReportName, description string, reportType ReportType, period ReportPeriod, config ReportConfig) error {
+	if err := ValidateReport(reportType, period, config); err != nil {
+		return err
+	}
+
+	r.name = name
+	r.description = description
+	r.reportType = reportType
+	r.period = period
+	r.config = config
+	r.updatedAt = time.Now()
+	return nil
+}
+
+// NextRunAt returns when the report is generated next, one period after it
+// was last generated or created. A failed run is retried a period later.
+func (r *Report) NextRunAt() time.Time {
+	base := r.createdAt
+	if r.generatedAt != nil {
+		base = *r.generatedAt
+	}
+	if r.status == ReportStatusError && r.updatedAt.After(base) {
+		base = r.updatedAt
+	}
+	return r.period.Next(base)
+}
+
+// Due reports whether the scheduler should generate the report at now. The
+// start and end time of the config bound when a report is scheduled.
+func (r *Report) Due(now time.Time) bool {
+	if r.config.StartTime != nil && now.Before(*r.config.StartTime) {
+		return false
+	}
+	if r.config.EndTime != nil && now.After(*r.config.EndTime) {
+		return false
+	}
+	return !now.Before(r.NextRunAt())
+}
+
+// Sections returns the sections a report of this type is made of
+func (t ReportType) Sections() (usage, performance, deployments bool) {
+	switch t {
+	case ReportTypeUsage:
+		return true, false, false
+	case ReportTypePerformance:
+		return false, true, false
+	case ReportTypeDeployments:
+		return false, false, true
+	case ReportTypeCustom:
+		return true, true, true
+	}
+	return false, false, false
+}
+
+// ReportRun is a generated report, its HTML content is kept for download
+type ReportRun struct {
+	id           ReportRunID
+	reportID     ReportID
+	projectID    uuid.UUID
+	periodStart  time.Time
+	periodEnd    time.Time
+	status       ReportStatus
+	content      string
+	errorMessage string
+	emailedTo    []string
+	createdAt    time.Time
+	completedAt  *time.Time
+}
+
+type ReportRunID struct {
+	value string
+}
+
+func NewReportRunID() ReportRunID {
+	return ReportRunID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func ReportRunIDFromString(id string) ReportRunID {
+	return ReportRunID{value: id}
+}
+
+func (id ReportRunID) String() string {
+	return id.value
+}
+
+func NewReportRun(report *Report, periodStart, periodEnd time.Time) *ReportRun {
+	return &ReportRun{
+		id:          NewReportRunID(),
+		reportID:    report.ID(),
+		projectID:   report.ProjectID(),
+		periodStart: periodStart,
+		periodEnd:   periodEnd,
+		status:      ReportStatusGenerating,
+		emailedTo:   []string{},
+		createdAt:   time.Now(),
+	}
+}
+
+func (r *ReportRun) ID() ReportRunID {
+	return r.id
+}
+
+func (r *ReportRun) ReportID() ReportID {
+	return r.reportID
+}
+
+func (r *ReportRun) ProjectID() uuid.UUID {
+	return r.projectID
+}
+
+func (r *ReportRun) PeriodStart() time.Time {
+	return r.periodStart
+}
+
+func (r *ReportRun) PeriodEnd() time.Time {
+	return r.periodEnd
+}
+
+func (r *ReportRun) Status() ReportStatus {
+	return r.status
+}
+
+// Content returns the rendered HTML of the report
+func (r *ReportRun) Content() string {
+	return r.content
+}
+
+func (r *ReportRun) ErrorMessage() string {
+	return r.errorMessage
+}
+
+func (r *ReportRun) EmailedTo() []string {
+	return r.emailedTo
+}
+
+func (r *ReportRun) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+func (r *ReportRun) CompletedAt() *time.Time {
+	return r.completedAt
+}
+
+func (r *ReportRun) Complete(content string) {
+	now := time.Now()
+	r.status = ReportStatusCompleted
+	r.content = content
+	r.completedAt = &now
+}
+
+func (r *ReportRun) Fail(errorMessage string) {
+	now := time.Now()
+	r.status = ReportStatusError
+	r.errorMessage = errorMessage
+	r.completedAt = &now
+}
+
+// Emailed records who the report was sent to, a delivery error is kept next
+// to the content that was still generated
+func (r *ReportRun) Emailed(recipients []string, err error) {
+	if err != nil {
+		r.errorMessage = fmt.Sprintf("failed to email report: %v", err)
+		return
+	}
+	r.emailedTo = recipients
+}
+
+func ReconstructReportRun(
+	id ReportRunID,
+	reportID ReportID,
+	projectID uuid.UUID,
+	periodStart, periodEnd time.Time,
+	status ReportStatus,
+	content, errorMessage string,
+	emailedTo []string,
+	createdAt time.Time,
+	completedAt *time.Time,
+) *ReportRun {
+	return &ReportRun{
+		id:           id,
+		reportID:     reportID,
+		projectID:    projectID,
+		periodStart:  periodStart,
+		periodEnd:    periodEnd,
+		status:       status,
+		content:      content,
+		errorMessage: errorMessage,
+		emailedTo:    emailedTo,
+		createdAt:    createdAt,
+		completedAt:  completedAt,
+	}
+}
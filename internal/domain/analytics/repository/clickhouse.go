@@ -0,0 +1,520 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+// ClickHouseMetricRepository implements MetricRepository using ClickHouse.
+// Batches are sent as a single native insert block and old rows expire
+// through the table TTL.
+type ClickHouseMetricRepository struct {
+	db *sql.DB
+}
+
+// NewClickHouseMetricRepository creates a new ClickHouse-based metric repository
+func NewClickHouseMetricRepository(db *sql.DB) *ClickHouseMetricRepository {
+	return &ClickHouseMetricRepository{db: db}
+}
+
+// Create inserts a new metric into the database
+func (r *ClickHouseMetricRepository) Create(ctx context.Context, metric *analytics.Metric) error {
+	if err := r.CreateBatch(ctx, []*analytics.Metric{metric}); err != nil {
+		return fmt.Errorf("failed to create metric: %w", err)
+	}
+
+	return nil
+}
+
+// CreateBatch inserts several metrics as one insert block
+func (r *ClickHouseMetricRepository) CreateBatch(ctx context.Context, metrics []*analytics.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO metrics (id, project_id, service_id, name, value, unit, tags, timestamp, created_at)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, metric := range metrics {
+		tagsJSON, err := json.Marshal(metric.Tags())
+		if err != nil {
+			return fmt.Errorf("failed to marshal tags: %w", err)
+		}
+
+		serviceIDStr := ""
+		if metric.ServiceID() != nil {
+			serviceIDStr = metric.ServiceID().String()
+		}
+
+		_, err = stmt.ExecContext(ctx,
+			metric.ID().String(),
+			metric.ProjectID().String(),
+			serviceIDStr,
+			metric.Name().String(),
+			metric.Value(),
+			string(metric.Unit()),
+			string(tagsJSON),
+			metric.Timestamp(),
+			metric.CreatedAt(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append metric: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to insert metrics: %w", err)
+	}
+
+	return nil
+}
+
+// metricConditions builds the WHERE conditions shared by Query, Count and
+// Aggregate
+func metricConditions(query analytics.MetricQuery) ([]string, []interface{}) {
+	conditions := []string{"project_id = ?"}
+	args := []interface{}{query.ProjectID.String()}
+
+	if query.ServiceID != nil {
+		conditions = append(conditions, "service_id = ?")
+		args = append(args, query.ServiceID.String())
+	}
+
+	if query.Name != nil {
+		conditions = append(conditions, "name = ?")
+		args = append(args, query.Name.String())
+	}
+
+	if query.StartTime != nil {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, *query.StartTime)
+	}
+
+	if query.EndTime != nil {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, *query.EndTime)
+	}
+
+	return conditions, args
+}
+
+// Query retrieves metrics based on the provided criteria
+func (r *ClickHouseMetricRepository) Query(ctx context.Context, query analytics.MetricQuery) ([]*analytics.Metric, error) {
+	conditions, args := metricConditions(query)
+
+	sql := "SELECT id, project_id, service_id, name, value, unit, tags, timestamp, created_at FROM metrics WHERE " +
+		strings.Join(conditions, " AND ") + " ORDER BY timestamp DESC"
+
+	if query.Limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+	if query.Offset > 0 {
+		sql += fmt.Sprintf(" OFFSET %d", query.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []*analytics.Metric
+	for rows.Next() {
+		metric, err := r.scanMetric(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metric: %w", err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// Count returns the number of metrics matching the query
+func (r *ClickHouseMetricRepository) Count(ctx context.Context, query analytics.MetricQuery) (int64, error) {
+	conditions, args := metricConditions(query)
+
+	var count uint64
+	err := r.db.QueryRowContext(ctx, "SELECT count() FROM metrics WHERE "+strings.Join(conditions, " AND "), args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count metrics: %w", err)
+	}
+
+	return int64(count), nil
+}
+
+// Aggregate performs aggregation on metrics
+func (r *ClickHouseMetricRepository) Aggregate(ctx context.Context, query analytics.MetricQuery) (map[string]float64, error) {
+	conditions, args := metricConditions(query)
+
+	sql := `SELECT name, avg(value), min(value), max(value), sum(value), toFloat64(count())
+		FROM metrics WHERE ` + strings.Join(conditions, " AND ") + ` GROUP BY name`
+
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate metrics: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]float64)
+	for rows.Next() {
+		var name string
+		var avg, min, max, sum, count float64
+
+		if err := rows.Scan(&name, &avg, &min, &max, &sum, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate row: %w", err)
+		}
+
+		result[name+"_avg"] = avg
+		result[name+"_min"] = min
+		result[name+"_max"] = max
+		result[name+"_sum"] = sum
+		result[name+"_count"] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteOlderThan removes metrics older than the specified cutoff (in days)
+func (r *ClickHouseMetricRepository) DeleteOlderThan(ctx context.Context, projectID string, cutoffDays int64) error {
+	cutoff := time.Now().AddDate(0, 0, -int(cutoffDays))
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM metrics WHERE project_id = ? AND timestamp < ?", projectID, cutoff); err != nil {
+		return fmt.Errorf("failed to delete old metrics: %w", err)
+	}
+
+	return nil
+}
+
+// Series downsamples a metric into buckets of aggregation.Interval, see
+// SQLiteMetricRepository.Series
+func (r *ClickHouseMetricRepository) Series(ctx context.Context, aggregation analyticsdb.MetricAggregation) ([]analyticsdb.AggregationResult, error) {
+	var function string
+	switch aggregation.Function {
+	case analyticsdb.Sum, analyticsdb.Avg, analyticsdb.Min, analyticsdb.Max, analyticsdb.Count:
+		function = string(aggregation.Function)
+	default:
+		return nil, fmt.Errorf("unsupported aggregation function: %s", aggregation.Function)
+	}
+
+	interval := int64(aggregation.Interval / time.Second)
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be at least one second")
+	}
+
+	conditions := []string{"name = ?", "timestamp >= ?", "timestamp <= ?"}
+	args := []interface{}{
+		aggregation.MetricName,
+		aggregation.TimeRange.Start,
+		aggregation.TimeRange.End,
+	}
+
+	for tag, value := range aggregation.Tags {
+		column, ok := metricSeriesColumns[tag]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tag filter: %s", tag)
+		}
+		conditions = append(conditions, column+" = ?")
+		args = append(args, value)
+	}
+
+	groupColumns := make([]string, 0, len(aggregation.GroupBy))
+	for _, tag := range aggregation.GroupBy {
+		column, ok := metricSeriesColumns[tag]
+		if !ok {
+			return nil, fmt.Errorf("unsupported group by: %s", tag)
+		}
+		groupColumns = append(groupColumns, column)
+	}
+
+	// Buckets are aligned on the epoch like the SQLite ones
+	bucket := fmt.Sprintf("intDiv(toUnixTimestamp(timestamp), %d) * %d AS bucket", interval, interval)
+	selectColumns := append([]string{bucket}, groupColumns...)
+	groupBy := append([]string{"bucket"}, groupColumns...)
+
+	query := fmt.Sprintf(
+		"SELECT %s, toFloat64(%s(value)) FROM metrics WHERE %s GROUP BY %s ORDER BY %s",
+		strings.Join(selectColumns, ", "),
+		function,
+		strings.Join(conditions, " AND "),
+		strings.Join(groupBy, ", "),
+		strings.Join(groupBy, ", "),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric series: %w", err)
+	}
+	defer rows.Close()
+
+	var results []analyticsdb.AggregationResult
+	for rows.Next() {
+		var bucket int64
+		var value float64
+		groups := make([]string, len(groupColumns))
+
+		dest := []interface{}{&bucket}
+		for i := range groups {
+			dest = append(dest, &groups[i])
+		}
+		dest = append(dest, &value)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan metric series: %w", err)
+		}
+
+		result := analyticsdb.AggregationResult{
+			Value:     value,
+			Timestamp: time.Unix(bucket, 0).UTC(),
+		}
+		if len(groups) > 0 {
+			result.GroupBy = make(map[string]string, len(groups))
+			for i, tag := range aggregation.GroupBy {
+				result.GroupBy[tag] = groups[i]
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return results, nil
+}
+
+// DeleteAllOlderThan removes the metrics of every project recorded before
+// cutoff. Whole partitions past the table TTL are already gone, this only
+// trims the rows of the partition the cutoff falls in.
+func (r *ClickHouseMetricRepository) DeleteAllOlderThan(ctx context.Context, cutoff time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM metrics WHERE timestamp < ?", cutoff); err != nil {
+		return fmt.Errorf("failed to delete old metrics: %w", err)
+	}
+
+	return nil
+}
+
+// scanMetric scans a row into a Metric object
+func (r *ClickHouseMetricRepository) scanMetric(rows *sql.Rows) (*analytics.Metric, error) {
+	var (
+		id, projectIDStr, serviceIDStr, nameStr, unitStr, tagsJSON string
+		value                                                      float64
+		timestamp, createdAt                                       time.Time
+	)
+
+	err := rows.Scan(
+		&id, &projectIDStr, &serviceIDStr, &nameStr,
+		&value, &unitStr, &tagsJSON, &timestamp, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	var serviceID *uuid.UUID
+	if serviceIDStr != "" {
+		parsed, err := uuid.Parse(serviceIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service ID: %w", err)
+		}
+		serviceID = &parsed
+	}
+
+	var tags map[string]string
+	if err := json.Unmarshal([]byte(tagsJSON), &tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	metricName, err := analytics.NewMetricName(nameStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric name: %w", err)
+	}
+
+	return analytics.ReconstructMetric(
+		analytics.MetricIDFromString(id),
+		projectID,
+		serviceID,
+		metricName,
+		value,
+		analytics.MetricUnit(unitStr),
+		tags,
+		timestamp,
+		createdAt,
+	), nil
+}
+
+// ClickHouseRequestRepository implements RequestRepository using ClickHouse
+type ClickHouseRequestRepository struct {
+	db *sql.DB
+}
+
+// NewClickHouseRequestRepository creates a new ClickHouse-based request repository
+func NewClickHouseRequestRepository(db *sql.DB) *ClickHouseRequestRepository {
+	return &ClickHouseRequestRepository{db: db}
+}
+
+// CreateBatch inserts request logs as one insert block
+func (r *ClickHouseRequestRepository) CreateBatch(ctx context.Context, requests []*analytics.RequestLog) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO requests (
+		id, project_id, application_id, router, method, host, path,
+		status, duration_ms, bytes, client_ip, timestamp
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, req := range requests {
+		_, err := stmt.ExecContext(ctx,
+			req.ID(),
+			req.ProjectID().String(),
+			req.ApplicationID().String(),
+			req.Router(),
+			req.Method(),
+			req.Host(),
+			req.Path(),
+			uint16(req.Status()),
+			float64(req.Duration())/float64(time.Millisecond),
+			req.Bytes(),
+			req.ClientIP(),
+			req.Timestamp(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append request: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store requests: %w", err)
+	}
+
+	return nil
+}
+
+// Stats aggregates the requests of an application over the query time range
+func (r *ClickHouseRequestRepository) Stats(ctx context.Context, query analytics.RequestQuery) (*analytics.RequestStats, error) {
+	where := "application_id = ? AND timestamp >= ? AND timestamp <= ?"
+	args := []any{query.ApplicationID.String(), query.StartTime, query.EndTime}
+
+	stats := &analytics.RequestStats{
+		StatusCodes:   make(map[int]int64),
+		StatusClasses: make(map[string]int64),
+		TopPaths:      []analytics.PathStats{},
+		StartTime:     query.StartTime,
+		EndTime:       query.EndTime,
+	}
+
+	var total uint64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(), sum(bytes), quantileExact(0.5)(duration_ms), quantileExact(0.95)(duration_ms)
+		FROM requests WHERE `+where, args...,
+	).Scan(&total, &stats.TotalBytes, &stats.P50LatencyMs, &stats.P95LatencyMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count requests: %w", err)
+	}
+
+	stats.TotalRequests = int64(total)
+	if stats.TotalRequests == 0 {
+		stats.P50LatencyMs, stats.P95LatencyMs = 0, 0
+		return stats, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT status, count() FROM requests WHERE "+where+" GROUP BY status", args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status codes: %w", err)
+	}
+	for rows.Next() {
+		var status uint16
+		var count uint64
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan status code: %w", err)
+		}
+		stats.StatusCodes[int(status)] = int64(count)
+		stats.StatusClasses[analytics.StatusClass(int(status))] += int64(count)
+	}
+	rows.Close()
+
+	topPaths := query.TopPaths
+	if topPaths <= 0 {
+		topPaths = 10
+	}
+
+	rows, err = r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT path, count() AS requests, countIf(status >= 500), avg(duration_ms)
+		FROM requests WHERE %s
+		GROUP BY path
+		ORDER BY requests DESC
+		LIMIT %d`, where, topPaths), args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top paths: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path analytics.PathStats
+		var requests, errors uint64
+		if err := rows.Scan(&path.Path, &requests, &errors, &path.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("failed to scan path stats: %w", err)
+		}
+		path.Requests = int64(requests)
+		path.Errors = int64(errors)
+		stats.TopPaths = append(stats.TopPaths, path)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating path stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// DeleteOlderThan removes requests recorded before cutoff
+func (r *ClickHouseRequestRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM requests WHERE timestamp < ?", cutoff); err != nil {
+		return fmt.Errorf("failed to delete old requests: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/database/analytics/clickhousetest"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+func TestClickHouseMetricRepository(t *testing.T) {
+	repo := NewClickHouseMetricRepository(clickhousetest.Open(t))
+	ctx := context.Background()
+
+	projectID, serviceID, otherServiceID := uuid.New(), uuid.New(), uuid.New()
+	cpu, _ := analytics.NewMetricName("cpu_usage")
+	memory, _ := analytics.NewMetricName("memory_usage")
+
+	// Two one-minute buckets of samples, starting on an hour boundary
+	start := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	metrics := []*analytics.Metric{
+		analytics.NewMetric(projectID, &serviceID, cpu, 10, analytics.MetricUnitPercent, map[string]string{"core": "0"}, start),
+		analytics.NewMetric(projectID, &serviceID, cpu, 20, analytics.MetricUnitPercent, nil, start.Add(30*time.Second)),
+		analytics.NewMetric(projectID, &serviceID, cpu, 40, analytics.MetricUnitPercent, nil, start.Add(2*time.Minute)),
+		analytics.NewMetric(projectID, &otherServiceID, cpu, 80, analytics.MetricUnitPercent, nil, start.Add(2*time.Minute)),
+		analytics.NewMetric(projectID, &serviceID, memory, 512, analytics.MetricUnitBytes, nil, start),
+		analytics.NewMetric(uuid.New(), &serviceID, cpu, 99, analytics.MetricUnitPercent, nil, start),
+	}
+	if err := repo.CreateBatch(ctx, metrics); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	query := analytics.MetricQuery{ProjectID: projectID, ServiceID: &serviceID, Name: &cpu}
+	stored, err := repo.Query(ctx, query)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("Query returned %d metrics, want 3", len(stored))
+	}
+	if stored[2].ID() != metrics[0].ID() || stored[2].Tags()["core"] != "0" || !stored[2].Timestamp().Equal(start) {
+		t.Errorf("oldest metric = %+v, want the first one written", stored[2])
+	}
+
+	count, err := repo.Count(ctx, analytics.MetricQuery{ProjectID: projectID})
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Count = %d, want 5", count)
+	}
+
+	aggregates, err := repo.Aggregate(ctx, analytics.MetricQuery{ProjectID: projectID, Name: &cpu})
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if aggregates["cpu_usage_sum"] != 150 || aggregates["cpu_usage_max"] != 80 || aggregates["cpu_usage_count"] != 4 {
+		t.Errorf("Aggregate = %v, want a sum of 150, max of 80 over 4 samples", aggregates)
+	}
+
+	series, err := repo.Series(ctx, analyticsdb.MetricAggregation{
+		MetricName: "cpu_usage",
+		Function:   analyticsdb.Avg,
+		GroupBy:    []string{"service_id"},
+		Tags:       map[string]string{"project_id": projectID.String()},
+		TimeRange:  analyticsdb.TimeRange{Start: start, End: start.Add(time.Hour)},
+		Interval:   time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Series: %v", err)
+	}
+
+	want := []analyticsdb.AggregationResult{
+		{Timestamp: start, Value: 15, GroupBy: map[string]string{"service_id": serviceID.String()}},
+		{Timestamp: start.Add(2 * time.Minute), Value: 40, GroupBy: map[string]string{"service_id": serviceID.String()}},
+		{Timestamp: start.Add(2 * time.Minute), Value: 80, GroupBy: map[string]string{"service_id": otherServiceID.String()}},
+	}
+	// Results are ordered by bucket, then by service ID
+	slices.SortFunc(want, func(a, b analyticsdb.AggregationResult) int {
+		return cmp.Or(a.Timestamp.Compare(b.Timestamp), cmp.Compare(a.GroupBy["service_id"], b.GroupBy["service_id"]))
+	})
+
+	if len(series) != len(want) {
+		t.Fatalf("Series = %+v, want %+v", series, want)
+	}
+	for i, got := range series {
+		if !got.Timestamp.Equal(want[i].Timestamp) || got.Value != want[i].Value || got.GroupBy["service_id"] != want[i].GroupBy["service_id"] {
+			t.Errorf("Series[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	if _, err := repo.Series(ctx, analyticsdb.MetricAggregation{
+		MetricName: "cpu_usage",
+		Function:   analyticsdb.Avg,
+		GroupBy:    []string{"core"},
+		TimeRange:  analyticsdb.TimeRange{Start: start, End: start.Add(time.Hour)},
+		Interval:   time.Minute,
+	}); err == nil {
+		t.Error("Series grouped by a tag succeeded, want an unsupported group by error")
+	}
+}
+
+func TestClickHouseRequestRepositoryStats(t *testing.T) {
+	repo := NewClickHouseRequestRepository(clickhousetest.Open(t))
+	ctx := context.Background()
+
+	projectID, applicationID := uuid.New(), uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// 101 requests taking 0 to 100ms, every tenth one failing, the even ones
+	// on /a and the odd ones on /b
+	var requests []*analytics.RequestLog
+	for i := 0; i <= 100; i++ {
+		status, path := 200, "/b"
+		if i%10 == 0 {
+			status = 500
+		}
+		if i%2 == 0 {
+			path = "/a"
+		}
+
+		request, err := analytics.NewRequestLog(projectID, applicationID, "app@docker", "GET", "example.com", path,
+			status, time.Duration(i)*time.Millisecond, 10, "10.0.0.1", now.Add(-time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("NewRequestLog: %v", err)
+		}
+		requests = append(requests, request)
+	}
+
+	// Neither counts, one is of another application and one is too old
+	other, _ := analytics.NewRequestLog(projectID, uuid.New(), "other@docker", "GET", "example.com", "/a", 200, time.Second, 10, "10.0.0.1", now)
+	old, _ := analytics.NewRequestLog(projectID, applicationID, "app@docker", "GET", "example.com", "/a", 200, time.Second, 10, "10.0.0.1", now.Add(-2*time.Hour))
+	requests = append(requests, other, old)
+
+	if err := repo.CreateBatch(ctx, requests); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	stats, err := repo.Stats(ctx, analytics.RequestQuery{
+		ApplicationID: applicationID,
+		StartTime:     now.Add(-time.Hour),
+		EndTime:       now.Add(time.Minute),
+		TopPaths:      1,
+	})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+
+	if stats.TotalRequests != 101 || stats.TotalBytes != 1010 {
+		t.Errorf("totals = %d requests, %d bytes, want 101 and 1010", stats.TotalRequests, stats.TotalBytes)
+	}
+	if stats.P50LatencyMs != 50 || stats.P95LatencyMs != 95 {
+		t.Errorf("latency p50 = %v, p95 = %v, want 50 and 95", stats.P50LatencyMs, stats.P95LatencyMs)
+	}
+	if stats.StatusCodes[200] != 90 || stats.StatusCodes[500] != 11 {
+		t.Errorf("status codes = %v, want 90 200s and 11 500s", stats.StatusCodes)
+	}
+	if stats.StatusClasses["2xx"] != 90 || stats.StatusClasses["5xx"] != 11 {
+		t.Errorf("status classes = %v, want 90 2xx and 11 5xx", stats.StatusClasses)
+	}
+
+	if len(stats.TopPaths) != 1 {
+		t.Fatalf("TopPaths = %+v, want only the busiest path", stats.TopPaths)
+	}
+	if top := stats.TopPaths[0]; top.Path != "/a" || top.Requests != 51 || top.Errors != 11 || top.AvgLatencyMs != 50 {
+		t.Errorf("top path = %+v, want /a with 51 requests, 11 errors and 50ms on average", top)
+	}
+
+	empty, err := repo.Stats(ctx, analytics.RequestQuery{
+		ApplicationID: uuid.New(),
+		StartTime:     now.Add(-time.Hour),
+		EndTime:       now,
+	})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if empty.TotalRequests != 0 || empty.P50LatencyMs != 0 || empty.P95LatencyMs != 0 {
+		t.Errorf("stats without requests = %+v, want zeros", empty)
+	}
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+const dashboardColumns = `id, project_id, name, widgets, layout, is_default, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// SQLiteDashboardRepository implements DashboardRepository, dashboards are
+// kept in the main database next to the projects they belong to
+type SQLiteDashboardRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteDashboardRepository(db *sql.DB) *SQLiteDashboardRepository {
+	return &SQLiteDashboardRepository{db: db}
+}
+
+func (r *SQLiteDashboardRepository) Create(ctx context.Context, dashboard *analytics.Dashboard) error {
+	widgets, layout, err := marshalDashboard(dashboard)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO dashboards (` + dashboardColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		dashboard.ID().String(),
+		dashboard.ProjectID().String(),
+		dashboard.Name().String(),
+		widgets,
+		layout,
+		dashboard.IsDefault(),
+		dashboard.CreatedAt(),
+		dashboard.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteDashboardRepository) GetByID(ctx context.Context, id analytics.DashboardID) (*analytics.Dashboard, error) {
+	query := `SELECT ` + dashboardColumns + ` FROM dashboards WHERE id = ?`
+	return r.scanDashboard(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteDashboardRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*analytics.Dashboard, error) {
+	query := `SELECT ` + dashboardColumns + ` FROM dashboards WHERE project_id = ? ORDER BY is_default DESC, name ASC, created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dashboards []*analytics.Dashboard
+	for rows.Next() {
+		dashboard, err := r.scanDashboard(rows)
+		if err != nil {
+			return nil, err
+		}
+		dashboards = append(dashboards, dashboard)
+	}
+
+	return dashboards, rows.Err()
+}
+
+func (r *SQLiteDashboardRepository) Update(ctx context.Context, dashboard *analytics.Dashboard) error {
+	widgets, layout, err := marshalDashboard(dashboard)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE dashboards SET name = ?, widgets = ?, layout = ?, is_default = ?, updated_at = ? WHERE id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		dashboard.Name().String(),
+		widgets,
+		layout,
+		dashboard.IsDefault(),
+		dashboard.UpdatedAt(),
+		dashboard.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteDashboardRepository) Delete(ctx context.Context, id analytics.DashboardID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM dashboards WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteDashboardRepository) UnsetDefault(ctx context.Context, projectID uuid.UUID, except analytics.DashboardID) error {
+	query := `UPDATE dashboards SET is_default = FALSE, updated_at = ? WHERE project_id = ? AND id != ? AND is_default = TRUE`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), projectID.String(), except.String())
+	return err
+}
+
+func (r *SQLiteDashboardRepository) scanDashboard(row rowScanner) (*analytics.Dashboard, error) {
+	var (
+		id, projectIDStr, name, widgetsJSON, layoutJSON string
+		isDefault                                       bool
+		createdAt, updatedAt                            time.Time
+	)
+
+	err := row.Scan(&id, &projectIDStr, &name, &widgetsJSON, &layoutJSON, &isDefault, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardName, err := analytics.NewDashboardName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	widgets := []analytics.DashboardWidget{}
+	if err := json.Unmarshal([]byte(widgetsJSON), &widgets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dashboard widgets: %w", err)
+	}
+
+	var layout analytics.DashboardLayout
+	if err := json.Unmarshal([]byte(layoutJSON), &layout); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dashboard layout: %w", err)
+	}
+
+	return analytics.ReconstructDashboard(
+		analytics.DashboardIDFromString(id),
+		projectID,
+		dashboardName,
+		widgets,
+		layout,
+		isDefault,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+func marshalDashboard(dashboard *analytics.Dashboard) (string, string, error) {
+	widgets := dashboard.Widgets()
+	if widgets == nil {
+		widgets = []analytics.DashboardWidget{}
+	}
+
+	widgetsJSON, err := json.Marshal(widgets)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal dashboard widgets: %w", err)
+	}
+
+	layoutJSON, err := json.Marshal(dashboard.Layout())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal dashboard layout: %w", err)
+	}
+
+	return string(widgetsJSON), string(layoutJSON), nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+const reportColumns = `id, project_id, name, description, type, period, config, status, generated_at, created_at, updated_at`
+
+const reportRunColumns = `id, report_id, project_id, period_start, period_end, status, content, error_message, emailed_to, created_at, completed_at`
+
+// reportRunSummaryColumns leave the content out of run listings
+const reportRunSummaryColumns = `id, report_id, project_id, period_start, period_end, status, '', error_message, emailed_to, created_at, completed_at`
+
+// SQLiteReportRepository implements ReportRepository on the main database
+type SQLiteReportRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReportRepository(db *sql.DB) *SQLiteReportRepository {
+	return &SQLiteReportRepository{db: db}
+}
+
+func (r *SQLiteReportRepository) Create(ctx context.Context, report *analytics.Report) error {
+	config, err := json.Marshal(report.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal report config: %w", err)
+	}
+
+	query := `INSERT INTO reports (` + reportColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		report.ID().String(),
+		report.ProjectID().String(),
+		report.Name().String(),
+		report.Description(),
+		string(report.Type()),
+		string(report.Period()),
+		string(config),
+		string(report.Status()),
+		report.GeneratedAt(),
+		report.CreatedAt(),
+		report.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteReportRepository) GetByID(ctx context.Context, id analytics.ReportID) (*analytics.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = ?`
+	return r.scanReport(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteReportRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*analytics.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE project_id = ? ORDER BY name ASC, created_at ASC`
+	return r.list(ctx, query, projectID.String())
+}
+
+func (r *SQLiteReportRepository) List(ctx context.Context) ([]*analytics.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports ORDER BY created_at ASC`
+	return r.list(ctx, query)
+}
+
+func (r *SQLiteReportRepository) Update(ctx context.Context, report *analytics.Report) error {
+	config, err := json.Marshal(report.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal report config: %w", err)
+	}
+
+	query := `UPDATE reports SET name = ?, description = ?, type = ?, period = ?, config = ?, status = ?, generated_at = ?, updated_at = ? WHERE id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		report.Name().String(),
+		report.Description(),
+		string(report.Type()),
+		string(report.Period()),
+		string(config),
+		string(report.Status()),
+		report.GeneratedAt(),
+		report.UpdatedAt(),
+		report.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteReportRepository) Delete(ctx context.Context, id analytics.ReportID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM reports WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteReportRepository) list(ctx context.Context, query string, args ...any) ([]*analytics.Report, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*analytics.Report
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *SQLiteReportRepository) scanReport(row rowScanner) (*analytics.Report, error) {
+	var (
+		id, projectIDStr, name, description, reportType, period, configJSON, status string
+		generatedAt                                                                 sql.NullTime
+		createdAt, updatedAt                                                        time.Time
+	)
+
+	err := row.Scan(&id, &projectIDStr, &name, &description, &reportType, &period, &configJSON, &status,
+		&generatedAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	reportName, err := analytics.NewReportName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var config analytics.ReportConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report config: %w", err)
+	}
+
+	var generated *time.Time
+	if generatedAt.Valid {
+		generated = &generatedAt.Time
+	}
+
+	return analytics.ReconstructReport(
+		analytics.ReportIDFromString(id),
+		projectID,
+		reportName,
+		description,
+		analytics.ReportType(reportType),
+		analytics.ReportPeriod(period),
+		config,
+		analytics.ReportStatus(status),
+		generated,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+// SQLiteReportRunRepository implements ReportRunRepository on the main database
+type SQLiteReportRunRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReportRunRepository(db *sql.DB) *SQLiteReportRunRepository {
+	return &SQLiteReportRunRepository{db: db}
+}
+
+func (r *SQLiteReportRunRepository) Create(ctx context.Context, run *analytics.ReportRun) error {
+	emailedTo, err := json.Marshal(run.EmailedTo())
+	if err != nil {
+		return fmt.Errorf("failed to marshal report recipients: %w", err)
+	}
+
+	query := `INSERT INTO report_runs (` + reportRunColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		run.ID().String(),
+		run.ReportID().String(),
+		run.ProjectID().String(),
+		run.PeriodStart(),
+		run.PeriodEnd(),
+		string(run.Status()),
+		run.Content(),
+		run.ErrorMessage(),
+		string(emailedTo),
+		run.CreatedAt(),
+		run.CompletedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteReportRunRepository) GetByID(ctx context.Context, id analytics.ReportRunID) (*analytics.ReportRun, error) {
+	query := `SELECT ` + reportRunColumns + ` FROM report_runs WHERE id = ?`
+	return r.scanRun(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteReportRunRepository) ListByReport(ctx context.Context, reportID analytics.ReportID, limit int) ([]*analytics.ReportRun, error) {
+	query := `SELECT ` + reportRunSummaryColumns + ` FROM report_runs WHERE report_id = ? ORDER BY created_at DESC, id DESC LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, reportID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*analytics.ReportRun
+	for rows.Next() {
+		run, err := r.scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *SQLiteReportRunRepository) Update(ctx context.Context, run *analytics.ReportRun) error {
+	emailedTo, err := json.Marshal(run.EmailedTo())
+	if err != nil {
+		return fmt.Errorf("failed to marshal report recipients: %w", err)
+	}
+
+	query := `UPDATE report_runs SET status = ?, content = ?, error_message = ?, emailed_to = ?, completed_at = ? WHERE id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		string(run.Status()),
+		run.Content(),
+		run.ErrorMessage(),
+		string(emailedTo),
+		run.CompletedAt(),
+		run.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteReportRunRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM report_runs WHERE created_at < ?`, cutoff)
+	return err
+}
+
+func (r *SQLiteReportRunRepository) scanRun(row rowScanner) (*analytics.ReportRun, error) {
+	var (
+		id, reportID, projectIDStr, status, content, errorMessage, emailedToJSON string
+		periodStart, periodEnd, createdAt                                        time.Time
+		completedAt                                                              sql.NullTime
+	)
+
+	err := row.Scan(&id, &reportID, &projectIDStr, &periodStart, &periodEnd, &status, &content, &errorMessage,
+		&emailedToJSON, &createdAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	emailedTo := []string{}
+	if err := json.Unmarshal([]byte(emailedToJSON), &emailedTo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report recipients: %w", err)
+	}
+
+	var completed *time.Time
+	if completedAt.Valid {
+		completed = &completedAt.Time
+	}
+
+	return analytics.ReconstructReportRun(
+		analytics.ReportRunIDFromString(id),
+		analytics.ReportIDFromString(reportID),
+		projectID,
+		periodStart,
+		periodEnd,
+		analytics.ReportStatus(status),
+		content,
+		errorMessage,
+		emailedTo,
+		createdAt,
+		completed,
+	), nil
+}
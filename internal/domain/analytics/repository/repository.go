@@ -2,15 +2,59 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
 	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
 )
 
 // MetricRepository handles metric data persistence
 type MetricRepository interface {
 	Create(ctx context.Context, metric *analytics.Metric) error
+	CreateBatch(ctx context.Context, metrics []*analytics.Metric) error
 	Query(ctx context.Context, query analytics.MetricQuery) ([]*analytics.Metric, error)
 	Count(ctx context.Context, query analytics.MetricQuery) (int64, error)
 	Aggregate(ctx context.Context, query analytics.MetricQuery) (map[string]float64, error)
 	DeleteOlderThan(ctx context.Context, projectID string, cutoff int64) error
+	Series(ctx context.Context, aggregation analyticsdb.MetricAggregation) ([]analyticsdb.AggregationResult, error)
+	DeleteAllOlderThan(ctx context.Context, cutoff time.Time) error
+}
+
+// RequestRepository handles proxy request log persistence
+type RequestRepository interface {
+	CreateBatch(ctx context.Context, requests []*analytics.RequestLog) error
+	Stats(ctx context.Context, query analytics.RequestQuery) (*analytics.RequestStats, error)
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) error
+}
+
+// DashboardRepository handles dashboard persistence in the main database
+type DashboardRepository interface {
+	Create(ctx context.Context, dashboard *analytics.Dashboard) error
+	GetByID(ctx context.Context, id analytics.DashboardID) (*analytics.Dashboard, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*analytics.Dashboard, error)
+	Update(ctx context.Context, dashboard *analytics.Dashboard) error
+	Delete(ctx context.Context, id analytics.DashboardID) error
+	// UnsetDefault clears the default flag of the other dashboards of a project
+	UnsetDefault(ctx context.Context, projectID uuid.UUID, except analytics.DashboardID) error
+}
+
+// ReportRepository handles report persistence in the main database
+type ReportRepository interface {
+	Create(ctx context.Context, report *analytics.Report) error
+	GetByID(ctx context.Context, id analytics.ReportID) (*analytics.Report, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*analytics.Report, error)
+	List(ctx context.Context) ([]*analytics.Report, error)
+	Update(ctx context.Context, report *analytics.Report) error
+	Delete(ctx context.Context, id analytics.ReportID) error
+}
+
+// ReportRunRepository handles the generated reports
+type ReportRunRepository interface {
+	Create(ctx context.Context, run *analytics.ReportRun) error
+	GetByID(ctx context.Context, id analytics.ReportRunID) (*analytics.ReportRun, error)
+	// ListByReport returns the runs of a report without their content, newest first
+	ListByReport(ctx context.Context, reportID analytics.ReportID, limit int) ([]*analytics.ReportRun, error)
+	Update(ctx context.Context, run *analytics.ReportRun) error
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) error
 }
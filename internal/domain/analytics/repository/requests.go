@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+// SQLiteRequestRepository implements RequestRepository using SQLite
+type SQLiteRequestRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRequestRepository creates a new SQLite-based request repository
+func NewSQLiteRequestRepository(db *sql.DB) *SQLiteRequestRepository {
+	return &SQLiteRequestRepository{db: db}
+}
+
+// CreateBatch inserts request logs in a single statement
+func (r *SQLiteRequestRepository) CreateBatch(ctx context.Context, requests []*analytics.RequestLog) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO requests (
+		id, project_id, application_id, router, method, host, path,
+		status, duration_ms, bytes, client_ip, timestamp
+	) VALUES `
+	values := make([]string, len(requests))
+	args := make([]any, 0, len(requests)*12)
+
+	for i, req := range requests {
+		values[i] = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		args = append(args,
+			req.ID(),
+			req.ProjectID().String(),
+			req.ApplicationID().String(),
+			req.Router(),
+			req.Method(),
+			req.Host(),
+			req.Path(),
+			req.Status(),
+			float64(req.Duration())/float64(time.Millisecond),
+			req.Bytes(),
+			req.ClientIP(),
+			req.Timestamp().Unix(),
+		)
+	}
+
+	query += strings.Join(values, ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to store requests: %w", err)
+	}
+
+	return nil
+}
+
+// Stats aggregates the requests of an application over the query time range
+func (r *SQLiteRequestRepository) Stats(ctx context.Context, query analytics.RequestQuery) (*analytics.RequestStats, error) {
+	where := "application_id = ? AND timestamp >= ? AND timestamp <= ?"
+	args := []any{query.ApplicationID.String(), query.StartTime.Unix(), query.EndTime.Unix()}
+
+	stats := &analytics.RequestStats{
+		StatusCodes:   make(map[int]int64),
+		StatusClasses: make(map[string]int64),
+		TopPaths:      []analytics.PathStats{},
+		StartTime:     query.StartTime,
+		EndTime:       query.EndTime,
+	}
+
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*), COALESCE(SUM(bytes), 0) FROM requests WHERE "+where, args...,
+	).Scan(&stats.TotalRequests, &stats.TotalBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count requests: %w", err)
+	}
+
+	if stats.TotalRequests == 0 {
+		return stats, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT status, COUNT(*) FROM requests WHERE "+where+" GROUP BY status", args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status codes: %w", err)
+	}
+	for rows.Next() {
+		var status int
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan status code: %w", err)
+		}
+		stats.StatusCodes[status] = count
+		stats.StatusClasses[analytics.StatusClass(status)] += count
+	}
+	rows.Close()
+
+	if stats.P50LatencyMs, err = r.percentile(ctx, where, args, stats.TotalRequests, 0.50); err != nil {
+		return nil, err
+	}
+	if stats.P95LatencyMs, err = r.percentile(ctx, where, args, stats.TotalRequests, 0.95); err != nil {
+		return nil, err
+	}
+
+	topPaths := query.TopPaths
+	if topPaths <= 0 {
+		topPaths = 10
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT path, COUNT(*), SUM(CASE WHEN status >= 500 THEN 1 ELSE 0 END), AVG(duration_ms)
+		FROM requests WHERE `+where+`
+		GROUP BY path
+		ORDER BY COUNT(*) DESC
+		LIMIT ?`, append(args, topPaths)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top paths: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path analytics.PathStats
+		if err := rows.Scan(&path.Path, &path.Requests, &path.Errors, &path.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("failed to scan path stats: %w", err)
+		}
+		stats.TopPaths = append(stats.TopPaths, path)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating path stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// percentile returns the nearest-rank percentile of request durations
+func (r *SQLiteRequestRepository) percentile(ctx context.Context, where string, args []any, total int64, p float64) (float64, error) {
+	offset := int64(math.Ceil(p*float64(total))) - 1
+	if offset < 0 {
+		offset = 0
+	}
+
+	var value float64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT duration_ms FROM requests WHERE "+where+" ORDER BY duration_ms LIMIT 1 OFFSET ?",
+		append(args, offset)...,
+	).Scan(&value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute p%.0f latency: %w", p*100, err)
+	}
+
+	return value, nil
+}
+
+// DeleteOlderThan removes requests recorded before cutoff
+func (r *SQLiteRequestRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM requests WHERE timestamp < ?", cutoff.Unix()); err != nil {
+		return fmt.Errorf("failed to delete old requests: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
 	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
 )
 
@@ -60,6 +61,49 @@ func (r *SQLiteMetricRepository) Create(ctx context.Context, metric *analytics.M
 	return nil
 }
 
+// CreateBatch inserts several metrics with a single statement
+func (r *SQLiteMetricRepository) CreateBatch(ctx context.Context, metrics []*analytics.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(metrics))
+	args := make([]interface{}, 0, len(metrics)*9)
+	for _, metric := range metrics {
+		tagsJSON, err := json.Marshal(metric.Tags())
+		if err != nil {
+			return fmt.Errorf("failed to marshal tags: %w", err)
+		}
+
+		serviceIDStr := ""
+		if metric.ServiceID() != nil {
+			serviceIDStr = metric.ServiceID().String()
+		}
+
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			metric.ID().String(),
+			metric.ProjectID().String(),
+			serviceIDStr,
+			metric.Name().String(),
+			metric.Value(),
+			string(metric.Unit()),
+			string(tagsJSON),
+			metric.Timestamp().Unix(),
+			metric.CreatedAt().Unix(),
+		)
+	}
+
+	query := `INSERT INTO metrics (id, project_id, service_id, name, value, unit, tags, timestamp, created_at)
+		VALUES ` + strings.Join(values, ", ")
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert metrics: %w", err)
+	}
+
+	return nil
+}
+
 // Query retrieves metrics based on the provided criteria
 func (r *SQLiteMetricRepository) Query(ctx context.Context, query analytics.MetricQuery) ([]*analytics.Metric, error) {
 	var args []interface{}
@@ -327,3 +371,117 @@ func (r *SQLiteMetricRepository) scanMetric(rows *sql.Rows) (*analytics.Metric,
 		time.Unix(createdAt, 0),
 	), nil
 }
+
+// metricSeriesColumns are the tags of a metric aggregation that map onto
+// columns, the only ones series can be filtered and grouped by
+var metricSeriesColumns = map[string]string{
+	"project_id": "project_id",
+	"service_id": "service_id",
+}
+
+// Series downsamples a metric into buckets of aggregation.Interval, applying
+// the aggregation function to the samples of each bucket. The bucket start is
+// returned as the timestamp of every result.
+func (r *SQLiteMetricRepository) Series(ctx context.Context, aggregation analyticsdb.MetricAggregation) ([]analyticsdb.AggregationResult, error) {
+	var function string
+	switch aggregation.Function {
+	case analyticsdb.Sum, analyticsdb.Avg, analyticsdb.Min, analyticsdb.Max, analyticsdb.Count:
+		function = strings.ToUpper(string(aggregation.Function))
+	default:
+		return nil, fmt.Errorf("unsupported aggregation function: %s", aggregation.Function)
+	}
+
+	interval := int64(aggregation.Interval / time.Second)
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be at least one second")
+	}
+
+	conditions := []string{"name = ?", "timestamp >= ?", "timestamp <= ?"}
+	args := []interface{}{
+		interval,
+		aggregation.MetricName,
+		aggregation.TimeRange.Start.Unix(),
+		aggregation.TimeRange.End.Unix(),
+	}
+
+	for tag, value := range aggregation.Tags {
+		column, ok := metricSeriesColumns[tag]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tag filter: %s", tag)
+		}
+		conditions = append(conditions, column+" = ?")
+		args = append(args, value)
+	}
+
+	groupColumns := make([]string, 0, len(aggregation.GroupBy))
+	for _, tag := range aggregation.GroupBy {
+		column, ok := metricSeriesColumns[tag]
+		if !ok {
+			return nil, fmt.Errorf("unsupported group by: %s", tag)
+		}
+		groupColumns = append(groupColumns, column)
+	}
+
+	// The modulo keeps the bucketing integer arithmetic on SQLite and DuckDB
+	selectColumns := append([]string{"timestamp - (timestamp % ?) AS bucket"}, groupColumns...)
+	groupBy := append([]string{"bucket"}, groupColumns...)
+
+	query := fmt.Sprintf(
+		"SELECT %s, %s(value) FROM metrics WHERE %s GROUP BY %s ORDER BY %s",
+		strings.Join(selectColumns, ", "),
+		function,
+		strings.Join(conditions, " AND "),
+		strings.Join(groupBy, ", "),
+		strings.Join(groupBy, ", "),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric series: %w", err)
+	}
+	defer rows.Close()
+
+	var results []analyticsdb.AggregationResult
+	for rows.Next() {
+		var bucket int64
+		var value float64
+		groups := make([]string, len(groupColumns))
+
+		dest := []interface{}{&bucket}
+		for i := range groups {
+			dest = append(dest, &groups[i])
+		}
+		dest = append(dest, &value)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan metric series: %w", err)
+		}
+
+		result := analyticsdb.AggregationResult{
+			Value:     value,
+			Timestamp: time.Unix(bucket, 0).UTC(),
+		}
+		if len(groups) > 0 {
+			result.GroupBy = make(map[string]string, len(groups))
+			for i, tag := range aggregation.GroupBy {
+				result.GroupBy[tag] = groups[i]
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return results, nil
+}
+
+// DeleteAllOlderThan removes the metrics of every project recorded before cutoff
+func (r *SQLiteMetricRepository) DeleteAllOlderThan(ctx context.Context, cutoff time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM metrics WHERE timestamp < ?", cutoff.Unix()); err != nil {
+		return fmt.Errorf("failed to delete old metrics: %w", err)
+	}
+
+	return nil
+}
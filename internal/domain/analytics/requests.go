@@ -0,0 +1,136 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestLog is a single HTTP request served by the proxy for an application
+type RequestLog struct {
+	id            string
+	projectID     uuid.UUID
+	applicationID uuid.UUID
+	router        string
+	method        string
+	host          string
+	path          string
+	status        int
+	duration      time.Duration
+	bytes         int64
+	clientIP      string
+	timestamp     time.Time
+}
+
+func NewRequestLog(
+	projectID, applicationID uuid.UUID,
+	router, method, host, path string,
+	status int,
+	duration time.Duration,
+	bytes int64,
+	clientIP string,
+	timestamp time.Time,
+) (*RequestLog, error) {
+	if status < 100 || status > 599 {
+		return nil, fmt.Errorf("invalid status code: %d", status)
+	}
+	if duration < 0 {
+		return nil, fmt.Errorf("duration cannot be negative")
+	}
+
+	return &RequestLog{
+		id:            uuid.Must(uuid.NewV7()).String(),
+		projectID:     projectID,
+		applicationID: applicationID,
+		router:        router,
+		method:        method,
+		host:          host,
+		path:          path,
+		status:        status,
+		duration:      duration,
+		bytes:         bytes,
+		clientIP:      clientIP,
+		timestamp:     timestamp,
+	}, nil
+}
+
+func (r *RequestLog) ID() string {
+	return r.id
+}
+
+func (r *RequestLog) ProjectID() uuid.UUID {
+	return r.projectID
+}
+
+func (r *RequestLog) ApplicationID() uuid.UUID {
+	return r.applicationID
+}
+
+func (r *RequestLog) Router() string {
+	return r.router
+}
+
+func (r *RequestLog) Method() string {
+	return r.method
+}
+
+func (r *RequestLog) Host() string {
+	return r.host
+}
+
+func (r *RequestLog) Path() string {
+	return r.path
+}
+
+func (r *RequestLog) Status() int {
+	return r.status
+}
+
+func (r *RequestLog) Duration() time.Duration {
+	return r.duration
+}
+
+func (r *RequestLog) Bytes() int64 {
+	return r.bytes
+}
+
+func (r *RequestLog) ClientIP() string {
+	return r.clientIP
+}
+
+func (r *RequestLog) Timestamp() time.Time {
+	return r.timestamp
+}
+
+type RequestQuery struct {
+	ApplicationID uuid.UUID
+	StartTime     time.Time
+	EndTime       time.Time
+	TopPaths      int
+}
+
+// RequestStats summarizes the traffic of an application over a time range
+type RequestStats struct {
+	TotalRequests int64            `json:"total_requests"`
+	StatusCodes   map[int]int64    `json:"status_codes"`
+	StatusClasses map[string]int64 `json:"status_classes"`
+	P50LatencyMs  float64          `json:"p50_latency_ms"`
+	P95LatencyMs  float64          `json:"p95_latency_ms"`
+	TotalBytes    int64            `json:"total_bytes"`
+	TopPaths      []PathStats      `json:"top_paths"`
+	StartTime     time.Time        `json:"start_time"`
+	EndTime       time.Time        `json:"end_time"`
+}
+
+type PathStats struct {
+	Path         string  `json:"path"`
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// StatusClass returns the "2xx" style class of an HTTP status code
+func StatusClass(status int) string {
+	return fmt.Sprintf("%dxx", status/100)
+}
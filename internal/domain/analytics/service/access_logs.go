@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	proxyContainers "github.com/mikrocloud/mikrocloud/pkg/containers/proxy"
+)
+
+const (
+	accessLogBatchSize     = 200
+	accessLogFlushInterval = 5 * time.Second
+	accessLogRetryInterval = 15 * time.Second
+	hostIndexTTL           = time.Minute
+
+	// RequestRetention is how long proxy requests are kept in the analytics database
+	RequestRetention = 30 * 24 * time.Hour
+)
+
+// AccessLogSource streams the JSON access logs of the proxy
+type AccessLogSource interface {
+	StreamAccessLogs(ctx context.Context) (io.ReadCloser, error)
+}
+
+// ApplicationLister lists the applications requests are attributed to
+type ApplicationLister interface {
+	ListApplications(ctx context.Context) ([]*applications.Application, error)
+}
+
+type applicationRef struct {
+	projectID     uuid.UUID
+	applicationID uuid.UUID
+}
+
+// AccessLogCollector tails the proxy access logs and records every request
+// served for an application in the analytics database
+type AccessLogCollector struct {
+	source    AccessLogSource
+	apps      ApplicationLister
+	analytics *AnalyticsService
+
+	hosts          map[string]applicationRef
+	hostsRefreshed time.Time
+	startedAt      time.Time
+	lastLogged     time.Time
+	lastCleanup    time.Time
+	stopCh         chan struct{}
+}
+
+func NewAccessLogCollector(source AccessLogSource, apps ApplicationLister, analytics *AnalyticsService) *AccessLogCollector {
+	return &AccessLogCollector{
+		source:    source,
+		apps:      apps,
+		analytics: analytics,
+		hosts:     make(map[string]applicationRef),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start follows the proxy logs until ctx is cancelled, reconnecting when the
+// stream ends (e.g. the proxy container restarted)
+func (c *AccessLogCollector) Start(ctx context.Context) {
+	slog.Info("Starting proxy access log collector")
+
+	// The container runtime replays the whole log on every connection, so only
+	// requests served after startup are collected
+	c.startedAt = time.Now().UTC()
+	c.lastLogged = c.startedAt
+
+	for {
+		if err := c.collect(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Proxy access log stream interrupted", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Proxy access log collector stopped due to context cancellation")
+			return
+		case <-c.stopCh:
+			slog.Info("Proxy access log collector stopped")
+			return
+		case <-time.After(accessLogRetryInterval):
+		}
+	}
+}
+
+// Stop stops the collector
+func (c *AccessLogCollector) Stop() {
+	close(c.stopCh)
+}
+
+func (c *AccessLogCollector) collect(ctx context.Context) error {
+	stream, err := c.source.StreamAccessLogs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to stream access logs: %w", err)
+	}
+	defer stream.Close()
+
+	entries := make(chan *proxyContainers.AccessLogEntry, accessLogBatchSize)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(entries)
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			entry, ok := proxyContainers.ParseAccessLogLine(scanner.Bytes())
+			if !ok {
+				continue
+			}
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errCh <- scanner.Err()
+	}()
+
+	ticker := time.NewTicker(accessLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*analytics.RequestLog, 0, accessLogBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.analytics.RecordRequests(ctx, batch); err != nil {
+			slog.Error("Failed to record proxy requests", "count", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				flush()
+				select {
+				case err := <-errCh:
+					if err != nil {
+						return err
+					}
+				default:
+				}
+				return fmt.Errorf("access log stream closed")
+			}
+
+			if request := c.toRequestLog(ctx, entry); request != nil {
+				batch = append(batch, request)
+			}
+			if len(batch) >= accessLogBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+			c.cleanup(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.stopCh:
+			flush()
+			return nil
+		}
+	}
+}
+
+func (c *AccessLogCollector) toRequestLog(ctx context.Context, entry *proxyContainers.AccessLogEntry) *analytics.RequestLog {
+	// Requests are logged when they complete, so StartUTC is not ordered and
+	// replayed lines are told apart by when they were logged
+	if entry.LoggedAt.IsZero() {
+		if entry.StartUTC.Before(c.startedAt) {
+			return nil
+		}
+	} else {
+		if !entry.LoggedAt.After(c.lastLogged) {
+			return nil
+		}
+		c.lastLogged = entry.LoggedAt
+	}
+
+	ref, ok := c.resolveHost(ctx, entry.Host())
+	if !ok {
+		return nil
+	}
+
+	request, err := analytics.NewRequestLog(
+		ref.projectID,
+		ref.applicationID,
+		strings.TrimSuffix(entry.RouterName, "@docker"),
+		entry.RequestMethod,
+		entry.Host(),
+		entry.Path(),
+		entry.DownstreamStatus,
+		time.Duration(entry.Duration),
+		entry.DownstreamContentSize,
+		entry.ClientHost,
+		entry.StartUTC,
+	)
+	if err != nil {
+		slog.Debug("Skipping invalid access log entry", "error", err)
+		return nil
+	}
+
+	return request
+}
+
+// resolveHost maps a request host to the application serving it, refreshing
+// the domain index periodically so new domains are picked up
+func (c *AccessLogCollector) resolveHost(ctx context.Context, host string) (applicationRef, bool) {
+	if time.Since(c.hostsRefreshed) > hostIndexTTL {
+		if err := c.refreshHosts(ctx); err != nil {
+			slog.Warn("Failed to refresh application domains", "error", err)
+		}
+	}
+
+	ref, ok := c.hosts[host]
+	return ref, ok
+}
+
+func (c *AccessLogCollector) refreshHosts(ctx context.Context) error {
+	apps, err := c.apps.ListApplications(ctx)
+	if err != nil {
+		return err
+	}
+
+	hosts := make(map[string]applicationRef)
+	for _, app := range apps {
+		appID, err := uuid.Parse(app.ID().String())
+		if err != nil {
+			continue
+		}
+		ref := applicationRef{projectID: app.ProjectID(), applicationID: appID}
+		for _, domain := range []string{app.Domain(), app.GeneratedDomain()} {
+			if domain != "" {
+				hosts[strings.ToLower(domain)] = ref
+			}
+		}
+	}
+
+	c.hosts = hosts
+	c.hostsRefreshed = time.Now()
+	return nil
+}
+
+func (c *AccessLogCollector) cleanup(ctx context.Context) {
+	if time.Since(c.lastCleanup) < time.Hour {
+		return
+	}
+	c.lastCleanup = time.Now()
+
+	if err := c.analytics.CleanupOldRequests(ctx, time.Now().Add(-RequestRetention)); err != nil {
+		slog.Error("Failed to clean up old proxy requests", "error", err)
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/manager"
+)
+
+const (
+	// metricSampleWorkers bounds the containers sampled at once, a Docker
+	// stats call takes about a second as it waits for a second CPU reading
+	metricSampleWorkers = 8
+
+	// MetricRetention is how long container metrics are kept in the analytics database
+	MetricRetention = 30 * 24 * time.Hour
+)
+
+// ContainerStatsSource samples the resource usage of a container
+type ContainerStatsSource interface {
+	ContainerStats(ctx context.Context, containerID string) (*manager.ContainerStats, error)
+}
+
+// DeploymentLister lists the deployments whose containers are sampled
+type DeploymentLister interface {
+	ListDeploymentsByStatus(ctx context.Context, status deployments.DeploymentStatus) ([]*deployments.Deployment, error)
+}
+
+// DatabaseLister lists the databases whose containers are sampled
+type DatabaseLister interface {
+	ListDatabasesWithContainers(ctx context.Context) ([]*databases.Database, error)
+}
+
+// sampledContainer is a running container and what its metrics are attributed to
+type sampledContainer struct {
+	containerID string
+	projectID   uuid.UUID
+	serviceID   uuid.UUID
+	tags        map[string]string
+}
+
+// MetricSampler periodically records the CPU, memory, network and block IO
+// usage of every running application and database container
+type MetricSampler struct {
+	stats       ContainerStatsSource
+	deployments DeploymentLister
+	apps        ApplicationLister
+	databases   DatabaseLister
+	analytics   *AnalyticsService
+	interval    time.Duration
+
+	// previous holds the last sample of each container, network and block IO
+	// are recorded as the difference to it
+	mu          sync.Mutex
+	previous    map[string]ContainerSample
+	lastCleanup time.Time
+	stopCh      chan struct{}
+}
+
+func NewMetricSampler(
+	stats ContainerStatsSource,
+	deploymentLister DeploymentLister,
+	appLister ApplicationLister,
+	databaseLister DatabaseLister,
+	analyticsService *AnalyticsService,
+	interval time.Duration,
+) *MetricSampler {
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	return &MetricSampler{
+		stats:       stats,
+		deployments: deploymentLister,
+		apps:        appLister,
+		databases:   databaseLister,
+		analytics:   analyticsService,
+		interval:    interval,
+		previous:    make(map[string]ContainerSample),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Interval returns how often containers are sampled
+func (s *MetricSampler) Interval() time.Duration {
+	return s.interval
+}
+
+// ContainerSample is the last resource usage read from a container
+type ContainerSample struct {
+	ContainerID string
+	ProjectID   uuid.UUID
+	ServiceID   uuid.UUID
+	// Kind is "application" or "database"
+	Kind      string
+	Stats     manager.ContainerStats
+	SampledAt time.Time
+}
+
+// Snapshot returns the last sample of every running container
+func (s *MetricSampler) Snapshot() []ContainerSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	samples := make([]ContainerSample, 0, len(s.previous))
+	for _, sample := range s.previous {
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// Start samples the containers every interval until ctx is cancelled
+func (s *MetricSampler) Start(ctx context.Context) {
+	slog.Info("Starting container metric sampler", "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sample(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Container metric sampler stopped due to context cancellation")
+			return
+		case <-s.stopCh:
+			slog.Info("Container metric sampler stopped")
+			return
+		case <-ticker.C:
+			s.sample(ctx)
+			s.cleanup(ctx)
+		}
+	}
+}
+
+// Stop stops the sampler
+func (s *MetricSampler) Stop() {
+	close(s.stopCh)
+}
+
+func (s *MetricSampler) sample(ctx context.Context) {
+	containers, err := s.resolveContainers(ctx)
+	if err != nil {
+		slog.Warn("Failed to resolve containers for metric sampling", "error", err)
+		return
+	}
+
+	now := time.Now()
+	samples := make([][]*analytics.Metric, len(containers))
+	workers := make(chan struct{}, metricSampleWorkers)
+	var wg sync.WaitGroup
+
+	for i, container := range containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			stats, err := s.stats.ContainerStats(ctx, container.containerID)
+			if err != nil {
+				slog.Debug("Failed to sample container", "container_id", container.containerID, "error", err)
+				return
+			}
+			samples[i] = s.toMetrics(container, stats, now)
+		}()
+	}
+	wg.Wait()
+
+	var metrics []*analytics.Metric
+	for _, sample := range samples {
+		metrics = append(metrics, sample...)
+	}
+
+	if err := s.analytics.RecordMetrics(ctx, metrics); err != nil {
+		slog.Error("Failed to record container metrics", "count", len(metrics), "error", err)
+	}
+
+	// Forget containers that are gone so the map doesn't grow with every deployment
+	current := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		current[container.containerID] = true
+	}
+	s.mu.Lock()
+	for containerID := range s.previous {
+		if !current[containerID] {
+			delete(s.previous, containerID)
+		}
+	}
+	s.mu.Unlock()
+}
+
+func (s *MetricSampler) toMetrics(container sampledContainer, stats *manager.ContainerStats, now time.Time) []*analytics.Metric {
+	s.mu.Lock()
+	previous, sampled := s.previous[container.containerID]
+	s.previous[container.containerID] = ContainerSample{
+		ContainerID: container.containerID,
+		ProjectID:   container.projectID,
+		ServiceID:   container.serviceID,
+		Kind:        container.tags["kind"],
+		Stats:       *stats,
+		SampledAt:   now,
+	}
+	s.mu.Unlock()
+
+	var metrics []*analytics.Metric
+	add := func(name string, value float64, unit analytics.MetricUnit) {
+		metricName, err := analytics.NewMetricName(name)
+		if err != nil {
+			return
+		}
+		serviceID := container.serviceID
+		metrics = append(metrics, analytics.NewMetric(container.projectID, &serviceID, metricName, value, unit, container.tags, now))
+	}
+
+	add(analytics.MetricContainerCPU, stats.CPUPercent, analytics.MetricUnitPercent)
+	add(analytics.MetricContainerMemoryUsage, float64(stats.MemoryUsage), analytics.MetricUnitBytes)
+	add(analytics.MetricContainerMemoryLimit, float64(stats.MemoryLimit), analytics.MetricUnitBytes)
+
+	// Counters need a previous sample to be turned into traffic per interval
+	if sampled {
+		add(analytics.MetricContainerNetworkRx, counterDelta(stats.NetworkRxBytes, previous.Stats.NetworkRxBytes), analytics.MetricUnitBytes)
+		add(analytics.MetricContainerNetworkTx, counterDelta(stats.NetworkTxBytes, previous.Stats.NetworkTxBytes), analytics.MetricUnitBytes)
+		add(analytics.MetricContainerBlockRead, counterDelta(stats.BlockReadBytes, previous.Stats.BlockReadBytes), analytics.MetricUnitBytes)
+		add(analytics.MetricContainerBlockWritten, counterDelta(stats.BlockWriteBytes, previous.Stats.BlockWriteBytes), analytics.MetricUnitBytes)
+	}
+
+	return metrics
+}
+
+// counterDelta returns how much a counter grew. A smaller value means the
+// container restarted and the counter started over.
+func counterDelta(current, previous uint64) float64 {
+	if current < previous {
+		return float64(current)
+	}
+	return float64(current - previous)
+}
+
+func (s *MetricSampler) resolveContainers(ctx context.Context) ([]sampledContainer, error) {
+	apps, err := s.apps.ListApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projectsByApp := make(map[string]uuid.UUID, len(apps))
+	for _, app := range apps {
+		projectsByApp[app.ID().String()] = app.ProjectID()
+	}
+
+	running, err := s.deployments.ListDeploymentsByStatus(ctx, deployments.DeploymentStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []sampledContainer
+	for _, deployment := range running {
+		projectID, ok := projectsByApp[deployment.ApplicationID().String()]
+		if !ok || deployment.ContainerID() == "" {
+			continue
+		}
+
+		appID, err := uuid.Parse(deployment.ApplicationID().String())
+		if err != nil {
+			continue
+		}
+
+		containers = append(containers, sampledContainer{
+			containerID: deployment.ContainerID(),
+			projectID:   projectID,
+			serviceID:   appID,
+			tags: map[string]string{
+				"kind":          "application",
+				"container_id":  deployment.ContainerID(),
+				"deployment_id": deployment.ID().String(),
+			},
+		})
+	}
+
+	dbs, err := s.databases.ListDatabasesWithContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, db := range dbs {
+		if db.Status() != databases.DatabaseStatusRunning {
+			continue
+		}
+
+		databaseID, err := uuid.Parse(db.ID().String())
+		if err != nil {
+			continue
+		}
+
+		containers = append(containers, sampledContainer{
+			containerID: db.ContainerID(),
+			projectID:   db.ProjectID(),
+			serviceID:   databaseID,
+			tags: map[string]string{
+				"kind":          "database",
+				"container_id":  db.ContainerID(),
+				"database_type": string(db.Type()),
+			},
+		})
+	}
+
+	return containers, nil
+}
+
+func (s *MetricSampler) cleanup(ctx context.Context) {
+	if time.Since(s.lastCleanup) < time.Hour {
+		return
+	}
+	s.lastCleanup = time.Now()
+
+	if err := s.analytics.CleanupMetricsBefore(ctx, time.Now().Add(-MetricRetention)); err != nil {
+		slog.Error("Failed to clean up old container metrics", "error", err)
+	}
+}
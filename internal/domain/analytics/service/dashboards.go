@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics/repository"
+)
+
+const (
+	// widgetSeriesPoints is how many buckets a chart widget is split in
+	widgetSeriesPoints = 120
+	// defaultWidgetRows is how many samples a table widget lists by default
+	defaultWidgetRows = 50
+)
+
+var ErrDashboardNotFound = errors.New("dashboard not found")
+
+// DashboardService manages the dashboards of projects and resolves the metric
+// queries their widgets are bound to
+type DashboardService struct {
+	repo      repository.DashboardRepository
+	analytics *AnalyticsService
+}
+
+func NewDashboardService(repo repository.DashboardRepository, analytics *AnalyticsService) *DashboardService {
+	return &DashboardService{
+		repo:      repo,
+		analytics: analytics,
+	}
+}
+
+type DashboardCommand struct {
+	Name      string
+	Layout    analytics.DashboardLayout
+	Widgets   []analytics.DashboardWidget
+	IsDefault bool
+}
+
+func (s *DashboardService) CreateDashboard(ctx context.Context, projectID uuid.UUID, cmd DashboardCommand) (*analytics.Dashboard, error) {
+	name, err := analytics.NewDashboardName(cmd.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := analytics.NewDashboard(projectID, name, cmd.IsDefault)
+	if err := dashboard.Update(name, cmd.Layout, cmd.Widgets); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, dashboard); err != nil {
+		return nil, fmt.Errorf("failed to create dashboard: %w", err)
+	}
+
+	if err := s.keepSingleDefault(ctx, dashboard); err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// GetDashboard returns a dashboard of a project, ErrDashboardNotFound when it
+// belongs to another project
+func (s *DashboardService) GetDashboard(ctx context.Context, projectID uuid.UUID, dashboardID string) (*analytics.Dashboard, error) {
+	dashboard, err := s.repo.GetByID(ctx, analytics.DashboardIDFromString(dashboardID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDashboardNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+
+	if dashboard.ProjectID() != projectID {
+		return nil, ErrDashboardNotFound
+	}
+
+	return dashboard, nil
+}
+
+func (s *DashboardService) ListDashboards(ctx context.Context, projectID uuid.UUID) ([]*analytics.Dashboard, error) {
+	dashboards, err := s.repo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dashboards: %w", err)
+	}
+	return dashboards, nil
+}
+
+func (s *DashboardService) UpdateDashboard(ctx context.Context, projectID uuid.UUID, dashboardID string, cmd DashboardCommand) (*analytics.Dashboard, error) {
+	dashboard, err := s.GetDashboard(ctx, projectID, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := analytics.NewDashboardName(cmd.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dashboard.Update(name, cmd.Layout, cmd.Widgets); err != nil {
+		return nil, err
+	}
+
+	if cmd.IsDefault {
+		dashboard.SetAsDefault()
+	} else {
+		dashboard.UnsetAsDefault()
+	}
+
+	if err := s.repo.Update(ctx, dashboard); err != nil {
+		return nil, fmt.Errorf("failed to update dashboard: %w", err)
+	}
+
+	if err := s.keepSingleDefault(ctx, dashboard); err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+func (s *DashboardService) DeleteDashboard(ctx context.Context, projectID uuid.UUID, dashboardID string) error {
+	dashboard, err := s.GetDashboard(ctx, projectID, dashboardID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, dashboard.ID()); err != nil {
+		return fmt.Errorf("failed to delete dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// keepSingleDefault unsets the previous default dashboard of the project
+func (s *DashboardService) keepSingleDefault(ctx context.Context, dashboard *analytics.Dashboard) error {
+	if !dashboard.IsDefault() {
+		return nil
+	}
+
+	if err := s.repo.UnsetDefault(ctx, dashboard.ProjectID(), dashboard.ID()); err != nil {
+		return fmt.Errorf("failed to unset default dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// WidgetData is the result of the metric query of a widget. Charts get
+// points, counters and gauges a value and tables the latest samples.
+type WidgetData struct {
+	WidgetID string
+	Value    *float64
+	Points   []analyticsdb.AggregationResult
+	Rows     []*analytics.Metric
+	// Error is set when the query of this widget failed, the other widgets
+	// are still resolved
+	Error string
+}
+
+// GetDashboardData resolves the data of every widget of a dashboard ending at
+// end. Text widgets have no data and are left out.
+func (s *DashboardService) GetDashboardData(ctx context.Context, dashboard *analytics.Dashboard, end time.Time) []WidgetData {
+	data := make([]WidgetData, 0, len(dashboard.Widgets()))
+
+	for _, widget := range dashboard.Widgets() {
+		if widget.Type == analytics.WidgetTypeText {
+			continue
+		}
+
+		result := WidgetData{WidgetID: widget.ID}
+		if err := s.resolveWidget(ctx, dashboard.ProjectID(), widget, end, &result); err != nil {
+			result.Error = err.Error()
+		}
+		data = append(data, result)
+	}
+
+	return data
+}
+
+func (s *DashboardService) resolveWidget(ctx context.Context, projectID uuid.UUID, widget analytics.DashboardWidget, end time.Time, result *WidgetData) error {
+	query, err := widget.DataSource.MetricQuery(projectID, end)
+	if err != nil {
+		return err
+	}
+
+	function, err := widget.DataSource.Function()
+	if err != nil {
+		return err
+	}
+
+	switch widget.Type {
+	case analytics.WidgetTypeChart:
+		aggregation := analyticsdb.MetricAggregation{
+			MetricName: query.Name.String(),
+			Function:   analyticsdb.AggregationFunc(function),
+			Tags:       map[string]string{"project_id": projectID.String()},
+			TimeRange:  analyticsdb.TimeRange{Start: *query.StartTime, End: *query.EndTime},
+			Interval:   max(query.EndTime.Sub(*query.StartTime)/widgetSeriesPoints, time.Minute).Truncate(time.Second),
+		}
+		if query.ServiceID != nil {
+			aggregation.Tags["service_id"] = query.ServiceID.String()
+		}
+
+		points, err := s.analytics.GetMetricSeries(ctx, aggregation)
+		if err != nil {
+			return err
+		}
+		if points == nil {
+			points = []analyticsdb.AggregationResult{}
+		}
+		result.Points = points
+
+	case analytics.WidgetTypeCounter, analytics.WidgetTypeGauge:
+		aggregates, err := s.analytics.AggregateMetrics(ctx, query)
+		if err != nil {
+			return err
+		}
+		if value, ok := aggregates[query.Name.String()+"_"+function]; ok {
+			result.Value = &value
+		}
+
+	case analytics.WidgetTypeTable:
+		if query.Limit == 0 {
+			query.Limit = defaultWidgetRows
+		}
+
+		rows, err := s.analytics.QueryMetrics(ctx, query)
+		if err != nil {
+			return err
+		}
+		result.Rows = rows
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+// reportData is what a report is rendered from, sections left nil are not
+// part of the report type
+type reportData struct {
+	Name        string
+	Description string
+	Project     string
+	Period      analytics.ReportPeriod
+	Start       time.Time
+	End         time.Time
+	Usage       *usageSection
+	Performance *performanceSection
+	Deployments *deploymentSection
+	GeneratedAt time.Time
+}
+
+type usageSection struct {
+	Columns []string
+	Rows    []usageRow
+}
+
+type usageRow struct {
+	Name   string
+	Kind   string
+	Values []string
+}
+
+type performanceSection struct {
+	Rows []performanceRow
+}
+
+type performanceRow struct {
+	Name         string
+	Requests     int64
+	ServerErrors int64
+	ClientErrors int64
+	ErrorRate    float64
+	P50LatencyMs float64
+	P95LatencyMs float64
+}
+
+type deploymentSection struct {
+	Rows      []deploymentRow
+	Total     int
+	Succeeded int
+	Failed    int
+	PerDay    float64
+}
+
+type deploymentRow struct {
+	Name      string
+	Total     int
+	Succeeded int
+	Failed    int
+	PerDay    float64
+}
+
+// usageColumn renders a container metric from the aggregates of a service
+type usageColumn struct {
+	label  string
+	format func(metric string, aggregates map[string]float64) string
+}
+
+// noData is shown for services without samples of a metric
+const noData = "–"
+
+func averageAndPeak(format func(float64) string) func(string, map[string]float64) string {
+	return func(metric string, aggregates map[string]float64) string {
+		if aggregates[metric+"_count"] == 0 {
+			return noData
+		}
+		return format(aggregates[metric+"_avg"]) + " / " + format(aggregates[metric+"_max"])
+	}
+}
+
+func aggregate(suffix string, format func(float64) string) func(string, map[string]float64) string {
+	return func(metric string, aggregates map[string]float64) string {
+		if aggregates[metric+"_count"] == 0 {
+			return noData
+		}
+		return format(aggregates[metric+suffix])
+	}
+}
+
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value)
+}
+
+func formatBytes(value float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", value, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+var usageColumns = map[string]usageColumn{
+	analytics.MetricContainerCPU:          {label: "CPU (avg / peak)", format: averageAndPeak(formatPercent)},
+	analytics.MetricContainerMemoryUsage:  {label: "Memory (avg / peak)", format: averageAndPeak(formatBytes)},
+	analytics.MetricContainerMemoryLimit:  {label: "Memory limit", format: aggregate("_max", formatBytes)},
+	analytics.MetricContainerNetworkRx:    {label: "Network in", format: aggregate("_sum", formatBytes)},
+	analytics.MetricContainerNetworkTx:    {label: "Network out", format: aggregate("_sum", formatBytes)},
+	analytics.MetricContainerBlockRead:    {label: "Disk read", format: aggregate("_sum", formatBytes)},
+	analytics.MetricContainerBlockWritten: {label: "Disk written", format: aggregate("_sum", formatBytes)},
+}
+
+// reportTemplate keeps its styles inline, mail clients drop style sheets
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string {
+		return t.UTC().Format("Jan 2, 2006 15:04 UTC")
+	},
+	"decimal": func(value float64) string {
+		return fmt.Sprintf("%.2f", value)
+	},
+	"percent": formatPercent,
+	"ms": func(value float64) string {
+		return fmt.Sprintf("%.0f ms", value)
+	},
+}).Parse(reportHTML))
+
+const reportHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+</head>
+<body style="margin:0;padding:24px;background:#f6f7f9;font-family:Arial,Helvetica,sans-serif;color:#1f2933;">
+<div style="max-width:860px;margin:0 auto;background:#ffffff;border:1px solid #e4e7eb;border-radius:8px;padding:24px;">
+<h1 style="font-size:22px;margin:0 0 4px;">{{.Name}}</h1>
+<p style="margin:0;color:#7b8794;font-size:14px;">
+{{.Project}} &middot; {{.Period}} report &middot; {{datetime .Start}} &ndash; {{datetime .End}}
+</p>
+{{if .Description}}<p style="font-size:14px;">{{.Description}}</p>{{end}}
+
+{{with .Usage}}
+<h2 style="font-size:17px;margin:28px 0 8px;">Resource usage</h2>
+<table style="width:100%;border-collapse:collapse;font-size:13px;">
+<tr style="text-align:left;border-bottom:2px solid #e4e7eb;">
+<th style="padding:6px;">Service</th>
+{{range .Columns}}<th style="padding:6px;">{{.}}</th>
+{{end}}</tr>
+{{range .Rows}}<tr style="border-bottom:1px solid #e4e7eb;">
+<td style="padding:6px;">{{.Name}} <span style="color:#7b8794;">{{.Kind}}</span></td>
+{{range .Values}}<td style="padding:6px;">{{.}}</td>
+{{end}}</tr>
+{{else}}<tr><td style="padding:6px;color:#7b8794;">No services in this project.</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Performance}}
+<h2 style="font-size:17px;margin:28px 0 8px;">Requests and error rates</h2>
+<table style="width:100%;border-collapse:collapse;font-size:13px;">
+<tr style="text-align:left;border-bottom:2px solid #e4e7eb;">
+<th style="padding:6px;">Application</th>
+<th style="padding:6px;">Requests</th>
+<th style="padding:6px;">5xx</th>
+<th style="padding:6px;">4xx</th>
+<th style="padding:6px;">Error rate</th>
+<th style="padding:6px;">p50</th>
+<th style="padding:6px;">p95</th>
+</tr>
+{{range .Rows}}<tr style="border-bottom:1px solid #e4e7eb;">
+<td style="padding:6px;">{{.Name}}</td>
+<td style="padding:6px;">{{.Requests}}</td>
+<td style="padding:6px;">{{.ServerErrors}}</td>
+<td style="padding:6px;">{{.ClientErrors}}</td>
+<td style="padding:6px;">{{percent .ErrorRate}}</td>
+<td style="padding:6px;">{{ms .P50LatencyMs}}</td>
+<td style="padding:6px;">{{ms .P95LatencyMs}}</td>
+</tr>
+{{else}}<tr><td style="padding:6px;color:#7b8794;">No applications in this project.</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Deployments}}
+<h2 style="font-size:17px;margin:28px 0 8px;">Deployment frequency</h2>
+<p style="font-size:14px;margin:0 0 8px;">
+{{.Total}} deployments, {{.Succeeded}} succeeded and {{.Failed}} failed, {{decimal .PerDay}} per day.
+</p>
+<table style="width:100%;border-collapse:collapse;font-size:13px;">
+<tr style="text-align:left;border-bottom:2px solid #e4e7eb;">
+<th style="padding:6px;">Application</th>
+<th style="padding:6px;">Deployments</th>
+<th style="padding:6px;">Succeeded</th>
+<th style="padding:6px;">Failed</th>
+<th style="padding:6px;">Per day</th>
+</tr>
+{{range .Rows}}<tr style="border-bottom:1px solid #e4e7eb;">
+<td style="padding:6px;">{{.Name}}</td>
+<td style="padding:6px;">{{.Total}}</td>
+<td style="padding:6px;">{{.Succeeded}}</td>
+<td style="padding:6px;">{{.Failed}}</td>
+<td style="padding:6px;">{{decimal .PerDay}}</td>
+</tr>
+{{else}}<tr><td style="padding:6px;color:#7b8794;">No applications in this project.</td></tr>
+{{end}}</table>
+{{end}}
+
+<p style="margin:28px 0 0;font-size:12px;color:#7b8794;">Generated {{datetime .GeneratedAt}}</p>
+</div>
+</body>
+</html>
+`
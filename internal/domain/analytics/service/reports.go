@@ -0,0 +1,523 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics/repository"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	"github.com/mikrocloud/mikrocloud/internal/domain/projects"
+)
+
+const (
+	// maxReportRunsListed caps the run history returned for a report
+	maxReportRunsListed = 100
+
+	// ReportRunRetention is how long generated reports are kept for download
+	ReportRunRetention = 180 * 24 * time.Hour
+)
+
+// defaultReportMetrics are the usage columns of reports without metrics in
+// their config
+var defaultReportMetrics = []string{
+	analytics.MetricContainerCPU,
+	analytics.MetricContainerMemoryUsage,
+	analytics.MetricContainerNetworkRx,
+	analytics.MetricContainerNetworkTx,
+}
+
+var (
+	ErrReportNotFound    = errors.New("report not found")
+	ErrReportRunNotFound = errors.New("report run not found")
+)
+
+type ProjectGetter interface {
+	GetProject(ctx context.Context, id string) (*projects.Project, error)
+}
+
+type ProjectApplicationLister interface {
+	ListApplicationsByProject(ctx context.Context, projectID uuid.UUID) ([]*applications.Application, error)
+}
+
+type ProjectDatabaseLister interface {
+	ListDatabases(ctx context.Context, projectID uuid.UUID) ([]*databases.Database, error)
+}
+
+type ApplicationDeploymentLister interface {
+	ListDeploymentsByApplication(ctx context.Context, applicationID applications.ApplicationID) ([]*deployments.Deployment, error)
+}
+
+// EmailSender delivers generated reports, the alert notifier sends them
+// through the SMTP server of the instance settings
+type EmailSender interface {
+	SendEmail(ctx context.Context, recipients []string, subject, contentType, body string) error
+}
+
+// ReportService manages the scheduled reports of projects and generates them
+// from the metrics, request logs and deployments of the period they cover
+type ReportService struct {
+	reportRepo  repository.ReportRepository
+	runRepo     repository.ReportRunRepository
+	analytics   *AnalyticsService
+	projects    ProjectGetter
+	apps        ProjectApplicationLister
+	databases   ProjectDatabaseLister
+	deployments ApplicationDeploymentLister
+	mailer      EmailSender
+}
+
+func NewReportService(
+	reportRepo repository.ReportRepository,
+	runRepo repository.ReportRunRepository,
+	analyticsService *AnalyticsService,
+	projectGetter ProjectGetter,
+	appLister ProjectApplicationLister,
+	databaseLister ProjectDatabaseLister,
+	deploymentLister ApplicationDeploymentLister,
+	mailer EmailSender,
+) *ReportService {
+	return &ReportService{
+		reportRepo:  reportRepo,
+		runRepo:     runRepo,
+		analytics:   analyticsService,
+		projects:    projectGetter,
+		apps:        appLister,
+		databases:   databaseLister,
+		deployments: deploymentLister,
+		mailer:      mailer,
+	}
+}
+
+type ReportCommand struct {
+	Name        string
+	Description string
+	Type        analytics.ReportType
+	Period      analytics.ReportPeriod
+	Config      analytics.ReportConfig
+}
+
+func (s *ReportService) CreateReport(ctx context.Context, projectID uuid.UUID, cmd ReportCommand) (*analytics.Report, error) {
+	name, err := analytics.NewReportName(cmd.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := analytics.ValidateReport(cmd.Type, cmd.Period, cmd.Config); err != nil {
+		return nil, err
+	}
+
+	report := analytics.NewReport(projectID, name, cmd.Description, cmd.Type, cmd.Period, cmd.Config)
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetReport returns a report of a project, ErrReportNotFound when it belongs
+// to another project
+func (s *ReportService) GetReport(ctx context.Context, projectID uuid.UUID, reportID string) (*analytics.Report, error) {
+	report, err := s.reportRepo.GetByID(ctx, analytics.ReportIDFromString(reportID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	if report.ProjectID() != projectID {
+		return nil, ErrReportNotFound
+	}
+
+	return report, nil
+}
+
+func (s *ReportService) ListReports(ctx context.Context, projectID uuid.UUID) ([]*analytics.Report, error) {
+	reports, err := s.reportRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (s *ReportService) UpdateReport(ctx context.Context, projectID uuid.UUID, reportID string, cmd ReportCommand) (*analytics.Report, error) {
+	report, err := s.GetReport(ctx, projectID, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := analytics.NewReportName(cmd.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := report.Update(name, cmd.Description, cmd.Type, cmd.Period, cmd.Config); err != nil {
+		return nil, err
+	}
+
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+
+	return report, nil
+}
+
+func (s *ReportService) DeleteReport(ctx context.Context, projectID uuid.UUID, reportID string) error {
+	report, err := s.GetReport(ctx, projectID, reportID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.reportRepo.Delete(ctx, report.ID()); err != nil {
+		return fmt.Errorf("failed to delete report: %w", err)
+	}
+
+	return nil
+}
+
+// ListRuns returns the generated runs of a report without their content
+func (s *ReportService) ListRuns(ctx context.Context, report *analytics.Report) ([]*analytics.ReportRun, error) {
+	runs, err := s.runRepo.ListByReport(ctx, report.ID(), maxReportRunsListed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report runs: %w", err)
+	}
+	return runs, nil
+}
+
+// GetRun returns a run of a report with its content
+func (s *ReportService) GetRun(ctx context.Context, report *analytics.Report, runID string) (*analytics.ReportRun, error) {
+	run, err := s.runRepo.GetByID(ctx, analytics.ReportRunIDFromString(runID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReportRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report run: %w", err)
+	}
+
+	if run.ReportID() != report.ID() {
+		return nil, ErrReportRunNotFound
+	}
+
+	return run, nil
+}
+
+// GenerateReport renders the report for the period ending at end, stores it
+// for download and emails it to the recipients of the report. The run is
+// returned without error when only the email failed, its error message says
+// so.
+func (s *ReportService) GenerateReport(ctx context.Context, report *analytics.Report, end time.Time) (*analytics.ReportRun, error) {
+	run := analytics.NewReportRun(report, report.Period().Start(end), end)
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create report run: %w", err)
+	}
+
+	report.ChangeStatus(analytics.ReportStatusGenerating)
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+
+	content, genErr := s.render(ctx, report, run.PeriodStart(), run.PeriodEnd())
+	if genErr != nil {
+		run.Fail(genErr.Error())
+		report.ChangeStatus(analytics.ReportStatusError)
+	} else {
+		run.Complete(content)
+		report.ChangeStatus(analytics.ReportStatusCompleted)
+
+		if recipients := report.Config().Recipients; len(recipients) > 0 {
+			subject := fmt.Sprintf("%s: %s to %s", report.Name().String(),
+				run.PeriodStart().UTC().Format("Jan 2 15:04"), run.PeriodEnd().UTC().Format("Jan 2 15:04 UTC"))
+			run.Emailed(recipients, s.mailer.SendEmail(ctx, recipients, subject, "text/html; charset=utf-8", content))
+		}
+	}
+
+	if err := s.runRepo.Update(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to update report run: %w", err)
+	}
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+
+	if genErr != nil {
+		return run, fmt.Errorf("failed to generate report: %w", genErr)
+	}
+
+	return run, nil
+}
+
+// reportService is a service (application or database) of a project included
+// in a report
+type reportService struct {
+	id   uuid.UUID
+	name string
+	kind string
+	app  *applications.Application
+}
+
+func (s *ReportService) render(ctx context.Context, report *analytics.Report, start, end time.Time) (string, error) {
+	project, err := s.projects.GetProject(ctx, report.ProjectID().String())
+	if err != nil {
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+
+	services, err := s.listServices(ctx, report)
+	if err != nil {
+		return "", err
+	}
+
+	data := reportData{
+		Name:        report.Name().String(),
+		Description: report.Description(),
+		Project:     project.Name().String(),
+		Period:      report.Period(),
+		Start:       start,
+		End:         end,
+		GeneratedAt: time.Now(),
+	}
+
+	usage, performance, deploys := report.Type().Sections()
+	if usage {
+		if data.Usage, err = s.usageSection(ctx, report, services, start, end); err != nil {
+			return "", err
+		}
+	}
+	if performance {
+		if data.Performance, err = s.performanceSection(ctx, services, start, end); err != nil {
+			return "", err
+		}
+	}
+	if deploys {
+		if data.Deployments, err = s.deploymentSection(ctx, services, start, end); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// listServices returns the applications and databases of the report project,
+// narrowed down to the services of the report config when it lists any
+func (s *ReportService) listServices(ctx context.Context, report *analytics.Report) ([]reportService, error) {
+	apps, err := s.apps.ListApplicationsByProject(ctx, report.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	dbs, err := s.databases.ListDatabases(ctx, report.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	filter := report.Config().Services
+	services := make([]reportService, 0, len(apps)+len(dbs))
+	for _, app := range apps {
+		id, err := uuid.Parse(app.ID().String())
+		if err != nil || (len(filter) > 0 && !slices.Contains(filter, id.String())) {
+			continue
+		}
+		services = append(services, reportService{id: id, name: app.Name().String(), kind: "application", app: app})
+	}
+	for _, db := range dbs {
+		id, err := uuid.Parse(db.ID().String())
+		if err != nil || (len(filter) > 0 && !slices.Contains(filter, id.String())) {
+			continue
+		}
+		services = append(services, reportService{id: id, name: db.Name().String(), kind: "database"})
+	}
+
+	return services, nil
+}
+
+func (s *ReportService) usageSection(ctx context.Context, report *analytics.Report, services []reportService, start, end time.Time) (*usageSection, error) {
+	metrics := report.Config().Metrics
+	if len(metrics) == 0 {
+		metrics = defaultReportMetrics
+	}
+
+	section := &usageSection{Rows: make([]usageRow, 0, len(services))}
+	for _, metric := range metrics {
+		section.Columns = append(section.Columns, usageColumns[metric].label)
+	}
+
+	for _, svc := range services {
+		aggregates, err := s.analytics.AggregateMetrics(ctx, analytics.MetricQuery{
+			ProjectID: report.ProjectID(),
+			ServiceID: &svc.id,
+			StartTime: &start,
+			EndTime:   &end,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate metrics of %s: %w", svc.name, err)
+		}
+
+		row := usageRow{Name: svc.name, Kind: svc.kind}
+		for _, metric := range metrics {
+			row.Values = append(row.Values, usageColumns[metric].format(metric, aggregates))
+		}
+		section.Rows = append(section.Rows, row)
+	}
+
+	return section, nil
+}
+
+func (s *ReportService) performanceSection(ctx context.Context, services []reportService, start, end time.Time) (*performanceSection, error) {
+	section := &performanceSection{Rows: []performanceRow{}}
+	for _, svc := range services {
+		if svc.app == nil {
+			continue
+		}
+
+		stats, err := s.analytics.GetRequestStats(ctx, svc.id, start, end, 5)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request stats of %s: %w", svc.name, err)
+		}
+
+		row := performanceRow{
+			Name:         svc.name,
+			Requests:     stats.TotalRequests,
+			ServerErrors: stats.StatusClasses["5xx"],
+			ClientErrors: stats.StatusClasses["4xx"],
+			P50LatencyMs: stats.P50LatencyMs,
+			P95LatencyMs: stats.P95LatencyMs,
+		}
+		if stats.TotalRequests > 0 {
+			row.ErrorRate = float64(row.ServerErrors) / float64(stats.TotalRequests) * 100
+		}
+		section.Rows = append(section.Rows, row)
+	}
+
+	return section, nil
+}
+
+func (s *ReportService) deploymentSection(ctx context.Context, services []reportService, start, end time.Time) (*deploymentSection, error) {
+	days := max(end.Sub(start).Hours()/24, 1.0/24)
+	section := &deploymentSection{Rows: []deploymentRow{}}
+
+	for _, svc := range services {
+		if svc.app == nil {
+			continue
+		}
+
+		list, err := s.deployments.ListDeploymentsByApplication(ctx, svc.app.ID())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments of %s: %w", svc.name, err)
+		}
+
+		row := deploymentRow{Name: svc.name}
+		for _, deployment := range list {
+			if deployment.StartedAt().Before(start) || !deployment.StartedAt().Before(end) {
+				continue
+			}
+
+			row.Total++
+			switch deployment.Status() {
+			case deployments.DeploymentStatusRunning, deployments.DeploymentStatusStopped:
+				row.Succeeded++
+			case deployments.DeploymentStatusFailed:
+				row.Failed++
+			}
+		}
+		row.PerDay = float64(row.Total) / days
+
+		section.Rows = append(section.Rows, row)
+		section.Total += row.Total
+		section.Succeeded += row.Succeeded
+		section.Failed += row.Failed
+	}
+	section.PerDay = float64(section.Total) / days
+
+	return section, nil
+}
+
+// ReportScheduler generates the reports that are due every interval and
+// removes runs past their retention
+type ReportScheduler struct {
+	reportService *ReportService
+	interval      time.Duration
+	lastCleanup   time.Time
+	stopCh        chan struct{}
+}
+
+func NewReportScheduler(reportService *ReportService, interval time.Duration) *ReportScheduler {
+	if interval == 0 {
+		interval = time.Minute
+	}
+
+	return &ReportScheduler{
+		reportService: reportService,
+		interval:      interval,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Start generates the due reports every interval until ctx is cancelled
+func (s *ReportScheduler) Start(ctx context.Context) {
+	slog.Info("Starting report scheduler", "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Report scheduler stopped due to context cancellation")
+			return
+		case <-s.stopCh:
+			slog.Info("Report scheduler stopped")
+			return
+		case <-ticker.C:
+			s.generateDue(ctx)
+		}
+	}
+}
+
+// Stop stops the scheduler
+func (s *ReportScheduler) Stop() {
+	close(s.stopCh)
+}
+
+func (s *ReportScheduler) generateDue(ctx context.Context) {
+	reports, err := s.reportService.reportRepo.List(ctx)
+	if err != nil {
+		slog.Error("Failed to list reports", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, report := range reports {
+		if !report.Due(now) {
+			continue
+		}
+
+		run, err := s.reportService.GenerateReport(ctx, report, now)
+		if err != nil {
+			slog.Error("Failed to generate report", "report_id", report.ID().String(), "error", err)
+			continue
+		}
+		if run.ErrorMessage() != "" {
+			slog.Warn("Report generated with errors", "report_id", report.ID().String(), "error", run.ErrorMessage())
+		}
+	}
+
+	if now.Sub(s.lastCleanup) >= time.Hour {
+		s.lastCleanup = now
+		if err := s.reportService.runRepo.DeleteOlderThan(ctx, now.Add(-ReportRunRetention)); err != nil {
+			slog.Error("Failed to clean up report runs", "error", err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+)
+
+// RecordRequests stores a batch of proxy requests
+func (s *AnalyticsService) RecordRequests(ctx context.Context, requests []*analytics.RequestLog) error {
+	return s.requestRepo.CreateBatch(ctx, requests)
+}
+
+// GetRequestStats returns request counts, status codes, latency percentiles and
+// top paths of an application between start and end
+func (s *AnalyticsService) GetRequestStats(ctx context.Context, applicationID uuid.UUID, start, end time.Time, topPaths int) (*analytics.RequestStats, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	stats, err := s.requestRepo.Stats(ctx, analytics.RequestQuery{
+		ApplicationID: applicationID,
+		StartTime:     start,
+		EndTime:       end,
+		TopPaths:      topPaths,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get request stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CleanupOldRequests removes requests recorded before cutoff
+func (s *AnalyticsService) CleanupOldRequests(ctx context.Context, cutoff time.Time) error {
+	return s.requestRepo.DeleteOlderThan(ctx, cutoff)
+}
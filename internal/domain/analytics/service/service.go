@@ -6,18 +6,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	analyticsdb "github.com/mikrocloud/mikrocloud/internal/database/analytics"
 	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
 	"github.com/mikrocloud/mikrocloud/internal/domain/analytics/repository"
 )
 
 // AnalyticsService handles analytics business logic
 type AnalyticsService struct {
-	metricRepo repository.MetricRepository
+	metricRepo  repository.MetricRepository
+	requestRepo repository.RequestRepository
 }
 
-func NewAnalyticsService(metricRepo repository.MetricRepository) *AnalyticsService {
+func NewAnalyticsService(metricRepo repository.MetricRepository, requestRepo repository.RequestRepository) *AnalyticsService {
 	return &AnalyticsService{
-		metricRepo: metricRepo,
+		metricRepo:  metricRepo,
+		requestRepo: requestRepo,
 	}
 }
 
@@ -95,3 +98,27 @@ func (s *AnalyticsService) CleanupOldMetrics(ctx context.Context, projectID stri
 	cutoff := int64(cutoffDays)
 	return s.metricRepo.DeleteOlderThan(ctx, projectID, cutoff)
 }
+
+// RecordMetrics stores a batch of metrics
+func (s *AnalyticsService) RecordMetrics(ctx context.Context, metrics []*analytics.Metric) error {
+	return s.metricRepo.CreateBatch(ctx, metrics)
+}
+
+// GetMetricSeries downsamples a metric over the aggregation time range
+func (s *AnalyticsService) GetMetricSeries(ctx context.Context, aggregation analyticsdb.MetricAggregation) ([]analyticsdb.AggregationResult, error) {
+	if !aggregation.TimeRange.End.After(aggregation.TimeRange.Start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	series, err := s.metricRepo.Series(ctx, aggregation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metric series: %w", err)
+	}
+
+	return series, nil
+}
+
+// CleanupMetricsBefore removes the metrics of every project recorded before cutoff
+func (s *AnalyticsService) CleanupMetricsBefore(ctx context.Context, cutoff time.Time) error {
+	return s.metricRepo.DeleteAllOlderThan(ctx, cutoff)
+}
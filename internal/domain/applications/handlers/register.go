@@ -3,7 +3,9 @@ package handlers
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+	analyticsHandler "github.com/mikrocloud/mikrocloud/internal/domain/analytics/handlers"
 	deploymentsHandler "github.com/mikrocloud/mikrocloud/internal/domain/deployments/handlers"
+	proxyHandler "github.com/mikrocloud/mikrocloud/internal/domain/proxy/handlers"
 )
 
 func RegisterApplicationRoutes(r chi.Router, deps *deps.Dependencies) {
@@ -32,6 +34,10 @@ func RegisterApplicationRoutes(r chi.Router, deps *deps.Dependencies) {
 
 			// Deployment routes within application
 			deploymentsHandler.RegisterDeploymentsRoutes(r, deps)
+			proxyHandler.RegisterRouteMiddlewareRoutes(r, deps)
+			proxyHandler.RegisterApplicationPageRoutes(r, deps)
+			proxyHandler.RegisterRedirectRuleRoutes(r, deps)
+			analyticsHandler.RegisterApplicationAnalyticsRoutes(r, deps)
 		})
 	})
 }
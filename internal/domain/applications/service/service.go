@@ -343,3 +343,16 @@ func (s *ApplicationService) UpdateGeneral(ctx context.Context, cmd UpdateGenera
 
 	return app, nil
 }
+
+// ReapplyRouting recreates the running container in the background so that
+// routing labels pick up changes made outside of the application itself
+func (s *ApplicationService) ReapplyRouting(ctx context.Context, id applications.ApplicationID) {
+	if s.containerRecreator == nil {
+		return
+	}
+
+	go func() {
+		bgCtx := context.WithoutCancel(ctx)
+		_ = s.containerRecreator.RecreateContainer(bgCtx, id, s.GetApplication)
+	}()
+}
@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups"
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	databaseService "github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+)
+
+type BackupHandler struct {
+	backupService *service.BackupService
+	dbService     *databaseService.DatabaseService
+	validator     *validator.Validate
+}
+
+func NewBackupHandler(backupService *service.BackupService, dbService *databaseService.DatabaseService) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+		dbService:     dbService,
+		validator:     validator.New(),
+	}
+}
+
+type ScheduleRequest struct {
+	Cron      string            `json:"cron" validate:"required,max=255"`
+	Retention backups.Retention `json:"retention"`
+	Storage   *storage.Config   `json:"storage,omitempty"`
+	Enabled   *bool             `json:"enabled,omitempty"`
+}
+
+type RunBackupRequest struct {
+	// ScheduleID stores the backup on the backend of a schedule and counts it
+	// towards its retention. Without it the local backend is used.
+	ScheduleID string `json:"schedule_id,omitempty"`
+}
+
+type RestoreRequest struct {
+	Target string `json:"target" validate:"required,oneof=in_place new"`
+	// Name of the database created for the "new" target
+	Name string `json:"name,omitempty" validate:"omitempty,max=255"`
+}
+
+type RestoreResponse struct {
+	DatabaseID   string `json:"database_id"`
+	DatabaseName string `json:"database_name"`
+	Target       string `json:"target"`
+}
+
+type PITRRequest struct {
+	BaseBackupCron string          `json:"base_backup_cron" validate:"required,max=255"`
+	RetentionDays  int             `json:"retention_days" validate:"required,min=1,max=3650"`
+	Storage        *storage.Config `json:"storage,omitempty"`
+}
+
+type RecoverRequest struct {
+	TargetTime time.Time `json:"target_time" validate:"required"`
+	// Name of the recovered database. Defaults to <name>-restore-<timestamp>.
+	Name string `json:"name,omitempty" validate:"omitempty,max=255"`
+}
+
+type PITRResponse struct {
+	DatabaseID           string         `json:"database_id"`
+	BaseBackupCron       string         `json:"base_backup_cron"`
+	RetentionDays        int            `json:"retention_days"`
+	Storage              storage.Config `json:"storage"`
+	NextBaseBackupAt     *time.Time     `json:"next_base_backup_at,omitempty"`
+	LastBaseBackupAt     *time.Time     `json:"last_base_backup_at,omitempty"`
+	EarliestRecoveryTime *time.Time     `json:"earliest_recovery_time,omitempty"`
+	LatestRecoveryTime   *time.Time     `json:"latest_recovery_time,omitempty"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+}
+
+type ScheduleResponse struct {
+	ID         string            `json:"id"`
+	DatabaseID string            `json:"database_id"`
+	Cron       string            `json:"cron"`
+	Enabled    bool              `json:"enabled"`
+	Retention  backups.Retention `json:"retention"`
+	Storage    storage.Config    `json:"storage"`
+	NextRunAt  *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time        `json:"last_run_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+type RunResponse struct {
+	ID           string         `json:"id"`
+	DatabaseID   string         `json:"database_id"`
+	ScheduleID   string         `json:"schedule_id,omitempty"`
+	Trigger      string         `json:"trigger"`
+	Status       string         `json:"status"`
+	Storage      storage.Config `json:"storage"`
+	StorageKey   string         `json:"storage_key,omitempty"`
+	Format       string         `json:"format,omitempty"`
+	Compression  string         `json:"compression"`
+	SizeBytes    int64          `json:"size_bytes"`
+	Checksum     string         `json:"checksum,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+	ErrorMessage string         `json:"error_message,omitempty"`
+	StartedAt    time.Time      `json:"started_at"`
+	FinishedAt   *time.Time     `json:"finished_at,omitempty"`
+}
+
+type ListSchedulesResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+}
+
+type ListRunsResponse struct {
+	Runs []RunResponse `json:"runs"`
+}
+
+func toScheduleResponse(schedule *backups.BackupSchedule) ScheduleResponse {
+	return ScheduleResponse{
+		ID:         schedule.ID().String(),
+		DatabaseID: schedule.DatabaseID(),
+		Cron:       schedule.Cron(),
+		Enabled:    schedule.Enabled(),
+		Retention:  schedule.Retention(),
+		Storage:    schedule.Storage().Redacted(),
+		NextRunAt:  schedule.NextRunAt(),
+		LastRunAt:  schedule.LastRunAt(),
+		CreatedAt:  schedule.CreatedAt(),
+		UpdatedAt:  schedule.UpdatedAt(),
+	}
+}
+
+func toPITRResponse(status *service.PITRStatus) PITRResponse {
+	archive := status.Archive
+	return PITRResponse{
+		DatabaseID:           archive.DatabaseID(),
+		BaseBackupCron:       archive.BaseBackupCron(),
+		RetentionDays:        archive.RetentionDays(),
+		Storage:              archive.Storage().Redacted(),
+		NextBaseBackupAt:     archive.NextBaseBackupAt(),
+		LastBaseBackupAt:     archive.LastBaseBackupAt(),
+		EarliestRecoveryTime: status.EarliestRecoveryTime,
+		LatestRecoveryTime:   status.LatestRecoveryTime,
+		CreatedAt:            archive.CreatedAt(),
+		UpdatedAt:            archive.UpdatedAt(),
+	}
+}
+
+func toRunResponse(run *backups.BackupRun) RunResponse {
+	return RunResponse{
+		ID:           run.ID().String(),
+		DatabaseID:   run.DatabaseID(),
+		ScheduleID:   run.ScheduleID(),
+		Trigger:      string(run.Trigger()),
+		Status:       string(run.Status()),
+		Storage:      run.Storage().Redacted(),
+		StorageKey:   run.StorageKey(),
+		Format:       run.Format(),
+		Compression:  run.Compression(),
+		SizeBytes:    run.SizeBytes(),
+		Checksum:     run.Checksum(),
+		DurationMs:   run.Duration().Milliseconds(),
+		ErrorMessage: run.ErrorMessage(),
+		StartedAt:    run.StartedAt(),
+		FinishedAt:   run.FinishedAt(),
+	}
+}
+
+func (h *BackupHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.backupService.ListSchedules(r.Context(), db.ID().String())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list backup schedules")
+		return
+	}
+
+	response := ListSchedulesResponse{Schedules: make([]ScheduleResponse, 0, len(schedules))}
+	for _, schedule := range schedules {
+		response.Schedules = append(response.Schedules, toScheduleResponse(schedule))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *BackupHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeScheduleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.backupService.CreateSchedule(r.Context(), db.ID().String(), toServiceScheduleRequest(req))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create backup schedule: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toScheduleResponse(schedule))
+}
+
+func (h *BackupHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.backupService.GetSchedule(r.Context(), db.ID().String(), chi.URLParam(r, "schedule_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusNotFound, "schedule_not_found", "Backup schedule not found")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toScheduleResponse(schedule))
+}
+
+func (h *BackupHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeScheduleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.backupService.UpdateSchedule(r.Context(), db.ID().String(), chi.URLParam(r, "schedule_id"), toServiceScheduleRequest(req))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update backup schedule: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toScheduleResponse(schedule))
+}
+
+func (h *BackupHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.backupService.DeleteSchedule(r.Context(), db.ID().String(), chi.URLParam(r, "schedule_id")); err != nil {
+		utils.SendError(w, http.StatusNotFound, "schedule_not_found", "Backup schedule not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunBackup starts a backup immediately. The run is returned in the pending
+// state and can be polled until it succeeds or fails.
+func (h *BackupHandler) RunBackup(w http.ResponseWriter, r *http.Request) {
+	var req RunBackupRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+			return
+		}
+	}
+
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	run, err := h.backupService.StartBackup(r.Context(), db.ID().String(), req.ScheduleID, backups.BackupTriggerManual)
+	if errors.Is(err, service.ErrBackupInProgress) {
+		utils.SendError(w, http.StatusConflict, "backup_in_progress", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "backup_failed", "Failed to start backup: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, toRunResponse(run))
+}
+
+// ListRuns lists the backups of a database, newest first. ?limit caps the result.
+func (h *BackupHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			utils.SendError(w, http.StatusBadRequest, "invalid_limit", "Limit must be a positive number")
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.backupService.ListRuns(r.Context(), db.ID().String(), limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list backups")
+		return
+	}
+
+	response := ListRunsResponse{Runs: make([]RunResponse, 0, len(runs))}
+	for _, run := range runs {
+		response.Runs = append(response.Runs, toRunResponse(run))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *BackupHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	run, err := h.backupService.GetRun(r.Context(), db.ID().String(), chi.URLParam(r, "run_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusNotFound, "backup_not_found", "Backup not found")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toRunResponse(run))
+}
+
+// DeleteRun deletes a backup together with its stored file
+func (h *BackupHandler) DeleteRun(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.backupService.DeleteRun(r.Context(), db.ID().String(), chi.URLParam(r, "run_id")); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "delete_failed", "Failed to delete backup: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreRun restores a backup in place or into a new database of the same
+// project. Progress is streamed by the logs endpoint of the target database
+// with ?source=restore.
+func (h *BackupHandler) RestoreRun(w http.ResponseWriter, r *http.Request) {
+	var req RestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	target, err := h.backupService.RestoreBackup(r.Context(), db.ID().String(), chi.URLParam(r, "run_id"), databaseService.RestoreTarget(req.Target), req.Name)
+	if errors.Is(err, databaseService.ErrOperationInProgress) {
+		utils.SendError(w, http.StatusConflict, "restore_in_progress", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "restore_failed", "Failed to start restore: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, RestoreResponse{
+		DatabaseID:   target.ID().String(),
+		DatabaseName: target.Name().String(),
+		Target:       req.Target,
+	})
+}
+
+func (h *BackupHandler) GetPITR(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.backupService.GetPITR(r.Context(), db.ID().String())
+	if errors.Is(err, service.ErrPITRNotEnabled) {
+		utils.SendError(w, http.StatusNotFound, "pitr_not_enabled", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "pitr_failed", "Failed to get point-in-time recovery settings")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toPITRResponse(status))
+}
+
+// EnablePITR enables WAL archiving for point-in-time recovery or updates its
+// settings. The database is redeployed when archiving is switched on.
+func (h *BackupHandler) EnablePITR(w http.ResponseWriter, r *http.Request) {
+	var req PITRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := h.backupService.EnablePITR(r.Context(), db.ID().String(), service.PITRRequest{
+		BaseBackupCron: req.BaseBackupCron,
+		RetentionDays:  req.RetentionDays,
+		Storage:        req.Storage,
+	})
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "pitr_failed", "Failed to enable point-in-time recovery: "+err.Error())
+		return
+	}
+
+	status, err := h.backupService.GetPITR(r.Context(), db.ID().String())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "pitr_failed", "Failed to get point-in-time recovery settings")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toPITRResponse(status))
+}
+
+func (h *BackupHandler) DisablePITR(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.backupService.DisablePITR(r.Context(), db.ID().String())
+	if errors.Is(err, service.ErrPITRNotEnabled) {
+		utils.SendError(w, http.StatusNotFound, "pitr_not_enabled", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "pitr_failed", "Failed to disable point-in-time recovery: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecoverToTime provisions a new database recovered to the requested moment.
+// Progress is streamed by the logs endpoint of the new database with
+// ?source=restore.
+func (h *BackupHandler) RecoverToTime(w http.ResponseWriter, r *http.Request) {
+	var req RecoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	db, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	target, err := h.backupService.RecoverToTime(r.Context(), db.ID().String(), req.TargetTime, req.Name)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "recovery_failed", "Failed to start recovery: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, RestoreResponse{
+		DatabaseID:   target.ID().String(),
+		DatabaseName: target.Name().String(),
+		Target:       string(databaseService.RestoreTargetNew),
+	})
+}
+
+func (h *BackupHandler) decodeScheduleRequest(w http.ResponseWriter, r *http.Request) (ScheduleRequest, bool) {
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return req, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return req, false
+	}
+
+	return req, true
+}
+
+func (h *BackupHandler) getDatabase(w http.ResponseWriter, r *http.Request) (*databases.Database, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return nil, false
+	}
+
+	databaseID, err := databases.DatabaseIDFromString(chi.URLParam(r, "database_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database_id", "Invalid database ID")
+		return nil, false
+	}
+
+	db, err := h.dbService.GetDatabase(r.Context(), databaseID)
+	if err != nil || db.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "database_not_found", "Database not found")
+		return nil, false
+	}
+
+	return db, true
+}
+
+func toServiceScheduleRequest(req ScheduleRequest) service.ScheduleRequest {
+	return service.ScheduleRequest{
+		Cron:      req.Cron,
+		Retention: req.Retention,
+		Storage:   req.Storage,
+		Enabled:   req.Enabled,
+	}
+}
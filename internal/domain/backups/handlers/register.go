@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+)
+
+// RegisterBackupRoutes registers the backup routes of a database
+func RegisterBackupRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewBackupHandler(deps.BackupService, deps.DatabaseService)
+
+	r.Route("/backups", func(r chi.Router) {
+		r.Get("/", handler.ListRuns)
+		r.Post("/", handler.RunBackup)
+		r.Get("/schedules", handler.ListSchedules)
+		r.Post("/schedules", handler.CreateSchedule)
+		r.Get("/schedules/{schedule_id}", handler.GetSchedule)
+		r.Put("/schedules/{schedule_id}", handler.UpdateSchedule)
+		r.Delete("/schedules/{schedule_id}", handler.DeleteSchedule)
+		r.Get("/pitr", handler.GetPITR)
+		r.Put("/pitr", handler.EnablePITR)
+		r.Delete("/pitr", handler.DisablePITR)
+		r.Post("/pitr/recover", handler.RecoverToTime)
+		r.Get("/{run_id}", handler.GetRun)
+		r.Delete("/{run_id}", handler.DeleteRun)
+		r.Post("/{run_id}/restore", handler.RestoreRun)
+	})
+}
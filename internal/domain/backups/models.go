@@ -0,0 +1,429 @@
+package backups
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+	"github.com/robfig/cron/v3"
+)
+
+type BackupScheduleID struct {
+	value string
+}
+
+func NewBackupScheduleID() BackupScheduleID {
+	return BackupScheduleID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func BackupScheduleIDFromString(s string) (BackupScheduleID, error) {
+	if s == "" {
+		return BackupScheduleID{}, fmt.Errorf("backup schedule ID cannot be empty")
+	}
+	return BackupScheduleID{value: s}, nil
+}
+
+func (id BackupScheduleID) String() string {
+	return id.value
+}
+
+type BackupRunID struct {
+	value string
+}
+
+func NewBackupRunID() BackupRunID {
+	return BackupRunID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func BackupRunIDFromString(s string) (BackupRunID, error) {
+	if s == "" {
+		return BackupRunID{}, fmt.Errorf("backup run ID cannot be empty")
+	}
+	return BackupRunID{value: s}, nil
+}
+
+func (id BackupRunID) String() string {
+	return id.value
+}
+
+// Retention bounds how many successful backups of a schedule are kept. Zero
+// disables a limit. The newest successful backup is never pruned.
+type Retention struct {
+	KeepLast   int `json:"keep_last"`
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+func (r Retention) Validate() error {
+	if r.KeepLast < 0 || r.MaxAgeDays < 0 {
+		return fmt.Errorf("retention limits cannot be negative")
+	}
+	return nil
+}
+
+// BackupSchedule periodically backs up a database to a storage backend
+type BackupSchedule struct {
+	id         BackupScheduleID
+	databaseID string
+	cron       string
+	enabled    bool
+	retention  Retention
+	storage    storage.Config
+	nextRunAt  *time.Time
+	lastRunAt  *time.Time
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+func NewBackupSchedule(databaseID, cronExpr string, retention Retention, storageConfig storage.Config) (*BackupSchedule, error) {
+	if databaseID == "" {
+		return nil, fmt.Errorf("database ID cannot be empty")
+	}
+
+	now := time.Now()
+	schedule := &BackupSchedule{
+		id:         NewBackupScheduleID(),
+		databaseID: databaseID,
+		enabled:    true,
+		createdAt:  now,
+		updatedAt:  now,
+	}
+
+	if err := schedule.Update(cronExpr, retention, storageConfig); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *BackupSchedule) ID() BackupScheduleID {
+	return s.id
+}
+
+func (s *BackupSchedule) DatabaseID() string {
+	return s.databaseID
+}
+
+func (s *BackupSchedule) Cron() string {
+	return s.cron
+}
+
+func (s *BackupSchedule) Enabled() bool {
+	return s.enabled
+}
+
+func (s *BackupSchedule) Retention() Retention {
+	return s.retention
+}
+
+func (s *BackupSchedule) Storage() storage.Config {
+	return s.storage
+}
+
+func (s *BackupSchedule) NextRunAt() *time.Time {
+	return s.nextRunAt
+}
+
+func (s *BackupSchedule) LastRunAt() *time.Time {
+	return s.lastRunAt
+}
+
+func (s *BackupSchedule) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *BackupSchedule) UpdatedAt() time.Time {
+	return s.updatedAt
+}
+
+// Update replaces the schedule definition and recomputes the next run
+func (s *BackupSchedule) Update(cronExpr string, retention Retention, storageConfig storage.Config) error {
+	schedule, err := cron.ParseStandard(cronExpr)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	if err := retention.Validate(); err != nil {
+		return err
+	}
+	if err := storageConfig.Validate(); err != nil {
+		return err
+	}
+
+	s.cron = cronExpr
+	s.retention = retention
+	s.storage = storageConfig
+	s.updatedAt = time.Now()
+
+	next := schedule.Next(s.updatedAt)
+	s.nextRunAt = &next
+	return nil
+}
+
+func (s *BackupSchedule) SetEnabled(enabled bool) {
+	s.enabled = enabled
+	s.updatedAt = time.Now()
+}
+
+// Due reports whether the schedule should run at now
+func (s *BackupSchedule) Due(now time.Time) bool {
+	return s.enabled && s.nextRunAt != nil && !now.Before(*s.nextRunAt)
+}
+
+// MarkRun records a run started at now and advances the next run. Runs missed
+// while the server was down are not replayed.
+func (s *BackupSchedule) MarkRun(now time.Time) {
+	s.lastRunAt = &now
+	s.updatedAt = now
+
+	if schedule, err := cron.ParseStandard(s.cron); err == nil {
+		next := schedule.Next(now)
+		s.nextRunAt = &next
+	}
+}
+
+type BackupTrigger string
+
+const (
+	BackupTriggerScheduled BackupTrigger = "scheduled"
+	BackupTriggerManual    BackupTrigger = "manual"
+)
+
+type BackupRunStatus string
+
+const (
+	BackupRunStatusPending   BackupRunStatus = "pending"
+	BackupRunStatusRunning   BackupRunStatus = "running"
+	BackupRunStatusSucceeded BackupRunStatus = "succeeded"
+	BackupRunStatusFailed    BackupRunStatus = "failed"
+)
+
+// CompressionGzip is the compression applied to every stored dump
+const CompressionGzip = "gzip"
+
+// BackupRun is a single backup attempt and, once it succeeded, the artifact it
+// stored. The storage config is copied from the schedule so the artifact can
+// still be found after the schedule changes.
+type BackupRun struct {
+	id           BackupRunID
+	databaseID   string
+	scheduleID   string
+	trigger      BackupTrigger
+	status       BackupRunStatus
+	storage      storage.Config
+	storageKey   string
+	format       string
+	compression  string
+	sizeBytes    int64
+	checksum     string
+	duration     time.Duration
+	errorMessage string
+	startedAt    time.Time
+	finishedAt   *time.Time
+}
+
+func NewBackupRun(databaseID, scheduleID string, trigger BackupTrigger, storageConfig storage.Config) *BackupRun {
+	return &BackupRun{
+		id:          NewBackupRunID(),
+		databaseID:  databaseID,
+		scheduleID:  scheduleID,
+		trigger:     trigger,
+		status:      BackupRunStatusPending,
+		storage:     storageConfig,
+		compression: CompressionGzip,
+		startedAt:   time.Now(),
+	}
+}
+
+func (r *BackupRun) ID() BackupRunID {
+	return r.id
+}
+
+func (r *BackupRun) DatabaseID() string {
+	return r.databaseID
+}
+
+func (r *BackupRun) ScheduleID() string {
+	return r.scheduleID
+}
+
+func (r *BackupRun) Trigger() BackupTrigger {
+	return r.trigger
+}
+
+func (r *BackupRun) Status() BackupRunStatus {
+	return r.status
+}
+
+func (r *BackupRun) Storage() storage.Config {
+	return r.storage
+}
+
+func (r *BackupRun) StorageKey() string {
+	return r.storageKey
+}
+
+func (r *BackupRun) Format() string {
+	return r.format
+}
+
+func (r *BackupRun) Compression() string {
+	return r.compression
+}
+
+func (r *BackupRun) SizeBytes() int64 {
+	return r.sizeBytes
+}
+
+func (r *BackupRun) Checksum() string {
+	return r.checksum
+}
+
+func (r *BackupRun) Duration() time.Duration {
+	return r.duration
+}
+
+func (r *BackupRun) ErrorMessage() string {
+	return r.errorMessage
+}
+
+func (r *BackupRun) StartedAt() time.Time {
+	return r.startedAt
+}
+
+func (r *BackupRun) FinishedAt() *time.Time {
+	return r.finishedAt
+}
+
+func (r *BackupRun) Start() {
+	r.status = BackupRunStatusRunning
+	r.startedAt = time.Now()
+}
+
+// Succeed records the stored artifact
+func (r *BackupRun) Succeed(storageKey, format string, sizeBytes int64, checksum string) {
+	r.storageKey = storageKey
+	r.format = format
+	r.sizeBytes = sizeBytes
+	r.checksum = checksum
+	r.finish(BackupRunStatusSucceeded)
+}
+
+func (r *BackupRun) Fail(err error) {
+	r.errorMessage = err.Error()
+	r.finish(BackupRunStatusFailed)
+}
+
+func (r *BackupRun) finish(status BackupRunStatus) {
+	now := time.Now()
+	r.status = status
+	r.finishedAt = &now
+	r.duration = now.Sub(r.startedAt)
+}
+
+// HasArtifact reports whether the run stored a file on its backend
+func (r *BackupRun) HasArtifact() bool {
+	return r.status == BackupRunStatusSucceeded && r.storageKey != ""
+}
+
+// ExpiredRuns returns the finished runs that fall outside the retention
+// policy. The newest successful run is always kept so a schedule never prunes
+// its only restorable backup.
+func ExpiredRuns(runs []*BackupRun, retention Retention, now time.Time) []*BackupRun {
+	sorted := make([]*BackupRun, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].startedAt.After(sorted[j].startedAt)
+	})
+
+	var expired []*BackupRun
+	kept := 0
+	newestKept := false
+	for _, run := range sorted {
+		if run.status == BackupRunStatusPending || run.status == BackupRunStatusRunning {
+			continue
+		}
+
+		tooOld := retention.MaxAgeDays > 0 && now.Sub(run.startedAt) > time.Duration(retention.MaxAgeDays)*24*time.Hour
+
+		if run.status != BackupRunStatusSucceeded {
+			if tooOld {
+				expired = append(expired, run)
+			}
+			continue
+		}
+
+		tooMany := retention.KeepLast > 0 && kept >= retention.KeepLast
+		if newestKept && (tooOld || tooMany) {
+			expired = append(expired, run)
+			continue
+		}
+
+		newestKept = true
+		kept++
+	}
+
+	return expired
+}
+
+func ReconstructBackupSchedule(
+	id BackupScheduleID,
+	databaseID string,
+	cronExpr string,
+	enabled bool,
+	retention Retention,
+	storageConfig storage.Config,
+	nextRunAt *time.Time,
+	lastRunAt *time.Time,
+	createdAt time.Time,
+	updatedAt time.Time,
+) *BackupSchedule {
+	return &BackupSchedule{
+		id:         id,
+		databaseID: databaseID,
+		cron:       cronExpr,
+		enabled:    enabled,
+		retention:  retention,
+		storage:    storageConfig,
+		nextRunAt:  nextRunAt,
+		lastRunAt:  lastRunAt,
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
+	}
+}
+
+func ReconstructBackupRun(
+	id BackupRunID,
+	databaseID string,
+	scheduleID string,
+	trigger BackupTrigger,
+	status BackupRunStatus,
+	storageConfig storage.Config,
+	storageKey string,
+	format string,
+	compression string,
+	sizeBytes int64,
+	checksum string,
+	duration time.Duration,
+	errorMessage string,
+	startedAt time.Time,
+	finishedAt *time.Time,
+) *BackupRun {
+	return &BackupRun{
+		id:           id,
+		databaseID:   databaseID,
+		scheduleID:   scheduleID,
+		trigger:      trigger,
+		status:       status,
+		storage:      storageConfig,
+		storageKey:   storageKey,
+		format:       format,
+		compression:  compression,
+		sizeBytes:    sizeBytes,
+		checksum:     checksum,
+		duration:     duration,
+		errorMessage: errorMessage,
+		startedAt:    startedAt,
+		finishedAt:   finishedAt,
+	}
+}
@@ -0,0 +1,220 @@
+package backups
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+)
+
+// WALArchive enables point-in-time recovery for a PostgreSQL database. WAL
+// segments are shipped to the storage backend as PostgreSQL archives them,
+// and base backups are taken on a cron schedule to bound replay time.
+type WALArchive struct {
+	databaseID       string
+	baseBackupCron   string
+	retentionDays    int
+	storage          storage.Config
+	nextBaseBackupAt *time.Time
+	lastBaseBackupAt *time.Time
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// NewWALArchive creates the archive settings of a database. The first base
+// backup is due immediately, since recovery needs one to start from.
+func NewWALArchive(databaseID, baseBackupCron string, retentionDays int, storageConfig storage.Config) (*WALArchive, error) {
+	now := time.Now()
+	archive := &WALArchive{
+		databaseID: databaseID,
+		createdAt:  now,
+	}
+	if err := archive.Update(baseBackupCron, retentionDays, storageConfig); err != nil {
+		return nil, err
+	}
+	archive.nextBaseBackupAt = &now
+	return archive, nil
+}
+
+func ReconstructWALArchive(
+	databaseID string,
+	baseBackupCron string,
+	retentionDays int,
+	storageConfig storage.Config,
+	nextBaseBackupAt *time.Time,
+	lastBaseBackupAt *time.Time,
+	createdAt time.Time,
+	updatedAt time.Time,
+) *WALArchive {
+	return &WALArchive{
+		databaseID:       databaseID,
+		baseBackupCron:   baseBackupCron,
+		retentionDays:    retentionDays,
+		storage:          storageConfig,
+		nextBaseBackupAt: nextBaseBackupAt,
+		lastBaseBackupAt: lastBaseBackupAt,
+		createdAt:        createdAt,
+		updatedAt:        updatedAt,
+	}
+}
+
+func (a *WALArchive) DatabaseID() string {
+	return a.databaseID
+}
+
+func (a *WALArchive) BaseBackupCron() string {
+	return a.baseBackupCron
+}
+
+// RetentionDays is how far back recovery is possible
+func (a *WALArchive) RetentionDays() int {
+	return a.retentionDays
+}
+
+func (a *WALArchive) Storage() storage.Config {
+	return a.storage
+}
+
+func (a *WALArchive) NextBaseBackupAt() *time.Time {
+	return a.nextBaseBackupAt
+}
+
+func (a *WALArchive) LastBaseBackupAt() *time.Time {
+	return a.lastBaseBackupAt
+}
+
+func (a *WALArchive) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+func (a *WALArchive) UpdatedAt() time.Time {
+	return a.updatedAt
+}
+
+// Update replaces the archive settings and recomputes the next base backup
+func (a *WALArchive) Update(baseBackupCron string, retentionDays int, storageConfig storage.Config) error {
+	schedule, err := cron.ParseStandard(baseBackupCron)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	if retentionDays < 1 {
+		return fmt.Errorf("retention must be at least one day")
+	}
+	if err := storageConfig.Validate(); err != nil {
+		return err
+	}
+
+	a.baseBackupCron = baseBackupCron
+	a.retentionDays = retentionDays
+	a.storage = storageConfig
+	a.updatedAt = time.Now()
+
+	next := schedule.Next(a.updatedAt)
+	if a.nextBaseBackupAt == nil || next.Before(*a.nextBaseBackupAt) {
+		a.nextBaseBackupAt = &next
+	}
+	return nil
+}
+
+// BaseBackupDue reports whether a base backup should be taken at now
+func (a *WALArchive) BaseBackupDue(now time.Time) bool {
+	return a.nextBaseBackupAt != nil && !now.Before(*a.nextBaseBackupAt)
+}
+
+// MarkBaseBackup records a base backup started at now and advances the next one
+func (a *WALArchive) MarkBaseBackup(now time.Time) {
+	a.lastBaseBackupAt = &now
+	a.updatedAt = now
+
+	if schedule, err := cron.ParseStandard(a.baseBackupCron); err == nil {
+		next := schedule.Next(now)
+		a.nextBaseBackupAt = &next
+	}
+}
+
+// RetentionCutoff is the oldest point in time recovery must remain possible for
+func (a *WALArchive) RetentionCutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -a.retentionDays)
+}
+
+// WALSegment is a WAL file shipped to the storage backend of an archive.
+// Besides segments this includes the timeline history and backup label files
+// PostgreSQL archives.
+type WALSegment struct {
+	databaseID string
+	name       string
+	storage    storage.Config
+	storageKey string
+	sizeBytes  int64
+	checksum   string
+	archivedAt time.Time
+}
+
+func NewWALSegment(databaseID, name string, storageConfig storage.Config, storageKey string, sizeBytes int64, checksum string, archivedAt time.Time) *WALSegment {
+	return &WALSegment{
+		databaseID: databaseID,
+		name:       name,
+		storage:    storageConfig,
+		storageKey: storageKey,
+		sizeBytes:  sizeBytes,
+		checksum:   checksum,
+		archivedAt: archivedAt,
+	}
+}
+
+func ReconstructWALSegment(databaseID, name string, storageConfig storage.Config, storageKey string, sizeBytes int64, checksum string, archivedAt time.Time) *WALSegment {
+	return NewWALSegment(databaseID, name, storageConfig, storageKey, sizeBytes, checksum, archivedAt)
+}
+
+func (s *WALSegment) DatabaseID() string {
+	return s.databaseID
+}
+
+func (s *WALSegment) Name() string {
+	return s.name
+}
+
+// Storage is the backend the segment was written to, which outlives changes
+// to the archive settings
+func (s *WALSegment) Storage() storage.Config {
+	return s.storage
+}
+
+func (s *WALSegment) StorageKey() string {
+	return s.storageKey
+}
+
+func (s *WALSegment) SizeBytes() int64 {
+	return s.sizeBytes
+}
+
+func (s *WALSegment) Checksum() string {
+	return s.checksum
+}
+
+// ArchivedAt is when PostgreSQL handed the file to the archive
+func (s *WALSegment) ArchivedAt() time.Time {
+	return s.archivedAt
+}
+
+// RecoveryBase picks the base backup to recover to target from: the newest
+// successful one that finished before target. Base backups are recognised by
+// their format.
+func RecoveryBase(runs []*BackupRun, baseFormat string, target time.Time) *BackupRun {
+	var base *BackupRun
+	for _, run := range runs {
+		if run.Status() != BackupRunStatusSucceeded || run.Format() != baseFormat || !run.HasArtifact() {
+			continue
+		}
+		finished := run.FinishedAt()
+		if finished == nil || finished.After(target) {
+			continue
+		}
+		if base == nil || finished.After(*base.FinishedAt()) {
+			base = run
+		}
+	}
+	return base
+}
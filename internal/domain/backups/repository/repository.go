@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups"
+)
+
+type BackupScheduleRepository interface {
+	Create(ctx context.Context, schedule *backups.BackupSchedule) error
+	GetByID(ctx context.Context, id backups.BackupScheduleID) (*backups.BackupSchedule, error)
+	ListByDatabase(ctx context.Context, databaseID string) ([]*backups.BackupSchedule, error)
+	ListEnabled(ctx context.Context) ([]*backups.BackupSchedule, error)
+	Update(ctx context.Context, schedule *backups.BackupSchedule) error
+	Delete(ctx context.Context, id backups.BackupScheduleID) error
+}
+
+type BackupRunRepository interface {
+	Create(ctx context.Context, run *backups.BackupRun) error
+	GetByID(ctx context.Context, id backups.BackupRunID) (*backups.BackupRun, error)
+	// ListByDatabase returns the newest runs first, at most limit when limit > 0
+	ListByDatabase(ctx context.Context, databaseID string, limit int) ([]*backups.BackupRun, error)
+	ListBySchedule(ctx context.Context, scheduleID backups.BackupScheduleID) ([]*backups.BackupRun, error)
+	Update(ctx context.Context, run *backups.BackupRun) error
+	Delete(ctx context.Context, id backups.BackupRunID) error
+	// FailUnfinished marks runs left pending or running by a previous process as failed
+	FailUnfinished(ctx context.Context, message string) error
+}
+
+type WALArchiveRepository interface {
+	// Save creates or replaces the archive settings of a database
+	Save(ctx context.Context, archive *backups.WALArchive) error
+	GetByDatabase(ctx context.Context, databaseID string) (*backups.WALArchive, error)
+	List(ctx context.Context) ([]*backups.WALArchive, error)
+	Delete(ctx context.Context, databaseID string) error
+}
+
+type WALSegmentRepository interface {
+	Create(ctx context.Context, segment *backups.WALSegment) error
+	// ListSince returns the segments archived at or after since, oldest first
+	ListSince(ctx context.Context, databaseID string, since time.Time) ([]*backups.WALSegment, error)
+	// ListBefore returns the segments archived before before, oldest first
+	ListBefore(ctx context.Context, databaseID string, before time.Time) ([]*backups.WALSegment, error)
+	// Latest returns the most recently archived segment, or nil if there is none
+	Latest(ctx context.Context, databaseID string) (*backups.WALSegment, error)
+	Delete(ctx context.Context, databaseID, name string) error
+}
@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups"
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+)
+
+const backupScheduleColumns = `id, database_id, cron_expression, enabled, keep_last, max_age_days, storage_config, next_run_at, last_run_at, created_at, updated_at`
+
+const backupRunColumns = `id, database_id, schedule_id, trigger, status, storage_config, storage_key, format, compression, size_bytes, checksum, duration_ms, error_message, started_at, finished_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type SQLiteBackupScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteBackupScheduleRepository(db *sql.DB) *SQLiteBackupScheduleRepository {
+	return &SQLiteBackupScheduleRepository{db: db}
+}
+
+func (r *SQLiteBackupScheduleRepository) Create(ctx context.Context, schedule *backups.BackupSchedule) error {
+	storageConfig, err := json.Marshal(schedule.Storage())
+	if err != nil {
+		return fmt.Errorf("failed to marshal storage config: %w", err)
+	}
+
+	query := `INSERT INTO backup_schedules (` + backupScheduleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		schedule.ID().String(),
+		schedule.DatabaseID(),
+		schedule.Cron(),
+		schedule.Enabled(),
+		schedule.Retention().KeepLast,
+		schedule.Retention().MaxAgeDays,
+		string(storageConfig),
+		schedule.NextRunAt(),
+		schedule.LastRunAt(),
+		schedule.CreatedAt(),
+		schedule.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteBackupScheduleRepository) GetByID(ctx context.Context, id backups.BackupScheduleID) (*backups.BackupSchedule, error) {
+	query := `SELECT ` + backupScheduleColumns + ` FROM backup_schedules WHERE id = ?`
+	return r.scanSchedule(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteBackupScheduleRepository) ListByDatabase(ctx context.Context, databaseID string) ([]*backups.BackupSchedule, error) {
+	query := `SELECT ` + backupScheduleColumns + ` FROM backup_schedules WHERE database_id = ? ORDER BY created_at ASC`
+	return r.list(ctx, query, databaseID)
+}
+
+func (r *SQLiteBackupScheduleRepository) ListEnabled(ctx context.Context) ([]*backups.BackupSchedule, error) {
+	query := `SELECT ` + backupScheduleColumns + ` FROM backup_schedules WHERE enabled = TRUE ORDER BY next_run_at ASC`
+	return r.list(ctx, query)
+}
+
+func (r *SQLiteBackupScheduleRepository) list(ctx context.Context, query string, args ...any) ([]*backups.BackupSchedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*backups.BackupSchedule
+	for rows.Next() {
+		schedule, err := r.scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *SQLiteBackupScheduleRepository) Update(ctx context.Context, schedule *backups.BackupSchedule) error {
+	storageConfig, err := json.Marshal(schedule.Storage())
+	if err != nil {
+		return fmt.Errorf("failed to marshal storage config: %w", err)
+	}
+
+	query := `
+		UPDATE backup_schedules SET
+			cron_expression = ?, enabled = ?, keep_last = ?, max_age_days = ?, storage_config = ?,
+			next_run_at = ?, last_run_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		schedule.Cron(),
+		schedule.Enabled(),
+		schedule.Retention().KeepLast,
+		schedule.Retention().MaxAgeDays,
+		string(storageConfig),
+		schedule.NextRunAt(),
+		schedule.LastRunAt(),
+		schedule.UpdatedAt(),
+		schedule.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteBackupScheduleRepository) Delete(ctx context.Context, id backups.BackupScheduleID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM backup_schedules WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteBackupScheduleRepository) scanSchedule(row rowScanner) (*backups.BackupSchedule, error) {
+	var (
+		id, databaseID, cronExpr, storageJSON string
+		enabled                               bool
+		keepLast, maxAgeDays                  int
+		nextRunAt, lastRunAt                  sql.NullTime
+		createdAt, updatedAt                  time.Time
+	)
+
+	err := row.Scan(&id, &databaseID, &cronExpr, &enabled, &keepLast, &maxAgeDays, &storageJSON, &nextRunAt, &lastRunAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleID, err := backups.BackupScheduleIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageConfig storage.Config
+	if err := json.Unmarshal([]byte(storageJSON), &storageConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal storage config: %w", err)
+	}
+
+	return backups.ReconstructBackupSchedule(
+		scheduleID,
+		databaseID,
+		cronExpr,
+		enabled,
+		backups.Retention{KeepLast: keepLast, MaxAgeDays: maxAgeDays},
+		storageConfig,
+		nullTimeToPtr(nextRunAt),
+		nullTimeToPtr(lastRunAt),
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+type SQLiteBackupRunRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteBackupRunRepository(db *sql.DB) *SQLiteBackupRunRepository {
+	return &SQLiteBackupRunRepository{db: db}
+}
+
+func (r *SQLiteBackupRunRepository) Create(ctx context.Context, run *backups.BackupRun) error {
+	storageConfig, err := json.Marshal(run.Storage())
+	if err != nil {
+		return fmt.Errorf("failed to marshal storage config: %w", err)
+	}
+
+	query := `INSERT INTO backup_runs (` + backupRunColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		run.ID().String(),
+		run.DatabaseID(),
+		stringToNullString(run.ScheduleID()),
+		string(run.Trigger()),
+		string(run.Status()),
+		string(storageConfig),
+		run.StorageKey(),
+		run.Format(),
+		run.Compression(),
+		run.SizeBytes(),
+		run.Checksum(),
+		run.Duration().Milliseconds(),
+		run.ErrorMessage(),
+		run.StartedAt(),
+		run.FinishedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteBackupRunRepository) GetByID(ctx context.Context, id backups.BackupRunID) (*backups.BackupRun, error) {
+	query := `SELECT ` + backupRunColumns + ` FROM backup_runs WHERE id = ?`
+	return r.scanRun(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteBackupRunRepository) ListByDatabase(ctx context.Context, databaseID string, limit int) ([]*backups.BackupRun, error) {
+	query := `SELECT ` + backupRunColumns + ` FROM backup_runs WHERE database_id = ? ORDER BY started_at DESC, id DESC`
+	args := []any{databaseID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	return r.list(ctx, query, args...)
+}
+
+func (r *SQLiteBackupRunRepository) ListBySchedule(ctx context.Context, scheduleID backups.BackupScheduleID) ([]*backups.BackupRun, error) {
+	query := `SELECT ` + backupRunColumns + ` FROM backup_runs WHERE schedule_id = ? ORDER BY started_at DESC, id DESC`
+	return r.list(ctx, query, scheduleID.String())
+}
+
+func (r *SQLiteBackupRunRepository) list(ctx context.Context, query string, args ...any) ([]*backups.BackupRun, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*backups.BackupRun
+	for rows.Next() {
+		run, err := r.scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *SQLiteBackupRunRepository) Update(ctx context.Context, run *backups.BackupRun) error {
+	query := `
+		UPDATE backup_runs SET
+			status = ?, storage_key = ?, format = ?, compression = ?, size_bytes = ?, checksum = ?,
+			duration_ms = ?, error_message = ?, started_at = ?, finished_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		string(run.Status()),
+		run.StorageKey(),
+		run.Format(),
+		run.Compression(),
+		run.SizeBytes(),
+		run.Checksum(),
+		run.Duration().Milliseconds(),
+		run.ErrorMessage(),
+		run.StartedAt(),
+		run.FinishedAt(),
+		run.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteBackupRunRepository) Delete(ctx context.Context, id backups.BackupRunID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM backup_runs WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteBackupRunRepository) FailUnfinished(ctx context.Context, message string) error {
+	query := `
+		UPDATE backup_runs SET status = 'failed', error_message = ?, finished_at = ?
+		WHERE status IN ('pending', 'running')
+	`
+	_, err := r.db.ExecContext(ctx, query, message, time.Now())
+	return err
+}
+
+func (r *SQLiteBackupRunRepository) scanRun(row rowScanner) (*backups.BackupRun, error) {
+	var (
+		id, databaseID, trigger, status, storageJSON      string
+		storageKey, format, compression, checksum, errMsg string
+		scheduleID                                        sql.NullString
+		sizeBytes, durationMs                             int64
+		startedAt                                         time.Time
+		finishedAt                                        sql.NullTime
+	)
+
+	err := row.Scan(&id, &databaseID, &scheduleID, &trigger, &status, &storageJSON, &storageKey, &format,
+		&compression, &sizeBytes, &checksum, &durationMs, &errMsg, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	runID, err := backups.BackupRunIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageConfig storage.Config
+	if err := json.Unmarshal([]byte(storageJSON), &storageConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal storage config: %w", err)
+	}
+
+	return backups.ReconstructBackupRun(
+		runID,
+		databaseID,
+		scheduleID.String,
+		backups.BackupTrigger(trigger),
+		backups.BackupRunStatus(status),
+		storageConfig,
+		storageKey,
+		format,
+		compression,
+		sizeBytes,
+		checksum,
+		time.Duration(durationMs)*time.Millisecond,
+		errMsg,
+		startedAt,
+		nullTimeToPtr(finishedAt),
+	), nil
+}
+
+func nullTimeToPtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func stringToNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+const walArchiveColumns = `database_id, base_backup_cron, retention_days, storage_config, next_base_backup_at, last_base_backup_at, created_at, updated_at`
+
+const walSegmentColumns = `database_id, name, storage_config, storage_key, size_bytes, checksum, archived_at`
+
+type SQLiteWALArchiveRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWALArchiveRepository(db *sql.DB) *SQLiteWALArchiveRepository {
+	return &SQLiteWALArchiveRepository{db: db}
+}
+
+func (r *SQLiteWALArchiveRepository) Save(ctx context.Context, archive *backups.WALArchive) error {
+	storageConfig, err := json.Marshal(archive.Storage())
+	if err != nil {
+		return fmt.Errorf("failed to marshal storage config: %w", err)
+	}
+
+	query := `
+		INSERT INTO wal_archives (` + walArchiveColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(database_id) DO UPDATE SET
+			base_backup_cron = excluded.base_backup_cron,
+			retention_days = excluded.retention_days,
+			storage_config = excluded.storage_config,
+			next_base_backup_at = excluded.next_base_backup_at,
+			last_base_backup_at = excluded.last_base_backup_at,
+			updated_at = excluded.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		archive.DatabaseID(),
+		archive.BaseBackupCron(),
+		archive.RetentionDays(),
+		string(storageConfig),
+		archive.NextBaseBackupAt(),
+		archive.LastBaseBackupAt(),
+		archive.CreatedAt(),
+		archive.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteWALArchiveRepository) GetByDatabase(ctx context.Context, databaseID string) (*backups.WALArchive, error) {
+	query := `SELECT ` + walArchiveColumns + ` FROM wal_archives WHERE database_id = ?`
+	return r.scanArchive(r.db.QueryRowContext(ctx, query, databaseID))
+}
+
+func (r *SQLiteWALArchiveRepository) List(ctx context.Context) ([]*backups.WALArchive, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+walArchiveColumns+` FROM wal_archives ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var archives []*backups.WALArchive
+	for rows.Next() {
+		archive, err := r.scanArchive(rows)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+
+	return archives, rows.Err()
+}
+
+func (r *SQLiteWALArchiveRepository) Delete(ctx context.Context, databaseID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM wal_archives WHERE database_id = ?`, databaseID)
+	return err
+}
+
+func (r *SQLiteWALArchiveRepository) scanArchive(row rowScanner) (*backups.WALArchive, error) {
+	var (
+		databaseID, cronExpr, storageJSON string
+		retentionDays                     int
+		nextAt, lastAt                    sql.NullTime
+		createdAt, updatedAt              time.Time
+	)
+
+	err := row.Scan(&databaseID, &cronExpr, &retentionDays, &storageJSON, &nextAt, &lastAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageConfig storage.Config
+	if err := json.Unmarshal([]byte(storageJSON), &storageConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal storage config: %w", err)
+	}
+
+	return backups.ReconstructWALArchive(
+		databaseID,
+		cronExpr,
+		retentionDays,
+		storageConfig,
+		nullTimeToPtr(nextAt),
+		nullTimeToPtr(lastAt),
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+type SQLiteWALSegmentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWALSegmentRepository(db *sql.DB) *SQLiteWALSegmentRepository {
+	return &SQLiteWALSegmentRepository{db: db}
+}
+
+func (r *SQLiteWALSegmentRepository) Create(ctx context.Context, segment *backups.WALSegment) error {
+	storageConfig, err := json.Marshal(segment.Storage())
+	if err != nil {
+		return fmt.Errorf("failed to marshal storage config: %w", err)
+	}
+
+	// A segment re-shipped after an interrupted run replaces its record
+	query := `INSERT OR REPLACE INTO wal_segments (` + walSegmentColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		segment.DatabaseID(),
+		segment.Name(),
+		string(storageConfig),
+		segment.StorageKey(),
+		segment.SizeBytes(),
+		segment.Checksum(),
+		segment.ArchivedAt().UTC(),
+	)
+
+	return err
+}
+
+func (r *SQLiteWALSegmentRepository) ListSince(ctx context.Context, databaseID string, since time.Time) ([]*backups.WALSegment, error) {
+	query := `SELECT ` + walSegmentColumns + ` FROM wal_segments WHERE database_id = ? AND archived_at >= ? ORDER BY archived_at ASC, name ASC`
+	return r.list(ctx, query, databaseID, since.UTC())
+}
+
+func (r *SQLiteWALSegmentRepository) ListBefore(ctx context.Context, databaseID string, before time.Time) ([]*backups.WALSegment, error) {
+	query := `SELECT ` + walSegmentColumns + ` FROM wal_segments WHERE database_id = ? AND archived_at < ? ORDER BY archived_at ASC, name ASC`
+	return r.list(ctx, query, databaseID, before.UTC())
+}
+
+func (r *SQLiteWALSegmentRepository) Latest(ctx context.Context, databaseID string) (*backups.WALSegment, error) {
+	query := `SELECT ` + walSegmentColumns + ` FROM wal_segments WHERE database_id = ? ORDER BY archived_at DESC, name DESC LIMIT 1`
+	segment, err := r.scanSegment(r.db.QueryRowContext(ctx, query, databaseID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return segment, err
+}
+
+func (r *SQLiteWALSegmentRepository) Delete(ctx context.Context, databaseID, name string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM wal_segments WHERE database_id = ? AND name = ?`, databaseID, name)
+	return err
+}
+
+func (r *SQLiteWALSegmentRepository) list(ctx context.Context, query string, args ...any) ([]*backups.WALSegment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var segments []*backups.WALSegment
+	for rows.Next() {
+		segment, err := r.scanSegment(rows)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, rows.Err()
+}
+
+func (r *SQLiteWALSegmentRepository) scanSegment(row rowScanner) (*backups.WALSegment, error) {
+	var (
+		databaseID, name, storageJSON, storageKey, checksum string
+		sizeBytes                                           int64
+		archivedAt                                          time.Time
+	)
+
+	if err := row.Scan(&databaseID, &name, &storageJSON, &storageKey, &sizeBytes, &checksum, &archivedAt); err != nil {
+		return nil, err
+	}
+
+	var storageConfig storage.Config
+	if err := json.Unmarshal([]byte(storageJSON), &storageConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal storage config: %w", err)
+	}
+
+	return backups.ReconstructWALSegment(databaseID, name, storageConfig, storageKey, sizeBytes, checksum, archivedAt), nil
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	databaseService "github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	databaseContainers "github.com/mikrocloud/mikrocloud/pkg/containers/database"
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+)
+
+// ErrPITRNotEnabled is returned for databases without a WAL archive
+var ErrPITRNotEnabled = errors.New("point-in-time recovery is not enabled for this database")
+
+// walFilePattern matches what PostgreSQL archives: WAL segments, backup
+// labels and timeline history files. Partially copied files end in .tmp.
+var walFilePattern = regexp.MustCompile(`^([0-9A-F]{24}(\.[0-9A-F]{8}\.backup|\.partial)?|[0-9A-F]{8}\.history)$`)
+
+type PITRRequest struct {
+	BaseBackupCron string
+	RetentionDays  int
+	Storage        *storage.Config // defaults to the local backend
+}
+
+// PITRStatus describes the WAL archive of a database and the window it can be
+// recovered to
+type PITRStatus struct {
+	Archive *backups.WALArchive
+	// EarliestRecoveryTime is when the oldest retained base backup finished
+	EarliestRecoveryTime *time.Time
+	// LatestRecoveryTime is when the newest shipped WAL segment was archived
+	LatestRecoveryTime *time.Time
+}
+
+// EnablePITR turns on WAL archiving for a PostgreSQL database, or updates the
+// archive settings when it is already on. The database is redeployed with
+// archiving enabled and a first base backup is taken on the next scheduler tick.
+func (s *BackupService) EnablePITR(ctx context.Context, databaseID string, req PITRRequest) (*backups.WALArchive, error) {
+	database, err := s.getDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	if database.Type() != databases.DatabaseTypePostgreSQL {
+		return nil, fmt.Errorf("point-in-time recovery is only supported for PostgreSQL")
+	}
+
+	archive, err := s.archiveRepo.GetByDatabase(ctx, databaseID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		archive, err = backups.NewWALArchive(databaseID, req.BaseBackupCron, req.RetentionDays, storageOrDefault(req.Storage))
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get WAL archive: %w", err)
+	default:
+		if err := archive.Update(req.BaseBackupCron, req.RetentionDays, mergeStorage(archive.Storage(), req.Storage)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.archiveRepo.Save(ctx, archive); err != nil {
+		return nil, fmt.Errorf("failed to save WAL archive: %w", err)
+	}
+
+	if _, err := s.dbService.SetWALArchiving(ctx, database.ID(), true); err != nil {
+		return nil, fmt.Errorf("failed to enable WAL archiving: %w", err)
+	}
+
+	return archive, nil
+}
+
+// DisablePITR turns WAL archiving off. Base backups and WAL already shipped
+// are kept, so recovery into the retained window remains possible until they
+// are deleted.
+func (s *BackupService) DisablePITR(ctx context.Context, databaseID string) error {
+	database, err := s.getDatabase(ctx, databaseID)
+	if err != nil {
+		return err
+	}
+
+	archive, err := s.archiveRepo.GetByDatabase(ctx, databaseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPITRNotEnabled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get WAL archive: %w", err)
+	}
+
+	if _, err := s.dbService.SetWALArchiving(ctx, database.ID(), false); err != nil {
+		return fmt.Errorf("failed to disable WAL archiving: %w", err)
+	}
+
+	// Ship what was archived before the restart so it isn't left on disk
+	s.shipArchive(ctx, archive)
+
+	if err := s.archiveRepo.Delete(ctx, databaseID); err != nil {
+		return fmt.Errorf("failed to delete WAL archive: %w", err)
+	}
+	return nil
+}
+
+func (s *BackupService) GetPITR(ctx context.Context, databaseID string) (*PITRStatus, error) {
+	archive, err := s.archiveRepo.GetByDatabase(ctx, databaseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPITRNotEnabled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get WAL archive: %w", err)
+	}
+
+	status := &PITRStatus{Archive: archive}
+
+	runs, err := s.runRepo.ListByDatabase(ctx, databaseID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup runs: %w", err)
+	}
+	for _, run := range baseBackups(runs) {
+		status.EarliestRecoveryTime = run.FinishedAt()
+	}
+
+	latest, err := s.segmentRepo.Latest(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest WAL segment: %w", err)
+	}
+	if latest != nil {
+		archivedAt := latest.ArchivedAt()
+		status.LatestRecoveryTime = &archivedAt
+	}
+
+	return status, nil
+}
+
+// RecoverToTime provisions a new database recovered to target from the newest
+// base backup before it and the WAL archived since. Progress is read like that
+// of any other restore.
+func (s *BackupService) RecoverToTime(ctx context.Context, databaseID string, target time.Time, name string) (*databases.Database, error) {
+	database, err := s.getDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	if database.Type() != databases.DatabaseTypePostgreSQL {
+		return nil, fmt.Errorf("point-in-time recovery is only supported for PostgreSQL")
+	}
+
+	runs, err := s.runRepo.ListByDatabase(ctx, databaseID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup runs: %w", err)
+	}
+
+	base := backups.RecoveryBase(runs, databaseContainers.DumpFormatPostgresBase, target)
+	if base == nil {
+		return nil, fmt.Errorf("no base backup finished before %s", target.UTC().Format(time.RFC3339))
+	}
+
+	latest, err := s.segmentRepo.Latest(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest WAL segment: %w", err)
+	}
+	if latest == nil || latest.ArchivedAt().Before(target) {
+		return nil, fmt.Errorf("no WAL has been archived up to %s yet", target.UTC().Format(time.RFC3339))
+	}
+
+	segments, err := s.segmentRepo.ListSince(ctx, databaseID, base.StartedAt())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WAL segments: %w", err)
+	}
+	// Timeline history files are needed regardless of when they were archived
+	older, err := s.segmentRepo.ListBefore(ctx, databaseID, base.StartedAt())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WAL segments: %w", err)
+	}
+	for _, segment := range older {
+		if strings.HasSuffix(segment.Name(), ".history") {
+			segments = append(segments, segment)
+		}
+	}
+
+	return s.dbService.RestoreDatabase(ctx, databaseService.RestoreDatabaseCommand{
+		ID:     database.ID(),
+		Target: databaseService.RestoreTargetNew,
+		Name:   name,
+		Source: &pitrSource{
+			base:      runSource{run: base, backupDir: s.backupDir},
+			segments:  segments,
+			target:    target,
+			backupDir: s.backupDir,
+		},
+	})
+}
+
+// RunDueBaseBackups starts the base backups of WAL archives that are due
+func (s *BackupService) RunDueBaseBackups(ctx context.Context, now time.Time) {
+	archives, err := s.archiveRepo.List(ctx)
+	if err != nil {
+		slog.Error("Failed to list WAL archives", "error", err)
+		return
+	}
+
+	for _, archive := range archives {
+		if !archive.BaseBackupDue(now) {
+			continue
+		}
+
+		err := s.startBaseBackup(ctx, archive)
+		if errors.Is(err, ErrBackupInProgress) {
+			// Try again on the next tick rather than skipping to the next slot
+			continue
+		}
+		if err != nil {
+			slog.Error("Failed to start base backup", "database_id", archive.DatabaseID(), "error", err)
+		}
+
+		archive.MarkBaseBackup(now)
+		if err := s.archiveRepo.Save(ctx, archive); err != nil {
+			slog.Error("Failed to update WAL archive", "database_id", archive.DatabaseID(), "error", err)
+		}
+	}
+}
+
+func (s *BackupService) startBaseBackup(ctx context.Context, archive *backups.WALArchive) error {
+	database, err := s.getDatabase(ctx, archive.DatabaseID())
+	if err != nil {
+		return err
+	}
+
+	run := backups.NewBackupRun(archive.DatabaseID(), "", backups.BackupTriggerScheduled, archive.Storage())
+	return s.launch(ctx, database, run, s.dumper.BaseBackup, func(ctx context.Context) {
+		s.applyWALRetention(ctx, archive)
+	})
+}
+
+// ShipWAL uploads the WAL segments PostgreSQL archived since the last call
+func (s *BackupService) ShipWAL(ctx context.Context) {
+	archives, err := s.archiveRepo.List(ctx)
+	if err != nil {
+		slog.Error("Failed to list WAL archives", "error", err)
+		return
+	}
+
+	for _, archive := range archives {
+		s.shipArchive(ctx, archive)
+	}
+}
+
+func (s *BackupService) shipArchive(ctx context.Context, archive *backups.WALArchive) {
+	dir := databaseContainers.WALArchivePath(s.walArchiveDir, archive.DatabaseID())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("Failed to read WAL archive directory", "database_id", archive.DatabaseID(), "error", err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !walFilePattern.MatchString(entry.Name()) {
+			continue
+		}
+		if err := s.shipSegment(ctx, archive, dir, entry); err != nil {
+			// Later segments are useless without this one, so stop here
+			slog.Error("Failed to ship WAL segment", "database_id", archive.DatabaseID(), "segment", entry.Name(), "error", err)
+			return
+		}
+	}
+}
+
+func (s *BackupService) shipSegment(ctx context.Context, archive *backups.WALArchive, dir string, entry os.DirEntry) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+
+	backend, err := storage.New(archive.Storage(), s.backupDir)
+	if err != nil {
+		return fmt.Errorf("failed to open storage backend: %w", err)
+	}
+
+	stage := filepath.Join(s.stagingDir, "wal-"+archive.DatabaseID())
+	if err := os.MkdirAll(stage, 0o750); err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	path := filepath.Join(dir, entry.Name())
+	compressed := filepath.Join(stage, entry.Name()+".gz")
+	defer os.Remove(compressed)
+
+	size, checksum, err := compressFile(path, compressed)
+	if err != nil {
+		return fmt.Errorf("failed to compress segment: %w", err)
+	}
+
+	f, err := os.Open(compressed)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	key := fmt.Sprintf("%s/wal/%s.gz", archive.DatabaseID(), entry.Name())
+	if err := backend.Put(ctx, key, f, size); err != nil {
+		return fmt.Errorf("failed to store segment: %w", err)
+	}
+
+	segment := backups.NewWALSegment(archive.DatabaseID(), entry.Name(), archive.Storage(), key, size, checksum, info.ModTime())
+	if err := s.segmentRepo.Create(ctx, segment); err != nil {
+		return fmt.Errorf("failed to record segment: %w", err)
+	}
+
+	return os.Remove(path)
+}
+
+// applyWALRetention prunes base backups and WAL that are no longer needed to
+// recover to any point within the retention window. The newest base backup
+// that finished before the window starts is kept, since recovery to the start
+// of the window replays from it.
+func (s *BackupService) applyWALRetention(ctx context.Context, archive *backups.WALArchive) {
+	runs, err := s.runRepo.ListByDatabase(ctx, archive.DatabaseID(), 0)
+	if err != nil {
+		slog.Warn("Failed to list backup runs for WAL retention", "database_id", archive.DatabaseID(), "error", err)
+		return
+	}
+
+	cutoff := archive.RetentionCutoff(time.Now())
+	var oldestKept *backups.BackupRun
+	for _, run := range baseBackups(runs) {
+		if oldestKept != nil && oldestKept.FinishedAt().Before(cutoff) {
+			if err := s.deleteRun(ctx, run); err != nil {
+				slog.Warn("Failed to prune base backup", "run_id", run.ID().String(), "error", err)
+			}
+			continue
+		}
+		oldestKept = run
+	}
+	if oldestKept == nil {
+		return
+	}
+
+	segments, err := s.segmentRepo.ListBefore(ctx, archive.DatabaseID(), oldestKept.StartedAt())
+	if err != nil {
+		slog.Warn("Failed to list WAL segments for retention", "database_id", archive.DatabaseID(), "error", err)
+		return
+	}
+	for _, segment := range segments {
+		if strings.HasSuffix(segment.Name(), ".history") {
+			continue
+		}
+		if err := s.deleteSegment(ctx, segment); err != nil {
+			slog.Warn("Failed to prune WAL segment", "segment", segment.Name(), "error", err)
+		}
+	}
+}
+
+func (s *BackupService) deleteSegment(ctx context.Context, segment *backups.WALSegment) error {
+	backend, err := storage.New(segment.Storage(), s.backupDir)
+	if err != nil {
+		return fmt.Errorf("failed to open storage backend: %w", err)
+	}
+	if err := backend.Delete(ctx, segment.StorageKey()); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return s.segmentRepo.Delete(ctx, segment.DatabaseID(), segment.Name())
+}
+
+func (s *BackupService) getDatabase(ctx context.Context, databaseID string) (*databases.Database, error) {
+	id, err := databases.DatabaseIDFromString(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := s.dbService.GetDatabase(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	return database, nil
+}
+
+// baseBackups returns the successful base backups among runs, newest first
+func baseBackups(runs []*backups.BackupRun) []*backups.BackupRun {
+	var bases []*backups.BackupRun
+	for _, run := range runs {
+		if run.Status() == backups.BackupRunStatusSucceeded && run.Format() == databaseContainers.DumpFormatPostgresBase && run.FinishedAt() != nil {
+			bases = append(bases, run)
+		}
+	}
+	return bases
+}
+
+// pitrSource fetches a base backup together with the WAL to replay on top of it
+type pitrSource struct {
+	base      runSource
+	segments  []*backups.WALSegment
+	target    time.Time
+	backupDir string
+}
+
+func (p *pitrSource) Format() string {
+	return databaseContainers.DumpFormatPostgresBase
+}
+
+func (p *pitrSource) Fetch(ctx context.Context, dir string) error {
+	if err := p.base.Fetch(ctx, dir); err != nil {
+		return err
+	}
+
+	walDir := filepath.Join(dir, databaseContainers.RecoveryWALDir)
+	if err := os.MkdirAll(walDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+	for _, segment := range p.segments {
+		dst := filepath.Join(walDir, segment.Name())
+		if err := fetchArtifact(ctx, segment.Storage(), p.backupDir, segment.StorageKey(), segment.Checksum(), dst); err != nil {
+			return err
+		}
+	}
+
+	// PostgreSQL parses the timestamp itself; pin it to UTC so the server's
+	// time zone doesn't shift it
+	target := p.target.UTC().Format("2006-01-02 15:04:05.999999") + "+00"
+	return os.WriteFile(filepath.Join(dir, databaseContainers.RecoveryTargetFile), []byte(target), 0o640)
+}
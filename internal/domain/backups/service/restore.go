@@ -0,0 +1,108 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	databaseService "github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	databaseContainers "github.com/mikrocloud/mikrocloud/pkg/containers/database"
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+)
+
+// RestoreBackup restores a successful backup into the database it was taken
+// from or into a new database. See DatabaseService.RestoreDatabase.
+func (s *BackupService) RestoreBackup(ctx context.Context, databaseID, runID string, target databaseService.RestoreTarget, name string) (*databases.Database, error) {
+	run, err := s.GetRun(ctx, databaseID, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status() != backups.BackupRunStatusSucceeded || !run.HasArtifact() {
+		return nil, fmt.Errorf("only successful backups can be restored")
+	}
+
+	id, err := databases.DatabaseIDFromString(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dbService.RestoreDatabase(ctx, databaseService.RestoreDatabaseCommand{
+		ID:     id,
+		Target: target,
+		Name:   name,
+		Source: &runSource{run: run, backupDir: s.backupDir},
+	})
+}
+
+// runSource fetches the artifact of a backup run for a restore
+type runSource struct {
+	run       *backups.BackupRun
+	backupDir string
+}
+
+func (r *runSource) Format() string {
+	return r.run.Format()
+}
+
+// Fetch downloads and decompresses the artifact of the run
+func (r *runSource) Fetch(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create restore directory: %w", err)
+	}
+
+	dumpPath := filepath.Join(dir, databaseContainers.DumpFileName)
+	return fetchArtifact(ctx, r.run.Storage(), r.backupDir, r.run.StorageKey(), r.run.Checksum(), dumpPath)
+}
+
+// fetchArtifact downloads a gzipped artifact to dst, decompressing it and
+// verifying it against the checksum recorded when it was stored
+func fetchArtifact(ctx context.Context, storageConfig storage.Config, backupDir, key, expectedChecksum, dst string) error {
+	backend, err := storage.New(storageConfig, backupDir)
+	if err != nil {
+		return fmt.Errorf("failed to open storage backend: %w", err)
+	}
+
+	body, err := backend.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(dst), err)
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	compressed := io.TeeReader(body, hash)
+
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if _, err := io.Copy(out, gz); err != nil {
+		return fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	// Hash anything gzip didn't consume so the checksum covers the whole file
+	if _, err := io.Copy(io.Discard, compressed); err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(dst), err)
+	}
+
+	if checksum := hex.EncodeToString(hash.Sum(nil)); expectedChecksum != "" && checksum != expectedChecksum {
+		_ = os.Remove(dst)
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", key, expectedChecksum, checksum)
+	}
+
+	return nil
+}
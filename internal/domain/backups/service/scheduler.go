@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// BackupScheduler periodically starts the backup schedules and base backups
+// that are due and ships archived WAL
+type BackupScheduler struct {
+	backupService *BackupService
+	interval      time.Duration
+	stopCh        chan struct{}
+}
+
+func NewBackupScheduler(backupService *BackupService, interval time.Duration) *BackupScheduler {
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	return &BackupScheduler{
+		backupService: backupService,
+		interval:      interval,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler until the context is cancelled or Stop is called
+func (s *BackupScheduler) Start(ctx context.Context) {
+	slog.Info("Starting database backup scheduler", "interval", s.interval)
+
+	if err := s.backupService.RecoverInterruptedRuns(ctx); err != nil {
+		slog.Warn("Failed to recover interrupted backup runs", "error", err)
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Database backup scheduler stopped due to context cancellation")
+			return
+		case <-s.stopCh:
+			slog.Info("Database backup scheduler stopped")
+			return
+		case now := <-ticker.C:
+			s.backupService.RunDueSchedules(ctx, now)
+			s.backupService.RunDueBaseBackups(ctx, now)
+			s.backupService.ShipWAL(ctx)
+		}
+	}
+}
+
+// Stop stops the scheduler
+func (s *BackupScheduler) Stop() {
+	close(s.stopCh)
+}
@@ -0,0 +1,492 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups"
+	"github.com/mikrocloud/mikrocloud/internal/domain/backups/repository"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	databaseService "github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	databaseContainers "github.com/mikrocloud/mikrocloud/pkg/containers/database"
+	"github.com/mikrocloud/mikrocloud/pkg/storage"
+)
+
+// backupTimeout bounds a single backup run, dump and upload included
+const backupTimeout = 6 * time.Hour
+
+// ErrBackupInProgress is returned when a database is already being backed up
+var ErrBackupInProgress = errors.New("a backup of this database is already running")
+
+// DatabaseDumper writes logical dumps and physical base backups of managed databases
+type DatabaseDumper interface {
+	Dump(ctx context.Context, database *databases.Database, outputDir string) (*databaseContainers.DumpResult, error)
+	BaseBackup(ctx context.Context, database *databases.Database, outputDir string) (*databaseContainers.DumpResult, error)
+}
+
+// dumpFunc writes a backup of a database to a directory
+type dumpFunc func(ctx context.Context, database *databases.Database, outputDir string) (*databaseContainers.DumpResult, error)
+
+type BackupService struct {
+	scheduleRepo  repository.BackupScheduleRepository
+	runRepo       repository.BackupRunRepository
+	archiveRepo   repository.WALArchiveRepository
+	segmentRepo   repository.WALSegmentRepository
+	dbService     *databaseService.DatabaseService
+	dumper        DatabaseDumper
+	backupDir     string // root of the local storage backend
+	stagingDir    string // dumps are written and compressed here before upload
+	walArchiveDir string // PostgreSQL archives WAL here before it is shipped
+
+	mu      sync.Mutex
+	running map[string]bool // database IDs with a run in progress
+}
+
+func NewBackupService(
+	scheduleRepo repository.BackupScheduleRepository,
+	runRepo repository.BackupRunRepository,
+	archiveRepo repository.WALArchiveRepository,
+	segmentRepo repository.WALSegmentRepository,
+	dbService *databaseService.DatabaseService,
+	dumper DatabaseDumper,
+	backupDir string,
+	stagingDir string,
+	walArchiveDir string,
+) *BackupService {
+	return &BackupService{
+		scheduleRepo:  scheduleRepo,
+		runRepo:       runRepo,
+		archiveRepo:   archiveRepo,
+		segmentRepo:   segmentRepo,
+		dbService:     dbService,
+		dumper:        dumper,
+		backupDir:     backupDir,
+		stagingDir:    stagingDir,
+		walArchiveDir: walArchiveDir,
+		running:       make(map[string]bool),
+	}
+}
+
+type ScheduleRequest struct {
+	Cron      string
+	Retention backups.Retention
+	Storage   *storage.Config // defaults to the local backend
+	Enabled   *bool
+}
+
+func (s *BackupService) CreateSchedule(ctx context.Context, databaseID string, req ScheduleRequest) (*backups.BackupSchedule, error) {
+	schedule, err := backups.NewBackupSchedule(databaseID, req.Cron, req.Retention, storageOrDefault(req.Storage))
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		schedule.SetEnabled(*req.Enabled)
+	}
+
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to create backup schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *BackupService) GetSchedule(ctx context.Context, databaseID, scheduleID string) (*backups.BackupSchedule, error) {
+	id, err := backups.BackupScheduleIDFromString(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup schedule: %w", err)
+	}
+	if schedule.DatabaseID() != databaseID {
+		return nil, fmt.Errorf("backup schedule not found")
+	}
+
+	return schedule, nil
+}
+
+func (s *BackupService) ListSchedules(ctx context.Context, databaseID string) ([]*backups.BackupSchedule, error) {
+	schedules, err := s.scheduleRepo.ListByDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule replaces a schedule. Omitting the storage keeps the current
+// backend, so clients do not have to send credentials back.
+func (s *BackupService) UpdateSchedule(ctx context.Context, databaseID, scheduleID string, req ScheduleRequest) (*backups.BackupSchedule, error) {
+	schedule, err := s.GetSchedule(ctx, databaseID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	storageConfig := mergeStorage(schedule.Storage(), req.Storage)
+	if err := schedule.Update(req.Cron, req.Retention, storageConfig); err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		schedule.SetEnabled(*req.Enabled)
+	}
+
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to update backup schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule. Its backups are kept and can still be
+// listed and deleted individually.
+func (s *BackupService) DeleteSchedule(ctx context.Context, databaseID, scheduleID string) error {
+	schedule, err := s.GetSchedule(ctx, databaseID, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.Delete(ctx, schedule.ID()); err != nil {
+		return fmt.Errorf("failed to delete backup schedule: %w", err)
+	}
+	return nil
+}
+
+// StartBackup records a pending run and performs it in the background. When
+// scheduleID is set the run uses the schedule's backend and retention.
+func (s *BackupService) StartBackup(ctx context.Context, databaseID, scheduleID string, trigger backups.BackupTrigger) (*backups.BackupRun, error) {
+	dbID, err := databases.DatabaseIDFromString(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := s.dbService.GetDatabase(ctx, dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+
+	storageConfig := storageOrDefault(nil)
+	var schedule *backups.BackupSchedule
+	if scheduleID != "" {
+		schedule, err = s.GetSchedule(ctx, databaseID, scheduleID)
+		if err != nil {
+			return nil, err
+		}
+		storageConfig = schedule.Storage()
+	}
+
+	run := backups.NewBackupRun(databaseID, scheduleID, trigger, storageConfig)
+	err = s.launch(ctx, database, run, s.dumper.Dump, func(ctx context.Context) {
+		if schedule != nil {
+			s.applyRetention(ctx, schedule)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// launch records a pending run and executes it in the background. onSuccess
+// runs after the run succeeded, while the database is still locked.
+func (s *BackupService) launch(ctx context.Context, database *databases.Database, run *backups.BackupRun, dump dumpFunc, onSuccess func(ctx context.Context)) error {
+	databaseID := database.ID().String()
+	if !s.acquire(databaseID) {
+		return ErrBackupInProgress
+	}
+
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		s.release(databaseID)
+		return fmt.Errorf("failed to create backup run: %w", err)
+	}
+
+	go func() {
+		defer s.release(databaseID)
+
+		runCtx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+		defer cancel()
+
+		s.execute(runCtx, database, run, dump)
+		if onSuccess != nil && run.Status() == backups.BackupRunStatusSucceeded {
+			onSuccess(runCtx)
+		}
+	}()
+
+	return nil
+}
+
+// execute dumps the database, compresses and checksums the dump and uploads it
+func (s *BackupService) execute(ctx context.Context, database *databases.Database, run *backups.BackupRun, dump dumpFunc) {
+	run.Start()
+	if err := s.runRepo.Update(ctx, run); err != nil {
+		slog.Warn("Failed to update backup run", "run_id", run.ID().String(), "error", err)
+	}
+
+	err := s.upload(ctx, database, run, dump)
+	if err != nil {
+		run.Fail(err)
+		slog.Error("Database backup failed", "database_id", database.ID().String(), "run_id", run.ID().String(), "error", err)
+	} else {
+		slog.Info("Database backup completed", "database_id", database.ID().String(), "run_id", run.ID().String(),
+			"size", run.SizeBytes(), "duration", run.Duration())
+	}
+
+	if err := s.runRepo.Update(context.Background(), run); err != nil {
+		slog.Error("Failed to record backup run", "run_id", run.ID().String(), "error", err)
+	}
+}
+
+func (s *BackupService) upload(ctx context.Context, database *databases.Database, run *backups.BackupRun, dumpDatabase dumpFunc) error {
+	backend, err := storage.New(run.Storage(), s.backupDir)
+	if err != nil {
+		return fmt.Errorf("failed to open storage backend: %w", err)
+	}
+
+	stage := filepath.Join(s.stagingDir, run.ID().String())
+	defer os.RemoveAll(stage)
+
+	dump, err := dumpDatabase(ctx, database, stage)
+	if err != nil {
+		return err
+	}
+
+	archive := dump.Path + ".gz"
+	size, checksum, err := compressFile(dump.Path, archive)
+	if err != nil {
+		return fmt.Errorf("failed to compress dump: %w", err)
+	}
+	// The uncompressed dump is no longer needed and may be large
+	_ = os.Remove(dump.Path)
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	key := fmt.Sprintf("%s/%s-%s.%s.gz",
+		database.ID().String(),
+		run.StartedAt().UTC().Format("20060102T150405Z"),
+		run.ID().String(),
+		dumpExtension(dump.Format))
+
+	if err := backend.Put(ctx, key, f, size); err != nil {
+		return fmt.Errorf("failed to store backup: %w", err)
+	}
+
+	run.Succeed(key, dump.Format, size, checksum)
+	return nil
+}
+
+// applyRetention prunes the backups of a schedule that fall outside its policy
+func (s *BackupService) applyRetention(ctx context.Context, schedule *backups.BackupSchedule) {
+	runs, err := s.runRepo.ListBySchedule(ctx, schedule.ID())
+	if err != nil {
+		slog.Warn("Failed to list backup runs for retention", "schedule_id", schedule.ID().String(), "error", err)
+		return
+	}
+
+	for _, run := range backups.ExpiredRuns(runs, schedule.Retention(), time.Now()) {
+		if err := s.deleteRun(ctx, run); err != nil {
+			slog.Warn("Failed to prune backup", "run_id", run.ID().String(), "error", err)
+		}
+	}
+}
+
+func (s *BackupService) ListRuns(ctx context.Context, databaseID string, limit int) ([]*backups.BackupRun, error) {
+	runs, err := s.runRepo.ListByDatabase(ctx, databaseID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (s *BackupService) GetRun(ctx context.Context, databaseID, runID string) (*backups.BackupRun, error) {
+	id, err := backups.BackupRunIDFromString(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.runRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup run: %w", err)
+	}
+	if run.DatabaseID() != databaseID {
+		return nil, fmt.Errorf("backup run not found")
+	}
+
+	return run, nil
+}
+
+// DeleteRun removes a backup and its stored artifact
+func (s *BackupService) DeleteRun(ctx context.Context, databaseID, runID string) error {
+	run, err := s.GetRun(ctx, databaseID, runID)
+	if err != nil {
+		return err
+	}
+	if run.Status() == backups.BackupRunStatusPending || run.Status() == backups.BackupRunStatusRunning {
+		return fmt.Errorf("cannot delete a backup that is still running")
+	}
+
+	return s.deleteRun(ctx, run)
+}
+
+func (s *BackupService) deleteRun(ctx context.Context, run *backups.BackupRun) error {
+	if run.HasArtifact() {
+		backend, err := storage.New(run.Storage(), s.backupDir)
+		if err != nil {
+			return fmt.Errorf("failed to open storage backend: %w", err)
+		}
+		if err := backend.Delete(ctx, run.StorageKey()); err != nil {
+			return err
+		}
+	}
+
+	if err := s.runRepo.Delete(ctx, run.ID()); err != nil {
+		return fmt.Errorf("failed to delete backup run: %w", err)
+	}
+	return nil
+}
+
+// RecoverInterruptedRuns fails runs that were in flight when the server stopped
+func (s *BackupService) RecoverInterruptedRuns(ctx context.Context) error {
+	return s.runRepo.FailUnfinished(ctx, "backup was interrupted by a server restart")
+}
+
+// RunDueSchedules starts the enabled schedules whose next run has passed
+func (s *BackupService) RunDueSchedules(ctx context.Context, now time.Time) {
+	schedules, err := s.scheduleRepo.ListEnabled(ctx)
+	if err != nil {
+		slog.Error("Failed to list backup schedules", "error", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		if !schedule.Due(now) {
+			continue
+		}
+
+		// Advance first so a failing database is retried at its next slot
+		// instead of on every tick
+		schedule.MarkRun(now)
+		if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+			slog.Error("Failed to update backup schedule", "schedule_id", schedule.ID().String(), "error", err)
+			continue
+		}
+
+		if _, err := s.StartBackup(ctx, schedule.DatabaseID(), schedule.ID().String(), backups.BackupTriggerScheduled); err != nil {
+			slog.Error("Failed to start scheduled backup", "schedule_id", schedule.ID().String(), "error", err)
+		}
+	}
+}
+
+func (s *BackupService) acquire(databaseID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[databaseID] {
+		return false
+	}
+	s.running[databaseID] = true
+	return true
+}
+
+func (s *BackupService) release(databaseID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, databaseID)
+}
+
+func storageOrDefault(cfg *storage.Config) storage.Config {
+	if cfg == nil || cfg.Type == "" {
+		return storage.Config{Type: storage.BackendTypeLocal}
+	}
+	return *cfg
+}
+
+// mergeStorage returns the requested backend, or current when none was sent
+func mergeStorage(current storage.Config, requested *storage.Config) storage.Config {
+	if requested == nil {
+		return current
+	}
+
+	merged := *requested
+	// Keep the stored secret when the redacted config is sent back unchanged
+	if merged.S3 != nil && merged.S3.SecretKey == "" && current.S3 != nil {
+		s3 := *merged.S3
+		s3.SecretKey = current.S3.SecretKey
+		merged.S3 = &s3
+	}
+	return merged
+}
+
+// compressFile gzips src into dst and returns the size and sha256 of dst
+func compressFile(src, dst string) (int64, string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, "", err
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	gz := gzip.NewWriter(io.MultiWriter(out, hash, counter))
+
+	if _, err := io.Copy(gz, in); err != nil {
+		return 0, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := out.Close(); err != nil {
+		return 0, "", err
+	}
+
+	return counter.n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// dumpExtension names stored artifacts after the tool that produced them
+func dumpExtension(format string) string {
+	switch format {
+	case databaseContainers.DumpFormatPostgres:
+		return "dump"
+	case databaseContainers.DumpFormatMySQL:
+		return "sql"
+	case databaseContainers.DumpFormatMongoDB:
+		return "archive"
+	case databaseContainers.DumpFormatRDB:
+		return "rdb"
+	case databaseContainers.DumpFormatClickHouse:
+		return "zip"
+	case databaseContainers.DumpFormatPostgresBase:
+		return "tar"
+	default:
+		return "bin"
+	}
+}
@@ -0,0 +1,90 @@
+package databases
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ExposureMode controls how a database is reachable from outside the host
+type ExposureMode string
+
+const (
+	// ExposureModeNone keeps the database on a loopback-only host port
+	ExposureModeNone ExposureMode = "none"
+	// ExposureModeTLS routes the database through Traefik using SNI over TLS
+	ExposureModeTLS ExposureMode = "tls"
+	// ExposureModePort publishes the allocated host port on all interfaces
+	ExposureModePort ExposureMode = "port"
+)
+
+// Host ports handed out to databases come from this pool so that several
+// instances of the same engine never fight over their default port.
+const (
+	HostPortRangeStart = 20000
+	HostPortRangeEnd   = 29999
+)
+
+// reservedHostPorts are bound by the platform itself (Traefik dashboard, API)
+var reservedHostPorts = map[int]bool{
+	3000: true,
+	8080: true,
+}
+
+var exposureHostnameRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// Exposure describes the public reachability of a database
+type Exposure struct {
+	mode     ExposureMode
+	hostname string
+}
+
+func NewExposure(mode ExposureMode, hostname string) (Exposure, error) {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+
+	switch mode {
+	case ExposureModeNone, ExposureModePort:
+	case ExposureModeTLS:
+		if hostname == "" {
+			return Exposure{}, fmt.Errorf("hostname is required for TLS exposure")
+		}
+		if !exposureHostnameRegex.MatchString(hostname) {
+			return Exposure{}, fmt.Errorf("invalid hostname: %s", hostname)
+		}
+	default:
+		return Exposure{}, fmt.Errorf("unsupported exposure mode: %s", mode)
+	}
+
+	return Exposure{mode: mode, hostname: hostname}, nil
+}
+
+func (e Exposure) Mode() ExposureMode {
+	if e.mode == "" {
+		return ExposureModeNone
+	}
+	return e.mode
+}
+
+func (e Exposure) Hostname() string {
+	return e.hostname
+}
+
+// IsPublic reports whether the database can be reached from outside the host
+func (e Exposure) IsPublic() bool {
+	return e.Mode() != ExposureModeNone
+}
+
+// ValidateHostPort checks that port can be assigned to a database
+func ValidateHostPort(port int) error {
+	if port < 1024 || port > 65535 {
+		return fmt.Errorf("host port must be between 1024 and 65535")
+	}
+	if reservedHostPorts[port] {
+		return fmt.Errorf("host port %d is reserved", port)
+	}
+	return nil
+}
+
+func ReconstructExposure(mode ExposureMode, hostname string) Exposure {
+	return Exposure{mode: mode, hostname: hostname}
+}
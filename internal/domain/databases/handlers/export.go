@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database/studio"
+)
+
+type ExportQueryRequest struct {
+	Query  string `json:"query" validate:"required"`
+	Format string `json:"format" validate:"required,oneof=csv json sql"`
+	// TableName is the table the statements of a SQL export insert into
+	TableName string `json:"table_name,omitempty" validate:"omitempty,max=255"`
+}
+
+// exportResponse sends the download headers with the first bytes of an
+// export, so an export that fails before producing any output can still be
+// answered with a JSON error
+type exportResponse struct {
+	w        http.ResponseWriter
+	format   studio.ExportFormat
+	filename string
+	started  bool
+}
+
+func (e *exportResponse) Write(p []byte) (int, error) {
+	e.start()
+	return e.w.Write(p)
+}
+
+func (e *exportResponse) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.w.Header().Set("Content-Type", e.format.ContentType())
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename+"."+string(e.format)))
+	e.w.WriteHeader(http.StatusOK)
+}
+
+// ExportQuery streams the result of a read-only query as CSV, JSON or SQL
+// INSERT statements
+func (h *StudioHandler) ExportQuery(w http.ResponseWriter, r *http.Request) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	var req ExportQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	out := &exportResponse{w: w, format: studio.ExportFormat(req.Format), filename: database.Name().String() + "-query"}
+	rowWriter, err := studio.NewRowWriter(out.format, out, database.Type(), req.TableName)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "unsupported_format", err.Error())
+		return
+	}
+
+	client, err := h.clientFactory.CreateClient(r.Context(), database)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "connection_failed", "Failed to connect to database: "+err.Error())
+		return
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), exportTimeout)
+	defer cancel()
+
+	if exporter, ok := client.(studio.Exporter); ok {
+		err = exporter.ExportQuery(ctx, req.Query, exportTimeout, rowWriter)
+	} else {
+		err = exportQueryResult(ctx, client, req.Query, rowWriter)
+	}
+	finishExport(w, out, database, err)
+}
+
+// ExportTable streams all rows of a table as CSV, JSON or SQL INSERT
+// statements
+func (h *StudioHandler) ExportTable(w http.ResponseWriter, r *http.Request) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	tableName := chi.URLParam(r, "table_name")
+	schema := r.URL.Query().Get("schema")
+
+	format := studio.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = studio.ExportFormatCSV
+	}
+
+	out := &exportResponse{w: w, format: format, filename: tableName}
+	rowWriter, err := studio.NewRowWriter(format, out, database.Type(), tableName)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "unsupported_format", err.Error())
+		return
+	}
+
+	client, err := h.clientFactory.CreateClient(r.Context(), database)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "connection_failed", "Failed to connect to database: "+err.Error())
+		return
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(r.Context(), exportTimeout)
+	defer cancel()
+
+	if exporter, ok := client.(studio.Exporter); ok {
+		err = exporter.ExportTable(ctx, tableName, schema, rowWriter)
+	} else {
+		err = exportTableData(ctx, client, tableName, schema, rowWriter)
+	}
+	finishExport(w, out, database, err)
+}
+
+// exportQueryResult exports a query of a client that can't stream its
+// results. The result is read with the caps of a studio query and refused
+// rather than cut short if it exceeds them.
+func exportQueryResult(ctx context.Context, client studio.DatabaseClient, query string, w studio.RowWriter) error {
+	result, err := client.ExecuteQuery(ctx, query, studio.QueryOptions{
+		ReadOnly: true,
+		Timeout:  exportTimeout,
+		MaxBytes: maxQueryResultBytes,
+	})
+	if err != nil {
+		return err
+	}
+	if result.Truncated {
+		return fmt.Errorf("the result exceeds %d MB, which is the export limit for this database type", maxQueryResultBytes>>20)
+	}
+
+	if err := w.WriteHeader(result.Columns); err != nil {
+		return err
+	}
+	if err := studio.WriteResultRows(result, w); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// exportTableData exports a table of a client that can't stream its rows one
+// page at a time. Columns that first appear after the first page, as fields
+// of later documents may, are left out.
+func exportTableData(ctx context.Context, client studio.DatabaseClient, tableName, schema string, w studio.RowWriter) error {
+	var columns []string
+	for offset := 0; ; offset += exportPageSize {
+		page, err := client.GetTableData(ctx, tableName, schema, studio.TableDataOptions{
+			Limit:  exportPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return err
+		}
+
+		if offset == 0 {
+			columns = page.Columns
+			if err := w.WriteHeader(columns); err != nil {
+				return err
+			}
+		}
+		page.Columns = columns
+
+		if err := studio.WriteResultRows(page, w); err != nil {
+			return err
+		}
+		if len(page.Rows) < exportPageSize {
+			break
+		}
+	}
+	return w.Close()
+}
+
+// finishExport answers an export that failed before sending anything with an
+// error. Once part of it is sent the connection is aborted instead, so that
+// the client doesn't take the partial file for a complete one.
+func finishExport(w http.ResponseWriter, out *exportResponse, database *databases.Database, err error) {
+	if err == nil {
+		// An empty SQL export writes nothing at all
+		out.start()
+		return
+	}
+
+	if out.started {
+		slog.Error("Export failed after it started streaming", "database_id", database.ID().String(), "error", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	if errors.Is(err, studio.ErrWriteNotAllowed) || errors.Is(err, studio.ErrMultipleStatements) {
+		utils.SendError(w, http.StatusBadRequest, "read_only", err.Error())
+		return
+	}
+	utils.SendError(w, http.StatusInternalServerError, "export_failed", "Failed to export data: "+err.Error())
+}
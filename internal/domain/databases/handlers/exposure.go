@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type ExposureResponse struct {
+	Mode     databases.ExposureMode `json:"mode"`
+	Hostname string                 `json:"hostname,omitempty"`
+	HostPort int                    `json:"host_port,omitempty"`
+	Endpoint string                 `json:"endpoint,omitempty"`
+}
+
+type UpdateExposureRequest struct {
+	Mode     databases.ExposureMode `json:"mode" validate:"required,oneof=none tls port"`
+	Hostname string                 `json:"hostname,omitempty"`
+	HostPort int                    `json:"host_port,omitempty" validate:"omitempty,min=1024,max=65535"`
+}
+
+func toExposureResponse(db *databases.Database) ExposureResponse {
+	exposure := db.Exposure()
+	response := ExposureResponse{
+		Mode:     exposure.Mode(),
+		Hostname: exposure.Hostname(),
+		HostPort: db.HostPort(),
+	}
+
+	switch exposure.Mode() {
+	case databases.ExposureModeTLS:
+		response.Endpoint = exposure.Hostname() + ":443"
+	case databases.ExposureModePort:
+		response.Endpoint = ":" + strconv.Itoa(db.HostPort())
+	}
+
+	return response
+}
+
+// UpdateDatabaseExposure configures public access to a database through
+// Traefik (TLS with SNI) or a dedicated host port
+func (h *DatabaseHandler) UpdateDatabaseExposure(w http.ResponseWriter, r *http.Request) {
+	var req UpdateExposureRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	databaseID, err := databases.DatabaseIDFromString(chi.URLParam(r, "database_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database_id", "Invalid database ID")
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return
+	}
+
+	db, err := h.dbService.GetDatabase(r.Context(), databaseID)
+	if err != nil || db.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "database_not_found", "Database not found")
+		return
+	}
+
+	updated, err := h.dbService.UpdateExposure(r.Context(), service.UpdateExposureCommand{
+		ID:       databaseID,
+		Mode:     req.Mode,
+		Hostname: req.Hostname,
+		HostPort: req.HostPort,
+	})
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "exposure_update_failed", "Failed to update database exposure: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toExposureResponse(updated))
+}
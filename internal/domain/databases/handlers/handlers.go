@@ -44,6 +44,7 @@ type DatabaseResponse struct {
 	Status           databases.DatabaseStatus `json:"status"`
 	ConnectionString string                   `json:"connection_string"`
 	Ports            map[string]int           `json:"ports"`
+	Exposure         ExposureResponse         `json:"exposure"`
 	CreatedAt        string                   `json:"created_at"`
 	UpdatedAt        string                   `json:"updated_at"`
 }
@@ -90,6 +91,7 @@ func maskDatabaseConfig(config databases.DatabaseConfig) databases.DatabaseConfi
 	if config.PostgreSQL != nil {
 		pgCopy := *config.PostgreSQL
 		pgCopy.Password = "********"
+		pgCopy.Replication = maskReplication(pgCopy.Replication)
 		masked.PostgreSQL = &pgCopy
 	}
 
@@ -97,6 +99,7 @@ func maskDatabaseConfig(config databases.DatabaseConfig) databases.DatabaseConfi
 		mysqlCopy := *config.MySQL
 		mysqlCopy.Password = "********"
 		mysqlCopy.RootPassword = "********"
+		mysqlCopy.Replication = maskReplication(mysqlCopy.Replication)
 		masked.MySQL = &mysqlCopy
 	}
 
@@ -146,6 +149,15 @@ func maskDatabaseConfig(config databases.DatabaseConfig) databases.DatabaseConfi
 	return masked
 }
 
+func maskReplication(replication *databases.ReplicationConfig) *databases.ReplicationConfig {
+	if replication == nil {
+		return nil
+	}
+	replicationCopy := *replication
+	replicationCopy.Password = "********"
+	return &replicationCopy
+}
+
 // CreateDatabase creates a new database in a project
 func (h *DatabaseHandler) CreateDatabase(w http.ResponseWriter, r *http.Request) {
 	var req CreateDatabaseRequest
@@ -209,6 +221,7 @@ func (h *DatabaseHandler) CreateDatabase(w http.ResponseWriter, r *http.Request)
 		Status:           database.Status(),
 		ConnectionString: database.ConnectionString(),
 		Ports:            database.Ports(),
+		Exposure:         toExposureResponse(database),
 		CreatedAt:        database.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        database.UpdatedAt().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -257,6 +270,7 @@ func (h *DatabaseHandler) GetDatabase(w http.ResponseWriter, r *http.Request) {
 		Status:           database.Status(),
 		ConnectionString: database.ConnectionString(),
 		Ports:            database.Ports(),
+		Exposure:         toExposureResponse(database),
 		CreatedAt:        database.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        database.UpdatedAt().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -386,6 +400,7 @@ func (h *DatabaseHandler) UpdateDatabase(w http.ResponseWriter, r *http.Request)
 		Status:           updatedDatabase.Status(),
 		ConnectionString: updatedDatabase.ConnectionString(),
 		Ports:            updatedDatabase.Ports(),
+		Exposure:         toExposureResponse(updatedDatabase),
 		CreatedAt:        updatedDatabase.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        updatedDatabase.UpdatedAt().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -508,6 +523,7 @@ func (h *DatabaseHandler) DatabaseAction(w http.ResponseWriter, r *http.Request)
 		Status:           updatedDatabase.Status(),
 		ConnectionString: updatedDatabase.ConnectionString(),
 		Ports:            updatedDatabase.Ports(),
+		Exposure:         toExposureResponse(updatedDatabase),
 		CreatedAt:        updatedDatabase.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        updatedDatabase.UpdatedAt().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -560,6 +576,7 @@ func (h *DatabaseHandler) GetDatabaseByName(w http.ResponseWriter, r *http.Reque
 		Status:           database.Status(),
 		ConnectionString: database.ConnectionString(),
 		Ports:            database.Ports(),
+		Exposure:         toExposureResponse(database),
 		CreatedAt:        database.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        database.UpdatedAt().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -663,24 +680,44 @@ func (h *DatabaseHandler) GetDatabaseLogs(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Check if database has a container
-	if database.ContainerID() == "" {
-		utils.SendError(w, http.StatusBadRequest, "no_container", "Database has no running container")
-		return
-	}
-
 	// Get follow parameter (default to false)
 	follow := r.URL.Query().Get("follow") == "true"
 
-	// Stream logs from container
-	logStream, err := h.containerservice.StreamContainerLogs(r.Context(), database.ContainerID(), follow)
-	if err != nil {
-		utils.SendError(w, http.StatusInternalServerError, "logs_failed", "Failed to get container logs: "+err.Error())
+	var logStream io.Reader
+	switch r.URL.Query().Get("source") {
+	case "", "container":
+		// Check if database has a container
+		if database.ContainerID() == "" {
+			utils.SendError(w, http.StatusBadRequest, "no_container", "Database has no running container")
+			return
+		}
+
+		// Stream logs from container
+		containerLogs, err := h.containerservice.StreamContainerLogs(r.Context(), database.ContainerID(), follow)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "logs_failed", "Failed to get container logs: "+err.Error())
+			return
+		}
+		defer func() {
+			_ = containerLogs.Close()
+		}()
+		logStream = containerLogs
+
+	case "restore", "upgrade", "import", "replication":
+		// Progress of the latest restore into, upgrade of, import into or replica
+		// change on this database
+		operation := service.Operation(r.URL.Query().Get("source"))
+		operationLog, err := h.dbService.OperationLog(r.Context(), databaseID, operation, follow)
+		if err != nil {
+			utils.SendError(w, http.StatusNotFound, "no_"+string(operation), err.Error())
+			return
+		}
+		logStream = operationLog
+
+	default:
+		utils.SendError(w, http.StatusBadRequest, "invalid_source", "Log source must be container, restore, upgrade, import or replication")
 		return
 	}
-	defer func() {
-		_ = logStream.Close()
-	}()
 
 	// Set appropriate headers for streaming
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -691,8 +728,8 @@ func (h *DatabaseHandler) GetDatabaseLogs(w http.ResponseWriter, r *http.Request
 		w.Header().Set("Transfer-Encoding", "chunked")
 	}
 
-	// Copy logs to response
-	_, err = io.Copy(w, logStream)
+	// Copy logs to response, flushing so followers see progress as it happens
+	_, err = io.Copy(flushWriter{w}, logStream)
 	if err != nil {
 		// Log error but don't send HTTP error since we've already started writing
 		// This is common when client disconnects from a streaming endpoint
@@ -700,6 +737,20 @@ func (h *DatabaseHandler) GetDatabaseLogs(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// flushWriter flushes after every write so streamed logs aren't held back by
+// response buffering
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
@@ -843,3 +894,26 @@ func (h *DatabaseHandler) HandleTerminal(w http.ResponseWriter, r *http.Request)
 	stdoutWriter.Close()
 	stderrWriter.Close()
 }
+
+// projectDatabase checks that the database in the URL belongs to the project
+func (h *DatabaseHandler) projectDatabase(w http.ResponseWriter, r *http.Request) (databases.DatabaseID, bool) {
+	databaseID, err := databases.DatabaseIDFromString(chi.URLParam(r, "database_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database_id", "Invalid database ID")
+		return databases.DatabaseID{}, false
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return databases.DatabaseID{}, false
+	}
+
+	db, err := h.dbService.GetDatabase(r.Context(), databaseID)
+	if err != nil || db.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "database_not_found", "Database not found")
+		return databases.DatabaseID{}, false
+	}
+
+	return databaseID, true
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type PullDatabaseRequest struct {
+	SourceURL string `json:"source_url" validate:"required,url"`
+}
+
+// ImportDatabase loads an uploaded dump into a database. The dump is sent as
+// the request body or as the "file" field of a multipart form; .sql scripts,
+// pg_dump custom archives and mongodump archives are accepted, gzipped or
+// not. Loading continues after the response and is followed from the logs
+// endpoint with source=import.
+func (h *DatabaseHandler) ImportDatabase(w http.ResponseWriter, r *http.Request) {
+	databaseID, ok := h.projectDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	dump := io.Reader(r.Body)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		// Read the file part as it arrives rather than spooling the form to disk
+		reader, err := r.MultipartReader()
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_form", "Invalid multipart form")
+			return
+		}
+		dump = nil
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			if part.FormName() == "file" {
+				dump = part
+				break
+			}
+		}
+		if dump == nil {
+			utils.SendError(w, http.StatusBadRequest, "missing_file", "The form has no file field")
+			return
+		}
+	}
+
+	h.startImport(w, r, service.ImportDatabaseCommand{ID: databaseID, Dump: dump})
+}
+
+// PullDatabase copies an external database into a database, streaming the
+// dump of the source straight into it
+func (h *DatabaseHandler) PullDatabase(w http.ResponseWriter, r *http.Request) {
+	var req PullDatabaseRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	databaseID, ok := h.projectDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	h.startImport(w, r, service.ImportDatabaseCommand{ID: databaseID, SourceURL: req.SourceURL})
+}
+
+func (h *DatabaseHandler) startImport(w http.ResponseWriter, r *http.Request, cmd service.ImportDatabaseCommand) {
+	cmd.InitiatorID = middleware.GetUserID(r)
+	cmd.OrganizationID = middleware.GetOrgID(r)
+
+	err := h.dbService.ImportDatabase(r.Context(), cmd)
+	if errors.Is(err, service.ErrOperationInProgress) {
+		utils.SendError(w, http.StatusConflict, "operation_in_progress", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "import_failed", "Failed to start import: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, map[string]string{
+		"message":     "Import started",
+		"database_id": cmd.ID.String(),
+	})
+}
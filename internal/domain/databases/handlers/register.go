@@ -4,6 +4,9 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
+	backupsHandlers "github.com/mikrocloud/mikrocloud/internal/domain/backups/handlers"
+	queriesHandlers "github.com/mikrocloud/mikrocloud/internal/domain/queries/handlers"
 )
 
 func RegisterDatabasesRoutes(r chi.Router, deps *deps.Dependencies) {
@@ -20,16 +23,30 @@ func RegisterDatabasesRoutes(r chi.Router, deps *deps.Dependencies) {
 			r.Put("/", databaseHandler.UpdateDatabase)
 			r.Delete("/", databaseHandler.DeleteDatabase)
 			r.Post("/action", databaseHandler.DatabaseAction)
+			r.Put("/exposure", databaseHandler.UpdateDatabaseExposure)
+			r.Post("/upgrade", databaseHandler.UpgradeDatabase)
+			r.Get("/replicas", databaseHandler.ListReplicas)
+			r.Post("/replicas", databaseHandler.CreateReplica)
+			r.Get("/replication", databaseHandler.GetReplicationStatus)
+			r.Post("/promote", databaseHandler.PromoteReplica)
 			r.Get("/logs", databaseHandler.GetDatabaseLogs)
 			r.Get("/terminal", databaseHandler.HandleTerminal)
 
+			// Uploads are read within the request, however long they take
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.NoTimeout())
+				r.Post("/import", databaseHandler.ImportDatabase)
+				r.Post("/import/pull", databaseHandler.PullDatabase)
+			})
+
 			RegisterDatabaseStudioRoutes(r, deps)
+			backupsHandlers.RegisterBackupRoutes(r, deps)
 		})
 	})
 }
 
 func RegisterDatabaseStudioRoutes(r chi.Router, deps *deps.Dependencies) {
-	handler := NewStudioHandler(deps.DatabaseService)
+	handler := NewStudioHandler(deps.DatabaseService, deps.QueryService)
 
 	// Database studio routes
 	r.Route("/studio", func(r chi.Router) {
@@ -39,9 +56,36 @@ func RegisterDatabaseStudioRoutes(r chi.Router, deps *deps.Dependencies) {
 		r.Get("/tables/{table_name}/schema", handler.GetTableSchema)
 		r.Get("/tables/{table_name}/data", handler.GetTableData)
 		r.Post("/tables/{table_name}/data", handler.GetTableData)
-		r.Post("/query", handler.ExecuteQuery)
+		r.Delete("/queries/{query_id}", handler.CancelQuery)
+
+		// Queries and exports are bounded by their own timeout rather than the
+		// router timeout
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.NoTimeout())
+			r.Post("/query", handler.ExecuteQuery)
+			r.Get("/tables/{table_name}/export", handler.ExportTable)
+			r.Post("/export", handler.ExportQuery)
+		})
 		r.Post("/tables/{table_name}/rows", handler.InsertRow)
 		r.Put("/tables/{table_name}/rows", handler.UpdateRow)
 		r.Delete("/tables/{table_name}/rows", handler.DeleteRow)
+		r.Get("/tables/{table_name}/partitions", handler.ListPartitions)
+		r.Get("/keys/{key}", handler.GetKey)
+		r.Put("/keys/{key}/ttl", handler.SetKeyTTL)
+
+		// Schema changes, previewed rather than applied with ?preview=true
+		r.Post("/tables", handler.CreateTable)
+		r.Delete("/tables/{table_name}", handler.DropTable)
+		r.Post("/tables/{table_name}/rename", handler.RenameTable)
+		r.Post("/tables/{table_name}/columns", handler.AddColumn)
+		r.Put("/tables/{table_name}/columns/{column_name}", handler.AlterColumn)
+		r.Post("/tables/{table_name}/columns/{column_name}/rename", handler.RenameColumn)
+		r.Delete("/tables/{table_name}/columns/{column_name}", handler.DropColumn)
+		r.Post("/tables/{table_name}/indexes", handler.CreateIndex)
+		r.Delete("/tables/{table_name}/indexes/{index_name}", handler.DropIndex)
+		r.Post("/tables/{table_name}/foreign-keys", handler.AddForeignKey)
+		r.Delete("/tables/{table_name}/foreign-keys/{foreign_key_name}", handler.DropForeignKey)
+
+		queriesHandlers.RegisterQueryRoutes(r, deps)
 	})
 }
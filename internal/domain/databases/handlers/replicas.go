@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+type CreateReplicaRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=63"`
+	Description string `json:"description,omitempty"`
+}
+
+type ReplicaResponse struct {
+	ID               string                      `json:"id"`
+	Name             string                      `json:"name"`
+	Status           databases.DatabaseStatus    `json:"status"`
+	ConnectionString string                      `json:"connection_string"`
+	Replication      *database.ReplicationStatus `json:"replication,omitempty"`
+	// ReplicationError explains why the replication status of a running
+	// replica couldn't be read
+	ReplicationError string `json:"replication_error,omitempty"`
+	CreatedAt        string `json:"created_at"`
+}
+
+type ListReplicasResponse struct {
+	Replicas []ReplicaResponse `json:"replicas"`
+}
+
+// CreateReplica adds a streaming read replica to a PostgreSQL or MySQL
+// database. The replica is returned while the primary is still being copied;
+// progress is streamed from the logs endpoint of the replica with
+// source=replication.
+func (h *DatabaseHandler) CreateReplica(w http.ResponseWriter, r *http.Request) {
+	var req CreateReplicaRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	databaseID, ok := h.projectDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	replica, err := h.dbService.CreateReplica(r.Context(), service.CreateReplicaCommand{
+		PrimaryID:   databaseID,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if errors.Is(err, service.ErrOperationInProgress) {
+		utils.SendError(w, http.StatusConflict, "operation_in_progress", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "replica_failed", "Failed to create replica: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, DatabaseResponse{
+		ID:               replica.ID().String(),
+		Name:             replica.Name().String(),
+		Description:      replica.Description(),
+		Type:             replica.Type(),
+		ProjectID:        replica.ProjectID().String(),
+		EnvironmentID:    replica.EnvironmentID().String(),
+		Config:           maskDatabaseConfig(replica.Config()),
+		Status:           replica.Status(),
+		ConnectionString: replica.ConnectionString(),
+		Ports:            replica.Ports(),
+		Exposure:         toExposureResponse(replica),
+		CreatedAt:        replica.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:        replica.UpdatedAt().Format("2006-01-02T15:04:05Z07:00"),
+	})
+}
+
+// ListReplicas lists the read replicas of a database with the lag of those
+// that are running
+func (h *DatabaseHandler) ListReplicas(w http.ResponseWriter, r *http.Request) {
+	databaseID, ok := h.projectDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	replicas, err := h.dbService.ListReplicas(r.Context(), databaseID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list replicas: "+err.Error())
+		return
+	}
+
+	response := ListReplicasResponse{Replicas: make([]ReplicaResponse, 0, len(replicas))}
+	for _, replica := range replicas {
+		item := ReplicaResponse{
+			ID:               replica.ID().String(),
+			Name:             replica.Name().String(),
+			Status:           replica.Status(),
+			ConnectionString: replica.ConnectionString(),
+			CreatedAt:        replica.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
+		}
+		if replica.Status() == databases.DatabaseStatusRunning {
+			status, err := h.dbService.GetReplicationStatus(r.Context(), replica.ID())
+			if err != nil {
+				item.ReplicationError = err.Error()
+			}
+			item.Replication = status
+		}
+		response.Replicas = append(response.Replicas, item)
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+// GetReplicationStatus reports the state and lag of a replica
+func (h *DatabaseHandler) GetReplicationStatus(w http.ResponseWriter, r *http.Request) {
+	databaseID, ok := h.projectDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.dbService.GetReplicationStatus(r.Context(), databaseID)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "replication_status_failed", "Failed to get replication status: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, status)
+}
+
+// PromoteReplica turns a replica into a standalone database that accepts
+// writes. Progress is streamed from the logs endpoint with source=replication.
+func (h *DatabaseHandler) PromoteReplica(w http.ResponseWriter, r *http.Request) {
+	databaseID, ok := h.projectDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.dbService.PromoteReplica(r.Context(), databaseID)
+	if errors.Is(err, service.ErrOperationInProgress) {
+		utils.SendError(w, http.StatusConflict, "operation_in_progress", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "promotion_failed", "Failed to promote replica: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, map[string]string{
+		"message":     "Promotion started",
+		"database_id": databaseID.String(),
+	})
+}
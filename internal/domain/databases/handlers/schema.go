@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database/studio"
+)
+
+type CreateTableRequest struct {
+	Name    string                    `json:"name" validate:"required,max=64"`
+	Columns []studio.ColumnDefinition `json:"columns" validate:"required,min=1,dive"`
+}
+
+type RenameRequest struct {
+	NewName string `json:"new_name" validate:"required,max=64"`
+}
+
+type AlterColumnRequest struct {
+	Type     string  `json:"type" validate:"required,max=64"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default,omitempty"`
+}
+
+type CreateIndexRequest struct {
+	Name     string   `json:"name" validate:"required,max=64"`
+	Columns  []string `json:"columns" validate:"required,min=1,dive,required"`
+	IsUnique bool     `json:"is_unique"`
+	Type     string   `json:"type,omitempty"`
+}
+
+type AddForeignKeyRequest struct {
+	Name             string `json:"name" validate:"required,max=64"`
+	Column           string `json:"column" validate:"required"`
+	ReferencedTable  string `json:"referenced_table" validate:"required"`
+	ReferencedColumn string `json:"referenced_column" validate:"required"`
+	OnDelete         string `json:"on_delete,omitempty"`
+	OnUpdate         string `json:"on_update,omitempty"`
+}
+
+// SchemaChangeResponse lists the statements of a schema change, which were
+// only built and not run when Applied is false
+type SchemaChangeResponse struct {
+	Statements []string `json:"statements"`
+	Applied    bool     `json:"applied"`
+}
+
+func (h *StudioHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
+	var req CreateTableRequest
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:    studio.SchemaChangeCreateTable,
+		Table:   req.Name,
+		Columns: req.Columns,
+	})
+}
+
+func (h *StudioHandler) RenameTable(w http.ResponseWriter, r *http.Request) {
+	var req RenameRequest
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:    studio.SchemaChangeRenameTable,
+		Table:   chi.URLParam(r, "table_name"),
+		NewName: req.NewName,
+	})
+}
+
+// DropTable drops a table, and with ?cascade=true the objects depending on it
+func (h *StudioHandler) DropTable(w http.ResponseWriter, r *http.Request) {
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:    studio.SchemaChangeDropTable,
+		Table:   chi.URLParam(r, "table_name"),
+		Cascade: r.URL.Query().Get("cascade") == "true",
+	})
+}
+
+func (h *StudioHandler) AddColumn(w http.ResponseWriter, r *http.Request) {
+	var req studio.ColumnDefinition
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:   studio.SchemaChangeAddColumn,
+		Table:  chi.URLParam(r, "table_name"),
+		Column: &req,
+	})
+}
+
+// AlterColumn changes the type, nullability and default of a column to the
+// ones given; a column without a default in the request loses its default
+func (h *StudioHandler) AlterColumn(w http.ResponseWriter, r *http.Request) {
+	var req AlterColumnRequest
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	column := chi.URLParam(r, "column_name")
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:  studio.SchemaChangeAlterColumn,
+		Table: chi.URLParam(r, "table_name"),
+		Name:  column,
+		Column: &studio.ColumnDefinition{
+			Name:     column,
+			Type:     req.Type,
+			Nullable: req.Nullable,
+			Default:  req.Default,
+		},
+	})
+}
+
+func (h *StudioHandler) RenameColumn(w http.ResponseWriter, r *http.Request) {
+	var req RenameRequest
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:    studio.SchemaChangeRenameColumn,
+		Table:   chi.URLParam(r, "table_name"),
+		Name:    chi.URLParam(r, "column_name"),
+		NewName: req.NewName,
+	})
+}
+
+func (h *StudioHandler) DropColumn(w http.ResponseWriter, r *http.Request) {
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:  studio.SchemaChangeDropColumn,
+		Table: chi.URLParam(r, "table_name"),
+		Name:  chi.URLParam(r, "column_name"),
+	})
+}
+
+func (h *StudioHandler) CreateIndex(w http.ResponseWriter, r *http.Request) {
+	var req CreateIndexRequest
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:  studio.SchemaChangeCreateIndex,
+		Table: chi.URLParam(r, "table_name"),
+		Index: &studio.Index{
+			Name:     req.Name,
+			Columns:  req.Columns,
+			IsUnique: req.IsUnique,
+			Type:     req.Type,
+		},
+	})
+}
+
+func (h *StudioHandler) DropIndex(w http.ResponseWriter, r *http.Request) {
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:  studio.SchemaChangeDropIndex,
+		Table: chi.URLParam(r, "table_name"),
+		Name:  chi.URLParam(r, "index_name"),
+	})
+}
+
+func (h *StudioHandler) AddForeignKey(w http.ResponseWriter, r *http.Request) {
+	var req AddForeignKeyRequest
+	if !h.decodeSchemaRequest(w, r, &req) {
+		return
+	}
+
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:  studio.SchemaChangeAddForeignKey,
+		Table: chi.URLParam(r, "table_name"),
+		ForeignKey: &studio.ForeignKey{
+			Name:             req.Name,
+			Column:           req.Column,
+			ReferencedTable:  req.ReferencedTable,
+			ReferencedColumn: req.ReferencedColumn,
+			OnDelete:         req.OnDelete,
+			OnUpdate:         req.OnUpdate,
+		},
+	})
+}
+
+func (h *StudioHandler) DropForeignKey(w http.ResponseWriter, r *http.Request) {
+	h.changeSchema(w, r, studio.SchemaChange{
+		Kind:  studio.SchemaChangeDropForeignKey,
+		Table: chi.URLParam(r, "table_name"),
+		Name:  chi.URLParam(r, "foreign_key_name"),
+	})
+}
+
+func (h *StudioHandler) decodeSchemaRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return false
+	}
+	return true
+}
+
+// changeSchema builds the statements of a change and, unless ?preview=true
+// is set, applies them
+func (h *StudioHandler) changeSchema(w http.ResponseWriter, r *http.Request, change studio.SchemaChange) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	change.Schema = r.URL.Query().Get("schema")
+
+	if _, err := studio.BuildSchemaChange(database.Type(), change); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_schema_change", err.Error())
+		return
+	}
+
+	h.applySchemaChange(w, r, database, change, r.URL.Query().Get("preview") == "true")
+}
+
+// applySchemaChange runs a change, or only returns its statements when
+// previewing. Some statements depend on the current schema, so previews
+// connect too.
+func (h *StudioHandler) applySchemaChange(w http.ResponseWriter, r *http.Request, database *databases.Database, change studio.SchemaChange, preview bool) {
+	client, err := h.clientFactory.CreateClient(r.Context(), database)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "connection_failed", "Failed to connect to database: "+err.Error())
+		return
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	editor, ok := client.(studio.SchemaEditor)
+	if !ok {
+		utils.SendError(w, http.StatusBadRequest, "not_supported", "Schema changes are not supported for this database type")
+		return
+	}
+
+	if preview {
+		statements, err := editor.PlanSchemaChange(r.Context(), change)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "schema_change_failed", "Failed to build schema change: "+err.Error())
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, SchemaChangeResponse{Statements: statements})
+		return
+	}
+
+	statements, err := editor.ApplySchemaChange(r.Context(), change)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "schema_change_failed", "Failed to apply schema change: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, SchemaChangeResponse{Statements: statements, Applied: true})
+}
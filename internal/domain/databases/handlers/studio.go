@@ -1,31 +1,93 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
 	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
 	"github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries"
+	queriesService "github.com/mikrocloud/mikrocloud/internal/domain/queries/service"
 	"github.com/mikrocloud/mikrocloud/internal/utils"
 	"github.com/mikrocloud/mikrocloud/pkg/containers/database/studio"
 )
 
+const (
+	defaultQueryLimit   = 1000
+	defaultQueryTimeout = 30 * time.Second
+	// maxQueryResultBytes caps the values returned by a studio query
+	maxQueryResultBytes = 16 << 20
+	// exportTimeout bounds an export, which may read a whole table. Export
+	// routes are exempt from the router timeout.
+	exportTimeout = 30 * time.Minute
+	// exportPageSize is how many rows an export reads at a time from clients
+	// that can't stream
+	exportPageSize = 1000
+)
+
 type StudioHandler struct {
 	dbService     *service.DatabaseService
+	queryService  *queriesService.QueryService
 	clientFactory *studio.ClientFactory
 	validator     *validator.Validate
+	queries       *runningQueries
 }
 
-func NewStudioHandler(dbService *service.DatabaseService) *StudioHandler {
+func NewStudioHandler(dbService *service.DatabaseService, queryService *queriesService.QueryService) *StudioHandler {
 	return &StudioHandler{
 		dbService:     dbService,
+		queryService:  queryService,
 		clientFactory: studio.NewClientFactory(),
 		validator:     validator.New(),
+		queries:       &runningQueries{cancels: make(map[string]context.CancelFunc)},
+	}
+}
+
+// runningQueries holds the cancel functions of studio queries that were
+// given an ID, keyed by database and query ID
+type runningQueries struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func (q *runningQueries) add(key string, cancel context.CancelFunc) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.cancels[key]; exists {
+		return false
+	}
+	q.cancels[key] = cancel
+	return true
+}
+
+func (q *runningQueries) remove(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.cancels, key)
+}
+
+func (q *runningQueries) cancel(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cancel, ok := q.cancels[key]
+	if ok {
+		cancel()
+		delete(q.cancels, key)
 	}
+	return ok
 }
 
 type ListTablesResponse struct {
@@ -38,8 +100,15 @@ type ListSchemasResponse struct {
 
 type ExecuteQueryRequest struct {
 	Query  string `json:"query" validate:"required"`
-	Limit  int    `json:"limit,omitempty"`
-	Offset int    `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty" validate:"min=0,max=10000"`
+	Offset int    `json:"offset,omitempty" validate:"min=0"`
+	// Write runs the query with write access; queries are read-only otherwise
+	Write bool `json:"write,omitempty"`
+	// TimeoutSeconds bounds the query, 30 seconds by default and up to an
+	// hour. The query route is exempt from the router timeout.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" validate:"min=0,max=3600"`
+	// QueryID lets the query be cancelled while it runs
+	QueryID string `json:"query_id,omitempty" validate:"omitempty,max=64"`
 }
 
 type GetTableDataRequest struct {
@@ -63,8 +132,9 @@ type DeleteRowRequest struct {
 }
 
 type DatabaseInfoResponse struct {
-	Version string `json:"version"`
-	Size    int64  `json:"size"`
+	Version   string           `json:"version"`
+	Size      int64            `json:"size"`
+	DataModel studio.DataModel `json:"data_model"`
 }
 
 func (h *StudioHandler) validateDatabaseAccess(r *http.Request) (*databases.Database, error) {
@@ -260,15 +330,47 @@ func (h *StudioHandler) ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	if req.Limit == 0 {
-		req.Limit = 1000
+		req.Limit = defaultQueryLimit
+	}
+
+	timeout := defaultQueryTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
 	}
 
 	opts := studio.QueryOptions{
-		Limit:  req.Limit,
-		Offset: req.Offset,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+		ReadOnly: !req.Write,
+		Timeout:  timeout,
+		MaxBytes: maxQueryResultBytes,
+	}
+
+	// Closing the request cancels the query as well
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	if req.QueryID != "" {
+		key := database.ID().String() + "/" + req.QueryID
+		if !h.queries.add(key, cancel) {
+			utils.SendError(w, http.StatusConflict, "query_id_in_use", "A query with this ID is already running")
+			return
+		}
+		defer h.queries.remove(key)
 	}
 
-	result, err := client.ExecuteQuery(r.Context(), req.Query, opts)
+	start := time.Now()
+	result, err := client.ExecuteQuery(ctx, req.Query, opts)
+	h.recordQuery(r, database, req.Query, opts.ReadOnly, time.Since(start), result, err, ctx.Err())
+
+	if errors.Is(err, studio.ErrWriteNotAllowed) || errors.Is(err, studio.ErrMultipleStatements) {
+		utils.SendError(w, http.StatusBadRequest, "read_only", err.Error())
+		return
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		utils.SendError(w, http.StatusBadRequest, "query_cancelled", "Query was cancelled")
+		return
+	}
 	if err != nil {
 		utils.SendError(w, http.StatusInternalServerError, "query_failed", "Failed to execute query: "+err.Error())
 		return
@@ -277,6 +379,50 @@ func (h *StudioHandler) ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSON(w, http.StatusOK, result)
 }
 
+// recordQuery adds a query to the history of the user who ran it
+func (h *StudioHandler) recordQuery(r *http.Request, database *databases.Database, query string, readOnly bool, duration time.Duration, result *studio.QueryResult, err, ctxErr error) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		return
+	}
+
+	status := queries.QueryStatusSucceeded
+	var rowCount int64
+	var errorMessage string
+	switch {
+	case errors.Is(ctxErr, context.Canceled):
+		status = queries.QueryStatusCancelled
+	case err != nil:
+		status = queries.QueryStatusFailed
+		errorMessage = err.Error()
+	default:
+		rowCount = result.RowsAffected
+	}
+
+	// The request context may already be cancelled, which must not stop the
+	// entry from being written
+	h.queryService.RecordQuery(context.WithoutCancel(r.Context()), queries.NewQueryHistoryEntry(
+		database.ID().String(), userID, query, readOnly, status, duration, rowCount, errorMessage,
+	))
+}
+
+// CancelQuery cancels a running query by the ID it was started with
+func (h *StudioHandler) CancelQuery(w http.ResponseWriter, r *http.Request) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	key := database.ID().String() + "/" + chi.URLParam(r, "query_id")
+	if !h.queries.cancel(key) {
+		utils.SendError(w, http.StatusNotFound, "query_not_found", "No running query with this ID")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Query cancelled"})
+}
+
 func (h *StudioHandler) InsertRow(w http.ResponseWriter, r *http.Request) {
 	database, err := h.validateDatabaseAccess(r)
 	if err != nil {
@@ -411,9 +557,150 @@ func (h *StudioHandler) GetDatabaseInfo(w http.ResponseWriter, r *http.Request)
 	size, _ := client.GetDatabaseSize(r.Context())
 
 	response := DatabaseInfoResponse{
-		Version: version,
-		Size:    size,
+		Version:   version,
+		Size:      size,
+		DataModel: h.clientFactory.DataModel(database.Type()),
 	}
 
 	utils.SendJSON(w, http.StatusOK, response)
 }
+
+type ListPartitionsResponse struct {
+	Partitions []studio.Partition `json:"partitions"`
+}
+
+type SetKeyTTLRequest struct {
+	// TTL in seconds; zero removes the expiry
+	TTL int64 `json:"ttl" validate:"min=0"`
+}
+
+// ListPartitions lists the partitions of a table for databases that store
+// tables in partitions
+func (h *StudioHandler) ListPartitions(w http.ResponseWriter, r *http.Request) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	client, err := h.clientFactory.CreateClient(r.Context(), database)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "connection_failed", "Failed to connect to database: "+err.Error())
+		return
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	browser, ok := client.(studio.PartitionBrowser)
+	if !ok {
+		utils.SendError(w, http.StatusBadRequest, "not_supported", "This database doesn't partition tables")
+		return
+	}
+
+	tableName := chi.URLParam(r, "table_name")
+	schema := r.URL.Query().Get("schema")
+
+	partitions, err := browser.ListPartitions(r.Context(), tableName, schema)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list partitions: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, ListPartitionsResponse{Partitions: partitions})
+}
+
+// GetKey returns the value of a key of a key-value store
+func (h *StudioHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	key, err := url.PathUnescape(chi.URLParam(r, "key"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_key", "Invalid key")
+		return
+	}
+
+	client, err := h.clientFactory.CreateClient(r.Context(), database)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "connection_failed", "Failed to connect to database: "+err.Error())
+		return
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	browser, ok := client.(studio.KeyBrowser)
+	if !ok {
+		utils.SendError(w, http.StatusBadRequest, "not_supported", "This database doesn't store keys")
+		return
+	}
+
+	value, err := browser.GetKey(r.Context(), r.URL.Query().Get("schema"), key)
+	if errors.Is(err, studio.ErrKeyNotFound) {
+		utils.SendError(w, http.StatusNotFound, "key_not_found", "Key not found")
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "get_failed", "Failed to get key: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, value)
+}
+
+// SetKeyTTL sets or removes the expiry of a key of a key-value store
+func (h *StudioHandler) SetKeyTTL(w http.ResponseWriter, r *http.Request) {
+	database, err := h.validateDatabaseAccess(r)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database", "Invalid database or access denied")
+		return
+	}
+
+	key, err := url.PathUnescape(chi.URLParam(r, "key"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_key", "Invalid key")
+		return
+	}
+
+	var req SetKeyTTLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	client, err := h.clientFactory.CreateClient(r.Context(), database)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "connection_failed", "Failed to connect to database: "+err.Error())
+		return
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	browser, ok := client.(studio.KeyBrowser)
+	if !ok {
+		utils.SendError(w, http.StatusBadRequest, "not_supported", "This database doesn't store keys")
+		return
+	}
+
+	err = browser.SetKeyTTL(r.Context(), r.URL.Query().Get("schema"), key, time.Duration(req.TTL)*time.Second)
+	if errors.Is(err, studio.ErrKeyNotFound) {
+		utils.SendError(w, http.StatusNotFound, "key_not_found", "Key not found")
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "update_failed", "Failed to set TTL: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "TTL updated successfully"})
+}
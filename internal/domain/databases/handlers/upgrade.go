@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+type UpgradeDatabaseRequest struct {
+	Version string `json:"version" validate:"required"`
+}
+
+type UpgradeDatabaseResponse struct {
+	DatabaseID  string                 `json:"database_id"`
+	FromVersion string                 `json:"from_version"`
+	ToVersion   string                 `json:"to_version"`
+	Method      database.UpgradeMethod `json:"method"`
+	Type        databases.DatabaseType `json:"type"`
+}
+
+// UpgradeDatabase starts a version upgrade of a database. Progress is streamed
+// from the logs endpoint with source=upgrade.
+func (h *DatabaseHandler) UpgradeDatabase(w http.ResponseWriter, r *http.Request) {
+	var req UpgradeDatabaseRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	databaseID, err := databases.DatabaseIDFromString(chi.URLParam(r, "database_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database_id", "Invalid database ID")
+		return
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return
+	}
+
+	db, err := h.dbService.GetDatabase(r.Context(), databaseID)
+	if err != nil || db.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "database_not_found", "Database not found")
+		return
+	}
+
+	plan, err := h.dbService.UpgradeDatabase(r.Context(), service.UpgradeDatabaseCommand{
+		ID:      databaseID,
+		Version: req.Version,
+	})
+	if errors.Is(err, service.ErrOperationInProgress) {
+		utils.SendError(w, http.StatusConflict, "operation_in_progress", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "upgrade_failed", "Failed to start upgrade: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusAccepted, UpgradeDatabaseResponse{
+		DatabaseID:  databaseID.String(),
+		FromVersion: plan.FromVersion,
+		ToVersion:   plan.ToVersion,
+		Method:      plan.Method,
+		Type:        plan.Type,
+	})
+}
@@ -57,20 +57,40 @@ type PostgreSQLConfig struct {
 	Extensions   []string          `json:"extensions,omitempty"`
 	Environment  map[string]string `json:"environment,omitempty"`
 	Resources    *ResourceConfig   `json:"resources,omitempty"`
+	// WALArchiving ships WAL segments for point-in-time recovery. It is
+	// managed by the backups domain rather than set directly.
+	WALArchiving bool               `json:"wal_archiving,omitempty"`
+	Replication  *ReplicationConfig `json:"replication,omitempty"`
 }
 
 // MySQL configuration
 type MySQLConfig struct {
-	Version      string            `json:"version"`
-	DatabaseName string            `json:"database_name"`
-	Username     string            `json:"username"`
-	Password     string            `json:"password"`
-	RootPassword string            `json:"root_password"`
-	Port         int               `json:"port"`
-	CharacterSet string            `json:"character_set,omitempty"`
-	Collation    string            `json:"collation,omitempty"`
-	Environment  map[string]string `json:"environment,omitempty"`
-	Resources    *ResourceConfig   `json:"resources,omitempty"`
+	Version      string             `json:"version"`
+	DatabaseName string             `json:"database_name"`
+	Username     string             `json:"username"`
+	Password     string             `json:"password"`
+	RootPassword string             `json:"root_password"`
+	Port         int                `json:"port"`
+	CharacterSet string             `json:"character_set,omitempty"`
+	Collation    string             `json:"collation,omitempty"`
+	Environment  map[string]string  `json:"environment,omitempty"`
+	Resources    *ResourceConfig    `json:"resources,omitempty"`
+	Replication  *ReplicationConfig `json:"replication,omitempty"`
+}
+
+// ReplicationConfig sets up streaming replication. On a primary it holds the
+// account its replicas connect with; on a replica it also points at the
+// primary. It is managed by the replica operations rather than set directly.
+type ReplicationConfig struct {
+	// PrimaryID is the database a replica streams from, empty on a primary
+	PrimaryID string `json:"primary_id,omitempty"`
+	// PrimaryHost and PrimaryPort reach the primary on the container network
+	PrimaryHost string `json:"primary_host,omitempty"`
+	PrimaryPort int    `json:"primary_port,omitempty"`
+	User        string `json:"user"`
+	Password    string `json:"password"`
+	// ServerID identifies a MySQL server among the servers replicating together
+	ServerID int `json:"server_id,omitempty"`
 }
 
 // MariaDB configuration
@@ -176,6 +196,8 @@ type Database struct {
 	connectionString string
 	ports            map[string]int // map of service name to port
 	containerID      string         // container ID for deployed database
+	hostPort         int            // allocated host port, 0 until first deployment
+	exposure         Exposure
 	createdAt        time.Time
 	updatedAt        time.Time
 }
@@ -300,6 +322,14 @@ func (d *Database) ContainerID() string {
 	return d.containerID
 }
 
+func (d *Database) HostPort() int {
+	return d.hostPort
+}
+
+func (d *Database) Exposure() Exposure {
+	return d.exposure
+}
+
 // Setters
 func (d *Database) UpdateDescription(description string) {
 	d.description = description
@@ -334,6 +364,16 @@ func (d *Database) SetContainerID(containerID string) {
 	d.updatedAt = time.Now()
 }
 
+func (d *Database) SetHostPort(port int) {
+	d.hostPort = port
+	d.updatedAt = time.Now()
+}
+
+func (d *Database) SetExposure(exposure Exposure) {
+	d.exposure = exposure
+	d.updatedAt = time.Now()
+}
+
 // Business logic methods
 func (d *Database) CanStart() error {
 	switch d.status {
@@ -366,6 +406,25 @@ func (d *Database) CanDelete() error {
 	}
 }
 
+// Replication returns the replication settings of the database, or nil when
+// it takes no part in replication
+func (d *Database) Replication() *ReplicationConfig {
+	switch {
+	case d.config.PostgreSQL != nil:
+		return d.config.PostgreSQL.Replication
+	case d.config.MySQL != nil:
+		return d.config.MySQL.Replication
+	default:
+		return nil
+	}
+}
+
+// IsReplica reports whether the database streams from a primary
+func (d *Database) IsReplica() bool {
+	replication := d.Replication()
+	return replication != nil && replication.PrimaryID != ""
+}
+
 // Helper method to get the main service port
 func (d *Database) GetMainPort() int {
 	switch d.dbType {
@@ -414,59 +473,148 @@ func (d *Database) GetMainPort() int {
 	}
 }
 
+// Version returns the configured engine version. Empty means the image
+// resolver's default.
+func (d *Database) Version() string {
+	switch {
+	case d.config.PostgreSQL != nil:
+		return d.config.PostgreSQL.Version
+	case d.config.MySQL != nil:
+		return d.config.MySQL.Version
+	case d.config.MariaDB != nil:
+		return d.config.MariaDB.Version
+	case d.config.Redis != nil:
+		return d.config.Redis.Version
+	case d.config.KeyDB != nil:
+		return d.config.KeyDB.Version
+	case d.config.Dragonfly != nil:
+		return d.config.Dragonfly.Version
+	case d.config.MongoDB != nil:
+		return d.config.MongoDB.Version
+	case d.config.ClickHouse != nil:
+		return d.config.ClickHouse.Version
+	default:
+		return ""
+	}
+}
+
+// WithReplication returns a copy of the config with the replication settings
+// replaced. Only PostgreSQL and MySQL configs carry them.
+func (c DatabaseConfig) WithReplication(replication *ReplicationConfig) DatabaseConfig {
+	switch {
+	case c.PostgreSQL != nil:
+		cfg := *c.PostgreSQL
+		cfg.Replication = replication
+		c.PostgreSQL = &cfg
+	case c.MySQL != nil:
+		cfg := *c.MySQL
+		cfg.Replication = replication
+		c.MySQL = &cfg
+	}
+	return c
+}
+
+// WithVersion returns a copy of the config with the engine version replaced
+func (c DatabaseConfig) WithVersion(version string) DatabaseConfig {
+	switch {
+	case c.PostgreSQL != nil:
+		cfg := *c.PostgreSQL
+		cfg.Version = version
+		c.PostgreSQL = &cfg
+	case c.MySQL != nil:
+		cfg := *c.MySQL
+		cfg.Version = version
+		c.MySQL = &cfg
+	case c.MariaDB != nil:
+		cfg := *c.MariaDB
+		cfg.Version = version
+		c.MariaDB = &cfg
+	case c.Redis != nil:
+		cfg := *c.Redis
+		cfg.Version = version
+		c.Redis = &cfg
+	case c.KeyDB != nil:
+		cfg := *c.KeyDB
+		cfg.Version = version
+		c.KeyDB = &cfg
+	case c.Dragonfly != nil:
+		cfg := *c.Dragonfly
+		cfg.Version = version
+		c.Dragonfly = &cfg
+	case c.MongoDB != nil:
+		cfg := *c.MongoDB
+		cfg.Version = version
+		c.MongoDB = &cfg
+	case c.ClickHouse != nil:
+		cfg := *c.ClickHouse
+		cfg.Version = version
+		c.ClickHouse = &cfg
+	}
+	return c
+}
+
 // Helper method to generate default connection string
 func (d *Database) GenerateConnectionString() string {
 	switch d.dbType {
 	case DatabaseTypePostgreSQL:
 		if cfg := d.config.PostgreSQL; cfg != nil {
 			return fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=disable",
-				cfg.Username, cfg.Password, "localhost", cfg.Port, cfg.DatabaseName)
+				cfg.Username, cfg.Password, "localhost", d.connectPort(cfg.Port), cfg.DatabaseName)
 		}
 	case DatabaseTypeMySQL:
 		if cfg := d.config.MySQL; cfg != nil {
 			return fmt.Sprintf("%s:%s@tcp(localhost:%d)/%s",
-				cfg.Username, cfg.Password, cfg.Port, cfg.DatabaseName)
+				cfg.Username, cfg.Password, d.connectPort(cfg.Port), cfg.DatabaseName)
 		}
 	case DatabaseTypeMariaDB:
 		if cfg := d.config.MariaDB; cfg != nil {
 			return fmt.Sprintf("%s:%s@tcp(localhost:%d)/%s",
-				cfg.Username, cfg.Password, cfg.Port, cfg.DatabaseName)
+				cfg.Username, cfg.Password, d.connectPort(cfg.Port), cfg.DatabaseName)
 		}
 	case DatabaseTypeRedis:
 		if cfg := d.config.Redis; cfg != nil {
 			if cfg.Password != "" {
-				return fmt.Sprintf("redis://:%s@localhost:%d/%d", cfg.Password, cfg.Port, cfg.Database)
+				return fmt.Sprintf("redis://:%s@localhost:%d/%d", cfg.Password, d.connectPort(cfg.Port), cfg.Database)
 			}
-			return fmt.Sprintf("redis://localhost:%d/%d", cfg.Port, cfg.Database)
+			return fmt.Sprintf("redis://localhost:%d/%d", d.connectPort(cfg.Port), cfg.Database)
 		}
 	case DatabaseTypeKeyDB:
 		if cfg := d.config.KeyDB; cfg != nil {
 			if cfg.Password != "" {
-				return fmt.Sprintf("redis://:%s@localhost:%d/%d", cfg.Password, cfg.Port, cfg.Database)
+				return fmt.Sprintf("redis://:%s@localhost:%d/%d", cfg.Password, d.connectPort(cfg.Port), cfg.Database)
 			}
-			return fmt.Sprintf("redis://localhost:%d/%d", cfg.Port, cfg.Database)
+			return fmt.Sprintf("redis://localhost:%d/%d", d.connectPort(cfg.Port), cfg.Database)
 		}
 	case DatabaseTypeDragonfly:
 		if cfg := d.config.Dragonfly; cfg != nil {
 			if cfg.Password != "" {
-				return fmt.Sprintf("redis://:%s@localhost:%d", cfg.Password, cfg.Port)
+				return fmt.Sprintf("redis://:%s@localhost:%d", cfg.Password, d.connectPort(cfg.Port))
 			}
-			return fmt.Sprintf("redis://localhost:%d", cfg.Port)
+			return fmt.Sprintf("redis://localhost:%d", d.connectPort(cfg.Port))
 		}
 	case DatabaseTypeMongoDB:
 		if cfg := d.config.MongoDB; cfg != nil {
 			return fmt.Sprintf("mongodb://%s:%s@localhost:%d/%s",
-				cfg.Username, cfg.Password, cfg.Port, cfg.DatabaseName)
+				cfg.Username, cfg.Password, d.connectPort(cfg.Port), cfg.DatabaseName)
 		}
 	case DatabaseTypeClickHouse:
 		if cfg := d.config.ClickHouse; cfg != nil {
 			return fmt.Sprintf("tcp://localhost:%d?database=%s&username=%s&password=%s",
-				cfg.Port, cfg.DatabaseName, cfg.Username, cfg.Password)
+				d.connectPort(cfg.Port), cfg.DatabaseName, cfg.Username, cfg.Password)
 		}
 	}
 	return ""
 }
 
+// connectPort returns the port the control plane reaches the database on.
+// Databases created before host port allocation still listen on their own port.
+func (d *Database) connectPort(containerPort int) int {
+	if d.hostPort != 0 {
+		return d.hostPort
+	}
+	return containerPort
+}
+
 // Reconstruction helper for repository layer
 func ReconstructDatabase(
 	id DatabaseID,
@@ -479,6 +627,8 @@ func ReconstructDatabase(
 	connectionString string,
 	ports map[string]int,
 	containerID string,
+	hostPort int,
+	exposure Exposure,
 	createdAt, updatedAt time.Time,
 ) *Database {
 	if ports == nil {
@@ -496,6 +646,8 @@ func ReconstructDatabase(
 		connectionString: connectionString,
 		ports:            ports,
 		containerID:      containerID,
+		hostPort:         hostPort,
+		exposure:         exposure,
 		createdAt:        createdAt,
 		updatedAt:        updatedAt,
 	}
@@ -20,6 +20,8 @@ type DatabaseRepository interface {
 	Update(database *databases.Database) error
 	Delete(id databases.DatabaseID) error
 	ExistsByName(projectID uuid.UUID, name databases.DatabaseName) (bool, error)
+	ListHostPorts() (map[int]databases.DatabaseID, error)
+	ExistsByExposureHostname(hostname string, excludeID databases.DatabaseID) (bool, error)
 }
 
 type SQLiteDatabaseRepository struct {
@@ -44,8 +46,9 @@ func (r *SQLiteDatabaseRepository) Create(database *databases.Database) error {
 	query := `
 		INSERT INTO databases (
 			id, name, description, type, project_id, environment_id,
-			config, status, connection_string, ports, container_id, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			config, status, connection_string, ports, container_id,
+			host_port, exposure_mode, exposure_hostname, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = r.db.Exec(
@@ -61,6 +64,9 @@ func (r *SQLiteDatabaseRepository) Create(database *databases.Database) error {
 		database.ConnectionString(),
 		string(portsJSON),
 		database.ContainerID(),
+		nullableHostPort(database.HostPort()),
+		string(database.Exposure().Mode()),
+		database.Exposure().Hostname(),
 		database.CreatedAt(),
 		database.UpdatedAt(),
 	)
@@ -75,7 +81,8 @@ func (r *SQLiteDatabaseRepository) Create(database *databases.Database) error {
 func (r *SQLiteDatabaseRepository) GetByID(id databases.DatabaseID) (*databases.Database, error) {
 	query := `
 		SELECT id, name, description, type, project_id, environment_id,
-			   config, status, connection_string, ports, container_id, created_at, updated_at
+			   config, status, connection_string, ports, container_id,
+			   host_port, exposure_mode, exposure_hostname, created_at, updated_at
 		FROM databases
 		WHERE id = ?
 	`
@@ -87,7 +94,8 @@ func (r *SQLiteDatabaseRepository) GetByID(id databases.DatabaseID) (*databases.
 func (r *SQLiteDatabaseRepository) GetByName(projectID uuid.UUID, name databases.DatabaseName) (*databases.Database, error) {
 	query := `
 		SELECT id, name, description, type, project_id, environment_id,
-			   config, status, connection_string, ports, container_id, created_at, updated_at
+			   config, status, connection_string, ports, container_id,
+			   host_port, exposure_mode, exposure_hostname, created_at, updated_at
 		FROM databases
 		WHERE project_id = ? AND name = ?
 	`
@@ -99,7 +107,8 @@ func (r *SQLiteDatabaseRepository) GetByName(projectID uuid.UUID, name databases
 func (r *SQLiteDatabaseRepository) ListByProject(projectID uuid.UUID) ([]*databases.Database, error) {
 	query := `
 		SELECT id, name, description, type, project_id, environment_id,
-			   config, status, connection_string, ports, container_id, created_at, updated_at
+			   config, status, connection_string, ports, container_id,
+			   host_port, exposure_mode, exposure_hostname, created_at, updated_at
 		FROM databases
 		WHERE project_id = ?
 		ORDER BY name ASC
@@ -117,7 +126,8 @@ func (r *SQLiteDatabaseRepository) ListByProject(projectID uuid.UUID) ([]*databa
 func (r *SQLiteDatabaseRepository) ListByEnvironment(projectID, environmentID uuid.UUID) ([]*databases.Database, error) {
 	query := `
 		SELECT id, name, description, type, project_id, environment_id,
-			   config, status, connection_string, ports, container_id, created_at, updated_at
+			   config, status, connection_string, ports, container_id,
+			   host_port, exposure_mode, exposure_hostname, created_at, updated_at
 		FROM databases
 		WHERE project_id = ? AND environment_id = ?
 		ORDER BY name ASC
@@ -135,7 +145,8 @@ func (r *SQLiteDatabaseRepository) ListByEnvironment(projectID, environmentID uu
 func (r *SQLiteDatabaseRepository) ListAllWithContainers() ([]*databases.Database, error) {
 	query := `
 		SELECT id, name, description, type, project_id, environment_id,
-			   config, status, connection_string, ports, container_id, created_at, updated_at
+			   config, status, connection_string, ports, container_id,
+			   host_port, exposure_mode, exposure_hostname, created_at, updated_at
 		FROM databases
 		WHERE container_id != '' AND container_id IS NOT NULL
 		ORDER BY name ASC
@@ -164,7 +175,8 @@ func (r *SQLiteDatabaseRepository) Update(database *databases.Database) error {
 	query := `
 		UPDATE databases
 		SET name = ?, description = ?, type = ?, config = ?, status = ?,
-		    connection_string = ?, ports = ?, container_id = ?, updated_at = ?
+		    connection_string = ?, ports = ?, container_id = ?,
+		    host_port = ?, exposure_mode = ?, exposure_hostname = ?, updated_at = ?
 		WHERE id = ?
 	`
 
@@ -178,6 +190,9 @@ func (r *SQLiteDatabaseRepository) Update(database *databases.Database) error {
 		database.ConnectionString(),
 		string(portsJSON),
 		database.ContainerID(),
+		nullableHostPort(database.HostPort()),
+		string(database.Exposure().Mode()),
+		database.Exposure().Hostname(),
 		database.UpdatedAt(),
 		database.ID().String(),
 	)
@@ -234,13 +249,15 @@ func (r *SQLiteDatabaseRepository) scanDatabase(row *sql.Row) (*databases.Databa
 	var (
 		id, name, description, dbType, projectID, environmentID string
 		configJSON, status, connectionString, portsJSON         string
-		containerID                                             string
+		containerID, exposureMode, exposureHostname             string
+		hostPort                                                sql.NullInt64
 		createdAt, updatedAt                                    string
 	)
 
 	err := row.Scan(
 		&id, &name, &description, &dbType, &projectID, &environmentID,
-		&configJSON, &status, &connectionString, &portsJSON, &containerID, &createdAt, &updatedAt,
+		&configJSON, &status, &connectionString, &portsJSON, &containerID,
+		&hostPort, &exposureMode, &exposureHostname, &createdAt, &updatedAt,
 	)
 
 	if err != nil {
@@ -252,7 +269,8 @@ func (r *SQLiteDatabaseRepository) scanDatabase(row *sql.Row) (*databases.Databa
 
 	return r.buildDatabaseFromRow(
 		id, name, description, dbType, projectID, environmentID,
-		configJSON, status, connectionString, portsJSON, containerID, createdAt, updatedAt,
+		configJSON, status, connectionString, portsJSON, containerID,
+		int(hostPort.Int64), exposureMode, exposureHostname, createdAt, updatedAt,
 	)
 }
 
@@ -263,13 +281,15 @@ func (r *SQLiteDatabaseRepository) scanDatabases(rows *sql.Rows) ([]*databases.D
 		var (
 			id, name, description, dbType, projectID, environmentID string
 			configJSON, status, connectionString, portsJSON         string
-			containerID                                             string
+			containerID, exposureMode, exposureHostname             string
+			hostPort                                                sql.NullInt64
 			createdAt, updatedAt                                    string
 		)
 
 		err := rows.Scan(
 			&id, &name, &description, &dbType, &projectID, &environmentID,
-			&configJSON, &status, &connectionString, &portsJSON, &containerID, &createdAt, &updatedAt,
+			&configJSON, &status, &connectionString, &portsJSON, &containerID,
+			&hostPort, &exposureMode, &exposureHostname, &createdAt, &updatedAt,
 		)
 
 		if err != nil {
@@ -278,7 +298,8 @@ func (r *SQLiteDatabaseRepository) scanDatabases(rows *sql.Rows) ([]*databases.D
 
 		database, err := r.buildDatabaseFromRow(
 			id, name, description, dbType, projectID, environmentID,
-			configJSON, status, connectionString, portsJSON, containerID, createdAt, updatedAt,
+			configJSON, status, connectionString, portsJSON, containerID,
+			int(hostPort.Int64), exposureMode, exposureHostname, createdAt, updatedAt,
 		)
 
 		if err != nil {
@@ -297,7 +318,8 @@ func (r *SQLiteDatabaseRepository) scanDatabases(rows *sql.Rows) ([]*databases.D
 
 func (r *SQLiteDatabaseRepository) buildDatabaseFromRow(
 	idStr, nameStr, description, dbTypeStr, projectIDStr, environmentIDStr,
-	configJSON, statusStr, connectionString, portsJSON, containerID, createdAtStr, updatedAtStr string,
+	configJSON, statusStr, connectionString, portsJSON, containerID string,
+	hostPort int, exposureMode, exposureHostname, createdAtStr, updatedAtStr string,
 ) (*databases.Database, error) {
 	// Parse IDs
 	databaseID, err := databases.DatabaseIDFromString(idStr)
@@ -356,11 +378,61 @@ func (r *SQLiteDatabaseRepository) buildDatabaseFromRow(
 		connectionString,
 		ports,
 		containerID,
+		hostPort,
+		databases.ReconstructExposure(databases.ExposureMode(exposureMode), exposureHostname),
 		createdAt,
 		updatedAt,
 	), nil
 }
 
+// ListHostPorts returns the host ports allocated to databases keyed by port
+func (r *SQLiteDatabaseRepository) ListHostPorts() (map[int]databases.DatabaseID, error) {
+	query := `SELECT id, host_port FROM databases WHERE host_port IS NOT NULL`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list host ports: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]databases.DatabaseID)
+	for rows.Next() {
+		var id string
+		var port int
+		if err := rows.Scan(&id, &port); err != nil {
+			return nil, fmt.Errorf("failed to scan host port: %w", err)
+		}
+		databaseID, err := databases.DatabaseIDFromString(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid database ID: %w", err)
+		}
+		result[port] = databaseID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating host port rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// ExistsByExposureHostname reports whether another database is already routed on hostname
+func (r *SQLiteDatabaseRepository) ExistsByExposureHostname(hostname string, excludeID databases.DatabaseID) (bool, error) {
+	query := `SELECT COUNT(*) FROM databases WHERE exposure_hostname = ? AND id != ?`
+
+	var count int
+	err := r.db.QueryRow(query, hostname, excludeID.String()).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check exposure hostname: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func nullableHostPort(port int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(port), Valid: port != 0}
+}
+
 // parseTime parses time string in RFC3339 format
 func parseTime(timeStr string) (time.Time, error) {
 	return time.Parse(time.RFC3339, timeStr)
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// SetWALArchiving turns continuous WAL archiving of a PostgreSQL database on or
+// off. Running databases are redeployed so PostgreSQL picks up the setting.
+func (s *DatabaseService) SetWALArchiving(ctx context.Context, id databases.DatabaseID, enabled bool) (*databases.Database, error) {
+	database, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	config := database.Config()
+	if database.Type() != databases.DatabaseTypePostgreSQL || config.PostgreSQL == nil {
+		return nil, fmt.Errorf("WAL archiving is only supported for PostgreSQL")
+	}
+	if config.PostgreSQL.WALArchiving == enabled {
+		return database, nil
+	}
+
+	pg := *config.PostgreSQL
+	pg.WALArchiving = enabled
+	config.PostgreSQL = &pg
+	database.UpdateConfig(config)
+
+	if err := s.repo.Update(database); err != nil {
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+
+	if database.Status() == databases.DatabaseStatusRunning {
+		if err := s.redeploy(ctx, database); err != nil {
+			return nil, err
+		}
+	}
+
+	return database, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+// DependentApplications stops and starts the applications that use a database
+type DependentApplications interface {
+	// StopDependents stops the running applications that use the database and
+	// returns their container IDs
+	StopDependents(ctx context.Context, database *databases.Database) ([]string, error)
+
+	// StartDependents starts containers stopped by StopDependents
+	StartDependents(ctx context.Context, containerIDs []string) error
+}
+
+type ApplicationLister interface {
+	ListApplicationsByEnvironment(ctx context.Context, environmentID uuid.UUID) ([]*applications.Application, error)
+}
+
+type DeploymentLookup interface {
+	GetLatestDeploymentByApplication(ctx context.Context, applicationID applications.ApplicationID) (*deployments.Deployment, error)
+}
+
+type ContainerController interface {
+	StartContainer(ctx context.Context, containerID string) error
+	StopContainer(ctx context.Context, containerID string) error
+}
+
+// ApplicationDependents finds the applications that use a database by looking
+// for its hostname or connection string in their environment variables
+type ApplicationDependents struct {
+	apps        ApplicationLister
+	deployments DeploymentLookup
+	containers  ContainerController
+}
+
+func NewApplicationDependents(apps ApplicationLister, deployments DeploymentLookup, containers ContainerController) *ApplicationDependents {
+	return &ApplicationDependents{
+		apps:        apps,
+		deployments: deployments,
+		containers:  containers,
+	}
+}
+
+func (d *ApplicationDependents) StopDependents(ctx context.Context, db *databases.Database) ([]string, error) {
+	apps, err := d.apps.ListApplicationsByEnvironment(ctx, db.EnvironmentID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	var stopped []string
+	for _, app := range apps {
+		if !usesDatabase(app, db) {
+			continue
+		}
+
+		deployment, err := d.deployments.GetLatestDeploymentByApplication(ctx, app.ID())
+		if err != nil || deployment == nil {
+			continue
+		}
+		if deployment.Status() != deployments.DeploymentStatusRunning || deployment.ContainerID() == "" {
+			continue
+		}
+
+		if err := d.containers.StopContainer(ctx, deployment.ContainerID()); err != nil {
+			// Bring back what was already stopped before giving up
+			_ = d.StartDependents(context.Background(), stopped)
+			return nil, fmt.Errorf("failed to stop application %s: %w", app.Name(), err)
+		}
+		stopped = append(stopped, deployment.ContainerID())
+	}
+
+	return stopped, nil
+}
+
+func (d *ApplicationDependents) StartDependents(ctx context.Context, containerIDs []string) error {
+	var failed []string
+	for _, containerID := range containerIDs {
+		if err := d.containers.StartContainer(ctx, containerID); err != nil {
+			slog.Error("Failed to start dependent application container", "container_id", containerID, "error", err)
+			failed = append(failed, containerID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to start containers: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func usesDatabase(app *applications.Application, db *databases.Database) bool {
+	hostname := database.ContainerName(db)
+	connectionString := db.ConnectionString()
+
+	for _, value := range app.EnvVars() {
+		if strings.Contains(value, hostname) {
+			return true
+		}
+		if connectionString != "" && strings.Contains(value, connectionString) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+type UpdateExposureCommand struct {
+	ID       databases.DatabaseID
+	Mode     databases.ExposureMode
+	Hostname string
+	HostPort int // 0 keeps the current allocation
+}
+
+// UpdateExposure changes how a database is reachable from outside the host.
+// Running databases are redeployed so the new bindings and routes apply.
+func (s *DatabaseService) UpdateExposure(ctx context.Context, cmd UpdateExposureCommand) (*databases.Database, error) {
+	database, err := s.repo.GetByID(cmd.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	exposure, err := databases.NewExposure(cmd.Mode, cmd.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exposure: %w", err)
+	}
+
+	if exposure.Hostname() != "" {
+		exists, err := s.repo.ExistsByExposureHostname(exposure.Hostname(), database.ID())
+		if err != nil {
+			return nil, fmt.Errorf("failed to check hostname: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("hostname %s is already used by another database", exposure.Hostname())
+		}
+	}
+
+	if cmd.HostPort != 0 && cmd.HostPort != database.HostPort() {
+		if err := s.checkHostPort(ctx, database, cmd.HostPort); err != nil {
+			return nil, err
+		}
+		database.SetHostPort(cmd.HostPort)
+	} else if err := s.ensureHostPort(ctx, database); err != nil {
+		return nil, err
+	}
+
+	database.SetExposure(exposure)
+	database.SetConnectionString(database.GenerateConnectionString())
+
+	if err := s.repo.Update(database); err != nil {
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+
+	if database.Status() == databases.DatabaseStatusRunning {
+		if err := s.redeploy(ctx, database); err != nil {
+			return nil, err
+		}
+	}
+
+	return database, nil
+}
+
+// ensureHostPort allocates a host port to databases that don't have one yet
+func (s *DatabaseService) ensureHostPort(ctx context.Context, database *databases.Database) error {
+	if database.HostPort() != 0 {
+		return nil
+	}
+
+	port, err := s.allocateHostPort(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	database.SetHostPort(port)
+	database.SetConnectionString(database.GenerateConnectionString())
+	return nil
+}
+
+// allocateHostPort returns the lowest port of the pool that is neither assigned
+// to a database nor published by a container other than the one of database
+func (s *DatabaseService) allocateHostPort(ctx context.Context, database *databases.Database) (int, error) {
+	allocated, err := s.repo.ListHostPorts()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list allocated host ports: %w", err)
+	}
+
+	published, err := s.containerDeployment.ListHostPorts(ctx, database)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list published host ports: %w", err)
+	}
+
+	for port := databases.HostPortRangeStart; port <= databases.HostPortRangeEnd; port++ {
+		if _, ok := allocated[port]; ok {
+			continue
+		}
+		if _, ok := published[port]; ok {
+			continue
+		}
+		return port, nil
+	}
+
+	return 0, fmt.Errorf("no free host port left in range %d-%d", databases.HostPortRangeStart, databases.HostPortRangeEnd)
+}
+
+// checkHostPort verifies that port can be assigned to database
+func (s *DatabaseService) checkHostPort(ctx context.Context, database *databases.Database, port int) error {
+	if err := databases.ValidateHostPort(port); err != nil {
+		return err
+	}
+
+	allocated, err := s.repo.ListHostPorts()
+	if err != nil {
+		return fmt.Errorf("failed to list allocated host ports: %w", err)
+	}
+	if owner, ok := allocated[port]; ok && owner != database.ID() {
+		return fmt.Errorf("host port %d is already allocated to another database", port)
+	}
+
+	published, err := s.containerDeployment.ListHostPorts(ctx, database)
+	if err != nil {
+		return fmt.Errorf("failed to list published host ports: %w", err)
+	}
+	if owner, ok := published[port]; ok {
+		return fmt.Errorf("host port %d is already in use by container %s", port, owner)
+	}
+
+	return nil
+}
+
+// redeploy replaces the container of a running database with one built from its current state
+func (s *DatabaseService) redeploy(ctx context.Context, database *databases.Database) error {
+	if database.ContainerID() != "" {
+		if err := s.containerDeployment.Remove(ctx, database); err != nil {
+			return fmt.Errorf("failed to remove database container: %w", err)
+		}
+	}
+
+	deployResult, err := s.containerDeployment.Deploy(ctx, database)
+	if err != nil {
+		database.ChangeStatus(databases.DatabaseStatusFailed)
+		database.SetContainerID("")
+		_ = s.repo.Update(database)
+		return fmt.Errorf("failed to redeploy database container: %w", err)
+	}
+
+	database.SetContainerID(deployResult.ContainerID)
+	if err := s.repo.Update(database); err != nil {
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	return nil
+}
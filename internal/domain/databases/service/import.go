@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/activities"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+// importTimeout bounds loading an import into a database
+const importTimeout = 6 * time.Hour
+
+// ActivityLogger records events in the activity log
+type ActivityLogger interface {
+	LogActivity(
+		eventType activities.EventType,
+		description string,
+		initiatorID *uuid.UUID,
+		resourceType *string,
+		resourceID *uuid.UUID,
+		resourceName *string,
+		metadata map[string]any,
+		organizationID uuid.UUID,
+	) error
+}
+
+// ImportDatabaseCommand brings data into a database, either from an uploaded
+// dump or by copying an external database. Exactly one of Dump and SourceURL
+// is set.
+type ImportDatabaseCommand struct {
+	ID databases.DatabaseID
+	// Dump is a .sql script, a pg_dump custom archive or a mongodump archive,
+	// optionally gzipped
+	Dump io.Reader
+	// SourceURL is the connection string of a reachable database of the same type
+	SourceURL string
+
+	InitiatorID    string
+	OrganizationID string
+}
+
+// ImportDatabase starts an import into a running database. An uploaded dump
+// is read to the end before it returns; loading it, or copying the external
+// database, continues in the background. Progress is read with OperationLog
+// and the outcome is recorded in the activity log of the database.
+func (s *DatabaseService) ImportDatabase(ctx context.Context, cmd ImportDatabaseCommand) error {
+	target, err := s.repo.GetByID(cmd.ID)
+	if err != nil {
+		return fmt.Errorf("database not found: %w", err)
+	}
+	if target.Status() != databases.DatabaseStatusRunning {
+		return fmt.Errorf("database must be running to import into it (status: %s)", target.Status())
+	}
+	if target.IsReplica() {
+		return fmt.Errorf("replicas are read-only; import into the primary instead")
+	}
+
+	if (cmd.Dump == nil) == (cmd.SourceURL == "") {
+		return fmt.Errorf("either a dump or a source connection string is required")
+	}
+
+	source := "uploaded dump"
+	if cmd.SourceURL != "" {
+		if err := database.ValidateImportSource(target.Type(), cmd.SourceURL); err != nil {
+			return err
+		}
+		u, _ := url.Parse(cmd.SourceURL)
+		source = u.Redacted()
+	}
+
+	progress, err := s.operations.begin(target.ID().String(), OperationImport)
+	if err != nil {
+		return err
+	}
+
+	workDir := filepath.Join(s.restoreDir, uuid.Must(uuid.NewV7()).String())
+
+	var format string
+	if cmd.Dump != nil {
+		fmt.Fprintln(progress, "Receiving dump")
+		format, err = s.stageImport(target, cmd.Dump, workDir, progress)
+		if err != nil {
+			fmt.Fprintf(progress, "Import failed: %v\n", err)
+			progress.close()
+			_ = os.RemoveAll(workDir)
+			return err
+		}
+	}
+
+	s.logImportActivity(target, cmd, activities.EventTypeDatabaseImporting,
+		fmt.Sprintf("Import into database '%s' started from %s", target.Name().String(), source),
+		map[string]any{"source": source, "format": format})
+
+	go func() {
+		defer progress.close()
+		defer func() {
+			_ = os.RemoveAll(workDir)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+		defer cancel()
+
+		start := time.Now()
+		var err error
+		if cmd.SourceURL != "" {
+			err = s.containerDeployment.Pull(ctx, target, cmd.SourceURL, workDir, progress)
+		} else {
+			err = s.containerDeployment.Import(ctx, target, workDir, format, progress)
+		}
+
+		if err != nil {
+			slog.Error("Database import failed", "database_id", target.ID().String(), "error", err)
+			fmt.Fprintf(progress, "Import failed: %v\n", err)
+			s.logImportActivity(target, cmd, activities.EventTypeDatabaseImportFailed,
+				fmt.Sprintf("Import into database '%s' failed", target.Name().String()),
+				map[string]any{"source": source, "format": format, "error": err.Error()})
+			return
+		}
+
+		fmt.Fprintln(progress, "Import completed")
+		s.logImportActivity(target, cmd, activities.EventTypeDatabaseImported,
+			fmt.Sprintf("Imported %s into database '%s'", source, target.Name().String()),
+			map[string]any{"source": source, "format": format, "duration_seconds": int(time.Since(start).Seconds())})
+	}()
+
+	return nil
+}
+
+func (s *DatabaseService) stageImport(target *databases.Database, dump io.Reader, workDir string, progress io.Writer) (string, error) {
+	format, size, err := database.StageImport(dump, workDir)
+	if err != nil {
+		return "", err
+	}
+	if !database.SupportsImport(target.Type(), format) {
+		return "", fmt.Errorf("a %s dump cannot be imported into a %s database", format, target.Type())
+	}
+
+	fmt.Fprintf(progress, "Received %d bytes (%s)\n", size, format)
+	return format, nil
+}
+
+func (s *DatabaseService) logImportActivity(target *databases.Database, cmd ImportDatabaseCommand, eventType activities.EventType, description string, metadata map[string]any) {
+	if s.activities == nil {
+		return
+	}
+
+	orgID, err := uuid.Parse(cmd.OrganizationID)
+	if err != nil {
+		return
+	}
+	var initiatorID *uuid.UUID
+	if id, err := uuid.Parse(cmd.InitiatorID); err == nil {
+		initiatorID = &id
+	}
+	resourceID, _ := uuid.Parse(target.ID().String())
+	resourceType := "database"
+	resourceName := target.Name().String()
+
+	if err := s.activities.LogActivity(eventType, description, initiatorID, &resourceType, &resourceID, &resourceName, metadata, orgID); err != nil {
+		slog.Error("Failed to log database import activity", "database_id", target.ID().String(), "error", err)
+	}
+}
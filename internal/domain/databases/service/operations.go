@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// maxOperationLogSize caps the progress output kept in memory per operation
+const maxOperationLogSize = 4 << 20
+
+var (
+	ErrOperationInProgress = errors.New("a restore, upgrade, import or replica change is already running for this database")
+	ErrNoOperationLog      = errors.New("this operation has not run for this database")
+)
+
+// Operation is a long-running change to a database that reports progress
+type Operation string
+
+const (
+	OperationRestore Operation = "restore"
+	OperationUpgrade Operation = "upgrade"
+	OperationImport  Operation = "import"
+	// OperationReplication covers creating a replica, on both the replica and
+	// its primary, and promoting one
+	OperationReplication Operation = "replication"
+)
+
+// OperationLog returns the progress of the latest operation of a kind on a
+// database. With follow the reader blocks for new output until the operation
+// finishes or ctx is cancelled.
+func (s *DatabaseService) OperationLog(ctx context.Context, id databases.DatabaseID, operation Operation, follow bool) (io.Reader, error) {
+	log := s.operations.get(id.String(), operation)
+	if log == nil {
+		return nil, ErrNoOperationLog
+	}
+	return log.reader(ctx, follow), nil
+}
+
+type operationKey struct {
+	databaseID string
+	operation  Operation
+}
+
+// operationLogs keeps the progress output of the latest operation of each kind
+// per database. Only one operation runs on a database at a time.
+type operationLogs struct {
+	mu   sync.Mutex
+	logs map[operationKey]*operationLog
+}
+
+func newOperationLogs() *operationLogs {
+	return &operationLogs{logs: make(map[operationKey]*operationLog)}
+}
+
+// begin replaces the log of an operation unless any operation is still
+// running on the database
+func (o *operationLogs) begin(databaseID string, operation Operation) (*operationLog, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for key, existing := range o.logs {
+		if key.databaseID == databaseID && !existing.finished() {
+			return nil, ErrOperationInProgress
+		}
+	}
+
+	log := &operationLog{changed: make(chan struct{})}
+	o.logs[operationKey{databaseID: databaseID, operation: operation}] = log
+	return log, nil
+}
+
+func (o *operationLogs) get(databaseID string, operation Operation) *operationLog {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.logs[operationKey{databaseID: databaseID, operation: operation}]
+}
+
+// operationLog is an append-only buffer that readers can follow
+type operationLog struct {
+	mu      sync.Mutex
+	data    []byte
+	done    bool
+	changed chan struct{} // closed and replaced on every write
+}
+
+func (l *operationLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.data) < maxOperationLogSize {
+		l.data = append(l.data, p[:min(len(p), maxOperationLogSize-len(l.data))]...)
+	}
+	l.notify()
+	return len(p), nil
+}
+
+func (l *operationLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.done = true
+	l.notify()
+}
+
+func (l *operationLog) finished() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done
+}
+
+func (l *operationLog) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *operationLog) reader(ctx context.Context, follow bool) io.Reader {
+	return &operationLogReader{log: l, ctx: ctx, follow: follow}
+}
+
+type operationLogReader struct {
+	log    *operationLog
+	ctx    context.Context
+	follow bool
+	offset int
+}
+
+func (r *operationLogReader) Read(p []byte) (int, error) {
+	for {
+		r.log.mu.Lock()
+		if r.offset < len(r.log.data) {
+			n := copy(p, r.log.data[r.offset:])
+			r.offset += n
+			r.log.mu.Unlock()
+			return n, nil
+		}
+		if r.log.done || !r.follow {
+			r.log.mu.Unlock()
+			return 0, io.EOF
+		}
+		changed := r.log.changed
+		r.log.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+}
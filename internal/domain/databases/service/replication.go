@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+const (
+	// replicationTimeout bounds seeding or promoting a replica
+	replicationTimeout = 6 * time.Hour
+	// replicationStatusTimeout bounds reading the lag of a replica
+	replicationStatusTimeout = 10 * time.Second
+)
+
+type CreateReplicaCommand struct {
+	PrimaryID   databases.DatabaseID
+	Name        string
+	Description string
+}
+
+// CreateReplica adds a streaming read replica to a running PostgreSQL or MySQL
+// database. The replica is a database of its own in the environment of the
+// primary, sharing its credentials, with its own container and connection
+// string. It is returned as soon as it has been recorded; copying the primary
+// continues in the background and is read with OperationLog on the replica.
+// The first replica of a MySQL database restarts the primary to turn on the
+// binary log and GTIDs.
+func (s *DatabaseService) CreateReplica(ctx context.Context, cmd CreateReplicaCommand) (*databases.Database, error) {
+	primary, err := s.repo.GetByID(cmd.PrimaryID)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+	if primary.IsReplica() {
+		return nil, fmt.Errorf("replicas can't have replicas of their own; add it to the primary instead")
+	}
+	if err := database.ValidateReplication(primary); err != nil {
+		return nil, err
+	}
+	if primary.Status() != databases.DatabaseStatusRunning {
+		return nil, fmt.Errorf("database must be running to add a replica (status: %s)", primary.Status())
+	}
+
+	name, err := databases.NewDatabaseName(cmd.Name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database name: %w", err)
+	}
+	exists, err := s.repo.ExistsByName(primary.ProjectID(), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if database exists: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("database with name %s already exists in project", name.String())
+	}
+
+	replicas, err := s.listReplicas(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	// Holding an operation on the primary keeps restores and upgrades off it
+	// while it is being copied
+	primaryLog, err := s.operations.begin(primary.ID().String(), OperationReplication)
+	if err != nil {
+		return nil, err
+	}
+
+	replica, restartPrimary, err := s.recordReplica(ctx, primary, replicas, name, cmd.Description)
+	if err != nil {
+		primaryLog.close()
+		return nil, err
+	}
+
+	progress, err := s.operations.begin(replica.ID().String(), OperationReplication)
+	if err != nil {
+		primaryLog.close()
+		return nil, err
+	}
+
+	go func() {
+		defer primaryLog.close()
+		defer progress.close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), replicationTimeout)
+		defer cancel()
+
+		workDir := filepath.Join(s.restoreDir, uuid.Must(uuid.NewV7()).String())
+		defer func() {
+			_ = os.RemoveAll(workDir)
+		}()
+
+		out := io.MultiWriter(progress, primaryLog)
+		if err := s.seedReplica(ctx, primary, replica, restartPrimary, workDir, out); err != nil {
+			slog.Error("Replica setup failed", "database_id", replica.ID().String(), "primary_id", primary.ID().String(), "error", err)
+			fmt.Fprintf(out, "Replica setup failed: %v\n", err)
+			replica.ChangeStatus(databases.DatabaseStatusFailed)
+			_ = s.repo.Update(replica)
+			return
+		}
+		fmt.Fprintln(out, "Replica is running")
+	}()
+
+	return replica, nil
+}
+
+// recordReplica creates the record of a new replica, first setting the
+// primary up for replication if it has no replicas yet. It reports whether
+// the primary has to be restarted to pick up its new settings.
+func (s *DatabaseService) recordReplica(ctx context.Context, primary *databases.Database, replicas []*databases.Database, name databases.DatabaseName, description string) (*databases.Database, bool, error) {
+	source := primary.Replication()
+	restartPrimary := false
+	if source == nil {
+		password, err := generateReplicationPassword()
+		if err != nil {
+			return nil, false, err
+		}
+		source = &databases.ReplicationConfig{
+			User:     database.ReplicationUser,
+			Password: password,
+			ServerID: 1,
+		}
+		primary.UpdateConfig(primary.Config().WithReplication(source))
+		if err := s.repo.Update(primary); err != nil {
+			return nil, false, fmt.Errorf("failed to update database: %w", err)
+		}
+		// PostgreSQL streams with its default settings, MySQL needs a binary log
+		restartPrimary = primary.Type() == databases.DatabaseTypeMySQL
+	}
+
+	serverID := source.ServerID
+	for _, replica := range replicas {
+		if replication := replica.Replication(); replication != nil {
+			serverID = max(serverID, replication.ServerID)
+		}
+	}
+
+	config := primary.Config().WithReplication(&databases.ReplicationConfig{
+		PrimaryID:   primary.ID().String(),
+		PrimaryHost: database.ContainerName(primary),
+		PrimaryPort: primary.GetMainPort(),
+		User:        source.User,
+		Password:    source.Password,
+		ServerID:    serverID + 1,
+	})
+	// WAL is archived by the primary only
+	if config.PostgreSQL != nil {
+		pg := *config.PostgreSQL
+		pg.WALArchiving = false
+		config.PostgreSQL = &pg
+	}
+
+	replica := databases.NewDatabase(name, description, primary.Type(), primary.ProjectID(), primary.EnvironmentID(), config)
+	replica.SetConnectionString(replica.GenerateConnectionString())
+	replica.SetPorts(map[string]int{
+		"main": replica.GetMainPort(),
+	})
+	if err := s.ensureHostPort(ctx, replica); err != nil {
+		return nil, false, fmt.Errorf("failed to allocate host port: %w", err)
+	}
+	replica.ChangeStatus(databases.DatabaseStatusProvisioning)
+
+	if err := s.repo.Create(replica); err != nil {
+		return nil, false, fmt.Errorf("failed to create database: %w", err)
+	}
+
+	return replica, restartPrimary, nil
+}
+
+// seedReplica sets up the primary, copies it into the replica and starts the
+// replica. A PostgreSQL data directory is cloned before the replica first
+// starts, while MySQL loads the copy into a running server.
+func (s *DatabaseService) seedReplica(ctx context.Context, primary, replica *databases.Database, restartPrimary bool, workDir string, progress io.Writer) error {
+	if restartPrimary {
+		fmt.Fprintln(progress, "Restarting the primary with binary logging and GTIDs")
+		if err := s.redeploy(ctx, primary); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(progress, "Preparing the primary for replication")
+	if err := s.containerDeployment.PrepareReplicationSource(ctx, primary, workDir, progress); err != nil {
+		return fmt.Errorf("failed to prepare the primary: %w", err)
+	}
+
+	if replica.Type() == databases.DatabaseTypePostgreSQL {
+		if err := s.containerDeployment.SeedReplica(ctx, primary, replica, workDir, progress); err != nil {
+			return fmt.Errorf("failed to copy the primary: %w", err)
+		}
+	}
+
+	fmt.Fprintln(progress, "Starting replica container")
+	deployResult, err := s.containerDeployment.Deploy(ctx, replica)
+	if err != nil {
+		return fmt.Errorf("failed to deploy database container: %w", err)
+	}
+	replica.SetContainerID(deployResult.ContainerID)
+	if err := s.repo.Update(replica); err != nil {
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	if replica.Type() == databases.DatabaseTypeMySQL {
+		if err := s.containerDeployment.SeedReplica(ctx, primary, replica, workDir, progress); err != nil {
+			return fmt.Errorf("failed to copy the primary: %w", err)
+		}
+	}
+
+	replica.ChangeStatus(databases.DatabaseStatusRunning)
+	if err := s.repo.Update(replica); err != nil {
+		return fmt.Errorf("failed to update database status: %w", err)
+	}
+	return nil
+}
+
+// ListReplicas returns the read replicas of a database
+func (s *DatabaseService) ListReplicas(ctx context.Context, id databases.DatabaseID) ([]*databases.Database, error) {
+	primary, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+	return s.listReplicas(primary)
+}
+
+func (s *DatabaseService) listReplicas(primary *databases.Database) ([]*databases.Database, error) {
+	all, err := s.repo.ListByProject(primary.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	var replicas []*databases.Database
+	for _, db := range all {
+		if replication := db.Replication(); replication != nil && replication.PrimaryID == primary.ID().String() {
+			replicas = append(replicas, db)
+		}
+	}
+	return replicas, nil
+}
+
+// GetReplicationStatus reports the state and lag of a running replica
+func (s *DatabaseService) GetReplicationStatus(ctx context.Context, id databases.DatabaseID) (*database.ReplicationStatus, error) {
+	replica, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+	if !replica.IsReplica() {
+		return nil, fmt.Errorf("database is not a replica")
+	}
+	if replica.Status() != databases.DatabaseStatusRunning {
+		return nil, fmt.Errorf("replica is not running (status: %s)", replica.Status())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, replicationStatusTimeout)
+	defer cancel()
+
+	return s.containerDeployment.ReplicationStatus(ctx, replica)
+}
+
+// PromoteReplica turns a running replica into a standalone database that
+// accepts writes. The primary keeps running unaffected. The replica stops
+// following it and is redeployed without its replica settings; progress is
+// read with OperationLog.
+func (s *DatabaseService) PromoteReplica(ctx context.Context, id databases.DatabaseID) error {
+	replica, err := s.repo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("database not found: %w", err)
+	}
+	if !replica.IsReplica() {
+		return fmt.Errorf("database is not a replica")
+	}
+	if replica.Status() != databases.DatabaseStatusRunning {
+		return fmt.Errorf("replica must be running to promote it (status: %s)", replica.Status())
+	}
+
+	progress, err := s.operations.begin(replica.ID().String(), OperationReplication)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer progress.close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), replicationTimeout)
+		defer cancel()
+
+		workDir := filepath.Join(s.restoreDir, uuid.Must(uuid.NewV7()).String())
+		defer func() {
+			_ = os.RemoveAll(workDir)
+		}()
+
+		if err := s.promoteReplica(ctx, replica, workDir, progress); err != nil {
+			slog.Error("Replica promotion failed", "database_id", replica.ID().String(), "error", err)
+			fmt.Fprintf(progress, "Promotion failed: %v\n", err)
+			return
+		}
+		fmt.Fprintln(progress, "Replica promoted to a standalone database")
+	}()
+
+	return nil
+}
+
+func (s *DatabaseService) promoteReplica(ctx context.Context, replica *databases.Database, workDir string, progress io.Writer) error {
+	primaryID := replica.Replication().PrimaryID
+
+	if err := s.containerDeployment.PromoteReplica(ctx, replica, workDir, progress); err != nil {
+		return err
+	}
+
+	replica.UpdateConfig(replica.Config().WithReplication(nil))
+	if err := s.repo.Update(replica); err != nil {
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	fmt.Fprintln(progress, "Restarting without replica settings")
+	if err := s.redeploy(ctx, replica); err != nil {
+		return err
+	}
+
+	s.dropReplicationSlot(ctx, primaryID, replica.ID().String())
+	return nil
+}
+
+// dropReplicationSlot lets the primary of a former replica stop keeping WAL
+// for it. Failures are only logged: the primary may be gone or stopped, and
+// the slot can still be dropped by hand.
+func (s *DatabaseService) dropReplicationSlot(ctx context.Context, primaryID, replicaID string) {
+	id, err := databases.DatabaseIDFromString(primaryID)
+	if err != nil {
+		return
+	}
+	primary, err := s.repo.GetByID(id)
+	if err != nil || primary.Status() != databases.DatabaseStatusRunning {
+		return
+	}
+
+	workDir := filepath.Join(s.restoreDir, uuid.Must(uuid.NewV7()).String())
+	defer func() {
+		_ = os.RemoveAll(workDir)
+	}()
+
+	if err := s.containerDeployment.DropReplicationSlot(ctx, primary, replicaID, workDir); err != nil {
+		slog.Warn("Failed to drop replication slot", "primary_id", primaryID, "slot", database.ReplicationSlotName(replicaID), "error", err)
+	}
+}
+
+// generateReplicationPassword returns a random hex password, which the
+// replication scripts can use in SQL without quoting
+func generateReplicationPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate replication password: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+// restoreTimeout bounds a restore including fetching the backup
+const restoreTimeout = 6 * time.Hour
+
+type RestoreTarget string
+
+const (
+	// RestoreTargetInPlace overwrites the data of the database the backup was taken from
+	RestoreTargetInPlace RestoreTarget = "in_place"
+	// RestoreTargetNew provisions a copy of the database in the same environment
+	RestoreTargetNew RestoreTarget = "new"
+)
+
+// BackupSource provides the dump a restore loads
+type BackupSource interface {
+	// Format is the dump format of the backup
+	Format() string
+
+	// Fetch writes the verified, uncompressed dump to dir/database.DumpFileName
+	Fetch(ctx context.Context, dir string) error
+}
+
+type RestoreDatabaseCommand struct {
+	ID     databases.DatabaseID
+	Target RestoreTarget
+	// Name of the database created for RestoreTargetNew. Defaults to
+	// <name>-restore-<timestamp>.
+	Name   string
+	Source BackupSource
+}
+
+// RestoreDatabase loads a backup into the database or into a new copy of it.
+// The target database is returned as soon as the restore has started; its
+// progress is read with OperationLog.
+//
+// In-place restores stop the applications that use the database first and
+// start them again afterwards, whether or not the restore succeeded.
+func (s *DatabaseService) RestoreDatabase(ctx context.Context, cmd RestoreDatabaseCommand) (*databases.Database, error) {
+	source, err := s.repo.GetByID(cmd.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	if cmd.Source == nil {
+		return nil, fmt.Errorf("backup source is required")
+	}
+	if !database.SupportsRestore(source.Type(), cmd.Source.Format()) {
+		return nil, fmt.Errorf("a %s backup cannot be restored into a %s database", cmd.Source.Format(), source.Type())
+	}
+
+	var target *databases.Database
+	switch cmd.Target {
+	case RestoreTargetInPlace:
+		if source.Status() != databases.DatabaseStatusRunning {
+			return nil, fmt.Errorf("database must be running to restore in place (status: %s)", source.Status())
+		}
+		if source.IsReplica() {
+			return nil, fmt.Errorf("replicas are read-only; restore into the primary instead")
+		}
+		target = source
+
+	case RestoreTargetNew:
+		name := cmd.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-restore-%s", source.Name().String(), time.Now().UTC().Format("20060102-150405"))
+		}
+		config := source.Config()
+		if config.PostgreSQL != nil {
+			// The copy starts without WAL archiving; it can be enabled separately
+			pg := *config.PostgreSQL
+			pg.WALArchiving = false
+			config.PostgreSQL = &pg
+		}
+		target, err = s.CreateDatabase(ctx, CreateDatabaseCommand{
+			Name:          name,
+			Description:   fmt.Sprintf("Restored from a backup of %s", source.Name().String()),
+			Type:          source.Type(),
+			ProjectID:     source.ProjectID(),
+			EnvironmentID: source.EnvironmentID(),
+			Config:        &config,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create restore target: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid restore target: %s", cmd.Target)
+	}
+
+	progress, err := s.operations.begin(target.ID().String(), OperationRestore)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer progress.close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+		defer cancel()
+
+		if err := s.runRestore(ctx, target, cmd.Target, cmd.Source, progress); err != nil {
+			slog.Error("Database restore failed", "database_id", target.ID().String(), "error", err)
+			fmt.Fprintf(progress, "Restore failed: %v\n", err)
+			return
+		}
+		fmt.Fprintln(progress, "Restore completed")
+	}()
+
+	return target, nil
+}
+
+func (s *DatabaseService) runRestore(ctx context.Context, target *databases.Database, mode RestoreTarget, source BackupSource, progress io.Writer) error {
+	workDir := filepath.Join(s.restoreDir, uuid.Must(uuid.NewV7()).String())
+	defer func() {
+		_ = os.RemoveAll(workDir)
+	}()
+
+	fmt.Fprintln(progress, "Fetching backup")
+	if err := source.Fetch(ctx, workDir); err != nil {
+		return fmt.Errorf("failed to fetch backup: %w", err)
+	}
+
+	if mode == RestoreTargetNew {
+		fmt.Fprintf(progress, "Starting database %s\n", target.Name().String())
+		if err := s.StartDatabase(ctx, target.ID()); err != nil {
+			return err
+		}
+		// StartDatabase stores the container, so reload before handing it on
+		reloaded, err := s.repo.GetByID(target.ID())
+		if err != nil {
+			return fmt.Errorf("failed to reload database: %w", err)
+		}
+		target = reloaded
+	}
+
+	if mode == RestoreTargetInPlace && s.dependents != nil {
+		stopped, err := s.dependents.StopDependents(ctx, target)
+		if err != nil {
+			return fmt.Errorf("failed to stop dependent applications: %w", err)
+		}
+		fmt.Fprintf(progress, "Stopped %d dependent application(s)\n", len(stopped))
+		defer func() {
+			fmt.Fprintf(progress, "Starting %d dependent application(s)\n", len(stopped))
+			if err := s.dependents.StartDependents(context.Background(), stopped); err != nil {
+				fmt.Fprintf(progress, "Warning: %v\n", err)
+			}
+		}()
+	}
+
+	fmt.Fprintln(progress, "Restoring backup")
+	if err := s.containerDeployment.Restore(ctx, target, workDir, source.Format(), progress); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -20,6 +20,8 @@ type DatabaseRepository interface {
 	Update(database *databases.Database) error
 	Delete(id databases.DatabaseID) error
 	ExistsByName(projectID uuid.UUID, name databases.DatabaseName) (bool, error)
+	ListHostPorts() (map[int]databases.DatabaseID, error)
+	ExistsByExposureHostname(hostname string, excludeID databases.DatabaseID) (bool, error)
 }
 
 type DiskService interface {
@@ -31,13 +33,23 @@ type DatabaseService struct {
 	repo                DatabaseRepository
 	containerDeployment database.DatabaseDeploymentService
 	diskService         DiskService
+	dependents          DependentApplications
+	activities          ActivityLogger
+	restoreDir          string
+	upgradeDir          string
+	operations          *operationLogs
 }
 
-func NewDatabaseService(repo DatabaseRepository, containerDeployment database.DatabaseDeploymentService, diskService DiskService) *DatabaseService {
+func NewDatabaseService(repo DatabaseRepository, containerDeployment database.DatabaseDeploymentService, diskService DiskService, dependents DependentApplications, activities ActivityLogger, restoreDir, upgradeDir string) *DatabaseService {
 	return &DatabaseService{
 		repo:                repo,
 		containerDeployment: containerDeployment,
 		diskService:         diskService,
+		dependents:          dependents,
+		activities:          activities,
+		restoreDir:          restoreDir,
+		upgradeDir:          upgradeDir,
+		operations:          newOperationLogs(),
 	}
 }
 
@@ -101,6 +113,11 @@ func (s *DatabaseService) CreateDatabase(ctx context.Context, cmd CreateDatabase
 
 	database.SetPorts(ports)
 
+	// Allocate a dedicated host port so instances of the same engine don't collide
+	if err := s.ensureHostPort(ctx, database); err != nil {
+		return nil, fmt.Errorf("failed to allocate host port: %w", err)
+	}
+
 	if err := s.repo.Create(database); err != nil {
 		return nil, fmt.Errorf("failed to create database: %w", err)
 	}
@@ -146,7 +163,21 @@ func (s *DatabaseService) UpdateDatabase(ctx context.Context, cmd UpdateDatabase
 	}
 
 	if cmd.Config != nil {
-		database.UpdateConfig(*cmd.Config)
+		config := *cmd.Config
+		// WAL archiving is switched through SetWALArchiving only
+		if current := database.Config().PostgreSQL; current != nil && config.PostgreSQL != nil {
+			pg := *config.PostgreSQL
+			pg.WALArchiving = current.WALArchiving
+			config.PostgreSQL = &pg
+		}
+		// Replication is set up through the replica operations only
+		config = config.WithReplication(database.Replication())
+		// Deployed databases change version through UpgradeDatabase, since a
+		// new image may not read the existing data files
+		if database.ContainerID() != "" {
+			config = config.WithVersion(database.Version())
+		}
+		database.UpdateConfig(config)
 
 		// Regenerate connection string if config changed
 		connectionString := database.GenerateConnectionString()
@@ -186,6 +217,14 @@ func (s *DatabaseService) DeleteDatabase(ctx context.Context, id databases.Datab
 		return fmt.Errorf("cannot delete database: %w", err)
 	}
 
+	replicas, err := s.listReplicas(database)
+	if err != nil {
+		return err
+	}
+	if len(replicas) > 0 {
+		return fmt.Errorf("cannot delete database: it has %d read replicas; delete or promote them first", len(replicas))
+	}
+
 	// Mark as deleting
 	database.ChangeStatus(databases.DatabaseStatusDeleting)
 	if err := s.repo.Update(database); err != nil {
@@ -201,6 +240,11 @@ func (s *DatabaseService) DeleteDatabase(ctx context.Context, id databases.Datab
 		}
 	}
 
+	// The primary would otherwise keep WAL for the replica forever
+	if database.IsReplica() {
+		s.dropReplicationSlot(ctx, database.Replication().PrimaryID, database.ID().String())
+	}
+
 	// Delete the database record
 	if err := s.repo.Delete(id); err != nil {
 		return fmt.Errorf("failed to delete database: %w", err)
@@ -217,6 +261,12 @@ func (s *DatabaseService) ListDatabases(ctx context.Context, projectID uuid.UUID
 	return databases, nil
 }
 
+// ListDatabasesWithContainers lists the databases of every project that have
+// been deployed to a container
+func (s *DatabaseService) ListDatabasesWithContainers(ctx context.Context) ([]*databases.Database, error) {
+	return s.repo.ListAllWithContainers()
+}
+
 func (s *DatabaseService) ListDatabasesByEnvironment(ctx context.Context, projectID, environmentID uuid.UUID) ([]*databases.Database, error) {
 	databases, err := s.repo.ListByEnvironment(projectID, environmentID)
 	if err != nil {
@@ -235,6 +285,11 @@ func (s *DatabaseService) StartDatabase(ctx context.Context, id databases.Databa
 		return fmt.Errorf("cannot start database: %w", err)
 	}
 
+	// Databases created before host port allocation get one on their next start
+	if err := s.ensureHostPort(ctx, database); err != nil {
+		return fmt.Errorf("failed to allocate host port: %w", err)
+	}
+
 	database.ChangeStatus(databases.DatabaseStatusProvisioning)
 
 	if err := s.repo.Update(database); err != nil {
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/database"
+)
+
+// upgradeTimeout bounds an upgrade including the snapshot and any rollback
+const upgradeTimeout = 6 * time.Hour
+
+type UpgradeDatabaseCommand struct {
+	ID      databases.DatabaseID
+	Version string
+}
+
+// UpgradeDatabase moves a running database to another version of its engine.
+// The plan is returned as soon as the upgrade has started; its progress is
+// read with OperationLog.
+//
+// The data volumes are snapshotted first, with the applications that use the
+// database stopped until the upgrade has finished. After the upgrade the
+// schema is compared with what it was before; if any step or that
+// verification fails, the snapshot is restored on the previous version.
+func (s *DatabaseService) UpgradeDatabase(ctx context.Context, cmd UpgradeDatabaseCommand) (*database.UpgradePlan, error) {
+	db, err := s.repo.GetByID(cmd.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	if db.Status() != databases.DatabaseStatusRunning {
+		return nil, fmt.Errorf("database must be running to upgrade (status: %s)", db.Status())
+	}
+
+	// A replica must run the version of its primary
+	if db.IsReplica() {
+		return nil, fmt.Errorf("replicas can't be upgraded on their own; promote the replica first")
+	}
+	if replicas, err := s.listReplicas(db); err != nil {
+		return nil, err
+	} else if len(replicas) > 0 {
+		return nil, fmt.Errorf("delete or promote the read replicas of the database before upgrading it")
+	}
+
+	plan, err := database.PlanUpgrade(db.Type(), db.Version(), cmd.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived WAL and base backups can't be replayed across a dump and reload
+	if pg := db.Config().PostgreSQL; pg != nil && pg.WALArchiving && plan.Method == database.UpgradeMethodDumpRestore {
+		return nil, fmt.Errorf("disable point-in-time recovery before a major upgrade and enable it again afterwards")
+	}
+
+	progress, err := s.operations.begin(db.ID().String(), OperationUpgrade)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer progress.close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), upgradeTimeout)
+		defer cancel()
+
+		if err := s.runUpgrade(ctx, db, plan, progress); err != nil {
+			slog.Error("Database upgrade failed", "database_id", db.ID().String(), "error", err)
+			fmt.Fprintf(progress, "Upgrade failed: %v\n", err)
+			return
+		}
+		fmt.Fprintln(progress, "Upgrade completed")
+	}()
+
+	return plan, nil
+}
+
+func (s *DatabaseService) runUpgrade(ctx context.Context, db *databases.Database, plan *database.UpgradePlan, progress io.Writer) error {
+	workDir := filepath.Join(s.upgradeDir, uuid.Must(uuid.NewV7()).String())
+	keepWorkDir := false
+	defer func() {
+		if !keepWorkDir {
+			_ = os.RemoveAll(workDir)
+		}
+	}()
+
+	fmt.Fprintf(progress, "Upgrading %s from %s to %s (%s)\n", db.Name().String(), plan.FromVersion, plan.ToVersion, plan.Method)
+
+	fmt.Fprintln(progress, "Running compatibility checks")
+	if err := s.containerDeployment.CheckUpgrade(ctx, db, plan, workDir, progress); err != nil {
+		return fmt.Errorf("compatibility check failed: %w", err)
+	}
+
+	before, err := s.containerDeployment.Fingerprint(ctx, db, workDir)
+	if err != nil {
+		return fmt.Errorf("failed to record schema: %w", err)
+	}
+
+	if s.dependents != nil {
+		stopped, err := s.dependents.StopDependents(ctx, db)
+		if err != nil {
+			return fmt.Errorf("failed to stop dependent applications: %w", err)
+		}
+		fmt.Fprintf(progress, "Stopped %d dependent application(s)\n", len(stopped))
+		defer func() {
+			fmt.Fprintf(progress, "Starting %d dependent application(s)\n", len(stopped))
+			if err := s.dependents.StartDependents(context.Background(), stopped); err != nil {
+				fmt.Fprintf(progress, "Warning: %v\n", err)
+			}
+		}()
+	}
+
+	fmt.Fprintln(progress, "Taking a snapshot of the data volumes")
+	if err := s.containerDeployment.Snapshot(ctx, db, workDir, progress); err != nil {
+		return fmt.Errorf("failed to take snapshot: %w", err)
+	}
+
+	previous := db.Config()
+	upgradeErr := s.applyUpgrade(ctx, db, plan, workDir, before, progress)
+	if upgradeErr == nil {
+		return nil
+	}
+
+	fmt.Fprintf(progress, "Upgrade failed: %v\n", upgradeErr)
+	fmt.Fprintf(progress, "Rolling back to version %s\n", plan.FromVersion)
+	if err := s.rollbackUpgrade(ctx, db, previous, workDir, progress); err != nil {
+		keepWorkDir = true
+		fmt.Fprintf(progress, "The snapshot is kept at %s\n", filepath.Join(workDir, database.SnapshotFileName))
+		return fmt.Errorf("%w; rollback failed: %v", upgradeErr, err)
+	}
+
+	return fmt.Errorf("rolled back to version %s: %w", plan.FromVersion, upgradeErr)
+}
+
+// applyUpgrade moves the database to the target version and verifies its
+// schema against the fingerprint taken before
+func (s *DatabaseService) applyUpgrade(ctx context.Context, db *databases.Database, plan *database.UpgradePlan, workDir, before string, progress io.Writer) error {
+	if err := s.containerDeployment.PrepareUpgrade(ctx, db, plan, workDir, progress); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(progress, "Starting database on version %s\n", plan.ToVersion)
+	db.UpdateConfig(db.Config().WithVersion(plan.ToVersion))
+	if err := s.redeploy(ctx, db); err != nil {
+		return err
+	}
+
+	if err := s.containerDeployment.CompleteUpgrade(ctx, db, plan, workDir, progress); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(progress, "Verifying the upgraded database")
+	after, err := s.containerDeployment.Fingerprint(ctx, db, workDir)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if after != before {
+		return fmt.Errorf("verification failed: schema differs after the upgrade\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+
+	db.ChangeStatus(databases.DatabaseStatusRunning)
+	if err := s.repo.Update(db); err != nil {
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	return nil
+}
+
+// rollbackUpgrade restores the snapshot and redeploys the database on the
+// config it had before the upgrade
+func (s *DatabaseService) rollbackUpgrade(ctx context.Context, db *databases.Database, previous databases.DatabaseConfig, workDir string, progress io.Writer) error {
+	db.UpdateConfig(previous)
+
+	// The container may already be gone if redeploying failed
+	_ = s.containerDeployment.Stop(ctx, db)
+
+	if err := s.containerDeployment.RestoreSnapshot(ctx, db, workDir, progress); err != nil {
+		return err
+	}
+	if err := s.redeploy(ctx, db); err != nil {
+		return err
+	}
+
+	db.ChangeStatus(databases.DatabaseStatusRunning)
+	if err := s.repo.Update(db); err != nil {
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	return nil
+}
@@ -41,6 +41,9 @@ type CreateDeploymentRequest struct {
 	GitCommitMessage string                  `json:"git_commit_message,omitempty"`
 	GitBranch        string                  `json:"git_branch,omitempty"`
 	GitAuthorName    string                  `json:"git_author_name,omitempty"`
+	// GitCommittedAt is when the commit was made, lead time insights measure
+	// from it
+	GitCommittedAt *time.Time `json:"git_committed_at,omitempty"`
 }
 
 type DeploymentResponse struct {
@@ -59,6 +62,7 @@ type DeploymentResponse struct {
 	GitCommitMessage      string                       `json:"git_commit_message,omitempty"`
 	GitBranch             string                       `json:"git_branch,omitempty"`
 	GitAuthorName         string                       `json:"git_author_name,omitempty"`
+	GitCommittedAt        *string                      `json:"git_committed_at,omitempty"`
 	BuildStartedAt        *string                      `json:"build_started_at,omitempty"`
 	BuildCompletedAt      *string                      `json:"build_completed_at,omitempty"`
 	BuildDurationSeconds  *int                         `json:"build_duration_seconds,omitempty"`
@@ -135,6 +139,7 @@ func (h *DeploymentHandler) CreateDeployment(w http.ResponseWriter, r *http.Requ
 		GitCommitMessage: req.GitCommitMessage,
 		GitBranch:        req.GitBranch,
 		GitAuthorName:    req.GitAuthorName,
+		GitCommittedAt:   req.GitCommittedAt,
 	}
 
 	// If no image tag provided, generate one
@@ -674,6 +679,11 @@ func (h *DeploymentHandler) mapDeploymentToResponse(deployment *deployments.Depl
 		response.TriggeredBy = &triggeredBy
 	}
 
+	if deployment.GitCommittedAt() != nil {
+		gitCommittedAt := deployment.GitCommittedAt().Format("2006-01-02T15:04:05Z07:00")
+		response.GitCommittedAt = &gitCommittedAt
+	}
+
 	if deployment.BuildStartedAt() != nil {
 		buildStartedAt := deployment.BuildStartedAt().Format("2006-01-02T15:04:05Z07:00")
 		response.BuildStartedAt = &buildStartedAt
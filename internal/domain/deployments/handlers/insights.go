@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments/service"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type InsightsHandler struct {
+	insightsService    *service.InsightsService
+	applicationService service.ApplicationService
+	projectService     *projectsService.ProjectService
+}
+
+func NewInsightsHandler(insightsService *service.InsightsService, appService service.ApplicationService, projectService *projectsService.ProjectService) *InsightsHandler {
+	return &InsightsHandler{
+		insightsService:    insightsService,
+		applicationService: appService,
+		projectService:     projectService,
+	}
+}
+
+// GetProjectInsights returns the deployment insights of a project, overall and
+// per application. The days query parameter picks how many days back, up to
+// 365.
+func (h *InsightsHandler) GetProjectInsights(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return
+	}
+
+	start, end, ok := parseInsightsWindow(w, r)
+	if !ok {
+		return
+	}
+
+	insights, err := h.insightsService.GetProjectInsights(r.Context(), projectID, start, end)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "insights_failed", "Failed to compute deployment insights")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, insights)
+}
+
+// GetApplicationInsights returns the deployment insights of an application.
+// The days query parameter picks how many days back, up to 365.
+func (h *InsightsHandler) GetApplicationInsights(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return
+	}
+
+	applicationID, err := applications.ApplicationIDFromString(chi.URLParam(r, "application_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_application_id", "Invalid application ID")
+		return
+	}
+
+	app, err := h.applicationService.GetApplication(r.Context(), applicationID)
+	if err != nil || app.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "application_not_found", "Application not found")
+		return
+	}
+
+	start, end, ok := parseInsightsWindow(w, r)
+	if !ok {
+		return
+	}
+
+	insights, err := h.insightsService.GetApplicationInsights(r.Context(), app.ID(), start, end)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "insights_failed", "Failed to compute deployment insights")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, insights)
+}
+
+func parseInsightsWindow(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	days := deployments.DefaultInsightsDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > deployments.MaxInsightsDays {
+			utils.SendError(w, http.StatusBadRequest, "invalid_days", fmt.Sprintf("days must be between 1 and %d", deployments.MaxInsightsDays))
+			return time.Time{}, time.Time{}, false
+		}
+		days = parsed
+	}
+
+	end := time.Now()
+	return end.AddDate(0, 0, -days), end, true
+}
@@ -7,10 +7,12 @@ import (
 
 func RegisterDeploymentsRoutes(r chi.Router, deps *deps.Dependencies) {
 	deploymentHandler := NewDeploymentHandler(deps.DeploymentService, deps.ApplicationService)
+	insightsHandler := NewInsightsHandler(deps.InsightsService, deps.ApplicationService, deps.ProjectService)
 
 	r.Route("/deployments", func(r chi.Router) {
 		r.Get("/", deploymentHandler.ListDeployments)
 		r.Post("/", deploymentHandler.CreateDeployment)
+		r.Get("/insights", insightsHandler.GetApplicationInsights)
 		r.Route("/{deployment_id}", func(r chi.Router) {
 			r.Get("/", deploymentHandler.GetDeployment)
 			r.Post("/stop", deploymentHandler.StopDeployment)
@@ -20,3 +22,11 @@ func RegisterDeploymentsRoutes(r chi.Router, deps *deps.Dependencies) {
 		})
 	})
 }
+
+// RegisterProjectDeploymentRoutes registers the deployment routes spanning the
+// applications of a project
+func RegisterProjectDeploymentRoutes(r chi.Router, deps *deps.Dependencies) {
+	insightsHandler := NewInsightsHandler(deps.InsightsService, deps.ApplicationService, deps.ProjectService)
+
+	r.Get("/deployments/insights", insightsHandler.GetProjectInsights)
+}
@@ -0,0 +1,381 @@
+package deployments
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments/logs"
+)
+
+const (
+	// DefaultInsightsDays is how many days back insights look by default
+	DefaultInsightsDays = 30
+	MaxInsightsDays     = 365
+
+	// maxSlowestSteps is how many build steps insights list
+	maxSlowestSteps = 10
+)
+
+// Insights are DORA-style metrics of the deployments started in a window
+type Insights struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Deployments int       `json:"deployments"`
+	Succeeded   int       `json:"succeeded"`
+	Failed      int       `json:"failed"`
+	Cancelled   int       `json:"cancelled"`
+	InProgress  int       `json:"in_progress"`
+
+	// Frequency counts successful deployments
+	Frequency DeploymentFrequency `json:"frequency"`
+
+	// LeadTime runs from the commit to the container running. Deployments that
+	// did not report when their commit was made are measured from when they
+	// started, FromCommit counts the others.
+	LeadTime   DurationStats `json:"lead_time"`
+	FromCommit int           `json:"lead_time_from_commit"`
+
+	// ChangeFailureRate is the percentage of finished deployments that failed
+	ChangeFailureRate float64 `json:"change_failure_rate"`
+
+	// TimeToRestore runs from a failed deployment to the next successful one of
+	// the application, consecutive failures are restored together.
+	// Unrestored counts the failures still waiting for a successful deployment.
+	TimeToRestore DurationStats `json:"time_to_restore"`
+	Unrestored    int           `json:"unrestored"`
+
+	BuildDuration DurationStats `json:"build_duration"`
+	BuildTrend    []DailyBuilds `json:"build_trend"`
+	SlowestSteps  []StepStats   `json:"slowest_steps"`
+}
+
+type DeploymentFrequency struct {
+	PerDay  float64            `json:"per_day"`
+	PerWeek float64            `json:"per_week"`
+	Daily   []DailyDeployments `json:"daily"`
+}
+
+type DailyDeployments struct {
+	Date        string `json:"date"`
+	Deployments int    `json:"deployments"`
+	Succeeded   int    `json:"succeeded"`
+	Failed      int    `json:"failed"`
+}
+
+// DurationStats summarize durations in seconds, they are zero without samples
+type DurationStats struct {
+	Count          int     `json:"count"`
+	AverageSeconds float64 `json:"average_seconds"`
+	MedianSeconds  float64 `json:"median_seconds"`
+	P90Seconds     float64 `json:"p90_seconds"`
+	MaxSeconds     float64 `json:"max_seconds"`
+}
+
+type DailyBuilds struct {
+	Date           string  `json:"date"`
+	Builds         int     `json:"builds"`
+	AverageSeconds float64 `json:"average_seconds"`
+}
+
+// StepStats aggregate a build step over the builds that ran it, the average
+// leaves out the builds that took it from the cache
+type StepStats struct {
+	Stage          string  `json:"stage,omitempty"`
+	Instruction    string  `json:"instruction"`
+	Runs           int     `json:"runs"`
+	Cached         int     `json:"cached"`
+	AverageSeconds float64 `json:"average_seconds"`
+	MaxSeconds     float64 `json:"max_seconds"`
+}
+
+// ComputeInsights computes the insights of the deployments started between
+// start and end. The list may hold deployments of several applications and
+// from outside the window, later ones restore failures of the window.
+func ComputeInsights(list []*Deployment, start, end time.Time) *Insights {
+	return CollectInsights(list, start, end).Insights()
+}
+
+// InsightsSamples are the counts and samples insights are computed from. The
+// samples of several applications merge into the insights of all of them,
+// without going through their deployments again.
+type InsightsSamples struct {
+	start, end time.Time
+	counts     Insights
+	daily      map[string]*DailyDeployments
+	builds     map[string][]float64
+	leadTimes  []float64
+	buildTimes []float64
+	restores   []float64
+	steps      map[[2]string]*stepSamples
+}
+
+// CollectInsights collects the samples of the deployments started between
+// start and end, see ComputeInsights
+func CollectInsights(list []*Deployment, start, end time.Time) *InsightsSamples {
+	samples := &InsightsSamples{
+		start:  start,
+		end:    end,
+		daily:  make(map[string]*DailyDeployments),
+		builds: make(map[string][]float64),
+		steps:  make(map[[2]string]*stepSamples),
+	}
+	for _, day := range dayBuckets(start, end) {
+		samples.daily[day] = &DailyDeployments{Date: day}
+	}
+
+	sorted := slices.Clone(list)
+	slices.SortFunc(sorted, func(a, b *Deployment) int {
+		return a.StartedAt().Compare(b.StartedAt())
+	})
+
+	counts := &samples.counts
+	for _, deployment := range sorted {
+		if !inWindow(deployment.StartedAt(), start, end) {
+			continue
+		}
+
+		day := deployment.StartedAt().UTC().Format(time.DateOnly)
+		counts.Deployments++
+		samples.daily[day].Deployments++
+
+		switch {
+		case deployment.Succeeded():
+			counts.Succeeded++
+			samples.daily[day].Succeeded++
+			samples.leadTimes = append(samples.leadTimes, deployment.leadTime().Seconds())
+			if committedAt := deployment.GitCommittedAt(); committedAt != nil && committedAt.Before(deployment.StartedAt()) {
+				counts.FromCommit++
+			}
+		case deployment.Status() == DeploymentStatusFailed:
+			counts.Failed++
+			samples.daily[day].Failed++
+		case deployment.Status() == DeploymentStatusCancelled:
+			counts.Cancelled++
+		case deployment.Status() == DeploymentStatusStopped:
+			// Stopped before it got to run
+		default:
+			counts.InProgress++
+		}
+
+		// Only builds that produced an image, failed ones stop part way
+		if deployment.BuildDurationSeconds() != nil && deployment.DeployStartedAt() != nil {
+			seconds := float64(*deployment.BuildDurationSeconds())
+			samples.buildTimes = append(samples.buildTimes, seconds)
+			samples.builds[day] = append(samples.builds[day], seconds)
+		}
+
+		for _, step := range logs.ParseBuildSteps(deployment.BuildLogs()) {
+			key := [2]string{step.Stage, step.Instruction}
+			if samples.steps[key] == nil {
+				samples.steps[key] = &stepSamples{}
+			}
+			samples.steps[key].add(step)
+		}
+	}
+
+	samples.restores, counts.Unrestored = timesToRestore(sorted, start, end)
+
+	return samples
+}
+
+// Merge adds the samples of other, collected over the same window
+func (s *InsightsSamples) Merge(other *InsightsSamples) {
+	s.counts.Deployments += other.counts.Deployments
+	s.counts.Succeeded += other.counts.Succeeded
+	s.counts.Failed += other.counts.Failed
+	s.counts.Cancelled += other.counts.Cancelled
+	s.counts.InProgress += other.counts.InProgress
+	s.counts.FromCommit += other.counts.FromCommit
+	s.counts.Unrestored += other.counts.Unrestored
+
+	for day, daily := range other.daily {
+		if s.daily[day] == nil {
+			s.daily[day] = &DailyDeployments{Date: day}
+		}
+		s.daily[day].Deployments += daily.Deployments
+		s.daily[day].Succeeded += daily.Succeeded
+		s.daily[day].Failed += daily.Failed
+	}
+	for day, seconds := range other.builds {
+		s.builds[day] = append(s.builds[day], seconds...)
+	}
+
+	s.leadTimes = append(s.leadTimes, other.leadTimes...)
+	s.buildTimes = append(s.buildTimes, other.buildTimes...)
+	s.restores = append(s.restores, other.restores...)
+
+	for key, step := range other.steps {
+		if s.steps[key] == nil {
+			s.steps[key] = &stepSamples{}
+		}
+		s.steps[key].merge(step)
+	}
+}
+
+// Insights computes the insights of the samples
+func (s *InsightsSamples) Insights() *Insights {
+	insights := s.counts
+	insights.Start = s.start
+	insights.End = s.end
+	insights.Frequency = DeploymentFrequency{Daily: []DailyDeployments{}}
+	insights.BuildTrend = []DailyBuilds{}
+	insights.SlowestSteps = []StepStats{}
+
+	window := s.end.Sub(s.start).Hours() / 24
+	if window > 0 {
+		insights.Frequency.PerDay = float64(insights.Succeeded) / window
+		insights.Frequency.PerWeek = insights.Frequency.PerDay * 7
+	}
+	for _, day := range dayBuckets(s.start, s.end) {
+		insights.Frequency.Daily = append(insights.Frequency.Daily, *s.daily[day])
+		if samples := s.builds[day]; len(samples) > 0 {
+			insights.BuildTrend = append(insights.BuildTrend, DailyBuilds{
+				Date:           day,
+				Builds:         len(samples),
+				AverageSeconds: summarize(samples).AverageSeconds,
+			})
+		}
+	}
+
+	if finished := insights.Succeeded + insights.Failed; finished > 0 {
+		insights.ChangeFailureRate = float64(insights.Failed) / float64(finished) * 100
+	}
+
+	insights.LeadTime = summarize(s.leadTimes)
+	insights.BuildDuration = summarize(s.buildTimes)
+	insights.TimeToRestore = summarize(s.restores)
+
+	for key, samples := range s.steps {
+		insights.SlowestSteps = append(insights.SlowestSteps, samples.stats(key[0], key[1]))
+	}
+	slices.SortFunc(insights.SlowestSteps, func(a, b StepStats) int {
+		return cmp.Or(cmp.Compare(b.AverageSeconds, a.AverageSeconds), cmp.Compare(a.Instruction, b.Instruction))
+	})
+	if len(insights.SlowestSteps) > maxSlowestSteps {
+		insights.SlowestSteps = insights.SlowestSteps[:maxSlowestSteps]
+	}
+
+	return &insights
+}
+
+// leadTime of a successful deployment, from its commit when it is known
+func (d *Deployment) leadTime() time.Duration {
+	from := d.startedAt
+	if d.gitCommittedAt != nil && d.gitCommittedAt.Before(from) {
+		from = *d.gitCommittedAt
+	}
+	return d.deployCompletedAt.Sub(from)
+}
+
+// failedAt is when a failed deployment gave up
+func (d *Deployment) failedAt() time.Time {
+	switch {
+	case d.deployCompletedAt != nil:
+		return *d.deployCompletedAt
+	case d.buildCompletedAt != nil:
+		return *d.buildCompletedAt
+	default:
+		return d.updatedAt
+	}
+}
+
+// timesToRestore pairs the failures started in the window with the next
+// successful deployment of their application. The deployments are sorted by
+// when they started.
+func timesToRestore(sorted []*Deployment, start, end time.Time) ([]float64, int) {
+	failing := make(map[string]*Deployment)
+	var restoreTimes []float64
+
+	for _, deployment := range sorted {
+		appID := deployment.ApplicationID().String()
+
+		switch {
+		case deployment.Status() == DeploymentStatusFailed:
+			if failing[appID] == nil && inWindow(deployment.StartedAt(), start, end) {
+				failing[appID] = deployment
+			}
+		case deployment.Succeeded():
+			if failed := failing[appID]; failed != nil {
+				restoreTimes = append(restoreTimes, deployment.DeployCompletedAt().Sub(failed.failedAt()).Seconds())
+				delete(failing, appID)
+			}
+		}
+	}
+
+	return restoreTimes, len(failing)
+}
+
+type stepSamples struct {
+	runs    int
+	cached  int
+	seconds []float64
+}
+
+func (s *stepSamples) add(step logs.BuildStep) {
+	s.runs++
+	if step.Cached {
+		s.cached++
+		return
+	}
+	s.seconds = append(s.seconds, step.DurationSeconds)
+}
+
+func (s *stepSamples) merge(other *stepSamples) {
+	s.runs += other.runs
+	s.cached += other.cached
+	s.seconds = append(s.seconds, other.seconds...)
+}
+
+func (s *stepSamples) stats(stage, instruction string) StepStats {
+	summary := summarize(s.seconds)
+	return StepStats{
+		Stage:          stage,
+		Instruction:    instruction,
+		Runs:           s.runs,
+		Cached:         s.cached,
+		AverageSeconds: summary.AverageSeconds,
+		MaxSeconds:     summary.MaxSeconds,
+	}
+}
+
+func summarize(samples []float64) DurationStats {
+	if len(samples) == 0 {
+		return DurationStats{}
+	}
+
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	sum := 0.0
+	for _, sample := range sorted {
+		sum += sample
+	}
+
+	return DurationStats{
+		Count:          len(sorted),
+		AverageSeconds: sum / float64(len(sorted)),
+		MedianSeconds:  percentile(sorted, 50),
+		P90Seconds:     percentile(sorted, 90),
+		MaxSeconds:     sorted[len(sorted)-1],
+	}
+}
+
+// percentile of sorted samples, nearest rank
+func percentile(sorted []float64, p int) float64 {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+func inWindow(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// dayBuckets lists the UTC dates from start to end
+func dayBuckets(start, end time.Time) []string {
+	var days []string
+	for day := start.UTC().Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		days = append(days, day.Format(time.DateOnly))
+	}
+	return days
+}
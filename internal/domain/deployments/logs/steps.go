@@ -0,0 +1,171 @@
+package logs
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxStepInstructionLength cuts long RUN commands in step names
+const maxStepInstructionLength = 200
+
+var (
+	// BuildKit plain progress, e.g. "#8 [builder 2/5] RUN npm ci",
+	// "#8 DONE 12.3s", "#8 CACHED" and "#8 ERROR: ..."
+	buildkitStepRegex   = regexp.MustCompile(`^#(\d+) \[([^\]]+)\] (.+)$`)
+	buildkitDoneRegex   = regexp.MustCompile(`^#(\d+) DONE (\d+(?:\.\d+)?)s$`)
+	buildkitCachedRegex = regexp.MustCompile(`^#(\d+) CACHED$`)
+	buildkitErrorRegex  = regexp.MustCompile(`^#(\d+) ERROR`)
+	stepCounterRegex    = regexp.MustCompile(`^(?:(.+) )?\d+/\d+$`)
+
+	// Legacy builder, e.g. "Step 2/5 : RUN npm ci" and " ---> Using cache"
+	legacyStepRegex = regexp.MustCompile(`^Step \d+/\d+ : (.+)$`)
+)
+
+// BuildStep is an instruction of an image build and how long it took
+type BuildStep struct {
+	Stage           string  `json:"stage,omitempty"`
+	Instruction     string  `json:"instruction"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Cached          bool    `json:"cached"`
+	Failed          bool    `json:"failed"`
+}
+
+// ParseBuildSteps extracts the Dockerfile instructions of a build from its
+// logs, the structured logs stored once a deployment finished as well as the
+// raw output of a build in progress. BuildKit reports how long a step took,
+// steps of the legacy builder last until the next one starts and are timed
+// from the log timestamps.
+func ParseBuildSteps(buildLogs string) []BuildStep {
+	var steps []BuildStep
+	vertices := make(map[string]int)
+	started := make(map[int]time.Time)
+	legacy := -1
+	var last time.Time
+
+	closeLegacy := func(at time.Time) {
+		if legacy >= 0 && !started[legacy].IsZero() && !at.IsZero() {
+			steps[legacy].DurationSeconds = at.Sub(started[legacy]).Seconds()
+		}
+		legacy = -1
+	}
+
+	for _, line := range buildLogLines(buildLogs) {
+		at := lineTime(line)
+		if !at.IsZero() {
+			last = at
+		}
+		message := cleanMessage(line)
+
+		if matches := buildkitStepRegex.FindStringSubmatch(message); matches != nil {
+			stage, ok := buildStage(matches[2])
+			if !ok {
+				continue
+			}
+			vertices[matches[1]] = len(steps)
+			started[len(steps)] = at
+			steps = append(steps, BuildStep{Stage: stage, Instruction: truncateInstruction(matches[3])})
+			continue
+		}
+
+		if matches := buildkitDoneRegex.FindStringSubmatch(message); matches != nil {
+			if i, ok := vertices[matches[1]]; ok {
+				steps[i].DurationSeconds, _ = strconv.ParseFloat(matches[2], 64)
+				delete(vertices, matches[1])
+			}
+			continue
+		}
+
+		if matches := buildkitCachedRegex.FindStringSubmatch(message); matches != nil {
+			if i, ok := vertices[matches[1]]; ok {
+				steps[i].Cached = true
+				delete(vertices, matches[1])
+			}
+			continue
+		}
+
+		if matches := buildkitErrorRegex.FindStringSubmatch(message); matches != nil {
+			if i, ok := vertices[matches[1]]; ok {
+				steps[i].Failed = true
+				if !started[i].IsZero() && !at.IsZero() {
+					steps[i].DurationSeconds = at.Sub(started[i]).Seconds()
+				}
+				delete(vertices, matches[1])
+			}
+			continue
+		}
+
+		if matches := legacyStepRegex.FindStringSubmatch(message); matches != nil {
+			closeLegacy(at)
+			legacy = len(steps)
+			started[legacy] = at
+			steps = append(steps, BuildStep{Instruction: truncateInstruction(matches[1])})
+			continue
+		}
+
+		if legacy >= 0 {
+			switch {
+			case strings.Contains(message, "---> Using cache"):
+				steps[legacy].Cached = true
+			case strings.HasPrefix(message, "Successfully built"):
+				closeLegacy(at)
+			}
+		}
+	}
+	closeLegacy(last)
+
+	return steps
+}
+
+// buildLogLines returns the raw lines of build logs, whether stored as
+// structured logs or not
+func buildLogLines(buildLogs string) []string {
+	if strings.HasPrefix(strings.TrimSpace(buildLogs), "[") {
+		var structured []StructuredLog
+		if err := json.Unmarshal([]byte(buildLogs), &structured); err == nil {
+			lines := make([]string, 0, len(structured))
+			for _, log := range structured {
+				lines = append(lines, log.Raw)
+			}
+			return lines
+		}
+	}
+
+	return strings.Split(buildLogs, "\n")
+}
+
+// lineTime is the ISO timestamp the container engine prefixed a log line
+// with, the zero time when it has none
+func lineTime(line string) time.Time {
+	match := isoTimestampRegex.FindString(line)
+	if match == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(match))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// buildStage returns the stage of a BuildKit step from its "[stage 2/5]"
+// prefix, internal steps like loading the build definition are not
+// instructions of the Dockerfile
+func buildStage(prefix string) (string, bool) {
+	matches := stepCounterRegex.FindStringSubmatch(prefix)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+func truncateInstruction(instruction string) string {
+	instruction = strings.TrimSpace(instruction)
+	if runes := []rune(instruction); len(runes) > maxStepInstructionLength {
+		return string(runes[:maxStepInstructionLength]) + "…"
+	}
+	return instruction
+}
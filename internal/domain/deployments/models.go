@@ -24,6 +24,7 @@ type Deployment struct {
 	gitCommitMessage      string
 	gitBranch             string
 	gitAuthorName         string
+	gitCommittedAt        *time.Time
 	buildLogs             string
 	deployLogs            string
 	errorMessage          string
@@ -159,6 +160,12 @@ func (d *Deployment) GitAuthorName() string {
 	return d.gitAuthorName
 }
 
+// GitCommittedAt is when the deployed commit was made, nil when the trigger
+// did not report it
+func (d *Deployment) GitCommittedAt() *time.Time {
+	return d.gitCommittedAt
+}
+
 func (d *Deployment) BuildLogs() string {
 	return d.buildLogs
 }
@@ -230,6 +237,17 @@ func (d *Deployment) SetGitInfo(commitHash, commitMessage, branch, authorName st
 	d.updatedAt = time.Now()
 }
 
+func (d *Deployment) SetGitCommitTime(committedAt time.Time) {
+	d.gitCommittedAt = &committedAt
+	d.updatedAt = time.Now()
+}
+
+// Succeeded reports whether the deployment got its container running, stopped
+// deployments were running before they were stopped
+func (d *Deployment) Succeeded() bool {
+	return d.deployCompletedAt != nil && (d.status == DeploymentStatusRunning || d.status == DeploymentStatusStopped)
+}
+
 func (d *Deployment) StartBuild() {
 	now := time.Now()
 	d.status = DeploymentStatusBuilding
@@ -341,6 +359,7 @@ func ReconstructDeployment(
 	status DeploymentStatus,
 	containerID, imageTag, imageDigest string,
 	gitCommitHash, gitCommitMessage, gitBranch, gitAuthorName string,
+	gitCommittedAt *time.Time,
 	buildLogs, deployLogs, errorMessage string,
 	startedAt time.Time,
 	buildStartedAt, buildCompletedAt, deployStartedAt, deployCompletedAt, stoppedAt *time.Time,
@@ -362,6 +381,7 @@ func ReconstructDeployment(
 		gitCommitMessage:      gitCommitMessage,
 		gitBranch:             gitBranch,
 		gitAuthorName:         gitAuthorName,
+		gitCommittedAt:        gitCommittedAt,
 		buildLogs:             buildLogs,
 		deployLogs:            deployLogs,
 		errorMessage:          errorMessage,
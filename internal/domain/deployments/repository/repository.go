@@ -18,6 +18,17 @@ type DeploymentWithMetadata struct {
 	Username   *string
 }
 
+// StatusSummary aggregates the deployments in one status. The counts next to
+// the duration sums only include deployments that got to record a duration.
+type StatusSummary struct {
+	Status        deployments.DeploymentStatus
+	Count         int64
+	BuildSeconds  int64
+	BuildCount    int64
+	DeploySeconds int64
+	DeployCount   int64
+}
+
 type DeploymentRepository interface {
 	Create(ctx context.Context, deployment *deployments.Deployment) error
 	GetByID(ctx context.Context, id deployments.DeploymentID) (*deployments.Deployment, error)
@@ -28,8 +39,10 @@ type DeploymentRepository interface {
 	ListWithMetadata(ctx context.Context) ([]*DeploymentWithMetadata, error)
 	ListByApplication(ctx context.Context, applicationID applications.ApplicationID) ([]*deployments.Deployment, error)
 	ListByApplicationWithMetadata(ctx context.Context, applicationID applications.ApplicationID) ([]*DeploymentWithMetadata, error)
+	ListByApplicationBetween(ctx context.Context, applicationID applications.ApplicationID, start, end time.Time) ([]*deployments.Deployment, error)
 	GetLatestByApplication(ctx context.Context, applicationID applications.ApplicationID) (*deployments.Deployment, error)
 	ListByStatus(ctx context.Context, status deployments.DeploymentStatus) ([]*deployments.Deployment, error)
+	SummarizeByStatus(ctx context.Context) ([]StatusSummary, error)
 }
 
 type sqliteDeploymentRepository struct {
@@ -94,6 +107,7 @@ func (r *sqliteDeploymentRepository) Create(ctx context.Context, deployment *dep
 			sqlite.Arg(deployment.BuildDurationSeconds()),
 			sqlite.Arg(deployment.DeployDurationSeconds()),
 			sqlite.Arg(deployment.UpdatedAt().Format(time.RFC3339)),
+			sqlite.Arg(formatTimePtr(deployment.GitCommittedAt())),
 		),
 	)
 
@@ -114,7 +128,7 @@ func (r *sqliteDeploymentRepository) GetByID(ctx context.Context, id deployments
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -127,7 +141,7 @@ func (r *sqliteDeploymentRepository) GetByID(ctx context.Context, id deployments
 	err := r.db.QueryRowContext(ctx, query, id.String()).Scan(
 		&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 		&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-		&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+		&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 		&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 		&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 		&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -147,7 +161,7 @@ func (r *sqliteDeploymentRepository) GetByIDWithMetadata(ctx context.Context, id
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -160,7 +174,7 @@ func (r *sqliteDeploymentRepository) GetByIDWithMetadata(ctx context.Context, id
 	err := r.db.QueryRowContext(ctx, query, id.String()).Scan(
 		&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 		&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-		&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+		&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 		&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 		&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 		&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -195,7 +209,7 @@ func (r *sqliteDeploymentRepository) Update(ctx context.Context, deployment *dep
 	query := `UPDATE deployments SET 
 		deployment_number = ?, is_production = ?, triggered_by = ?, trigger_type = ?, 
 		status = ?, container_id = ?, image_tag = ?, image_digest = ?,
-		git_commit_hash = ?, git_commit_message = ?, git_branch = ?, git_author_name = ?,
+		git_commit_hash = ?, git_commit_message = ?, git_branch = ?, git_author_name = ?, git_committed_at = ?,
 		build_logs = ?, deploy_logs = ?, error_message = ?, started_at = ?,
 		build_started_at = ?, build_completed_at = ?, deploy_started_at = ?, 
 		deploy_completed_at = ?, stopped_at = ?, build_duration_seconds = ?,
@@ -215,6 +229,7 @@ func (r *sqliteDeploymentRepository) Update(ctx context.Context, deployment *dep
 		deployment.GitCommitMessage(),
 		deployment.GitBranch(),
 		deployment.GitAuthorName(),
+		formatTimePtr(deployment.GitCommittedAt()),
 		deployment.BuildLogs(),
 		deployment.DeployLogs(),
 		deployment.ErrorMessage(),
@@ -249,7 +264,7 @@ func (r *sqliteDeploymentRepository) List(ctx context.Context) ([]*deployments.D
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -270,7 +285,7 @@ func (r *sqliteDeploymentRepository) List(ctx context.Context) ([]*deployments.D
 		err := rows.Scan(
 			&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 			&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 			&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 			&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 			&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -294,7 +309,7 @@ func (r *sqliteDeploymentRepository) ListWithMetadata(ctx context.Context) ([]*D
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -315,7 +330,7 @@ func (r *sqliteDeploymentRepository) ListWithMetadata(ctx context.Context) ([]*D
 		err := rows.Scan(
 			&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 			&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 			&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 			&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 			&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -342,7 +357,7 @@ func (r *sqliteDeploymentRepository) ListByApplication(ctx context.Context, appl
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -364,7 +379,7 @@ func (r *sqliteDeploymentRepository) ListByApplication(ctx context.Context, appl
 		err := rows.Scan(
 			&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 			&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 			&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 			&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 			&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -388,7 +403,7 @@ func (r *sqliteDeploymentRepository) ListByApplicationWithMetadata(ctx context.C
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -410,7 +425,7 @@ func (r *sqliteDeploymentRepository) ListByApplicationWithMetadata(ctx context.C
 		err := rows.Scan(
 			&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 			&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 			&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 			&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 			&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -433,11 +448,77 @@ func (r *sqliteDeploymentRepository) ListByApplicationWithMetadata(ctx context.C
 	return result, nil
 }
 
+// ListByApplicationBetween lists the deployments of an application started
+// between start and end, along with the first successful one started after
+// end, which restores the failures at the end of the window
+func (r *sqliteDeploymentRepository) ListByApplicationBetween(ctx context.Context, applicationID applications.ApplicationID, start, end time.Time) ([]*deployments.Deployment, error) {
+	columns := `d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
+		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
+		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
+		d.build_started_at, d.build_completed_at, d.deploy_started_at,
+		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
+		d.deploy_duration_seconds, d.updated_at, u.username AS triggered_by_username`
+
+	// started_at keeps the offset it was written with, julianday compares the
+	// instants
+	query := `SELECT * FROM (SELECT ` + columns + `
+		FROM deployments d
+		LEFT JOIN users u ON d.triggered_by = u.id
+		WHERE d.application_id = ? AND julianday(d.started_at) >= julianday(?) AND julianday(d.started_at) < julianday(?))
+	UNION ALL
+	SELECT * FROM (SELECT ` + columns + `
+		FROM deployments d
+		LEFT JOIN users u ON d.triggered_by = u.id
+		WHERE d.application_id = ? AND julianday(d.started_at) >= julianday(?)
+			AND d.deploy_completed_at IS NOT NULL AND d.status IN (?, ?)
+		ORDER BY julianday(d.started_at) ASC
+		LIMIT 1)`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		applicationID.String(), start.Format(time.RFC3339), end.Format(time.RFC3339),
+		applicationID.String(), end.Format(time.RFC3339),
+		string(deployments.DeploymentStatusRunning), string(deployments.DeploymentStatusStopped),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments by application: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*deployments.Deployment
+	for rows.Next() {
+		row := deploymentRow{}
+		err := rows.Scan(
+			&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
+			&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
+			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
+			&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
+			&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
+			&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
+			&row.DeployDurationSeconds, &row.UpdatedAt, &row.TriggeredByUsername,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deployment row: %w", err)
+		}
+
+		deployment, err := r.mapRowToDeployment(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, deployment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list deployments by application: %w", err)
+	}
+
+	return result, nil
+}
+
 func (r *sqliteDeploymentRepository) GetLatestByApplication(ctx context.Context, applicationID applications.ApplicationID) (*deployments.Deployment, error) {
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -452,7 +533,7 @@ func (r *sqliteDeploymentRepository) GetLatestByApplication(ctx context.Context,
 	err := r.db.QueryRowContext(ctx, query, applicationID.String()).Scan(
 		&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 		&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-		&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+		&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 		&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 		&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 		&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -472,7 +553,7 @@ func (r *sqliteDeploymentRepository) ListByStatus(ctx context.Context, status de
 	query := `SELECT 
 		d.id, d.application_id, d.deployment_number, d.is_production, d.triggered_by,
 		d.trigger_type, d.status, d.container_id, d.image_tag, d.image_digest,
-		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name,
+		d.git_commit_hash, d.git_commit_message, d.git_branch, d.git_author_name, d.git_committed_at,
 		d.build_logs, d.deploy_logs, d.error_message, d.started_at,
 		d.build_started_at, d.build_completed_at, d.deploy_started_at,
 		d.deploy_completed_at, d.stopped_at, d.build_duration_seconds,
@@ -494,7 +575,7 @@ func (r *sqliteDeploymentRepository) ListByStatus(ctx context.Context, status de
 		err := rows.Scan(
 			&row.ID, &row.ApplicationID, &row.DeploymentNumber, &row.IsProduction, &row.TriggeredBy,
 			&row.TriggerType, &row.Status, &row.ContainerID, &row.ImageTag, &row.ImageDigest,
-			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName,
+			&row.GitCommitHash, &row.GitCommitMessage, &row.GitBranch, &row.GitAuthorName, &row.GitCommittedAt,
 			&row.BuildLogs, &row.DeployLogs, &row.ErrorMessage, &row.StartedAt,
 			&row.BuildStartedAt, &row.BuildCompletedAt, &row.DeployStartedAt,
 			&row.DeployCompletedAt, &row.StoppedAt, &row.BuildDurationSeconds,
@@ -530,6 +611,7 @@ type deploymentRow struct {
 	GitCommitMessage      string
 	GitBranch             string
 	GitAuthorName         string
+	GitCommittedAt        *string
 	BuildLogs             string
 	DeployLogs            string
 	ErrorMessage          string
@@ -574,7 +656,15 @@ func (r *sqliteDeploymentRepository) mapRowToDeployment(row deploymentRow) (*dep
 		return nil, fmt.Errorf("invalid updated at time: %w", err)
 	}
 
-	var buildStartedAt, buildCompletedAt, deployStartedAt, deployCompletedAt, stoppedAt *time.Time
+	var gitCommittedAt, buildStartedAt, buildCompletedAt, deployStartedAt, deployCompletedAt, stoppedAt *time.Time
+
+	if row.GitCommittedAt != nil {
+		t, err := time.Parse(time.RFC3339, *row.GitCommittedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid git committed at time: %w", err)
+		}
+		gitCommittedAt = &t
+	}
 
 	if row.BuildStartedAt != nil {
 		t, err := time.Parse(time.RFC3339, *row.BuildStartedAt)
@@ -631,6 +721,7 @@ func (r *sqliteDeploymentRepository) mapRowToDeployment(row deploymentRow) (*dep
 		row.GitCommitMessage,
 		row.GitBranch,
 		row.GitAuthorName,
+		gitCommittedAt,
 		row.BuildLogs,
 		row.DeployLogs,
 		row.ErrorMessage,
@@ -664,3 +755,30 @@ func formatTimePtr(t *time.Time) *string {
 	formatted := t.Format(time.RFC3339)
 	return &formatted
 }
+
+func (r *sqliteDeploymentRepository) SummarizeByStatus(ctx context.Context) ([]StatusSummary, error) {
+	query := `SELECT status, COUNT(*),
+		COALESCE(SUM(build_duration_seconds), 0), COUNT(build_duration_seconds),
+		COALESCE(SUM(deploy_duration_seconds), 0), COUNT(deploy_duration_seconds)
+	FROM deployments
+	GROUP BY status`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize deployments: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []StatusSummary
+	for rows.Next() {
+		var summary StatusSummary
+		var status string
+		if err := rows.Scan(&status, &summary.Count, &summary.BuildSeconds, &summary.BuildCount, &summary.DeploySeconds, &summary.DeployCount); err != nil {
+			return nil, fmt.Errorf("failed to scan deployment summary: %w", err)
+		}
+		summary.Status = deployments.DeploymentStatus(status)
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments/repository"
+)
+
+type ProjectApplicationLister interface {
+	ListApplicationsByProject(ctx context.Context, projectID uuid.UUID) ([]*applications.Application, error)
+}
+
+// InsightsService computes deployment frequency, lead time, change failure
+// rate, time to restore and build durations from the deployment history
+type InsightsService struct {
+	repo repository.DeploymentRepository
+	apps ProjectApplicationLister
+}
+
+func NewInsightsService(repo repository.DeploymentRepository, apps ProjectApplicationLister) *InsightsService {
+	return &InsightsService{
+		repo: repo,
+		apps: apps,
+	}
+}
+
+// ProjectInsights are the insights of all the applications of a project next
+// to the ones of each application
+type ProjectInsights struct {
+	*deployments.Insights
+	Applications []ApplicationSummary `json:"applications"`
+}
+
+type ApplicationSummary struct {
+	ApplicationID     string                    `json:"application_id"`
+	Name              string                    `json:"name"`
+	Deployments       int                       `json:"deployments"`
+	Succeeded         int                       `json:"succeeded"`
+	Failed            int                       `json:"failed"`
+	Frequency         float64                   `json:"deployments_per_day"`
+	LeadTime          deployments.DurationStats `json:"lead_time"`
+	ChangeFailureRate float64                   `json:"change_failure_rate"`
+	TimeToRestore     deployments.DurationStats `json:"time_to_restore"`
+	BuildDuration     deployments.DurationStats `json:"build_duration"`
+	SlowestStep       *deployments.StepStats    `json:"slowest_step,omitempty"`
+}
+
+func (s *InsightsService) GetApplicationInsights(ctx context.Context, applicationID applications.ApplicationID, start, end time.Time) (*deployments.Insights, error) {
+	list, err := s.repo.ListByApplicationBetween(ctx, applicationID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	return deployments.ComputeInsights(list, start, end), nil
+}
+
+func (s *InsightsService) GetProjectInsights(ctx context.Context, projectID uuid.UUID, start, end time.Time) (*ProjectInsights, error) {
+	apps, err := s.apps.ListApplicationsByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	// The project totals merge the samples of each application, their build
+	// logs are only parsed once
+	total := deployments.CollectInsights(nil, start, end)
+	summaries := make([]ApplicationSummary, 0, len(apps))
+	for _, app := range apps {
+		list, err := s.repo.ListByApplicationBetween(ctx, app.ID(), start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments of %s: %w", app.Name().String(), err)
+		}
+
+		samples := deployments.CollectInsights(list, start, end)
+		total.Merge(samples)
+
+		insights := samples.Insights()
+		summary := ApplicationSummary{
+			ApplicationID:     app.ID().String(),
+			Name:              app.Name().String(),
+			Deployments:       insights.Deployments,
+			Succeeded:         insights.Succeeded,
+			Failed:            insights.Failed,
+			Frequency:         insights.Frequency.PerDay,
+			LeadTime:          insights.LeadTime,
+			ChangeFailureRate: insights.ChangeFailureRate,
+			TimeToRestore:     insights.TimeToRestore,
+			BuildDuration:     insights.BuildDuration,
+		}
+		if len(insights.SlowestSteps) > 0 {
+			summary.SlowestStep = &insights.SlowestSteps[0]
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return &ProjectInsights{
+		Insights:     total.Insights(),
+		Applications: summaries,
+	}, nil
+}
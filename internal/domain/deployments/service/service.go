@@ -3,15 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
 	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
 	"github.com/mikrocloud/mikrocloud/internal/domain/deployments/logs"
 	"github.com/mikrocloud/mikrocloud/internal/domain/deployments/repository"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
 	"github.com/mikrocloud/mikrocloud/internal/domain/users"
 	"github.com/mikrocloud/mikrocloud/pkg/containers"
 	"github.com/mikrocloud/mikrocloud/pkg/containers/build"
 	"github.com/mikrocloud/mikrocloud/pkg/containers/manager"
+	proxyContainers "github.com/mikrocloud/mikrocloud/pkg/containers/proxy"
 	services "github.com/mikrocloud/mikrocloud/pkg/containers/service"
 )
 
@@ -23,15 +27,31 @@ type ApplicationService interface {
 	GetApplication(ctx context.Context, id applications.ApplicationID) (*applications.Application, error)
 }
 
+type MiddlewareProvider interface {
+	ListApplicationMiddlewares(ctx context.Context, applicationID string) ([]*proxy.RouteMiddleware, error)
+	GetApplicationPageSettings(ctx context.Context, applicationID string) (*proxy.ApplicationPageSettings, error)
+	HasApplicationRedirects(ctx context.Context, applicationID string) (bool, error)
+}
+
+// TelemetryProvider configures application containers to export their
+// traces, metrics and logs to mikrocloud
+type TelemetryProvider interface {
+	InjectEnvironment(ctx context.Context, app *applications.Application, deploymentID string, env map[string]string) error
+}
+
 type DeploymentService struct {
-	repo             repository.DeploymentRepository
-	containerService *services.ContainerService
+	repo               repository.DeploymentRepository
+	containerService   *services.ContainerService
+	middlewareProvider MiddlewareProvider
+	telemetryProvider  TelemetryProvider
 }
 
-func NewDeploymentService(repo repository.DeploymentRepository, containerService *services.ContainerService) *DeploymentService {
+func NewDeploymentService(repo repository.DeploymentRepository, containerService *services.ContainerService, middlewareProvider MiddlewareProvider, telemetryProvider TelemetryProvider) *DeploymentService {
 	return &DeploymentService{
-		repo:             repo,
-		containerService: containerService,
+		repo:               repo,
+		containerService:   containerService,
+		middlewareProvider: middlewareProvider,
+		telemetryProvider:  telemetryProvider,
 	}
 }
 
@@ -45,6 +65,7 @@ type CreateDeploymentCommand struct {
 	GitCommitMessage string
 	GitBranch        string
 	GitAuthorName    string
+	GitCommittedAt   *time.Time
 }
 
 func (s *DeploymentService) CreateDeployment(ctx context.Context, cmd CreateDeploymentCommand) (*deployments.Deployment, error) {
@@ -67,6 +88,10 @@ func (s *DeploymentService) CreateDeployment(ctx context.Context, cmd CreateDepl
 		deployment.SetGitInfo(cmd.GitCommitHash, cmd.GitCommitMessage, cmd.GitBranch, cmd.GitAuthorName)
 	}
 
+	if cmd.GitCommittedAt != nil {
+		deployment.SetGitCommitTime(*cmd.GitCommittedAt)
+	}
+
 	if err := s.repo.Create(ctx, deployment); err != nil {
 		return nil, fmt.Errorf("failed to create deployment: %w", err)
 	}
@@ -539,6 +564,16 @@ func (s *DeploymentService) GetLatestDeploymentByApplication(ctx context.Context
 	return deployment, nil
 }
 
+// SummarizeDeployments counts the deployments and sums their build and deploy
+// durations per status
+func (s *DeploymentService) SummarizeDeployments(ctx context.Context) ([]repository.StatusSummary, error) {
+	summaries, err := s.repo.SummarizeByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize deployments: %w", err)
+	}
+	return summaries, nil
+}
+
 func (s *DeploymentService) ListDeploymentsByStatus(ctx context.Context, status deployments.DeploymentStatus) ([]*deployments.Deployment, error) {
 	deployments, err := s.repo.ListByStatus(ctx, status)
 	if err != nil {
@@ -580,13 +615,23 @@ func (s *DeploymentService) deployContainer(ctx context.Context, deploymentID de
 			labels["traefik.http.services."+containerName+".loadbalancer.server.port"] = "8080"
 			s.AppendDeployLogs(ctx, deploymentID, fmt.Sprintf("Configured Traefik routing: %s -> port 8080 (default)", domain))
 		}
+
+		if err := s.addMiddlewareLabels(ctx, labels, containerName, app, domain); err != nil {
+			return fmt.Errorf("failed to configure middlewares: %w", err)
+		}
+	}
+
+	environment := app.EnvVars()
+	if err := s.telemetryProvider.InjectEnvironment(ctx, app, deploymentID.String(), environment); err != nil {
+		// Telemetry is optional, the application still runs without it
+		s.AppendDeployLogs(ctx, deploymentID, fmt.Sprintf("Warning: Failed to configure telemetry export: %v", err))
 	}
 
 	containerConfig := manager.ContainerConfig{
 		Image:         imageTag,
 		Name:          containerName,
 		Ports:         ports,
-		Environment:   app.EnvVars(),
+		Environment:   environment,
 		Networks:      []string{},
 		RestartPolicy: "unless-stopped",
 		AutoRemove:    false,
@@ -623,6 +668,66 @@ func (s *DeploymentService) deployContainer(ctx context.Context, deploymentID de
 	return nil
 }
 
+func (s *DeploymentService) addMiddlewareLabels(ctx context.Context, labels map[string]string, routerName string, app *applications.Application, domain string) error {
+	if s.middlewareProvider == nil {
+		return nil
+	}
+
+	routeMiddlewares, err := s.middlewareProvider.ListApplicationMiddlewares(ctx, app.ID().String())
+	if err != nil {
+		return err
+	}
+
+	var names []string
+
+	// The errors middleware goes first so it also replaces error responses
+	// produced by the other middlewares
+	pageSettings, err := s.middlewareProvider.GetApplicationPageSettings(ctx, app.ID().String())
+	if err != nil {
+		return err
+	}
+	if pageSettings.ErrorPagesEnabled() {
+		name := proxyContainers.MiddlewareName(routerName, "error-pages")
+		for k, v := range proxyContainers.ErrorPagesMiddlewareLabels(name, app.ProjectID(), pageSettings.ErrorStatuses()) {
+			labels[k] = v
+		}
+		names = append(names, name+"@docker")
+	}
+
+	// Redirect rules are compiled into a chain in the file provider config
+	hasRedirects, err := s.middlewareProvider.HasApplicationRedirects(ctx, app.ID().String())
+	if err != nil {
+		return err
+	}
+	if hasRedirects {
+		names = append(names, proxyContainers.RedirectChainName(app.ID().String())+"@file")
+	}
+
+	for _, rm := range routeMiddlewares {
+		if !rm.AppliesTo(domain) {
+			continue
+		}
+
+		mw := rm.Middleware()
+		name := proxyContainers.MiddlewareName(routerName, mw.Name())
+		mwLabels, err := proxyContainers.MiddlewareLabels(name, mw)
+		if err != nil {
+			return fmt.Errorf("middleware %s: %w", mw.Name(), err)
+		}
+
+		for k, v := range mwLabels {
+			labels[k] = v
+		}
+		names = append(names, name+"@docker")
+	}
+
+	if len(names) > 0 {
+		labels["traefik.http.routers."+routerName+".middlewares"] = strings.Join(names, ",")
+	}
+
+	return nil
+}
+
 func (s *DeploymentService) DeleteDeployment(ctx context.Context, id deployments.DeploymentID) error {
 	deployment, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs/service"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+const (
+	// tailKeepAliveInterval keeps idle live tails from being closed by proxies
+	tailKeepAliveInterval = 15 * time.Second
+	maxSearchLimit        = 1000
+)
+
+type LogHandler struct {
+	logService     *service.LogService
+	projectService *projectsService.ProjectService
+}
+
+func NewLogHandler(logService *service.LogService, projectService *projectsService.ProjectService) *LogHandler {
+	return &LogHandler{
+		logService:     logService,
+		projectService: projectService,
+	}
+}
+
+type LogEntryResponse struct {
+	ID           string         `json:"id"`
+	ServiceID    *string        `json:"service_id,omitempty"`
+	DeploymentID *string        `json:"deployment_id,omitempty"`
+	ContainerID  string         `json:"container_id,omitempty"`
+	Level        logs.LogLevel  `json:"level"`
+	Source       logs.LogSource `json:"source"`
+	Message      string         `json:"message"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+	Timestamp    string         `json:"timestamp"`
+}
+
+type SearchLogsResponse struct {
+	Logs []LogEntryResponse `json:"logs"`
+	// NextCursor is passed as cursor to get the next, older page. It is
+	// omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total is the number of entries matching the filters. It is omitted
+	// for regex searches, which are filtered while scanning.
+	Total *int64 `json:"total,omitempty"`
+}
+
+// SearchLogs searches the collected logs of a project, newest first.
+//
+// Filters are given as query parameters: service_id, deployment_id, level,
+// source, from and to (RFC3339), search (case-insensitive text) and regex (RE2
+// syntax, matched against the message). Pages are requested with limit and the
+// cursor returned as next_cursor.
+func (h *LogHandler) SearchLogs(w http.ResponseWriter, r *http.Request) {
+	query, pattern, ok := h.parseLogQuery(w, r)
+	if !ok {
+		return
+	}
+
+	params := r.URL.Query()
+
+	if limit := params.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			utils.SendError(w, http.StatusBadRequest, "invalid_limit", "limit must be between 1 and 1000")
+			return
+		}
+		query.Limit = parsed
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		parsed, err := logs.ParseLogCursor(cursor)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_cursor", "Invalid cursor")
+			return
+		}
+		query.Before = parsed
+	}
+
+	page, err := h.logService.SearchLogs(r.Context(), query, pattern)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "search_failed", "Failed to search logs: "+err.Error())
+		return
+	}
+
+	response := SearchLogsResponse{Logs: make([]LogEntryResponse, 0, len(page.Entries))}
+	for _, entry := range page.Entries {
+		response.Logs = append(response.Logs, toLogEntryResponse(entry))
+	}
+	if page.NextCursor != nil {
+		response.NextCursor = page.NextCursor.Encode()
+	}
+
+	if pattern == nil {
+		total, err := h.logService.CountLogs(r.Context(), query)
+		if err != nil {
+			utils.SendError(w, http.StatusInternalServerError, "search_failed", "Failed to count logs: "+err.Error())
+			return
+		}
+		response.Total = &total
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// TailLogs streams the entries collected from now on that match the same
+// filters as SearchLogs. Clients opening a WebSocket receive one JSON entry
+// per message, other clients get a server-sent event stream.
+func (h *LogHandler) TailLogs(w http.ResponseWriter, r *http.Request) {
+	query, pattern, ok := h.parseLogQuery(w, r)
+	if !ok {
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		h.tailWebSocket(w, r, query, pattern)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.SendError(w, http.StatusInternalServerError, "streaming_not_supported", "Streaming not supported")
+		return
+	}
+
+	entries, cancel := h.logService.TailLogs(query, pattern)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(tailKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(toLogEntryResponse(entry))
+			if err != nil {
+				continue
+			}
+			if _, err := w.Write([]byte("data: " + string(data) + "\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *LogHandler) tailWebSocket(w http.ResponseWriter, r *http.Request, query logs.LogQuery, pattern *regexp.Regexp) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	entries, cancel := h.logService.TailLogs(query, pattern)
+	defer cancel()
+
+	// The client doesn't send anything, reading only notices when it leaves
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(tailKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(toLogEntryResponse(entry)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseLogQuery reads the filters shared by search and tail
+func (h *LogHandler) parseLogQuery(w http.ResponseWriter, r *http.Request) (logs.LogQuery, *regexp.Regexp, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return logs.LogQuery{}, nil, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return logs.LogQuery{}, nil, false
+	}
+
+	params := r.URL.Query()
+	query := logs.LogQuery{
+		ProjectID: projectID,
+		Search:    params.Get("search"),
+	}
+
+	if serviceID := params.Get("service_id"); serviceID != "" {
+		parsed, err := uuid.Parse(serviceID)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_service_id", "Invalid service ID")
+			return logs.LogQuery{}, nil, false
+		}
+		query.ServiceID = &parsed
+	}
+
+	if deploymentID := params.Get("deployment_id"); deploymentID != "" {
+		parsed, err := uuid.Parse(deploymentID)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_deployment_id", "Invalid deployment ID")
+			return logs.LogQuery{}, nil, false
+		}
+		query.DeploymentID = &parsed
+	}
+
+	if level := params.Get("level"); level != "" {
+		parsed := logs.LogLevel(level)
+		if !parsed.IsValid() {
+			utils.SendError(w, http.StatusBadRequest, "invalid_level", "Invalid log level")
+			return logs.LogQuery{}, nil, false
+		}
+		query.Level = &parsed
+	}
+
+	if source := params.Get("source"); source != "" {
+		parsed := logs.LogSource(source)
+		if !parsed.IsValid() {
+			utils.SendError(w, http.StatusBadRequest, "invalid_source", "Invalid log source")
+			return logs.LogQuery{}, nil, false
+		}
+		query.Source = &parsed
+	}
+
+	if from := params.Get("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'from' time, expected RFC3339")
+			return logs.LogQuery{}, nil, false
+		}
+		query.StartTime = &parsed
+	}
+
+	if to := params.Get("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_time_range", "Invalid 'to' time, expected RFC3339")
+			return logs.LogQuery{}, nil, false
+		}
+		query.EndTime = &parsed
+	}
+
+	var pattern *regexp.Regexp
+	if expr := params.Get("regex"); expr != "" {
+		pattern, err = regexp.Compile(expr)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_regex", "Invalid regex: "+err.Error())
+			return logs.LogQuery{}, nil, false
+		}
+	}
+
+	return query, pattern, true
+}
+
+func toLogEntryResponse(entry *logs.LogEntry) LogEntryResponse {
+	response := LogEntryResponse{
+		ID:          entry.ID().String(),
+		ContainerID: entry.ContainerID(),
+		Level:       entry.Level(),
+		Source:      entry.Source(),
+		Message:     entry.Message(),
+		Metadata:    entry.Metadata(),
+		Timestamp:   entry.Timestamp().UTC().Format(time.RFC3339Nano),
+	}
+
+	if entry.ServiceID() != nil {
+		serviceID := entry.ServiceID().String()
+		response.ServiceID = &serviceID
+	}
+
+	if entry.DeploymentID() != nil {
+		deploymentID := entry.DeploymentID().String()
+		response.DeploymentID = &deploymentID
+	}
+
+	return response
+}
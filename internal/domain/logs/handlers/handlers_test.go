@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+	logsService "github.com/mikrocloud/mikrocloud/internal/domain/logs/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/projects"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/users"
+)
+
+// tailTimeout is the router timeout of the tests, the tails outlive it
+const tailTimeout = 100 * time.Millisecond
+
+func TestTailLogsOutlivesRouterTimeout(t *testing.T) {
+	server, logService, projectID := newTailServer(t)
+
+	resp, err := http.Get(server.URL + "/projects/" + projectID.String() + "/logs/tail")
+	if err != nil {
+		t.Fatalf("failed to open the tail: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	time.Sleep(3 * tailTimeout)
+	recordLine(t, logService, projectID, "after the deadline")
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("the tail was closed before the entry arrived")
+			}
+			data, found := strings.CutPrefix(line, "data: ")
+			if !found {
+				continue
+			}
+			var entry LogEntryResponse
+			if err := json.Unmarshal([]byte(data), &entry); err != nil {
+				t.Fatalf("failed to decode %q: %v", data, err)
+			}
+			if entry.Message != "after the deadline" {
+				t.Fatalf("message = %q, want the line recorded after the deadline", entry.Message)
+			}
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("no entry received after the router timeout")
+		}
+	}
+}
+
+func TestTailLogsWebSocketOutlivesRouterTimeout(t *testing.T) {
+	server, logService, projectID := newTailServer(t)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/projects/" + projectID.String() + "/logs/tail"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to open the tail: %v", err)
+	}
+	defer conn.Close()
+
+	time.Sleep(3 * tailTimeout)
+	recordLine(t, logService, projectID, "after the deadline")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var entry LogEntryResponse
+	if err := conn.ReadJSON(&entry); err != nil {
+		t.Fatalf("no entry received after the router timeout: %v", err)
+	}
+	if entry.Message != "after the deadline" {
+		t.Fatalf("message = %q, want the line recorded after the deadline", entry.Message)
+	}
+}
+
+// newTailServer serves the log routes of a project behind a router timeout
+// of tailTimeout
+func newTailServer(t *testing.T) (*httptest.Server, *logsService.LogService, uuid.UUID) {
+	t.Helper()
+
+	name, err := projects.NewProjectName("tail")
+	if err != nil {
+		t.Fatalf("NewProjectName: %v", err)
+	}
+	project := projects.NewProject(name, nil, users.NewUserID(), users.NewOrganizationID())
+
+	logService := logsService.NewLogService(discardLogRepository{})
+	dependencies := &deps.Dependencies{
+		LogService:     logService,
+		ProjectService: projectsService.NewProjectService(projectRepository{project: project}, nil, nil),
+	}
+
+	router := chi.NewRouter()
+	router.Use(middleware.Timeout(tailTimeout))
+	router.Route("/projects/{project_id}", func(r chi.Router) {
+		RegisterLogsRoutes(r, dependencies)
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server, logService, project.ID().UUID()
+}
+
+func recordLine(t *testing.T, logService *logsService.LogService, projectID uuid.UUID, message string) {
+	t.Helper()
+
+	entry := logs.NewContainerLogEntry(projectID, uuid.New(), nil, "container", logs.LogLevelInfo, message, logs.LogSourceContainer, time.Now(), nil)
+	if err := logService.RecordLogs(context.Background(), []*logs.LogEntry{entry}); err != nil {
+		t.Fatalf("RecordLogs: %v", err)
+	}
+}
+
+// projectRepository finds a single project
+type projectRepository struct {
+	project *projects.Project
+}
+
+func (r projectRepository) Save(ctx context.Context, project *projects.Project) error {
+	return nil
+}
+
+func (r projectRepository) FindByID(ctx context.Context, id projects.ProjectID) (*projects.Project, error) {
+	return r.project, nil
+}
+
+func (r projectRepository) FindByName(ctx context.Context, name projects.ProjectName) (*projects.Project, error) {
+	return r.project, nil
+}
+
+func (r projectRepository) FindAll(ctx context.Context, orgID users.OrganizationID) ([]*projects.Project, error) {
+	return []*projects.Project{r.project}, nil
+}
+
+func (r projectRepository) Delete(ctx context.Context, id projects.ProjectID) error {
+	return nil
+}
+
+func (r projectRepository) Exists(ctx context.Context, name projects.ProjectName) (bool, error) {
+	return true, nil
+}
+
+// discardLogRepository stores nothing, the tails get the entries anyway
+type discardLogRepository struct{}
+
+func (discardLogRepository) Create(ctx context.Context, logEntry *logs.LogEntry) error {
+	return nil
+}
+
+func (discardLogRepository) CreateBatch(ctx context.Context, logEntries []*logs.LogEntry) error {
+	return nil
+}
+
+func (discardLogRepository) Query(ctx context.Context, query logs.LogQuery) ([]*logs.LogEntry, error) {
+	return nil, nil
+}
+
+func (discardLogRepository) Count(ctx context.Context, query logs.LogQuery) (int64, error) {
+	return 0, nil
+}
+
+func (discardLogRepository) DeleteOlderThan(ctx context.Context, projectID uuid.UUID, cutoff time.Time) error {
+	return nil
+}
+
+func (discardLogRepository) LatestContainerTimestamp(ctx context.Context, containerID string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (discardLogRepository) ListProjectIDs(ctx context.Context) ([]uuid.UUID, error) {
+	return nil, nil
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
+)
+
+// RegisterLogsRoutes registers the log search and live tail routes of a project
+func RegisterLogsRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewLogHandler(deps.LogService, deps.ProjectService)
+
+	r.Route("/logs", func(r chi.Router) {
+		r.Get("/", handler.SearchLogs)
+		r.With(middleware.NoTimeout()).Get("/tail", handler.TailLogs)
+	})
+}
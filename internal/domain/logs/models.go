@@ -1,7 +1,10 @@
 package logs
 
 import (
+	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +14,16 @@ type LogEntry struct {
 	id        LogEntryID
 	projectID uuid.UUID
 	serviceID *uuid.UUID
-	level     LogLevel
-	message   string
-	timestamp time.Time
-	source    LogSource
-	metadata  map[string]interface{}
-	createdAt time.Time
+	// deploymentID and containerID identify the container a collected line
+	// was written by
+	deploymentID *uuid.UUID
+	containerID  string
+	level        LogLevel
+	message      string
+	timestamp    time.Time
+	source       LogSource
+	metadata     map[string]interface{}
+	createdAt    time.Time
 }
 
 type LogEntryID struct {
@@ -58,18 +65,109 @@ const (
 	LogSourceProxy       LogSource = "proxy"
 	LogSourceBuild       LogSource = "build"
 	LogSourceDeploy      LogSource = "deploy"
+	LogSourceDatabase    LogSource = "database"
+	LogSourceTelemetry   LogSource = "telemetry"
 )
 
+func (l LogLevel) IsValid() bool {
+	switch l {
+	case LogLevelTrace, LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelFatal:
+		return true
+	}
+	return false
+}
+
+func (s LogSource) IsValid() bool {
+	switch s {
+	case LogSourceApplication, LogSourceSystem, LogSourceContainer, LogSourceProxy,
+		LogSourceBuild, LogSourceDeploy, LogSourceDatabase, LogSourceTelemetry:
+		return true
+	}
+	return false
+}
+
 type LogQuery struct {
-	ProjectID uuid.UUID
-	ServiceID *uuid.UUID
-	Level     *LogLevel
-	Source    *LogSource
-	StartTime *time.Time
-	EndTime   *time.Time
-	Search    string
-	Limit     int
-	Offset    int
+	ProjectID    uuid.UUID
+	ServiceID    *uuid.UUID
+	DeploymentID *uuid.UUID
+	Level        *LogLevel
+	Source       *LogSource
+	StartTime    *time.Time
+	EndTime      *time.Time
+	// Search matches messages containing the text, ignoring case
+	Search string
+	// Before only returns entries older than the cursor, for pagination
+	Before *LogCursor
+	Limit  int
+	Offset int
+}
+
+// Matches reports whether an entry satisfies the filters of the query. It is
+// used to filter live entries the same way the repository filters stored ones.
+func (q LogQuery) Matches(entry *LogEntry) bool {
+	if entry.projectID != q.ProjectID {
+		return false
+	}
+	if q.ServiceID != nil && (entry.serviceID == nil || *entry.serviceID != *q.ServiceID) {
+		return false
+	}
+	if q.DeploymentID != nil && (entry.deploymentID == nil || *entry.deploymentID != *q.DeploymentID) {
+		return false
+	}
+	if q.Level != nil && entry.level != *q.Level {
+		return false
+	}
+	if q.Source != nil && entry.source != *q.Source {
+		return false
+	}
+	if q.StartTime != nil && entry.timestamp.Before(*q.StartTime) {
+		return false
+	}
+	if q.EndTime != nil && entry.timestamp.After(*q.EndTime) {
+		return false
+	}
+	if q.Search != "" && !strings.Contains(strings.ToLower(entry.message), strings.ToLower(q.Search)) {
+		return false
+	}
+	return true
+}
+
+// LogCursor is the position of an entry in the newest first order logs are
+// paginated in. The ID breaks ties between entries with the same timestamp.
+type LogCursor struct {
+	Timestamp time.Time
+	ID        string
+}
+
+// CursorOf returns the cursor pointing at an entry
+func CursorOf(entry *LogEntry) *LogCursor {
+	return &LogCursor{Timestamp: entry.timestamp, ID: entry.id.value}
+}
+
+// Encode returns the opaque form of the cursor handed to API clients
+func (c LogCursor) Encode() string {
+	raw := strconv.FormatInt(c.Timestamp.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseLogCursor decodes a cursor returned by Encode
+func ParseLogCursor(s string) (*LogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log cursor")
+	}
+
+	timestamp, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return nil, fmt.Errorf("invalid log cursor")
+	}
+
+	nanos, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log cursor")
+	}
+
+	return &LogCursor{Timestamp: time.Unix(0, nanos), ID: id}, nil
 }
 
 func NewLogEntry(
@@ -94,6 +192,59 @@ func NewLogEntry(
 	}
 }
 
+// NewContainerLogEntry creates a log entry for a line written by an application
+// or database container at the given time
+func NewContainerLogEntry(
+	projectID uuid.UUID,
+	serviceID uuid.UUID,
+	deploymentID *uuid.UUID,
+	containerID string,
+	level LogLevel,
+	message string,
+	source LogSource,
+	timestamp time.Time,
+	metadata map[string]interface{},
+) *LogEntry {
+	return &LogEntry{
+		id:           NewLogEntryID(),
+		projectID:    projectID,
+		serviceID:    &serviceID,
+		deploymentID: deploymentID,
+		containerID:  containerID,
+		level:        level,
+		message:      message,
+		timestamp:    timestamp,
+		source:       source,
+		metadata:     metadata,
+		createdAt:    time.Now(),
+	}
+}
+
+// NewTelemetryLogEntry creates a log entry for a record exported over OTLP,
+// serviceID and deploymentID are nil when the resource does not name them
+func NewTelemetryLogEntry(
+	projectID uuid.UUID,
+	serviceID *uuid.UUID,
+	deploymentID *uuid.UUID,
+	level LogLevel,
+	message string,
+	timestamp time.Time,
+	metadata map[string]interface{},
+) *LogEntry {
+	return &LogEntry{
+		id:           NewLogEntryID(),
+		projectID:    projectID,
+		serviceID:    serviceID,
+		deploymentID: deploymentID,
+		level:        level,
+		message:      message,
+		timestamp:    timestamp,
+		source:       LogSourceTelemetry,
+		metadata:     metadata,
+		createdAt:    time.Now(),
+	}
+}
+
 func (l *LogEntry) ID() LogEntryID {
 	return l.id
 }
@@ -106,6 +257,14 @@ func (l *LogEntry) ServiceID() *uuid.UUID {
 	return l.serviceID
 }
 
+func (l *LogEntry) DeploymentID() *uuid.UUID {
+	return l.deploymentID
+}
+
+func (l *LogEntry) ContainerID() string {
+	return l.containerID
+}
+
 func (l *LogEntry) Level() LogLevel {
 	return l.level
 }
@@ -134,6 +293,8 @@ func ReconstructLogEntry(
 	id LogEntryID,
 	projectID uuid.UUID,
 	serviceID *uuid.UUID,
+	deploymentID *uuid.UUID,
+	containerID string,
 	level LogLevel,
 	message string,
 	timestamp time.Time,
@@ -142,14 +303,16 @@ func ReconstructLogEntry(
 	createdAt time.Time,
 ) *LogEntry {
 	return &LogEntry{
-		id:        id,
-		projectID: projectID,
-		serviceID: serviceID,
-		level:     level,
-		message:   message,
-		timestamp: timestamp,
-		source:    source,
-		metadata:  metadata,
-		createdAt: createdAt,
+		id:           id,
+		projectID:    projectID,
+		serviceID:    serviceID,
+		deploymentID: deploymentID,
+		containerID:  containerID,
+		level:        level,
+		message:      message,
+		timestamp:    timestamp,
+		source:       source,
+		metadata:     metadata,
+		createdAt:    createdAt,
 	}
 }
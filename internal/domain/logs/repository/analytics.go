@@ -24,95 +24,89 @@ func NewAnalyticsLogRepository(db *sql.DB) *AnalyticsLogRepository {
 
 // Create inserts a new log entry into the analytics database
 func (r *AnalyticsLogRepository) Create(ctx context.Context, logEntry *logs.LogEntry) error {
-	// Convert metadata to JSON
-	metadataJSON, err := json.Marshal(logEntry.Metadata())
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+	if err := r.CreateBatch(ctx, []*logs.LogEntry{logEntry}); err != nil {
+		return fmt.Errorf("failed to create log entry: %w", err)
 	}
 
-	serviceIDStr := ""
-	if logEntry.ServiceID() != nil {
-		serviceIDStr = logEntry.ServiceID().String()
-	}
+	return nil
+}
 
-	query := `
-		INSERT INTO logs (id, project_id, service_id, level, message, source, fields, tags, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
+// CreateBatch inserts several log entries with a single statement.
+// Timestamps are stored in nanoseconds so lines written in the same
+// millisecond keep their order.
+func (r *AnalyticsLogRepository) CreateBatch(ctx context.Context, logEntries []*logs.LogEntry) error {
+	if len(logEntries) == 0 {
+		return nil
+	}
 
 	// For the analytics database, we'll use fields for metadata and empty tags for now
 	emptyTags := "{}"
 
-	_, err = r.db.ExecContext(
-		ctx,
-		query,
-		logEntry.ID().String(),
-		logEntry.ProjectID().String(),
-		serviceIDStr,
-		string(logEntry.Level()),
-		logEntry.Message(),
-		string(logEntry.Source()),
-		string(metadataJSON),
-		emptyTags,
-		logEntry.Timestamp().Unix(),
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to create log entry: %w", err)
-	}
-
-	return nil
-}
-
-// Query retrieves log entries based on the provided criteria
-func (r *AnalyticsLogRepository) Query(ctx context.Context, query logs.LogQuery) ([]*logs.LogEntry, error) {
-	var args []interface{}
-	var conditions []string
+	values := make([]string, 0, len(logEntries))
+	args := make([]interface{}, 0, len(logEntries)*11)
+	for _, logEntry := range logEntries {
+		metadataJSON, err := json.Marshal(logEntry.Metadata())
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
 
-	sql := `SELECT id, project_id, service_id, level, message, source, fields, timestamp 
-			FROM logs`
+		serviceIDStr := ""
+		if logEntry.ServiceID() != nil {
+			serviceIDStr = logEntry.ServiceID().String()
+		}
 
-	// Add WHERE conditions
-	conditions = append(conditions, "project_id = ?")
-	args = append(args, query.ProjectID.String())
+		deploymentIDStr := ""
+		if logEntry.DeploymentID() != nil {
+			deploymentIDStr = logEntry.DeploymentID().String()
+		}
 
-	if query.ServiceID != nil {
-		conditions = append(conditions, "service_id = ?")
-		args = append(args, query.ServiceID.String())
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			logEntry.ID().String(),
+			logEntry.ProjectID().String(),
+			serviceIDStr,
+			deploymentIDStr,
+			logEntry.ContainerID(),
+			string(logEntry.Level()),
+			logEntry.Message(),
+			string(logEntry.Source()),
+			string(metadataJSON),
+			emptyTags,
+			logEntry.Timestamp().UnixNano(),
+		)
 	}
 
-	if query.Level != nil {
-		conditions = append(conditions, "level = ?")
-		args = append(args, string(*query.Level))
-	}
+	query := `INSERT INTO logs (id, project_id, service_id, deployment_id, container_id, level, message, source, fields, tags, timestamp)
+		VALUES ` + strings.Join(values, ", ")
 
-	if query.Source != nil {
-		conditions = append(conditions, "source = ?")
-		args = append(args, string(*query.Source))
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert log entries: %w", err)
 	}
 
-	if query.StartTime != nil {
-		conditions = append(conditions, "timestamp >= ?")
-		args = append(args, query.StartTime.Unix())
-	}
+	return nil
+}
 
-	if query.EndTime != nil {
-		conditions = append(conditions, "timestamp <= ?")
-		args = append(args, query.EndTime.Unix())
-	}
+// Query retrieves log entries based on the provided criteria, newest first
+func (r *AnalyticsLogRepository) Query(ctx context.Context, query logs.LogQuery) ([]*logs.LogEntry, error) {
+	conditions, args := logConditions(query)
 
-	if query.Search != "" {
-		conditions = append(conditions, "message LIKE ?")
-		args = append(args, "%"+query.Search+"%")
+	if query.Before != nil {
+		conditions = append(conditions, "(timestamp < ? OR (timestamp = ? AND id < ?))")
+		before := query.Before.Timestamp.UnixNano()
+		args = append(args, before, before, query.Before.ID)
 	}
 
+	sql := `SELECT id, project_id, service_id, deployment_id, container_id, level, message, source, fields, timestamp
+			FROM logs`
+
 	// Add WHERE clause if conditions exist
 	if len(conditions) > 0 {
 		sql += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	// Add ORDER BY
-	sql += " ORDER BY timestamp DESC"
+	// Add ORDER BY, the ID keeps the order of entries with the same timestamp
+	// stable across pages
+	sql += " ORDER BY timestamp DESC, id DESC"
 
 	// Add LIMIT and OFFSET
 	if query.Limit > 0 {
@@ -144,22 +138,43 @@ func (r *AnalyticsLogRepository) Query(ctx context.Context, query logs.LogQuery)
 	return logEntries, nil
 }
 
-// Count returns the number of log entries matching the query
+// Count returns the number of log entries matching the query. The cursor is
+// ignored so the count stays the same across pages.
 func (r *AnalyticsLogRepository) Count(ctx context.Context, query logs.LogQuery) (int64, error) {
-	var args []interface{}
-	var conditions []string
+	conditions, args := logConditions(query)
 
 	sql := "SELECT COUNT(*) FROM logs"
+	if len(conditions) > 0 {
+		sql += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count logs: %w", err)
+	}
+
+	return count, nil
+}
+
+// likeEscaper escapes the LIKE wildcards so searches match them literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
 
-	// Add WHERE conditions (same logic as Query)
-	conditions = append(conditions, "project_id = ?")
-	args = append(args, query.ProjectID.String())
+// logConditions builds the WHERE conditions shared by Query and Count
+func logConditions(query logs.LogQuery) ([]string, []interface{}) {
+	conditions := []string{"project_id = ?"}
+	args := []interface{}{query.ProjectID.String()}
 
 	if query.ServiceID != nil {
 		conditions = append(conditions, "service_id = ?")
 		args = append(args, query.ServiceID.String())
 	}
 
+	if query.DeploymentID != nil {
+		conditions = append(conditions, "deployment_id = ?")
+		args = append(args, query.DeploymentID.String())
+	}
+
 	if query.Level != nil {
 		conditions = append(conditions, "level = ?")
 		args = append(args, string(*query.Level))
@@ -172,37 +187,28 @@ func (r *AnalyticsLogRepository) Count(ctx context.Context, query logs.LogQuery)
 
 	if query.StartTime != nil {
 		conditions = append(conditions, "timestamp >= ?")
-		args = append(args, query.StartTime.Unix())
+		args = append(args, query.StartTime.UnixNano())
 	}
 
 	if query.EndTime != nil {
 		conditions = append(conditions, "timestamp <= ?")
-		args = append(args, query.EndTime.Unix())
+		args = append(args, query.EndTime.UnixNano())
 	}
 
 	if query.Search != "" {
-		conditions = append(conditions, "message LIKE ?")
-		args = append(args, "%"+query.Search+"%")
-	}
-
-	if len(conditions) > 0 {
-		sql += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	var count int64
-	err := r.db.QueryRowContext(ctx, sql, args...).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count logs: %w", err)
+		// LOWER instead of ILIKE so the query runs on SQLite and DuckDB
+		conditions = append(conditions, `LOWER(message) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(strings.ToLower(query.Search))+"%")
 	}
 
-	return count, nil
+	return conditions, args
 }
 
 // DeleteOlderThan removes log entries older than the specified cutoff time
 func (r *AnalyticsLogRepository) DeleteOlderThan(ctx context.Context, projectID uuid.UUID, cutoff time.Time) error {
 	query := "DELETE FROM logs WHERE project_id = ? AND timestamp < ?"
 
-	result, err := r.db.ExecContext(ctx, query, projectID.String(), cutoff.Unix())
+	result, err := r.db.ExecContext(ctx, query, projectID.String(), cutoff.UnixNano())
 	if err != nil {
 		return fmt.Errorf("failed to delete old logs: %w", err)
 	}
@@ -216,15 +222,60 @@ func (r *AnalyticsLogRepository) DeleteOlderThan(ctx context.Context, projectID
 	return nil
 }
 
+// LatestContainerTimestamp returns the time of the last line stored for a
+// container, or the zero time when none was stored yet
+func (r *AnalyticsLogRepository) LatestContainerTimestamp(ctx context.Context, containerID string) (time.Time, error) {
+	var latest sql.NullInt64
+	err := r.db.QueryRowContext(ctx, "SELECT MAX(timestamp) FROM logs WHERE container_id = ?", containerID).Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get latest container log: %w", err)
+	}
+
+	if !latest.Valid {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(0, latest.Int64), nil
+}
+
+// ListProjectIDs returns the projects that have stored log entries
+func (r *AnalyticsLogRepository) ListProjectIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT DISTINCT project_id FROM logs WHERE project_id != ''")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projectIDs []uuid.UUID
+	for rows.Next() {
+		var projectIDStr string
+		if err := rows.Scan(&projectIDStr); err != nil {
+			return nil, fmt.Errorf("failed to scan project ID: %w", err)
+		}
+		projectID, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			continue
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return projectIDs, nil
+}
+
 // scanLogEntry scans a row into a LogEntry object
 func (r *AnalyticsLogRepository) scanLogEntry(rows *sql.Rows) (*logs.LogEntry, error) {
 	var (
-		id, projectIDStr, serviceIDStr, levelStr, message, sourceStr, fieldsJSON string
-		timestamp                                                                int64
+		id, projectIDStr, serviceIDStr, deploymentIDStr, containerID string
+		levelStr, message, sourceStr, fieldsJSON                     string
+		timestamp                                                    int64
 	)
 
 	err := rows.Scan(
-		&id, &projectIDStr, &serviceIDStr, &levelStr,
+		&id, &projectIDStr, &serviceIDStr, &deploymentIDStr, &containerID, &levelStr,
 		&message, &sourceStr, &fieldsJSON, &timestamp,
 	)
 	if err != nil {
@@ -246,6 +297,15 @@ func (r *AnalyticsLogRepository) scanLogEntry(rows *sql.Rows) (*logs.LogEntry, e
 		serviceID = &parsed
 	}
 
+	var deploymentID *uuid.UUID
+	if deploymentIDStr != "" {
+		parsed, err := uuid.Parse(deploymentIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment ID: %w", err)
+		}
+		deploymentID = &parsed
+	}
+
 	// Parse metadata
 	var metadata map[string]interface{}
 	if err := json.Unmarshal([]byte(fieldsJSON), &metadata); err != nil {
@@ -258,13 +318,15 @@ func (r *AnalyticsLogRepository) scanLogEntry(rows *sql.Rows) (*logs.LogEntry, e
 		return nil, fmt.Errorf("invalid log entry ID: %w", err)
 	}
 
-	timestampTime := time.Unix(timestamp, 0)
+	timestampTime := time.Unix(0, timestamp)
 
 	// Reconstruct the log entry
 	return logs.ReconstructLogEntry(
 		logEntryID,
 		projectID,
 		serviceID,
+		deploymentID,
+		containerID,
 		logs.LogLevel(levelStr),
 		message,
 		timestampTime,
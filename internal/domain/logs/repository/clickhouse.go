@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+)
+
+// ClickHouseLogRepository implements LogRepository using a ClickHouse
+// analytics database. Timestamps are DateTime64(9) so, as with the SQL
+// databases, lines written in the same millisecond keep their order.
+type ClickHouseLogRepository struct {
+	db *sql.DB
+}
+
+// NewClickHouseLogRepository creates a new ClickHouse-based log repository
+func NewClickHouseLogRepository(db *sql.DB) *ClickHouseLogRepository {
+	return &ClickHouseLogRepository{db: db}
+}
+
+// Create inserts a new log entry into the analytics database
+func (r *ClickHouseLogRepository) Create(ctx context.Context, logEntry *logs.LogEntry) error {
+	if err := r.CreateBatch(ctx, []*logs.LogEntry{logEntry}); err != nil {
+		return fmt.Errorf("failed to create log entry: %w", err)
+	}
+
+	return nil
+}
+
+// CreateBatch inserts several log entries as one insert block
+func (r *ClickHouseLogRepository) CreateBatch(ctx context.Context, logEntries []*logs.LogEntry) error {
+	if len(logEntries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO logs (id, project_id, service_id, deployment_id, container_id, level, message, source, fields, timestamp)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, logEntry := range logEntries {
+		metadataJSON, err := json.Marshal(logEntry.Metadata())
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+
+		serviceIDStr := ""
+		if logEntry.ServiceID() != nil {
+			serviceIDStr = logEntry.ServiceID().String()
+		}
+
+		deploymentIDStr := ""
+		if logEntry.DeploymentID() != nil {
+			deploymentIDStr = logEntry.DeploymentID().String()
+		}
+
+		_, err = stmt.ExecContext(ctx,
+			logEntry.ID().String(),
+			logEntry.ProjectID().String(),
+			serviceIDStr,
+			deploymentIDStr,
+			logEntry.ContainerID(),
+			string(logEntry.Level()),
+			logEntry.Message(),
+			string(logEntry.Source()),
+			string(metadataJSON),
+			logEntry.Timestamp(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append log entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to insert log entries: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves log entries based on the provided criteria, newest first
+func (r *ClickHouseLogRepository) Query(ctx context.Context, query logs.LogQuery) ([]*logs.LogEntry, error) {
+	conditions, args := clickHouseLogConditions(query)
+
+	if query.Before != nil {
+		conditions = append(conditions, "(timestamp < "+nanoTimestamp+" OR (timestamp = "+nanoTimestamp+" AND id < ?))")
+		before := query.Before.Timestamp.UnixNano()
+		args = append(args, before, before, query.Before.ID)
+	}
+
+	sql := `SELECT id, project_id, service_id, deployment_id, container_id, level, message, source, fields, timestamp
+		FROM logs WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY timestamp DESC, id DESC`
+
+	if query.Limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+	if query.Offset > 0 {
+		sql += fmt.Sprintf(" OFFSET %d", query.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logEntries []*logs.LogEntry
+	for rows.Next() {
+		logEntry, err := r.scanLogEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %w", err)
+		}
+		logEntries = append(logEntries, logEntry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return logEntries, nil
+}
+
+// Count returns the number of log entries matching the query. The cursor is
+// ignored so the count stays the same across pages.
+func (r *ClickHouseLogRepository) Count(ctx context.Context, query logs.LogQuery) (int64, error) {
+	conditions, args := clickHouseLogConditions(query)
+
+	var count uint64
+	err := r.db.QueryRowContext(ctx, "SELECT count() FROM logs WHERE "+strings.Join(conditions, " AND "), args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count logs: %w", err)
+	}
+
+	return int64(count), nil
+}
+
+// nanoTimestamp converts a bound unix nanosecond timestamp to DateTime64(9),
+// bound time.Time values are truncated to seconds by the driver
+const nanoTimestamp = "fromUnixTimestamp64Nano(toInt64(?))"
+
+// clickHouseLogConditions builds the WHERE conditions shared by Query and Count
+func clickHouseLogConditions(query logs.LogQuery) ([]string, []interface{}) {
+	conditions := []string{"project_id = ?"}
+	args := []interface{}{query.ProjectID.String()}
+
+	if query.ServiceID != nil {
+		conditions = append(conditions, "service_id = ?")
+		args = append(args, query.ServiceID.String())
+	}
+
+	if query.DeploymentID != nil {
+		conditions = append(conditions, "deployment_id = ?")
+		args = append(args, query.DeploymentID.String())
+	}
+
+	if query.Level != nil {
+		conditions = append(conditions, "level = ?")
+		args = append(args, string(*query.Level))
+	}
+
+	if query.Source != nil {
+		conditions = append(conditions, "source = ?")
+		args = append(args, string(*query.Source))
+	}
+
+	if query.StartTime != nil {
+		conditions = append(conditions, "timestamp >= "+nanoTimestamp)
+		args = append(args, query.StartTime.UnixNano())
+	}
+
+	if query.EndTime != nil {
+		conditions = append(conditions, "timestamp <= "+nanoTimestamp)
+		args = append(args, query.EndTime.UnixNano())
+	}
+
+	if query.Search != "" {
+		// A substring search, so no wildcards need escaping
+		conditions = append(conditions, "positionCaseInsensitiveUTF8(message, ?) > 0")
+		args = append(args, query.Search)
+	}
+
+	return conditions, args
+}
+
+// DeleteOlderThan removes log entries older than the specified cutoff time
+func (r *ClickHouseLogRepository) DeleteOlderThan(ctx context.Context, projectID uuid.UUID, cutoff time.Time) error {
+	query := "DELETE FROM logs WHERE project_id = ? AND timestamp < " + nanoTimestamp
+
+	if _, err := r.db.ExecContext(ctx, query, projectID.String(), cutoff.UnixNano()); err != nil {
+		return fmt.Errorf("failed to delete old logs: %w", err)
+	}
+
+	return nil
+}
+
+// LatestContainerTimestamp returns the time of the last line stored for a
+// container, or the zero time when none was stored yet
+func (r *ClickHouseLogRepository) LatestContainerTimestamp(ctx context.Context, containerID string) (time.Time, error) {
+	// max() of no rows is the epoch rather than NULL, the count tells them apart
+	var count uint64
+	var latest int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT count(), toUnixTimestamp64Nano(max(timestamp)) FROM logs WHERE container_id = ?", containerID,
+	).Scan(&count, &latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get latest container log: %w", err)
+	}
+
+	if count == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(0, latest), nil
+}
+
+// ListProjectIDs returns the projects that have stored log entries
+func (r *ClickHouseLogRepository) ListProjectIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT DISTINCT project_id FROM logs WHERE project_id != ''")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projectIDs []uuid.UUID
+	for rows.Next() {
+		var projectIDStr string
+		if err := rows.Scan(&projectIDStr); err != nil {
+			return nil, fmt.Errorf("failed to scan project ID: %w", err)
+		}
+		projectID, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			continue
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return projectIDs, nil
+}
+
+// scanLogEntry scans a row into a LogEntry object
+func (r *ClickHouseLogRepository) scanLogEntry(rows *sql.Rows) (*logs.LogEntry, error) {
+	var (
+		id, projectIDStr, serviceIDStr, deploymentIDStr, containerID string
+		levelStr, message, sourceStr, fieldsJSON                     string
+		timestamp                                                    time.Time
+	)
+
+	err := rows.Scan(
+		&id, &projectIDStr, &serviceIDStr, &deploymentIDStr, &containerID, &levelStr,
+		&message, &sourceStr, &fieldsJSON, &timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	var serviceID *uuid.UUID
+	if serviceIDStr != "" {
+		parsed, err := uuid.Parse(serviceIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service ID: %w", err)
+		}
+		serviceID = &parsed
+	}
+
+	var deploymentID *uuid.UUID
+	if deploymentIDStr != "" {
+		parsed, err := uuid.Parse(deploymentIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment ID: %w", err)
+		}
+		deploymentID = &parsed
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(fieldsJSON), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	logEntryID, err := logs.LogEntryIDFromString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log entry ID: %w", err)
+	}
+
+	return logs.ReconstructLogEntry(
+		logEntryID,
+		projectID,
+		serviceID,
+		deploymentID,
+		containerID,
+		logs.LogLevel(levelStr),
+		message,
+		timestamp,
+		logs.LogSource(sourceStr),
+		metadata,
+		timestamp,
+	), nil
+}
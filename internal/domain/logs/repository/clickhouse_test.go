@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/database/analytics/clickhousetest"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+)
+
+func TestClickHouseLogRepository(t *testing.T) {
+	repo := NewClickHouseLogRepository(clickhousetest.Open(t))
+	ctx := context.Background()
+
+	projectID, serviceID, deploymentID := uuid.New(), uuid.New(), uuid.New()
+	otherProjectID := uuid.New()
+
+	// Lines a microsecond apart, ClickHouse must keep their order
+	start := time.Now().UTC().Truncate(time.Second)
+	entries := []*logs.LogEntry{
+		logs.NewContainerLogEntry(projectID, serviceID, &deploymentID, "container-1", logs.LogLevelInfo, "Server listening on :8080", logs.LogSourceContainer, start, map[string]interface{}{"stream": "stdout"}),
+		logs.NewContainerLogEntry(projectID, serviceID, &deploymentID, "container-1", logs.LogLevelError, "connection REFUSED by database", logs.LogSourceContainer, start.Add(time.Microsecond), nil),
+		logs.NewContainerLogEntry(projectID, serviceID, &deploymentID, "container-1", logs.LogLevelError, "retrying connection", logs.LogSourceContainer, start.Add(2*time.Microsecond), nil),
+		logs.NewContainerLogEntry(projectID, serviceID, nil, "container-2", logs.LogLevelInfo, "connection restored", logs.LogSourceContainer, start.Add(3*time.Microsecond), nil),
+		logs.NewContainerLogEntry(otherProjectID, uuid.New(), nil, "container-3", logs.LogLevelInfo, "connection of another project", logs.LogSourceContainer, start, nil),
+	}
+	if err := repo.CreateBatch(ctx, entries); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	all, err := repo.Query(ctx, logs.LogQuery{ProjectID: projectID})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got, want := ids(all), ids([]*logs.LogEntry{entries[3], entries[2], entries[1], entries[0]}); !slices.Equal(got, want) {
+		t.Fatalf("Query = %v, want %v, newest first", got, want)
+	}
+	if oldest := all[3]; !oldest.Timestamp().Equal(start) || oldest.Metadata()["stream"] != "stdout" || *oldest.DeploymentID() != deploymentID {
+		t.Errorf("oldest entry = %+v, want the first one written", oldest)
+	}
+
+	// The search ignores case
+	found, err := repo.Query(ctx, logs.LogQuery{ProjectID: projectID, Search: "connection refused"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := ids(found); !slices.Equal(got, []string{entries[1].ID().String()}) {
+		t.Errorf("search = %v, want only %s", got, entries[1].ID())
+	}
+
+	errorLevel := logs.LogLevelError
+	errorsQuery := logs.LogQuery{ProjectID: projectID, DeploymentID: &deploymentID, Level: &errorLevel, Search: "connection"}
+	count, err := repo.Count(ctx, errorsQuery)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Count = %d, want 2", count)
+	}
+
+	// Pages follow the cursor of the last entry, down to the microsecond
+	end := start.Add(2 * time.Microsecond)
+	page, err := repo.Query(ctx, logs.LogQuery{ProjectID: projectID, EndTime: &end, Before: logs.CursorOf(entries[2]), Limit: 1})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := ids(page); !slices.Equal(got, []string{entries[1].ID().String()}) {
+		t.Errorf("page before %s = %v, want %s", entries[2].ID(), got, entries[1].ID())
+	}
+
+	latest, err := repo.LatestContainerTimestamp(ctx, "container-1")
+	if err != nil {
+		t.Fatalf("LatestContainerTimestamp: %v", err)
+	}
+	if !latest.Equal(start.Add(2 * time.Microsecond)) {
+		t.Errorf("LatestContainerTimestamp = %s, want %s", latest, start.Add(2*time.Microsecond))
+	}
+
+	none, err := repo.LatestContainerTimestamp(ctx, "unknown")
+	if err != nil {
+		t.Fatalf("LatestContainerTimestamp: %v", err)
+	}
+	if !none.IsZero() {
+		t.Errorf("LatestContainerTimestamp of a container without logs = %s, want zero", none)
+	}
+
+	projectIDs, err := repo.ListProjectIDs(ctx)
+	if err != nil {
+		t.Fatalf("ListProjectIDs: %v", err)
+	}
+	if len(projectIDs) != 2 || !slices.Contains(projectIDs, projectID) || !slices.Contains(projectIDs, otherProjectID) {
+		t.Errorf("ListProjectIDs = %v, want %s and %s", projectIDs, projectID, otherProjectID)
+	}
+
+	if err := repo.DeleteOlderThan(ctx, projectID, start.Add(2*time.Microsecond)); err != nil {
+		t.Fatalf("DeleteOlderThan: %v", err)
+	}
+	count, err = repo.Count(ctx, logs.LogQuery{ProjectID: projectID})
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Count after DeleteOlderThan = %d, want 2", count)
+	}
+}
+
+func ids(entries []*logs.LogEntry) []string {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.ID().String())
+	}
+	return result
+}
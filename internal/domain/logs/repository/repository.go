@@ -10,7 +10,10 @@ import (
 
 type LogRepository interface {
 	Create(ctx context.Context, logEntry *logs.LogEntry) error
+	CreateBatch(ctx context.Context, logEntries []*logs.LogEntry) error
 	Query(ctx context.Context, query logs.LogQuery) ([]*logs.LogEntry, error)
 	Count(ctx context.Context, query logs.LogQuery) (int64, error)
 	DeleteOlderThan(ctx context.Context, projectID uuid.UUID, cutoff time.Time) error
+	LatestContainerTimestamp(ctx context.Context, containerID string) (time.Time, error)
+	ListProjectIDs(ctx context.Context) ([]uuid.UUID, error)
 }
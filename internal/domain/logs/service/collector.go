@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+	deploymentLogs "github.com/mikrocloud/mikrocloud/internal/domain/deployments/logs"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+	"github.com/mikrocloud/mikrocloud/internal/domain/projects"
+)
+
+const (
+	containerLogBatchSize = 200
+	// containerLogFlushInterval is short as live tails only see entries once
+	// they are recorded
+	containerLogFlushInterval   = time.Second
+	containerLogRefreshInterval = 30 * time.Second
+	containerLogCleanupInterval = time.Hour
+	// containerLogShutdownTimeout bounds recording the last batch once the
+	// collector's context is cancelled
+	containerLogShutdownTimeout = 5 * time.Second
+)
+
+// ContainerLogSource streams the output of a container
+type ContainerLogSource interface {
+	StreamContainerLogs(ctx context.Context, containerID string, follow bool) (io.ReadCloser, error)
+}
+
+// DeploymentLister lists the deployments whose containers are followed
+type DeploymentLister interface {
+	ListDeploymentsByStatus(ctx context.Context, status deployments.DeploymentStatus) ([]*deployments.Deployment, error)
+}
+
+// ApplicationLister lists the applications deployments belong to
+type ApplicationLister interface {
+	ListApplications(ctx context.Context) ([]*applications.Application, error)
+}
+
+// DatabaseLister lists the databases whose containers are followed
+type DatabaseLister interface {
+	ListDatabasesWithContainers(ctx context.Context) ([]*databases.Database, error)
+}
+
+// ProjectLookup resolves the log retention of a project
+type ProjectLookup interface {
+	GetProject(ctx context.Context, id string) (*projects.Project, error)
+}
+
+// logTarget is a managed container and what its lines are attributed to
+type logTarget struct {
+	containerID  string
+	projectID    uuid.UUID
+	serviceID    uuid.UUID
+	deploymentID *uuid.UUID
+	source       logs.LogSource
+	metadata     map[string]interface{}
+}
+
+// containerFollower tracks how far the output of a container was collected.
+// lastSeen and loaded are only touched by the goroutine following the
+// container, the collector only changes target while it isn't active.
+type containerFollower struct {
+	target   logTarget
+	lastSeen time.Time
+	loaded   bool
+	active   bool
+}
+
+// ContainerLogCollector follows the output of every running application and
+// database container and stores it in the analytics database, so logs outlive
+// the containers that wrote them
+type ContainerLogCollector struct {
+	logs        *LogService
+	containers  ContainerLogSource
+	deployments DeploymentLister
+	apps        ApplicationLister
+	databases   DatabaseLister
+	projects    ProjectLookup
+
+	entries     chan *logs.LogEntry
+	mu          sync.Mutex
+	followers   map[string]*containerFollower
+	lastCleanup time.Time
+	stopCh      chan struct{}
+}
+
+func NewContainerLogCollector(
+	logService *LogService,
+	containers ContainerLogSource,
+	deploymentLister DeploymentLister,
+	appLister ApplicationLister,
+	databaseLister DatabaseLister,
+	projectLookup ProjectLookup,
+) *ContainerLogCollector {
+	return &ContainerLogCollector{
+		logs:        logService,
+		containers:  containers,
+		deployments: deploymentLister,
+		apps:        appLister,
+		databases:   databaseLister,
+		projects:    projectLookup,
+		entries:     make(chan *logs.LogEntry, containerLogBatchSize),
+		followers:   make(map[string]*containerFollower),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start collects container logs until ctx is cancelled. The set of containers
+// is refreshed periodically so new deployments are picked up and replaced
+// containers are let go.
+func (c *ContainerLogCollector) Start(ctx context.Context) {
+	slog.Info("Starting container log collector")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	refreshTicker := time.NewTicker(containerLogRefreshInterval)
+	defer refreshTicker.Stop()
+	flushTicker := time.NewTicker(containerLogFlushInterval)
+	defer flushTicker.Stop()
+
+	batch := make([]*logs.LogEntry, 0, containerLogBatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.logs.RecordLogs(ctx, batch); err != nil {
+			slog.Error("Failed to record container logs", "count", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	c.refresh(ctx)
+
+	for {
+		select {
+		case entry := <-c.entries:
+			batch = append(batch, entry)
+			if len(batch) >= containerLogBatchSize {
+				flush(ctx)
+			}
+		case <-flushTicker.C:
+			flush(ctx)
+			c.cleanup(ctx)
+		case <-refreshTicker.C:
+			c.refresh(ctx)
+		case <-ctx.Done():
+			c.drain(&batch)
+			flushCtx, cancel := context.WithTimeout(context.Background(), containerLogShutdownTimeout)
+			flush(flushCtx)
+			cancel()
+			slog.Info("Container log collector stopped due to context cancellation")
+			return
+		case <-c.stopCh:
+			c.drain(&batch)
+			flush(ctx)
+			slog.Info("Container log collector stopped")
+			return
+		}
+	}
+}
+
+// Stop stops the collector
+func (c *ContainerLogCollector) Stop() {
+	close(c.stopCh)
+}
+
+// drain appends the entries the followers already handed over to batch
+func (c *ContainerLogCollector) drain(batch *[]*logs.LogEntry) {
+	for {
+		select {
+		case entry := <-c.entries:
+			*batch = append(*batch, entry)
+		default:
+			return
+		}
+	}
+}
+
+// refresh starts following the containers that aren't followed yet, including
+// those whose stream ended and which are still running
+func (c *ContainerLogCollector) refresh(ctx context.Context) {
+	targets, err := c.resolveTargets(ctx)
+	if err != nil {
+		slog.Warn("Failed to resolve containers for log collection", "error", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		current[target.containerID] = true
+
+		follower, ok := c.followers[target.containerID]
+		if !ok {
+			follower = &containerFollower{}
+			c.followers[target.containerID] = follower
+		}
+		if follower.active {
+			continue
+		}
+
+		follower.target = target
+		follower.active = true
+		go c.follow(ctx, follower)
+	}
+
+	for containerID, follower := range c.followers {
+		if !follower.active && !current[containerID] {
+			delete(c.followers, containerID)
+		}
+	}
+}
+
+func (c *ContainerLogCollector) resolveTargets(ctx context.Context) ([]logTarget, error) {
+	apps, err := c.apps.ListApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appsByID := make(map[string]*applications.Application, len(apps))
+	for _, app := range apps {
+		appsByID[app.ID().String()] = app
+	}
+
+	running, err := c.deployments.ListDeploymentsByStatus(ctx, deployments.DeploymentStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []logTarget
+	for _, deployment := range running {
+		app, ok := appsByID[deployment.ApplicationID().String()]
+		if !ok || deployment.ContainerID() == "" {
+			continue
+		}
+
+		appID, err := uuid.Parse(app.ID().String())
+		if err != nil {
+			continue
+		}
+		deploymentID, err := uuid.Parse(deployment.ID().String())
+		if err != nil {
+			continue
+		}
+
+		targets = append(targets, logTarget{
+			containerID:  deployment.ContainerID(),
+			projectID:    app.ProjectID(),
+			serviceID:    appID,
+			deploymentID: &deploymentID,
+			source:       logs.LogSourceApplication,
+			metadata: map[string]interface{}{
+				"application_name":  app.Name().String(),
+				"deployment_number": deployment.DeploymentNumber(),
+			},
+		})
+	}
+
+	dbs, err := c.databases.ListDatabasesWithContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, db := range dbs {
+		if db.Status() != databases.DatabaseStatusRunning {
+			continue
+		}
+
+		databaseID, err := uuid.Parse(db.ID().String())
+		if err != nil {
+			continue
+		}
+
+		targets = append(targets, logTarget{
+			containerID: db.ContainerID(),
+			projectID:   db.ProjectID(),
+			serviceID:   databaseID,
+			source:      logs.LogSourceDatabase,
+			metadata: map[string]interface{}{
+				"database_name": db.Name().String(),
+				"database_type": string(db.Type()),
+			},
+		})
+	}
+
+	return targets, nil
+}
+
+// follow streams the output of a container until it stops. The container
+// runtime replays the whole log on every connection, so lines that are not
+// newer than the last one collected are skipped.
+func (c *ContainerLogCollector) follow(ctx context.Context, follower *containerFollower) {
+	defer func() {
+		c.mu.Lock()
+		follower.active = false
+		c.mu.Unlock()
+	}()
+
+	target := follower.target
+
+	if !follower.loaded {
+		latest, err := c.logs.LatestContainerLog(ctx, target.containerID)
+		if err != nil {
+			slog.Warn("Failed to read collected logs of container", "container_id", target.containerID, "error", err)
+			return
+		}
+		follower.lastSeen = latest
+		follower.loaded = true
+	}
+
+	stream, err := c.containers.StreamContainerLogs(ctx, target.containerID, true)
+	if err != nil {
+		slog.Debug("Failed to stream container logs", "container_id", target.containerID, "error", err)
+		return
+	}
+	defer stream.Close()
+
+	parser := deploymentLogs.NewLogParser(time.Now())
+	scanner := bufio.NewScanner(demultiplexLogStream(stream))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		timestamp, line, ok := splitLogTimestamp(scanner.Text())
+		if !ok || !timestamp.After(follower.lastSeen) {
+			continue
+		}
+		follower.lastSeen = timestamp
+
+		parsed := parser.ParseLine(line)
+		if parsed == nil || parsed.Message == "" {
+			continue
+		}
+
+		entry := logs.NewContainerLogEntry(
+			target.projectID,
+			target.serviceID,
+			target.deploymentID,
+			target.containerID,
+			toLogLevel(parsed.Level),
+			parsed.Message,
+			target.source,
+			timestamp,
+			target.metadata,
+		)
+
+		select {
+		case c.entries <- entry:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		slog.Debug("Container log stream interrupted", "container_id", target.containerID, "error", err)
+	}
+}
+
+// cleanup applies the log retention of every project that has stored logs.
+// Logs of deleted projects are kept for the default retention.
+func (c *ContainerLogCollector) cleanup(ctx context.Context) {
+	if time.Since(c.lastCleanup) < containerLogCleanupInterval {
+		return
+	}
+	c.lastCleanup = time.Now()
+
+	projectIDs, err := c.logs.ListLoggedProjects(ctx)
+	if err != nil {
+		slog.Error("Failed to list projects with container logs", "error", err)
+		return
+	}
+
+	for _, projectID := range projectIDs {
+		retentionDays := projects.DefaultLogRetentionDays
+		if project, err := c.projects.GetProject(ctx, projectID.String()); err == nil {
+			retentionDays = project.LogRetentionDays()
+		}
+
+		if err := c.logs.CleanupOldLogs(ctx, projectID, retentionDays); err != nil {
+			slog.Error("Failed to clean up old container logs", "project_id", projectID, "error", err)
+		}
+	}
+}
+
+// toLogLevel maps the levels detected by the deployment log parser to log
+// entry levels
+func toLogLevel(level deploymentLogs.LogLevel) logs.LogLevel {
+	switch level {
+	case deploymentLogs.LogLevelError:
+		return logs.LogLevelError
+	case deploymentLogs.LogLevelWarn:
+		return logs.LogLevelWarn
+	default:
+		return logs.LogLevelInfo
+	}
+}
+
+// splitLogTimestamp splits the RFC 3339 timestamp the container runtime puts
+// in front of every line from the line itself
+func splitLogTimestamp(line string) (time.Time, string, bool) {
+	raw, rest, _ := strings.Cut(line, " ")
+	timestamp, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return timestamp, rest, true
+}
+
+// demultiplexLogStream strips the 8 byte frame headers Docker puts in front of
+// the output of containers without a TTY. Other streams are returned as is.
+func demultiplexLogStream(stream io.Reader) io.Reader {
+	reader := bufio.NewReader(stream)
+	header, err := reader.Peek(8)
+	if err != nil || header[0] > 2 || header[1] != 0 || header[2] != 0 || header[3] != 0 {
+		return reader
+	}
+	return &frameReader{reader: reader}
+}
+
+// frameReader reads the payload of a multiplexed Docker log stream
+type frameReader struct {
+	reader    *bufio.Reader
+	remaining int
+}
+
+func (f *frameReader) Read(p []byte) (int, error) {
+	for f.remaining == 0 {
+		var header [8]byte
+		if _, err := io.ReadFull(f.reader, header[:]); err != nil {
+			return 0, err
+		}
+		f.remaining = int(binary.BigEndian.Uint32(header[4:]))
+	}
+
+	if len(p) > f.remaining {
+		p = p[:f.remaining]
+	}
+	n, err := f.reader.Read(p)
+	f.remaining -= n
+	return n, err
+}
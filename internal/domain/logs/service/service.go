@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,13 +12,32 @@ import (
 	"github.com/mikrocloud/mikrocloud/internal/domain/logs/repository"
 )
 
+const (
+	// logSearchScanBatch is how many entries are read at once when a search
+	// filters messages with a regular expression
+	logSearchScanBatch = 500
+	// logSearchScanLimit caps the entries read by one regular expression
+	// search, the next cursor lets clients continue from there
+	logSearchScanLimit = 10000
+)
+
 type LogService struct {
 	logRepo repository.LogRepository
+
+	mu    sync.RWMutex
+	tails map[*logTail]struct{}
+}
+
+// LogPage is a page of a log search. NextCursor is empty on the last page.
+type LogPage struct {
+	Entries    []*logs.LogEntry
+	NextCursor *logs.LogCursor
 }
 
 func NewLogService(logRepo repository.LogRepository) *LogService {
 	return &LogService{
 		logRepo: logRepo,
+		tails:   make(map[*logTail]struct{}),
 	}
 }
 
@@ -48,6 +69,67 @@ func (s *LogService) QueryLogs(ctx context.Context, query logs.LogQuery) ([]*log
 	return s.logRepo.Query(ctx, query)
 }
 
+// SearchLogs returns a page of the entries matching the query, newest first.
+// When a pattern is given only entries whose message matches it are returned;
+// as the analytics databases don't share a regular expression syntax the
+// pattern is applied here while scanning, up to logSearchScanLimit entries
+// per page.
+func (s *LogService) SearchLogs(ctx context.Context, query logs.LogQuery, pattern *regexp.Regexp) (*LogPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	query.Offset = 0
+
+	if pattern == nil {
+		query.Limit = limit + 1
+		entries, err := s.logRepo.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		page := &LogPage{Entries: entries}
+		if len(entries) > limit {
+			page.Entries = entries[:limit]
+			page.NextCursor = logs.CursorOf(entries[limit-1])
+		}
+		return page, nil
+	}
+
+	page := &LogPage{Entries: make([]*logs.LogEntry, 0, limit)}
+	query.Limit = logSearchScanBatch
+	for scanned := 0; scanned < logSearchScanLimit; {
+		entries, err := s.logRepo.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			query.Before = logs.CursorOf(entry)
+			if !pattern.MatchString(entry.Message()) {
+				continue
+			}
+			page.Entries = append(page.Entries, entry)
+			if len(page.Entries) == limit {
+				page.NextCursor = query.Before
+				return page, nil
+			}
+		}
+
+		if len(entries) < logSearchScanBatch {
+			return page, nil
+		}
+		scanned += len(entries)
+	}
+
+	// The scan limit was reached, clients continue from where it stopped
+	page.NextCursor = query.Before
+	return page, nil
+}
+
 func (s *LogService) CountLogs(ctx context.Context, query logs.LogQuery) (int64, error) {
 	return s.logRepo.Count(ctx, query)
 }
@@ -60,3 +142,25 @@ func (s *LogService) CleanupOldLogs(ctx context.Context, projectID uuid.UUID, re
 	cutoff := time.Now().AddDate(0, 0, -retentionDays)
 	return s.logRepo.DeleteOlderThan(ctx, projectID, cutoff)
 }
+
+// RecordLogs stores a batch of collected log entries and hands them to the
+// live tails
+func (s *LogService) RecordLogs(ctx context.Context, entries []*logs.LogEntry) error {
+	if err := s.logRepo.CreateBatch(ctx, entries); err != nil {
+		return err
+	}
+
+	s.publish(entries)
+	return nil
+}
+
+// LatestContainerLog returns the time of the last stored line of a container,
+// or the zero time when nothing was collected from it yet
+func (s *LogService) LatestContainerLog(ctx context.Context, containerID string) (time.Time, error) {
+	return s.logRepo.LatestContainerTimestamp(ctx, containerID)
+}
+
+// ListLoggedProjects returns the projects that have stored log entries
+func (s *LogService) ListLoggedProjects(ctx context.Context) ([]uuid.UUID, error) {
+	return s.logRepo.ListProjectIDs(ctx)
+}
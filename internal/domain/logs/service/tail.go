@@ -0,0 +1,72 @@
+package service
+
+import (
+	"log/slog"
+	"regexp"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+)
+
+// logTailBuffer is how many entries a tail can fall behind before entries
+// are dropped for it
+const logTailBuffer = 256
+
+// logTail is a live subscription to newly recorded entries
+type logTail struct {
+	query   logs.LogQuery
+	pattern *regexp.Regexp
+	entries chan *logs.LogEntry
+}
+
+// TailLogs subscribes to the entries recorded from now on that match the query
+// and the optional message pattern. Paging fields of the query are ignored.
+// The returned function ends the subscription and closes the channel.
+// A tail that doesn't keep up misses entries rather than slowing down
+// collection.
+func (s *LogService) TailLogs(query logs.LogQuery, pattern *regexp.Regexp) (<-chan *logs.LogEntry, func()) {
+	tail := &logTail{
+		query:   query,
+		pattern: pattern,
+		entries: make(chan *logs.LogEntry, logTailBuffer),
+	}
+
+	s.mu.Lock()
+	s.tails[tail] = struct{}{}
+	s.mu.Unlock()
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.tails[tail]; ok {
+			delete(s.tails, tail)
+			close(tail.entries)
+		}
+	}
+
+	return tail.entries, cancel
+}
+
+func (s *LogService) publish(entries []*logs.LogEntry) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for tail := range s.tails {
+		dropped := 0
+		for _, entry := range entries {
+			if !tail.query.Matches(entry) {
+				continue
+			}
+			if tail.pattern != nil && !tail.pattern.MatchString(entry.Message()) {
+				continue
+			}
+			select {
+			case tail.entries <- entry:
+			default:
+				dropped++
+			}
+		}
+		if dropped > 0 {
+			slog.Debug("Dropped log entries for a slow live tail", "count", dropped)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/mikrocloud/mikrocloud/internal/api/deps"
 	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
+	uptimeHandler "github.com/mikrocloud/mikrocloud/internal/domain/uptime/handlers"
 )
 
 func RegisterOrganizationsRoutes(r chi.Router, deps *deps.Dependencies) {
@@ -30,6 +31,8 @@ func RegisterOrganizationsRoutes(r chi.Router, deps *deps.Dependencies) {
 			})
 
 			r.Post("/transfer-ownership", handler.TransferOwnership)
+
+			uptimeHandler.RegisterStatusPageRoutes(r, deps)
 		})
 	})
 }
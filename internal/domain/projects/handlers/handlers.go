@@ -28,11 +28,12 @@ func NewProjectHandler(pgs *service.ProjectService) *ProjectHandler {
 
 // ProjectResponse represents a project in API responses
 type ProjectResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Description      *string `json:"description"`
+	LogRetentionDays int     `json:"log_retention_days"`
+	CreatedAt        string  `json:"created_at"`
+	UpdatedAt        string  `json:"updated_at"`
 }
 
 type CreateProjectRequest struct {
@@ -43,6 +44,8 @@ type CreateProjectRequest struct {
 type UpdateProjectRequest struct {
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
 	Description *string `json:"description,omitempty"`
+	// LogRetentionDays is how long container logs of the project are kept
+	LogRetentionDays *int `json:"log_retention_days,omitempty" validate:"omitempty,min=1,max=365"`
 }
 
 type ProjectListItem struct {
@@ -87,11 +90,12 @@ func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := ProjectResponse{
-		ID:          proj.ID().String(),
-		Name:        proj.Name().String(),
-		Description: proj.Description(),
-		CreatedAt:   proj.CreatedAt().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:   proj.UpdatedAt().Format("2006-01-02T15:04:05Z"),
+		ID:               proj.ID().String(),
+		Name:             proj.Name().String(),
+		Description:      proj.Description(),
+		LogRetentionDays: proj.LogRetentionDays(),
+		CreatedAt:        proj.CreatedAt().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:        proj.UpdatedAt().Format("2006-01-02T15:04:05Z"),
 	}
 
 	utils.SendJSON(w, http.StatusCreated, response)
@@ -146,11 +150,12 @@ func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := ProjectResponse{
-		ID:          proj.ID().String(),
-		Name:        proj.Name().String(),
-		Description: proj.Description(),
-		CreatedAt:   proj.CreatedAt().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:   proj.UpdatedAt().Format("2006-01-02T15:04:05Z"),
+		ID:               proj.ID().String(),
+		Name:             proj.Name().String(),
+		Description:      proj.Description(),
+		LogRetentionDays: proj.LogRetentionDays(),
+		CreatedAt:        proj.CreatedAt().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:        proj.UpdatedAt().Format("2006-01-02T15:04:05Z"),
 	}
 
 	utils.SendJSON(w, http.StatusOK, response)
@@ -175,18 +180,19 @@ func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	proj, err := h.projectService.UpdateProject(r.Context(), projectID, req.Description)
+	proj, err := h.projectService.UpdateProject(r.Context(), projectID, req.Description, req.LogRetentionDays)
 	if err != nil {
 		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update project: "+err.Error())
 		return
 	}
 
 	response := ProjectResponse{
-		ID:          proj.ID().String(),
-		Name:        proj.Name().String(),
-		Description: proj.Description(),
-		CreatedAt:   proj.CreatedAt().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:   proj.UpdatedAt().Format("2006-01-02T15:04:05Z"),
+		ID:               proj.ID().String(),
+		Name:             proj.Name().String(),
+		Description:      proj.Description(),
+		LogRetentionDays: proj.LogRetentionDays(),
+		CreatedAt:        proj.CreatedAt().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:        proj.UpdatedAt().Format("2006-01-02T15:04:05Z"),
 	}
 
 	utils.SendJSON(w, http.StatusOK, response)
@@ -6,11 +6,17 @@ import (
 	"github.com/mikrocloud/mikrocloud/internal/api/deps"
 	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
 
+	alertsHandler "github.com/mikrocloud/mikrocloud/internal/domain/alerts/handlers"
+	analyticsHandler "github.com/mikrocloud/mikrocloud/internal/domain/analytics/handlers"
 	appHandler "github.com/mikrocloud/mikrocloud/internal/domain/applications/handlers"
 	dbHandler "github.com/mikrocloud/mikrocloud/internal/domain/databases/handlers"
+	deploymentsHandler "github.com/mikrocloud/mikrocloud/internal/domain/deployments/handlers"
 	disksHandler "github.com/mikrocloud/mikrocloud/internal/domain/disks/handlers"
 	envHandler "github.com/mikrocloud/mikrocloud/internal/domain/environments/handlers"
+	logsHandler "github.com/mikrocloud/mikrocloud/internal/domain/logs/handlers"
 	proxyHandler "github.com/mikrocloud/mikrocloud/internal/domain/proxy/handlers"
+	telemetryHandler "github.com/mikrocloud/mikrocloud/internal/domain/telemetry/handlers"
+	uptimeHandler "github.com/mikrocloud/mikrocloud/internal/domain/uptime/handlers"
 )
 
 func RegisterProjectRoutes(r chi.Router, deps *deps.Dependencies) {
@@ -28,9 +34,17 @@ func RegisterProjectRoutes(r chi.Router, deps *deps.Dependencies) {
 
 			envHandler.RegisterEnvironmentRoutes(r, deps)
 			appHandler.RegisterApplicationRoutes(r, deps)
+			deploymentsHandler.RegisterProjectDeploymentRoutes(r, deps)
 			dbHandler.RegisterDatabasesRoutes(r, deps)
 			proxyHandler.RegisterProxyRoutes(r, deps)
+			proxyHandler.RegisterErrorPageRoutes(r, deps)
 			disksHandler.RegisterDisksRoutes(r, deps)
+			logsHandler.RegisterLogsRoutes(r, deps)
+			analyticsHandler.RegisterProjectMetricsRoutes(r, deps)
+			analyticsHandler.RegisterDashboardRoutes(r, deps)
+			alertsHandler.RegisterAlertRoutes(r, deps)
+			telemetryHandler.RegisterTelemetryRoutes(r, deps)
+			uptimeHandler.RegisterUptimeRoutes(r, deps)
 		})
 	})
 }
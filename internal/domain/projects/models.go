@@ -2,6 +2,7 @@
 package projects
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -9,6 +10,14 @@ import (
 	"github.com/mikrocloud/mikrocloud/internal/domain/users"
 )
 
+const (
+	// DefaultLogRetentionDays is how long container logs are kept for projects
+	// that don't configure a retention
+	DefaultLogRetentionDays = 14
+	// MaxLogRetentionDays caps the configurable log retention
+	MaxLogRetentionDays = 365
+)
+
 // Project represents the core project entity
 type Project struct {
 	id             ProjectID
@@ -142,6 +151,42 @@ func (p *Project) UpdateSettings(settings string) {
 	p.updatedAt = time.Now()
 }
 
+// LogRetentionDays returns how many days of container logs are kept for the
+// project
+func (p *Project) LogRetentionDays() int {
+	var settings struct {
+		LogRetentionDays int `json:"log_retention_days"`
+	}
+	if err := json.Unmarshal([]byte(p.settings), &settings); err != nil || settings.LogRetentionDays <= 0 {
+		return DefaultLogRetentionDays
+	}
+	return settings.LogRetentionDays
+}
+
+// SetLogRetentionDays changes the container log retention, keeping the other
+// settings
+func (p *Project) SetLogRetentionDays(days int) error {
+	if days < 1 || days > MaxLogRetentionDays {
+		return fmt.Errorf("log retention must be between 1 and %d days", MaxLogRetentionDays)
+	}
+
+	settings := make(map[string]any)
+	if p.settings != "" {
+		if err := json.Unmarshal([]byte(p.settings), &settings); err != nil {
+			return fmt.Errorf("failed to parse project settings: %w", err)
+		}
+	}
+	settings["log_retention_days"] = days
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project settings: %w", err)
+	}
+
+	p.UpdateSettings(string(data))
+	return nil
+}
+
 func (p *Project) ChangeName(name ProjectName) error {
 	p.name = name
 	p.updatedAt = time.Now()
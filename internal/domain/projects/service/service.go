@@ -132,8 +132,9 @@ func (s *ProjectService) GetProjectByName(ctx context.Context, name string) (*pr
 	return s.projectRepo.FindByName(ctx, projectName)
 }
 
-// UpdateProject updates an existing projects
-func (s *ProjectService) UpdateProject(ctx context.Context, id string, description *string) (*projects.Project, error) {
+// UpdateProject updates an existing projects. The log retention is left
+// unchanged when logRetentionDays is nil.
+func (s *ProjectService) UpdateProject(ctx context.Context, id string, description *string, logRetentionDays *int) (*projects.Project, error) {
 	// Get existing projects
 	proj, err := s.GetProject(ctx, id)
 	if err != nil {
@@ -143,6 +144,12 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id string, descripti
 	// Update description
 	proj.UpdateDescription(description)
 
+	if logRetentionDays != nil {
+		if err := proj.SetLogRetentionDays(*logRetentionDays); err != nil {
+			return nil, err
+		}
+	}
+
 	// Save the updated projects
 	if err := s.projectRepo.Save(ctx, proj); err != nil {
 		return nil, fmt.Errorf("failed to update projects: %w", err)
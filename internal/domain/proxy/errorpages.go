@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultErrorStatuses are the upstream statuses replaced by the custom error pages
+	DefaultErrorStatuses = "500-599,404"
+
+	// MaxErrorPageSize is the largest HTML page accepted for upload
+	MaxErrorPageSize = 512 * 1024
+
+	ErrorPageFallback    = "error"
+	ErrorPageMaintenance = "maintenance"
+)
+
+var errorPageNameRegex = regexp.MustCompile(`^[45][0-9]{2}$`)
+
+// ApplicationPageSettings controls the maintenance mode and custom error pages of an application
+type ApplicationPageSettings struct {
+	applicationID string
+	maintenance   bool
+	errorPages    bool
+	errorStatuses string
+	updatedAt     time.Time
+}
+
+func NewApplicationPageSettings(applicationID string) *ApplicationPageSettings {
+	return &ApplicationPageSettings{
+		applicationID: applicationID,
+		errorStatuses: DefaultErrorStatuses,
+		updatedAt:     time.Now(),
+	}
+}
+
+func (s *ApplicationPageSettings) ApplicationID() string {
+	return s.applicationID
+}
+
+func (s *ApplicationPageSettings) MaintenanceEnabled() bool {
+	return s.maintenance
+}
+
+func (s *ApplicationPageSettings) ErrorPagesEnabled() bool {
+	return s.errorPages
+}
+
+func (s *ApplicationPageSettings) ErrorStatuses() string {
+	return s.errorStatuses
+}
+
+func (s *ApplicationPageSettings) UpdatedAt() time.Time {
+	return s.updatedAt
+}
+
+func (s *ApplicationPageSettings) SetMaintenance(enabled bool) {
+	s.maintenance = enabled
+	s.updatedAt = time.Now()
+}
+
+// SetErrorPages enables or disables the custom error pages. statuses uses the
+// Traefik range syntax ("500-599,404"); empty keeps the default ranges.
+func (s *ApplicationPageSettings) SetErrorPages(enabled bool, statuses string) error {
+	statuses = strings.ReplaceAll(statuses, " ", "")
+	if statuses == "" {
+		statuses = DefaultErrorStatuses
+	}
+	if err := validateStatusRanges(statuses); err != nil {
+		return err
+	}
+
+	s.errorPages = enabled
+	s.errorStatuses = statuses
+	s.updatedAt = time.Now()
+	return nil
+}
+
+func validateStatusRanges(statuses string) error {
+	for _, part := range strings.Split(statuses, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		prev := 0
+		for _, bound := range bounds {
+			code, err := strconv.Atoi(bound)
+			if err != nil || code < 400 || code > 599 {
+				return fmt.Errorf("invalid error status range: %s", part)
+			}
+			if code < prev {
+				return fmt.Errorf("invalid error status range: %s", part)
+			}
+			prev = code
+		}
+	}
+	return nil
+}
+
+func ReconstructApplicationPageSettings(applicationID string, maintenance, errorPages bool, errorStatuses string, updatedAt time.Time) *ApplicationPageSettings {
+	if errorStatuses == "" {
+		errorStatuses = DefaultErrorStatuses
+	}
+	return &ApplicationPageSettings{
+		applicationID: applicationID,
+		maintenance:   maintenance,
+		errorPages:    errorPages,
+		errorStatuses: errorStatuses,
+		updatedAt:     updatedAt,
+	}
+}
+
+// MaintenanceRoute is an application in maintenance mode and the domains
+// that must be routed to its project's maintenance page
+type MaintenanceRoute struct {
+	ApplicationID string
+	ProjectID     uuid.UUID
+	Domains       []string
+}
+
+// ValidateErrorPageName checks the name of an uploaded page: a 4xx/5xx status
+// code, "error" for the fallback page or "maintenance"
+func ValidateErrorPageName(name string) error {
+	if name == ErrorPageFallback || name == ErrorPageMaintenance || errorPageNameRegex.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("error page name must be a 4xx/5xx status code, %q or %q", ErrorPageFallback, ErrorPageMaintenance)
+}
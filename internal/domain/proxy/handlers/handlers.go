@@ -34,6 +34,8 @@ type CreateProxyConfigRequest struct {
 	Protocol    string   `json:"protocol" validate:"required,oneof=http https tcp udp"`
 	PathPrefix  string   `json:"path_prefix,omitempty"`
 	StripPrefix bool     `json:"strip_prefix,omitempty"`
+
+	Middlewares []service.MiddlewareConfigRequest `json:"middlewares,omitempty" validate:"omitempty,dive"`
 }
 
 type UpdateProxyConfigRequest struct {
@@ -46,6 +48,8 @@ type UpdateProxyConfigRequest struct {
 	Protocol    *string  `json:"protocol,omitempty" validate:"omitempty,oneof=http https tcp udp"`
 	PathPrefix  *string  `json:"path_prefix,omitempty"`
 	StripPrefix *bool    `json:"strip_prefix,omitempty"`
+
+	Middlewares []service.MiddlewareConfigRequest `json:"middlewares,omitempty" validate:"omitempty,dive"`
 }
 
 type ListProxyConfigsResponse struct {
@@ -86,6 +90,7 @@ func (h *ProxyHandler) CreateProxyConfig(w http.ResponseWriter, r *http.Request)
 		Protocol:    req.Protocol,
 		PathPrefix:  req.PathPrefix,
 		StripPrefix: req.StripPrefix,
+		Middlewares: req.Middlewares,
 	}
 
 	// Create proxy config
@@ -201,6 +206,9 @@ func (h *ProxyHandler) UpdateProxyConfig(w http.ResponseWriter, r *http.Request)
 	if req.StripPrefix != nil {
 		updateReq.StripPrefix = *req.StripPrefix
 	}
+	if req.Middlewares != nil {
+		updateReq.Middlewares = req.Middlewares
+	}
 
 	// Update the configuration
 	config, err := h.proxyService.UpdateProxyConfig(r.Context(), configID, updateReq)
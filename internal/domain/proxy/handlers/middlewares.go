@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	applicationsService "github.com/mikrocloud/mikrocloud/internal/domain/applications/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type RouteMiddlewareHandler struct {
+	proxyService *service.ProxyService
+	appService   *applicationsService.ApplicationService
+	validator    *validator.Validate
+}
+
+func NewRouteMiddlewareHandler(proxyService *service.ProxyService, appService *applicationsService.ApplicationService) *RouteMiddlewareHandler {
+	return &RouteMiddlewareHandler{
+		proxyService: proxyService,
+		appService:   appService,
+		validator:    validator.New(),
+	}
+}
+
+type RouteMiddlewareRequest struct {
+	Name     string                 `json:"name" validate:"required,min=1,max=63"`
+	Type     string                 `json:"type" validate:"required,oneof=auth ratelimit compression cors headers stripprefix redirect ipallowlist replacepathregex"`
+	Domain   string                 `json:"domain,omitempty" validate:"omitempty,fqdn"`
+	Config   map[string]interface{} `json:"config"`
+	Priority int                    `json:"priority"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+}
+
+type ListRouteMiddlewaresResponse struct {
+	Middlewares []*service.RouteMiddlewareResponse `json:"middlewares"`
+}
+
+// ListRouteMiddlewares lists the middlewares attached to an application
+func (h *RouteMiddlewareHandler) ListRouteMiddlewares(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	middlewares, err := h.proxyService.ListRouteMiddlewares(r.Context(), app.ID().String())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list middlewares")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, ListRouteMiddlewaresResponse{Middlewares: middlewares})
+}
+
+// CreateRouteMiddleware attaches a new middleware to an application and re-applies its routing
+func (h *RouteMiddlewareHandler) CreateRouteMiddleware(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	middleware, err := h.proxyService.CreateRouteMiddleware(r.Context(), app.ID().String(), toServiceRequest(req))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create middleware: "+err.Error())
+		return
+	}
+
+	h.appService.ReapplyRouting(r.Context(), app.ID())
+
+	utils.SendJSON(w, http.StatusCreated, middleware)
+}
+
+// UpdateRouteMiddleware replaces a middleware configuration and re-applies routing
+func (h *RouteMiddlewareHandler) UpdateRouteMiddleware(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	middlewareID := chi.URLParam(r, "middleware_id")
+
+	middleware, err := h.proxyService.UpdateRouteMiddleware(r.Context(), app.ID().String(), middlewareID, toServiceRequest(req))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update middleware: "+err.Error())
+		return
+	}
+
+	h.appService.ReapplyRouting(r.Context(), app.ID())
+
+	utils.SendJSON(w, http.StatusOK, middleware)
+}
+
+// DeleteRouteMiddleware detaches a middleware from an application
+func (h *RouteMiddlewareHandler) DeleteRouteMiddleware(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	middlewareID := chi.URLParam(r, "middleware_id")
+
+	if err := h.proxyService.DeleteRouteMiddleware(r.Context(), app.ID().String(), middlewareID); err != nil {
+		utils.SendError(w, http.StatusNotFound, "middleware_not_found", "Middleware not found")
+		return
+	}
+
+	h.appService.ReapplyRouting(r.Context(), app.ID())
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RouteMiddlewareHandler) decodeRequest(w http.ResponseWriter, r *http.Request) (RouteMiddlewareRequest, bool) {
+	var req RouteMiddlewareRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return req, false
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return req, false
+	}
+
+	return req, true
+}
+
+func (h *RouteMiddlewareHandler) getApplication(w http.ResponseWriter, r *http.Request) (*applications.Application, bool) {
+	return getProjectApplication(w, r, h.appService)
+}
+
+// getProjectApplication loads the application of the request URL, making sure
+// it belongs to the project of the URL
+func getProjectApplication(w http.ResponseWriter, r *http.Request, appService *applicationsService.ApplicationService) (*applications.Application, bool) {
+	appID, err := applications.ApplicationIDFromString(chi.URLParam(r, "application_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_application_id", "Invalid application ID")
+		return nil, false
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return nil, false
+	}
+
+	app, err := appService.GetApplication(r.Context(), appID)
+	if err != nil || app.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "application_not_found", "Application not found")
+		return nil, false
+	}
+
+	return app, true
+}
+
+func toServiceRequest(req RouteMiddlewareRequest) service.RouteMiddlewareRequest {
+	return service.RouteMiddlewareRequest{
+		Name:     req.Name,
+		Type:     req.Type,
+		Domain:   req.Domain,
+		Config:   req.Config,
+		Priority: req.Priority,
+		Enabled:  req.Enabled,
+	}
+}
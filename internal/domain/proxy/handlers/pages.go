@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	applicationsService "github.com/mikrocloud/mikrocloud/internal/domain/applications/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	proxyContainers "github.com/mikrocloud/mikrocloud/pkg/containers/proxy"
+)
+
+type PagesHandler struct {
+	proxyService *service.ProxyService
+	appService   *applicationsService.ApplicationService
+	errorPages   *proxyContainers.ErrorPagesService
+	traefik      *proxyContainers.TraefikService
+	validator    *validator.Validate
+}
+
+func NewPagesHandler(
+	proxyService *service.ProxyService,
+	appService *applicationsService.ApplicationService,
+	errorPages *proxyContainers.ErrorPagesService,
+	traefik *proxyContainers.TraefikService,
+) *PagesHandler {
+	return &PagesHandler{
+		proxyService: proxyService,
+		appService:   appService,
+		errorPages:   errorPages,
+		traefik:      traefik,
+		validator:    validator.New(),
+	}
+}
+
+type MaintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ErrorPagesRequest struct {
+	Enabled  bool   `json:"enabled"`
+	Statuses string `json:"statuses,omitempty" validate:"omitempty,max=255"`
+}
+
+type ListErrorPagesResponse struct {
+	Pages []string `json:"pages"`
+}
+
+// GetPageSettings returns the maintenance and error page settings of an application
+func (h *PagesHandler) GetPageSettings(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.proxyService.GetApplicationPageSettings(r.Context(), app.ID().String())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "get_failed", "Failed to get page settings")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, service.ToApplicationPageSettingsResponse(settings))
+}
+
+// SetMaintenance toggles maintenance mode, routing the application's domains
+// to the project's maintenance page while it is enabled
+func (h *PagesHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.proxyService.SetMaintenance(r.Context(), app.ID().String(), req.Enabled)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "update_failed", "Failed to update maintenance mode: "+err.Error())
+		return
+	}
+
+	routes, err := h.proxyService.ListMaintenanceRoutes(r.Context())
+	if err == nil {
+		err = h.traefik.WriteMaintenanceConfig(routes)
+	}
+	if err != nil {
+		slog.Error("Failed to apply maintenance routes", "application_id", app.ID().String(), "error", err)
+		utils.SendError(w, http.StatusInternalServerError, "apply_failed", "Failed to apply maintenance routes")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, settings)
+}
+
+// UpdateErrorPages enables or disables the custom error pages of an application
+// and re-applies its routing
+func (h *PagesHandler) UpdateErrorPages(w http.ResponseWriter, r *http.Request) {
+	var req ErrorPagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.proxyService.UpdateErrorPages(r.Context(), app.ID().String(), req.Enabled, req.Statuses)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update error pages: "+err.Error())
+		return
+	}
+
+	h.appService.ReapplyRouting(r.Context(), app.ID())
+
+	utils.SendJSON(w, http.StatusOK, settings)
+}
+
+// ListErrorPages lists the pages uploaded for a project
+func (h *PagesHandler) ListErrorPages(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProjectID(w, r)
+	if !ok {
+		return
+	}
+
+	pages, err := h.errorPages.ListPages(projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list error pages")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, ListErrorPagesResponse{Pages: pages})
+}
+
+// GetErrorPage returns the HTML of an uploaded page
+func (h *PagesHandler) GetErrorPage(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProjectID(w, r)
+	if !ok {
+		return
+	}
+
+	html, err := h.errorPages.GetPage(projectID, chi.URLParam(r, "page"))
+	if errors.Is(err, os.ErrNotExist) {
+		utils.SendError(w, http.StatusNotFound, "page_not_found", "Error page not found")
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "get_failed", "Failed to get error page: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(html)
+}
+
+// UploadErrorPage stores the HTML of a project page, sent either as the "file"
+// field of a multipart form or as the raw request body
+func (h *PagesHandler) UploadErrorPage(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProjectID(w, r)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = r.Body
+	if err := r.ParseMultipartForm(proxy.MaxErrorPageSize); err == nil {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_file", "No file uploaded")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	html, err := io.ReadAll(io.LimitReader(body, proxy.MaxErrorPageSize+1))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Failed to read error page")
+		return
+	}
+	if len(html) == 0 {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Error page is empty")
+		return
+	}
+
+	name := chi.URLParam(r, "page")
+	if err := h.errorPages.SavePage(projectID, name, html); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "upload_failed", "Failed to save error page: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"page": name})
+}
+
+// DeleteErrorPage removes an uploaded page so the default one is served again
+func (h *PagesHandler) DeleteErrorPage(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProjectID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.errorPages.DeletePage(projectID, chi.URLParam(r, "page"))
+	if errors.Is(err, os.ErrNotExist) {
+		utils.SendError(w, http.StatusNotFound, "page_not_found", "Error page not found")
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "delete_failed", "Failed to delete error page: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PagesHandler) getProjectID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return uuid.Nil, false
+	}
+	return projectID, true
+}
+
+func (h *PagesHandler) getApplication(w http.ResponseWriter, r *http.Request) (*applications.Application, bool) {
+	return getProjectApplication(w, r, h.appService)
+}
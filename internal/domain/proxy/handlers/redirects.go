@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	applicationsService "github.com/mikrocloud/mikrocloud/internal/domain/applications/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	proxyContainers "github.com/mikrocloud/mikrocloud/pkg/containers/proxy"
+)
+
+// maxRedirectImportSize bounds the size of an uploaded CSV file
+const maxRedirectImportSize = 10 << 20
+
+type RedirectRuleHandler struct {
+	proxyService *service.ProxyService
+	appService   *applicationsService.ApplicationService
+	traefik      *proxyContainers.TraefikService
+	validator    *validator.Validate
+}
+
+func NewRedirectRuleHandler(proxyService *service.ProxyService, appService *applicationsService.ApplicationService, traefik *proxyContainers.TraefikService) *RedirectRuleHandler {
+	return &RedirectRuleHandler{
+		proxyService: proxyService,
+		appService:   appService,
+		traefik:      traefik,
+		validator:    validator.New(),
+	}
+}
+
+type RedirectRuleRequest struct {
+	Action      string `json:"action" validate:"required,oneof=redirect rewrite"`
+	Match       string `json:"match" validate:"omitempty,oneof=exact prefix regex"`
+	Source      string `json:"source" validate:"required,max=2048"`
+	Destination string `json:"destination" validate:"required,max=2048"`
+	StatusCode  int    `json:"status_code,omitempty" validate:"omitempty,oneof=301 302"`
+	Priority    int    `json:"priority"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+type TestRedirectRequest struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+type ListRedirectRulesResponse struct {
+	Rules []*service.RedirectRuleResponse `json:"rules"`
+}
+
+// ListRedirectRules lists the redirect and rewrite rules of an application in evaluation order
+func (h *RedirectRuleHandler) ListRedirectRules(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.proxyService.ListRedirectRules(r.Context(), app.ID().String())
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list redirect rules")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, ListRedirectRulesResponse{Rules: rules})
+}
+
+func (h *RedirectRuleHandler) CreateRedirectRule(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	hadRedirects := h.hasRedirects(r.Context(), app)
+
+	rule, err := h.proxyService.CreateRedirectRule(r.Context(), app.ID().String(), toRedirectServiceRequest(req))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create redirect rule: "+err.Error())
+		return
+	}
+
+	h.applyRedirects(r.Context(), app, hadRedirects)
+
+	utils.SendJSON(w, http.StatusCreated, rule)
+}
+
+func (h *RedirectRuleHandler) UpdateRedirectRule(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	hadRedirects := h.hasRedirects(r.Context(), app)
+
+	rule, err := h.proxyService.UpdateRedirectRule(r.Context(), app.ID().String(), chi.URLParam(r, "rule_id"), toRedirectServiceRequest(req))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update redirect rule: "+err.Error())
+		return
+	}
+
+	h.applyRedirects(r.Context(), app, hadRedirects)
+
+	utils.SendJSON(w, http.StatusOK, rule)
+}
+
+func (h *RedirectRuleHandler) DeleteRedirectRule(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	hadRedirects := h.hasRedirects(r.Context(), app)
+
+	if err := h.proxyService.DeleteRedirectRule(r.Context(), app.ID().String(), chi.URLParam(r, "rule_id")); err != nil {
+		utils.SendError(w, http.StatusNotFound, "rule_not_found", "Redirect rule not found")
+		return
+	}
+
+	h.applyRedirects(r.Context(), app, hadRedirects)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportRedirectRules imports rules from a CSV file sent as the "file" field of a
+// multipart form or as the raw body. ?mode=replace drops the existing rules first.
+func (h *RedirectRuleHandler) ImportRedirectRules(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "append" && mode != "replace" {
+		utils.SendError(w, http.StatusBadRequest, "invalid_mode", "Mode must be append or replace")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRedirectImportSize)
+	body := r.Body
+	if err := r.ParseMultipartForm(maxRedirectImportSize); err == nil {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_file", "No file uploaded")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	hadRedirects := h.hasRedirects(r.Context(), app)
+
+	result, err := h.proxyService.ImportRedirectRules(r.Context(), app.ID().String(), body, mode == "replace")
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "import_failed", "Failed to import redirect rules: "+err.Error())
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		utils.SendJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	h.applyRedirects(r.Context(), app, hadRedirects)
+
+	utils.SendJSON(w, http.StatusOK, result)
+}
+
+// ExportRedirectRules downloads the rules of an application as CSV
+func (h *RedirectRuleHandler) ExportRedirectRules(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-redirects.csv"`, app.Name().String()))
+
+	if err := h.proxyService.ExportRedirectRules(r.Context(), app.ID().String(), w); err != nil {
+		slog.Error("Failed to export redirect rules", "application_id", app.ID().String(), "error", err)
+	}
+}
+
+// TestRedirect is a dry run showing which rule a URL hits and the resulting location or path
+func (h *RedirectRuleHandler) TestRedirect(w http.ResponseWriter, r *http.Request) {
+	var req TestRedirectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	app, ok := h.getApplication(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.proxyService.TestRedirect(r.Context(), app.ID().String(), req.URL)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "test_failed", "Failed to test redirect: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, result)
+}
+
+func (h *RedirectRuleHandler) hasRedirects(ctx context.Context, app *applications.Application) bool {
+	hasRedirects, err := h.proxyService.HasApplicationRedirects(ctx, app.ID().String())
+	if err != nil {
+		slog.Warn("Failed to check redirect rules", "application_id", app.ID().String(), "error", err)
+	}
+	return hasRedirects
+}
+
+// applyRedirects recompiles the redirect config. The application router only
+// needs to be recreated when it starts or stops referencing its redirect chain.
+func (h *RedirectRuleHandler) applyRedirects(ctx context.Context, app *applications.Application, hadRedirects bool) {
+	rules, err := h.proxyService.ListEnabledRedirectRules(ctx)
+	if err == nil {
+		err = h.traefik.WriteRedirectConfig(rules)
+	}
+	if err != nil {
+		slog.Error("Failed to apply redirect rules", "application_id", app.ID().String(), "error", err)
+		return
+	}
+
+	if h.hasRedirects(ctx, app) != hadRedirects {
+		h.appService.ReapplyRouting(ctx, app.ID())
+	}
+}
+
+func (h *RedirectRuleHandler) decodeRequest(w http.ResponseWriter, r *http.Request) (RedirectRuleRequest, bool) {
+	var req RedirectRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return req, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return req, false
+	}
+
+	return req, true
+}
+
+func (h *RedirectRuleHandler) getApplication(w http.ResponseWriter, r *http.Request) (*applications.Application, bool) {
+	return getProjectApplication(w, r, h.appService)
+}
+
+func toRedirectServiceRequest(req RedirectRuleRequest) service.RedirectRuleRequest {
+	return service.RedirectRuleRequest{
+		Action:      req.Action,
+		Match:       req.Match,
+		Source:      req.Source,
+		Destination: req.Destination,
+		StatusCode:  req.StatusCode,
+		Priority:    req.Priority,
+		Enabled:     req.Enabled,
+	}
+}
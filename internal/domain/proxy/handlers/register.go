@@ -20,3 +20,57 @@ func RegisterProxyRoutes(r chi.Router, deps *deps.Dependencies) {
 		})
 	})
 }
+
+func RegisterRouteMiddlewareRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewRouteMiddlewareHandler(deps.ProxyService, deps.ApplicationService)
+
+	// Middleware routes within application
+	r.Route("/middlewares", func(r chi.Router) {
+		r.Get("/", handler.ListRouteMiddlewares)
+		r.Post("/", handler.CreateRouteMiddleware)
+		r.Route("/{middleware_id}", func(r chi.Router) {
+			r.Put("/", handler.UpdateRouteMiddleware)
+			r.Delete("/", handler.DeleteRouteMiddleware)
+		})
+	})
+}
+
+func RegisterApplicationPageRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewPagesHandler(deps.ProxyService, deps.ApplicationService, deps.ErrorPagesService, deps.TraefikService)
+
+	// Maintenance and error page routes within application
+	r.Get("/pages", handler.GetPageSettings)
+	r.Put("/maintenance", handler.SetMaintenance)
+	r.Put("/error-pages", handler.UpdateErrorPages)
+}
+
+func RegisterErrorPageRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewPagesHandler(deps.ProxyService, deps.ApplicationService, deps.ErrorPagesService, deps.TraefikService)
+
+	// Custom error page routes within project
+	r.Route("/error-pages", func(r chi.Router) {
+		r.Get("/", handler.ListErrorPages)
+		r.Route("/{page}", func(r chi.Router) {
+			r.Get("/", handler.GetErrorPage)
+			r.Put("/", handler.UploadErrorPage)
+			r.Delete("/", handler.DeleteErrorPage)
+		})
+	})
+}
+
+func RegisterRedirectRuleRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewRedirectRuleHandler(deps.ProxyService, deps.ApplicationService, deps.TraefikService)
+
+	// Redirect and rewrite rule routes within application
+	r.Route("/redirects", func(r chi.Router) {
+		r.Get("/", handler.ListRedirectRules)
+		r.Post("/", handler.CreateRedirectRule)
+		r.Post("/import", handler.ImportRedirectRules)
+		r.Get("/export", handler.ExportRedirectRules)
+		r.Post("/test", handler.TestRedirect)
+		r.Route("/{rule_id}", func(r chi.Router) {
+			r.Put("/", handler.UpdateRedirectRule)
+			r.Delete("/", handler.DeleteRedirectRule)
+		})
+	})
+}
@@ -0,0 +1,313 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var middlewareNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func NewMiddlewareConfig(name string, type_ MiddlewareType, config map[string]interface{}) (MiddlewareConfig, error) {
+	if !middlewareNameRegex.MatchString(name) {
+		return MiddlewareConfig{}, fmt.Errorf("middleware name must be lowercase alphanumeric with dashes")
+	}
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+
+	mw := MiddlewareConfig{name: name, type_: type_, config: config}
+	if err := mw.validate(); err != nil {
+		return MiddlewareConfig{}, err
+	}
+
+	return mw, nil
+}
+
+func (m *MiddlewareConfig) validate() error {
+	switch m.type_ {
+	case MiddlewareTypeAuth:
+		users := m.GetStringSlice("users")
+		if len(users) == 0 {
+			return fmt.Errorf("basic auth requires at least one user")
+		}
+		for _, user := range users {
+			if !strings.Contains(user, ":") {
+				return fmt.Errorf("basic auth user must be in htpasswd format (user:hash)")
+			}
+		}
+	case MiddlewareTypeIPAllowList:
+		ranges := m.GetStringSlice("source_range")
+		if len(ranges) == 0 {
+			return fmt.Errorf("ip allowlist requires at least one source range")
+		}
+		for _, r := range ranges {
+			if _, _, err := net.ParseCIDR(r); err != nil && net.ParseIP(r) == nil {
+				return fmt.Errorf("invalid source range: %s", r)
+			}
+		}
+	case MiddlewareTypeRateLimit:
+		if m.GetInt("average") <= 0 {
+			return fmt.Errorf("rate limit average must be greater than zero")
+		}
+		if m.GetInt("burst") < 0 {
+			return fmt.Errorf("rate limit burst cannot be negative")
+		}
+		if period := m.GetString("period"); period != "" {
+			if _, err := time.ParseDuration(period); err != nil {
+				return fmt.Errorf("invalid rate limit period: %w", err)
+			}
+		}
+	case MiddlewareTypeHeaders:
+		if m.GetInt("sts_seconds") < 0 {
+			return fmt.Errorf("sts_seconds cannot be negative")
+		}
+	case MiddlewareTypeReplacePath, MiddlewareTypeRedirect:
+		regex := m.GetString("regex")
+		if regex == "" {
+			return fmt.Errorf("%s requires a regex", m.type_)
+		}
+		if _, err := regexp.Compile(regex); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		if m.GetString("replacement") == "" {
+			return fmt.Errorf("%s requires a replacement", m.type_)
+		}
+	case MiddlewareTypeStripPrefix:
+		if len(m.GetStringSlice("prefixes")) == 0 {
+			return fmt.Errorf("strip prefix requires at least one prefix")
+		}
+	case MiddlewareTypeCompression, MiddlewareTypeCORS:
+	default:
+		return fmt.Errorf("unsupported middleware type: %s", m.type_)
+	}
+
+	return nil
+}
+
+// GetString returns the config value for key, or "" when missing or not a string.
+func (m *MiddlewareConfig) GetString(key string) string {
+	s, _ := m.config[key].(string)
+	return s
+}
+
+func (m *MiddlewareConfig) GetInt(key string) int {
+	switch v := m.config[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func (m *MiddlewareConfig) GetBool(key string) bool {
+	b, _ := m.config[key].(bool)
+	return b
+}
+
+// GetStringSlice accepts both JSON-decoded arrays and comma-separated strings.
+func (m *MiddlewareConfig) GetStringSlice(key string) []string {
+	switch v := m.config[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	case string:
+		var result []string
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func (m *MiddlewareConfig) GetStringMap(key string) map[string]string {
+	switch v := m.config[key].(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		result := make(map[string]string, len(v))
+		for k, item := range v {
+			if s, ok := item.(string); ok {
+				result[k] = s
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+type middlewareConfigJSON struct {
+	Name   string                 `json:"name"`
+	Type   MiddlewareType         `json:"type"`
+	Config map[string]interface{} `json:"config"`
+}
+
+func (m MiddlewareConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(middlewareConfigJSON{Name: m.name, Type: m.type_, Config: m.config})
+}
+
+func (m *MiddlewareConfig) UnmarshalJSON(data []byte) error {
+	var raw middlewareConfigJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.name = raw.Name
+	m.type_ = raw.Type
+	m.config = raw.Config
+	return nil
+}
+
+// RouteMiddleware attaches a middleware to the router of an application.
+// When domain is set it only applies while the application is served on it.
+type RouteMiddleware struct {
+	id            RouteMiddlewareID
+	applicationID string
+	domain        string
+	middleware    MiddlewareConfig
+	priority      int
+	enabled       bool
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+type RouteMiddlewareID struct {
+	value string
+}
+
+func NewRouteMiddlewareID() RouteMiddlewareID {
+	return RouteMiddlewareID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func RouteMiddlewareIDFromString(s string) (RouteMiddlewareID, error) {
+	if s == "" {
+		return RouteMiddlewareID{}, fmt.Errorf("route middleware ID cannot be empty")
+	}
+	return RouteMiddlewareID{value: s}, nil
+}
+
+func (id RouteMiddlewareID) String() string {
+	return id.value
+}
+
+func NewRouteMiddleware(applicationID, domain string, middleware MiddlewareConfig, priority int) (*RouteMiddleware, error) {
+	if applicationID == "" {
+		return nil, fmt.Errorf("application ID cannot be empty")
+	}
+
+	now := time.Now()
+	return &RouteMiddleware{
+		id:            NewRouteMiddlewareID(),
+		applicationID: applicationID,
+		domain:        strings.ToLower(strings.TrimSpace(domain)),
+		middleware:    middleware,
+		priority:      priority,
+		enabled:       true,
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+func (rm *RouteMiddleware) ID() RouteMiddlewareID {
+	return rm.id
+}
+
+func (rm *RouteMiddleware) ApplicationID() string {
+	return rm.applicationID
+}
+
+func (rm *RouteMiddleware) Domain() string {
+	return rm.domain
+}
+
+func (rm *RouteMiddleware) Middleware() MiddlewareConfig {
+	return rm.middleware
+}
+
+func (rm *RouteMiddleware) Priority() int {
+	return rm.priority
+}
+
+func (rm *RouteMiddleware) Enabled() bool {
+	return rm.enabled
+}
+
+func (rm *RouteMiddleware) CreatedAt() time.Time {
+	return rm.createdAt
+}
+
+func (rm *RouteMiddleware) UpdatedAt() time.Time {
+	return rm.updatedAt
+}
+
+// AppliesTo reports whether the middleware should be attached to a router serving domain.
+func (rm *RouteMiddleware) AppliesTo(domain string) bool {
+	if !rm.enabled {
+		return false
+	}
+	return rm.domain == "" || strings.EqualFold(rm.domain, domain)
+}
+
+func (rm *RouteMiddleware) UpdateMiddleware(middleware MiddlewareConfig) {
+	rm.middleware = middleware
+	rm.updatedAt = time.Now()
+}
+
+func (rm *RouteMiddleware) SetDomain(domain string) {
+	rm.domain = strings.ToLower(strings.TrimSpace(domain))
+	rm.updatedAt = time.Now()
+}
+
+func (rm *RouteMiddleware) SetPriority(priority int) {
+	rm.priority = priority
+	rm.updatedAt = time.Now()
+}
+
+func (rm *RouteMiddleware) SetEnabled(enabled bool) {
+	rm.enabled = enabled
+	rm.updatedAt = time.Now()
+}
+
+func ReconstructRouteMiddleware(
+	id RouteMiddlewareID,
+	applicationID, domain string,
+	middleware MiddlewareConfig,
+	priority int,
+	enabled bool,
+	createdAt, updatedAt time.Time,
+) *RouteMiddleware {
+	return &RouteMiddleware{
+		id:            id,
+		applicationID: applicationID,
+		domain:        domain,
+		middleware:    middleware,
+		priority:      priority,
+		enabled:       enabled,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+func ReconstructMiddlewareConfig(name string, type_ MiddlewareType, config map[string]interface{}) MiddlewareConfig {
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	return MiddlewareConfig{name: name, type_: type_, config: config}
+}
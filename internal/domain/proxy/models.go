@@ -141,6 +141,8 @@ const (
 	MiddlewareTypeHeaders     MiddlewareType = "headers"
 	MiddlewareTypeStripPrefix MiddlewareType = "stripprefix"
 	MiddlewareTypeRedirect    MiddlewareType = "redirect"
+	MiddlewareTypeIPAllowList MiddlewareType = "ipallowlist"
+	MiddlewareTypeReplacePath MiddlewareType = "replacepathregex"
 )
 
 type HealthCheckConfig struct {
@@ -434,6 +436,11 @@ func (pc *ProxyConfig) AddMiddleware(middleware MiddlewareConfig) {
 	pc.updatedAt = time.Now()
 }
 
+func (pc *ProxyConfig) SetMiddlewares(middlewares []MiddlewareConfig) {
+	pc.middlewares = middlewares
+	pc.updatedAt = time.Now()
+}
+
 func (pc *ProxyConfig) SetHealthCheck(config *HealthCheckConfig) {
 	pc.healthCheck = config
 	pc.updatedAt = time.Now()
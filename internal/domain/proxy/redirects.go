@@ -0,0 +1,359 @@
+package proxy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RedirectRuleID struct {
+	value string
+}
+
+func NewRedirectRuleID() RedirectRuleID {
+	return RedirectRuleID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func RedirectRuleIDFromString(s string) (RedirectRuleID, error) {
+	if s == "" {
+		return RedirectRuleID{}, fmt.Errorf("redirect rule ID cannot be empty")
+	}
+	return RedirectRuleID{value: s}, nil
+}
+
+func (id RedirectRuleID) String() string {
+	return id.value
+}
+
+type RedirectAction string
+
+const (
+	// RedirectActionRedirect answers with a redirect to the destination
+	RedirectActionRedirect RedirectAction = "redirect"
+	// RedirectActionRewrite serves the destination path without the client noticing
+	RedirectActionRewrite RedirectAction = "rewrite"
+)
+
+type RedirectMatch string
+
+const (
+	RedirectMatchExact  RedirectMatch = "exact"
+	RedirectMatchPrefix RedirectMatch = "prefix"
+	RedirectMatchRegex  RedirectMatch = "regex"
+)
+
+// urlPrefix matches the scheme and host Traefik prepends to the request URI
+// before evaluating redirectRegex
+const urlPrefix = `^https?://[^/]+`
+
+// RedirectRule maps request paths of an application to a new location
+type RedirectRule struct {
+	id            RedirectRuleID
+	applicationID string
+	action        RedirectAction
+	match         RedirectMatch
+	source        string
+	destination   string
+	statusCode    int
+	priority      int
+	enabled       bool
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+func NewRedirectRule(applicationID string, action RedirectAction, match RedirectMatch, source, destination string, statusCode, priority int) (*RedirectRule, error) {
+	if applicationID == "" {
+		return nil, fmt.Errorf("application ID cannot be empty")
+	}
+
+	now := time.Now()
+	rule := &RedirectRule{
+		id:            NewRedirectRuleID(),
+		applicationID: applicationID,
+		enabled:       true,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+
+	if err := rule.Update(action, match, source, destination, statusCode, priority); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// Update replaces the matching and target of the rule
+func (r *RedirectRule) Update(action RedirectAction, match RedirectMatch, source, destination string, statusCode, priority int) error {
+	if match == "" {
+		match = RedirectMatchExact
+	}
+	if action == RedirectActionRedirect && statusCode == 0 {
+		statusCode = 301
+	}
+	if action == RedirectActionRewrite {
+		statusCode = 0
+	}
+
+	candidate := RedirectRule{
+		action:      action,
+		match:       match,
+		source:      strings.TrimSpace(source),
+		destination: strings.TrimSpace(destination),
+		statusCode:  statusCode,
+	}
+	if err := candidate.validate(); err != nil {
+		return err
+	}
+
+	r.action = candidate.action
+	r.match = candidate.match
+	r.source = candidate.source
+	r.destination = candidate.destination
+	r.statusCode = candidate.statusCode
+	r.priority = priority
+	r.updatedAt = time.Now()
+	return nil
+}
+
+func (r *RedirectRule) validate() error {
+	switch r.action {
+	case RedirectActionRedirect:
+		// Traefik only distinguishes permanent and temporary redirects
+		if r.statusCode != 301 && r.statusCode != 302 {
+			return fmt.Errorf("redirect status code must be 301 or 302")
+		}
+	case RedirectActionRewrite:
+	default:
+		return fmt.Errorf("unsupported redirect action: %s", r.action)
+	}
+
+	if r.source == "" {
+		return fmt.Errorf("source is required")
+	}
+	if r.destination == "" {
+		return fmt.Errorf("destination is required")
+	}
+
+	switch r.match {
+	case RedirectMatchExact, RedirectMatchPrefix:
+		if !strings.HasPrefix(r.source, "/") {
+			return fmt.Errorf("source path must start with /")
+		}
+	case RedirectMatchRegex:
+		if _, err := regexp.Compile(r.source); err != nil {
+			return fmt.Errorf("invalid source regex: %w", err)
+		}
+		if !strings.HasPrefix(strings.TrimPrefix(r.source, "^"), "/") {
+			return fmt.Errorf("source regex must match from the start of the path (^/...)")
+		}
+	default:
+		return fmt.Errorf("unsupported match type: %s", r.match)
+	}
+
+	if r.action == RedirectActionRewrite {
+		if !strings.HasPrefix(r.destination, "/") {
+			return fmt.Errorf("rewrite destination must be a path")
+		}
+	} else if !strings.HasPrefix(r.destination, "/") {
+		u, err := url.Parse(r.destination)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("redirect destination must be a path or an absolute http(s) URL")
+		}
+	}
+
+	return nil
+}
+
+func (r *RedirectRule) ID() RedirectRuleID {
+	return r.id
+}
+
+func (r *RedirectRule) ApplicationID() string {
+	return r.applicationID
+}
+
+func (r *RedirectRule) Action() RedirectAction {
+	return r.action
+}
+
+func (r *RedirectRule) Match() RedirectMatch {
+	return r.match
+}
+
+func (r *RedirectRule) Source() string {
+	return r.source
+}
+
+func (r *RedirectRule) Destination() string {
+	return r.destination
+}
+
+func (r *RedirectRule) StatusCode() int {
+	return r.statusCode
+}
+
+func (r *RedirectRule) Permanent() bool {
+	return r.statusCode == 301
+}
+
+func (r *RedirectRule) Priority() int {
+	return r.priority
+}
+
+func (r *RedirectRule) Enabled() bool {
+	return r.enabled
+}
+
+func (r *RedirectRule) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+func (r *RedirectRule) UpdatedAt() time.Time {
+	return r.updatedAt
+}
+
+func (r *RedirectRule) SetEnabled(enabled bool) {
+	r.enabled = enabled
+	r.updatedAt = time.Now()
+}
+
+// Regex returns the pattern Traefik evaluates for the rule. Redirects are
+// matched against the full request URL, rewrites against the path only.
+// Exact and prefix rules keep the query string of the request.
+func (r *RedirectRule) Regex() string {
+	prefix := "^"
+	if r.action == RedirectActionRedirect {
+		prefix = urlPrefix
+	}
+
+	switch r.match {
+	case RedirectMatchExact:
+		path := regexp.QuoteMeta(strings.TrimSuffix(r.source, "/"))
+		if r.action == RedirectActionRedirect {
+			return prefix + path + `/?(\?.*)?$`
+		}
+		return prefix + path + `/?$`
+	case RedirectMatchPrefix:
+		path := regexp.QuoteMeta(strings.TrimSuffix(r.source, "/"))
+		if r.action == RedirectActionRedirect {
+			return prefix + path + `(/[^?]*)?(\?.*)?$`
+		}
+		return prefix + path + `(/.*)?$`
+	default:
+		if r.action == RedirectActionRedirect {
+			return urlPrefix + strings.TrimPrefix(r.source, "^")
+		}
+		return r.source
+	}
+}
+
+// Replacement returns the replacement Traefik substitutes for the match of Regex
+func (r *RedirectRule) Replacement() string {
+	if r.match == RedirectMatchRegex {
+		return r.destination
+	}
+
+	destination := strings.ReplaceAll(r.destination, "$", "$$")
+	switch {
+	case r.match == RedirectMatchExact && r.action == RedirectActionRedirect:
+		return destination + "${1}"
+	case r.match == RedirectMatchPrefix && r.action == RedirectActionRedirect:
+		return strings.TrimSuffix(destination, "/") + "${1}${2}"
+	case r.match == RedirectMatchPrefix:
+		return strings.TrimSuffix(destination, "/") + "${1}"
+	default:
+		return destination
+	}
+}
+
+func ReconstructRedirectRule(
+	id RedirectRuleID,
+	applicationID string,
+	action RedirectAction,
+	match RedirectMatch,
+	source, destination string,
+	statusCode, priority int,
+	enabled bool,
+	createdAt, updatedAt time.Time,
+) *RedirectRule {
+	return &RedirectRule{
+		id:            id,
+		applicationID: applicationID,
+		action:        action,
+		match:         match,
+		source:        source,
+		destination:   destination,
+		statusCode:    statusCode,
+		priority:      priority,
+		enabled:       enabled,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+// RedirectResult describes what the proxy does with a request URL
+type RedirectResult struct {
+	Matched    bool           `json:"matched"`
+	RuleID     string         `json:"rule_id,omitempty"`
+	Action     RedirectAction `json:"action,omitempty"`
+	StatusCode int            `json:"status_code,omitempty"`
+	Location   string         `json:"location,omitempty"`
+	Path       string         `json:"path"`
+	Rewrites   []string       `json:"rewrites"`
+}
+
+// EvaluateRedirects runs a request URL through the enabled rules in order, the
+// way the compiled middleware chain does: rewrites change the path seen by the
+// following rules and the first matching redirect ends the chain
+func EvaluateRedirects(rules []*RedirectRule, requestURL string) (*RedirectResult, error) {
+	u, err := url.Parse(requestURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("url must be absolute")
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	result := &RedirectResult{Path: u.Path, Rewrites: []string{}}
+
+	for _, rule := range rules {
+		if !rule.Enabled() {
+			continue
+		}
+
+		re, err := regexp.Compile(rule.Regex())
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID(), err)
+		}
+
+		if rule.Action() == RedirectActionRewrite {
+			if re.MatchString(u.Path) {
+				u.Path = re.ReplaceAllString(u.Path, rule.Replacement())
+				result.Path = u.Path
+				result.Rewrites = append(result.Rewrites, rule.ID().String())
+			}
+			continue
+		}
+
+		if full := u.String(); re.MatchString(full) {
+			result.Matched = true
+			result.RuleID = rule.ID().String()
+			result.Action = RedirectActionRedirect
+			result.StatusCode = rule.StatusCode()
+			result.Location = re.ReplaceAllString(full, rule.Replacement())
+			return result, nil
+		}
+	}
+
+	if len(result.Rewrites) > 0 {
+		result.Matched = true
+		result.RuleID = result.Rewrites[len(result.Rewrites)-1]
+		result.Action = RedirectActionRewrite
+	}
+
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+type SQLiteRouteMiddlewareRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRouteMiddlewareRepository(db *sql.DB) *SQLiteRouteMiddlewareRepository {
+	return &SQLiteRouteMiddlewareRepository{db: db}
+}
+
+func (r *SQLiteRouteMiddlewareRepository) Create(ctx context.Context, rm *proxy.RouteMiddleware) error {
+	mw := rm.Middleware()
+	config, err := json.Marshal(mw.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal middleware config: %w", err)
+	}
+
+	query := `
+		INSERT INTO route_middlewares (
+			id, application_id, domain, name, type, config, priority, enabled, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		rm.ID().String(),
+		rm.ApplicationID(),
+		rm.Domain(),
+		mw.Name(),
+		string(mw.Type()),
+		string(config),
+		rm.Priority(),
+		rm.Enabled(),
+		rm.CreatedAt(),
+		rm.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteRouteMiddlewareRepository) GetByID(ctx context.Context, id proxy.RouteMiddlewareID) (*proxy.RouteMiddleware, error) {
+	query := `
+		SELECT id, application_id, domain, name, type, config, priority, enabled, created_at, updated_at
+		FROM route_middlewares WHERE id = ?
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id.String())
+	return r.scanRouteMiddleware(row)
+}
+
+func (r *SQLiteRouteMiddlewareRepository) ListByApplication(ctx context.Context, applicationID string) ([]*proxy.RouteMiddleware, error) {
+	query := `
+		SELECT id, application_id, domain, name, type, config, priority, enabled, created_at, updated_at
+		FROM route_middlewares WHERE application_id = ? ORDER BY priority ASC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var middlewares []*proxy.RouteMiddleware
+	for rows.Next() {
+		rm, err := r.scanRouteMiddleware(rows)
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, rm)
+	}
+
+	return middlewares, rows.Err()
+}
+
+func (r *SQLiteRouteMiddlewareRepository) Update(ctx context.Context, rm *proxy.RouteMiddleware) error {
+	mw := rm.Middleware()
+	config, err := json.Marshal(mw.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal middleware config: %w", err)
+	}
+
+	query := `
+		UPDATE route_middlewares SET
+			domain = ?, name = ?, type = ?, config = ?, priority = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		rm.Domain(),
+		mw.Name(),
+		string(mw.Type()),
+		string(config),
+		rm.Priority(),
+		rm.Enabled(),
+		rm.UpdatedAt(),
+		rm.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteRouteMiddlewareRepository) Delete(ctx context.Context, id proxy.RouteMiddlewareID) error {
+	query := `DELETE FROM route_middlewares WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id.String())
+	return err
+}
+
+func (r *SQLiteRouteMiddlewareRepository) ExistsByName(ctx context.Context, applicationID, name string) (bool, error) {
+	query := `SELECT 1 FROM route_middlewares WHERE application_id = ? AND name = ?`
+	var exists int
+	err := r.db.QueryRowContext(ctx, query, applicationID, name).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func (r *SQLiteRouteMiddlewareRepository) scanRouteMiddleware(row rowScanner) (*proxy.RouteMiddleware, error) {
+	var (
+		id, applicationID, domain, name, typeStr, configJSON string
+		priority                                             int
+		enabled                                              bool
+		createdAt, updatedAt                                 time.Time
+	)
+
+	err := row.Scan(&id, &applicationID, &domain, &name, &typeStr, &configJSON, &priority, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	middlewareID, err := proxy.RouteMiddlewareIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if configJSON != "" {
+		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal middleware config: %w", err)
+		}
+	}
+
+	// Config was validated on write, so a stored middleware is trusted as-is
+	mw := proxy.ReconstructMiddlewareConfig(name, proxy.MiddlewareType(typeStr), config)
+
+	return proxy.ReconstructRouteMiddleware(
+		middlewareID,
+		applicationID,
+		domain,
+		mw,
+		priority,
+		enabled,
+		createdAt,
+		updatedAt,
+	), nil
+}
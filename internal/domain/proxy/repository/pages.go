@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+type SQLiteApplicationPageSettingsRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteApplicationPageSettingsRepository(db *sql.DB) *SQLiteApplicationPageSettingsRepository {
+	return &SQLiteApplicationPageSettingsRepository{db: db}
+}
+
+// Get returns the page settings of an application, or the defaults when none were saved
+func (r *SQLiteApplicationPageSettingsRepository) Get(ctx context.Context, applicationID string) (*proxy.ApplicationPageSettings, error) {
+	query := `
+		SELECT maintenance, error_pages, error_statuses, updated_at
+		FROM application_page_settings WHERE application_id = ?
+	`
+
+	var (
+		maintenance, errorPages bool
+		errorStatuses           string
+		updatedAt               time.Time
+	)
+
+	err := r.db.QueryRowContext(ctx, query, applicationID).Scan(&maintenance, &errorPages, &errorStatuses, &updatedAt)
+	if err == sql.ErrNoRows {
+		return proxy.NewApplicationPageSettings(applicationID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return proxy.ReconstructApplicationPageSettings(applicationID, maintenance, errorPages, errorStatuses, updatedAt), nil
+}
+
+func (r *SQLiteApplicationPageSettingsRepository) Save(ctx context.Context, settings *proxy.ApplicationPageSettings) error {
+	query := `
+		INSERT INTO application_page_settings (application_id, maintenance, error_pages, error_statuses, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(application_id) DO UPDATE SET
+			maintenance = excluded.maintenance,
+			error_pages = excluded.error_pages,
+			error_statuses = excluded.error_statuses,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		settings.ApplicationID(),
+		settings.MaintenanceEnabled(),
+		settings.ErrorPagesEnabled(),
+		settings.ErrorStatuses(),
+		settings.UpdatedAt(),
+	)
+
+	return err
+}
+
+// ListMaintenanceRoutes returns the domains of every application in maintenance mode
+func (r *SQLiteApplicationPageSettingsRepository) ListMaintenanceRoutes(ctx context.Context) ([]proxy.MaintenanceRoute, error) {
+	query := `
+		SELECT a.id, a.project_id, COALESCE(a.domain, ''), COALESCE(a.generated_domain, '')
+		FROM application_page_settings s
+		JOIN applications a ON a.id = s.application_id
+		WHERE s.maintenance = TRUE
+		ORDER BY a.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routes []proxy.MaintenanceRoute
+	for rows.Next() {
+		var applicationID, projectID, domain, generatedDomain string
+		if err := rows.Scan(&applicationID, &projectID, &domain, &generatedDomain); err != nil {
+			return nil, err
+		}
+
+		parsedProjectID, err := uuid.Parse(projectID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid project ID for application %s: %w", applicationID, err)
+		}
+
+		route := proxy.MaintenanceRoute{ApplicationID: applicationID, ProjectID: parsedProjectID}
+		for _, d := range []string{domain, generatedDomain} {
+			if d != "" {
+				route.Domains = append(route.Domains, d)
+			}
+		}
+		if len(route.Domains) > 0 {
+			routes = append(routes, route)
+		}
+	}
+
+	return routes, rows.Err()
+}
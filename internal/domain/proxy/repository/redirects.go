@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+const redirectRuleColumns = `id, application_id, action, match_type, source, destination, status_code, priority, enabled, created_at, updated_at`
+
+type SQLiteRedirectRuleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRedirectRuleRepository(db *sql.DB) *SQLiteRedirectRuleRepository {
+	return &SQLiteRedirectRuleRepository{db: db}
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (r *SQLiteRedirectRuleRepository) Create(ctx context.Context, rule *proxy.RedirectRule) error {
+	return r.insert(ctx, r.db, rule)
+}
+
+func (r *SQLiteRedirectRuleRepository) insert(ctx context.Context, db execer, rule *proxy.RedirectRule) error {
+	query := `INSERT INTO redirect_rules (` + redirectRuleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.ExecContext(ctx, query,
+		rule.ID().String(),
+		rule.ApplicationID(),
+		string(rule.Action()),
+		string(rule.Match()),
+		rule.Source(),
+		rule.Destination(),
+		rule.StatusCode(),
+		rule.Priority(),
+		rule.Enabled(),
+		rule.CreatedAt(),
+		rule.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteRedirectRuleRepository) GetByID(ctx context.Context, id proxy.RedirectRuleID) (*proxy.RedirectRule, error) {
+	query := `SELECT ` + redirectRuleColumns + ` FROM redirect_rules WHERE id = ?`
+	return r.scanRedirectRule(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteRedirectRuleRepository) ListByApplication(ctx context.Context, applicationID string) ([]*proxy.RedirectRule, error) {
+	query := `SELECT ` + redirectRuleColumns + ` FROM redirect_rules
+		WHERE application_id = ? ORDER BY priority ASC, created_at ASC, id ASC`
+	return r.list(ctx, query, applicationID)
+}
+
+// ListEnabled returns the enabled rules of every application in evaluation order
+func (r *SQLiteRedirectRuleRepository) ListEnabled(ctx context.Context) ([]*proxy.RedirectRule, error) {
+	query := `SELECT ` + redirectRuleColumns + ` FROM redirect_rules
+		WHERE enabled = TRUE ORDER BY application_id, priority ASC, created_at ASC, id ASC`
+	return r.list(ctx, query)
+}
+
+func (r *SQLiteRedirectRuleRepository) list(ctx context.Context, query string, args ...any) ([]*proxy.RedirectRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*proxy.RedirectRule
+	for rows.Next() {
+		rule, err := r.scanRedirectRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *SQLiteRedirectRuleRepository) Update(ctx context.Context, rule *proxy.RedirectRule) error {
+	query := `
+		UPDATE redirect_rules SET
+			action = ?, match_type = ?, source = ?, destination = ?, status_code = ?, priority = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		string(rule.Action()),
+		string(rule.Match()),
+		rule.Source(),
+		rule.Destination(),
+		rule.StatusCode(),
+		rule.Priority(),
+		rule.Enabled(),
+		rule.UpdatedAt(),
+		rule.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteRedirectRuleRepository) Delete(ctx context.Context, id proxy.RedirectRuleID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM redirect_rules WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteRedirectRuleRepository) Import(ctx context.Context, applicationID string, rules []*proxy.RedirectRule, replace bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if replace {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM redirect_rules WHERE application_id = ?`, applicationID); err != nil {
+			return fmt.Errorf("failed to delete existing rules: %w", err)
+		}
+	}
+
+	for _, rule := range rules {
+		if err := r.insert(ctx, tx, rule); err != nil {
+			return fmt.Errorf("failed to insert rule %s: %w", rule.Source(), err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteRedirectRuleRepository) scanRedirectRule(row rowScanner) (*proxy.RedirectRule, error) {
+	var (
+		id, applicationID, action, match, source, destination string
+		statusCode, priority                                  int
+		enabled                                               bool
+		createdAt, updatedAt                                  time.Time
+	)
+
+	err := row.Scan(&id, &applicationID, &action, &match, &source, &destination, &statusCode, &priority, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleID, err := proxy.RedirectRuleIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return proxy.ReconstructRedirectRule(
+		ruleID,
+		applicationID,
+		proxy.RedirectAction(action),
+		proxy.RedirectMatch(match),
+		source,
+		destination,
+		statusCode,
+		priority,
+		enabled,
+		createdAt,
+		updatedAt,
+	), nil
+}
@@ -29,3 +29,30 @@ type TraefikConfigRepository interface {
 	Delete(ctx context.Context, id proxy.TraefikConfigID) error
 	Exists(ctx context.Context) (bool, error)
 }
+
+type RouteMiddlewareRepository interface {
+	Create(ctx context.Context, middleware *proxy.RouteMiddleware) error
+	GetByID(ctx context.Context, id proxy.RouteMiddlewareID) (*proxy.RouteMiddleware, error)
+	ListByApplication(ctx context.Context, applicationID string) ([]*proxy.RouteMiddleware, error)
+	Update(ctx context.Context, middleware *proxy.RouteMiddleware) error
+	Delete(ctx context.Context, id proxy.RouteMiddlewareID) error
+	ExistsByName(ctx context.Context, applicationID, name string) (bool, error)
+}
+
+type ApplicationPageSettingsRepository interface {
+	Get(ctx context.Context, applicationID string) (*proxy.ApplicationPageSettings, error)
+	Save(ctx context.Context, settings *proxy.ApplicationPageSettings) error
+	ListMaintenanceRoutes(ctx context.Context) ([]proxy.MaintenanceRoute, error)
+}
+
+type RedirectRuleRepository interface {
+	Create(ctx context.Context, rule *proxy.RedirectRule) error
+	GetByID(ctx context.Context, id proxy.RedirectRuleID) (*proxy.RedirectRule, error)
+	ListByApplication(ctx context.Context, applicationID string) ([]*proxy.RedirectRule, error)
+	ListEnabled(ctx context.Context) ([]*proxy.RedirectRule, error)
+	Update(ctx context.Context, rule *proxy.RedirectRule) error
+	Delete(ctx context.Context, id proxy.RedirectRuleID) error
+	// Import stores rules in a single transaction, first removing the existing
+	// rules of the application when replace is set
+	Import(ctx context.Context, applicationID string, rules []*proxy.RedirectRule, replace bool) error
+}
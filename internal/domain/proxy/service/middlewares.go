@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+type RouteMiddlewareRequest struct {
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Domain   string                 `json:"domain,omitempty"`
+	Config   map[string]interface{} `json:"config"`
+	Priority int                    `json:"priority"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+}
+
+type RouteMiddlewareResponse struct {
+	ID            string                 `json:"id"`
+	ApplicationID string                 `json:"application_id"`
+	Domain        string                 `json:"domain"`
+	Name          string                 `json:"name"`
+	Type          string                 `json:"type"`
+	Config        map[string]interface{} `json:"config"`
+	Priority      int                    `json:"priority"`
+	Enabled       bool                   `json:"enabled"`
+	CreatedAt     string                 `json:"created_at"`
+	UpdatedAt     string                 `json:"updated_at"`
+}
+
+func (s *ProxyService) CreateRouteMiddleware(ctx context.Context, applicationID string, req RouteMiddlewareRequest) (*RouteMiddlewareResponse, error) {
+	exists, err := s.middlewareRepo.ExistsByName(ctx, applicationID, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check middleware name: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("middleware %s already exists for this application", req.Name)
+	}
+
+	mw, err := s.buildMiddlewareConfig(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rm, err := proxy.NewRouteMiddleware(applicationID, req.Domain, mw, req.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route middleware: %w", err)
+	}
+
+	if req.Enabled != nil {
+		rm.SetEnabled(*req.Enabled)
+	}
+
+	if err := s.middlewareRepo.Create(ctx, rm); err != nil {
+		return nil, fmt.Errorf("failed to create route middleware: %w", err)
+	}
+
+	return toRouteMiddlewareResponse(rm), nil
+}
+
+func (s *ProxyService) UpdateRouteMiddleware(ctx context.Context, applicationID, id string, req RouteMiddlewareRequest) (*RouteMiddlewareResponse, error) {
+	rm, err := s.getApplicationMiddleware(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := rm.Middleware()
+	if req.Name != current.Name() {
+		exists, err := s.middlewareRepo.ExistsByName(ctx, applicationID, req.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check middleware name: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("middleware %s already exists for this application", req.Name)
+		}
+	}
+
+	mw, err := s.buildMiddlewareConfig(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rm.UpdateMiddleware(mw)
+	rm.SetDomain(req.Domain)
+	rm.SetPriority(req.Priority)
+	if req.Enabled != nil {
+		rm.SetEnabled(*req.Enabled)
+	}
+
+	if err := s.middlewareRepo.Update(ctx, rm); err != nil {
+		return nil, fmt.Errorf("failed to update route middleware: %w", err)
+	}
+
+	return toRouteMiddlewareResponse(rm), nil
+}
+
+func (s *ProxyService) DeleteRouteMiddleware(ctx context.Context, applicationID, id string) error {
+	rm, err := s.getApplicationMiddleware(ctx, applicationID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.middlewareRepo.Delete(ctx, rm.ID()); err != nil {
+		return fmt.Errorf("failed to delete route middleware: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ProxyService) ListRouteMiddlewares(ctx context.Context, applicationID string) ([]*RouteMiddlewareResponse, error) {
+	middlewares, err := s.ListApplicationMiddlewares(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*RouteMiddlewareResponse, len(middlewares))
+	for i, rm := range middlewares {
+		responses[i] = toRouteMiddlewareResponse(rm)
+	}
+
+	return responses, nil
+}
+
+// ListApplicationMiddlewares returns the middlewares of an application ordered by priority
+func (s *ProxyService) ListApplicationMiddlewares(ctx context.Context, applicationID string) ([]*proxy.RouteMiddleware, error) {
+	middlewares, err := s.middlewareRepo.ListByApplication(ctx, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list route middlewares: %w", err)
+	}
+	return middlewares, nil
+}
+
+func (s *ProxyService) getApplicationMiddleware(ctx context.Context, applicationID, id string) (*proxy.RouteMiddleware, error) {
+	middlewareID, err := proxy.RouteMiddlewareIDFromString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route middleware ID: %w", err)
+	}
+
+	rm, err := s.middlewareRepo.GetByID(ctx, middlewareID)
+	if err != nil {
+		return nil, fmt.Errorf("route middleware not found: %w", err)
+	}
+
+	if rm.ApplicationID() != applicationID {
+		return nil, fmt.Errorf("route middleware not found: %s", id)
+	}
+
+	return rm, nil
+}
+
+func (s *ProxyService) buildMiddlewareConfig(req RouteMiddlewareRequest) (proxy.MiddlewareConfig, error) {
+	config := req.Config
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	middlewareType := proxy.MiddlewareType(req.Type)
+
+	if middlewareType == proxy.MiddlewareTypeAuth {
+		users, err := hashBasicAuthUsers(config["users"])
+		if err != nil {
+			return proxy.MiddlewareConfig{}, err
+		}
+		config["users"] = users
+	}
+
+	mw, err := proxy.NewMiddlewareConfig(req.Name, middlewareType, config)
+	if err != nil {
+		return proxy.MiddlewareConfig{}, fmt.Errorf("invalid middleware: %w", err)
+	}
+
+	return mw, nil
+}
+
+// hashBasicAuthUsers turns {"username", "password"} entries into htpasswd lines.
+// Entries that are already in "user:hash" form are kept as-is so that updates can
+// send back the stored users without re-hashing them.
+func hashBasicAuthUsers(raw interface{}) ([]string, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("basic auth users must be a list")
+	}
+
+	users := make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			if !strings.Contains(v, ":") {
+				return nil, fmt.Errorf("basic auth user must be in htpasswd format (user:hash)")
+			}
+			users = append(users, v)
+		case map[string]interface{}:
+			username, _ := v["username"].(string)
+			password, _ := v["password"].(string)
+			if username == "" || password == "" {
+				return nil, fmt.Errorf("basic auth users require a username and password")
+			}
+			if strings.Contains(username, ":") {
+				return nil, fmt.Errorf("basic auth username cannot contain ':'")
+			}
+
+			hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash basic auth password: %w", err)
+			}
+			users = append(users, username+":"+string(hash))
+		default:
+			return nil, fmt.Errorf("invalid basic auth user entry")
+		}
+	}
+
+	return users, nil
+}
+
+func toRouteMiddlewareResponse(rm *proxy.RouteMiddleware) *RouteMiddlewareResponse {
+	mw := rm.Middleware()
+	return &RouteMiddlewareResponse{
+		ID:            rm.ID().String(),
+		ApplicationID: rm.ApplicationID(),
+		Domain:        rm.Domain(),
+		Name:          mw.Name(),
+		Type:          string(mw.Type()),
+		Config:        mw.Config(),
+		Priority:      rm.Priority(),
+		Enabled:       rm.Enabled(),
+		CreatedAt:     rm.CreatedAt().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:     rm.UpdatedAt().Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+type ApplicationPageSettingsResponse struct {
+	ApplicationID string `json:"application_id"`
+	Maintenance   bool   `json:"maintenance"`
+	ErrorPages    bool   `json:"error_pages"`
+	ErrorStatuses string `json:"error_statuses"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// GetApplicationPageSettings returns the maintenance and error page settings of an application
+func (s *ProxyService) GetApplicationPageSettings(ctx context.Context, applicationID string) (*proxy.ApplicationPageSettings, error) {
+	settings, err := s.pageRepo.Get(ctx, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SetMaintenance toggles the maintenance mode of an application
+func (s *ProxyService) SetMaintenance(ctx context.Context, applicationID string, enabled bool) (*ApplicationPageSettingsResponse, error) {
+	settings, err := s.GetApplicationPageSettings(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.SetMaintenance(enabled)
+
+	if err := s.pageRepo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save page settings: %w", err)
+	}
+
+	return ToApplicationPageSettingsResponse(settings), nil
+}
+
+// UpdateErrorPages enables or disables the custom error pages of an application
+func (s *ProxyService) UpdateErrorPages(ctx context.Context, applicationID string, enabled bool, statuses string) (*ApplicationPageSettingsResponse, error) {
+	settings, err := s.GetApplicationPageSettings(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := settings.SetErrorPages(enabled, statuses); err != nil {
+		return nil, fmt.Errorf("invalid error page settings: %w", err)
+	}
+
+	if err := s.pageRepo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save page settings: %w", err)
+	}
+
+	return ToApplicationPageSettingsResponse(settings), nil
+}
+
+// ListMaintenanceRoutes returns the domains of every application in maintenance mode
+func (s *ProxyService) ListMaintenanceRoutes(ctx context.Context) ([]proxy.MaintenanceRoute, error) {
+	routes, err := s.pageRepo.ListMaintenanceRoutes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance routes: %w", err)
+	}
+	return routes, nil
+}
+
+func ToApplicationPageSettingsResponse(settings *proxy.ApplicationPageSettings) *ApplicationPageSettingsResponse {
+	return &ApplicationPageSettingsResponse{
+		ApplicationID: settings.ApplicationID(),
+		Maintenance:   settings.MaintenanceEnabled(),
+		ErrorPages:    settings.ErrorPagesEnabled(),
+		ErrorStatuses: settings.ErrorStatuses(),
+		UpdatedAt:     settings.UpdatedAt().Format("2006-01-02T15:04:05Z"),
+	}
+}
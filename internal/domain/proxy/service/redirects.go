@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+// MaxRedirectImportRows caps the number of rules accepted in a single CSV import
+const MaxRedirectImportRows = 10000
+
+// redirectCSVHeader is the column layout of exported files. Imports match
+// columns by name, so only source and destination are required.
+var redirectCSVHeader = []string{"action", "match", "source", "destination", "status_code", "priority", "enabled"}
+
+type RedirectRuleRequest struct {
+	Action      string `json:"action"`
+	Match       string `json:"match"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Priority    int    `json:"priority"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+type RedirectRuleResponse struct {
+	ID            string `json:"id"`
+	ApplicationID string `json:"application_id"`
+	Action        string `json:"action"`
+	Match         string `json:"match"`
+	Source        string `json:"source"`
+	Destination   string `json:"destination"`
+	StatusCode    int    `json:"status_code,omitempty"`
+	Priority      int    `json:"priority"`
+	Enabled       bool   `json:"enabled"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// RedirectImportError reports an invalid line of an imported CSV file
+type RedirectImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// RedirectImportResult is returned by ImportRedirectRules. Nothing is stored
+// when Errors is not empty.
+type RedirectImportResult struct {
+	Imported int                   `json:"imported"`
+	Errors   []RedirectImportError `json:"errors,omitempty"`
+}
+
+func (s *ProxyService) CreateRedirectRule(ctx context.Context, applicationID string, req RedirectRuleRequest) (*RedirectRuleResponse, error) {
+	rule, err := newRedirectRule(applicationID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redirectRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create redirect rule: %w", err)
+	}
+
+	return toRedirectRuleResponse(rule), nil
+}
+
+func (s *ProxyService) UpdateRedirectRule(ctx context.Context, applicationID, id string, req RedirectRuleRequest) (*RedirectRuleResponse, error) {
+	rule, err := s.getApplicationRedirectRule(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rule.Update(proxy.RedirectAction(req.Action), proxy.RedirectMatch(req.Match), req.Source, req.Destination, req.StatusCode, req.Priority); err != nil {
+		return nil, fmt.Errorf("invalid redirect rule: %w", err)
+	}
+	if req.Enabled != nil {
+		rule.SetEnabled(*req.Enabled)
+	}
+
+	if err := s.redirectRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update redirect rule: %w", err)
+	}
+
+	return toRedirectRuleResponse(rule), nil
+}
+
+func (s *ProxyService) DeleteRedirectRule(ctx context.Context, applicationID, id string) error {
+	rule, err := s.getApplicationRedirectRule(ctx, applicationID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.redirectRepo.Delete(ctx, rule.ID()); err != nil {
+		return fmt.Errorf("failed to delete redirect rule: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ProxyService) ListRedirectRules(ctx context.Context, applicationID string) ([]*RedirectRuleResponse, error) {
+	rules, err := s.redirectRepo.ListByApplication(ctx, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redirect rules: %w", err)
+	}
+
+	responses := make([]*RedirectRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toRedirectRuleResponse(rule)
+	}
+
+	return responses, nil
+}
+
+// HasApplicationRedirects reports whether the application has enabled rules,
+// i.e. whether its router must reference the compiled redirect chain
+func (s *ProxyService) HasApplicationRedirects(ctx context.Context, applicationID string) (bool, error) {
+	rules, err := s.redirectRepo.ListByApplication(ctx, applicationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list redirect rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if rule.Enabled() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ListEnabledRedirectRules returns the enabled rules of every application, in
+// evaluation order, for compiling into the proxy configuration
+func (s *ProxyService) ListEnabledRedirectRules(ctx context.Context) ([]*proxy.RedirectRule, error) {
+	rules, err := s.redirectRepo.ListEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redirect rules: %w", err)
+	}
+	return rules, nil
+}
+
+// TestRedirect shows which rule a request URL hits and where it ends up
+func (s *ProxyService) TestRedirect(ctx context.Context, applicationID, requestURL string) (*proxy.RedirectResult, error) {
+	rules, err := s.redirectRepo.ListByApplication(ctx, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redirect rules: %w", err)
+	}
+
+	return proxy.EvaluateRedirects(rules, requestURL)
+}
+
+// ImportRedirectRules reads rules from CSV. Every line is validated before any
+// rule is stored; replace removes the existing rules of the application first.
+func (s *ProxyService) ImportRedirectRules(ctx context.Context, applicationID string, r io.Reader, replace bool) (*RedirectImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"source", "destination"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header must contain a %q column", required)
+		}
+	}
+
+	result := &RedirectImportResult{}
+	var rules []*proxy.RedirectRule
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			result.Errors = append(result.Errors, RedirectImportError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		if len(rules)+len(result.Errors) >= MaxRedirectImportRows {
+			return nil, fmt.Errorf("csv file exceeds %d rules", MaxRedirectImportRows)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req := RedirectRuleRequest{
+			Action:      field("action"),
+			Match:       field("match"),
+			Source:      field("source"),
+			Destination: field("destination"),
+		}
+		if req.Action == "" {
+			req.Action = string(proxy.RedirectActionRedirect)
+		}
+
+		if req.StatusCode, err = parseOptionalInt(field("status_code")); err != nil {
+			result.Errors = append(result.Errors, RedirectImportError{Line: line, Error: "invalid status_code"})
+			continue
+		}
+		if req.Priority, err = parseOptionalInt(field("priority")); err != nil {
+			result.Errors = append(result.Errors, RedirectImportError{Line: line, Error: "invalid priority"})
+			continue
+		}
+		if enabled := field("enabled"); enabled != "" {
+			value, err := strconv.ParseBool(enabled)
+			if err != nil {
+				result.Errors = append(result.Errors, RedirectImportError{Line: line, Error: "invalid enabled"})
+				continue
+			}
+			req.Enabled = &value
+		}
+
+		rule, err := newRedirectRule(applicationID, req)
+		if err != nil {
+			result.Errors = append(result.Errors, RedirectImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if err := s.redirectRepo.Import(ctx, applicationID, rules, replace); err != nil {
+		return nil, fmt.Errorf("failed to import redirect rules: %w", err)
+	}
+
+	result.Imported = len(rules)
+	return result, nil
+}
+
+// ExportRedirectRules writes the rules of an application as CSV
+func (s *ProxyService) ExportRedirectRules(ctx context.Context, applicationID string, w io.Writer) error {
+	rules, err := s.redirectRepo.ListByApplication(ctx, applicationID)
+	if err != nil {
+		return fmt.Errorf("failed to list redirect rules: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(redirectCSVHeader); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		statusCode := ""
+		if rule.StatusCode() != 0 {
+			statusCode = strconv.Itoa(rule.StatusCode())
+		}
+
+		if err := writer.Write([]string{
+			string(rule.Action()),
+			string(rule.Match()),
+			rule.Source(),
+			rule.Destination(),
+			statusCode,
+			strconv.Itoa(rule.Priority()),
+			strconv.FormatBool(rule.Enabled()),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *ProxyService) getApplicationRedirectRule(ctx context.Context, applicationID, id string) (*proxy.RedirectRule, error) {
+	ruleID, err := proxy.RedirectRuleIDFromString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect rule ID: %w", err)
+	}
+
+	rule, err := s.redirectRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("redirect rule not found: %w", err)
+	}
+
+	if rule.ApplicationID() != applicationID {
+		return nil, fmt.Errorf("redirect rule not found: %s", id)
+	}
+
+	return rule, nil
+}
+
+func newRedirectRule(applicationID string, req RedirectRuleRequest) (*proxy.RedirectRule, error) {
+	rule, err := proxy.NewRedirectRule(
+		applicationID,
+		proxy.RedirectAction(req.Action),
+		proxy.RedirectMatch(req.Match),
+		req.Source,
+		req.Destination,
+		req.StatusCode,
+		req.Priority,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect rule: %w", err)
+	}
+
+	if req.Enabled != nil {
+		rule.SetEnabled(*req.Enabled)
+	}
+
+	return rule, nil
+}
+
+func parseOptionalInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func toRedirectRuleResponse(rule *proxy.RedirectRule) *RedirectRuleResponse {
+	return &RedirectRuleResponse{
+		ID:            rule.ID().String(),
+		ApplicationID: rule.ApplicationID(),
+		Action:        string(rule.Action()),
+		Match:         string(rule.Match()),
+		Source:        rule.Source(),
+		Destination:   rule.Destination(),
+		StatusCode:    rule.StatusCode(),
+		Priority:      rule.Priority(),
+		Enabled:       rule.Enabled(),
+		CreatedAt:     rule.CreatedAt().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:     rule.UpdatedAt().Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -7,20 +7,30 @@ import (
 	"github.com/google/uuid"
 	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
 	"github.com/mikrocloud/mikrocloud/internal/domain/proxy/repository"
+	proxyContainers "github.com/mikrocloud/mikrocloud/pkg/containers/proxy"
 )
 
 type ProxyService struct {
-	proxyRepo   repository.ProxyRepository
-	traefikRepo repository.TraefikConfigRepository
+	proxyRepo      repository.ProxyRepository
+	traefikRepo    repository.TraefikConfigRepository
+	middlewareRepo repository.RouteMiddlewareRepository
+	pageRepo       repository.ApplicationPageSettingsRepository
+	redirectRepo   repository.RedirectRuleRepository
 }
 
 func New(
 	proxyRepo repository.ProxyRepository,
 	traefikRepo repository.TraefikConfigRepository,
+	middlewareRepo repository.RouteMiddlewareRepository,
+	pageRepo repository.ApplicationPageSettingsRepository,
+	redirectRepo repository.RedirectRuleRepository,
 ) *ProxyService {
 	return &ProxyService{
-		proxyRepo:   proxyRepo,
-		traefikRepo: traefikRepo,
+		proxyRepo:      proxyRepo,
+		traefikRepo:    traefikRepo,
+		middlewareRepo: middlewareRepo,
+		pageRepo:       pageRepo,
+		redirectRepo:   redirectRepo,
 	}
 }
 
@@ -147,6 +157,12 @@ func (s *ProxyService) CreateProxyConfig(ctx context.Context, req CreateProxyCon
 
 	config.SetStripPrefix(req.StripPrefix)
 
+	middlewares, err := s.buildMiddlewareConfigs(req.Middlewares)
+	if err != nil {
+		return nil, err
+	}
+	config.SetMiddlewares(middlewares)
+
 	if req.TLS != nil {
 		tls := &proxy.TLSConfig{
 			// Note: TLSConfig fields are unexported, so we'd need to add constructors
@@ -230,6 +246,14 @@ func (s *ProxyService) UpdateProxyConfig(ctx context.Context, id string, req Cre
 
 	config.SetStripPrefix(req.StripPrefix)
 
+	if req.Middlewares != nil {
+		middlewares, err := s.buildMiddlewareConfigs(req.Middlewares)
+		if err != nil {
+			return nil, err
+		}
+		config.SetMiddlewares(middlewares)
+	}
+
 	if err := s.proxyRepo.Update(ctx, config); err != nil {
 		return nil, fmt.Errorf("failed to update proxy config: %w", err)
 	}
@@ -266,13 +290,15 @@ func (s *ProxyService) GenerateTraefikConfig(ctx context.Context) (map[string]in
 
 	traefikConfig := map[string]interface{}{
 		"http": map[string]interface{}{
-			"routers":  make(map[string]interface{}),
-			"services": make(map[string]interface{}),
+			"routers":     make(map[string]interface{}),
+			"services":    make(map[string]interface{}),
+			"middlewares": make(map[string]interface{}),
 		},
 	}
 
 	routers := traefikConfig["http"].(map[string]interface{})["routers"].(map[string]interface{})
 	services := traefikConfig["http"].(map[string]interface{})["services"].(map[string]interface{})
+	middlewares := traefikConfig["http"].(map[string]interface{})["middlewares"].(map[string]interface{})
 
 	for _, config := range configs {
 		routerName := config.GetRouterName()
@@ -292,6 +318,21 @@ func (s *ProxyService) GenerateTraefikConfig(ctx context.Context) (map[string]in
 			router["tls"] = map[string]interface{}{}
 		}
 
+		if len(config.Middlewares()) > 0 {
+			names := make([]string, 0, len(config.Middlewares()))
+			for _, mw := range config.Middlewares() {
+				middleware, err := proxyContainers.BuildMiddleware(mw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid middleware %s for router %s: %w", mw.Name(), routerName, err)
+				}
+
+				name := proxyContainers.MiddlewareName(routerName, mw.Name())
+				middlewares[name] = middleware
+				names = append(names, name)
+			}
+			router["middlewares"] = names
+		}
+
 		routers[routerName] = router
 
 		service := map[string]interface{}{
@@ -318,6 +359,22 @@ func (s *ProxyService) GenerateTraefikConfig(ctx context.Context) (map[string]in
 	return traefikConfig, nil
 }
 
+func (s *ProxyService) buildMiddlewareConfigs(reqs []MiddlewareConfigRequest) ([]proxy.MiddlewareConfig, error) {
+	middlewares := make([]proxy.MiddlewareConfig, 0, len(reqs))
+	for _, req := range reqs {
+		mw, err := s.buildMiddlewareConfig(RouteMiddlewareRequest{
+			Name:   req.Name,
+			Type:   req.Type,
+			Config: req.Config,
+		})
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, mw)
+	}
+	return middlewares, nil
+}
+
 func (s *ProxyService) toProxyConfigResponse(config *proxy.ProxyConfig) *ProxyConfigResponse {
 	response := &ProxyConfigResponse{
 		ID:           config.ID().String(),
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/api/middleware"
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	databaseService "github.com/mikrocloud/mikrocloud/internal/domain/databases/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries"
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type QueryHandler struct {
+	queryService *service.QueryService
+	dbService    *databaseService.DatabaseService
+	validator    *validator.Validate
+}
+
+func NewQueryHandler(queryService *service.QueryService, dbService *databaseService.DatabaseService) *QueryHandler {
+	return &QueryHandler{
+		queryService: queryService,
+		dbService:    dbService,
+		validator:    validator.New(),
+	}
+}
+
+type SavedQueryRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description,omitempty" validate:"max=1000"`
+	Query       string `json:"query" validate:"required"`
+	Shared      bool   `json:"shared"`
+}
+
+type HistoryEntryResponse struct {
+	ID           string    `json:"id"`
+	Query        string    `json:"query"`
+	ReadOnly     bool      `json:"read_only"`
+	Status       string    `json:"status"`
+	DurationMs   int64     `json:"duration_ms"`
+	RowCount     int64     `json:"row_count"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	ExecutedAt   time.Time `json:"executed_at"`
+}
+
+type SavedQueryResponse struct {
+	ID          string    `json:"id"`
+	DatabaseID  string    `json:"database_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Query       string    `json:"query"`
+	Shared      bool      `json:"shared"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ListHistoryResponse struct {
+	Entries []HistoryEntryResponse `json:"entries"`
+}
+
+type ListSavedQueriesResponse struct {
+	Queries []SavedQueryResponse `json:"queries"`
+}
+
+func toHistoryEntryResponse(entry *queries.QueryHistoryEntry) HistoryEntryResponse {
+	return HistoryEntryResponse{
+		ID:           entry.ID().String(),
+		Query:        entry.Query(),
+		ReadOnly:     entry.ReadOnly(),
+		Status:       string(entry.Status()),
+		DurationMs:   entry.Duration().Milliseconds(),
+		RowCount:     entry.RowCount(),
+		ErrorMessage: entry.ErrorMessage(),
+		ExecutedAt:   entry.ExecutedAt(),
+	}
+}
+
+func toSavedQueryResponse(saved *queries.SavedQuery) SavedQueryResponse {
+	return SavedQueryResponse{
+		ID:          saved.ID().String(),
+		DatabaseID:  saved.DatabaseID(),
+		Name:        saved.Name(),
+		Description: saved.Description(),
+		Query:       saved.Query(),
+		Shared:      saved.Shared(),
+		CreatedBy:   saved.CreatedBy(),
+		CreatedAt:   saved.CreatedAt(),
+		UpdatedAt:   saved.UpdatedAt(),
+	}
+}
+
+// ListHistory returns the queries the current user ran on a database, newest
+// first
+func (h *QueryHandler) ListHistory(w http.ResponseWriter, r *http.Request) {
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := h.queryService.ListHistory(r.Context(), db.ID().String(), userID, limit, offset)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list query history")
+		return
+	}
+
+	response := ListHistoryResponse{Entries: make([]HistoryEntryResponse, 0, len(entries))}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, toHistoryEntryResponse(entry))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *QueryHandler) ClearHistory(w http.ResponseWriter, r *http.Request) {
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.queryService.ClearHistory(r.Context(), db.ID().String(), userID); err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "delete_failed", "Failed to clear query history")
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Query history cleared"})
+}
+
+func (h *QueryHandler) ListSavedQueries(w http.ResponseWriter, r *http.Request) {
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.queryService.ListSavedQueries(r.Context(), db.ID().String(), userID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list saved queries")
+		return
+	}
+
+	response := ListSavedQueriesResponse{Queries: make([]SavedQueryResponse, 0, len(saved))}
+	for _, q := range saved {
+		response.Queries = append(response.Queries, toSavedQueryResponse(q))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *QueryHandler) CreateSavedQuery(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeSavedQueryRequest(w, r)
+	if !ok {
+		return
+	}
+
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.queryService.SaveQuery(r.Context(), service.SaveQueryCommand{
+		DatabaseID:  db.ID().String(),
+		ProjectID:   db.ProjectID(),
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Query:       req.Query,
+		Shared:      req.Shared,
+	})
+	if err != nil {
+		h.sendSavedQueryError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toSavedQueryResponse(saved))
+}
+
+func (h *QueryHandler) GetSavedQuery(w http.ResponseWriter, r *http.Request) {
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.queryService.GetSavedQuery(r.Context(), db.ID().String(), userID, chi.URLParam(r, "saved_query_id"))
+	if err != nil {
+		h.sendSavedQueryError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toSavedQueryResponse(saved))
+}
+
+func (h *QueryHandler) UpdateSavedQuery(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeSavedQueryRequest(w, r)
+	if !ok {
+		return
+	}
+
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.queryService.UpdateSavedQuery(r.Context(), db.ID().String(), userID, chi.URLParam(r, "saved_query_id"),
+		req.Name, req.Description, req.Query, req.Shared)
+	if err != nil {
+		h.sendSavedQueryError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toSavedQueryResponse(saved))
+}
+
+func (h *QueryHandler) DeleteSavedQuery(w http.ResponseWriter, r *http.Request) {
+	db, userID, ok := h.getDatabase(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.queryService.DeleteSavedQuery(r.Context(), db.ID().String(), userID, chi.URLParam(r, "saved_query_id")); err != nil {
+		h.sendSavedQueryError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, map[string]string{"message": "Saved query deleted"})
+}
+
+func (h *QueryHandler) decodeSavedQueryRequest(w http.ResponseWriter, r *http.Request) (SavedQueryRequest, bool) {
+	var req SavedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return req, false
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return req, false
+	}
+
+	return req, true
+}
+
+func (h *QueryHandler) sendSavedQueryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSavedQueryNotFound):
+		utils.SendError(w, http.StatusNotFound, "saved_query_not_found", "Saved query not found")
+	case errors.Is(err, service.ErrSavedQueryExists):
+		utils.SendError(w, http.StatusConflict, "saved_query_exists", err.Error())
+	case errors.Is(err, service.ErrNotQueryOwner):
+		utils.SendError(w, http.StatusForbidden, "not_query_owner", err.Error())
+	default:
+		utils.SendError(w, http.StatusBadRequest, "saved_query_failed", err.Error())
+	}
+}
+
+// getDatabase resolves the database of the request and the current user
+func (h *QueryHandler) getDatabase(w http.ResponseWriter, r *http.Request) (*databases.Database, string, bool) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.SendError(w, http.StatusUnauthorized, "unauthorized", "User not authenticated")
+		return nil, "", false
+	}
+
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return nil, "", false
+	}
+
+	databaseID, err := databases.DatabaseIDFromString(chi.URLParam(r, "database_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_database_id", "Invalid database ID")
+		return nil, "", false
+	}
+
+	db, err := h.dbService.GetDatabase(r.Context(), databaseID)
+	if err != nil || db.ProjectID() != projectID {
+		utils.SendError(w, http.StatusNotFound, "database_not_found", "Database not found")
+		return nil, "", false
+	}
+
+	return db, userID, true
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+)
+
+// RegisterQueryRoutes registers the query history and saved query routes of
+// the database studio
+func RegisterQueryRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewQueryHandler(deps.QueryService, deps.DatabaseService)
+
+	r.Get("/history", handler.ListHistory)
+	r.Delete("/history", handler.ClearHistory)
+	r.Route("/saved-queries", func(r chi.Router) {
+		r.Get("/", handler.ListSavedQueries)
+		r.Post("/", handler.CreateSavedQuery)
+		r.Get("/{saved_query_id}", handler.GetSavedQuery)
+		r.Put("/{saved_query_id}", handler.UpdateSavedQuery)
+		r.Delete("/{saved_query_id}", handler.DeleteSavedQuery)
+	})
+}
@@ -0,0 +1,279 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type QueryHistoryID struct {
+	value string
+}
+
+func NewQueryHistoryID() QueryHistoryID {
+	return QueryHistoryID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func QueryHistoryIDFromString(s string) (QueryHistoryID, error) {
+	if s == "" {
+		return QueryHistoryID{}, fmt.Errorf("query history ID cannot be empty")
+	}
+	return QueryHistoryID{value: s}, nil
+}
+
+func (id QueryHistoryID) String() string {
+	return id.value
+}
+
+type SavedQueryID struct {
+	value string
+}
+
+func NewSavedQueryID() SavedQueryID {
+	return SavedQueryID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func SavedQueryIDFromString(s string) (SavedQueryID, error) {
+	if s == "" {
+		return SavedQueryID{}, fmt.Errorf("saved query ID cannot be empty")
+	}
+	return SavedQueryID{value: s}, nil
+}
+
+func (id SavedQueryID) String() string {
+	return id.value
+}
+
+type QueryStatus string
+
+const (
+	QueryStatusSucceeded QueryStatus = "succeeded"
+	QueryStatusFailed    QueryStatus = "failed"
+	QueryStatusCancelled QueryStatus = "cancelled"
+)
+
+// QueryHistoryEntry records a query a user ran against a database from the
+// studio
+type QueryHistoryEntry struct {
+	id           QueryHistoryID
+	databaseID   string
+	userID       string
+	query        string
+	readOnly     bool
+	status       QueryStatus
+	duration     time.Duration
+	rowCount     int64
+	errorMessage string
+	executedAt   time.Time
+}
+
+func NewQueryHistoryEntry(databaseID, userID, query string, readOnly bool, status QueryStatus, duration time.Duration, rowCount int64, errorMessage string) *QueryHistoryEntry {
+	return &QueryHistoryEntry{
+		id:           NewQueryHistoryID(),
+		databaseID:   databaseID,
+		userID:       userID,
+		query:        query,
+		readOnly:     readOnly,
+		status:       status,
+		duration:     duration,
+		rowCount:     rowCount,
+		errorMessage: errorMessage,
+		executedAt:   time.Now().Add(-duration),
+	}
+}
+
+func (e *QueryHistoryEntry) ID() QueryHistoryID {
+	return e.id
+}
+
+func (e *QueryHistoryEntry) DatabaseID() string {
+	return e.databaseID
+}
+
+func (e *QueryHistoryEntry) UserID() string {
+	return e.userID
+}
+
+func (e *QueryHistoryEntry) Query() string {
+	return e.query
+}
+
+func (e *QueryHistoryEntry) ReadOnly() bool {
+	return e.readOnly
+}
+
+func (e *QueryHistoryEntry) Status() QueryStatus {
+	return e.status
+}
+
+func (e *QueryHistoryEntry) Duration() time.Duration {
+	return e.duration
+}
+
+func (e *QueryHistoryEntry) RowCount() int64 {
+	return e.rowCount
+}
+
+func (e *QueryHistoryEntry) ErrorMessage() string {
+	return e.errorMessage
+}
+
+func (e *QueryHistoryEntry) ExecutedAt() time.Time {
+	return e.executedAt
+}
+
+// SavedQuery is a named query kept for a database. It is private to the user
+// who saved it unless shared with the project.
+type SavedQuery struct {
+	id          SavedQueryID
+	databaseID  string
+	projectID   uuid.UUID
+	name        string
+	description string
+	query       string
+	shared      bool
+	createdBy   string
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func NewSavedQuery(databaseID string, projectID uuid.UUID, createdBy, name, description, query string, shared bool) (*SavedQuery, error) {
+	if databaseID == "" {
+		return nil, fmt.Errorf("database ID cannot be empty")
+	}
+	if createdBy == "" {
+		return nil, fmt.Errorf("creator cannot be empty")
+	}
+
+	now := time.Now()
+	saved := &SavedQuery{
+		id:         NewSavedQueryID(),
+		databaseID: databaseID,
+		projectID:  projectID,
+		createdBy:  createdBy,
+		createdAt:  now,
+		updatedAt:  now,
+	}
+
+	if err := saved.Update(name, description, query, shared); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (q *SavedQuery) ID() SavedQueryID {
+	return q.id
+}
+
+func (q *SavedQuery) DatabaseID() string {
+	return q.databaseID
+}
+
+func (q *SavedQuery) ProjectID() uuid.UUID {
+	return q.projectID
+}
+
+func (q *SavedQuery) Name() string {
+	return q.name
+}
+
+func (q *SavedQuery) Description() string {
+	return q.description
+}
+
+func (q *SavedQuery) Query() string {
+	return q.query
+}
+
+func (q *SavedQuery) Shared() bool {
+	return q.shared
+}
+
+func (q *SavedQuery) CreatedBy() string {
+	return q.createdBy
+}
+
+func (q *SavedQuery) CreatedAt() time.Time {
+	return q.createdAt
+}
+
+func (q *SavedQuery) UpdatedAt() time.Time {
+	return q.updatedAt
+}
+
+func (q *SavedQuery) Update(name, description, query string, shared bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("saved query name cannot be empty")
+	}
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("saved query cannot be empty")
+	}
+
+	q.name = name
+	q.description = description
+	q.query = query
+	q.shared = shared
+	q.updatedAt = time.Now()
+	return nil
+}
+
+// VisibleTo reports whether a user may see and run the query
+func (q *SavedQuery) VisibleTo(userID string) bool {
+	return q.shared || q.createdBy == userID
+}
+
+func ReconstructQueryHistoryEntry(
+	id QueryHistoryID,
+	databaseID string,
+	userID string,
+	query string,
+	readOnly bool,
+	status QueryStatus,
+	duration time.Duration,
+	rowCount int64,
+	errorMessage string,
+	executedAt time.Time,
+) *QueryHistoryEntry {
+	return &QueryHistoryEntry{
+		id:           id,
+		databaseID:   databaseID,
+		userID:       userID,
+		query:        query,
+		readOnly:     readOnly,
+		status:       status,
+		duration:     duration,
+		rowCount:     rowCount,
+		errorMessage: errorMessage,
+		executedAt:   executedAt,
+	}
+}
+
+func ReconstructSavedQuery(
+	id SavedQueryID,
+	databaseID string,
+	projectID uuid.UUID,
+	name string,
+	description string,
+	query string,
+	shared bool,
+	createdBy string,
+	createdAt time.Time,
+	updatedAt time.Time,
+) *SavedQuery {
+	return &SavedQuery{
+		id:          id,
+		databaseID:  databaseID,
+		projectID:   projectID,
+		name:        name,
+		description: description,
+		query:       query,
+		shared:      shared,
+		createdBy:   createdBy,
+		createdAt:   createdAt,
+		updatedAt:   updatedAt,
+	}
+}
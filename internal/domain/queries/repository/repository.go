@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries"
+)
+
+type QueryHistoryRepository interface {
+	Create(ctx context.Context, entry *queries.QueryHistoryEntry) error
+	// ListByUser returns the newest entries first
+	ListByUser(ctx context.Context, databaseID, userID string, limit, offset int) ([]*queries.QueryHistoryEntry, error)
+	// Prune deletes all but the newest keep entries of a user on a database
+	Prune(ctx context.Context, databaseID, userID string, keep int) error
+	DeleteByUser(ctx context.Context, databaseID, userID string) error
+}
+
+type SavedQueryRepository interface {
+	Create(ctx context.Context, saved *queries.SavedQuery) error
+	GetByID(ctx context.Context, id queries.SavedQueryID) (*queries.SavedQuery, error)
+	// ListVisible returns the queries of a database a user saved or that are
+	// shared with the project, ordered by name
+	ListVisible(ctx context.Context, databaseID, userID string) ([]*queries.SavedQuery, error)
+	ExistsByName(ctx context.Context, databaseID, userID, name string, excludeID queries.SavedQueryID) (bool, error)
+	Update(ctx context.Context, saved *queries.SavedQuery) error
+	Delete(ctx context.Context, id queries.SavedQueryID) error
+}
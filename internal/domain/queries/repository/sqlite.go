@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries"
+)
+
+const queryHistoryColumns = `id, database_id, user_id, query, read_only, status, duration_ms, row_count, error_message, executed_at`
+
+const savedQueryColumns = `id, database_id, project_id, name, description, query, shared, created_by, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type SQLiteQueryHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteQueryHistoryRepository(db *sql.DB) *SQLiteQueryHistoryRepository {
+	return &SQLiteQueryHistoryRepository{db: db}
+}
+
+func (r *SQLiteQueryHistoryRepository) Create(ctx context.Context, entry *queries.QueryHistoryEntry) error {
+	query := `INSERT INTO query_history (` + queryHistoryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID().String(),
+		entry.DatabaseID(),
+		entry.UserID(),
+		entry.Query(),
+		entry.ReadOnly(),
+		string(entry.Status()),
+		entry.Duration().Milliseconds(),
+		entry.RowCount(),
+		entry.ErrorMessage(),
+		entry.ExecutedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteQueryHistoryRepository) ListByUser(ctx context.Context, databaseID, userID string, limit, offset int) ([]*queries.QueryHistoryEntry, error) {
+	query := `SELECT ` + queryHistoryColumns + ` FROM query_history WHERE database_id = ? AND user_id = ? ORDER BY executed_at DESC, id DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, databaseID, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*queries.QueryHistoryEntry
+	for rows.Next() {
+		entry, err := r.scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *SQLiteQueryHistoryRepository) Prune(ctx context.Context, databaseID, userID string, keep int) error {
+	query := `
+		DELETE FROM query_history
+		WHERE database_id = ? AND user_id = ? AND id NOT IN (
+			SELECT id FROM query_history
+			WHERE database_id = ? AND user_id = ?
+			ORDER BY executed_at DESC, id DESC
+			LIMIT ?
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, databaseID, userID, databaseID, userID, keep)
+	return err
+}
+
+func (r *SQLiteQueryHistoryRepository) DeleteByUser(ctx context.Context, databaseID, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM query_history WHERE database_id = ? AND user_id = ?`, databaseID, userID)
+	return err
+}
+
+func (r *SQLiteQueryHistoryRepository) scanEntry(row rowScanner) (*queries.QueryHistoryEntry, error) {
+	var (
+		id, databaseID, userID, queryText, status, errorMessage string
+		readOnly                                                bool
+		durationMs, rowCount                                    int64
+		executedAt                                              time.Time
+	)
+
+	err := row.Scan(&id, &databaseID, &userID, &queryText, &readOnly, &status, &durationMs, &rowCount, &errorMessage, &executedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	entryID, err := queries.QueryHistoryIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return queries.ReconstructQueryHistoryEntry(
+		entryID,
+		databaseID,
+		userID,
+		queryText,
+		readOnly,
+		queries.QueryStatus(status),
+		time.Duration(durationMs)*time.Millisecond,
+		rowCount,
+		errorMessage,
+		executedAt,
+	), nil
+}
+
+type SQLiteSavedQueryRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSavedQueryRepository(db *sql.DB) *SQLiteSavedQueryRepository {
+	return &SQLiteSavedQueryRepository{db: db}
+}
+
+func (r *SQLiteSavedQueryRepository) Create(ctx context.Context, saved *queries.SavedQuery) error {
+	query := `INSERT INTO saved_queries (` + savedQueryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		saved.ID().String(),
+		saved.DatabaseID(),
+		saved.ProjectID().String(),
+		saved.Name(),
+		saved.Description(),
+		saved.Query(),
+		saved.Shared(),
+		saved.CreatedBy(),
+		saved.CreatedAt(),
+		saved.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteSavedQueryRepository) GetByID(ctx context.Context, id queries.SavedQueryID) (*queries.SavedQuery, error) {
+	query := `SELECT ` + savedQueryColumns + ` FROM saved_queries WHERE id = ?`
+	return r.scanSavedQuery(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteSavedQueryRepository) ListVisible(ctx context.Context, databaseID, userID string) ([]*queries.SavedQuery, error) {
+	query := `SELECT ` + savedQueryColumns + ` FROM saved_queries WHERE database_id = ? AND (shared = TRUE OR created_by = ?) ORDER BY name ASC, created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, databaseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saved []*queries.SavedQuery
+	for rows.Next() {
+		q, err := r.scanSavedQuery(rows)
+		if err != nil {
+			return nil, err
+		}
+		saved = append(saved, q)
+	}
+
+	return saved, rows.Err()
+}
+
+func (r *SQLiteSavedQueryRepository) ExistsByName(ctx context.Context, databaseID, userID, name string, excludeID queries.SavedQueryID) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM saved_queries WHERE database_id = ? AND created_by = ? AND name = ? AND id != ?`
+	err := r.db.QueryRowContext(ctx, query, databaseID, userID, name, excludeID.String()).Scan(&count)
+	return count > 0, err
+}
+
+func (r *SQLiteSavedQueryRepository) Update(ctx context.Context, saved *queries.SavedQuery) error {
+	query := `UPDATE saved_queries SET name = ?, description = ?, query = ?, shared = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query,
+		saved.Name(),
+		saved.Description(),
+		saved.Query(),
+		saved.Shared(),
+		saved.UpdatedAt(),
+		saved.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteSavedQueryRepository) Delete(ctx context.Context, id queries.SavedQueryID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM saved_queries WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteSavedQueryRepository) scanSavedQuery(row rowScanner) (*queries.SavedQuery, error) {
+	var (
+		id, databaseID, projectIDStr, name, description, queryText, createdBy string
+		shared                                                                bool
+		createdAt, updatedAt                                                  time.Time
+	)
+
+	err := row.Scan(&id, &databaseID, &projectIDStr, &name, &description, &queryText, &shared, &createdBy, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	savedID, err := queries.SavedQueryIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return queries.ReconstructSavedQuery(
+		savedID,
+		databaseID,
+		projectID,
+		name,
+		description,
+		queryText,
+		shared,
+		createdBy,
+		createdAt,
+		updatedAt,
+	), nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries"
+	"github.com/mikrocloud/mikrocloud/internal/domain/queries/repository"
+)
+
+// historyLimit is how many queries are kept per user and database
+const historyLimit = 500
+
+var (
+	ErrSavedQueryNotFound = errors.New("saved query not found")
+	ErrSavedQueryExists   = errors.New("a saved query with this name already exists")
+	// ErrNotQueryOwner is returned when a user changes a query shared with them
+	ErrNotQueryOwner = errors.New("only the user who saved a query can change it")
+)
+
+// QueryService keeps the studio query history of each user and the queries
+// saved for a database
+type QueryService struct {
+	historyRepo repository.QueryHistoryRepository
+	savedRepo   repository.SavedQueryRepository
+}
+
+func NewQueryService(historyRepo repository.QueryHistoryRepository, savedRepo repository.SavedQueryRepository) *QueryService {
+	return &QueryService{
+		historyRepo: historyRepo,
+		savedRepo:   savedRepo,
+	}
+}
+
+// RecordQuery adds a query to the history of a user and drops their oldest
+// entries beyond historyLimit. Failures are logged rather than returned so
+// they never fail the query itself.
+func (s *QueryService) RecordQuery(ctx context.Context, entry *queries.QueryHistoryEntry) {
+	if err := s.historyRepo.Create(ctx, entry); err != nil {
+		slog.Error("Failed to record query history", "database_id", entry.DatabaseID(), "error", err)
+		return
+	}
+	if err := s.historyRepo.Prune(ctx, entry.DatabaseID(), entry.UserID(), historyLimit); err != nil {
+		slog.Error("Failed to prune query history", "database_id", entry.DatabaseID(), "error", err)
+	}
+}
+
+func (s *QueryService) ListHistory(ctx context.Context, databaseID, userID string, limit, offset int) ([]*queries.QueryHistoryEntry, error) {
+	if limit <= 0 || limit > historyLimit {
+		limit = historyLimit
+	}
+
+	entries, err := s.historyRepo.ListByUser(ctx, databaseID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list query history: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *QueryService) ClearHistory(ctx context.Context, databaseID, userID string) error {
+	if err := s.historyRepo.DeleteByUser(ctx, databaseID, userID); err != nil {
+		return fmt.Errorf("failed to clear query history: %w", err)
+	}
+	return nil
+}
+
+type SaveQueryCommand struct {
+	DatabaseID  string
+	ProjectID   uuid.UUID
+	UserID      string
+	Name        string
+	Description string
+	Query       string
+	Shared      bool
+}
+
+func (s *QueryService) SaveQuery(ctx context.Context, cmd SaveQueryCommand) (*queries.SavedQuery, error) {
+	saved, err := queries.NewSavedQuery(cmd.DatabaseID, cmd.ProjectID, cmd.UserID, cmd.Name, cmd.Description, cmd.Query, cmd.Shared)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.savedRepo.ExistsByName(ctx, cmd.DatabaseID, cmd.UserID, saved.Name(), saved.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check saved query name: %w", err)
+	}
+	if exists {
+		return nil, ErrSavedQueryExists
+	}
+
+	if err := s.savedRepo.Create(ctx, saved); err != nil {
+		return nil, fmt.Errorf("failed to save query: %w", err)
+	}
+	return saved, nil
+}
+
+// GetSavedQuery returns a saved query of a database if the user may see it
+func (s *QueryService) GetSavedQuery(ctx context.Context, databaseID, userID, savedQueryID string) (*queries.SavedQuery, error) {
+	id, err := queries.SavedQueryIDFromString(savedQueryID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.savedRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrSavedQueryNotFound
+	}
+	if saved.DatabaseID() != databaseID || !saved.VisibleTo(userID) {
+		return nil, ErrSavedQueryNotFound
+	}
+
+	return saved, nil
+}
+
+func (s *QueryService) ListSavedQueries(ctx context.Context, databaseID, userID string) ([]*queries.SavedQuery, error) {
+	saved, err := s.savedRepo.ListVisible(ctx, databaseID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved queries: %w", err)
+	}
+	return saved, nil
+}
+
+func (s *QueryService) UpdateSavedQuery(ctx context.Context, databaseID, userID, savedQueryID, name, description, query string, shared bool) (*queries.SavedQuery, error) {
+	saved, err := s.GetSavedQuery(ctx, databaseID, userID, savedQueryID)
+	if err != nil {
+		return nil, err
+	}
+	if saved.CreatedBy() != userID {
+		return nil, ErrNotQueryOwner
+	}
+
+	if err := saved.Update(name, description, query, shared); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.savedRepo.ExistsByName(ctx, databaseID, userID, saved.Name(), saved.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check saved query name: %w", err)
+	}
+	if exists {
+		return nil, ErrSavedQueryExists
+	}
+
+	if err := s.savedRepo.Update(ctx, saved); err != nil {
+		return nil, fmt.Errorf("failed to update saved query: %w", err)
+	}
+	return saved, nil
+}
+
+func (s *QueryService) DeleteSavedQuery(ctx context.Context, databaseID, userID, savedQueryID string) error {
+	saved, err := s.GetSavedQuery(ctx, databaseID, userID, savedQueryID)
+	if err != nil {
+		return err
+	}
+	if saved.CreatedBy() != userID {
+		return ErrNotQueryOwner
+	}
+
+	if err := s.savedRepo.Delete(ctx, saved.ID()); err != nil {
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type TelemetryHandler struct {
+	telemetryService *service.TelemetryService
+	projectService   *projectsService.ProjectService
+	validator        *validator.Validate
+}
+
+func NewTelemetryHandler(telemetryService *service.TelemetryService, projectService *projectsService.ProjectService) *TelemetryHandler {
+	return &TelemetryHandler{
+		telemetryService: telemetryService,
+		projectService:   projectService,
+		validator:        validator.New(),
+	}
+}
+
+type CreateTokenRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type TokenResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Token is only returned in full when the token is created
+	Token      string     `json:"token"`
+	Managed    bool       `json:"managed"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ListTokensResponse struct {
+	Enabled  bool            `json:"enabled"`
+	Endpoint string          `json:"endpoint"`
+	Tokens   []TokenResponse `json:"tokens"`
+}
+
+type SpanEventResponse struct {
+	Name       string            `json:"name"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type SpanResponse struct {
+	SpanID        string               `json:"span_id"`
+	ParentSpanID  string               `json:"parent_span_id,omitempty"`
+	ServiceID     *string              `json:"service_id,omitempty"`
+	DeploymentID  *string              `json:"deployment_id,omitempty"`
+	ServiceName   string               `json:"service_name"`
+	Name          string               `json:"name"`
+	Kind          telemetry.SpanKind   `json:"kind"`
+	Status        telemetry.SpanStatus `json:"status"`
+	StatusMessage string               `json:"status_message,omitempty"`
+	StartTime     time.Time            `json:"start_time"`
+	DurationMs    float64              `json:"duration_ms"`
+	Attributes    map[string]string    `json:"attributes"`
+	Resource      map[string]string    `json:"resource"`
+	Events        []SpanEventResponse  `json:"events"`
+}
+
+type TraceResponse struct {
+	TraceID    string         `json:"trace_id"`
+	StartTime  time.Time      `json:"start_time"`
+	DurationMs float64        `json:"duration_ms"`
+	SpanCount  int            `json:"span_count"`
+	Services   []string       `json:"services"`
+	Spans      []SpanResponse `json:"spans"`
+}
+
+func toTokenResponse(token *telemetry.IngestToken, reveal bool) TokenResponse {
+	value := token.MaskedToken()
+	if reveal {
+		value = token.Token()
+	}
+
+	return TokenResponse{
+		ID:         token.ID().String(),
+		Name:       token.Name(),
+		Token:      value,
+		Managed:    token.Managed(),
+		LastUsedAt: token.LastUsedAt(),
+		CreatedAt:  token.CreatedAt(),
+	}
+}
+
+func toSpanResponse(span *telemetry.Span) SpanResponse {
+	response := SpanResponse{
+		SpanID:        span.SpanID,
+		ParentSpanID:  span.ParentSpanID,
+		ServiceName:   span.ServiceName,
+		Name:          span.Name,
+		Kind:          span.Kind,
+		Status:        span.Status,
+		StatusMessage: span.StatusMessage,
+		StartTime:     span.StartTime,
+		DurationMs:    float64(span.Duration) / float64(time.Millisecond),
+		Attributes:    span.Attributes,
+		Resource:      span.Resource,
+		Events:        make([]SpanEventResponse, 0, len(span.Events)),
+	}
+
+	if span.ServiceID != nil {
+		serviceID := span.ServiceID.String()
+		response.ServiceID = &serviceID
+	}
+	if span.DeploymentID != nil {
+		deploymentID := span.DeploymentID.String()
+		response.DeploymentID = &deploymentID
+	}
+
+	for _, event := range span.Events {
+		response.Events = append(response.Events, SpanEventResponse(event))
+	}
+
+	return response
+}
+
+// ListTokens returns the ingest tokens of a project, masked, along with the
+// endpoint to export to
+func (h *TelemetryHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.telemetryService.ListTokens(r.Context(), projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list ingest tokens")
+		return
+	}
+
+	response := ListTokensResponse{
+		Enabled:  h.telemetryService.Enabled(),
+		Endpoint: h.telemetryService.Endpoint(),
+		Tokens:   make([]TokenResponse, 0, len(tokens)),
+	}
+	for _, token := range tokens {
+		response.Tokens = append(response.Tokens, toTokenResponse(token, false))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+// CreateToken creates an ingest token, the only response holding it in full
+func (h *TelemetryHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	token, err := h.telemetryService.CreateToken(r.Context(), projectID, req.Name)
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create ingest token: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toTokenResponse(token, true))
+}
+
+func (h *TelemetryHandler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.telemetryService.DeleteToken(r.Context(), projectID, chi.URLParam(r, "token_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrace returns the spans of a trace in start order with a summary of the
+// whole trace
+func (h *TelemetryHandler) GetTrace(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	spans, err := h.telemetryService.GetTrace(r.Context(), projectID, chi.URLParam(r, "trace_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	start := spans[0].StartTime
+	end := spans[0].EndTime()
+	services := []string{}
+	response := TraceResponse{
+		TraceID:   spans[0].TraceID,
+		SpanCount: len(spans),
+		Spans:     make([]SpanResponse, 0, len(spans)),
+	}
+
+	for _, span := range spans {
+		if span.EndTime().After(end) {
+			end = span.EndTime()
+		}
+		if span.ServiceName != "" && !slices.Contains(services, span.ServiceName) {
+			services = append(services, span.ServiceName)
+		}
+		response.Spans = append(response.Spans, toSpanResponse(span))
+	}
+
+	response.StartTime = start
+	response.DurationMs = float64(end.Sub(start)) / float64(time.Millisecond)
+	response.Services = services
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *TelemetryHandler) getProject(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return uuid.Nil, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return uuid.Nil, false
+	}
+
+	return projectID, true
+}
+
+func (h *TelemetryHandler) sendLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrTokenNotFound):
+		utils.SendError(w, http.StatusNotFound, "token_not_found", "Ingest token not found")
+	case errors.Is(err, service.ErrTraceNotFound):
+		utils.SendError(w, http.StatusNotFound, "trace_not_found", "Trace not found")
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
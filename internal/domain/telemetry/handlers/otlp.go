@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+// maxExportSize caps the uncompressed body of an OTLP export
+const maxExportSize = 16 << 20
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// otlpIDFields are encoded as hex in OTLP/JSON where protojson expects the
+// base64 of other bytes fields
+var otlpIDFields = map[string]bool{
+	"traceId":        true,
+	"spanId":         true,
+	"parentSpanId":   true,
+	"trace_id":       true,
+	"span_id":        true,
+	"parent_span_id": true,
+}
+
+// OTLPHandler receives traces, metrics and logs exported over OTLP/HTTP in
+// the binary protobuf or JSON encoding
+type OTLPHandler struct {
+	telemetryService *service.TelemetryService
+}
+
+func NewOTLPHandler(telemetryService *service.TelemetryService) *OTLPHandler {
+	return &OTLPHandler{telemetryService: telemetryService}
+}
+
+func (h *OTLPHandler) ExportTraces(w http.ResponseWriter, r *http.Request) {
+	token, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var req coltracepb.ExportTraceServiceRequest
+	contentType, ok := decodeExport(w, r, &req)
+	if !ok {
+		return
+	}
+
+	rejected, err := h.telemetryService.IngestTraces(r.Context(), token, &req)
+	if err != nil {
+		slog.Error("Failed to ingest OTLP traces", "project_id", token.ProjectID(), "error", err)
+		utils.SendError(w, http.StatusServiceUnavailable, "ingest_failed", "Failed to store spans")
+		return
+	}
+
+	response := &coltracepb.ExportTraceServiceResponse{}
+	if rejected > 0 {
+		response.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			RejectedSpans: rejected,
+			ErrorMessage:  "spans without a valid trace or span ID were dropped",
+		}
+	}
+
+	writeExportResponse(w, contentType, response)
+}
+
+func (h *OTLPHandler) ExportMetrics(w http.ResponseWriter, r *http.Request) {
+	token, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var req colmetricspb.ExportMetricsServiceRequest
+	contentType, ok := decodeExport(w, r, &req)
+	if !ok {
+		return
+	}
+
+	rejected, err := h.telemetryService.IngestMetrics(r.Context(), token, &req)
+	if err != nil {
+		slog.Error("Failed to ingest OTLP metrics", "project_id", token.ProjectID(), "error", err)
+		utils.SendError(w, http.StatusServiceUnavailable, "ingest_failed", "Failed to store metrics")
+		return
+	}
+
+	response := &colmetricspb.ExportMetricsServiceResponse{}
+	if rejected > 0 {
+		response.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       "data points with an invalid metric name or type were dropped",
+		}
+	}
+
+	writeExportResponse(w, contentType, response)
+}
+
+func (h *OTLPHandler) ExportLogs(w http.ResponseWriter, r *http.Request) {
+	token, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var req collogspb.ExportLogsServiceRequest
+	contentType, ok := decodeExport(w, r, &req)
+	if !ok {
+		return
+	}
+
+	rejected, err := h.telemetryService.IngestLogs(r.Context(), token, &req)
+	if err != nil {
+		slog.Error("Failed to ingest OTLP logs", "project_id", token.ProjectID(), "error", err)
+		utils.SendError(w, http.StatusServiceUnavailable, "ingest_failed", "Failed to store logs")
+		return
+	}
+
+	response := &collogspb.ExportLogsServiceResponse{}
+	if rejected > 0 {
+		response.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: rejected,
+			ErrorMessage:       "log records without a body were dropped",
+		}
+	}
+
+	writeExportResponse(w, contentType, response)
+}
+
+// authenticate resolves the ingest token of the Authorization bearer header
+func (h *OTLPHandler) authenticate(w http.ResponseWriter, r *http.Request) (*telemetry.IngestToken, bool) {
+	value, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || value == "" {
+		utils.SendError(w, http.StatusUnauthorized, "missing_token", "An ingest token is required")
+		return nil, false
+	}
+
+	token, err := h.telemetryService.Authenticate(r.Context(), strings.TrimSpace(value))
+	if errors.Is(err, service.ErrInvalidToken) {
+		utils.SendError(w, http.StatusUnauthorized, "invalid_token", "Invalid ingest token")
+		return nil, false
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusServiceUnavailable, "internal_error", "Failed to verify ingest token")
+		return nil, false
+	}
+
+	return token, true
+}
+
+// decodeExport reads an export request in the encoding of its content type,
+// which the response uses as well
+func decodeExport(w http.ResponseWriter, r *http.Request, msg proto.Message) (string, bool) {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		utils.SendError(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/x-protobuf or application/json")
+		return "", false
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxExportSize)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, "invalid_body", "Invalid gzip body")
+			return "", false
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxExportSize+1))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.SendError(w, http.StatusRequestEntityTooLarge, "body_too_large", "Export exceeds 16 MiB")
+			return "", false
+		}
+		utils.SendError(w, http.StatusBadRequest, "invalid_body", "Failed to read export")
+		return "", false
+	}
+	if len(data) > maxExportSize {
+		utils.SendError(w, http.StatusRequestEntityTooLarge, "body_too_large", "Export exceeds 16 MiB")
+		return "", false
+	}
+
+	if contentType == contentTypeProtobuf {
+		err = proto.Unmarshal(data, msg)
+	} else {
+		err = unmarshalExportJSON(data, msg)
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_export", "Invalid OTLP export: "+err.Error())
+		return "", false
+	}
+
+	return contentType, true
+}
+
+// unmarshalExportJSON decodes OTLP/JSON, converting its hex trace and span IDs
+// to base64 first. Numbers are kept verbatim so nanosecond timestamps do not
+// lose precision on the way.
+func unmarshalExportJSON(data []byte, msg proto.Message) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	convertOTLPIDs(value)
+
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(normalized, msg)
+}
+
+func convertOTLPIDs(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if id, ok := item.(string); ok && otlpIDFields[key] {
+				if raw, err := hex.DecodeString(id); err == nil {
+					v[key] = base64.StdEncoding.EncodeToString(raw)
+				}
+				continue
+			}
+			convertOTLPIDs(item)
+		}
+	case []any:
+		for _, item := range v {
+			convertOTLPIDs(item)
+		}
+	}
+}
+
+func writeExportResponse(w http.ResponseWriter, contentType string, response proto.Message) {
+	var data []byte
+	var err error
+	if contentType == contentTypeProtobuf {
+		data, err = proto.Marshal(response)
+	} else {
+		data, err = protojson.Marshal(response)
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", "Failed to encode response")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+)
+
+// RegisterOTLPRoutes registers the OTLP/HTTP receiver. Exports authenticate
+// with an ingest token rather than a user session.
+func RegisterOTLPRoutes(r chi.Router, deps *deps.Dependencies) {
+	if !deps.TelemetryService.Enabled() {
+		return
+	}
+
+	handler := NewOTLPHandler(deps.TelemetryService)
+
+	r.Route("/otlp/v1", func(r chi.Router) {
+		r.Post("/traces", handler.ExportTraces)
+		r.Post("/metrics", handler.ExportMetrics)
+		r.Post("/logs", handler.ExportLogs)
+	})
+}
+
+// RegisterTelemetryRoutes registers the ingest token and trace routes of a
+// project
+func RegisterTelemetryRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewTelemetryHandler(deps.TelemetryService, deps.ProjectService)
+
+	r.Route("/telemetry/tokens", func(r chi.Router) {
+		r.Get("/", handler.ListTokens)
+		r.Post("/", handler.CreateToken)
+		r.Delete("/{token_id}", handler.DeleteToken)
+	})
+	r.Get("/traces/{trace_id}", handler.GetTrace)
+}
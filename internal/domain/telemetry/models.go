@@ -0,0 +1,247 @@
+package telemetry
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// TokenPrefix starts every ingest token so leaked ones are easy to spot
+	TokenPrefix = "mkt_"
+
+	// ManagedTokenName is the name of the token injected into the application
+	// containers of a project
+	ManagedTokenName = "deployments"
+)
+
+// Resource attributes set on the telemetry of application containers, they
+// attribute received logs, metrics and spans to the application
+const (
+	AttributeProjectID    = "mikrocloud.project.id"
+	AttributeAppID        = "mikrocloud.app.id"
+	AttributeDeploymentID = "mikrocloud.deployment.id"
+	AttributeServiceName  = "service.name"
+)
+
+type IngestTokenID struct {
+	value string
+}
+
+func NewIngestTokenID() IngestTokenID {
+	return IngestTokenID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func IngestTokenIDFromString(s string) (IngestTokenID, error) {
+	if s == "" {
+		return IngestTokenID{}, fmt.Errorf("ingest token ID cannot be empty")
+	}
+	return IngestTokenID{value: s}, nil
+}
+
+func (id IngestTokenID) String() string {
+	return id.value
+}
+
+// IngestToken authenticates OTLP exports of a project. Everything sent with
+// it is stored under the project, whatever the resource attributes say. Only
+// a SHA-256 hash of the token is stored, the token itself is known when it is
+// created.
+type IngestToken struct {
+	id          IngestTokenID
+	projectID   uuid.UUID
+	name        string
+	token       string
+	tokenHash   string
+	tokenPrefix string
+	managed     bool
+	lastUsedAt  *time.Time
+	createdAt   time.Time
+}
+
+// NewIngestToken creates a token for a user to configure exporters with
+func NewIngestToken(projectID uuid.UUID, name string) (*IngestToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("token name cannot be empty")
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return newIngestToken(NewIngestTokenID(), projectID, name, TokenPrefix+hex.EncodeToString(secret), false), nil
+}
+
+// NewManagedIngestToken creates the token injected into the application
+// containers of a project. It is derived from its ID and the secret so that
+// it can be injected again on the next deployment, see ManagedTokenValue.
+func NewManagedIngestToken(projectID uuid.UUID, secret []byte) *IngestToken {
+	id := NewIngestTokenID()
+	return newIngestToken(id, projectID, ManagedTokenName, ManagedTokenValue(id, secret), true)
+}
+
+func newIngestToken(id IngestTokenID, projectID uuid.UUID, name, token string, managed bool) *IngestToken {
+	return &IngestToken{
+		id:          id,
+		projectID:   projectID,
+		name:        name,
+		token:       token,
+		tokenHash:   HashIngestToken(token),
+		tokenPrefix: token[:min(len(token), len(TokenPrefix)+4)],
+		managed:     managed,
+		createdAt:   time.Now(),
+	}
+}
+
+// ManagedTokenValue returns the value of a managed token, an HMAC-SHA256 of
+// its ID keyed with the secret
+func ManagedTokenValue(id IngestTokenID, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id.value))
+	return TokenPrefix + hex.EncodeToString(mac.Sum(nil)[:24])
+}
+
+// HashIngestToken returns the hash a token is stored and looked up by
+func HashIngestToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *IngestToken) ID() IngestTokenID {
+	return t.id
+}
+
+func (t *IngestToken) ProjectID() uuid.UUID {
+	return t.projectID
+}
+
+func (t *IngestToken) Name() string {
+	return t.name
+}
+
+// Token returns the token of a token just created, it is empty for the ones
+// read from the repository
+func (t *IngestToken) Token() string {
+	return t.token
+}
+
+func (t *IngestToken) TokenHash() string {
+	return t.tokenHash
+}
+
+// TokenPrefix returns the start of the token, kept to tell tokens apart
+func (t *IngestToken) TokenPrefix() string {
+	return t.tokenPrefix
+}
+
+// MaskedToken returns the token with all but its start hidden, for listings
+func (t *IngestToken) MaskedToken() string {
+	return t.tokenPrefix + strings.Repeat("*", 8)
+}
+
+// Managed reports whether the token was created for the application
+// containers rather than by a user
+func (t *IngestToken) Managed() bool {
+	return t.managed
+}
+
+func (t *IngestToken) LastUsedAt() *time.Time {
+	return t.lastUsedAt
+}
+
+func (t *IngestToken) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+func ReconstructIngestToken(
+	id IngestTokenID,
+	projectID uuid.UUID,
+	name string,
+	tokenHash string,
+	tokenPrefix string,
+	managed bool,
+	lastUsedAt *time.Time,
+	createdAt time.Time,
+) *IngestToken {
+	return &IngestToken{
+		id:          id,
+		projectID:   projectID,
+		name:        name,
+		tokenHash:   tokenHash,
+		tokenPrefix: tokenPrefix,
+		managed:     managed,
+		lastUsedAt:  lastUsedAt,
+		createdAt:   createdAt,
+	}
+}
+
+// SpanKind mirrors the OTLP span kinds
+type SpanKind string
+
+const (
+	SpanKindUnspecified SpanKind = "unspecified"
+	SpanKindInternal    SpanKind = "internal"
+	SpanKindServer      SpanKind = "server"
+	SpanKindClient      SpanKind = "client"
+	SpanKindProducer    SpanKind = "producer"
+	SpanKindConsumer    SpanKind = "consumer"
+)
+
+// SpanStatus mirrors the OTLP span status codes
+type SpanStatus string
+
+const (
+	SpanStatusUnset SpanStatus = "unset"
+	SpanStatusOK    SpanStatus = "ok"
+	SpanStatusError SpanStatus = "error"
+)
+
+// SpanEvent is a timestamped annotation of a span, such as an exception
+type SpanEvent struct {
+	Name       string            `json:"name"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Span is a single operation of a trace received over OTLP. Trace and span
+// IDs are lowercase hex, as shown by tracing SDKs and other backends.
+type Span struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	ProjectID     uuid.UUID
+	ServiceID     *uuid.UUID
+	DeploymentID  *uuid.UUID
+	ServiceName   string
+	Name          string
+	Kind          SpanKind
+	Status        SpanStatus
+	StatusMessage string
+	StartTime     time.Time
+	Duration      time.Duration
+	Attributes    map[string]string
+	Resource      map[string]string
+	Events        []SpanEvent
+}
+
+// EndTime returns when the span finished
+func (s *Span) EndTime() time.Time {
+	return s.StartTime.Add(s.Duration)
+}
+
+// ValidTraceID reports whether id is a 32 character hex trace ID
+func ValidTraceID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+)
+
+const spanColumns = `trace_id, span_id, parent_span_id, project_id, service_id, deployment_id, service_name, name, kind, status, status_message, start_time, duration_ns, attributes, resource, events`
+
+// spanInsertBatch keeps batched inserts under the bound parameter limit of SQLite
+const spanInsertBatch = 500
+
+// AnalyticsSpanRepository implements SpanRepository using the SQLite or DuckDB
+// analytics database. Start times are stored in unix nanoseconds.
+type AnalyticsSpanRepository struct {
+	db *sql.DB
+}
+
+// NewAnalyticsSpanRepository creates a new analytics-based span repository
+func NewAnalyticsSpanRepository(db *sql.DB) *AnalyticsSpanRepository {
+	return &AnalyticsSpanRepository{db: db}
+}
+
+// CreateBatch inserts spans with one statement per batch
+func (r *AnalyticsSpanRepository) CreateBatch(ctx context.Context, spans []*telemetry.Span) error {
+	for start := 0; start < len(spans); start += spanInsertBatch {
+		end := min(start+spanInsertBatch, len(spans))
+		if err := r.insert(ctx, spans[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *AnalyticsSpanRepository) insert(ctx context.Context, spans []*telemetry.Span) error {
+	values := make([]string, 0, len(spans))
+	args := make([]any, 0, len(spans)*16)
+	for _, span := range spans {
+		row, err := spanRow(span, span.StartTime.UnixNano())
+		if err != nil {
+			return err
+		}
+
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, row...)
+	}
+
+	query := `INSERT INTO spans (` + spanColumns + `) VALUES ` + strings.Join(values, ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert spans: %w", err)
+	}
+
+	return nil
+}
+
+// ListByTrace returns the spans of a trace in start order
+func (r *AnalyticsSpanRepository) ListByTrace(ctx context.Context, projectID uuid.UUID, traceID string) ([]*telemetry.Span, error) {
+	query := `SELECT ` + spanColumns + ` FROM spans WHERE project_id = ? AND trace_id = ? ORDER BY start_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID.String(), traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spans: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []*telemetry.Span
+	for rows.Next() {
+		var startTime int64
+		span, err := scanSpan(rows, &startTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan span: %w", err)
+		}
+		span.StartTime = time.Unix(0, startTime)
+		spans = append(spans, span)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return spans, nil
+}
+
+// DeleteOlderThan removes the spans started before the cutoff
+func (r *AnalyticsSpanRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM spans WHERE start_time < ?`, cutoff.UnixNano()); err != nil {
+		return fmt.Errorf("failed to delete old spans: %w", err)
+	}
+
+	return nil
+}
+
+// spanRow returns the values of spanColumns for a span, startTime is the start
+// time in the representation of the database
+func spanRow(span *telemetry.Span, startTime any) ([]any, error) {
+	attributes, err := json.Marshal(span.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal span attributes: %w", err)
+	}
+
+	resource, err := json.Marshal(span.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal span resource: %w", err)
+	}
+
+	events := span.Events
+	if events == nil {
+		events = []telemetry.SpanEvent{}
+	}
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal span events: %w", err)
+	}
+
+	return []any{
+		span.TraceID,
+		span.SpanID,
+		span.ParentSpanID,
+		span.ProjectID.String(),
+		uuidString(span.ServiceID),
+		uuidString(span.DeploymentID),
+		span.ServiceName,
+		span.Name,
+		string(span.Kind),
+		string(span.Status),
+		span.StatusMessage,
+		startTime,
+		span.Duration.Nanoseconds(),
+		string(attributes),
+		string(resource),
+		string(eventsJSON),
+	}, nil
+}
+
+// scanSpan scans a row of spanColumns, the start time into startTime whose
+// type depends on the database
+func scanSpan(row rowScanner, startTime any) (*telemetry.Span, error) {
+	var (
+		traceID, spanID, parentSpanID, projectIDStr string
+		serviceIDStr, deploymentIDStr               sql.NullString
+		serviceName, name, kind, status, statusMsg  string
+		durationNs                                  int64
+		attributesJSON, resourceJSON, eventsJSON    string
+	)
+
+	err := row.Scan(
+		&traceID, &spanID, &parentSpanID, &projectIDStr, &serviceIDStr, &deploymentIDStr,
+		&serviceName, &name, &kind, &status, &statusMsg, startTime, &durationNs,
+		&attributesJSON, &resourceJSON, &eventsJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	span := &telemetry.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		ParentSpanID:  parentSpanID,
+		ProjectID:     projectID,
+		ServiceID:     parseOptionalUUID(serviceIDStr.String),
+		DeploymentID:  parseOptionalUUID(deploymentIDStr.String),
+		ServiceName:   serviceName,
+		Name:          name,
+		Kind:          telemetry.SpanKind(kind),
+		Status:        telemetry.SpanStatus(status),
+		StatusMessage: statusMsg,
+		Duration:      time.Duration(durationNs),
+	}
+
+	if err := json.Unmarshal([]byte(attributesJSON), &span.Attributes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal span attributes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(resourceJSON), &span.Resource); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal span resource: %w", err)
+	}
+	if err := json.Unmarshal([]byte(eventsJSON), &span.Events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal span events: %w", err)
+	}
+
+	return span, nil
+}
+
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func parseOptionalUUID(s string) *uuid.UUID {
+	if s == "" {
+		return nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+)
+
+// ClickHouseSpanRepository implements SpanRepository using a ClickHouse
+// analytics database. Old spans also expire through the table TTL.
+type ClickHouseSpanRepository struct {
+	db *sql.DB
+}
+
+// NewClickHouseSpanRepository creates a new ClickHouse-based span repository
+func NewClickHouseSpanRepository(db *sql.DB) *ClickHouseSpanRepository {
+	return &ClickHouseSpanRepository{db: db}
+}
+
+// CreateBatch inserts spans as one insert block
+func (r *ClickHouseSpanRepository) CreateBatch(ctx context.Context, spans []*telemetry.Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO spans (`+spanColumns+`)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, span := range spans {
+		row, err := spanRow(span, span.StartTime)
+		if err != nil {
+			return err
+		}
+
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("failed to append span: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to insert spans: %w", err)
+	}
+
+	return nil
+}
+
+// ListByTrace returns the spans of a trace in start order
+func (r *ClickHouseSpanRepository) ListByTrace(ctx context.Context, projectID uuid.UUID, traceID string) ([]*telemetry.Span, error) {
+	query := `SELECT ` + spanColumns + ` FROM spans WHERE project_id = ? AND trace_id = ? ORDER BY start_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID.String(), traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spans: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []*telemetry.Span
+	for rows.Next() {
+		var startTime time.Time
+		span, err := scanSpan(rows, &startTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan span: %w", err)
+		}
+		span.StartTime = startTime
+		spans = append(spans, span)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return spans, nil
+}
+
+// DeleteOlderThan removes the spans started before the cutoff
+func (r *ClickHouseSpanRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
+	query := "DELETE FROM spans WHERE start_time < fromUnixTimestamp64Nano(toInt64(?))"
+
+	if _, err := r.db.ExecContext(ctx, query, cutoff.UnixNano()); err != nil {
+		return fmt.Errorf("failed to delete old spans: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+)
+
+type IngestTokenRepository interface {
+	Create(ctx context.Context, token *telemetry.IngestToken) error
+	GetByID(ctx context.Context, id telemetry.IngestTokenID) (*telemetry.IngestToken, error)
+	// GetByTokenHash returns the token with the hash, see telemetry.HashIngestToken
+	GetByTokenHash(ctx context.Context, tokenHash string) (*telemetry.IngestToken, error)
+	// GetManaged returns the token injected into the containers of a project
+	GetManaged(ctx context.Context, projectID uuid.UUID) (*telemetry.IngestToken, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*telemetry.IngestToken, error)
+	TouchLastUsed(ctx context.Context, id telemetry.IngestTokenID, usedAt time.Time) error
+	Delete(ctx context.Context, id telemetry.IngestTokenID) error
+	// HashLegacyTokens hashes the tokens stored before tokens were hashed and
+	// returns how many there were
+	HashLegacyTokens(ctx context.Context) (int, error)
+}
+
+type SpanRepository interface {
+	CreateBatch(ctx context.Context, spans []*telemetry.Span) error
+	// ListByTrace returns the spans of a trace in start order
+	ListByTrace(ctx context.Context, projectID uuid.UUID, traceID string) ([]*telemetry.Span, error)
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) error
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+)
+
+const ingestTokenColumns = `id, project_id, name, token_hash, token_prefix, managed, last_used_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type SQLiteIngestTokenRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteIngestTokenRepository(db *sql.DB) *SQLiteIngestTokenRepository {
+	return &SQLiteIngestTokenRepository{db: db}
+}
+
+func (r *SQLiteIngestTokenRepository) Create(ctx context.Context, token *telemetry.IngestToken) error {
+	query := `INSERT INTO ingest_tokens (` + ingestTokenColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID().String(),
+		token.ProjectID().String(),
+		token.Name(),
+		token.TokenHash(),
+		token.TokenPrefix(),
+		token.Managed(),
+		token.LastUsedAt(),
+		token.CreatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteIngestTokenRepository) GetByID(ctx context.Context, id telemetry.IngestTokenID) (*telemetry.IngestToken, error) {
+	query := `SELECT ` + ingestTokenColumns + ` FROM ingest_tokens WHERE id = ?`
+	return r.scanToken(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteIngestTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*telemetry.IngestToken, error) {
+	query := `SELECT ` + ingestTokenColumns + ` FROM ingest_tokens WHERE token_hash = ?`
+	return r.scanToken(r.db.QueryRowContext(ctx, query, tokenHash))
+}
+
+func (r *SQLiteIngestTokenRepository) GetManaged(ctx context.Context, projectID uuid.UUID) (*telemetry.IngestToken, error) {
+	query := `SELECT ` + ingestTokenColumns + ` FROM ingest_tokens WHERE project_id = ? AND managed = TRUE ORDER BY created_at DESC LIMIT 1`
+	return r.scanToken(r.db.QueryRowContext(ctx, query, projectID.String()))
+}
+
+func (r *SQLiteIngestTokenRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*telemetry.IngestToken, error) {
+	query := `SELECT ` + ingestTokenColumns + ` FROM ingest_tokens WHERE project_id = ? ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*telemetry.IngestToken
+	for rows.Next() {
+		token, err := r.scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *SQLiteIngestTokenRepository) TouchLastUsed(ctx context.Context, id telemetry.IngestTokenID, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE ingest_tokens SET last_used_at = ? WHERE id = ?`, usedAt, id.String())
+	return err
+}
+
+func (r *SQLiteIngestTokenRepository) Delete(ctx context.Context, id telemetry.IngestTokenID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ingest_tokens WHERE id = ?`, id.String())
+	return err
+}
+
+// HashLegacyTokens replaces the tokens stored before they were hashed with
+// their hash. Hashes are hex, so stored values starting with the token prefix
+// are tokens.
+func (r *SQLiteIngestTokenRepository) HashLegacyTokens(ctx context.Context) (int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, token_hash FROM ingest_tokens WHERE substr(token_hash, 1, ?) = ?`,
+		len(telemetry.TokenPrefix), telemetry.TokenPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	legacy := make(map[string]string)
+	for rows.Next() {
+		var id, token string
+		if err := rows.Scan(&id, &token); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		legacy[id] = token
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, token := range legacy {
+		if _, err := r.db.ExecContext(ctx, `UPDATE ingest_tokens SET token_hash = ? WHERE id = ?`, telemetry.HashIngestToken(token), id); err != nil {
+			return 0, fmt.Errorf("failed to hash ingest token %s: %w", id, err)
+		}
+	}
+
+	return len(legacy), nil
+}
+
+func (r *SQLiteIngestTokenRepository) scanToken(row rowScanner) (*telemetry.IngestToken, error) {
+	var (
+		id, projectIDStr, name, tokenHash, tokenPrefix string
+		managed                                        bool
+		lastUsedAt                                     sql.NullTime
+		createdAt                                      time.Time
+	)
+
+	if err := row.Scan(&id, &projectIDStr, &name, &tokenHash, &tokenPrefix, &managed, &lastUsedAt, &createdAt); err != nil {
+		return nil, err
+	}
+
+	tokenID, err := telemetry.IngestTokenIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastUsed *time.Time
+	if lastUsedAt.Valid {
+		lastUsed = &lastUsedAt.Time
+	}
+
+	return telemetry.ReconstructIngestToken(tokenID, projectID, name, tokenHash, tokenPrefix, managed, lastUsed, createdAt), nil
+}
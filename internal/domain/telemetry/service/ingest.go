@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/logs"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+)
+
+// resourceInfo is what the resource of exported telemetry says about the
+// application that sent it
+type resourceInfo struct {
+	attributes   map[string]string
+	serviceName  string
+	serviceID    *uuid.UUID
+	deploymentID *uuid.UUID
+}
+
+func newResourceInfo(resource *resourcepb.Resource) resourceInfo {
+	attributes := attributeMap(resource.GetAttributes())
+
+	return resourceInfo{
+		attributes:   attributes,
+		serviceName:  attributes[telemetry.AttributeServiceName],
+		serviceID:    parseOptionalUUID(attributes[telemetry.AttributeAppID]),
+		deploymentID: parseOptionalUUID(attributes[telemetry.AttributeDeploymentID]),
+	}
+}
+
+// IngestLogs stores the log records of an export as telemetry log entries of
+// the project of the token. It returns how many records were rejected.
+func (s *TelemetryService) IngestLogs(ctx context.Context, token *telemetry.IngestToken, req *collogspb.ExportLogsServiceRequest) (int64, error) {
+	var entries []*logs.LogEntry
+	var rejected int64
+
+	for _, resourceLogs := range req.GetResourceLogs() {
+		resource := newResourceInfo(resourceLogs.GetResource())
+
+		for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+			for _, record := range scopeLogs.GetLogRecords() {
+				message := anyValueString(record.GetBody())
+				if message == "" {
+					rejected++
+					continue
+				}
+
+				metadata := make(map[string]interface{}, len(record.GetAttributes())+3)
+				for key, value := range attributeMap(record.GetAttributes()) {
+					metadata[key] = value
+				}
+				if resource.serviceName != "" {
+					metadata["service_name"] = resource.serviceName
+				}
+				if len(record.GetTraceId()) > 0 {
+					metadata["trace_id"] = hex.EncodeToString(record.GetTraceId())
+				}
+				if len(record.GetSpanId()) > 0 {
+					metadata["span_id"] = hex.EncodeToString(record.GetSpanId())
+				}
+
+				timestamp := record.GetTimeUnixNano()
+				if timestamp == 0 {
+					timestamp = record.GetObservedTimeUnixNano()
+				}
+
+				entries = append(entries, logs.NewTelemetryLogEntry(
+					token.ProjectID(),
+					resource.serviceID,
+					resource.deploymentID,
+					logLevel(record.GetSeverityNumber(), record.GetSeverityText()),
+					message,
+					unixNanoOrNow(timestamp),
+					metadata,
+				))
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return rejected, nil
+	}
+
+	if err := s.logs.RecordLogs(ctx, entries); err != nil {
+		return 0, fmt.Errorf("failed to store logs: %w", err)
+	}
+
+	return rejected, nil
+}
+
+// IngestMetrics stores the data points of an export as metrics of the project
+// of the token. Histograms and summaries are stored as their count and sum.
+// It returns how many data points were rejected.
+func (s *TelemetryService) IngestMetrics(ctx context.Context, token *telemetry.IngestToken, req *colmetricspb.ExportMetricsServiceRequest) (int64, error) {
+	var metrics []*analytics.Metric
+	var rejected int64
+
+	for _, resourceMetrics := range req.GetResourceMetrics() {
+		resource := newResourceInfo(resourceMetrics.GetResource())
+
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			for _, metric := range scopeMetrics.GetMetrics() {
+				unit := metricUnit(metric.GetUnit())
+
+				add := func(name string, value float64, unit analytics.MetricUnit, attributes []*commonpb.KeyValue, timestamp uint64) {
+					metricName, err := analytics.NewMetricName(name)
+					if err != nil {
+						rejected++
+						return
+					}
+
+					tags := attributeMap(attributes)
+					if resource.serviceName != "" {
+						tags["service_name"] = resource.serviceName
+					}
+					if resource.deploymentID != nil {
+						tags["deployment_id"] = resource.deploymentID.String()
+					}
+
+					metrics = append(metrics, analytics.NewMetric(
+						token.ProjectID(), resource.serviceID, metricName, value, unit, tags, unixNanoOrNow(timestamp),
+					))
+				}
+
+				switch data := metric.GetData().(type) {
+				case *metricspb.Metric_Gauge:
+					for _, point := range data.Gauge.GetDataPoints() {
+						add(metric.GetName(), numberValue(point), unit, point.GetAttributes(), point.GetTimeUnixNano())
+					}
+				case *metricspb.Metric_Sum:
+					for _, point := range data.Sum.GetDataPoints() {
+						add(metric.GetName(), numberValue(point), unit, point.GetAttributes(), point.GetTimeUnixNano())
+					}
+				case *metricspb.Metric_Histogram:
+					for _, point := range data.Histogram.GetDataPoints() {
+						add(metric.GetName()+"_count", float64(point.GetCount()), analytics.MetricUnitCount, point.GetAttributes(), point.GetTimeUnixNano())
+						if point.Sum != nil {
+							add(metric.GetName()+"_sum", point.GetSum(), unit, point.GetAttributes(), point.GetTimeUnixNano())
+						}
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					for _, point := range data.ExponentialHistogram.GetDataPoints() {
+						add(metric.GetName()+"_count", float64(point.GetCount()), analytics.MetricUnitCount, point.GetAttributes(), point.GetTimeUnixNano())
+						if point.Sum != nil {
+							add(metric.GetName()+"_sum", point.GetSum(), unit, point.GetAttributes(), point.GetTimeUnixNano())
+						}
+					}
+				case *metricspb.Metric_Summary:
+					for _, point := range data.Summary.GetDataPoints() {
+						add(metric.GetName()+"_count", float64(point.GetCount()), analytics.MetricUnitCount, point.GetAttributes(), point.GetTimeUnixNano())
+						add(metric.GetName()+"_sum", point.GetSum(), unit, point.GetAttributes(), point.GetTimeUnixNano())
+					}
+				default:
+					rejected++
+				}
+			}
+		}
+	}
+
+	if len(metrics) == 0 {
+		return rejected, nil
+	}
+
+	if err := s.analytics.RecordMetrics(ctx, metrics); err != nil {
+		return 0, fmt.Errorf("failed to store metrics: %w", err)
+	}
+
+	return rejected, nil
+}
+
+// IngestTraces stores the spans of an export under the project of the token.
+// It returns how many spans were rejected.
+func (s *TelemetryService) IngestTraces(ctx context.Context, token *telemetry.IngestToken, req *coltracepb.ExportTraceServiceRequest) (int64, error) {
+	var spans []*telemetry.Span
+	var rejected int64
+
+	for _, resourceSpans := range req.GetResourceSpans() {
+		resource := newResourceInfo(resourceSpans.GetResource())
+
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				if len(span.GetTraceId()) != 16 || len(span.GetSpanId()) != 8 {
+					rejected++
+					continue
+				}
+
+				spans = append(spans, toSpan(token.ProjectID(), resource, span))
+			}
+		}
+	}
+
+	if len(spans) == 0 {
+		return rejected, nil
+	}
+
+	if err := s.spanRepo.CreateBatch(ctx, spans); err != nil {
+		return 0, fmt.Errorf("failed to store spans: %w", err)
+	}
+
+	return rejected, nil
+}
+
+func toSpan(projectID uuid.UUID, resource resourceInfo, span *tracepb.Span) *telemetry.Span {
+	start := unixNanoOrNow(span.GetStartTimeUnixNano())
+
+	var duration time.Duration
+	if span.GetEndTimeUnixNano() > span.GetStartTimeUnixNano() {
+		duration = time.Duration(span.GetEndTimeUnixNano() - span.GetStartTimeUnixNano())
+	}
+
+	events := make([]telemetry.SpanEvent, 0, len(span.GetEvents()))
+	for _, event := range span.GetEvents() {
+		events = append(events, telemetry.SpanEvent{
+			Name:       event.GetName(),
+			Timestamp:  unixNanoOrNow(event.GetTimeUnixNano()),
+			Attributes: attributeMap(event.GetAttributes()),
+		})
+	}
+
+	return &telemetry.Span{
+		TraceID:       hex.EncodeToString(span.GetTraceId()),
+		SpanID:        hex.EncodeToString(span.GetSpanId()),
+		ParentSpanID:  hex.EncodeToString(span.GetParentSpanId()),
+		ProjectID:     projectID,
+		ServiceID:     resource.serviceID,
+		DeploymentID:  resource.deploymentID,
+		ServiceName:   resource.serviceName,
+		Name:          span.GetName(),
+		Kind:          spanKind(span.GetKind()),
+		Status:        spanStatus(span.GetStatus().GetCode()),
+		StatusMessage: span.GetStatus().GetMessage(),
+		StartTime:     start,
+		Duration:      duration,
+		Attributes:    attributeMap(span.GetAttributes()),
+		Resource:      resource.attributes,
+		Events:        events,
+	}
+}
+
+// logLevel maps an OTLP severity to a log level, falling back on the severity
+// text for records that only set that
+func logLevel(severity logspb.SeverityNumber, text string) logs.LogLevel {
+	switch {
+	case severity >= logspb.SeverityNumber_SEVERITY_NUMBER_FATAL:
+		return logs.LogLevelFatal
+	case severity >= logspb.SeverityNumber_SEVERITY_NUMBER_ERROR:
+		return logs.LogLevelError
+	case severity >= logspb.SeverityNumber_SEVERITY_NUMBER_WARN:
+		return logs.LogLevelWarn
+	case severity >= logspb.SeverityNumber_SEVERITY_NUMBER_INFO:
+		return logs.LogLevelInfo
+	case severity >= logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG:
+		return logs.LogLevelDebug
+	case severity >= logspb.SeverityNumber_SEVERITY_NUMBER_TRACE:
+		return logs.LogLevelTrace
+	}
+
+	level := logs.LogLevel(strings.ToLower(text))
+	if level == "warning" {
+		return logs.LogLevelWarn
+	}
+	if level.IsValid() {
+		return level
+	}
+	return logs.LogLevelInfo
+}
+
+// metricUnit maps the UCUM units used by OpenTelemetry to metric units,
+// annotations such as {request} are counts
+func metricUnit(unit string) analytics.MetricUnit {
+	switch unit {
+	case "", "1":
+		return analytics.MetricUnitCount
+	case "By":
+		return analytics.MetricUnitBytes
+	case "By/s":
+		return analytics.MetricUnitBytesSecond
+	case "s":
+		return analytics.MetricUnitSeconds
+	case "ms":
+		return analytics.MetricUnitMilliseconds
+	case "%":
+		return analytics.MetricUnitPercent
+	}
+
+	if strings.HasPrefix(unit, "{") {
+		return analytics.MetricUnitCount
+	}
+	return analytics.MetricUnit(unit)
+}
+
+func numberValue(point *metricspb.NumberDataPoint) float64 {
+	if value, ok := point.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(value.AsInt)
+	}
+	return point.GetAsDouble()
+}
+
+func spanKind(kind tracepb.Span_SpanKind) telemetry.SpanKind {
+	switch kind {
+	case tracepb.Span_SPAN_KIND_INTERNAL:
+		return telemetry.SpanKindInternal
+	case tracepb.Span_SPAN_KIND_SERVER:
+		return telemetry.SpanKindServer
+	case tracepb.Span_SPAN_KIND_CLIENT:
+		return telemetry.SpanKindClient
+	case tracepb.Span_SPAN_KIND_PRODUCER:
+		return telemetry.SpanKindProducer
+	case tracepb.Span_SPAN_KIND_CONSUMER:
+		return telemetry.SpanKindConsumer
+	}
+	return telemetry.SpanKindUnspecified
+}
+
+func spanStatus(code tracepb.Status_StatusCode) telemetry.SpanStatus {
+	switch code {
+	case tracepb.Status_STATUS_CODE_OK:
+		return telemetry.SpanStatusOK
+	case tracepb.Status_STATUS_CODE_ERROR:
+		return telemetry.SpanStatusError
+	}
+	return telemetry.SpanStatusUnset
+}
+
+// attributeMap flattens OTLP attributes to strings, arrays and maps become JSON
+func attributeMap(attributes []*commonpb.KeyValue) map[string]string {
+	result := make(map[string]string, len(attributes))
+	for _, attribute := range attributes {
+		result[attribute.GetKey()] = anyValueString(attribute.GetValue())
+	}
+	return result
+}
+
+func anyValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue, *commonpb.AnyValue_KvlistValue:
+		data, err := json.Marshal(anyValueJSON(value))
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	return ""
+}
+
+// anyValueJSON converts a value to its plain Go equivalent for marshalling
+func anyValueJSON(value *commonpb.AnyValue) any {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return v.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, anyValueJSON(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		values := make(map[string]any, len(v.KvlistValue.GetValues()))
+		for _, item := range v.KvlistValue.GetValues() {
+			values[item.GetKey()] = anyValueJSON(item.GetValue())
+		}
+		return values
+	}
+	return nil
+}
+
+// unixNanoOrNow converts an OTLP timestamp, unset ones are the receive time
+func unixNanoOrNow(nanos uint64) time.Time {
+	if nanos == 0 {
+		return time.Now()
+	}
+	return time.Unix(0, int64(nanos))
+}
+
+func parseOptionalUUID(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
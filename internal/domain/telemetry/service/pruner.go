@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SpanPruner periodically removes spans older than SpanRetention
+type SpanPruner struct {
+	telemetryService *TelemetryService
+	interval         time.Duration
+	stopCh           chan struct{}
+}
+
+func NewSpanPruner(telemetryService *TelemetryService, interval time.Duration) *SpanPruner {
+	if interval == 0 {
+		interval = time.Hour
+	}
+
+	return &SpanPruner{
+		telemetryService: telemetryService,
+		interval:         interval,
+		stopCh:           make(chan struct{}),
+	}
+}
+
+// Start runs the pruner until the context is cancelled or Stop is called
+func (p *SpanPruner) Start(ctx context.Context) {
+	slog.Info("Starting trace span pruner", "interval", p.interval, "retention", SpanRetention)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Trace span pruner stopped due to context cancellation")
+			return
+		case <-p.stopCh:
+			slog.Info("Trace span pruner stopped")
+			return
+		case <-ticker.C:
+			if err := p.telemetryService.CleanupOldSpans(ctx); err != nil {
+				slog.Error("Failed to clean up old trace spans", "error", err)
+			}
+		}
+	}
+}
+
+// Stop stops the pruner
+func (p *SpanPruner) Stop() {
+	close(p.stopCh)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	analyticsService "github.com/mikrocloud/mikrocloud/internal/domain/analytics/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	logsService "github.com/mikrocloud/mikrocloud/internal/domain/logs/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry"
+	"github.com/mikrocloud/mikrocloud/internal/domain/telemetry/repository"
+)
+
+const (
+	// SpanRetention is how long received spans are kept
+	SpanRetention = 7 * 24 * time.Hour
+
+	// tokenTouchInterval limits how often the last use of a token is written
+	tokenTouchInterval = time.Minute
+)
+
+var (
+	ErrTokenNotFound = errors.New("ingest token not found")
+	ErrInvalidToken  = errors.New("invalid ingest token")
+	ErrTraceNotFound = errors.New("trace not found")
+)
+
+// TelemetryService manages the ingest tokens of projects, stores what
+// applications export over OTLP and configures their containers to do so
+type TelemetryService struct {
+	tokenRepo repository.IngestTokenRepository
+	spanRepo  repository.SpanRepository
+	analytics *analyticsService.AnalyticsService
+	logs      *logsService.LogService
+	enabled   bool
+	endpoint  string
+	// secret keys the managed tokens, see telemetry.ManagedTokenValue
+	secret []byte
+}
+
+func NewTelemetryService(
+	tokenRepo repository.IngestTokenRepository,
+	spanRepo repository.SpanRepository,
+	analytics *analyticsService.AnalyticsService,
+	logs *logsService.LogService,
+	enabled bool,
+	endpoint string,
+	secret string,
+) *TelemetryService {
+	return &TelemetryService{
+		tokenRepo: tokenRepo,
+		spanRepo:  spanRepo,
+		analytics: analytics,
+		logs:      logs,
+		enabled:   enabled,
+		endpoint:  endpoint,
+		secret:    []byte(secret),
+	}
+}
+
+// Enabled reports whether the OTLP receiver is served
+func (s *TelemetryService) Enabled() bool {
+	return s.enabled
+}
+
+// Endpoint returns the OTLP/HTTP base URL applications export to
+func (s *TelemetryService) Endpoint() string {
+	return s.endpoint
+}
+
+func (s *TelemetryService) CreateToken(ctx context.Context, projectID uuid.UUID, name string) (*telemetry.IngestToken, error) {
+	token, err := telemetry.NewIngestToken(projectID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create ingest token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *TelemetryService) ListTokens(ctx context.Context, projectID uuid.UUID) ([]*telemetry.IngestToken, error) {
+	tokens, err := s.tokenRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingest tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteToken revokes a token of a project. Deleting the managed token cuts
+// off running containers until they are redeployed with a new one.
+func (s *TelemetryService) DeleteToken(ctx context.Context, projectID uuid.UUID, tokenID string) error {
+	id, err := telemetry.IngestTokenIDFromString(tokenID)
+	if err != nil {
+		return ErrTokenNotFound
+	}
+
+	token, err := s.tokenRepo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTokenNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ingest token: %w", err)
+	}
+
+	if token.ProjectID() != projectID {
+		return ErrTokenNotFound
+	}
+
+	if err := s.tokenRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete ingest token: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the token matching an Authorization bearer value,
+// ErrInvalidToken when there is none
+func (s *TelemetryService) Authenticate(ctx context.Context, value string) (*telemetry.IngestToken, error) {
+	if !strings.HasPrefix(value, telemetry.TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.tokenRepo.GetByTokenHash(ctx, telemetry.HashIngestToken(value))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest token: %w", err)
+	}
+
+	now := time.Now()
+	if token.LastUsedAt() == nil || now.Sub(*token.LastUsedAt()) > tokenTouchInterval {
+		if err := s.tokenRepo.TouchLastUsed(ctx, token.ID(), now); err != nil {
+			slog.Warn("Failed to record ingest token use", "token_id", token.ID().String(), "error", err)
+		}
+	}
+
+	return token, nil
+}
+
+// HashLegacyTokens hashes the tokens stored before tokens were hashed
+func (s *TelemetryService) HashLegacyTokens(ctx context.Context) error {
+	count, err := s.tokenRepo.HashLegacyTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to hash ingest tokens: %w", err)
+	}
+	if count > 0 {
+		slog.Info("Hashed stored ingest tokens", "count", count)
+	}
+	return nil
+}
+
+// InjectEnvironment adds the OTLP exporter settings of an application to the
+// environment of its container. Variables set by the user are kept, resource
+// attributes are appended to theirs.
+func (s *TelemetryService) InjectEnvironment(ctx context.Context, app *applications.Application, deploymentID string, env map[string]string) error {
+	if !s.enabled {
+		return nil
+	}
+
+	token, err := s.managedToken(ctx, app.ProjectID())
+	if err != nil {
+		return err
+	}
+
+	defaults := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": s.endpoint,
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+		// Header values are percent-encoded, hence the escaped space
+		"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Bearer%20" + token,
+		"OTEL_SERVICE_NAME":          app.Name().String(),
+	}
+	for key, value := range defaults {
+		if _, ok := env[key]; !ok {
+			env[key] = value
+		}
+	}
+
+	attributes := strings.Join([]string{
+		telemetry.AttributeProjectID + "=" + app.ProjectID().String(),
+		telemetry.AttributeAppID + "=" + app.ID().String(),
+		telemetry.AttributeDeploymentID + "=" + deploymentID,
+	}, ",")
+	if existing := env["OTEL_RESOURCE_ATTRIBUTES"]; existing != "" {
+		attributes = existing + "," + attributes
+	}
+	env["OTEL_RESOURCE_ATTRIBUTES"] = attributes
+
+	return nil
+}
+
+// managedToken returns the token injected into the containers of a project,
+// creating it on the first deployment. A token that no longer matches the
+// secret, because it changed or the token was stored before managed tokens
+// were derived from it, is replaced; the old one keeps working until deleted.
+func (s *TelemetryService) managedToken(ctx context.Context, projectID uuid.UUID) (string, error) {
+	token, err := s.tokenRepo.GetManaged(ctx, projectID)
+	if err == nil {
+		value := telemetry.ManagedTokenValue(token.ID(), s.secret)
+		if telemetry.HashIngestToken(value) == token.TokenHash() {
+			return value, nil
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to get managed ingest token: %w", err)
+	}
+
+	token = telemetry.NewManagedIngestToken(projectID, s.secret)
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", fmt.Errorf("failed to create managed ingest token: %w", err)
+	}
+
+	return token.Token(), nil
+}
+
+// GetTrace returns the spans of a trace of a project in start order
+func (s *TelemetryService) GetTrace(ctx context.Context, projectID uuid.UUID, traceID string) ([]*telemetry.Span, error) {
+	traceID = strings.ToLower(traceID)
+	if !telemetry.ValidTraceID(traceID) {
+		return nil, ErrTraceNotFound
+	}
+
+	spans, err := s.spanRepo.ListByTrace(ctx, projectID, traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trace: %w", err)
+	}
+
+	if len(spans) == 0 {
+		return nil, ErrTraceNotFound
+	}
+
+	return spans, nil
+}
+
+// CleanupOldSpans removes spans older than SpanRetention
+func (s *TelemetryService) CleanupOldSpans(ctx context.Context) error {
+	return s.spanRepo.DeleteOlderThan(ctx, time.Now().Add(-SpanRetention))
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	projectsService "github.com/mikrocloud/mikrocloud/internal/domain/projects/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+type UptimeHandler struct {
+	uptimeService  *service.UptimeService
+	projectService *projectsService.ProjectService
+	validator      *validator.Validate
+}
+
+func NewUptimeHandler(uptimeService *service.UptimeService, projectService *projectsService.ProjectService) *UptimeHandler {
+	return &UptimeHandler{
+		uptimeService:  uptimeService,
+		projectService: projectService,
+		validator:      validator.New(),
+	}
+}
+
+// MonitorSettings are the check settings shared by the create and update
+// requests. Zero values use the defaults: path /, port 80, any 2xx or 3xx
+// status, every 60 seconds with a 10 second timeout, 3 failures in a row.
+type MonitorSettings struct {
+	Path             string `json:"path,omitempty" validate:"omitempty,max=2048"`
+	Port             int    `json:"port,omitempty" validate:"min=0,max=65535"`
+	ExpectedStatus   int    `json:"expected_status,omitempty" validate:"omitempty,min=100,max=599"`
+	IntervalSeconds  int    `json:"interval_seconds,omitempty" validate:"omitempty,min=30,max=3600"`
+	TimeoutSeconds   int    `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=30"`
+	FailureThreshold int    `json:"failure_threshold,omitempty" validate:"omitempty,min=1,max=10"`
+}
+
+func (s MonitorSettings) config() uptime.MonitorConfig {
+	return uptime.MonitorConfig{
+		Path:             s.Path,
+		Port:             s.Port,
+		ExpectedStatus:   s.ExpectedStatus,
+		Interval:         time.Duration(s.IntervalSeconds) * time.Second,
+		Timeout:          time.Duration(s.TimeoutSeconds) * time.Second,
+		FailureThreshold: s.FailureThreshold,
+	}
+}
+
+type CreateMonitorRequest struct {
+	Name          string             `json:"name" validate:"required,max=255"`
+	ApplicationID string             `json:"application_id" validate:"required,uuid"`
+	Type          uptime.MonitorType `json:"type" validate:"required"`
+	MonitorSettings
+}
+
+type UpdateMonitorRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Enabled *bool  `json:"enabled,omitempty"`
+	MonitorSettings
+}
+
+type MonitorResponse struct {
+	ID               string             `json:"id"`
+	ProjectID        string             `json:"project_id"`
+	ApplicationID    string             `json:"application_id"`
+	Name             string             `json:"name"`
+	Type             uptime.MonitorType `json:"type"`
+	Path             string             `json:"path"`
+	Port             int                `json:"port"`
+	ExpectedStatus   int                `json:"expected_status"`
+	IntervalSeconds  int64              `json:"interval_seconds"`
+	TimeoutSeconds   int64              `json:"timeout_seconds"`
+	FailureThreshold int                `json:"failure_threshold"`
+	Enabled          bool               `json:"enabled"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type DailyUptimeResponse struct {
+	Date      string  `json:"date"`
+	Checks    int     `json:"checks"`
+	Successes int     `json:"successes"`
+	Uptime    float64 `json:"uptime"`
+}
+
+type MonitorHistoryResponse struct {
+	MonitorID string                `json:"monitor_id"`
+	Days      []DailyUptimeResponse `json:"days"`
+}
+
+type IncidentResponse struct {
+	ID              string                `json:"id"`
+	MonitorID       string                `json:"monitor_id"`
+	Status          uptime.IncidentStatus `json:"status"`
+	Cause           string                `json:"cause"`
+	StartedAt       time.Time             `json:"started_at"`
+	ResolvedAt      *time.Time            `json:"resolved_at,omitempty"`
+	DurationSeconds int64                 `json:"duration_seconds"`
+}
+
+type ListMonitorsResponse struct {
+	Monitors []MonitorResponse `json:"monitors"`
+}
+
+type ListIncidentsResponse struct {
+	Incidents []IncidentResponse `json:"incidents"`
+}
+
+func toMonitorResponse(monitor *uptime.Monitor) MonitorResponse {
+	config := monitor.Config()
+	return MonitorResponse{
+		ID:               monitor.ID().String(),
+		ProjectID:        monitor.ProjectID().String(),
+		ApplicationID:    monitor.ApplicationID().String(),
+		Name:             monitor.Name(),
+		Type:             monitor.Type(),
+		Path:             config.Path,
+		Port:             config.Port,
+		ExpectedStatus:   config.ExpectedStatus,
+		IntervalSeconds:  int64(config.Interval / time.Second),
+		TimeoutSeconds:   int64(config.Timeout / time.Second),
+		FailureThreshold: config.FailureThreshold,
+		Enabled:          monitor.Enabled(),
+		CreatedAt:        monitor.CreatedAt(),
+		UpdatedAt:        monitor.UpdatedAt(),
+	}
+}
+
+func toDailyUptimeResponses(days []uptime.DailyUptime) []DailyUptimeResponse {
+	response := make([]DailyUptimeResponse, 0, len(days))
+	for _, day := range days {
+		response = append(response, DailyUptimeResponse{
+			Date:      day.Day.Format("2006-01-02"),
+			Checks:    day.Checks,
+			Successes: day.Successes,
+			Uptime:    day.Percent(),
+		})
+	}
+	return response
+}
+
+func toIncidentResponse(incident *uptime.Incident) IncidentResponse {
+	return IncidentResponse{
+		ID:              incident.ID().String(),
+		MonitorID:       incident.MonitorID().String(),
+		Status:          incident.Status(),
+		Cause:           incident.Cause(),
+		StartedAt:       incident.StartedAt(),
+		ResolvedAt:      incident.ResolvedAt(),
+		DurationSeconds: int64(incident.Duration() / time.Second),
+	}
+}
+
+func (h *UptimeHandler) ListMonitors(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	monitors, err := h.uptimeService.ListMonitors(r.Context(), projectID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list monitors")
+		return
+	}
+
+	response := ListMonitorsResponse{Monitors: make([]MonitorResponse, 0, len(monitors))}
+	for _, monitor := range monitors {
+		response.Monitors = append(response.Monitors, toMonitorResponse(monitor))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *UptimeHandler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateMonitorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	monitor, err := h.uptimeService.CreateMonitor(r.Context(), service.CreateMonitorCommand{
+		ProjectID:     projectID,
+		ApplicationID: uuid.MustParse(req.ApplicationID),
+		Name:          req.Name,
+		Type:          req.Type,
+		Config:        req.config(),
+	})
+	if errors.Is(err, service.ErrApplicationNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create monitor: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toMonitorResponse(monitor))
+}
+
+func (h *UptimeHandler) GetMonitor(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	monitor, err := h.uptimeService.GetMonitor(r.Context(), projectID, chi.URLParam(r, "monitor_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toMonitorResponse(monitor))
+}
+
+func (h *UptimeHandler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateMonitorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	monitor, err := h.uptimeService.UpdateMonitor(r.Context(), projectID, chi.URLParam(r, "monitor_id"), req.Name, req.config(), enabled)
+	if errors.Is(err, service.ErrMonitorNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update monitor: "+err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toMonitorResponse(monitor))
+}
+
+func (h *UptimeHandler) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.uptimeService.DeleteMonitor(r.Context(), projectID, chi.URLParam(r, "monitor_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMonitorHistory returns the daily uptime of a monitor. The days query
+// parameter picks how many days back, up to 90.
+func (h *UptimeHandler) GetMonitorHistory(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	days := uptime.StatusPageDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > uptime.StatusPageDays {
+			utils.SendError(w, http.StatusBadRequest, "invalid_days", "days must be between 1 and 90")
+			return
+		}
+		days = parsed
+	}
+
+	monitor, err := h.uptimeService.GetMonitor(r.Context(), projectID, chi.URLParam(r, "monitor_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	history, err := h.uptimeService.MonitorHistory(r.Context(), monitor, days)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, MonitorHistoryResponse{
+		MonitorID: monitor.ID().String(),
+		Days:      toDailyUptimeResponses(history),
+	})
+}
+
+// ListIncidents returns the incidents of a project, newest first. The status
+// query parameter filters on open or resolved.
+func (h *UptimeHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := h.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var status *uptime.IncidentStatus
+	if value := r.URL.Query().Get("status"); value != "" {
+		parsed := uptime.IncidentStatus(value)
+		if parsed != uptime.IncidentStatusOpen && parsed != uptime.IncidentStatusResolved {
+			utils.SendError(w, http.StatusBadRequest, "invalid_status", "status must be open or resolved")
+			return
+		}
+		status = &parsed
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	incidents, err := h.uptimeService.ListIncidents(r.Context(), projectID, status, limit)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list incidents")
+		return
+	}
+
+	response := ListIncidentsResponse{Incidents: make([]IncidentResponse, 0, len(incidents))}
+	for _, incident := range incidents {
+		response.Incidents = append(response.Incidents, toIncidentResponse(incident))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *UptimeHandler) getProject(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "project_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_project_id", "Invalid project ID")
+		return uuid.Nil, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "project_not_found", "Project not found")
+		return uuid.Nil, false
+	}
+
+	return projectID, true
+}
+
+func (h *UptimeHandler) sendLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrMonitorNotFound):
+		utils.SendError(w, http.StatusNotFound, "monitor_not_found", "Monitor not found")
+	case errors.Is(err, service.ErrApplicationNotFound):
+		utils.SendError(w, http.StatusNotFound, "application_not_found", "Application not found")
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime/service"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+)
+
+// publicCacheControl lets browsers and CDNs reuse a status page for a short
+// while, checks run every minute at the most anyway
+const publicCacheControl = "public, max-age=30"
+
+// PublicStatusPageHandler serves status pages to anyone, on the API and on
+// their custom domain
+type PublicStatusPageHandler struct {
+	uptimeService *service.UptimeService
+}
+
+func NewPublicStatusPageHandler(uptimeService *service.UptimeService) *PublicStatusPageHandler {
+	return &PublicStatusPageHandler{uptimeService: uptimeService}
+}
+
+type PublicServiceResponse struct {
+	Name   string                `json:"name"`
+	State  service.ServiceState  `json:"state"`
+	Uptime float64               `json:"uptime"`
+	Days   []DailyUptimeResponse `json:"days"`
+}
+
+type PublicIncidentResponse struct {
+	Service    string     `json:"service"`
+	Cause      string     `json:"cause"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type PublicStatusPageResponse struct {
+	Name        string                   `json:"name"`
+	Operational bool                     `json:"operational"`
+	Services    []PublicServiceResponse  `json:"services"`
+	Incidents   []PublicIncidentResponse `json:"incidents"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// GetSummary returns a status page as JSON
+func (h *PublicStatusPageHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	page, err := h.uptimeService.GetPublicStatusPage(r.Context(), chi.URLParam(r, "slug"))
+	if errors.Is(err, service.ErrStatusPageNotFound) {
+		utils.SendError(w, http.StatusNotFound, "status_page_not_found", "Status page not found")
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to build status page", "slug", chi.URLParam(r, "slug"), "error", err)
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", "Failed to load status page")
+		return
+	}
+
+	response := PublicStatusPageResponse{
+		Name:        page.Name,
+		Operational: page.Operational,
+		Services:    make([]PublicServiceResponse, 0, len(page.Services)),
+		Incidents:   make([]PublicIncidentResponse, 0, len(page.Incidents)),
+		GeneratedAt: page.GeneratedAt,
+	}
+	for _, svc := range page.Services {
+		response.Services = append(response.Services, PublicServiceResponse{
+			Name:   svc.Name,
+			State:  svc.State,
+			Uptime: svc.Uptime,
+			Days:   toDailyUptimeResponses(svc.Days),
+		})
+	}
+	for _, incident := range page.Incidents {
+		response.Incidents = append(response.Incidents, PublicIncidentResponse{
+			Service:    incident.ServiceName,
+			Cause:      incident.Cause,
+			StartedAt:  incident.StartedAt,
+			ResolvedAt: incident.ResolvedAt,
+		})
+	}
+
+	w.Header().Set("Cache-Control", publicCacheControl)
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+// GetPage renders a status page as HTML
+func (h *PublicStatusPageHandler) GetPage(w http.ResponseWriter, r *http.Request) {
+	page, err := h.uptimeService.GetPublicStatusPage(r.Context(), chi.URLParam(r, "slug"))
+	if errors.Is(err, service.ErrStatusPageNotFound) {
+		http.Error(w, "Status page not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to build status page", "slug", chi.URLParam(r, "slug"), "error", err)
+		http.Error(w, "Failed to load status page", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := statusPageTemplate.Execute(&buf, page); err != nil {
+		slog.Error("Failed to render status page", "slug", chi.URLParam(r, "slug"), "error", err)
+		http.Error(w, "Failed to render status page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", publicCacheControl)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.2f%%", value)
+	},
+	"date": func(t time.Time) string {
+		return t.UTC().Format("Jan 2, 2006")
+	},
+	"datetime": func(t time.Time) string {
+		return t.UTC().Format("Jan 2, 2006 15:04 UTC")
+	},
+	// barClass grades a day of checks, days without any are left blank
+	"barClass": func(checks int, uptime float64) string {
+		switch {
+		case checks == 0:
+			return "none"
+		case uptime >= 99.9:
+			return "up"
+		case uptime >= 95:
+			return "degraded"
+		}
+		return "down"
+	},
+}).Parse(statusPageHTML))
+
+const statusPageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>{{.Name}} Status</title>
+<style>
+  body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f6f7f9; color: #1f2933; }
+  main { max-width: 860px; margin: 0 auto; padding: 48px 20px; }
+  h1 { font-size: 28px; margin: 0 0 24px; }
+  h2 { font-size: 18px; margin: 40px 0 12px; }
+  .banner { padding: 16px 20px; border-radius: 8px; color: #fff; font-weight: 600; }
+  .banner.up { background: #2f9e44; }
+  .banner.down { background: #e03131; }
+  .service { background: #fff; border: 1px solid #e4e7eb; border-radius: 8px; padding: 16px 20px; margin-top: 12px; }
+  .service header { display: flex; justify-content: space-between; margin-bottom: 10px; }
+  .state { font-size: 14px; }
+  .state.operational { color: #2f9e44; }
+  .state.down { color: #e03131; }
+  .state.paused { color: #7b8794; }
+  .bars { display: flex; gap: 2px; height: 32px; }
+  .bars span { flex: 1; border-radius: 2px; }
+  .bars .up { background: #40c057; }
+  .bars .degraded { background: #fab005; }
+  .bars .down { background: #fa5252; }
+  .bars .none { background: #e4e7eb; }
+  .legend { display: flex; justify-content: space-between; font-size: 12px; color: #7b8794; margin-top: 6px; }
+  .incident { border-left: 3px solid #fa5252; background: #fff; padding: 10px 14px; margin-top: 10px; font-size: 14px; }
+  .incident.resolved { border-color: #40c057; }
+  .muted { color: #7b8794; font-size: 14px; }
+  footer { margin-top: 48px; font-size: 12px; color: #7b8794; }
+</style>
+</head>
+<body>
+<main>
+  <h1>{{.Name}}</h1>
+  {{if .Operational}}<div class="banner up">All systems operational</div>{{else}}<div class="banner down">Some systems are down</div>{{end}}
+
+  {{range .Services}}
+  <section class="service">
+    <header>
+      <strong>{{.Name}}</strong>
+      <span class="state {{.State}}">{{.State}} &middot; {{percent .Uptime}} uptime</span>
+    </header>
+    <div class="bars">{{range .Days}}<span class="{{barClass .Checks .Percent}}" title="{{date .Day}}: {{if .Checks}}{{percent .Percent}}{{else}}no data{{end}}"></span>{{end}}</div>
+    <div class="legend"><span>90 days ago</span><span>Today</span></div>
+  </section>
+  {{else}}
+  <p class="muted">No services are shown on this page yet.</p>
+  {{end}}
+
+  <h2>Incident history</h2>
+  {{range .Incidents}}
+  <div class="incident{{if .ResolvedAt}} resolved{{end}}">
+    <strong>{{.ServiceName}}</strong> &middot; {{datetime .StartedAt}}{{if .ResolvedAt}} &ndash; {{datetime .ResolvedAt}}{{else}} &ndash; ongoing{{end}}
+    {{if .Cause}}<div class="muted">{{.Cause}}</div>{{end}}
+  </div>
+  {{else}}
+  <p class="muted">No incidents in the last 90 days.</p>
+  {{end}}
+
+  <footer>Updated {{datetime .GeneratedAt}}</footer>
+</main>
+</body>
+</html>
+`
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+)
+
+// RegisterUptimeRoutes registers the monitor and incident routes of a project
+func RegisterUptimeRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewUptimeHandler(deps.UptimeService, deps.ProjectService)
+
+	r.Route("/monitors", func(r chi.Router) {
+		r.Get("/", handler.ListMonitors)
+		r.Post("/", handler.CreateMonitor)
+		r.Get("/{monitor_id}", handler.GetMonitor)
+		r.Put("/{monitor_id}", handler.UpdateMonitor)
+		r.Delete("/{monitor_id}", handler.DeleteMonitor)
+		r.Get("/{monitor_id}/history", handler.GetMonitorHistory)
+	})
+	r.Get("/incidents", handler.ListIncidents)
+}
+
+// RegisterStatusPageRoutes registers the status page routes of an
+// organization
+func RegisterStatusPageRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewStatusPageHandler(deps.UptimeService, deps.OrganizationService, deps.TraefikService)
+
+	r.Route("/status-pages", func(r chi.Router) {
+		r.Get("/", handler.ListStatusPages)
+		r.Post("/", handler.CreateStatusPage)
+		r.Get("/{status_page_id}", handler.GetStatusPage)
+		r.Put("/{status_page_id}", handler.UpdateStatusPage)
+		r.Delete("/{status_page_id}", handler.DeleteStatusPage)
+	})
+}
+
+// RegisterPublicStatusPageRoutes registers the unauthenticated routes serving
+// status pages, which custom domains are rewritten to
+func RegisterPublicStatusPageRoutes(r chi.Router, deps *deps.Dependencies) {
+	handler := NewPublicStatusPageHandler(deps.UptimeService)
+
+	r.Get("/status-pages/{slug}", handler.GetSummary)
+	r.Get("/status-pages/{slug}/page", handler.GetPage)
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	organizationsService "github.com/mikrocloud/mikrocloud/internal/domain/organizations/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime/service"
+	"github.com/mikrocloud/mikrocloud/internal/domain/users"
+	"github.com/mikrocloud/mikrocloud/internal/utils"
+	proxyContainers "github.com/mikrocloud/mikrocloud/pkg/containers/proxy"
+)
+
+type StatusPageHandler struct {
+	uptimeService *service.UptimeService
+	orgService    *organizationsService.OrganizationService
+	traefik       *proxyContainers.TraefikService
+	validator     *validator.Validate
+}
+
+func NewStatusPageHandler(
+	uptimeService *service.UptimeService,
+	orgService *organizationsService.OrganizationService,
+	traefik *proxyContainers.TraefikService,
+) *StatusPageHandler {
+	return &StatusPageHandler{
+		uptimeService: uptimeService,
+		orgService:    orgService,
+		traefik:       traefik,
+		validator:     validator.New(),
+	}
+}
+
+type StatusPageRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Slug string `json:"slug" validate:"required,max=63"`
+	// CustomDomain is routed to the page by the proxy, its DNS must point at
+	// this server
+	CustomDomain string   `json:"custom_domain,omitempty" validate:"omitempty,max=253"`
+	MonitorIDs   []string `json:"monitor_ids"`
+	Enabled      *bool    `json:"enabled,omitempty"`
+}
+
+type StatusPageResponse struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Slug           string    `json:"slug"`
+	CustomDomain   string    `json:"custom_domain,omitempty"`
+	MonitorIDs     []string  `json:"monitor_ids"`
+	Enabled        bool      `json:"enabled"`
+	PageURL        string    `json:"page_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ListStatusPagesResponse struct {
+	StatusPages []StatusPageResponse `json:"status_pages"`
+}
+
+func toStatusPageResponse(page *uptime.StatusPage) StatusPageResponse {
+	response := StatusPageResponse{
+		ID:             page.ID().String(),
+		OrganizationID: page.OrganizationID().String(),
+		Name:           page.Name(),
+		Slug:           page.Slug(),
+		CustomDomain:   page.CustomDomain(),
+		MonitorIDs:     make([]string, 0, len(page.MonitorIDs())),
+		Enabled:        page.Enabled(),
+		PageURL:        proxyContainers.StatusPagePath(page.Slug()),
+		CreatedAt:      page.CreatedAt(),
+		UpdatedAt:      page.UpdatedAt(),
+	}
+
+	if page.CustomDomain() != "" {
+		response.PageURL = "http://" + page.CustomDomain() + "/"
+	}
+
+	for _, monitorID := range page.MonitorIDs() {
+		response.MonitorIDs = append(response.MonitorIDs, monitorID.String())
+	}
+
+	return response
+}
+
+func (h *StatusPageHandler) ListStatusPages(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.getOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	pages, err := h.uptimeService.ListStatusPages(r.Context(), organizationID)
+	if err != nil {
+		utils.SendError(w, http.StatusInternalServerError, "list_failed", "Failed to list status pages")
+		return
+	}
+
+	response := ListStatusPagesResponse{StatusPages: make([]StatusPageResponse, 0, len(pages))}
+	for _, page := range pages {
+		response.StatusPages = append(response.StatusPages, toStatusPageResponse(page))
+	}
+
+	utils.SendJSON(w, http.StatusOK, response)
+}
+
+func (h *StatusPageHandler) CreateStatusPage(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.getOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	cmd, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.uptimeService.CreateStatusPage(r.Context(), organizationID, cmd)
+	if errors.Is(err, service.ErrSlugTaken) || errors.Is(err, service.ErrDomainTaken) {
+		utils.SendError(w, http.StatusConflict, "status_page_conflict", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "create_failed", "Failed to create status page: "+err.Error())
+		return
+	}
+
+	if page.CustomDomain() != "" {
+		h.applyRoutes(r.Context())
+	}
+
+	utils.SendJSON(w, http.StatusCreated, toStatusPageResponse(page))
+}
+
+func (h *StatusPageHandler) GetStatusPage(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.getOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.uptimeService.GetStatusPage(r.Context(), organizationID, chi.URLParam(r, "status_page_id"))
+	if err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, http.StatusOK, toStatusPageResponse(page))
+}
+
+func (h *StatusPageHandler) UpdateStatusPage(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.getOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	cmd, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.uptimeService.UpdateStatusPage(r.Context(), organizationID, chi.URLParam(r, "status_page_id"), cmd)
+	if errors.Is(err, service.ErrStatusPageNotFound) {
+		h.sendLookupError(w, err)
+		return
+	}
+	if errors.Is(err, service.ErrSlugTaken) || errors.Is(err, service.ErrDomainTaken) {
+		utils.SendError(w, http.StatusConflict, "status_page_conflict", err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "update_failed", "Failed to update status page: "+err.Error())
+		return
+	}
+
+	h.applyRoutes(r.Context())
+
+	utils.SendJSON(w, http.StatusOK, toStatusPageResponse(page))
+}
+
+func (h *StatusPageHandler) DeleteStatusPage(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := h.getOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.uptimeService.DeleteStatusPage(r.Context(), organizationID, chi.URLParam(r, "status_page_id")); err != nil {
+		h.sendLookupError(w, err)
+		return
+	}
+
+	h.applyRoutes(r.Context())
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyRoutes rewrites the proxy config of the custom domains. A failure is
+// logged, the page itself was saved and is applied again at startup.
+func (h *StatusPageHandler) applyRoutes(ctx context.Context) {
+	routes, err := h.uptimeService.ListStatusPageRoutes(ctx)
+	if err == nil {
+		err = h.traefik.WriteStatusPageConfig(h.uptimeService.StatusPageUpstream(), routes)
+	}
+	if err != nil {
+		slog.Error("Failed to apply status page routes", "error", err)
+	}
+}
+
+func (h *StatusPageHandler) decodeRequest(w http.ResponseWriter, r *http.Request) (service.StatusPageCommand, bool) {
+	var req StatusPageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON format")
+		return service.StatusPageCommand{}, false
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return service.StatusPageCommand{}, false
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return service.StatusPageCommand{
+		Name:         req.Name,
+		Slug:         req.Slug,
+		CustomDomain: req.CustomDomain,
+		MonitorIDs:   req.MonitorIDs,
+		Enabled:      enabled,
+	}, true
+}
+
+func (h *StatusPageHandler) getOrganization(w http.ResponseWriter, r *http.Request) (users.OrganizationID, bool) {
+	organizationID, err := users.OrganizationIDFromString(chi.URLParam(r, "organization_id"))
+	if err != nil {
+		utils.SendError(w, http.StatusBadRequest, "invalid_organization_id", "Invalid organization ID")
+		return users.OrganizationID{}, false
+	}
+
+	if _, err := h.orgService.GetOrganization(r.Context(), organizationID.String()); err != nil {
+		utils.SendError(w, http.StatusNotFound, "organization_not_found", "Organization not found")
+		return users.OrganizationID{}, false
+	}
+
+	return organizationID, true
+}
+
+func (h *StatusPageHandler) sendLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrStatusPageNotFound):
+		utils.SendError(w, http.StatusNotFound, "status_page_not_found", "Status page not found")
+	default:
+		utils.SendError(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
@@ -0,0 +1,533 @@
+package uptime
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/domains"
+	"github.com/mikrocloud/mikrocloud/internal/domain/users"
+)
+
+const (
+	// MetricUp is recorded as 1 for a successful check and 0 for a failed one
+	MetricUp = "uptime_up"
+	// MetricResponseTime is how long a check took to get its response
+	MetricResponseTime = "uptime_response_time_ms"
+)
+
+// StatusPageDays is how many days of history a status page shows
+const StatusPageDays = 90
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type MonitorID struct {
+	value string
+}
+
+func NewMonitorID() MonitorID {
+	return MonitorID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func MonitorIDFromString(s string) (MonitorID, error) {
+	if s == "" {
+		return MonitorID{}, fmt.Errorf("monitor ID cannot be empty")
+	}
+	return MonitorID{value: s}, nil
+}
+
+func (id MonitorID) String() string {
+	return id.value
+}
+
+type IncidentID struct {
+	value string
+}
+
+func NewIncidentID() IncidentID {
+	return IncidentID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func IncidentIDFromString(s string) (IncidentID, error) {
+	if s == "" {
+		return IncidentID{}, fmt.Errorf("incident ID cannot be empty")
+	}
+	return IncidentID{value: s}, nil
+}
+
+func (id IncidentID) String() string {
+	return id.value
+}
+
+type StatusPageID struct {
+	value string
+}
+
+func NewStatusPageID() StatusPageID {
+	return StatusPageID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
+func StatusPageIDFromString(s string) (StatusPageID, error) {
+	if s == "" {
+		return StatusPageID{}, fmt.Errorf("status page ID cannot be empty")
+	}
+	return StatusPageID{value: s}, nil
+}
+
+func (id StatusPageID) String() string {
+	return id.value
+}
+
+type MonitorType string
+
+const (
+	// MonitorTypeHTTP requests a path of the application domain and checks
+	// the response status
+	MonitorTypeHTTP MonitorType = "http"
+	// MonitorTypeTCP only checks that a connection to the application domain
+	// can be opened
+	MonitorTypeTCP MonitorType = "tcp"
+)
+
+func (t MonitorType) IsValid() bool {
+	return t == MonitorTypeHTTP || t == MonitorTypeTCP
+}
+
+// MonitorConfig holds how a monitor checks its application. Zero values are
+// replaced by defaults when a monitor is created or updated.
+type MonitorConfig struct {
+	Path string
+	Port int
+	// ExpectedStatus is the status an HTTP check must get, 0 accepts any 2xx
+	// or 3xx status
+	ExpectedStatus int
+	Interval       time.Duration
+	Timeout        time.Duration
+	// FailureThreshold is how many checks in a row must fail before an
+	// incident is opened
+	FailureThreshold int
+}
+
+func (c MonitorConfig) withDefaults() MonitorConfig {
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.Port == 0 {
+		c.Port = 80
+	}
+	if c.Interval == 0 {
+		c.Interval = time.Minute
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.FailureThreshold == 0 {
+		c.FailureThreshold = 3
+	}
+	return c
+}
+
+func (c MonitorConfig) validate() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if c.ExpectedStatus != 0 && (c.ExpectedStatus < 100 || c.ExpectedStatus > 599) {
+		return fmt.Errorf("expected status must be an HTTP status code")
+	}
+	if c.Interval < 30*time.Second || c.Interval > time.Hour {
+		return fmt.Errorf("interval must be between 30 seconds and 1 hour")
+	}
+	if c.Timeout < time.Second || c.Timeout > 30*time.Second {
+		return fmt.Errorf("timeout must be between 1 and 30 seconds")
+	}
+	if c.Timeout >= c.Interval {
+		return fmt.Errorf("timeout must be shorter than the interval")
+	}
+	if c.FailureThreshold < 1 || c.FailureThreshold > 10 {
+		return fmt.Errorf("failure threshold must be between 1 and 10")
+	}
+	return nil
+}
+
+// Monitor periodically checks the domain of an application from the control
+// plane. The domain is read from the application on every check so a monitor
+// follows domain changes.
+type Monitor struct {
+	id            MonitorID
+	projectID     uuid.UUID
+	applicationID uuid.UUID
+	name          string
+	monitorType   MonitorType
+	config        MonitorConfig
+	enabled       bool
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+func NewMonitor(projectID, applicationID uuid.UUID, name string, monitorType MonitorType, config MonitorConfig) (*Monitor, error) {
+	if !monitorType.IsValid() {
+		return nil, fmt.Errorf("invalid monitor type: %s", monitorType)
+	}
+
+	now := time.Now()
+	monitor := &Monitor{
+		id:            NewMonitorID(),
+		projectID:     projectID,
+		applicationID: applicationID,
+		monitorType:   monitorType,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+
+	if err := monitor.Update(name, config, true); err != nil {
+		return nil, err
+	}
+
+	return monitor, nil
+}
+
+func (m *Monitor) ID() MonitorID {
+	return m.id
+}
+
+func (m *Monitor) ProjectID() uuid.UUID {
+	return m.projectID
+}
+
+func (m *Monitor) ApplicationID() uuid.UUID {
+	return m.applicationID
+}
+
+func (m *Monitor) Name() string {
+	return m.name
+}
+
+func (m *Monitor) Type() MonitorType {
+	return m.monitorType
+}
+
+func (m *Monitor) Config() MonitorConfig {
+	return m.config
+}
+
+func (m *Monitor) Enabled() bool {
+	return m.enabled
+}
+
+func (m *Monitor) CreatedAt() time.Time {
+	return m.createdAt
+}
+
+func (m *Monitor) UpdatedAt() time.Time {
+	return m.updatedAt
+}
+
+// Update changes everything but the type and application of the monitor,
+// which its history is about
+func (m *Monitor) Update(name string, config MonitorConfig, enabled bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("monitor name cannot be empty")
+	}
+
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	m.name = name
+	m.config = config
+	m.enabled = enabled
+	m.updatedAt = time.Now()
+	return nil
+}
+
+// Target returns what the monitor checks on a domain: a URL for HTTP
+// monitors, host:port for TCP ones. Port 443 is requested over HTTPS.
+func (m *Monitor) Target(domain string) string {
+	address := net.JoinHostPort(domain, strconv.Itoa(m.config.Port))
+	if m.monitorType == MonitorTypeTCP {
+		return address
+	}
+
+	switch m.config.Port {
+	case 80:
+		return "http://" + domain + m.config.Path
+	case 443:
+		return "https://" + domain + m.config.Path
+	}
+	return "http://" + address + m.config.Path
+}
+
+func ReconstructMonitor(
+	id MonitorID,
+	projectID, applicationID uuid.UUID,
+	name string,
+	monitorType MonitorType,
+	config MonitorConfig,
+	enabled bool,
+	createdAt, updatedAt time.Time,
+) *Monitor {
+	return &Monitor{
+		id:            id,
+		projectID:     projectID,
+		applicationID: applicationID,
+		name:          name,
+		monitorType:   monitorType,
+		config:        config,
+		enabled:       enabled,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+// CheckResult is the outcome of one check of a monitor
+type CheckResult struct {
+	MonitorID    MonitorID
+	Success      bool
+	StatusCode   int
+	ResponseTime time.Duration
+	// Error describes why a failed check failed
+	Error     string
+	CheckedAt time.Time
+}
+
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen     IncidentStatus = "open"
+	IncidentStatusResolved IncidentStatus = "resolved"
+)
+
+// Incident is a period a monitor was down. It opens once the failure
+// threshold of the monitor is reached and resolves on the next successful
+// check.
+type Incident struct {
+	id         IncidentID
+	monitorID  MonitorID
+	projectID  uuid.UUID
+	status     IncidentStatus
+	cause      string
+	startedAt  time.Time
+	resolvedAt *time.Time
+}
+
+// NewIncident opens an incident starting at the first failed check
+func NewIncident(monitor *Monitor, cause string, startedAt time.Time) *Incident {
+	return &Incident{
+		id:        NewIncidentID(),
+		monitorID: monitor.ID(),
+		projectID: monitor.ProjectID(),
+		status:    IncidentStatusOpen,
+		cause:     cause,
+		startedAt: startedAt,
+	}
+}
+
+func (i *Incident) ID() IncidentID {
+	return i.id
+}
+
+func (i *Incident) MonitorID() MonitorID {
+	return i.monitorID
+}
+
+func (i *Incident) ProjectID() uuid.UUID {
+	return i.projectID
+}
+
+func (i *Incident) Status() IncidentStatus {
+	return i.status
+}
+
+func (i *Incident) Cause() string {
+	return i.cause
+}
+
+func (i *Incident) StartedAt() time.Time {
+	return i.startedAt
+}
+
+func (i *Incident) ResolvedAt() *time.Time {
+	return i.resolvedAt
+}
+
+// Duration is how long the incident lasted, or has lasted so far
+func (i *Incident) Duration() time.Duration {
+	if i.resolvedAt != nil {
+		return i.resolvedAt.Sub(i.startedAt)
+	}
+	return time.Since(i.startedAt)
+}
+
+func (i *Incident) Resolve(at time.Time) {
+	i.status = IncidentStatusResolved
+	i.resolvedAt = &at
+}
+
+func ReconstructIncident(
+	id IncidentID,
+	monitorID MonitorID,
+	projectID uuid.UUID,
+	status IncidentStatus,
+	cause string,
+	startedAt time.Time,
+	resolvedAt *time.Time,
+) *Incident {
+	return &Incident{
+		id:         id,
+		monitorID:  monitorID,
+		projectID:  projectID,
+		status:     status,
+		cause:      cause,
+		startedAt:  startedAt,
+		resolvedAt: resolvedAt,
+	}
+}
+
+// DailyUptime counts the checks of a monitor on one UTC day
+type DailyUptime struct {
+	Day       time.Time
+	Checks    int
+	Successes int
+}
+
+// Percent returns the share of successful checks, 100 for a day without any
+func (d DailyUptime) Percent() float64 {
+	if d.Checks == 0 {
+		return 100
+	}
+	return float64(d.Successes) / float64(d.Checks) * 100
+}
+
+// StatusPage publishes the state of selected monitors of an organization. It
+// is served under its slug and, when set, on a custom domain routed to the
+// control plane.
+type StatusPage struct {
+	id             StatusPageID
+	organizationID users.OrganizationID
+	name           string
+	slug           string
+	customDomain   string
+	monitorIDs     []MonitorID
+	enabled        bool
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+func NewStatusPage(organizationID users.OrganizationID, name, slug, customDomain string, monitorIDs []MonitorID) (*StatusPage, error) {
+	now := time.Now()
+	page := &StatusPage{
+		id:             NewStatusPageID(),
+		organizationID: organizationID,
+		createdAt:      now,
+		updatedAt:      now,
+	}
+
+	if err := page.Update(name, slug, customDomain, monitorIDs, true); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (p *StatusPage) ID() StatusPageID {
+	return p.id
+}
+
+func (p *StatusPage) OrganizationID() users.OrganizationID {
+	return p.organizationID
+}
+
+func (p *StatusPage) Name() string {
+	return p.name
+}
+
+func (p *StatusPage) Slug() string {
+	return p.slug
+}
+
+// CustomDomain returns the domain the page is served on, empty when it is
+// only served under its slug
+func (p *StatusPage) CustomDomain() string {
+	return p.customDomain
+}
+
+func (p *StatusPage) MonitorIDs() []MonitorID {
+	return p.monitorIDs
+}
+
+func (p *StatusPage) Enabled() bool {
+	return p.enabled
+}
+
+func (p *StatusPage) CreatedAt() time.Time {
+	return p.createdAt
+}
+
+func (p *StatusPage) UpdatedAt() time.Time {
+	return p.updatedAt
+}
+
+func (p *StatusPage) Update(name, slug, customDomain string, monitorIDs []MonitorID, enabled bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("status page name cannot be empty")
+	}
+
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugRegex.MatchString(slug) {
+		return fmt.Errorf("slug must be lowercase letters, digits and dashes")
+	}
+
+	if customDomain != "" {
+		domainName, err := domains.NewDomainName(customDomain)
+		if err != nil {
+			return fmt.Errorf("invalid custom domain: %w", err)
+		}
+		customDomain = domainName.String()
+	}
+
+	p.name = name
+	p.slug = slug
+	p.customDomain = customDomain
+	p.monitorIDs = monitorIDs
+	p.enabled = enabled
+	p.updatedAt = time.Now()
+	return nil
+}
+
+func ReconstructStatusPage(
+	id StatusPageID,
+	organizationID users.OrganizationID,
+	name, slug, customDomain string,
+	monitorIDs []MonitorID,
+	enabled bool,
+	createdAt, updatedAt time.Time,
+) *StatusPage {
+	return &StatusPage{
+		id:             id,
+		organizationID: organizationID,
+		name:           name,
+		slug:           slug,
+		customDomain:   customDomain,
+		monitorIDs:     monitorIDs,
+		enabled:        enabled,
+		createdAt:      createdAt,
+		updatedAt:      updatedAt,
+	}
+}
+
+// StatusPageRoute is the custom domain of an enabled status page, routed by
+// Traefik to the page on the control plane
+type StatusPageRoute struct {
+	Slug   string
+	Domain string
+}
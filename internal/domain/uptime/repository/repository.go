@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+	"github.com/mikrocloud/mikrocloud/internal/domain/users"
+)
+
+type MonitorRepository interface {
+	Create(ctx context.Context, monitor *uptime.Monitor) error
+	GetByID(ctx context.Context, id uptime.MonitorID) (*uptime.Monitor, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*uptime.Monitor, error)
+	// ListEnabled returns the enabled monitors of every project
+	ListEnabled(ctx context.Context) ([]*uptime.Monitor, error)
+	Update(ctx context.Context, monitor *uptime.Monitor) error
+	Delete(ctx context.Context, id uptime.MonitorID) error
+	// RecordCheck counts a check in the daily rollup of its monitor
+	RecordCheck(ctx context.Context, id uptime.MonitorID, day time.Time, success bool) error
+	// ListDaily returns the rollups of a monitor from since on, oldest first
+	ListDaily(ctx context.Context, id uptime.MonitorID, since time.Time) ([]uptime.DailyUptime, error)
+}
+
+type IncidentRepository interface {
+	Create(ctx context.Context, incident *uptime.Incident) error
+	// ListOpen returns the open incidents of every project
+	ListOpen(ctx context.Context) ([]*uptime.Incident, error)
+	// ListByProject returns the newest incidents first, status filters when set
+	ListByProject(ctx context.Context, projectID uuid.UUID, status *uptime.IncidentStatus, limit int) ([]*uptime.Incident, error)
+	// ListByMonitors returns the incidents of monitors started from since on,
+	// newest first
+	ListByMonitors(ctx context.Context, ids []uptime.MonitorID, since time.Time) ([]*uptime.Incident, error)
+	Update(ctx context.Context, incident *uptime.Incident) error
+}
+
+type StatusPageRepository interface {
+	Create(ctx context.Context, page *uptime.StatusPage) error
+	GetByID(ctx context.Context, id uptime.StatusPageID) (*uptime.StatusPage, error)
+	GetBySlug(ctx context.Context, slug string) (*uptime.StatusPage, error)
+	GetByCustomDomain(ctx context.Context, domain string) (*uptime.StatusPage, error)
+	ListByOrganization(ctx context.Context, organizationID users.OrganizationID) ([]*uptime.StatusPage, error)
+	// ListWithCustomDomain returns the enabled pages of every organization
+	// served on a custom domain
+	ListWithCustomDomain(ctx context.Context) ([]*uptime.StatusPage, error)
+	Update(ctx context.Context, page *uptime.StatusPage) error
+	Delete(ctx context.Context, id uptime.StatusPageID) error
+}
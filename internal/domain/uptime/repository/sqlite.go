@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+	"github.com/mikrocloud/mikrocloud/internal/domain/users"
+)
+
+const monitorColumns = `id, project_id, application_id, name, type, path, port, expected_status, interval_seconds, timeout_seconds, failure_threshold, enabled, created_at, updated_at`
+
+const incidentColumns = `id, monitor_id, project_id, status, cause, started_at, resolved_at`
+
+const statusPageColumns = `id, organization_id, name, slug, custom_domain, monitor_ids, enabled, created_at, updated_at`
+
+// dayLayout is how the days of uptime_daily are stored
+const dayLayout = "2006-01-02"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type SQLiteMonitorRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteMonitorRepository(db *sql.DB) *SQLiteMonitorRepository {
+	return &SQLiteMonitorRepository{db: db}
+}
+
+func (r *SQLiteMonitorRepository) Create(ctx context.Context, monitor *uptime.Monitor) error {
+	query := `INSERT INTO uptime_monitors (` + monitorColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	config := monitor.Config()
+	_, err := r.db.ExecContext(ctx, query,
+		monitor.ID().String(),
+		monitor.ProjectID().String(),
+		monitor.ApplicationID().String(),
+		monitor.Name(),
+		string(monitor.Type()),
+		config.Path,
+		config.Port,
+		config.ExpectedStatus,
+		int64(config.Interval/time.Second),
+		int64(config.Timeout/time.Second),
+		config.FailureThreshold,
+		monitor.Enabled(),
+		monitor.CreatedAt(),
+		monitor.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteMonitorRepository) GetByID(ctx context.Context, id uptime.MonitorID) (*uptime.Monitor, error) {
+	query := `SELECT ` + monitorColumns + ` FROM uptime_monitors WHERE id = ?`
+	return r.scanMonitor(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteMonitorRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*uptime.Monitor, error) {
+	query := `SELECT ` + monitorColumns + ` FROM uptime_monitors WHERE project_id = ? ORDER BY name ASC, created_at ASC`
+	return r.list(ctx, query, projectID.String())
+}
+
+func (r *SQLiteMonitorRepository) ListEnabled(ctx context.Context) ([]*uptime.Monitor, error) {
+	query := `SELECT ` + monitorColumns + ` FROM uptime_monitors WHERE enabled = TRUE ORDER BY project_id, created_at ASC`
+	return r.list(ctx, query)
+}
+
+func (r *SQLiteMonitorRepository) Update(ctx context.Context, monitor *uptime.Monitor) error {
+	query := `UPDATE uptime_monitors SET name = ?, path = ?, port = ?, expected_status = ?, interval_seconds = ?, timeout_seconds = ?, failure_threshold = ?, enabled = ?, updated_at = ? WHERE id = ?`
+
+	config := monitor.Config()
+	_, err := r.db.ExecContext(ctx, query,
+		monitor.Name(),
+		config.Path,
+		config.Port,
+		config.ExpectedStatus,
+		int64(config.Interval/time.Second),
+		int64(config.Timeout/time.Second),
+		config.FailureThreshold,
+		monitor.Enabled(),
+		monitor.UpdatedAt(),
+		monitor.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteMonitorRepository) Delete(ctx context.Context, id uptime.MonitorID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM uptime_monitors WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteMonitorRepository) RecordCheck(ctx context.Context, id uptime.MonitorID, day time.Time, success bool) error {
+	successes := 0
+	if success {
+		successes = 1
+	}
+
+	query := `INSERT INTO uptime_daily (monitor_id, day, checks, successes) VALUES (?, ?, 1, ?)
+		ON CONFLICT (monitor_id, day) DO UPDATE SET checks = checks + 1, successes = successes + excluded.successes`
+
+	_, err := r.db.ExecContext(ctx, query, id.String(), day.UTC().Format(dayLayout), successes)
+	return err
+}
+
+func (r *SQLiteMonitorRepository) ListDaily(ctx context.Context, id uptime.MonitorID, since time.Time) ([]uptime.DailyUptime, error) {
+	query := `SELECT day, checks, successes FROM uptime_daily WHERE monitor_id = ? AND day >= ? ORDER BY day ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, id.String(), since.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []uptime.DailyUptime
+	for rows.Next() {
+		var (
+			day               string
+			checks, successes int
+		)
+		if err := rows.Scan(&day, &checks, &successes); err != nil {
+			return nil, err
+		}
+
+		parsed, err := time.Parse(dayLayout, day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse uptime day: %w", err)
+		}
+
+		result = append(result, uptime.DailyUptime{Day: parsed, Checks: checks, Successes: successes})
+	}
+
+	return result, rows.Err()
+}
+
+func (r *SQLiteMonitorRepository) list(ctx context.Context, query string, args ...any) ([]*uptime.Monitor, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var monitors []*uptime.Monitor
+	for rows.Next() {
+		monitor, err := r.scanMonitor(rows)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, monitor)
+	}
+
+	return monitors, rows.Err()
+}
+
+func (r *SQLiteMonitorRepository) scanMonitor(row rowScanner) (*uptime.Monitor, error) {
+	var (
+		id, projectIDStr, applicationIDStr, name, monitorType, path string
+		port, expectedStatus, failureThreshold                      int
+		intervalSeconds, timeoutSeconds                             int64
+		enabled                                                     bool
+		createdAt, updatedAt                                        time.Time
+	)
+
+	err := row.Scan(&id, &projectIDStr, &applicationIDStr, &name, &monitorType, &path, &port, &expectedStatus,
+		&intervalSeconds, &timeoutSeconds, &failureThreshold, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorID, err := uptime.MonitorIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	applicationID, err := uuid.Parse(applicationIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return uptime.ReconstructMonitor(
+		monitorID,
+		projectID,
+		applicationID,
+		name,
+		uptime.MonitorType(monitorType),
+		uptime.MonitorConfig{
+			Path:             path,
+			Port:             port,
+			ExpectedStatus:   expectedStatus,
+			Interval:         time.Duration(intervalSeconds) * time.Second,
+			Timeout:          time.Duration(timeoutSeconds) * time.Second,
+			FailureThreshold: failureThreshold,
+		},
+		enabled,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+type SQLiteIncidentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteIncidentRepository(db *sql.DB) *SQLiteIncidentRepository {
+	return &SQLiteIncidentRepository{db: db}
+}
+
+func (r *SQLiteIncidentRepository) Create(ctx context.Context, incident *uptime.Incident) error {
+	query := `INSERT INTO uptime_incidents (` + incidentColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		incident.ID().String(),
+		incident.MonitorID().String(),
+		incident.ProjectID().String(),
+		string(incident.Status()),
+		incident.Cause(),
+		incident.StartedAt(),
+		incident.ResolvedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteIncidentRepository) ListOpen(ctx context.Context) ([]*uptime.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM uptime_incidents WHERE status = ? ORDER BY started_at ASC`
+	return r.list(ctx, query, string(uptime.IncidentStatusOpen))
+}
+
+func (r *SQLiteIncidentRepository) ListByProject(ctx context.Context, projectID uuid.UUID, status *uptime.IncidentStatus, limit int) ([]*uptime.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM uptime_incidents WHERE project_id = ?`
+	args := []any{projectID.String()}
+
+	if status != nil {
+		query += ` AND status = ?`
+		args = append(args, string(*status))
+	}
+
+	query += ` ORDER BY started_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return r.list(ctx, query, args...)
+}
+
+func (r *SQLiteIncidentRepository) ListByMonitors(ctx context.Context, ids []uptime.MonitorID, since time.Time) ([]*uptime.Incident, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, 0, len(ids)+1)
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id.String())
+	}
+	args = append(args, since)
+
+	query := `SELECT ` + incidentColumns + ` FROM uptime_incidents
+		WHERE monitor_id IN (` + strings.Join(placeholders, ", ") + `) AND started_at >= ?
+		ORDER BY started_at DESC, id DESC`
+
+	return r.list(ctx, query, args...)
+}
+
+func (r *SQLiteIncidentRepository) Update(ctx context.Context, incident *uptime.Incident) error {
+	query := `UPDATE uptime_incidents SET status = ?, cause = ?, resolved_at = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query,
+		string(incident.Status()),
+		incident.Cause(),
+		incident.ResolvedAt(),
+		incident.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteIncidentRepository) list(ctx context.Context, query string, args ...any) ([]*uptime.Incident, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []*uptime.Incident
+	for rows.Next() {
+		incident, err := r.scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, rows.Err()
+}
+
+func (r *SQLiteIncidentRepository) scanIncident(row rowScanner) (*uptime.Incident, error) {
+	var (
+		id, monitorIDStr, projectIDStr, status, cause string
+		startedAt                                     time.Time
+		resolvedAt                                    sql.NullTime
+	)
+
+	err := row.Scan(&id, &monitorIDStr, &projectIDStr, &status, &cause, &startedAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	incidentID, err := uptime.IncidentIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorID, err := uptime.MonitorIDFromString(monitorIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved *time.Time
+	if resolvedAt.Valid {
+		resolved = &resolvedAt.Time
+	}
+
+	return uptime.ReconstructIncident(
+		incidentID,
+		monitorID,
+		projectID,
+		uptime.IncidentStatus(status),
+		cause,
+		startedAt,
+		resolved,
+	), nil
+}
+
+type SQLiteStatusPageRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteStatusPageRepository(db *sql.DB) *SQLiteStatusPageRepository {
+	return &SQLiteStatusPageRepository{db: db}
+}
+
+func (r *SQLiteStatusPageRepository) Create(ctx context.Context, page *uptime.StatusPage) error {
+	monitorIDs, err := marshalMonitorIDs(page.MonitorIDs())
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO status_pages (` + statusPageColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		page.ID().String(),
+		page.OrganizationID().String(),
+		page.Name(),
+		page.Slug(),
+		nullableString(page.CustomDomain()),
+		monitorIDs,
+		page.Enabled(),
+		page.CreatedAt(),
+		page.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *SQLiteStatusPageRepository) GetByID(ctx context.Context, id uptime.StatusPageID) (*uptime.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE id = ?`
+	return r.scanPage(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SQLiteStatusPageRepository) GetBySlug(ctx context.Context, slug string) (*uptime.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE slug = ?`
+	return r.scanPage(r.db.QueryRowContext(ctx, query, slug))
+}
+
+func (r *SQLiteStatusPageRepository) GetByCustomDomain(ctx context.Context, domain string) (*uptime.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE custom_domain = ?`
+	return r.scanPage(r.db.QueryRowContext(ctx, query, domain))
+}
+
+func (r *SQLiteStatusPageRepository) ListByOrganization(ctx context.Context, organizationID users.OrganizationID) ([]*uptime.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE organization_id = ? ORDER BY name ASC, created_at ASC`
+	return r.list(ctx, query, organizationID.String())
+}
+
+func (r *SQLiteStatusPageRepository) ListWithCustomDomain(ctx context.Context) ([]*uptime.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE enabled = TRUE AND custom_domain IS NOT NULL ORDER BY custom_domain ASC`
+	return r.list(ctx, query)
+}
+
+func (r *SQLiteStatusPageRepository) Update(ctx context.Context, page *uptime.StatusPage) error {
+	monitorIDs, err := marshalMonitorIDs(page.MonitorIDs())
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE status_pages SET name = ?, slug = ?, custom_domain = ?, monitor_ids = ?, enabled = ?, updated_at = ? WHERE id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		page.Name(),
+		page.Slug(),
+		nullableString(page.CustomDomain()),
+		monitorIDs,
+		page.Enabled(),
+		page.UpdatedAt(),
+		page.ID().String(),
+	)
+
+	return err
+}
+
+func (r *SQLiteStatusPageRepository) Delete(ctx context.Context, id uptime.StatusPageID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM status_pages WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *SQLiteStatusPageRepository) list(ctx context.Context, query string, args ...any) ([]*uptime.StatusPage, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []*uptime.StatusPage
+	for rows.Next() {
+		page, err := r.scanPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, rows.Err()
+}
+
+func (r *SQLiteStatusPageRepository) scanPage(row rowScanner) (*uptime.StatusPage, error) {
+	var (
+		id, organizationIDStr, name, slug, monitorIDsJSON string
+		customDomain                                      sql.NullString
+		enabled                                           bool
+		createdAt, updatedAt                              time.Time
+	)
+
+	err := row.Scan(&id, &organizationIDStr, &name, &slug, &customDomain, &monitorIDsJSON, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	pageID, err := uptime.StatusPageIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationID, err := users.OrganizationIDFromString(organizationIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawMonitorIDs []string
+	if err := json.Unmarshal([]byte(monitorIDsJSON), &rawMonitorIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal monitor IDs: %w", err)
+	}
+
+	monitorIDs := make([]uptime.MonitorID, 0, len(rawMonitorIDs))
+	for _, raw := range rawMonitorIDs {
+		monitorID, err := uptime.MonitorIDFromString(raw)
+		if err != nil {
+			return nil, err
+		}
+		monitorIDs = append(monitorIDs, monitorID)
+	}
+
+	return uptime.ReconstructStatusPage(
+		pageID,
+		organizationID,
+		name,
+		slug,
+		customDomain.String,
+		monitorIDs,
+		enabled,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+func marshalMonitorIDs(monitorIDs []uptime.MonitorID) (string, error) {
+	raw := make([]string, 0, len(monitorIDs))
+	for _, monitorID := range monitorIDs {
+		raw = append(raw, monitorID.String())
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal monitor IDs: %w", err)
+	}
+	return string(data), nil
+}
+
+// nullableString stores an empty value as NULL, which the unique custom
+// domain index allows any number of times
+func nullableString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
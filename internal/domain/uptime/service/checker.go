@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/analytics"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+)
+
+const (
+	// checkWorkers bounds the monitors checked at once
+	checkWorkers = 16
+
+	// maxBodyRead is how much of a response is read before it is closed, so
+	// that connections can be reused
+	maxBodyRead = 64 << 10
+
+	checkUserAgent = "mikrocloud-uptime/1.0"
+)
+
+type MetricRecorder interface {
+	RecordMetrics(ctx context.Context, metrics []*analytics.Metric) error
+}
+
+// monitorState is what the checker remembers of a monitor between checks
+type monitorState struct {
+	lastCheck time.Time
+	failures  int
+	// firstFailure is when the current run of failed checks started, an
+	// incident opened for it starts there
+	firstFailure time.Time
+}
+
+type dueCheck struct {
+	monitor *uptime.Monitor
+	app     *applications.Application
+}
+
+// UptimeChecker checks the enabled monitors of every project when their
+// interval has passed. Results are recorded as metrics and daily rollups, an
+// incident is opened once a monitor fails its threshold of checks in a row
+// and resolved by the next successful check.
+type UptimeChecker struct {
+	uptimeService *UptimeService
+	apps          ApplicationGetter
+	metrics       MetricRecorder
+	client        *http.Client
+	interval      time.Duration
+
+	states map[uptime.MonitorID]*monitorState
+	stopCh chan struct{}
+}
+
+func NewUptimeChecker(uptimeService *UptimeService, appGetter ApplicationGetter, metricRecorder MetricRecorder, interval time.Duration) *UptimeChecker {
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+
+	return &UptimeChecker{
+		uptimeService: uptimeService,
+		apps:          appGetter,
+		metrics:       metricRecorder,
+		client: &http.Client{
+			// A redirect is a response of its own, following it would check
+			// another service
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval: interval,
+		states:   make(map[uptime.MonitorID]*monitorState),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start checks the due monitors every interval until ctx is cancelled
+func (c *UptimeChecker) Start(ctx context.Context) {
+	slog.Info("Starting uptime checker", "interval", c.interval)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Uptime checker stopped due to context cancellation")
+			return
+		case <-c.stopCh:
+			slog.Info("Uptime checker stopped")
+			return
+		case <-ticker.C:
+			c.checkDue(ctx)
+		}
+	}
+}
+
+// Stop stops the checker
+func (c *UptimeChecker) Stop() {
+	close(c.stopCh)
+}
+
+func (c *UptimeChecker) checkDue(ctx context.Context) {
+	monitors, err := c.uptimeService.monitorRepo.ListEnabled(ctx)
+	if err != nil {
+		slog.Error("Failed to list uptime monitors", "error", err)
+		return
+	}
+
+	openIncidents, err := c.uptimeService.incidentRepo.ListOpen(ctx)
+	if err != nil {
+		slog.Error("Failed to list open incidents", "error", err)
+		return
+	}
+
+	open := make(map[uptime.MonitorID]*uptime.Incident, len(openIncidents))
+	for _, incident := range openIncidents {
+		open[incident.MonitorID()] = incident
+	}
+
+	now := time.Now()
+	enabled := make(map[uptime.MonitorID]bool, len(monitors))
+	var due []dueCheck
+
+	for _, monitor := range monitors {
+		enabled[monitor.ID()] = true
+
+		state, ok := c.states[monitor.ID()]
+		if !ok {
+			state = &monitorState{}
+			c.states[monitor.ID()] = state
+		}
+		if now.Sub(state.lastCheck) < monitor.Config().Interval {
+			continue
+		}
+		state.lastCheck = now
+
+		app, err := c.application(ctx, monitor.ApplicationID())
+		if err != nil {
+			slog.Warn("Failed to get application of uptime monitor", "monitor_id", monitor.ID().String(), "error", err)
+			continue
+		}
+		// Nothing can be checked until the application has a domain
+		if app.Domain() == "" {
+			continue
+		}
+
+		due = append(due, dueCheck{monitor: monitor, app: app})
+	}
+
+	// Monitors that were deleted or disabled start over when enabled again
+	for id := range c.states {
+		if !enabled[id] {
+			delete(c.states, id)
+		}
+	}
+
+	results := make([]uptime.CheckResult, len(due))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, checkWorkers)
+	for i, check := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.check(ctx, check.monitor, check.app.Domain())
+		}()
+	}
+	wg.Wait()
+
+	var metrics []*analytics.Metric
+	for i, check := range due {
+		result := results[i]
+		metrics = append(metrics, resultMetrics(check.monitor, result)...)
+
+		if err := c.uptimeService.monitorRepo.RecordCheck(ctx, check.monitor.ID(), result.CheckedAt, result.Success); err != nil {
+			slog.Error("Failed to record uptime check", "monitor_id", check.monitor.ID().String(), "error", err)
+		}
+
+		c.track(ctx, check.monitor, result, open[check.monitor.ID()])
+	}
+
+	if len(metrics) > 0 {
+		if err := c.metrics.RecordMetrics(ctx, metrics); err != nil {
+			slog.Error("Failed to record uptime metrics", "error", err)
+		}
+	}
+}
+
+// track counts consecutive failures of a monitor and opens or resolves its
+// incident
+func (c *UptimeChecker) track(ctx context.Context, monitor *uptime.Monitor, result uptime.CheckResult, incident *uptime.Incident) {
+	state := c.states[monitor.ID()]
+
+	if result.Success {
+		state.failures = 0
+		if incident == nil {
+			return
+		}
+
+		incident.Resolve(result.CheckedAt)
+		if err := c.uptimeService.incidentRepo.Update(ctx, incident); err != nil {
+			slog.Error("Failed to resolve incident", "incident_id", incident.ID().String(), "error", err)
+			return
+		}
+		slog.Info("Uptime incident resolved", "monitor_id", monitor.ID().String(), "incident_id", incident.ID().String())
+		return
+	}
+
+	if state.failures == 0 {
+		state.firstFailure = result.CheckedAt
+	}
+	state.failures++
+
+	if incident != nil || state.failures < monitor.Config().FailureThreshold {
+		return
+	}
+
+	incident = uptime.NewIncident(monitor, result.Error, state.firstFailure)
+	if err := c.uptimeService.incidentRepo.Create(ctx, incident); err != nil {
+		slog.Error("Failed to open incident", "monitor_id", monitor.ID().String(), "error", err)
+		return
+	}
+	slog.Warn("Uptime incident opened", "monitor_id", monitor.ID().String(), "incident_id", incident.ID().String(), "cause", result.Error)
+}
+
+func (c *UptimeChecker) check(ctx context.Context, monitor *uptime.Monitor, domain string) uptime.CheckResult {
+	result := uptime.CheckResult{MonitorID: monitor.ID(), CheckedAt: time.Now()}
+
+	checkCtx, cancel := context.WithTimeout(ctx, monitor.Config().Timeout)
+	defer cancel()
+
+	target := monitor.Target(domain)
+	start := time.Now()
+
+	if monitor.Type() == uptime.MonitorTypeTCP {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(checkCtx, "tcp", target)
+		result.ResponseTime = time.Since(start)
+		if err != nil {
+			result.Error = fmt.Sprintf("Connection to %s failed: %v", target, err)
+			return result
+		}
+		conn.Close()
+		result.Success = true
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(checkCtx, http.MethodGet, target, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid URL %s: %v", target, err)
+		return result
+	}
+	req.Header.Set("User-Agent", checkUserAgent)
+
+	resp, err := c.client.Do(req)
+	result.ResponseTime = time.Since(start)
+	if err != nil {
+		result.Error = fmt.Sprintf("Request to %s failed: %v", target, err)
+		return result
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodyRead))
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	expected := monitor.Config().ExpectedStatus
+	if (expected != 0 && resp.StatusCode == expected) || (expected == 0 && resp.StatusCode < 400) {
+		result.Success = true
+		return result
+	}
+
+	result.Error = fmt.Sprintf("%s responded with status %d", target, resp.StatusCode)
+	return result
+}
+
+func (c *UptimeChecker) application(ctx context.Context, id uuid.UUID) (*applications.Application, error) {
+	appID, err := applications.ApplicationIDFromString(id.String())
+	if err != nil {
+		return nil, err
+	}
+	return c.apps.GetApplication(ctx, appID)
+}
+
+// resultMetrics records a check on the application of its monitor. Checks
+// that got no response have no response time.
+func resultMetrics(monitor *uptime.Monitor, result uptime.CheckResult) []*analytics.Metric {
+	applicationID := monitor.ApplicationID()
+	tags := map[string]string{
+		"monitor_id":   monitor.ID().String(),
+		"monitor_type": string(monitor.Type()),
+	}
+
+	up := 0.0
+	if result.Success {
+		up = 1
+	}
+
+	var metrics []*analytics.Metric
+	if name, err := analytics.NewMetricName(uptime.MetricUp); err == nil {
+		metrics = append(metrics, analytics.NewMetric(monitor.ProjectID(), &applicationID, name, up, analytics.MetricUnitCount, tags, result.CheckedAt))
+	}
+
+	if result.Success || result.StatusCode != 0 {
+		if name, err := analytics.NewMetricName(uptime.MetricResponseTime); err == nil {
+			responseTime := float64(result.ResponseTime) / float64(time.Millisecond)
+			metrics = append(metrics, analytics.NewMetric(monitor.ProjectID(), &applicationID, name, responseTime, analytics.MetricUnitMilliseconds, tags, result.CheckedAt))
+		}
+	}
+
+	return metrics
+}
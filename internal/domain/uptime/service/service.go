@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/applications"
+	"github.com/mikrocloud/mikrocloud/internal/domain/projects"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime/repository"
+	"github.com/mikrocloud/mikrocloud/internal/domain/users"
+)
+
+// maxIncidentsListed caps the incident history returned for a project
+const maxIncidentsListed = 500
+
+var (
+	ErrMonitorNotFound     = errors.New("monitor not found")
+	ErrApplicationNotFound = errors.New("application not found")
+	ErrStatusPageNotFound  = errors.New("status page not found")
+	ErrSlugTaken           = errors.New("slug is already used by another status page")
+	ErrDomainTaken         = errors.New("domain is already used by another status page")
+)
+
+type ApplicationGetter interface {
+	GetApplication(ctx context.Context, id applications.ApplicationID) (*applications.Application, error)
+}
+
+type ProjectGetter interface {
+	GetProject(ctx context.Context, id string) (*projects.Project, error)
+}
+
+// UptimeService manages the uptime monitors of projects, the incidents the
+// checker opens for them and the status pages of organizations
+type UptimeService struct {
+	monitorRepo  repository.MonitorRepository
+	incidentRepo repository.IncidentRepository
+	pageRepo     repository.StatusPageRepository
+	apps         ApplicationGetter
+	projects     ProjectGetter
+	upstream     string
+}
+
+func NewUptimeService(
+	monitorRepo repository.MonitorRepository,
+	incidentRepo repository.IncidentRepository,
+	pageRepo repository.StatusPageRepository,
+	apps ApplicationGetter,
+	projects ProjectGetter,
+	upstream string,
+) *UptimeService {
+	return &UptimeService{
+		monitorRepo:  monitorRepo,
+		incidentRepo: incidentRepo,
+		pageRepo:     pageRepo,
+		apps:         apps,
+		projects:     projects,
+		upstream:     upstream,
+	}
+}
+
+// StatusPageUpstream returns the URL Traefik reaches the control plane at to
+// serve status pages on their custom domain
+func (s *UptimeService) StatusPageUpstream() string {
+	return s.upstream
+}
+
+type CreateMonitorCommand struct {
+	ProjectID     uuid.UUID
+	ApplicationID uuid.UUID
+	Name          string
+	Type          uptime.MonitorType
+	Config        uptime.MonitorConfig
+}
+
+func (s *UptimeService) CreateMonitor(ctx context.Context, cmd CreateMonitorCommand) (*uptime.Monitor, error) {
+	appID, err := applications.ApplicationIDFromString(cmd.ApplicationID.String())
+	if err != nil {
+		return nil, ErrApplicationNotFound
+	}
+
+	app, err := s.apps.GetApplication(ctx, appID)
+	if err != nil || app.ProjectID() != cmd.ProjectID {
+		return nil, ErrApplicationNotFound
+	}
+
+	monitor, err := uptime.NewMonitor(cmd.ProjectID, cmd.ApplicationID, cmd.Name, cmd.Type, cmd.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.monitorRepo.Create(ctx, monitor); err != nil {
+		return nil, fmt.Errorf("failed to create monitor: %w", err)
+	}
+
+	return monitor, nil
+}
+
+// GetMonitor returns a monitor of a project, ErrMonitorNotFound when it
+// belongs to another project
+func (s *UptimeService) GetMonitor(ctx context.Context, projectID uuid.UUID, monitorID string) (*uptime.Monitor, error) {
+	id, err := uptime.MonitorIDFromString(monitorID)
+	if err != nil {
+		return nil, ErrMonitorNotFound
+	}
+
+	monitor, err := s.monitorRepo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMonitorNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monitor: %w", err)
+	}
+
+	if monitor.ProjectID() != projectID {
+		return nil, ErrMonitorNotFound
+	}
+
+	return monitor, nil
+}
+
+func (s *UptimeService) ListMonitors(ctx context.Context, projectID uuid.UUID) ([]*uptime.Monitor, error) {
+	monitors, err := s.monitorRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list monitors: %w", err)
+	}
+	return monitors, nil
+}
+
+func (s *UptimeService) UpdateMonitor(ctx context.Context, projectID uuid.UUID, monitorID, name string, config uptime.MonitorConfig, enabled bool) (*uptime.Monitor, error) {
+	monitor, err := s.GetMonitor(ctx, projectID, monitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := monitor.Update(name, config, enabled); err != nil {
+		return nil, err
+	}
+
+	if err := s.monitorRepo.Update(ctx, monitor); err != nil {
+		return nil, fmt.Errorf("failed to update monitor: %w", err)
+	}
+
+	return monitor, nil
+}
+
+// DeleteMonitor removes a monitor with its history and drops it from the
+// status pages of the organization
+func (s *UptimeService) DeleteMonitor(ctx context.Context, projectID uuid.UUID, monitorID string) error {
+	monitor, err := s.GetMonitor(ctx, projectID, monitorID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.projects.GetProject(ctx, projectID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	pages, err := s.pageRepo.ListByOrganization(ctx, project.OrganizationID())
+	if err != nil {
+		return fmt.Errorf("failed to list status pages: %w", err)
+	}
+
+	for _, page := range pages {
+		if !slices.Contains(page.MonitorIDs(), monitor.ID()) {
+			continue
+		}
+
+		remaining := slices.DeleteFunc(slices.Clone(page.MonitorIDs()), func(id uptime.MonitorID) bool {
+			return id == monitor.ID()
+		})
+		if err := page.Update(page.Name(), page.Slug(), page.CustomDomain(), remaining, page.Enabled()); err != nil {
+			return err
+		}
+		if err := s.pageRepo.Update(ctx, page); err != nil {
+			return fmt.Errorf("failed to update status page: %w", err)
+		}
+	}
+
+	if err := s.monitorRepo.Delete(ctx, monitor.ID()); err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+	return nil
+}
+
+// MonitorHistory returns one rollup per UTC day for the last days days,
+// oldest first. Days without checks have no checks counted.
+func (s *UptimeService) MonitorHistory(ctx context.Context, monitor *uptime.Monitor, days int) ([]uptime.DailyUptime, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	recorded, err := s.monitorRepo.ListDaily(ctx, monitor.ID(), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monitor history: %w", err)
+	}
+
+	byDay := make(map[string]uptime.DailyUptime, len(recorded))
+	for _, day := range recorded {
+		byDay[day.Day.Format(time.DateOnly)] = day
+	}
+
+	history := make([]uptime.DailyUptime, 0, days)
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if recorded, ok := byDay[day.Format(time.DateOnly)]; ok {
+			history = append(history, recorded)
+			continue
+		}
+		history = append(history, uptime.DailyUptime{Day: day})
+	}
+
+	return history, nil
+}
+
+// ListIncidents returns the incidents of a project, newest first
+func (s *UptimeService) ListIncidents(ctx context.Context, projectID uuid.UUID, status *uptime.IncidentStatus, limit int) ([]*uptime.Incident, error) {
+	if limit <= 0 || limit > maxIncidentsListed {
+		limit = maxIncidentsListed
+	}
+
+	incidents, err := s.incidentRepo.ListByProject(ctx, projectID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+type StatusPageCommand struct {
+	Name         string
+	Slug         string
+	CustomDomain string
+	MonitorIDs   []string
+	Enabled      bool
+}
+
+func (s *UptimeService) CreateStatusPage(ctx context.Context, organizationID users.OrganizationID, cmd StatusPageCommand) (*uptime.StatusPage, error) {
+	monitorIDs, err := s.organizationMonitorIDs(ctx, organizationID, cmd.MonitorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := uptime.NewStatusPage(organizationID, cmd.Name, cmd.Slug, cmd.CustomDomain, monitorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAvailable(ctx, page); err != nil {
+		return nil, err
+	}
+
+	if err := s.pageRepo.Create(ctx, page); err != nil {
+		return nil, fmt.Errorf("failed to create status page: %w", err)
+	}
+
+	return page, nil
+}
+
+// GetStatusPage returns a status page of an organization,
+// ErrStatusPageNotFound when it belongs to another organization
+func (s *UptimeService) GetStatusPage(ctx context.Context, organizationID users.OrganizationID, pageID string) (*uptime.StatusPage, error) {
+	id, err := uptime.StatusPageIDFromString(pageID)
+	if err != nil {
+		return nil, ErrStatusPageNotFound
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStatusPageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page: %w", err)
+	}
+
+	if page.OrganizationID() != organizationID {
+		return nil, ErrStatusPageNotFound
+	}
+
+	return page, nil
+}
+
+func (s *UptimeService) ListStatusPages(ctx context.Context, organizationID users.OrganizationID) ([]*uptime.StatusPage, error) {
+	pages, err := s.pageRepo.ListByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status pages: %w", err)
+	}
+	return pages, nil
+}
+
+func (s *UptimeService) UpdateStatusPage(ctx context.Context, organizationID users.OrganizationID, pageID string, cmd StatusPageCommand) (*uptime.StatusPage, error) {
+	page, err := s.GetStatusPage(ctx, organizationID, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorIDs, err := s.organizationMonitorIDs(ctx, organizationID, cmd.MonitorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := page.Update(cmd.Name, cmd.Slug, cmd.CustomDomain, monitorIDs, cmd.Enabled); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAvailable(ctx, page); err != nil {
+		return nil, err
+	}
+
+	if err := s.pageRepo.Update(ctx, page); err != nil {
+		return nil, fmt.Errorf("failed to update status page: %w", err)
+	}
+
+	return page, nil
+}
+
+func (s *UptimeService) DeleteStatusPage(ctx context.Context, organizationID users.OrganizationID, pageID string) error {
+	page, err := s.GetStatusPage(ctx, organizationID, pageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.pageRepo.Delete(ctx, page.ID()); err != nil {
+		return fmt.Errorf("failed to delete status page: %w", err)
+	}
+	return nil
+}
+
+// ListStatusPageRoutes returns the custom domains of the enabled status pages
+func (s *UptimeService) ListStatusPageRoutes(ctx context.Context) ([]uptime.StatusPageRoute, error) {
+	pages, err := s.pageRepo.ListWithCustomDomain(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status pages: %w", err)
+	}
+
+	routes := make([]uptime.StatusPageRoute, 0, len(pages))
+	for _, page := range pages {
+		routes = append(routes, uptime.StatusPageRoute{Slug: page.Slug(), Domain: page.CustomDomain()})
+	}
+	return routes, nil
+}
+
+// checkAvailable makes sure no other page uses the slug or custom domain of
+// a page
+func (s *UptimeService) checkAvailable(ctx context.Context, page *uptime.StatusPage) error {
+	existing, err := s.pageRepo.GetBySlug(ctx, page.Slug())
+	if err == nil && existing.ID() != page.ID() {
+		return ErrSlugTaken
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check slug: %w", err)
+	}
+
+	if page.CustomDomain() == "" {
+		return nil
+	}
+
+	existing, err = s.pageRepo.GetByCustomDomain(ctx, page.CustomDomain())
+	if err == nil && existing.ID() != page.ID() {
+		return ErrDomainTaken
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check custom domain: %w", err)
+	}
+
+	return nil
+}
+
+// organizationMonitorIDs parses monitor IDs and checks they belong to
+// projects of the organization
+func (s *UptimeService) organizationMonitorIDs(ctx context.Context, organizationID users.OrganizationID, raw []string) ([]uptime.MonitorID, error) {
+	monitorIDs := make([]uptime.MonitorID, 0, len(raw))
+	for _, value := range raw {
+		id, err := uptime.MonitorIDFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("monitor %s: %w", value, ErrMonitorNotFound)
+		}
+
+		monitor, err := s.monitorRepo.GetByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("monitor %s: %w", value, ErrMonitorNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get monitor: %w", err)
+		}
+
+		project, err := s.projects.GetProject(ctx, monitor.ProjectID().String())
+		if err != nil || project.OrganizationID() != organizationID {
+			return nil, fmt.Errorf("monitor %s: %w", value, ErrMonitorNotFound)
+		}
+
+		if !slices.Contains(monitorIDs, monitor.ID()) {
+			monitorIDs = append(monitorIDs, monitor.ID())
+		}
+	}
+	return monitorIDs, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+)
+
+// ServiceState is the current state of a service shown on a status page
+type ServiceState string
+
+const (
+	ServiceStateOperational ServiceState = "operational"
+	ServiceStateDown        ServiceState = "down"
+	// ServiceStatePaused is shown for monitors that are disabled
+	ServiceStatePaused ServiceState = "paused"
+)
+
+// PublicService is a monitor as shown on a status page, without anything
+// that identifies the project or application behind it
+type PublicService struct {
+	Name  string
+	State ServiceState
+	// Uptime is the share of successful checks over the days shown
+	Uptime float64
+	Days   []uptime.DailyUptime
+}
+
+type PublicIncident struct {
+	ServiceName string
+	Cause       string
+	StartedAt   time.Time
+	ResolvedAt  *time.Time
+}
+
+// PublicStatusPage is what an enabled status page publishes
+type PublicStatusPage struct {
+	Name        string
+	Operational bool
+	Services    []PublicService
+	Incidents   []PublicIncident
+	GeneratedAt time.Time
+}
+
+// GetPublicStatusPage builds the public view of the enabled page with a slug
+func (s *UptimeService) GetPublicStatusPage(ctx context.Context, slug string) (*PublicStatusPage, error) {
+	page, err := s.pageRepo.GetBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStatusPageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status page: %w", err)
+	}
+
+	if !page.Enabled() {
+		return nil, ErrStatusPageNotFound
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(uptime.StatusPageDays - 1))
+	incidents, err := s.incidentRepo.ListByMonitors(ctx, page.MonitorIDs(), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+
+	open := make(map[uptime.MonitorID]bool)
+	for _, incident := range incidents {
+		if incident.Status() == uptime.IncidentStatusOpen {
+			open[incident.MonitorID()] = true
+		}
+	}
+
+	view := &PublicStatusPage{
+		Name:        page.Name(),
+		Operational: true,
+		Services:    make([]PublicService, 0, len(page.MonitorIDs())),
+		Incidents:   make([]PublicIncident, 0, len(incidents)),
+		GeneratedAt: time.Now(),
+	}
+
+	names := make(map[uptime.MonitorID]string, len(page.MonitorIDs()))
+	for _, id := range page.MonitorIDs() {
+		monitor, err := s.monitorRepo.GetByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get monitor: %w", err)
+		}
+		names[id] = monitor.Name()
+
+		days, err := s.MonitorHistory(ctx, monitor, uptime.StatusPageDays)
+		if err != nil {
+			return nil, err
+		}
+
+		service := PublicService{
+			Name:   monitor.Name(),
+			State:  ServiceStateOperational,
+			Uptime: overallUptime(days),
+			Days:   days,
+		}
+		switch {
+		case !monitor.Enabled():
+			service.State = ServiceStatePaused
+		case open[id]:
+			service.State = ServiceStateDown
+			view.Operational = false
+		}
+
+		view.Services = append(view.Services, service)
+	}
+
+	for _, incident := range incidents {
+		name, ok := names[incident.MonitorID()]
+		if !ok {
+			continue
+		}
+		view.Incidents = append(view.Incidents, PublicIncident{
+			ServiceName: name,
+			Cause:       incident.Cause(),
+			StartedAt:   incident.StartedAt(),
+			ResolvedAt:  incident.ResolvedAt(),
+		})
+	}
+
+	return view, nil
+}
+
+// overallUptime returns the share of successful checks over days, 100 when
+// nothing was checked
+func overallUptime(days []uptime.DailyUptime) float64 {
+	total := uptime.DailyUptime{}
+	for _, day := range days {
+		total.Checks += day.Checks
+		total.Successes += day.Successes
+	}
+	return total.Percent()
+}
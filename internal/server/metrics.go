@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/exp/slog"
+
+	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+	"github.com/mikrocloud/mikrocloud/internal/domain/deployments"
+)
+
+// metricsCollectTimeout bounds the database and runtime lookups of a scrape
+const metricsCollectTimeout = 10 * time.Second
+
+// httpMetrics counts the requests served by the router, labelled with the
+// route pattern rather than the path so IDs don't explode the series
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newHTTPMetrics(registerer prometheus.Registerer) *httpMetrics {
+	m := &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mikrocloud_http_requests_total",
+			Help: "HTTP requests served by route, method and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mikrocloud_http_request_duration_seconds",
+			Help:    "Latency of HTTP requests by route and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+
+	registerer.MustRegister(m.requests, m.duration)
+	return m
+}
+
+func (m *httpMetrics) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		// The pattern is only complete once the sub-routers have matched
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.requests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		m.duration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// newMetricsRegistry registers the runtime, database pool and control plane
+// collectors
+func newMetricsRegistry(d *deps.Dependencies) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(d.DB.MainDB().DB(), "main"),
+		newControlPlaneCollector(d),
+	)
+
+	// DuckDB and SQLite analytics databases share database/sql, other backends
+	// manage their own connections
+	if analyticsDB, ok := d.DB.AnalyticsDB().DB().(*sql.DB); ok {
+		registry.MustRegister(collectors.NewDBStatsCollector(analyticsDB, "analytics"))
+	}
+
+	return registry
+}
+
+// startMetricsServer serves /metrics on the exporter port. Errors are sent to
+// errCh like those of the API server.
+func (s *Server) startMetricsServer(errCh chan<- error) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(s.metrics, promhttp.HandlerOpts{}))
+
+	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Metrics.ExporterPort)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	slog.Info("Starting metrics exporter", "address", addr)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- fmt.Errorf("metrics exporter: %w", err)
+		}
+	}()
+
+	return server
+}
+
+// controlPlaneCollector reads deployment, container and per service usage
+// figures on every scrape so they never go stale between restarts
+type controlPlaneCollector struct {
+	deps *deps.Dependencies
+
+	deployments    *prometheus.Desc
+	buildDuration  *prometheus.Desc
+	deployDuration *prometheus.Desc
+	buildQueue     *prometheus.Desc
+	containers     *prometheus.Desc
+
+	containerCPU        *prometheus.Desc
+	containerMemory     *prometheus.Desc
+	containerMemLimit   *prometheus.Desc
+	containerNetRx      *prometheus.Desc
+	containerNetTx      *prometheus.Desc
+	containerBlockRead  *prometheus.Desc
+	containerBlockWrite *prometheus.Desc
+}
+
+func newControlPlaneCollector(d *deps.Dependencies) *controlPlaneCollector {
+	containerLabels := []string{"project_id", "service_id", "kind", "container_id"}
+
+	return &controlPlaneCollector{
+		deps: d,
+
+		deployments: prometheus.NewDesc("mikrocloud_deployments",
+			"Deployments by status.", []string{"status"}, nil),
+		buildDuration: prometheus.NewDesc("mikrocloud_deployment_build_duration_seconds",
+			"Build durations of deployments by status.", []string{"status"}, nil),
+		deployDuration: prometheus.NewDesc("mikrocloud_deployment_deploy_duration_seconds",
+			"Deploy durations of deployments by status.", []string{"status"}, nil),
+		buildQueue: prometheus.NewDesc("mikrocloud_build_queue_depth",
+			"Deployments waiting for a build to start.", nil, nil),
+		containers: prometheus.NewDesc("mikrocloud_containers",
+			"Containers of the container runtime by state.", []string{"state"}, nil),
+
+		containerCPU: prometheus.NewDesc("mikrocloud_container_cpu_percent",
+			"CPU usage of an application or database container.", containerLabels, nil),
+		containerMemory: prometheus.NewDesc("mikrocloud_container_memory_usage_bytes",
+			"Memory used by an application or database container.", containerLabels, nil),
+		containerMemLimit: prometheus.NewDesc("mikrocloud_container_memory_limit_bytes",
+			"Memory limit of an application or database container.", containerLabels, nil),
+		containerNetRx: prometheus.NewDesc("mikrocloud_container_network_receive_bytes_total",
+			"Bytes received by a container since it started.", containerLabels, nil),
+		containerNetTx: prometheus.NewDesc("mikrocloud_container_network_transmit_bytes_total",
+			"Bytes sent by a container since it started.", containerLabels, nil),
+		containerBlockRead: prometheus.NewDesc("mikrocloud_container_block_read_bytes_total",
+			"Bytes read from block devices by a container since it started.", containerLabels, nil),
+		containerBlockWrite: prometheus.NewDesc("mikrocloud_container_block_write_bytes_total",
+			"Bytes written to block devices by a container since it started.", containerLabels, nil),
+	}
+}
+
+func (c *controlPlaneCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.deployments
+	ch <- c.buildDuration
+	ch <- c.deployDuration
+	ch <- c.buildQueue
+	ch <- c.containers
+	ch <- c.containerCPU
+	ch <- c.containerMemory
+	ch <- c.containerMemLimit
+	ch <- c.containerNetRx
+	ch <- c.containerNetTx
+	ch <- c.containerBlockRead
+	ch <- c.containerBlockWrite
+}
+
+func (c *controlPlaneCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsCollectTimeout)
+	defer cancel()
+
+	c.collectDeployments(ctx, ch)
+	c.collectContainers(ctx, ch)
+	c.collectContainerUsage(ch)
+}
+
+func (c *controlPlaneCollector) collectDeployments(ctx context.Context, ch chan<- prometheus.Metric) {
+	summaries, err := c.deps.DeploymentService.SummarizeDeployments(ctx)
+	if err != nil {
+		slog.Warn("Failed to collect deployment metrics", "error", err)
+		return
+	}
+
+	var queued int64
+	for _, summary := range summaries {
+		status := string(summary.Status)
+
+		ch <- prometheus.MustNewConstMetric(c.deployments, prometheus.GaugeValue, float64(summary.Count), status)
+		ch <- prometheus.MustNewConstSummary(c.buildDuration, uint64(summary.BuildCount), float64(summary.BuildSeconds), nil, status)
+		ch <- prometheus.MustNewConstSummary(c.deployDuration, uint64(summary.DeployCount), float64(summary.DeploySeconds), nil, status)
+
+		if summary.Status == deployments.DeploymentStatusPending || summary.Status == deployments.DeploymentStatusQueued {
+			queued += summary.Count
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.buildQueue, prometheus.GaugeValue, float64(queued))
+}
+
+func (c *controlPlaneCollector) collectContainers(ctx context.Context, ch chan<- prometheus.Metric) {
+	containers, err := c.deps.ContainerService.ListContainers(ctx)
+	if err != nil {
+		slog.Warn("Failed to collect container metrics", "error", err)
+		return
+	}
+
+	states := make(map[string]int)
+	for _, container := range containers {
+		states[container.State]++
+	}
+
+	for state, count := range states {
+		ch <- prometheus.MustNewConstMetric(c.containers, prometheus.GaugeValue, float64(count), state)
+	}
+}
+
+// collectContainerUsage exports the last samples of the metric sampler rather
+// than asking the runtime for stats, which takes a second per container
+func (c *controlPlaneCollector) collectContainerUsage(ch chan<- prometheus.Metric) {
+	for _, sample := range c.deps.MetricSampler.Snapshot() {
+		labels := []string{sample.ProjectID.String(), sample.ServiceID.String(), sample.Kind, sample.ContainerID}
+		stats := sample.Stats
+
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerCPU, prometheus.GaugeValue, stats.CPUPercent, labels...))
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerMemory, prometheus.GaugeValue, float64(stats.MemoryUsage), labels...))
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerMemLimit, prometheus.GaugeValue, float64(stats.MemoryLimit), labels...))
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerNetRx, prometheus.CounterValue, float64(stats.NetworkRxBytes), labels...))
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerNetTx, prometheus.CounterValue, float64(stats.NetworkTxBytes), labels...))
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerBlockRead, prometheus.CounterValue, float64(stats.BlockReadBytes), labels...))
+		ch <- prometheus.NewMetricWithTimestamp(sample.SampledAt,
+			prometheus.MustNewConstMetric(c.containerBlockWrite, prometheus.CounterValue, float64(stats.BlockWriteBytes), labels...))
+	}
+}
@@ -18,11 +18,13 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/exp/slog"
 
 	"github.com/mikrocloud/mikrocloud/internal/api"
 	"github.com/mikrocloud/mikrocloud/internal/api/deps"
+	apiMiddleware "github.com/mikrocloud/mikrocloud/internal/api/middleware"
 	"github.com/mikrocloud/mikrocloud/internal/config"
 	"github.com/mikrocloud/mikrocloud/internal/database"
 	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
@@ -36,6 +38,10 @@ type Server struct {
 	router     *chi.Mux
 	staticFS   fs.FS
 	traefikSvc *proxyContainers.TraefikService
+
+	// metrics is nil unless the metrics exporter is enabled
+	metrics     *prometheus.Registry
+	httpMetrics *httpMetrics
 }
 
 func New(cfg *config.Config, staticFS fs.FS) *Server {
@@ -53,12 +59,19 @@ func New(cfg *config.Config, staticFS fs.FS) *Server {
 
 	router := chi.NewRouter()
 
-	return &Server{
+	s := &Server{
 		config:   cfg,
 		deps:     dependencies,
 		router:   router,
 		staticFS: staticFS,
 	}
+
+	if cfg.Metrics.Enabled {
+		s.metrics = newMetricsRegistry(dependencies)
+		s.httpMetrics = newHTTPMetrics(s.metrics)
+	}
+
+	return s
 }
 
 func (s *Server) Start(ctx context.Context) error {
@@ -91,7 +104,7 @@ func (s *Server) Start(ctx context.Context) error {
 	slog.Info("Starting Mikrocloud server", "address", addr)
 
 	// Start server in a goroutine
-	serverChan := make(chan error, 1)
+	serverChan := make(chan error, 2)
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -99,6 +112,11 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
+	var metricsServer *http.Server
+	if s.metrics != nil {
+		metricsServer = s.startMetricsServer(serverChan)
+	}
+
 	// Wait for interrupt signal or server error
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -121,6 +139,12 @@ func (s *Server) Start(ctx context.Context) error {
 			}
 		}
 
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+				slog.Error("Error stopping metrics exporter", "error", err)
+			}
+		}
+
 		if err := server.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("server shutdown error: %w", err)
 		}
@@ -138,8 +162,14 @@ func (s *Server) setupMiddlewares() {
 
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.RealIP)
+
+	if s.httpMetrics != nil {
+		s.router.Use(s.httpMetrics.handler)
+	}
+
 	s.router.Use(middleware.Recoverer)
-	s.router.Use(middleware.Timeout(60 * time.Second))
+	// Long-running routes opt out with apiMiddleware.NoTimeout
+	s.router.Use(apiMiddleware.Timeout(60 * time.Second))
 
 	// TODO: Pipe to a file instead of clogging the term
 	s.router.Use(func(next http.Handler) http.Handler {
@@ -174,6 +204,34 @@ func (s *Server) setupDependencies(ctx context.Context) error {
 
 		s.traefikSvc = s.deps.TraefikService
 		slog.Info("Traefik proxy container started successfully")
+
+		if err := s.deps.ErrorPagesService.Start(ctx); err != nil {
+			slog.Error("Failed to start error pages container", "error", err)
+		}
+
+		routes, err := s.deps.ProxyService.ListMaintenanceRoutes(ctx)
+		if err == nil {
+			err = s.deps.TraefikService.WriteMaintenanceConfig(routes)
+		}
+		if err != nil {
+			slog.Error("Failed to apply maintenance routes", "error", err)
+		}
+
+		rules, err := s.deps.ProxyService.ListEnabledRedirectRules(ctx)
+		if err == nil {
+			err = s.deps.TraefikService.WriteRedirectConfig(rules)
+		}
+		if err != nil {
+			slog.Error("Failed to apply redirect rules", "error", err)
+		}
+
+		statusPageRoutes, err := s.deps.UptimeService.ListStatusPageRoutes(ctx)
+		if err == nil {
+			err = s.deps.TraefikService.WriteStatusPageConfig(s.deps.UptimeService.StatusPageUpstream(), statusPageRoutes)
+		}
+		if err != nil {
+			slog.Error("Failed to apply status page routes", "error", err)
+		}
 	}
 
 	return nil
@@ -294,7 +352,21 @@ func (s *Server) serveIndexHTML(frontendFS fs.FS) http.HandlerFunc {
 }
 
 func (s *Server) setupBackgroundTasks(ctx context.Context) {
+	if err := s.deps.TelemetryService.HashLegacyTokens(ctx); err != nil {
+		slog.Error("Failed to hash stored ingest tokens", "error", err)
+	}
+
 	go s.deps.DatabaseStatusSyncService.Start(ctx)
+	go s.deps.AccessLogCollector.Start(ctx)
+	go s.deps.ContainerLogCollector.Start(ctx)
+	go s.deps.MetricSampler.Start(ctx)
+	go s.deps.AlertEvaluator.Start(ctx)
+	// Tunnel health feeds the tunnel_unhealthy alert rules
+	s.deps.TunnelService.StartHealthCheckMonitor(ctx, time.Minute)
+	go s.deps.BackupScheduler.Start(ctx)
+	go s.deps.SpanPruner.Start(ctx)
+	go s.deps.UptimeChecker.Start(ctx)
+	go s.deps.ReportScheduler.Start(ctx)
 }
 
 func (s *Server) initializeControlPlaneServer(ctx context.Context) error {
@@ -4,76 +4,64 @@
 -- Metrics table for time-series data
 CREATE TABLE IF NOT EXISTS metrics (
     id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    service_id TEXT,
     name TEXT NOT NULL,
     value REAL NOT NULL,
-    project_id TEXT NOT NULL DEFAULT '',
-    service_id TEXT,
-    unit TEXT NOT NULL DEFAULT 'count',
+    unit TEXT NOT NULL,
     tags TEXT NOT NULL DEFAULT '{}', -- JSON object
-    timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+    timestamp BIGINT NOT NULL, -- unix seconds
+    created_at BIGINT NOT NULL -- unix seconds
 );
 
--- Index for time-based queries
-CREATE INDEX IF NOT EXISTS idx_metrics_timestamp ON metrics(timestamp);
+-- Index for metric queries
+CREATE INDEX IF NOT EXISTS idx_metrics_project_id ON metrics(project_id);
 CREATE INDEX IF NOT EXISTS idx_metrics_name ON metrics(name);
 CREATE INDEX IF NOT EXISTS idx_metrics_name_timestamp ON metrics(name, timestamp);
-CREATE INDEX IF NOT EXISTS idx_metrics_project_id ON metrics(project_id);
-CREATE INDEX IF NOT EXISTS idx_metrics_service_id ON metrics(service_id);
-CREATE INDEX IF NOT EXISTS idx_metrics_project_service ON metrics(project_id, service_id);
 
 -- Events table for application events
 CREATE TABLE IF NOT EXISTS events (
     id TEXT PRIMARY KEY,
     type TEXT NOT NULL,
     source TEXT NOT NULL,
-    data TEXT NOT NULL DEFAULT '{}', -- JSON object
-    tags TEXT NOT NULL DEFAULT '{}', -- JSON object
-    timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+    data TEXT, -- JSON object
+    tags TEXT, -- JSON object
+    timestamp DATETIME NOT NULL
 );
 
 -- Index for event queries
-CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp);
-CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
-CREATE INDEX IF NOT EXISTS idx_events_source ON events(source);
 CREATE INDEX IF NOT EXISTS idx_events_type_timestamp ON events(type, timestamp);
 
--- Logs table for application logs
+-- Logs table for application and container logs
 CREATE TABLE IF NOT EXISTS logs (
     id TEXT PRIMARY KEY,
-    level TEXT NOT NULL CHECK(level IN ('debug', 'info', 'warn', 'error', 'fatal')),
+    project_id TEXT NOT NULL DEFAULT '',
+    service_id TEXT NOT NULL DEFAULT '',
+    deployment_id TEXT NOT NULL DEFAULT '',
+    container_id TEXT NOT NULL DEFAULT '',
+    level TEXT NOT NULL,
     message TEXT NOT NULL,
     source TEXT NOT NULL,
-    fields TEXT NOT NULL DEFAULT '{}', -- JSON object
-    tags TEXT NOT NULL DEFAULT '{}', -- JSON object
-    timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+    fields TEXT, -- JSON object
+    tags TEXT, -- JSON object
+    timestamp BIGINT NOT NULL -- unix nanoseconds
 );
 
 -- Index for log queries
-CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON logs(timestamp);
-CREATE INDEX IF NOT EXISTS idx_logs_level ON logs(level);
-CREATE INDEX IF NOT EXISTS idx_logs_source ON logs(source);
 CREATE INDEX IF NOT EXISTS idx_logs_level_timestamp ON logs(level, timestamp);
+CREATE INDEX IF NOT EXISTS idx_logs_project_timestamp ON logs(project_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_logs_container_timestamp ON logs(container_id, timestamp);
 
 -- +goose Down
+DROP INDEX IF EXISTS idx_logs_container_timestamp;
+DROP INDEX IF EXISTS idx_logs_project_timestamp;
 DROP INDEX IF EXISTS idx_logs_level_timestamp;
-DROP INDEX IF EXISTS idx_logs_source;
-DROP INDEX IF EXISTS idx_logs_level;
-DROP INDEX IF EXISTS idx_logs_timestamp;
 DROP TABLE IF EXISTS logs;
 
 DROP INDEX IF EXISTS idx_events_type_timestamp;
-DROP INDEX IF EXISTS idx_events_source;
-DROP INDEX IF EXISTS idx_events_type;
-DROP INDEX IF EXISTS idx_events_timestamp;
 DROP TABLE IF EXISTS events;
 
-DROP INDEX IF EXISTS idx_metrics_project_service;
-DROP INDEX IF EXISTS idx_metrics_service_id;
-DROP INDEX IF EXISTS idx_metrics_project_id;
 DROP INDEX IF EXISTS idx_metrics_name_timestamp;
 DROP INDEX IF EXISTS idx_metrics_name;
-DROP INDEX IF EXISTS idx_metrics_timestamp;
-DROP TABLE IF EXISTS metrics;
\ No newline at end of file
+DROP INDEX IF EXISTS idx_metrics_project_id;
+DROP TABLE IF EXISTS metrics;
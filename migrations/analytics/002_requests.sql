@@ -0,0 +1,23 @@
+-- +goose Up
+-- Requests served by the proxy, parsed from Traefik access logs
+CREATE TABLE IF NOT EXISTS requests (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    application_id TEXT NOT NULL,
+    router TEXT NOT NULL,
+    method TEXT NOT NULL,
+    host TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    duration_ms REAL NOT NULL,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    client_ip TEXT,
+    timestamp BIGINT NOT NULL -- unix seconds
+);
+
+-- Index for per-application time range queries
+CREATE INDEX IF NOT EXISTS idx_requests_application_timestamp ON requests(application_id, timestamp);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_requests_application_timestamp;
+DROP TABLE IF EXISTS requests;
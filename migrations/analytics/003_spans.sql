@@ -0,0 +1,30 @@
+-- +goose Up
+-- Trace spans received by the OTLP endpoint
+CREATE TABLE IF NOT EXISTS spans (
+    trace_id TEXT NOT NULL, -- hex
+    span_id TEXT NOT NULL, -- hex
+    parent_span_id TEXT NOT NULL DEFAULT '',
+    project_id TEXT NOT NULL,
+    service_id TEXT,
+    deployment_id TEXT,
+    service_name TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    status TEXT NOT NULL,
+    status_message TEXT NOT NULL DEFAULT '',
+    start_time BIGINT NOT NULL, -- unix nanoseconds
+    duration_ns BIGINT NOT NULL,
+    attributes TEXT NOT NULL DEFAULT '{}', -- JSON object
+    resource TEXT NOT NULL DEFAULT '{}', -- JSON object
+    events TEXT NOT NULL DEFAULT '[]' -- JSON array
+);
+
+-- Index for trace lookups
+CREATE INDEX IF NOT EXISTS idx_spans_project_trace ON spans(project_id, trace_id);
+-- Index for retention cleanup
+CREATE INDEX IF NOT EXISTS idx_spans_start_time ON spans(start_time);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_spans_start_time;
+DROP INDEX IF EXISTS idx_spans_project_trace;
+DROP TABLE IF EXISTS spans;
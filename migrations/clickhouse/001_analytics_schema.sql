@@ -0,0 +1,82 @@
+-- +goose Up
+-- Analytics database schema for ClickHouse. Rows expire through the table TTLs,
+-- partitions are daily so expired data is dropped a whole part at a time.
+
+-- Metrics table for time-series data, kept as long as MetricRetention
+CREATE TABLE IF NOT EXISTS metrics (
+    id String,
+    project_id String DEFAULT '',
+    service_id String DEFAULT '',
+    name LowCardinality(String),
+    value Float64,
+    unit LowCardinality(String) DEFAULT 'count',
+    tags String DEFAULT '{}', -- JSON object
+    timestamp DateTime,
+    created_at DateTime DEFAULT now()
+) ENGINE = MergeTree
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (project_id, name, service_id, timestamp)
+TTL timestamp + INTERVAL 30 DAY
+SETTINGS ttl_only_drop_parts = 1;
+
+-- Events table for application events
+CREATE TABLE IF NOT EXISTS events (
+    id String,
+    type LowCardinality(String),
+    source LowCardinality(String),
+    data String DEFAULT '{}', -- JSON object
+    tags String DEFAULT '{}', -- JSON object
+    timestamp DateTime,
+    created_at DateTime DEFAULT now()
+) ENGINE = MergeTree
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (type, timestamp)
+TTL timestamp + INTERVAL 90 DAY
+SETTINGS ttl_only_drop_parts = 1;
+
+-- Logs table for container logs. The TTL is the longest retention a project
+-- can configure, shorter retentions are enforced by the log collector.
+CREATE TABLE IF NOT EXISTS logs (
+    id String,
+    project_id String DEFAULT '',
+    service_id String DEFAULT '',
+    deployment_id String DEFAULT '',
+    container_id String DEFAULT '',
+    level LowCardinality(String),
+    message String,
+    source LowCardinality(String),
+    fields String DEFAULT '{}', -- JSON object
+    tags String DEFAULT '{}', -- JSON object
+    timestamp DateTime64(9),
+    INDEX idx_logs_container_id container_id TYPE bloom_filter GRANULARITY 4
+) ENGINE = MergeTree
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (project_id, timestamp, id)
+TTL toDateTime(timestamp) + INTERVAL 365 DAY
+SETTINGS ttl_only_drop_parts = 1;
+
+-- Requests served by the proxy, parsed from Traefik access logs
+CREATE TABLE IF NOT EXISTS requests (
+    id String,
+    project_id String,
+    application_id String,
+    router String,
+    method LowCardinality(String),
+    host String,
+    path String,
+    status UInt16,
+    duration_ms Float64,
+    bytes Int64 DEFAULT 0,
+    client_ip String DEFAULT '',
+    timestamp DateTime
+) ENGINE = MergeTree
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (application_id, timestamp)
+TTL timestamp + INTERVAL 30 DAY
+SETTINGS ttl_only_drop_parts = 1;
+
+-- +goose Down
+DROP TABLE IF EXISTS requests;
+DROP TABLE IF EXISTS logs;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS metrics;
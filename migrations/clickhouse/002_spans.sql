@@ -0,0 +1,28 @@
+-- +goose Up
+-- Trace spans received by the OTLP endpoint, looked up by trace ID
+CREATE TABLE IF NOT EXISTS spans (
+    trace_id String,
+    span_id String,
+    parent_span_id String DEFAULT '',
+    project_id String,
+    service_id String DEFAULT '',
+    deployment_id String DEFAULT '',
+    service_name LowCardinality(String) DEFAULT '',
+    name String,
+    kind LowCardinality(String),
+    status LowCardinality(String),
+    status_message String DEFAULT '',
+    start_time DateTime64(9),
+    duration_ns Int64,
+    attributes String DEFAULT '{}', -- JSON object
+    resource String DEFAULT '{}', -- JSON object
+    events String DEFAULT '[]', -- JSON array
+    INDEX idx_spans_trace_id trace_id TYPE bloom_filter GRANULARITY 4
+) ENGINE = MergeTree
+PARTITION BY toYYYYMMDD(start_time)
+ORDER BY (project_id, start_time, trace_id)
+TTL toDateTime(start_time) + INTERVAL 7 DAY
+SETTINGS ttl_only_drop_parts = 1;
+
+-- +goose Down
+DROP TABLE IF EXISTS spans;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS route_middlewares (
+    id TEXT PRIMARY KEY,
+    application_id TEXT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    domain TEXT NOT NULL DEFAULT '', -- Empty applies to every domain of the application
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK(type IN ('auth', 'ratelimit', 'compression', 'cors', 'headers', 'stripprefix', 'redirect', 'ipallowlist', 'replacepathregex')),
+    config TEXT NOT NULL DEFAULT '{}', -- JSON middleware settings
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(application_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_middlewares_application_id ON route_middlewares(application_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_route_middlewares_application_id;
+DROP TABLE IF EXISTS route_middlewares;
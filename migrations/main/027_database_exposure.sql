@@ -0,0 +1,17 @@
+-- +goose Up
+-- Host port allocated to each database from the managed pool and how it is exposed
+ALTER TABLE databases ADD COLUMN host_port INTEGER;
+ALTER TABLE databases ADD COLUMN exposure_mode TEXT NOT NULL DEFAULT 'none' CHECK(exposure_mode IN ('none', 'tls', 'port'));
+ALTER TABLE databases ADD COLUMN exposure_hostname TEXT NOT NULL DEFAULT '';
+
+-- A host port can only be bound by one database, and a hostname only routed to one
+CREATE UNIQUE INDEX IF NOT EXISTS idx_databases_host_port ON databases(host_port) WHERE host_port IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_databases_exposure_hostname ON databases(exposure_hostname) WHERE exposure_hostname != '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_databases_exposure_hostname;
+DROP INDEX IF EXISTS idx_databases_host_port;
+
+ALTER TABLE databases DROP COLUMN exposure_hostname;
+ALTER TABLE databases DROP COLUMN exposure_mode;
+ALTER TABLE databases DROP COLUMN host_port;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS application_page_settings (
+    application_id TEXT PRIMARY KEY REFERENCES applications(id) ON DELETE CASCADE,
+    maintenance BOOLEAN NOT NULL DEFAULT FALSE,
+    error_pages BOOLEAN NOT NULL DEFAULT FALSE,
+    error_statuses TEXT NOT NULL DEFAULT '500-599,404', -- Traefik status ranges replaced by the error pages
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS application_page_settings;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS redirect_rules (
+    id TEXT PRIMARY KEY,
+    application_id TEXT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK(action IN ('redirect', 'rewrite')),
+    match_type TEXT NOT NULL CHECK(match_type IN ('exact', 'prefix', 'regex')),
+    source TEXT NOT NULL,
+    destination TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0, -- 301/302 for redirects, 0 for rewrites
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_redirect_rules_application_id ON redirect_rules(application_id, priority);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_redirect_rules_application_id;
+DROP TABLE IF EXISTS redirect_rules;
@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS backup_schedules (
+    id TEXT PRIMARY KEY,
+    database_id TEXT NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    cron_expression TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    keep_last INTEGER NOT NULL DEFAULT 0,
+    max_age_days INTEGER NOT NULL DEFAULT 0,
+    storage_config TEXT NOT NULL, -- JSON storage backend settings
+    next_run_at DATETIME,
+    last_run_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_schedules_database_id ON backup_schedules(database_id);
+
+CREATE TABLE IF NOT EXISTS backup_runs (
+    id TEXT PRIMARY KEY,
+    database_id TEXT NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    schedule_id TEXT REFERENCES backup_schedules(id) ON DELETE SET NULL,
+    trigger TEXT NOT NULL CHECK(trigger IN ('scheduled', 'manual')),
+    status TEXT NOT NULL CHECK(status IN ('pending', 'running', 'succeeded', 'failed')),
+    storage_config TEXT NOT NULL, -- copy of the backend the artifact was written to
+    storage_key TEXT NOT NULL DEFAULT '',
+    format TEXT NOT NULL DEFAULT '',
+    compression TEXT NOT NULL DEFAULT 'gzip',
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    checksum TEXT NOT NULL DEFAULT '', -- sha256 of the stored artifact
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_runs_database_id ON backup_runs(database_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_backup_runs_schedule_id ON backup_runs(schedule_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_backup_runs_schedule_id;
+DROP INDEX IF EXISTS idx_backup_runs_database_id;
+DROP TABLE IF EXISTS backup_runs;
+DROP INDEX IF EXISTS idx_backup_schedules_database_id;
+DROP TABLE IF EXISTS backup_schedules;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS wal_archives (
+    database_id TEXT PRIMARY KEY REFERENCES databases(id) ON DELETE CASCADE,
+    base_backup_cron TEXT NOT NULL,
+    retention_days INTEGER NOT NULL,
+    storage_config TEXT NOT NULL, -- JSON storage backend settings
+    next_base_backup_at DATETIME,
+    last_base_backup_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS wal_segments (
+    database_id TEXT NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    storage_config TEXT NOT NULL, -- copy of the backend the segment was written to
+    storage_key TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    checksum TEXT NOT NULL DEFAULT '', -- sha256 of the stored file
+    archived_at DATETIME NOT NULL,
+    PRIMARY KEY (database_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wal_segments_archived_at ON wal_segments(database_id, archived_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_wal_segments_archived_at;
+DROP TABLE IF EXISTS wal_segments;
+DROP TABLE IF EXISTS wal_archives;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS query_history (
+    id TEXT PRIMARY KEY,
+    database_id TEXT NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    query TEXT NOT NULL,
+    read_only BOOLEAN NOT NULL DEFAULT TRUE,
+    status TEXT NOT NULL CHECK(status IN ('succeeded', 'failed', 'cancelled')),
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    executed_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_history_database_user ON query_history(database_id, user_id, executed_at);
+
+CREATE TABLE IF NOT EXISTS saved_queries (
+    id TEXT PRIMARY KEY,
+    database_id TEXT NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    query TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE, -- visible to everyone in the project
+    created_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(database_id, created_by, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_queries_database_id ON saved_queries(database_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_saved_queries_database_id;
+DROP TABLE IF EXISTS saved_queries;
+DROP INDEX IF EXISTS idx_query_history_database_user;
+DROP TABLE IF EXISTS query_history;
@@ -0,0 +1,54 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK(type IN ('email', 'webhook', 'slack', 'discord')),
+    config TEXT NOT NULL DEFAULT '{}', -- JSON: url, secret, recipients
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channels_project_id ON notification_channels(project_id);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK(type IN ('deployment_failed', 'container_restart_loop', 'memory_usage', 'certificate_expiring', 'tunnel_unhealthy')),
+    service_id TEXT, -- application or database the rule is limited to
+    threshold REAL NOT NULL DEFAULT 0,
+    for_seconds INTEGER NOT NULL DEFAULT 0,
+    channel_ids TEXT NOT NULL DEFAULT '[]', -- JSON array, empty routes to every channel of the project
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_project_id ON alert_rules(project_id);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id TEXT PRIMARY KEY,
+    rule_id TEXT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    fingerprint TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK(status IN ('firing', 'resolved')),
+    started_at DATETIME NOT NULL,
+    resolved_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_project_started ON alerts(project_id, started_at);
+-- A subject has at most one firing alert per rule
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_firing ON alerts(rule_id, fingerprint) WHERE status = 'firing';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_alerts_firing;
+DROP INDEX IF EXISTS idx_alerts_project_started;
+DROP TABLE IF EXISTS alerts;
+DROP INDEX IF EXISTS idx_alert_rules_project_id;
+DROP TABLE IF EXISTS alert_rules;
+DROP INDEX IF EXISTS idx_notification_channels_project_id;
+DROP TABLE IF EXISTS notification_channels;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Tokens authenticating OTLP exports to the telemetry receiver of a project
+CREATE TABLE IF NOT EXISTS ingest_tokens (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    managed BOOLEAN NOT NULL DEFAULT FALSE, -- injected into application containers on deploy
+    last_used_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_tokens_project_id ON ingest_tokens(project_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_ingest_tokens_project_id;
+DROP TABLE IF EXISTS ingest_tokens;
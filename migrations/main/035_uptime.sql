@@ -0,0 +1,69 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS uptime_monitors (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    application_id TEXT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK(type IN ('http', 'tcp')),
+    path TEXT NOT NULL DEFAULT '/', -- requested by http monitors
+    port INTEGER NOT NULL DEFAULT 80,
+    expected_status INTEGER NOT NULL DEFAULT 0, -- 0 accepts any 2xx or 3xx
+    interval_seconds INTEGER NOT NULL DEFAULT 60,
+    timeout_seconds INTEGER NOT NULL DEFAULT 10,
+    failure_threshold INTEGER NOT NULL DEFAULT 3,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_uptime_monitors_project_id ON uptime_monitors(project_id);
+
+CREATE TABLE IF NOT EXISTS uptime_incidents (
+    id TEXT PRIMARY KEY,
+    monitor_id TEXT NOT NULL REFERENCES uptime_monitors(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK(status IN ('open', 'resolved')),
+    cause TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    resolved_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_uptime_incidents_monitor_started ON uptime_incidents(monitor_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_uptime_incidents_project_started ON uptime_incidents(project_id, started_at);
+-- A monitor has at most one open incident
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uptime_incidents_open ON uptime_incidents(monitor_id) WHERE status = 'open';
+
+-- Check results rolled up per UTC day. Raw results are kept as metrics in the
+-- analytics database, which doesn't hold the 90 days status pages show.
+CREATE TABLE IF NOT EXISTS uptime_daily (
+    monitor_id TEXT NOT NULL REFERENCES uptime_monitors(id) ON DELETE CASCADE,
+    day TEXT NOT NULL, -- YYYY-MM-DD
+    checks INTEGER NOT NULL DEFAULT 0,
+    successes INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (monitor_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS status_pages (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    custom_domain TEXT UNIQUE,
+    monitor_ids TEXT NOT NULL DEFAULT '[]', -- JSON array, in display order
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_pages_organization_id ON status_pages(organization_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_status_pages_organization_id;
+DROP TABLE IF EXISTS status_pages;
+DROP TABLE IF EXISTS uptime_daily;
+DROP INDEX IF EXISTS idx_uptime_incidents_open;
+DROP INDEX IF EXISTS idx_uptime_incidents_project_started;
+DROP INDEX IF EXISTS idx_uptime_incidents_monitor_started;
+DROP TABLE IF EXISTS uptime_incidents;
+DROP INDEX IF EXISTS idx_uptime_monitors_project_id;
+DROP TABLE IF EXISTS uptime_monitors;
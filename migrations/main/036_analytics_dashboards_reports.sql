@@ -0,0 +1,53 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS dashboards (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    widgets TEXT NOT NULL DEFAULT '[]', -- JSON array of widgets
+    layout TEXT NOT NULL DEFAULT '{}', -- JSON object
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboards_project_id ON dashboards(project_id);
+
+CREATE TABLE IF NOT EXISTS reports (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL CHECK(type IN ('usage', 'performance', 'cost', 'deployments', 'custom')),
+    period TEXT NOT NULL CHECK(period IN ('hourly', 'daily', 'weekly', 'monthly')),
+    config TEXT NOT NULL DEFAULT '{}', -- JSON ReportConfig
+    status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'generating', 'completed', 'error')),
+    generated_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_project_id ON reports(project_id);
+
+CREATE TABLE IF NOT EXISTS report_runs (
+    id TEXT PRIMARY KEY,
+    report_id TEXT NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('generating', 'completed', 'error')),
+    content TEXT NOT NULL DEFAULT '', -- rendered HTML
+    error_message TEXT NOT NULL DEFAULT '',
+    emailed_to TEXT NOT NULL DEFAULT '[]', -- JSON array
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_runs_report_created ON report_runs(report_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_report_runs_report_created;
+DROP TABLE IF EXISTS report_runs;
+DROP INDEX IF EXISTS idx_reports_project_id;
+DROP TABLE IF EXISTS reports;
+DROP INDEX IF EXISTS idx_dashboards_project_id;
+DROP TABLE IF EXISTS dashboards;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- When the deployed commit was made, lead time is measured from it
+ALTER TABLE deployments ADD COLUMN git_committed_at DATETIME;
+
+-- +goose Down
+ALTER TABLE deployments DROP COLUMN git_committed_at;
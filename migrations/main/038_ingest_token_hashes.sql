@@ -0,0 +1,12 @@
+-- +goose Up
+-- Ingest tokens are stored as SHA-256 hashes. The ones created before still
+-- hold the token and are hashed by the API on startup, token_prefix keeps the
+-- start of the token for listings.
+ALTER TABLE ingest_tokens RENAME COLUMN token TO token_hash;
+ALTER TABLE ingest_tokens ADD COLUMN token_prefix TEXT NOT NULL DEFAULT '';
+UPDATE ingest_tokens SET token_prefix = substr(token_hash, 1, 8);
+
+-- +goose Down
+-- Hashed tokens can't be recovered, they stop authenticating
+ALTER TABLE ingest_tokens DROP COLUMN token_prefix;
+ALTER TABLE ingest_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+const (
+	// DumpFileName is the file a backup sidecar writes inside its output directory
+	// and a restore sidecar reads from its work directory
+	DumpFileName = "dump"
+	// dumpLogFileName receives the output of the dump tool
+	dumpLogFileName = "dump.log"
+)
+
+// Dump formats produced by the backup sidecars
+const (
+	DumpFormatPostgres   = "pg_dump"
+	DumpFormatMySQL      = "mysqldump"
+	DumpFormatMongoDB    = "mongodump"
+	DumpFormatRDB        = "rdb"
+	DumpFormatClickHouse = "clickhouse_backup"
+)
+
+// dumpFormatFor returns the dump format produced for a database type, or an
+// empty string if the type can't be dumped
+func dumpFormatFor(dbType databases.DatabaseType) string {
+	switch dbType {
+	case databases.DatabaseTypePostgreSQL:
+		return DumpFormatPostgres
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		return DumpFormatMySQL
+	case databases.DatabaseTypeMongoDB:
+		return DumpFormatMongoDB
+	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB, databases.DatabaseTypeDragonfly:
+		return DumpFormatRDB
+	case databases.DatabaseTypeClickHouse:
+		return DumpFormatClickHouse
+	default:
+		return ""
+	}
+}
+
+// DumpResult describes a logical dump written by a backup sidecar
+type DumpResult struct {
+	Path   string // path of the dump file on the host
+	Format string
+}
+
+// Dump runs the native dump tool of the database in a short-lived sidecar
+// container started from the database image. The sidecar joins the network
+// namespace of the database container so it can reach it on localhost, and
+// writes the dump to outputDir.
+func (s *Service) Dump(ctx context.Context, database *databases.Database, outputDir string) (*DumpResult, error) {
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	status, err := s.GetStatus(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database status: %w", err)
+	}
+	if status.State != "running" {
+		return nil, fmt.Errorf("database container is not running (state: %s)", status.State)
+	}
+
+	job, err := buildDumpJob(database, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.runSidecar(ctx, config, "backup", job, outputDir, dumpLogFileName, nil); err != nil {
+		return nil, err
+	}
+
+	dumpPath := filepath.Join(outputDir, DumpFileName)
+	if _, err := os.Stat(dumpPath); err != nil {
+		return nil, fmt.Errorf("dump file was not written: %w", err)
+	}
+
+	return &DumpResult{Path: dumpPath, Format: job.format}, nil
+}
+
+// buildDumpJob returns the sidecar job that dumps a database
+func buildDumpJob(database *databases.Database, config *DatabaseContainerConfig) (*sidecarJob, error) {
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return nil, err
+	}
+
+	out := sidecarMountPath + "/" + DumpFileName
+
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		return &sidecarJob{
+			format:      DumpFormatPostgres,
+			script:      `pg_dump -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -d "$PGDATABASE" -Fc -f ` + out,
+			environment: environment,
+		}, nil
+
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		return &sidecarJob{
+			format: DumpFormatMySQL,
+			// Recent MariaDB images only ship mariadb-dump
+			script: `DUMP=mysqldump
+command -v mariadb-dump >/dev/null 2>&1 && DUMP=mariadb-dump
+$DUMP -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" --single-transaction --routines --triggers --databases "$DB_NAME" > ` + out,
+			environment: environment,
+		}, nil
+
+	case databases.DatabaseTypeMongoDB:
+		return &sidecarJob{
+			format:      DumpFormatMongoDB,
+			script:      mongoArgs + `mongodump "$@" --archive=` + out,
+			environment: environment,
+		}, nil
+
+	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB:
+		// BGSAVE returns immediately, so wait for LASTSAVE to move before
+		// copying the snapshot out of the data volume
+		return &sidecarJob{
+			format: DumpFormatRDB,
+			script: redisCLI + `before=$(cli LASTSAVE)
+cli BGSAVE
+i=0
+while [ "$(cli LASTSAVE)" = "$before" ]; do
+  i=$((i+1))
+  if [ "$i" -gt 3600 ]; then echo "timed out waiting for BGSAVE"; exit 1; fi
+  sleep 1
+done
+cp /data/dump.rdb ` + out,
+			environment: environment,
+			mountData:   true,
+		}, nil
+
+	case databases.DatabaseTypeDragonfly:
+		return &sidecarJob{
+			format: DumpFormatRDB,
+			script: redisCLI + `cli SAVE RDB mikrocloud-backup
+mv /data/mikrocloud-backup.rdb ` + out,
+			environment: environment,
+			mountData:   true,
+		}, nil
+
+	case databases.DatabaseTypeClickHouse:
+		// BACKUP writes below the server's allowed backups path inside the
+		// data directory, which the sidecar reads through the data volume
+		return &sidecarJob{
+			format: DumpFormatClickHouse,
+			script: clickHouseClient + `NAME="mikrocloud-$(date +%s).zip"
+ch --query "BACKUP DATABASE \"$DB_NAME\" TO File('backups/$NAME')"
+mv "/var/lib/clickhouse/backups/$NAME" ` + out,
+			environment: environment,
+			mountData:   true,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("backups are not supported for database type: %s", database.Type())
+	}
+}
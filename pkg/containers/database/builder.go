@@ -13,11 +13,13 @@ import (
 // DefaultContainerConfigBuilder builds container configurations for different database types
 type DefaultContainerConfigBuilder struct {
 	imageResolver DatabaseImageResolver
+	walArchiveDir string // host directory PostgreSQL archives WAL segments to
 }
 
-func NewDefaultContainerConfigBuilder(imageResolver DatabaseImageResolver) ContainerConfigBuilder {
+func NewDefaultContainerConfigBuilder(imageResolver DatabaseImageResolver, walArchiveDir string) ContainerConfigBuilder {
 	return &DefaultContainerConfigBuilder{
 		imageResolver: imageResolver,
+		walArchiveDir: walArchiveDir,
 	}
 }
 
@@ -86,6 +88,21 @@ func (b *DefaultContainerConfigBuilder) BuildPostgreSQLConfig(database *database
 		fmt.Sprintf("mikrocloud-postgres-%s", database.ID().String()): "/var/lib/postgresql/data",
 	}
 
+	command := []string{}
+	if pgConfig.WALArchiving && b.walArchiveDir != "" {
+		volumes[WALArchivePath(b.walArchiveDir, database.ID().String())] = walArchiveMountPath
+		command = postgresArchiveCommand()
+	}
+	if replication := pgConfig.Replication; replication != nil && replication.PrimaryID != "" {
+		if len(command) == 0 {
+			command = []string{"postgres"}
+		}
+		command = append(command, postgresStandbyArgs(database, replication)...)
+		// The WAL receiver reads the password of the replication user from
+		// the environment rather than from the visible conninfo
+		environment["PGPASSWORD"] = replication.Password
+	}
+
 	return &DatabaseContainerConfig{
 		Database:      database,
 		Image:         image,
@@ -93,7 +110,7 @@ func (b *DefaultContainerConfigBuilder) BuildPostgreSQLConfig(database *database
 		Port:          strconv.Itoa(pgConfig.Port),
 		Environment:   environment,
 		Volumes:       volumes,
-		Command:       []string{},
+		Command:       command,
 		HealthCheck: &HealthCheckConfig{
 			Test:     []string{"CMD-SHELL", "pg_isready -U " + pgConfig.Username + " -d " + pgConfig.DatabaseName},
 			Interval: "30s",
@@ -144,6 +161,11 @@ func (b *DefaultContainerConfigBuilder) BuildMySQLConfig(database *databases.Dat
 		fmt.Sprintf("mikrocloud-mysql-%s", database.ID().String()): "/var/lib/mysql",
 	}
 
+	command := []string{}
+	if mysqlConfig.Replication != nil {
+		command = mysqlReplicationCommand(mysqlConfig.Replication)
+	}
+
 	return &DatabaseContainerConfig{
 		Database:      database,
 		Image:         image,
@@ -151,7 +173,7 @@ func (b *DefaultContainerConfigBuilder) BuildMySQLConfig(database *databases.Dat
 		Port:          strconv.Itoa(mysqlConfig.Port),
 		Environment:   environment,
 		Volumes:       volumes,
-		Command:       []string{},
+		Command:       command,
 		HealthCheck: &HealthCheckConfig{
 			Test:     []string{"CMD", "mysqladmin", "ping", "-h", "localhost"},
 			Interval: "30s",
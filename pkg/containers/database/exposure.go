@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// TCPEntryPoint is the Traefik entrypoint TLS-exposed databases are routed on.
+// TCP routers with a HostSNI rule take precedence over HTTP routers on it, so
+// databases share :443 with the web applications.
+const TCPEntryPoint = "websecure"
+
+// CertResolver is the ACME resolver that issues the certificates of
+// TLS-exposed databases, the one the Traefik of docker-compose.yml defines
+const CertResolver = "letsencrypt"
+
+// ListHostPorts returns the host ports currently published by any container
+// but the current one of database, keyed by port with the owning container
+// name as value. database may be nil.
+func (s *Service) ListHostPorts(ctx context.Context, database *databases.Database) (map[int]string, error) {
+	containers, err := s.containerService.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	ports := make(map[int]string)
+	for _, container := range containers {
+		if database != nil && database.ContainerID() != "" && container.ID == database.ContainerID() {
+			continue
+		}
+		for hostPort := range container.Ports {
+			port, err := strconv.Atoi(hostPort)
+			if err != nil {
+				continue
+			}
+			ports[port] = container.Name
+		}
+	}
+
+	return ports, nil
+}
+
+// checkHostPort fails when hostPort is already published by another container
+func (s *Service) checkHostPort(ctx context.Context, containerName, hostPort string) error {
+	port, err := strconv.Atoi(hostPort)
+	if err != nil {
+		return fmt.Errorf("invalid host port %s: %w", hostPort, err)
+	}
+
+	used, err := s.ListHostPorts(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if owner, ok := used[port]; ok && owner != containerName {
+		return fmt.Errorf("host port %d is already in use by container %s", port, owner)
+	}
+
+	return nil
+}
+
+// hostBinding returns the host side of the port mapping for database. Only
+// databases exposed by port listen on all interfaces, the rest stay on loopback
+// where the control plane reaches them.
+func hostBinding(database *databases.Database, hostPort string) string {
+	if database.Exposure().Mode() == databases.ExposureModePort {
+		return hostPort
+	}
+	return "127.0.0.1:" + hostPort
+}
+
+// buildExposureLabels returns the Traefik TCP router labels for databases exposed over TLS
+func buildExposureLabels(database *databases.Database, config *DatabaseContainerConfig) map[string]string {
+	exposure := database.Exposure()
+	if exposure.Mode() != databases.ExposureModeTLS {
+		return nil
+	}
+
+	name := config.ContainerName
+	return map[string]string{
+		"traefik.enable":                                             "true",
+		"traefik.tcp.routers." + name + ".rule":                      fmt.Sprintf("HostSNI(`%s`)", exposure.Hostname()),
+		"traefik.tcp.routers." + name + ".entrypoints":               TCPEntryPoint,
+		"traefik.tcp.routers." + name + ".tls":                       "true",
+		"traefik.tcp.routers." + name + ".tls.certresolver":          CertResolver,
+		"traefik.tcp.routers." + name + ".service":                   name,
+		"traefik.tcp.services." + name + ".loadbalancer.server.port": config.Port,
+	}
+}
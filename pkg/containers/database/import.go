@@ -0,0 +1,253 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// DumpFormatSQL is a plain SQL script, as written by pg_dump -Fp or mysqldump
+const DumpFormatSQL = "sql"
+
+// importLogFileName receives the output of an import
+const importLogFileName = "import.log"
+
+var (
+	gzipMagic         = []byte{0x1f, 0x8b}
+	pgCustomMagic     = []byte("PGDMP")
+	mongoArchiveMagic = []byte{0x6d, 0xe2, 0x99, 0x81}
+)
+
+// StageImport writes an uploaded dump to workDir/DumpFileName, decompressing
+// it if it is gzipped, and returns its format: a pg_dump custom archive, a
+// mongodump archive or otherwise a SQL script
+func StageImport(r io.Reader, workDir string) (string, int64, error) {
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		defer gz.Close()
+		buffered = bufio.NewReader(gz)
+	}
+
+	format := DumpFormatSQL
+	if magic, _ := buffered.Peek(len(pgCustomMagic)); bytes.Equal(magic, pgCustomMagic) {
+		format = DumpFormatPostgres
+	} else if magic, _ := buffered.Peek(len(mongoArchiveMagic)); bytes.Equal(magic, mongoArchiveMagic) {
+		format = DumpFormatMongoDB
+	}
+
+	if err := os.MkdirAll(workDir, 0o750); err != nil {
+		return "", 0, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	f, err := os.Create(filepath.Join(workDir, DumpFileName))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create dump file: %w", err)
+	}
+	defer f.Close()
+
+	size, err := io.Copy(f, buffered)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write dump file: %w", err)
+	}
+	if size == 0 {
+		return "", 0, fmt.Errorf("the dump is empty")
+	}
+
+	return format, size, f.Close()
+}
+
+// SupportsImport reports whether a dump format can be imported into a database type
+func SupportsImport(dbType databases.DatabaseType, format string) bool {
+	switch dbType {
+	case databases.DatabaseTypePostgreSQL:
+		return format == DumpFormatSQL || format == DumpFormatPostgres
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		return format == DumpFormatSQL
+	case databases.DatabaseTypeMongoDB:
+		return format == DumpFormatMongoDB
+	default:
+		return false
+	}
+}
+
+// Import loads a dump staged by StageImport into the running database. Unlike
+// Restore it adds to what is there rather than replacing it, so objects that
+// already exist make the import fail.
+func (s *Service) Import(ctx context.Context, database *databases.Database, workDir, format string, progress io.Writer) error {
+	if !SupportsImport(database.Type(), format) {
+		return fmt.Errorf("a %s dump cannot be imported into a %s database", format, database.Type())
+	}
+
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireRunning(ctx, database); err != nil {
+		return err
+	}
+
+	in := sidecarMountPath + "/" + DumpFileName
+	var script string
+	switch {
+	case database.Type() == databases.DatabaseTypePostgreSQL && format == DumpFormatSQL:
+		script = waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+psql -h 127.0.0.1 -p "$DB_PORT" -v ON_ERROR_STOP=1 --echo-errors -f ` + in
+
+	case database.Type() == databases.DatabaseTypePostgreSQL:
+		script = waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+pg_restore -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -d "$PGDATABASE" --no-owner --no-privileges --exit-on-error --verbose ` + in
+
+	case database.Type() == databases.DatabaseTypeMongoDB:
+		script = waitForReady + mongoPing + `wait_for ping
+` + mongoArgs + `mongorestore "$@" --archive=` + in
+
+	default:
+		script = waitForReady + mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+echo "Loading SQL script into $DB_NAME"
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" "$DB_NAME" < ` + in
+	}
+
+	job := &sidecarJob{format: format, script: script, environment: environment}
+	return s.runSidecar(ctx, config, "import", job, workDir, importLogFileName, progress)
+}
+
+// pipeSource runs SOURCE_CMD into TARGET_CMD through a FIFO rather than a
+// pipe, whose exit status would only be that of the target. Waiting on the
+// dump afterwards fails the job when the source breaks off midway.
+const pipeSource = `mkfifo /tmp/import.fifo
+eval "$SOURCE_CMD" > /tmp/import.fifo &
+SOURCE_PID=$!
+eval "$TARGET_CMD" < /tmp/import.fifo
+wait "$SOURCE_PID"
+`
+
+// ValidateImportSource checks that a connection string points at a database
+// of the same type
+func ValidateImportSource(dbType databases.DatabaseType, sourceURL string) error {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("the connection string has no host")
+	}
+
+	var schemes []string
+	switch dbType {
+	case databases.DatabaseTypePostgreSQL:
+		schemes = []string{"postgres", "postgresql"}
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		schemes = []string{"mysql", "mariadb"}
+	case databases.DatabaseTypeMongoDB:
+		schemes = []string{"mongodb", "mongodb+srv"}
+	default:
+		return fmt.Errorf("imports from external databases are not supported for %s", dbType)
+	}
+
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("a %s database needs a %s:// connection string", dbType, schemes[0])
+	}
+	// The MySQL dump tools copy one database, which has to be named
+	if schemes[0] == "mysql" && strings.Trim(u.Path, "/") == "" {
+		return fmt.Errorf("the connection string has no database name")
+	}
+	return nil
+}
+
+// Pull dumps an external database with the native tools of the database image
+// and loads the dump into the running database as it is written, without
+// staging it on disk. Being the tools of the target's version, they may refuse
+// a source running a newer one.
+func (s *Service) Pull(ctx context.Context, database *databases.Database, sourceURL, workDir string, progress io.Writer) error {
+	if err := ValidateImportSource(database.Type(), sourceURL); err != nil {
+		return err
+	}
+
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireRunning(ctx, database); err != nil {
+		return err
+	}
+
+	var script string
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		environment["SOURCE_URL"] = sourceURL
+		environment["SOURCE_CMD"] = `pg_dump --format=custom --no-owner --no-privileges "$SOURCE_URL"`
+		environment["TARGET_CMD"] = `pg_restore -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -d "$PGDATABASE" --no-owner --no-privileges --exit-on-error --verbose`
+		script = waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+echo "Dumping source database"
+` + pipeSource
+
+	case databases.DatabaseTypeMongoDB:
+		environment["SOURCE_URL"] = sourceURL
+		environment["SOURCE_CMD"] = `mongodump --uri="$SOURCE_URL" --archive`
+		environment["TARGET_CMD"] = `mongorestore "$@" --archive`
+		script = waitForReady + mongoPing + `wait_for ping
+` + mongoArgs + `echo "Dumping source database"
+` + pipeSource
+
+	default:
+		// The MySQL clients don't take URLs, so the parts are passed separately
+		u, _ := url.Parse(sourceURL)
+		password, _ := u.User.Password()
+		port := u.Port()
+		if port == "" {
+			port = "3306"
+		}
+		environment["SOURCE_HOST"] = u.Hostname()
+		environment["SOURCE_PORT"] = port
+		environment["SOURCE_USER"] = u.User.Username()
+		environment["SOURCE_PASSWORD"] = password
+		environment["SOURCE_DB"] = strings.Trim(u.Path, "/")
+		environment["SOURCE_CMD"] = `MYSQL_PWD="$SOURCE_PASSWORD" $DUMP -h "$SOURCE_HOST" -P "$SOURCE_PORT" -u "$SOURCE_USER" --single-transaction --routines --triggers --events "$SOURCE_DB"`
+		environment["TARGET_CMD"] = `$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" "$DB_NAME"`
+		script = waitForReady + mysqlClients + `DUMP=mysqldump
+command -v mariadb-dump >/dev/null 2>&1 && DUMP=mariadb-dump
+wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+echo "Dumping $SOURCE_DB from $SOURCE_HOST"
+` + pipeSource
+	}
+
+	job := &sidecarJob{format: "import", script: script, environment: environment}
+	return s.runSidecar(ctx, config, "import", job, workDir, importLogFileName, progress)
+}
+
+// requireRunning fails unless the database container is running
+func (s *Service) requireRunning(ctx context.Context, database *databases.Database) error {
+	status, err := s.GetStatus(ctx, database)
+	if err != nil {
+		return fmt.Errorf("failed to get database status: %w", err)
+	}
+	if status.State != "running" {
+		return fmt.Errorf("database container is not running (state: %s)", status.State)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// DumpFormatPostgresBase is a physical base backup taken with pg_basebackup. It
+// is restored together with archived WAL for point-in-time recovery.
+const DumpFormatPostgresBase = "pg_basebackup"
+
+const (
+	// walArchiveMountPath is where PostgreSQL containers archive WAL segments
+	walArchiveMountPath = "/wal-archive"
+
+	// RecoveryWALDir holds the archived WAL segments a point-in-time restore
+	// replays, relative to the restore work directory
+	RecoveryWALDir = "wal"
+	// RecoveryTargetFile holds the recovery_target_time of a point-in-time
+	// restore, relative to the restore work directory. Without it recovery
+	// replays all available WAL.
+	RecoveryTargetFile = "recovery_target_time"
+)
+
+// WALArchivePath returns the host directory a database archives WAL segments to
+func WALArchivePath(root, databaseID string) string {
+	return filepath.Join(root, databaseID)
+}
+
+// postgresArchiveCommand starts PostgreSQL with continuous archiving to
+// walArchiveMountPath. Segments are copied under a temporary name and renamed
+// so the shipper never picks up a partial file, and archive_timeout bounds how
+// much recent data a recovery can miss on a quiet database.
+func postgresArchiveCommand() []string {
+	return []string{
+		"postgres",
+		"-c", "wal_level=replica",
+		"-c", "archive_mode=on",
+		"-c", "archive_timeout=60",
+		"-c", "archive_command=test ! -f " + walArchiveMountPath + "/%f && cp %p " + walArchiveMountPath + "/%f.tmp && mv " + walArchiveMountPath + "/%f.tmp " + walArchiveMountPath + "/%f",
+	}
+}
+
+// prepareWALArchive creates the archive directory of a database that archives
+// WAL. The postgres user has a different uid across image variants, so the
+// directory is made writable for everyone; it lives under the data directory,
+// which is not.
+func (s *Service) prepareWALArchive(database *databases.Database, config *DatabaseContainerConfig) error {
+	if s.walArchiveDir == "" || database.Type() != databases.DatabaseTypePostgreSQL {
+		return nil
+	}
+
+	dir := WALArchivePath(s.walArchiveDir, database.ID().String())
+	if _, ok := config.Volumes[dir]; !ok {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return fmt.Errorf("failed to create WAL archive directory: %w", err)
+	}
+	if err := os.Chmod(dir, 0o777); err != nil {
+		return fmt.Errorf("failed to set WAL archive permissions: %w", err)
+	}
+
+	return nil
+}
+
+// BaseBackup takes a physical base backup of a PostgreSQL database with
+// pg_basebackup. The tar output, including the WAL needed to make it
+// consistent, is bundled into a single file in outputDir.
+func (s *Service) BaseBackup(ctx context.Context, database *databases.Database, outputDir string) (*DumpResult, error) {
+	if database.Type() != databases.DatabaseTypePostgreSQL {
+		return nil, fmt.Errorf("base backups are only supported for PostgreSQL")
+	}
+
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	status, err := s.GetStatus(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database status: %w", err)
+	}
+	if status.State != "running" {
+		return nil, fmt.Errorf("database container is not running (state: %s)", status.State)
+	}
+
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &sidecarJob{
+		format: DumpFormatPostgresBase,
+		script: `rm -rf /backup/base
+pg_basebackup -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -D /backup/base -Ft -X fetch --checkpoint=fast
+tar -cf /backup/` + DumpFileName + ` -C /backup/base .
+rm -rf /backup/base`,
+		environment: environment,
+	}
+
+	if err := s.runSidecar(ctx, config, "basebackup", job, outputDir, dumpLogFileName, nil); err != nil {
+		return nil, err
+	}
+
+	dumpPath := filepath.Join(outputDir, DumpFileName)
+	if _, err := os.Stat(dumpPath); err != nil {
+		return nil, fmt.Errorf("base backup was not written: %w", err)
+	}
+
+	return &DumpResult{Path: dumpPath, Format: job.format}, nil
+}
+
+// baseBackupRestoreJob replaces the data directory with a base backup and
+// configures recovery from the WAL segments in the work directory. PostgreSQL
+// replays them on its next start and promotes once the target is reached.
+func baseBackupRestoreJob(environment map[string]string) *sidecarJob {
+	return &sidecarJob{
+		format: DumpFormatPostgresBase,
+		script: `DATA=/var/lib/postgresql/data
+PGDATA="$DATA/pgdata"
+WAL="$DATA/recovery-wal"
+rm -rf "$PGDATA" "$WAL" /backup/base
+mkdir -p "$PGDATA/pg_wal" "$WAL" /backup/base
+echo "Extracting base backup"
+tar -xf /backup/` + DumpFileName + ` -C /backup/base
+tar -xf /backup/base/base.tar -C "$PGDATA"
+if [ -f /backup/base/pg_wal.tar ]; then tar -xf /backup/base/pg_wal.tar -C "$PGDATA/pg_wal"; fi
+rm -rf /backup/base
+if [ -d /backup/` + RecoveryWALDir + ` ]; then
+  echo "Copying $(ls /backup/` + RecoveryWALDir + ` | wc -l) archived WAL segment(s)"
+  cp -R /backup/` + RecoveryWALDir + `/. "$WAL"/
+fi
+touch "$PGDATA/recovery.signal"
+echo "restore_command = 'cp $WAL/%f %p'" >> "$PGDATA/postgresql.auto.conf"
+if [ -f /backup/` + RecoveryTargetFile + ` ]; then
+  TARGET=$(cat /backup/` + RecoveryTargetFile + `)
+  echo "Recovering to $TARGET"
+  echo "recovery_target_time = '$TARGET'" >> "$PGDATA/postgresql.auto.conf"
+fi
+echo "recovery_target_action = 'promote'" >> "$PGDATA/postgresql.auto.conf"
+OWNER=$(stat -c %u:%g "$DATA")
+chown -R "$OWNER" "$PGDATA" "$WAL"
+chmod 700 "$PGDATA"`,
+		environment: environment,
+		mountData:   true,
+		offline:     true,
+	}
+}
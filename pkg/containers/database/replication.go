@@ -0,0 +1,454 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+const (
+	// ReplicationUser is the account replicas connect to their primary with
+	ReplicationUser = "mikrocloud_replicator"
+
+	replicationLogFileName = "replication.log"
+)
+
+// ReplicationStatus is how far a replica trails its primary
+type ReplicationStatus struct {
+	// State is streaming while the replica follows its primary
+	State string `json:"state"`
+	// LagSeconds is how long ago the primary committed what the replica has
+	// applied last; nil while the replica can't tell
+	LagSeconds *float64 `json:"lag_seconds"`
+	// LagBytes is WAL received but not yet applied, PostgreSQL only
+	LagBytes  *int64 `json:"lag_bytes,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// ValidateReplication checks that replicas can be added to a database. MySQL
+// replicas position themselves with GTIDs and the replication statements of
+// 8.0.
+func ValidateReplication(database *databases.Database) error {
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		return nil
+
+	case databases.DatabaseTypeMySQL:
+		version := database.Version()
+		if version == "" {
+			version = NewDefaultImageResolver().GetDefaultVersion(databases.DatabaseTypeMySQL)
+		}
+		parsed, err := parseVersion(version)
+		if err != nil {
+			return err
+		}
+		if parsed[0] < 8 {
+			return fmt.Errorf("read replicas need MySQL 8.0 or newer; upgrade the database first")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("read replicas are not supported for %s", database.Type())
+	}
+}
+
+// ReplicationSlotName returns the PostgreSQL replication slot that keeps the
+// WAL a replica still needs on its primary
+func ReplicationSlotName(replicaID string) string {
+	return "mikrocloud_" + strings.ReplaceAll(replicaID, "-", "_")
+}
+
+// postgresStandbyArgs starts PostgreSQL as a hot standby streaming from its
+// primary through the replica's slot
+func postgresStandbyArgs(database *databases.Database, replication *databases.ReplicationConfig) []string {
+	slot := ReplicationSlotName(database.ID().String())
+	conninfo := fmt.Sprintf("host=%s port=%d user=%s application_name=%s",
+		replication.PrimaryHost, replication.PrimaryPort, replication.User, slot)
+	return []string{
+		"-c", "hot_standby=on",
+		"-c", "primary_conninfo=" + conninfo,
+		"-c", "primary_slot_name=" + slot,
+	}
+}
+
+// mysqlReplicationCommand starts MySQL with the binary log and GTIDs that
+// replication needs. Replicas are made read-only by SeedReplica rather than
+// here, since the image runs its first-start initialization with these flags.
+func mysqlReplicationCommand(replication *databases.ReplicationConfig) []string {
+	return []string{
+		"mysqld",
+		"--server-id=" + strconv.Itoa(replication.ServerID),
+		"--log-bin=mysql-bin",
+		"--gtid-mode=ON",
+		"--enforce-gtid-consistency=ON",
+	}
+}
+
+// replicationEnvironment adds the replication settings of a database to the
+// environment of a sidecar
+func replicationEnvironment(environment map[string]string, database *databases.Database) (map[string]string, error) {
+	replication := database.Replication()
+	if replication == nil {
+		return nil, fmt.Errorf("database %s is not set up for replication", database.Name().String())
+	}
+
+	environment["REPL_USER"] = replication.User
+	environment["REPL_PASSWORD"] = replication.Password
+	environment["SLOT"] = ReplicationSlotName(database.ID().String())
+	if replication.PrimaryID != "" {
+		environment["PRIMARY_HOST"] = replication.PrimaryHost
+		environment["PRIMARY_PORT"] = strconv.Itoa(replication.PrimaryPort)
+	}
+	return environment, nil
+}
+
+// PrepareReplicationSource creates the replication user on a running primary
+// and, for PostgreSQL, lets it open replication connections. It is safe to run
+// again, which also resets the password of the user.
+func (s *Service) PrepareReplicationSource(ctx context.Context, primary *databases.Database, workDir string, progress io.Writer) error {
+	if err := ValidateReplication(primary); err != nil {
+		return err
+	}
+
+	config, err := s.configBuilder.BuildConfig(primary)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	environment, err := sidecarEnvironment(primary, config)
+	if err != nil {
+		return err
+	}
+	if environment, err = replicationEnvironment(environment, primary); err != nil {
+		return err
+	}
+
+	if err := s.requireRunning(ctx, primary); err != nil {
+		return err
+	}
+
+	job := &sidecarJob{format: "replication", environment: environment}
+	switch primary.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		// pg_hba.conf is on the data volume; md5 also accepts SCRAM passwords
+		job.mountData = true
+		job.script = waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+echo "Creating replication user $REPL_USER"
+psql -h 127.0.0.1 -p "$DB_PORT" -v ON_ERROR_STOP=1 -v user="$REPL_USER" -v password="$REPL_PASSWORD" <<'SQL'
+SELECT format(CASE WHEN EXISTS (SELECT 1 FROM pg_roles WHERE rolname = :'user')
+  THEN 'ALTER ROLE %I WITH REPLICATION LOGIN PASSWORD %L'
+  ELSE 'CREATE ROLE %I WITH REPLICATION LOGIN PASSWORD %L' END, :'user', :'password')
+\gexec
+SQL
+HBA=$(psql -h 127.0.0.1 -p "$DB_PORT" -Atc "SHOW hba_file")
+if ! grep -q "^host replication $REPL_USER " "$HBA"; then
+  echo "Allowing replication connections in $HBA"
+  echo "host replication $REPL_USER all md5" >> "$HBA"
+  psql -h 127.0.0.1 -p "$DB_PORT" -Atc "SELECT pg_reload_conf()" >/dev/null
+fi`
+
+	default:
+		// The password is generated hex, so it needs no SQL quoting
+		job.script = waitForReady + mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+echo "Creating replication user $REPL_USER"
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -e "CREATE USER IF NOT EXISTS '$REPL_USER'@'%' IDENTIFIED BY '$REPL_PASSWORD'; ALTER USER '$REPL_USER'@'%' IDENTIFIED BY '$REPL_PASSWORD'; GRANT REPLICATION SLAVE, REPLICATION CLIENT ON *.* TO '$REPL_USER'@'%'"`
+	}
+
+	return s.runSidecar(ctx, config, "replication", job, workDir, replicationLogFileName, progress)
+}
+
+// SeedReplica copies the data of a primary into a new replica and points the
+// replica at it. A PostgreSQL replica must not have been deployed yet: its
+// data directory is cloned with pg_basebackup and it starts as a standby. A
+// MySQL replica must be running; the primary is dumped into it and
+// replication starts from the GTIDs of the dump.
+func (s *Service) SeedReplica(ctx context.Context, primary, replica *databases.Database, workDir string, progress io.Writer) error {
+	primaryConfig, err := s.configBuilder.BuildConfig(primary)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+	replicaConfig, err := s.configBuilder.BuildConfig(replica)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	if primary.Type() == databases.DatabaseTypePostgreSQL {
+		environment, err := sidecarEnvironment(primary, primaryConfig)
+		if err != nil {
+			return err
+		}
+		if environment, err = replicationEnvironment(environment, replica); err != nil {
+			return err
+		}
+		environment["PGDATA"] = replicaConfig.Environment["PGDATA"]
+
+		if err := s.requireRunning(ctx, primary); err != nil {
+			return err
+		}
+
+		// The sidecar joins the primary's network and writes to the replica's volume
+		seedConfig := *replicaConfig
+		seedConfig.ContainerName = primaryConfig.ContainerName
+
+		job := &sidecarJob{
+			format: "replication",
+			script: waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+psql -h 127.0.0.1 -p "$DB_PORT" -v ON_ERROR_STOP=1 -Atc "SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = '$SLOT'" >/dev/null
+rm -rf "$PGDATA"
+mkdir -p "$PGDATA"
+echo "Copying the primary with pg_basebackup"
+PGPASSWORD="$REPL_PASSWORD" pg_basebackup -h 127.0.0.1 -p "$DB_PORT" -U "$REPL_USER" -D "$PGDATA" -X stream -C -S "$SLOT" --progress --verbose
+touch "$PGDATA/standby.signal"
+chown -R postgres:postgres "$PGDATA"
+chmod 700 "$PGDATA"
+echo "Replica data directory ready"`,
+			environment: environment,
+			mountData:   true,
+		}
+		return s.runSidecar(ctx, &seedConfig, "replica", job, workDir, replicationLogFileName, progress)
+	}
+
+	environment, err := sidecarEnvironment(replica, replicaConfig)
+	if err != nil {
+		return err
+	}
+	if environment, err = replicationEnvironment(environment, replica); err != nil {
+		return err
+	}
+
+	if err := s.requireRunning(ctx, replica); err != nil {
+		return err
+	}
+
+	// Both servers share the root password, so one MYSQL_PWD serves the dump
+	// and the load. The dump carries GTID_PURGED, which needs an empty GTID
+	// history on the replica; RESET MASTER is RESET BINARY LOGS AND GTIDS
+	// from 8.4.
+	environment["SOURCE_CMD"] = `mysqldump -h "$PRIMARY_HOST" -P "$PRIMARY_PORT" -u "$DB_USER" --all-databases --single-transaction --routines --triggers --events --set-gtid-purged=ON`
+	environment["TARGET_CMD"] = `$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER"`
+	job := &sidecarJob{
+		format: "replication",
+		script: waitForReady + mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+wait_for $ADMIN -h "$PRIMARY_HOST" -P "$PRIMARY_PORT" -u "$DB_USER" ping
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -e "RESET BINARY LOGS AND GTIDS" 2>/dev/null ||
+  $CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -e "RESET MASTER"
+echo "Copying the primary with mysqldump"
+` + pipeSource + `echo "Starting replication from $PRIMARY_HOST"
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -e "FLUSH PRIVILEGES; CHANGE REPLICATION SOURCE TO SOURCE_HOST='$PRIMARY_HOST', SOURCE_PORT=$PRIMARY_PORT, SOURCE_USER='$REPL_USER', SOURCE_PASSWORD='$REPL_PASSWORD', SOURCE_AUTO_POSITION=1, GET_SOURCE_PUBLIC_KEY=1; START REPLICA; SET PERSIST super_read_only = ON"
+echo "Replica ready"`,
+		environment: environment,
+	}
+	return s.runSidecar(ctx, replicaConfig, "replica", job, workDir, replicationLogFileName, progress)
+}
+
+// PromoteReplica stops a running replica from following its primary and
+// makes it writable. The container keeps its replica settings until it is
+// redeployed without them.
+func (s *Service) PromoteReplica(ctx context.Context, replica *databases.Database, workDir string, progress io.Writer) error {
+	config, err := s.configBuilder.BuildConfig(replica)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	environment, err := sidecarEnvironment(replica, config)
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireRunning(ctx, replica); err != nil {
+		return err
+	}
+
+	job := &sidecarJob{format: "promotion", environment: environment}
+	switch replica.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		job.script = waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+echo "Promoting the standby"
+PROMOTED=$(psql -h 127.0.0.1 -p "$DB_PORT" -v ON_ERROR_STOP=1 -Atc "SELECT pg_promote(true, 60)")
+if [ "$PROMOTED" != "t" ]; then echo "promotion did not finish within 60 seconds"; exit 1; fi
+echo "Promotion completed"`
+
+	case databases.DatabaseTypeMySQL:
+		job.script = waitForReady + mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+echo "Stopping replication"
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -e "STOP REPLICA; RESET REPLICA ALL; SET PERSIST super_read_only = OFF; SET PERSIST read_only = OFF"
+echo "Promotion completed"`
+
+	default:
+		return fmt.Errorf("read replicas are not supported for %s", replica.Type())
+	}
+
+	return s.runSidecar(ctx, config, "promote", job, workDir, replicationLogFileName, progress)
+}
+
+// DropReplicationSlot removes the slot of a replica from its PostgreSQL
+// primary once the replica no longer follows it, so the primary stops keeping
+// WAL for it. Other engines keep no per-replica state on the primary.
+func (s *Service) DropReplicationSlot(ctx context.Context, primary *databases.Database, replicaID, workDir string) error {
+	if primary.Type() != databases.DatabaseTypePostgreSQL {
+		return nil
+	}
+
+	config, err := s.configBuilder.BuildConfig(primary)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	environment, err := sidecarEnvironment(primary, config)
+	if err != nil {
+		return err
+	}
+	environment["SLOT"] = ReplicationSlotName(replicaID)
+
+	if err := s.requireRunning(ctx, primary); err != nil {
+		return err
+	}
+
+	// A slot stays active for a moment after its replica disconnects
+	job := &sidecarJob{
+		format: "replication",
+		script: waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+drop_slot() {
+  psql -h 127.0.0.1 -p "$DB_PORT" -v ON_ERROR_STOP=1 -Atc "SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = '$SLOT'"
+}
+wait_for drop_slot`,
+		environment: environment,
+	}
+	return s.runSidecar(ctx, config, "replica-slot", job, workDir, replicationLogFileName, nil)
+}
+
+// ReplicationStatus reads the replication state of a running replica over its
+// connection string
+func (s *Service) ReplicationStatus(ctx context.Context, replica *databases.Database) (*ReplicationStatus, error) {
+	connStr := replica.ConnectionString()
+	if connStr == "" {
+		connStr = replica.GenerateConnectionString()
+	}
+
+	switch replica.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		return postgresReplicationStatus(ctx, connStr)
+	case databases.DatabaseTypeMySQL:
+		return mysqlReplicationStatus(ctx, replica, connStr)
+	default:
+		return nil, fmt.Errorf("read replicas are not supported for %s", replica.Type())
+	}
+}
+
+func postgresReplicationStatus(ctx context.Context, connStr string) (*ReplicationStatus, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer db.Close()
+
+	var inRecovery bool
+	if err := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return nil, fmt.Errorf("failed to query replica: %w", err)
+	}
+	if !inRecovery {
+		return &ReplicationStatus{State: "not_replicating"}, nil
+	}
+
+	// Nothing left to replay means no lag, however old the last transaction is
+	var (
+		state      string
+		lagSeconds sql.NullFloat64
+		lagBytes   sql.NullInt64
+	)
+	err = db.QueryRowContext(ctx, `SELECT
+  COALESCE((SELECT status FROM pg_stat_wal_receiver), 'disconnected'),
+  CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END,
+  pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())::bigint`).Scan(&state, &lagSeconds, &lagBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replication status: %w", err)
+	}
+
+	status := &ReplicationStatus{State: state}
+	if lagSeconds.Valid {
+		status.LagSeconds = &lagSeconds.Float64
+	}
+	if lagBytes.Valid {
+		status.LagBytes = &lagBytes.Int64
+	}
+	return status, nil
+}
+
+func mysqlReplicationStatus(ctx context.Context, replica *databases.Database, connStr string) (*ReplicationStatus, error) {
+	// SHOW REPLICA STATUS needs more than the application user's grants
+	dsn, err := mysql.ParseDSN(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	if cfg := replica.Config().MySQL; cfg != nil && cfg.RootPassword != "" {
+		dsn.User, dsn.Passwd = "root", cfg.RootPassword
+	}
+
+	db, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replication status: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication status: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read replication status: %w", err)
+		}
+		return &ReplicationStatus{State: "not_replicating"}, nil
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to read replication status: %w", err)
+	}
+	fields := make(map[string]sql.NullString, len(columns))
+	for i, column := range columns {
+		fields[column] = values[i]
+	}
+
+	ioRunning, sqlRunning := fields["Replica_IO_Running"].String, fields["Replica_SQL_Running"].String
+	status := &ReplicationStatus{State: "stopped"}
+	switch {
+	case ioRunning == "Yes" && sqlRunning == "Yes":
+		status.State = "streaming"
+	case ioRunning == "Connecting":
+		status.State = "connecting"
+	}
+
+	// Seconds_Behind_Source is NULL while the applier isn't running
+	if behind := fields["Seconds_Behind_Source"]; behind.Valid {
+		if seconds, err := strconv.ParseFloat(behind.String, 64); err == nil {
+			status.LagSeconds = &seconds
+		}
+	}
+	if lastError := fields["Last_IO_Error"].String; lastError != "" {
+		status.LastError = lastError
+	} else {
+		status.LastError = fields["Last_SQL_Error"].String
+	}
+
+	return status, nil
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// restoreLogFileName receives the output of the restore tool
+const restoreLogFileName = "restore.log"
+
+// waitForReady defines wait_for, which retries a readiness probe for up to two
+// minutes so restores into freshly started instances don't race the server
+const waitForReady = `wait_for() {
+  i=0
+  until "$@" >/dev/null 2>&1; do
+    i=$((i+1))
+    if [ "$i" -ge 60 ]; then echo "database did not become ready"; return 1; fi
+    sleep 2
+  done
+}
+`
+
+// Restore loads a dump written by Dump back into the database. workDir holds
+// the uncompressed dump as written by Dump; the output of the restore tool is
+// copied to progress while it runs.
+func (s *Service) Restore(ctx context.Context, database *databases.Database, workDir, format string, progress io.Writer) error {
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(workDir, DumpFileName)); err != nil {
+		return fmt.Errorf("dump file not found: %w", err)
+	}
+
+	job, err := buildRestoreJob(database, config, format)
+	if err != nil {
+		return err
+	}
+	if job.format != format {
+		return fmt.Errorf("a %s backup cannot be restored into a %s database", format, database.Type())
+	}
+
+	if !job.offline {
+		if err := s.requireRunning(ctx, database); err != nil {
+			return err
+		}
+		return s.runSidecar(ctx, config, "restore", job, workDir, restoreLogFileName, progress)
+	}
+
+	// Snapshot files are only read on startup, so swap them while the server is down
+	fmt.Fprintln(progress, "Stopping database container")
+	if err := s.Stop(ctx, database); err != nil {
+		return fmt.Errorf("failed to stop database container: %w", err)
+	}
+
+	restoreErr := s.runSidecar(ctx, config, "restore", job, workDir, restoreLogFileName, progress)
+
+	fmt.Fprintln(progress, "Starting database container")
+	if err := s.Start(ctx, database); err != nil {
+		return fmt.Errorf("failed to start database container: %w", err)
+	}
+
+	return restoreErr
+}
+
+// SupportsRestore reports whether a dump format can be restored into a database type.
+// Dragonfly backups are RDB snapshots, but Dragonfly prefers its own snapshots
+// on startup, so they can only be loaded by hand for now.
+func SupportsRestore(dbType databases.DatabaseType, format string) bool {
+	switch {
+	case dbType == databases.DatabaseTypePostgreSQL && format == DumpFormatPostgresBase:
+		return true
+	case dbType == databases.DatabaseTypeDragonfly:
+		return false
+	}
+	return format != "" && format == dumpFormatFor(dbType)
+}
+
+// buildRestoreJob returns the sidecar job that loads a dump of the given format
+// into a database
+func buildRestoreJob(database *databases.Database, config *DatabaseContainerConfig, format string) (*sidecarJob, error) {
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.Type() == databases.DatabaseTypePostgreSQL && format == DumpFormatPostgresBase {
+		return baseBackupRestoreJob(environment), nil
+	}
+
+	in := sidecarMountPath + "/" + DumpFileName
+
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		return &sidecarJob{
+			format: DumpFormatPostgres,
+			script: waitForReady + `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+pg_restore -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -d "$PGDATABASE" --clean --if-exists --no-owner --verbose ` + in,
+			environment: environment,
+		}, nil
+
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		// The dump was taken with --databases, so it recreates the schema itself
+		return &sidecarJob{
+			format: DumpFormatMySQL,
+			script: waitForReady + mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+echo "Loading SQL dump into $DB_NAME"
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" < ` + in + `
+echo "Restore completed"`,
+			environment: environment,
+		}, nil
+
+	case databases.DatabaseTypeMongoDB:
+		return &sidecarJob{
+			format: DumpFormatMongoDB,
+			script: waitForReady + mongoPing + `wait_for ping
+` + mongoArgs + `mongorestore "$@" --drop --archive=` + in,
+			environment: environment,
+		}, nil
+
+	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB:
+		// An AOF would take precedence over the snapshot on startup
+		return &sidecarJob{
+			format: DumpFormatRDB,
+			script: `rm -rf /data/appendonlydir /data/appendonly.aof
+cp ` + in + ` /data/dump.rdb
+echo "Snapshot copied to the data volume"`,
+			environment: environment,
+			mountData:   true,
+			offline:     true,
+		}, nil
+
+	case databases.DatabaseTypeClickHouse:
+		// RESTORE reads from the same allowed backups path BACKUP wrote to
+		return &sidecarJob{
+			format: DumpFormatClickHouse,
+			script: waitForReady + clickHouseClient + `wait_for ch --query "SELECT 1"
+NAME="mikrocloud-restore-$(date +%s).zip"
+OWNER=$(stat -c %u:%g /var/lib/clickhouse)
+mkdir -p /var/lib/clickhouse/backups
+cp ` + in + ` "/var/lib/clickhouse/backups/$NAME"
+chown "$OWNER" /var/lib/clickhouse/backups "/var/lib/clickhouse/backups/$NAME"
+echo "Dropping database $DB_NAME"
+ch --query "DROP DATABASE IF EXISTS \"$DB_NAME\" SYNC"
+echo "Restoring database $DB_NAME"
+ch --query "RESTORE DATABASE \"$DB_NAME\" FROM File('backups/$NAME')"
+rm -f "/var/lib/clickhouse/backups/$NAME"`,
+			environment: environment,
+			mountData:   true,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("restores are not supported for database type: %s", database.Type())
+	}
+}
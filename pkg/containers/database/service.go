@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
@@ -22,21 +23,24 @@ type Service struct {
 	imageResolver    DatabaseImageResolver
 	configBuilder    ContainerConfigBuilder
 	diskService      DiskService
+	walArchiveDir    string
 }
 
 func NewDatabaseDeploymentService(
 	containerService *services.ContainerService,
 	diskService DiskService,
+	walArchiveDir string,
 ) DatabaseDeploymentService {
 	// Database service helper
 	dbImageResolver := NewDefaultImageResolver()
-	dbConfigBuilder := NewDefaultContainerConfigBuilder(dbImageResolver)
+	dbConfigBuilder := NewDefaultContainerConfigBuilder(dbImageResolver, walArchiveDir)
 
 	return &Service{
 		containerService: containerService,
 		imageResolver:    dbImageResolver,
 		configBuilder:    dbConfigBuilder,
 		diskService:      diskService,
+		walArchiveDir:    walArchiveDir,
 	}
 }
 
@@ -53,6 +57,10 @@ func (s *Service) Deploy(ctx context.Context, database *databases.Database) (*De
 		return nil, fmt.Errorf("failed to pull image: %w", err)
 	}
 
+	if err := s.prepareWALArchive(database, config); err != nil {
+		return nil, err
+	}
+
 	// Get disk mounts if available
 	volumes := config.Volumes
 	if s.diskService != nil {
@@ -65,15 +73,27 @@ func (s *Service) Deploy(ctx context.Context, database *databases.Database) (*De
 		}
 	}
 
+	// Databases without an allocated host port keep publishing their own port
+	hostPort := config.Port
+	if database.HostPort() != 0 {
+		hostPort = strconv.Itoa(database.HostPort())
+	}
+
+	// Detect port conflicts before anything is created
+	if err := s.checkHostPort(ctx, config.ContainerName, hostPort); err != nil {
+		return nil, err
+	}
+
 	// Convert to manager.ContainerConfig
 	containerConfig := manager.ContainerConfig{
 		Image:         config.Image,
 		Name:          config.ContainerName,
-		Ports:         map[string]string{config.Port: config.Port},
+		Ports:         map[string]string{hostBinding(database, hostPort): config.Port},
 		Environment:   config.Environment,
 		Volumes:       volumes,
 		RestartPolicy: "unless-stopped",
 		Command:       config.Command,
+		Labels:        buildExposureLabels(database, config),
 	}
 
 	// Create and start the container
@@ -92,7 +112,7 @@ func (s *Service) Deploy(ctx context.Context, database *databases.Database) (*De
 	return &DeploymentResult{
 		ContainerID:   containerID,
 		ContainerName: config.ContainerName,
-		Port:          config.Port,
+		Port:          hostPort,
 		Status:        "running",
 		CreatedAt:     "", // Will be populated by inspection
 	}, nil
@@ -250,6 +270,12 @@ func (s *Service) GetLogs(ctx context.Context, database *databases.Database, fol
 
 // buildContainerName creates a consistent container name for the database
 func (s *Service) buildContainerName(database *databases.Database) string {
+	return ContainerName(database)
+}
+
+// ContainerName returns the name of the container of a database, which is also
+// its hostname on the container network
+func ContainerName(database *databases.Database) string {
 	return containers.SanitizeDockerName(fmt.Sprintf("mikrocloud-%s-%s-%s",
 		database.ProjectID(),
 		database.EnvironmentID(),
@@ -0,0 +1,330 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/manager"
+)
+
+// sidecarMountPath is where the work directory is mounted in a sidecar
+const sidecarMountPath = "/backup"
+
+// Shell snippets shared by the sidecar scripts. They expect the variables set
+// by sidecarEnvironment.
+const (
+	// mongoArgs sets the positional parameters to the connection flags
+	mongoArgs = `set -- --host 127.0.0.1 --port "$DB_PORT"
+if [ -n "$DB_USER" ]; then
+  set -- "$@" --username "$DB_USER" --password "$DB_PASSWORD" --authenticationDatabase "$DB_AUTH_SOURCE"
+fi
+`
+	// mongoPing defines ping, which checks the server with whichever shell the
+	// image ships
+	mongoPing = `ping() {
+  mongosh --quiet --host 127.0.0.1 --port "$DB_PORT" --eval "db.adminCommand({ping: 1})" ||
+    mongo --quiet --host 127.0.0.1 --port "$DB_PORT" --eval "db.adminCommand({ping: 1})"
+}
+`
+	// mysqlClients defines CLIENT and ADMIN, preferring the MariaDB binaries
+	mysqlClients = `CLIENT=mysql
+ADMIN=mysqladmin
+command -v mariadb >/dev/null 2>&1 && CLIENT=mariadb
+command -v mariadb-admin >/dev/null 2>&1 && ADMIN=mariadb-admin
+`
+	// redisCLI defines cli, preferring keydb-cli on KeyDB images
+	redisCLI = `CLI=redis-cli
+command -v keydb-cli >/dev/null 2>&1 && CLI=keydb-cli
+cli() { $CLI -h 127.0.0.1 -p "$DB_PORT" "$@"; }
+`
+	clickHouseClient = `ch() { clickhouse-client --host 127.0.0.1 --port "$DB_PORT" --user "$DB_USER" --password "$DB_PASSWORD" "$@"; }
+`
+)
+
+// sidecarJob is a shell script run in a short-lived container started from the
+// database image. Values are passed through the environment so they never need
+// shell quoting.
+type sidecarJob struct {
+	format      string
+	script      string
+	environment map[string]string
+	// mountData mounts the data volume of the database into the sidecar, for
+	// engines that read or write their snapshot on disk
+	mountData bool
+	// offline jobs run without a network while the database container is
+	// stopped. Other jobs join the network namespace of the database container
+	// so they reach it on localhost.
+	offline bool
+}
+
+// runSidecar runs job with workDir mounted at sidecarMountPath. The output of
+// the script is written to logFile inside workDir and, when progress is set,
+// copied there while the job runs.
+func (s *Service) runSidecar(ctx context.Context, config *DatabaseContainerConfig, suffix string, job *sidecarJob, workDir, logFile string, progress io.Writer) error {
+	if err := os.MkdirAll(workDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+
+	volumes := map[string]string{workDir: sidecarMountPath}
+	if job.mountData {
+		maps.Copy(volumes, config.Volumes)
+	}
+
+	networkMode := "container:" + config.ContainerName
+	if job.offline {
+		networkMode = "none"
+	}
+
+	name := config.ContainerName + "-" + suffix
+	s.removeContainerByName(ctx, name)
+
+	script := "exec >>" + sidecarMountPath + "/" + logFile + " 2>&1\nset -e\n" + job.script
+
+	containerID, err := s.containerService.CreateContainer(ctx, manager.ContainerConfig{
+		Image:         config.Image,
+		Name:          name,
+		Environment:   job.environment,
+		Volumes:       volumes,
+		NetworkMode:   networkMode,
+		RestartPolicy: "no",
+		Entrypoint:    []string{"sh", "-c"},
+		Command:       []string{script},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s container: %w", suffix, err)
+	}
+	defer func() {
+		_ = s.containerService.DeleteContainer(context.Background(), containerID)
+	}()
+
+	if err := s.containerService.StartContainer(ctx, containerID); err != nil {
+		return fmt.Errorf("failed to start %s container: %w", suffix, err)
+	}
+
+	logPath := filepath.Join(workDir, logFile)
+	var tail *logTail
+	if progress != nil {
+		tail = newLogTail(logPath, progress)
+		go tail.run()
+	}
+
+	exitCode, err := s.containerService.WaitContainer(ctx, containerID)
+	if tail != nil {
+		tail.stop()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to wait for %s container: %w", suffix, err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", job.format, exitCode, readSidecarLog(logPath))
+	}
+
+	return nil
+}
+
+// sidecarEnvironment returns the connection settings of a database as the
+// environment variables the sidecar scripts and native clients read
+func sidecarEnvironment(database *databases.Database, config *DatabaseContainerConfig) (map[string]string, error) {
+	dbConfig, err := parseDatabaseConfig(database)
+	if err != nil {
+		return nil, err
+	}
+
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		pg := dbConfig.PostgreSQL
+		if pg == nil {
+			return nil, fmt.Errorf("postgresql config is nil")
+		}
+		return map[string]string{
+			"DB_PORT":    config.Port,
+			"PGUSER":     pg.Username,
+			"PGPASSWORD": pg.Password,
+			"PGDATABASE": pg.DatabaseName,
+		}, nil
+
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		var name, user, password, rootPassword string
+		if mysql := dbConfig.MySQL; mysql != nil {
+			name, user, password, rootPassword = mysql.DatabaseName, mysql.Username, mysql.Password, mysql.RootPassword
+		} else if mariadb := dbConfig.MariaDB; mariadb != nil {
+			name, user, password, rootPassword = mariadb.DatabaseName, mariadb.Username, mariadb.Password, mariadb.RootPassword
+		} else {
+			return nil, fmt.Errorf("%s config is nil", database.Type())
+		}
+		// Routines and triggers need more than the application user's grants
+		if rootPassword != "" {
+			user, password = "root", rootPassword
+		}
+		return map[string]string{
+			"DB_PORT":   config.Port,
+			"DB_USER":   user,
+			"DB_NAME":   name,
+			"MYSQL_PWD": password,
+		}, nil
+
+	case databases.DatabaseTypeMongoDB:
+		mongo := dbConfig.MongoDB
+		if mongo == nil {
+			return nil, fmt.Errorf("mongodb config is nil")
+		}
+		authSource := mongo.AuthSource
+		if authSource == "" {
+			authSource = "admin"
+		}
+		return map[string]string{
+			"DB_PORT":        config.Port,
+			"DB_USER":        mongo.Username,
+			"DB_PASSWORD":    mongo.Password,
+			"DB_AUTH_SOURCE": authSource,
+		}, nil
+
+	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB, databases.DatabaseTypeDragonfly:
+		var password string
+		switch {
+		case dbConfig.Redis != nil:
+			password = dbConfig.Redis.Password
+		case dbConfig.KeyDB != nil:
+			password = dbConfig.KeyDB.Password
+		case dbConfig.Dragonfly != nil:
+			password = dbConfig.Dragonfly.Password
+		}
+		return map[string]string{
+			"DB_PORT":       config.Port,
+			"REDISCLI_AUTH": password,
+		}, nil
+
+	case databases.DatabaseTypeClickHouse:
+		ch := dbConfig.ClickHouse
+		if ch == nil {
+			return nil, fmt.Errorf("clickhouse config is nil")
+		}
+		return map[string]string{
+			"DB_PORT":     clickHouseNativePort(config),
+			"DB_USER":     ch.Username,
+			"DB_PASSWORD": ch.Password,
+			"DB_NAME":     ch.DatabaseName,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", database.Type())
+	}
+}
+
+func parseDatabaseConfig(database *databases.Database) (*databases.DatabaseConfig, error) {
+	configBytes, err := json.Marshal(database.Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var dbConfig databases.DatabaseConfig
+	if err := json.Unmarshal(configBytes, &dbConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &dbConfig, nil
+}
+
+// clickHouseNativePort falls back to the default native protocol port when the
+// configured port is the HTTP interface
+func clickHouseNativePort(config *DatabaseContainerConfig) string {
+	if port, err := strconv.Atoi(config.Port); err == nil && port != 8123 {
+		return config.Port
+	}
+	return "9000"
+}
+
+// logTail copies what is appended to a file to a writer until stopped
+type logTail struct {
+	path   string
+	out    io.Writer
+	offset int64
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func newLogTail(path string, out io.Writer) *logTail {
+	return &logTail{
+		path:   path,
+		out:    out,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+}
+
+func (t *logTail) run() {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			t.copy()
+			return
+		case <-ticker.C:
+			t.copy()
+		}
+	}
+}
+
+func (t *logTail) copy() {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return
+	}
+	n, _ := io.Copy(t.out, f)
+	t.offset += n
+}
+
+// stop flushes the remaining output and waits for the tail to finish
+func (t *logTail) stop() {
+	close(t.stopCh)
+	<-t.doneCh
+}
+
+func readSidecarLog(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "no output"
+	}
+
+	output := strings.TrimSpace(string(data))
+	if len(output) > 2048 {
+		output = output[len(output)-2048:]
+	}
+	if output == "" {
+		return "no output"
+	}
+	return output
+}
+
+// removeContainerByName deletes a leftover container, e.g. a sidecar from an
+// interrupted run
+func (s *Service) removeContainerByName(ctx context.Context, name string) {
+	containers, err := s.containerService.ListContainers(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, container := range containers {
+		if container.Name == name {
+			_ = s.containerService.DeleteContainer(ctx, container.ID)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package studio
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+type ClickHouseClient struct {
+	db *sql.DB
+}
+
+func NewClickHouseClient() *ClickHouseClient {
+	return &ClickHouseClient{}
+}
+
+func (c *ClickHouseClient) Connect(ctx context.Context, connectionString string) error {
+	opts, err := clickhouse.ParseDSN(connectionString)
+	if err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	db := clickhouse.OpenDB(opts)
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	c.db = db
+	return nil
+}
+
+func (c *ClickHouseClient) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
+
+func (c *ClickHouseClient) ListDatabases(ctx context.Context) ([]string, error) {
+	query := `SELECT name FROM system.databases WHERE name NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema') ORDER BY name`
+	return c.queryStrings(ctx, query)
+}
+
+func (c *ClickHouseClient) ListSchemas(ctx context.Context) ([]string, error) {
+	return c.ListDatabases(ctx)
+}
+
+func (c *ClickHouseClient) ListTables(ctx context.Context, schema string) ([]string, error) {
+	query := `SELECT name FROM system.tables WHERE database = currentDatabase() AND NOT is_temporary ORDER BY name`
+	var args []any
+	if schema != "" {
+		query = `SELECT name FROM system.tables WHERE database = ? AND NOT is_temporary ORDER BY name`
+		args = []any{schema}
+	}
+	return c.queryStrings(ctx, query, args...)
+}
+
+// GetTableSchema reports the sorting key columns as the primary key, since
+// that is what identifies rows for updates and deletes, and data skipping
+// indices as the indexes
+func (c *ClickHouseClient) GetTableSchema(ctx context.Context, tableName, schema string) (*TableSchema, error) {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	tableSchema := &TableSchema{
+		Name:        tableName,
+		Schema:      schema,
+		ForeignKeys: []ForeignKey{},
+	}
+
+	var totalRows sql.NullInt64
+	tableQuery := `SELECT engine, comment, total_rows FROM system.tables WHERE database = ? AND name = ?`
+	if err := c.db.QueryRowContext(ctx, tableQuery, schema, tableName).Scan(&tableSchema.Type, &tableSchema.Comment, &totalRows); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("table %s.%s not found", schema, tableName)
+		}
+		return nil, err
+	}
+	if totalRows.Valid {
+		tableSchema.RowCount = &totalRows.Int64
+	}
+
+	columnsQuery := `
+		SELECT
+			name,
+			type,
+			default_kind,
+			default_expression,
+			is_in_sorting_key
+		FROM system.columns
+		WHERE database = ? AND table = ?
+		ORDER BY position
+	`
+
+	rows, err := c.db.QueryContext(ctx, columnsQuery, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col Column
+		var defaultKind, defaultExpression string
+		var inSortingKey uint8
+
+		if err := rows.Scan(&col.Name, &col.NativeType, &defaultKind, &defaultExpression, &inSortingKey); err != nil {
+			return nil, err
+		}
+
+		col.Type = mapClickHouseType(col.NativeType)
+		col.Nullable = strings.HasPrefix(col.NativeType, "Nullable(")
+		if defaultKind != "" {
+			expression := defaultKind + " " + defaultExpression
+			col.DefaultValue = &expression
+		}
+
+		col.IsPrimaryKey = inSortingKey == 1
+		if col.IsPrimaryKey {
+			tableSchema.PrimaryKeys = append(tableSchema.PrimaryKeys, col.Name)
+		}
+
+		tableSchema.Columns = append(tableSchema.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexQuery := `SELECT name, expr, type FROM system.data_skipping_indices WHERE database = ? AND table = ? ORDER BY name`
+	indexRows, err := c.db.QueryContext(ctx, indexQuery, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+
+	for indexRows.Next() {
+		var idx Index
+		var expr string
+		if err := indexRows.Scan(&idx.Name, &expr, &idx.Type); err != nil {
+			return nil, err
+		}
+		idx.Columns = []string{expr}
+		tableSchema.Indexes = append(tableSchema.Indexes, idx)
+	}
+
+	return tableSchema, indexRows.Err()
+}
+
+func (c *ClickHouseClient) GetTableData(ctx context.Context, tableName, schema string, opts TableDataOptions) (*QueryResult, error) {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	query := fmt.Sprintf("SELECT * FROM `%s`.`%s`", schema, tableName)
+
+	whereClauses := []string{}
+	args := []any{}
+
+	for _, filter := range opts.Filters {
+		clause, arg := buildMySQLWhereClause(filter)
+		whereClauses = append(whereClauses, clause)
+		if arg != nil {
+			args = append(args, arg)
+		}
+	}
+
+	if len(whereClauses) > 0 {
+		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	if len(opts.Sorts) > 0 {
+		orderParts := []string{}
+		for _, sort := range opts.Sorts {
+			direction := "ASC"
+			if strings.ToUpper(sort.Direction) == "DESC" {
+				direction = "DESC"
+			}
+			orderParts = append(orderParts, fmt.Sprintf("`%s` %s", sort.Column, direction))
+		}
+		query += " ORDER BY " + strings.Join(orderParts, ", ")
+	}
+
+	countQuery := fmt.Sprintf("SELECT count() FROM `%s`.`%s`", schema, tableName)
+	if len(whereClauses) > 0 {
+		countQuery += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	var totalCount uint64
+	if err := c.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, err
+	}
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", opts.Offset)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return &QueryResult{Error: err.Error()}, err
+	}
+	defer rows.Close()
+
+	result, err := collectRows(rows, QueryOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	total := int64(totalCount)
+	result.TotalCount = &total
+	result.ExecutionTime = time.Since(start)
+	return result, nil
+}
+
+// ExecuteQuery runs a single statement, which is all the native protocol
+// accepts. ClickHouse has no transactions, so read-only mode and the timeout
+// are passed as the readonly and max_execution_time settings of the query.
+func (c *ClickHouseClient) ExecuteQuery(ctx context.Context, query string, opts QueryOptions) (*QueryResult, error) {
+	start := time.Now()
+
+	result, err := runSQLStatement(queryContext(ctx, opts), c.db, clickHouseDialect, query, opts)
+	if err != nil {
+		return &QueryResult{Error: err.Error()}, err
+	}
+
+	result.ExecutionTime = time.Since(start)
+	return result, nil
+}
+
+// ExportQuery streams the rows of a read-only query to w
+func (c *ClickHouseClient) ExportQuery(ctx context.Context, query string, timeout time.Duration, w RowWriter) error {
+	return exportRows(queryContext(ctx, QueryOptions{ReadOnly: true, Timeout: timeout}), c.db, query, w)
+}
+
+func (c *ClickHouseClient) ExportTable(ctx context.Context, tableName, schema string, w RowWriter) error {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("SELECT * FROM %s.%s", clickHouseDialect.quoteIdentifier(schema), clickHouseDialect.quoteIdentifier(tableName))
+	return c.ExportQuery(ctx, query, 0, w)
+}
+
+// queryContext passes read-only mode and the timeout of a studio query as
+// the readonly and max_execution_time settings
+func queryContext(ctx context.Context, opts QueryOptions) context.Context {
+	settings := clickhouse.Settings{}
+	if opts.ReadOnly {
+		// 2 still allows the settings below to be changed
+		settings["readonly"] = 2
+	}
+	if opts.Timeout > 0 {
+		settings["max_execution_time"] = int(math.Ceil(opts.Timeout.Seconds()))
+	}
+	return clickhouse.Context(ctx, clickhouse.WithSettings(settings))
+}
+
+func (c *ClickHouseClient) InsertRow(ctx context.Context, tableName, schema string, data map[string]any) error {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{}
+	placeholders := []string{}
+	values := []any{}
+
+	for col, val := range data {
+		columns = append(columns, fmt.Sprintf("`%s`", col))
+		placeholders = append(placeholders, "?")
+		values = append(values, val)
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO `%s`.`%s` (%s) VALUES (%s)",
+		schema, tableName,
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+	)
+
+	_, err = c.db.ExecContext(ctx, query, values...)
+	return err
+}
+
+// UpdateRow runs an ALTER TABLE ... UPDATE mutation and waits for it to be
+// applied, so the change is visible when the studio reloads the table.
+// Columns of the sorting key can't be updated.
+func (c *ClickHouseClient) UpdateRow(ctx context.Context, tableName, schema string, primaryKey map[string]any, data map[string]any) error {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return err
+	}
+
+	setClauses := []string{}
+	whereClauses := []string{}
+	values := []any{}
+
+	for col, val := range data {
+		setClauses = append(setClauses, fmt.Sprintf("`%s` = ?", col))
+		values = append(values, val)
+	}
+
+	for col, val := range primaryKey {
+		whereClauses = append(whereClauses, fmt.Sprintf("`%s` = ?", col))
+		values = append(values, val)
+	}
+
+	query := fmt.Sprintf(
+		"ALTER TABLE `%s`.`%s` UPDATE %s WHERE %s",
+		schema, tableName,
+		strings.Join(setClauses, ", "),
+		strings.Join(whereClauses, " AND "),
+	)
+
+	_, err = c.db.ExecContext(mutationContext(ctx), query, values...)
+	return err
+}
+
+// DeleteRow runs an ALTER TABLE ... DELETE mutation and waits for it to be
+// applied
+func (c *ClickHouseClient) DeleteRow(ctx context.Context, tableName, schema string, primaryKey map[string]any) error {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return err
+	}
+
+	whereClauses := []string{}
+	values := []any{}
+
+	for col, val := range primaryKey {
+		whereClauses = append(whereClauses, fmt.Sprintf("`%s` = ?", col))
+		values = append(values, val)
+	}
+
+	query := fmt.Sprintf(
+		"ALTER TABLE `%s`.`%s` DELETE WHERE %s",
+		schema, tableName,
+		strings.Join(whereClauses, " AND "),
+	)
+
+	_, err = c.db.ExecContext(mutationContext(ctx), query, values...)
+	return err
+}
+
+func (c *ClickHouseClient) GetDatabaseVersion(ctx context.Context) (string, error) {
+	var version string
+	err := c.db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
+	return version, err
+}
+
+func (c *ClickHouseClient) GetDatabaseSize(ctx context.Context) (int64, error) {
+	var size uint64
+	query := `SELECT sum(bytes_on_disk) FROM system.parts WHERE database = currentDatabase() AND active`
+	err := c.db.QueryRowContext(ctx, query).Scan(&size)
+	return int64(size), err
+}
+
+// ListPartitions summarises the active parts of a table by partition. Tables
+// without a date or time in their partition key have no time range.
+func (c *ClickHouseClient) ListPartitions(ctx context.Context, tableName, schema string) ([]Partition, error) {
+	schema, err := c.schemaOrCurrent(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			partition,
+			count(),
+			sum(rows),
+			sum(bytes_on_disk),
+			min(min_time),
+			max(max_time)
+		FROM system.parts
+		WHERE database = ? AND table = ? AND active
+		GROUP BY partition
+		ORDER BY partition
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := []Partition{}
+	for rows.Next() {
+		var partition Partition
+		var parts, rowCount, bytes uint64
+		var minTime, maxTime time.Time
+
+		if err := rows.Scan(&partition.Partition, &parts, &rowCount, &bytes, &minTime, &maxTime); err != nil {
+			return nil, err
+		}
+
+		partition.Parts = int64(parts)
+		partition.Rows = int64(rowCount)
+		partition.BytesOnDisk = int64(bytes)
+		if minTime.Unix() > 0 {
+			partition.MinTime = &minTime
+			partition.MaxTime = &maxTime
+		}
+
+		partitions = append(partitions, partition)
+	}
+
+	return partitions, rows.Err()
+}
+
+func (c *ClickHouseClient) schemaOrCurrent(ctx context.Context, schema string) (string, error) {
+	if schema != "" {
+		return schema, nil
+	}
+	err := c.db.QueryRowContext(ctx, "SELECT currentDatabase()").Scan(&schema)
+	return schema, err
+}
+
+func (c *ClickHouseClient) queryStrings(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// mutationContext makes ALTER TABLE mutations return only once they have been
+// applied
+func mutationContext(ctx context.Context) context.Context {
+	return clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"mutations_sync": 1,
+	}))
+}
+
+func mapClickHouseType(nativeType string) ColumnType {
+	nativeType = strings.TrimSuffix(strings.TrimPrefix(nativeType, "Nullable("), ")")
+	nativeType = strings.TrimSuffix(strings.TrimPrefix(nativeType, "LowCardinality("), ")")
+	switch {
+	case strings.HasPrefix(nativeType, "Array("):
+		return ColumnTypeArray
+	case nativeType == "String", strings.HasPrefix(nativeType, "FixedString"), strings.HasPrefix(nativeType, "Enum"):
+		return ColumnTypeString
+	case strings.HasPrefix(nativeType, "Int"), strings.HasPrefix(nativeType, "UInt"):
+		return ColumnTypeInteger
+	case strings.HasPrefix(nativeType, "Float"), strings.HasPrefix(nativeType, "Decimal"):
+		return ColumnTypeFloat
+	case nativeType == "Bool":
+		return ColumnTypeBoolean
+	case strings.HasPrefix(nativeType, "Date32"), nativeType == "Date":
+		return ColumnTypeDate
+	case strings.HasPrefix(nativeType, "DateTime"):
+		return ColumnTypeDateTime
+	case nativeType == "UUID":
+		return ColumnTypeUUID
+	case strings.HasPrefix(nativeType, "Map("), strings.HasPrefix(nativeType, "Tuple("), nativeType == "JSON", strings.HasPrefix(nativeType, "JSON("):
+		return ColumnTypeJSON
+	default:
+		return ColumnTypeUnknown
+	}
+}
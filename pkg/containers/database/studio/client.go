@@ -69,12 +69,22 @@ type QueryResult struct {
 	RowsAffected  int64            `json:"rows_affected"`
 	ExecutionTime time.Duration    `json:"execution_time"`
 	TotalCount    *int64           `json:"total_count,omitempty"`
+	Truncated     bool             `json:"truncated,omitempty"`
 	Error         string           `json:"error,omitempty"`
 }
 
+// QueryOptions bound a query run from the studio. The row and byte caps are
+// applied while reading the result, so queries don't have to be rewritten.
 type QueryOptions struct {
 	Limit  int
 	Offset int
+
+	// ReadOnly rejects anything that writes data
+	ReadOnly bool
+	// Timeout is enforced by the database server where it supports one
+	Timeout time.Duration
+	// MaxBytes caps the approximate size of the values returned
+	MaxBytes int64
 }
 
 type Filter struct {
@@ -114,3 +124,48 @@ type DatabaseClient interface {
 	GetDatabaseVersion(ctx context.Context) (string, error)
 	GetDatabaseSize(ctx context.Context) (int64, error)
 }
+
+// DataModel tells the studio UI how to present the data of a database
+type DataModel string
+
+const (
+	DataModelRelational DataModel = "relational"
+	DataModelDocument   DataModel = "document"
+	DataModelKeyValue   DataModel = "key_value"
+	DataModelColumnar   DataModel = "columnar"
+)
+
+// KeyValue is a key of a key-value store with its type-dependent value. Large
+// collections are cut off after a fixed number of elements.
+type KeyValue struct {
+	Key       string `json:"key"`
+	Type      string `json:"type"`
+	TTL       int64  `json:"ttl"`  // seconds until the key expires, -1 if it doesn't
+	Size      int64  `json:"size"` // bytes of a string, elements of other types
+	Value     any    `json:"value"`
+	Truncated bool   `json:"truncated"`
+}
+
+// KeyBrowser is implemented by clients of key-value stores, whose keys hold
+// typed values with an expiry rather than rows
+type KeyBrowser interface {
+	GetKey(ctx context.Context, schema, key string) (*KeyValue, error)
+	// SetKeyTTL sets the expiry of a key; a ttl of zero or less removes it
+	SetKeyTTL(ctx context.Context, schema, key string, ttl time.Duration) error
+}
+
+// Partition summarises the active parts of one partition of a table
+type Partition struct {
+	Partition   string     `json:"partition"`
+	Parts       int64      `json:"parts"`
+	Rows        int64      `json:"rows"`
+	BytesOnDisk int64      `json:"bytes_on_disk"`
+	MinTime     *time.Time `json:"min_time,omitempty"`
+	MaxTime     *time.Time `json:"max_time,omitempty"`
+}
+
+// PartitionBrowser is implemented by clients of databases that store tables
+// in partitions
+type PartitionBrowser interface {
+	ListPartitions(ctx context.Context, tableName, schema string) ([]Partition, error)
+}
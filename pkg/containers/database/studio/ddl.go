@@ -0,0 +1,462 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+type SchemaChangeKind string
+
+const (
+	SchemaChangeCreateTable    SchemaChangeKind = "create_table"
+	SchemaChangeRenameTable    SchemaChangeKind = "rename_table"
+	SchemaChangeDropTable      SchemaChangeKind = "drop_table"
+	SchemaChangeAddColumn      SchemaChangeKind = "add_column"
+	SchemaChangeAlterColumn    SchemaChangeKind = "alter_column"
+	SchemaChangeRenameColumn   SchemaChangeKind = "rename_column"
+	SchemaChangeDropColumn     SchemaChangeKind = "drop_column"
+	SchemaChangeCreateIndex    SchemaChangeKind = "create_index"
+	SchemaChangeDropIndex      SchemaChangeKind = "drop_index"
+	SchemaChangeAddForeignKey  SchemaChangeKind = "add_foreign_key"
+	SchemaChangeDropForeignKey SchemaChangeKind = "drop_foreign_key"
+)
+
+// ColumnDefinition describes a column to create or change. PrimaryKey,
+// Unique and AutoIncrement only apply when the column is created.
+type ColumnDefinition struct {
+	Name          string  `json:"name" validate:"required,max=64"`
+	Type          string  `json:"type" validate:"required,max=64"`
+	Nullable      bool    `json:"nullable"`
+	Default       *string `json:"default,omitempty"`
+	PrimaryKey    bool    `json:"primary_key,omitempty"`
+	Unique        bool    `json:"unique,omitempty"`
+	AutoIncrement bool    `json:"auto_increment,omitempty"`
+}
+
+// SchemaChange is a single change to the schema of a table
+type SchemaChange struct {
+	Kind   SchemaChangeKind
+	Schema string
+	Table  string
+
+	// Name is the column, index or foreign key the change applies to
+	Name    string
+	NewName string
+
+	Columns    []ColumnDefinition // create_table
+	Column     *ColumnDefinition  // add_column and alter_column
+	Index      *Index             // create_index
+	ForeignKey *ForeignKey        // add_foreign_key
+
+	// Cascade drops the objects that depend on a dropped table
+	Cascade bool
+}
+
+// SchemaEditor is implemented by clients that can change the schema of a
+// database. ApplySchemaChange returns the statements it ran.
+type SchemaEditor interface {
+	ApplySchemaChange(ctx context.Context, change SchemaChange) ([]string, error)
+	// PlanSchemaChange returns the statements ApplySchemaChange would run
+	// without running them
+	PlanSchemaChange(ctx context.Context, change SchemaChange) ([]string, error)
+}
+
+var (
+	// nativeTypePattern accepts type names such as varchar(255), numeric(10, 2),
+	// int unsigned, timestamp with time zone and text[]
+	nativeTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*\d+\s*(,\s*\d+\s*)?\))?[A-Za-z0-9_ ]*(\[\])*$`)
+
+	referentialActions = map[string]bool{
+		"CASCADE":     true,
+		"SET NULL":    true,
+		"SET DEFAULT": true,
+		"RESTRICT":    true,
+		"NO ACTION":   true,
+	}
+
+	postgresIndexMethods = map[string]bool{"btree": true, "hash": true, "gin": true, "gist": true, "spgist": true, "brin": true}
+	mysqlIndexMethods    = map[string]bool{"btree": true, "hash": true}
+)
+
+// BuildSchemaChange returns the statements that make a change in a database
+// of the type, so that a change can be validated before connecting. MySQL
+// column changes depend on the current column, PlanSchemaChange of the client
+// returns the statements that will run.
+func BuildSchemaChange(dbType databases.DatabaseType, change SchemaChange) ([]string, error) {
+	switch dbType {
+	case databases.DatabaseTypePostgreSQL:
+		return postgresSchemaChange(change)
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		return mysqlSchemaChange(change, nil)
+	default:
+		return nil, fmt.Errorf("schema changes are not supported for %s databases", dbType)
+	}
+}
+
+func postgresSchemaChange(change SchemaChange) ([]string, error) {
+	d := postgresDialect
+	schema := change.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	table := d.quoteIdentifier(schema) + "." + d.quoteIdentifier(change.Table)
+
+	if err := validateSchemaChange(d, change); err != nil {
+		return nil, err
+	}
+
+	switch change.Kind {
+	case SchemaChangeCreateTable:
+		return []string{createTableStatement(d, table, change.Columns, "GENERATED BY DEFAULT AS IDENTITY")}, nil
+
+	case SchemaChangeRenameTable:
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, d.quoteIdentifier(change.NewName))}, nil
+
+	case SchemaChangeDropTable:
+		statement := "DROP TABLE " + table
+		if change.Cascade {
+			statement += " CASCADE"
+		}
+		return []string{statement}, nil
+
+	case SchemaChangeAddColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, columnDefinition(d, *change.Column, "GENERATED BY DEFAULT AS IDENTITY"))}, nil
+
+	case SchemaChangeAlterColumn:
+		// PostgreSQL changes the type, nullability and default separately, in
+		// one statement so that they apply together
+		col := d.quoteIdentifier(change.Name)
+		clauses := []string{fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", col, change.Column.Type, col, change.Column.Type)}
+		if change.Column.Nullable {
+			clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", col))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", col))
+		}
+		if change.Column.Default != nil {
+			clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", col, *change.Column.Default))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", col))
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s %s", table, strings.Join(clauses, ", "))}, nil
+
+	case SchemaChangeRenameColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, d.quoteIdentifier(change.Name), d.quoteIdentifier(change.NewName))}, nil
+
+	case SchemaChangeDropColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, d.quoteIdentifier(change.Name))}, nil
+
+	case SchemaChangeCreateIndex:
+		index := change.Index
+		statement := "CREATE INDEX "
+		if index.IsUnique {
+			statement = "CREATE UNIQUE INDEX "
+		}
+		statement += d.quoteIdentifier(index.Name) + " ON " + table
+		if index.Type != "" {
+			statement += " USING " + strings.ToLower(index.Type)
+		}
+		return []string{statement + " (" + quoteIdentifiers(d, index.Columns) + ")"}, nil
+
+	case SchemaChangeDropIndex:
+		return []string{fmt.Sprintf("DROP INDEX %s.%s", d.quoteIdentifier(schema), d.quoteIdentifier(change.Name))}, nil
+
+	case SchemaChangeAddForeignKey:
+		referenced := d.quoteIdentifier(schema) + "." + d.quoteIdentifier(change.ForeignKey.ReferencedTable)
+		return []string{addForeignKeyStatement(d, table, referenced, *change.ForeignKey)}, nil
+
+	case SchemaChangeDropForeignKey:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, d.quoteIdentifier(change.Name))}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown schema change: %s", change.Kind)
+	}
+}
+
+// mysqlColumnAttributes are the attributes of a MySQL column that a change
+// doesn't describe, MODIFY COLUMN drops them unless they are repeated
+type mysqlColumnAttributes struct {
+	AutoIncrement bool
+	OnUpdate      string
+	Collation     string
+	Comment       string
+}
+
+// mysqlTextTypes are the types that take a collation
+var mysqlTextTypes = map[string]bool{
+	"char": true, "varchar": true, "tinytext": true, "text": true, "mediumtext": true, "longtext": true, "enum": true, "set": true,
+}
+
+// mysqlSchemaChange builds the statements of a change. current holds the
+// attributes of the column a change alters, nil when unknown.
+func mysqlSchemaChange(change SchemaChange, current *mysqlColumnAttributes) ([]string, error) {
+	d := mysqlDialect
+	table := d.quoteIdentifier(change.Table)
+	if change.Schema != "" {
+		table = d.quoteIdentifier(change.Schema) + "." + table
+	}
+
+	if err := validateSchemaChange(d, change); err != nil {
+		return nil, err
+	}
+
+	switch change.Kind {
+	case SchemaChangeCreateTable:
+		return []string{createTableStatement(d, table, change.Columns, "AUTO_INCREMENT")}, nil
+
+	case SchemaChangeRenameTable:
+		renamed := d.quoteIdentifier(change.NewName)
+		if change.Schema != "" {
+			renamed = d.quoteIdentifier(change.Schema) + "." + renamed
+		}
+		return []string{fmt.Sprintf("RENAME TABLE %s TO %s", table, renamed)}, nil
+
+	case SchemaChangeDropTable:
+		// MySQL accepts CASCADE but ignores it
+		if change.Cascade {
+			return nil, fmt.Errorf("MySQL cannot drop the objects that depend on a table")
+		}
+		return []string{"DROP TABLE " + table}, nil
+
+	case SchemaChangeAddColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, columnDefinition(d, *change.Column, "AUTO_INCREMENT"))}, nil
+
+	case SchemaChangeAlterColumn:
+		// Keys are left to the indexes, MODIFY COLUMN would add another one
+		col := *change.Column
+		col.Name = change.Name
+		col.PrimaryKey, col.Unique = false, false
+		if current == nil {
+			return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", table, columnDefinition(d, col, "AUTO_INCREMENT"))}, nil
+		}
+
+		col.AutoIncrement = col.AutoIncrement || current.AutoIncrement
+		if current.Collation != "" && mysqlTextTypes[mysqlBaseType(col.Type)] && !strings.Contains(strings.ToLower(col.Type), "collate") {
+			col.Type += " COLLATE " + current.Collation
+		}
+		definition := columnDefinition(d, col, "AUTO_INCREMENT")
+		if current.OnUpdate != "" {
+			definition += " ON UPDATE " + current.OnUpdate
+		}
+		if current.Comment != "" {
+			definition += " COMMENT " + d.quoteString(current.Comment)
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", table, definition)}, nil
+
+	case SchemaChangeRenameColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, d.quoteIdentifier(change.Name), d.quoteIdentifier(change.NewName))}, nil
+
+	case SchemaChangeDropColumn:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, d.quoteIdentifier(change.Name))}, nil
+
+	case SchemaChangeCreateIndex:
+		index := change.Index
+		statement := "CREATE INDEX "
+		if index.IsUnique {
+			statement = "CREATE UNIQUE INDEX "
+		}
+		statement += d.quoteIdentifier(index.Name) + " ON " + table + " (" + quoteIdentifiers(d, index.Columns) + ")"
+		if index.Type != "" {
+			statement += " USING " + strings.ToUpper(index.Type)
+		}
+		return []string{statement}, nil
+
+	case SchemaChangeDropIndex:
+		return []string{fmt.Sprintf("DROP INDEX %s ON %s", d.quoteIdentifier(change.Name), table)}, nil
+
+	case SchemaChangeAddForeignKey:
+		referenced := d.quoteIdentifier(change.ForeignKey.ReferencedTable)
+		if change.Schema != "" {
+			referenced = d.quoteIdentifier(change.Schema) + "." + referenced
+		}
+		return []string{addForeignKeyStatement(d, table, referenced, *change.ForeignKey)}, nil
+
+	case SchemaChangeDropForeignKey:
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", table, d.quoteIdentifier(change.Name))}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown schema change: %s", change.Kind)
+	}
+}
+
+// validateSchemaChange checks that a change has what its kind needs and that
+// the types and expressions it holds can't break out of the statement. Names
+// are quoted, so any name is safe.
+func validateSchemaChange(d sqlDialect, change SchemaChange) error {
+	if change.Table == "" {
+		return fmt.Errorf("table name is required")
+	}
+
+	switch change.Kind {
+	case SchemaChangeCreateTable:
+		if len(change.Columns) == 0 {
+			return fmt.Errorf("a table needs at least one column")
+		}
+		for _, col := range change.Columns {
+			if err := validateColumn(d, col); err != nil {
+				return err
+			}
+		}
+
+	case SchemaChangeAddColumn, SchemaChangeAlterColumn:
+		if change.Column == nil {
+			return fmt.Errorf("column definition is required")
+		}
+		if change.Kind == SchemaChangeAlterColumn && change.Name == "" {
+			return fmt.Errorf("column name is required")
+		}
+		return validateColumn(d, *change.Column)
+
+	case SchemaChangeRenameTable, SchemaChangeRenameColumn:
+		if change.NewName == "" {
+			return fmt.Errorf("new name is required")
+		}
+		if change.Kind == SchemaChangeRenameColumn && change.Name == "" {
+			return fmt.Errorf("column name is required")
+		}
+
+	case SchemaChangeDropColumn, SchemaChangeDropIndex, SchemaChangeDropForeignKey:
+		if change.Name == "" {
+			return fmt.Errorf("name is required")
+		}
+
+	case SchemaChangeCreateIndex:
+		index := change.Index
+		if index == nil || index.Name == "" || len(index.Columns) == 0 {
+			return fmt.Errorf("an index needs a name and at least one column")
+		}
+		methods := mysqlIndexMethods
+		if d == postgresDialect {
+			methods = postgresIndexMethods
+		}
+		if index.Type != "" && !methods[strings.ToLower(index.Type)] {
+			return fmt.Errorf("unsupported index type: %s", index.Type)
+		}
+
+	case SchemaChangeAddForeignKey:
+		fk := change.ForeignKey
+		if fk == nil || fk.Name == "" || fk.Column == "" || fk.ReferencedTable == "" || fk.ReferencedColumn == "" {
+			return fmt.Errorf("a foreign key needs a name, a column and the table and column it references")
+		}
+		for _, action := range []string{fk.OnDelete, fk.OnUpdate} {
+			if action != "" && !referentialActions[strings.ToUpper(action)] {
+				return fmt.Errorf("unsupported referential action: %s", action)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateColumn(d sqlDialect, col ColumnDefinition) error {
+	if col.Name == "" {
+		return fmt.Errorf("column name is required")
+	}
+	if !nativeTypePattern.MatchString(col.Type) {
+		return fmt.Errorf("invalid type for column %s: %s", col.Name, col.Type)
+	}
+	if col.Default != nil && !d.isExpression(*col.Default) {
+		return fmt.Errorf("invalid default for column %s: %s", col.Name, *col.Default)
+	}
+	return nil
+}
+
+// isExpression reports whether s stays a single expression when placed in a
+// statement: it must not end the statement, comment out or quote what
+// follows it, or close more parentheses than it opens
+func (d sqlDialect) isExpression(s string) bool {
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+
+	stripped := d.strip(s)
+	if strings.Contains(stripped, ";") {
+		return false
+	}
+	if !strings.HasSuffix(d.strip(s+" )"), ")") {
+		return false
+	}
+
+	depth := 0
+	for _, ch := range stripped {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+func createTableStatement(d sqlDialect, table string, columns []ColumnDefinition, autoIncrement string) string {
+	var definitions, primaryKeys []string
+	for _, col := range columns {
+		// The primary key is declared once for the table so it can span columns
+		pk := col.PrimaryKey
+		col.PrimaryKey = false
+		definitions = append(definitions, "  "+columnDefinition(d, col, autoIncrement))
+		if pk {
+			primaryKeys = append(primaryKeys, col.Name)
+		}
+	}
+	if len(primaryKeys) > 0 {
+		definitions = append(definitions, "  PRIMARY KEY ("+quoteIdentifiers(d, primaryKeys)+")")
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n)", table, strings.Join(definitions, ",\n"))
+}
+
+// mysqlBaseType is the lowercase name of a type without its length, e.g.
+// varchar for VARCHAR(255)
+func mysqlBaseType(nativeType string) string {
+	name, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(nativeType)), "(")
+	name, _, _ = strings.Cut(name, " ")
+	return name
+}
+
+func columnDefinition(d sqlDialect, col ColumnDefinition, autoIncrement string) string {
+	parts := []string{d.quoteIdentifier(col.Name), col.Type}
+	if !col.Nullable || col.PrimaryKey {
+		parts = append(parts, "NOT NULL")
+	}
+	if col.AutoIncrement && autoIncrement != "" {
+		parts = append(parts, autoIncrement)
+	}
+	if col.Default != nil {
+		parts = append(parts, "DEFAULT "+*col.Default)
+	}
+	if col.PrimaryKey {
+		parts = append(parts, "PRIMARY KEY")
+	} else if col.Unique {
+		parts = append(parts, "UNIQUE")
+	}
+	return strings.Join(parts, " ")
+}
+
+func addForeignKeyStatement(d sqlDialect, table, referenced string, fk ForeignKey) string {
+	statement := fmt.Sprintf(
+		"ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		table, d.quoteIdentifier(fk.Name), d.quoteIdentifier(fk.Column), referenced, d.quoteIdentifier(fk.ReferencedColumn),
+	)
+	if fk.OnDelete != "" {
+		statement += " ON DELETE " + strings.ToUpper(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		statement += " ON UPDATE " + strings.ToUpper(fk.OnUpdate)
+	}
+	return statement
+}
+
+func quoteIdentifiers(d sqlDialect, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.quoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,324 @@
+package studio
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatSQL  ExportFormat = "sql"
+)
+
+// ContentType is the MIME type of an export in the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ExportFormatJSON:
+		return "application/json"
+	default:
+		return "application/sql; charset=utf-8"
+	}
+}
+
+// RowWriter writes an export one row at a time, so results of any size pass
+// through without being held in memory. WriteHeader is called once before
+// the first row and Close after the last one.
+type RowWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// Exporter is implemented by clients that can stream a result set straight
+// from the database instead of reading it page by page
+type Exporter interface {
+	// ExportQuery runs a query read-only and writes all of its rows
+	ExportQuery(ctx context.Context, query string, timeout time.Duration, w RowWriter) error
+	ExportTable(ctx context.Context, tableName, schema string, w RowWriter) error
+}
+
+// NewRowWriter returns a writer of the format. SQL exports are INSERT
+// statements into table in the dialect of the database type, so they are
+// only available for SQL databases.
+func NewRowWriter(format ExportFormat, out io.Writer, dbType databases.DatabaseType, table string) (RowWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return &csvWriter{w: csv.NewWriter(out)}, nil
+	case ExportFormatJSON:
+		return &jsonWriter{w: bufio.NewWriter(out)}, nil
+	case ExportFormatSQL:
+		dialect, ok := dialectFor(dbType)
+		if !ok {
+			return nil, fmt.Errorf("SQL export is not supported for %s databases", dbType)
+		}
+		if table == "" {
+			table = "export"
+		}
+		return &sqlWriter{w: bufio.NewWriter(out), dialect: dialect, table: dialect.quoteIdentifier(table)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func dialectFor(dbType databases.DatabaseType) (sqlDialect, bool) {
+	switch dbType {
+	case databases.DatabaseTypePostgreSQL:
+		return postgresDialect, true
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		return mysqlDialect, true
+	case databases.DatabaseTypeClickHouse:
+		return clickHouseDialect, true
+	default:
+		return sqlDialect{}, false
+	}
+}
+
+// exportRows runs a query and writes every row it returns to w
+func exportRows(ctx context.Context, db sqlRunner, query string, w RowWriter) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if err := w.WriteHeader(columns); err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns))
+	valuePtrs := make([]any, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return err
+		}
+		if err := w.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// WriteResultRows writes the rows of a query result to w, for clients that
+// aren't Exporters. The header is left to the caller, which may write the
+// result in pages.
+func WriteResultRows(result *QueryResult, w RowWriter) error {
+	values := make([]any, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, col := range result.Columns {
+			values[i] = row[col]
+		}
+		if err := w.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	c.record = make([]string, len(columns))
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		c.record[i] = exportString(v)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes an array of objects whose keys keep the column order
+type jsonWriter struct {
+	w       *bufio.Writer
+	columns [][]byte
+	rows    int
+}
+
+func (j *jsonWriter) WriteHeader(columns []string) error {
+	j.columns = make([][]byte, len(columns))
+	for i, col := range columns {
+		key, err := json.Marshal(col)
+		if err != nil {
+			return err
+		}
+		j.columns[i] = key
+	}
+	_, err := j.w.WriteString("[")
+	return err
+}
+
+func (j *jsonWriter) WriteRow(values []any) error {
+	if j.rows > 0 {
+		j.w.WriteString(",")
+	}
+	j.rows++
+
+	j.w.WriteString("\n{")
+	for i, v := range values {
+		if i > 0 {
+			j.w.WriteString(",")
+		}
+		value, err := json.Marshal(exportJSONValue(v))
+		if err != nil {
+			return fmt.Errorf("failed to encode column %s: %w", j.columns[i], err)
+		}
+		j.w.Write(j.columns[i])
+		j.w.WriteString(":")
+		if _, err := j.w.Write(value); err != nil {
+			return err
+		}
+	}
+	_, err := j.w.WriteString("}")
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.columns == nil {
+		j.w.WriteString("[")
+	}
+	j.w.WriteString("\n]\n")
+	return j.w.Flush()
+}
+
+// sqlWriter writes one INSERT statement per row
+type sqlWriter struct {
+	w       *bufio.Writer
+	dialect sqlDialect
+	table   string
+	prefix  string
+}
+
+func (s *sqlWriter) WriteHeader(columns []string) error {
+	s.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES (", s.table, quoteIdentifiers(s.dialect, columns))
+	return nil
+}
+
+func (s *sqlWriter) WriteRow(values []any) error {
+	s.w.WriteString(s.prefix)
+	for i, v := range values {
+		if i > 0 {
+			s.w.WriteString(", ")
+		}
+		s.w.WriteString(s.dialect.literal(v))
+	}
+	_, err := s.w.WriteString(");\n")
+	return err
+}
+
+func (s *sqlWriter) Close() error {
+	return s.w.Flush()
+}
+
+func (d sqlDialect) quoteIdentifier(name string) string {
+	quote := string(d.identifierQuote)
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// literal renders a scanned value as a SQL literal
+func (d sqlDialect) literal(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case []byte:
+		if utf8.Valid(v) {
+			return d.quoteString(string(v))
+		}
+		return fmt.Sprintf(d.binaryLiteral, hex.EncodeToString(v))
+	case time.Time:
+		return d.quoteString(v.Format("2006-01-02 15:04:05.999999999"))
+	default:
+		return d.quoteString(exportString(v))
+	}
+}
+
+func (d sqlDialect) quoteString(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if d.backslashEscapes {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
+
+// exportString renders a value as text for CSV. Binary values that aren't
+// UTF-8 are base64 encoded.
+func exportString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	case fmt.Stringer:
+		return v.String()
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exportJSONValue turns the text a driver returns as bytes into a string, so
+// it isn't base64 encoded like binary data
+func exportJSONValue(value any) any {
+	if v, ok := value.([]byte); ok && utf8.Valid(v) {
+		return string(v)
+	}
+	return value
+}
@@ -41,13 +41,41 @@ func (f *ClientFactory) CreateClient(ctx context.Context, db *databases.Database
 		return client, nil
 
 	case databases.DatabaseTypeMongoDB:
-		return nil, fmt.Errorf("MongoDB support is coming soon")
+		client := NewMongoDBClient()
+		connStr := db.ConnectionString()
+		if connStr == "" {
+			connStr = db.GenerateConnectionString()
+		}
+		authSource := "admin"
+		if cfg := db.Config().MongoDB; cfg != nil && cfg.AuthSource != "" {
+			authSource = cfg.AuthSource
+		}
+		if err := client.Connect(ctx, ensureAuthSource(connStr, authSource)); err != nil {
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		return client, nil
 
 	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB, databases.DatabaseTypeDragonfly:
-		return nil, fmt.Errorf("Redis-based databases do not support studio features (use CLI instead)")
+		client := NewRedisClient()
+		connStr := db.ConnectionString()
+		if connStr == "" {
+			connStr = db.GenerateConnectionString()
+		}
+		if err := client.Connect(ctx, connStr); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", db.Type(), err)
+		}
+		return client, nil
 
 	case databases.DatabaseTypeClickHouse:
-		return nil, fmt.Errorf("ClickHouse support is coming soon")
+		client := NewClickHouseClient()
+		connStr := db.ConnectionString()
+		if connStr == "" {
+			connStr = db.GenerateConnectionString()
+		}
+		if err := client.Connect(ctx, connStr); err != nil {
+			return nil, fmt.Errorf("failed to connect to ClickHouse: %w", err)
+		}
+		return client, nil
 
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", db.Type())
@@ -56,13 +84,29 @@ func (f *ClientFactory) CreateClient(ctx context.Context, db *databases.Database
 
 func (f *ClientFactory) SupportsStudio(dbType databases.DatabaseType) bool {
 	switch dbType {
-	case databases.DatabaseTypePostgreSQL, databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+	case databases.DatabaseTypePostgreSQL, databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB,
+		databases.DatabaseTypeMongoDB, databases.DatabaseTypeClickHouse,
+		databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB, databases.DatabaseTypeDragonfly:
 		return true
 	default:
 		return false
 	}
 }
 
+// DataModel returns how the studio presents the data of a database type
+func (f *ClientFactory) DataModel(dbType databases.DatabaseType) DataModel {
+	switch dbType {
+	case databases.DatabaseTypeMongoDB:
+		return DataModelDocument
+	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB, databases.DatabaseTypeDragonfly:
+		return DataModelKeyValue
+	case databases.DatabaseTypeClickHouse:
+		return DataModelColumnar
+	default:
+		return DataModelRelational
+	}
+}
+
 func ensureSSLMode(connStr string) string {
 	if !strings.Contains(connStr, "sslmode=") {
 		if strings.Contains(connStr, "?") {
@@ -72,3 +116,15 @@ func ensureSSLMode(connStr string) string {
 	}
 	return connStr
 }
+
+// ensureAuthSource adds the database holding the user's credentials, since the
+// root user of a MongoDB container isn't created in the application database
+func ensureAuthSource(connStr, authSource string) string {
+	if strings.Contains(connStr, "authSource=") {
+		return connStr
+	}
+	if strings.Contains(connStr, "?") {
+		return connStr + "&authSource=" + authSource
+	}
+	return connStr + "?authSource=" + authSource
+}
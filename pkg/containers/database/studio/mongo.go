@@ -0,0 +1,673 @@
+package studio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// mongoSchemaSampleSize is how many documents GetTableSchema inspects to infer
+// the fields of a collection
+const mongoSchemaSampleSize = 100
+
+// MongoDBClient maps databases to schemas, collections to tables and
+// documents to rows. Documents are returned as relaxed extended JSON, and
+// values written back are read the same way, so ObjectIDs and dates survive a
+// round trip through the studio.
+type MongoDBClient struct {
+	client   *mongo.Client
+	database string
+}
+
+func NewMongoDBClient() *MongoDBClient {
+	return &MongoDBClient{}
+}
+
+func (c *MongoDBClient) Connect(ctx context.Context, connectionString string) error {
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(connectionString))
+	if err != nil {
+		return fmt.Errorf("failed to open connection: %w", err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	c.client = client
+	c.database = strings.TrimPrefix(u.Path, "/")
+	return nil
+}
+
+func (c *MongoDBClient) Close() error {
+	if c.client != nil {
+		return c.client.Disconnect(context.Background())
+	}
+	return nil
+}
+
+func (c *MongoDBClient) db(schema string) *mongo.Database {
+	if schema == "" {
+		schema = c.database
+	}
+	return c.client.Database(schema)
+}
+
+func (c *MongoDBClient) ListDatabases(ctx context.Context) ([]string, error) {
+	return c.client.ListDatabaseNames(ctx, bson.D{})
+}
+
+func (c *MongoDBClient) ListSchemas(ctx context.Context) ([]string, error) {
+	return c.ListDatabases(ctx)
+}
+
+func (c *MongoDBClient) ListTables(ctx context.Context, schema string) ([]string, error) {
+	names, err := c.db(schema).ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetTableSchema infers the fields of a collection from a sample of its
+// documents. Fields missing from some documents are reported as nullable.
+func (c *MongoDBClient) GetTableSchema(ctx context.Context, tableName, schema string) (*TableSchema, error) {
+	coll := c.db(schema).Collection(tableName)
+
+	cursor, err := coll.Find(ctx, bson.D{}, options.Find().SetLimit(mongoSchemaSampleSize))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var order []string
+	columns := make(map[string]*Column)
+	seen := make(map[string]int)
+	documents := 0
+
+	for cursor.Next(ctx) {
+		documents++
+		elements, err := cursor.Current.Elements()
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range elements {
+			key := element.Key()
+			value := element.Value()
+			seen[key]++
+
+			column, ok := columns[key]
+			if !ok {
+				column = &Column{
+					Name:         key,
+					Type:         mapBSONType(value.Type),
+					NativeType:   value.Type.String(),
+					IsPrimaryKey: key == "_id",
+					IsUnique:     key == "_id",
+				}
+				columns[key] = column
+				order = append(order, key)
+			}
+			if value.Type == bson.TypeNull {
+				column.Nullable = true
+			} else if column.NativeType == bson.TypeNull.String() {
+				column.Type = mapBSONType(value.Type)
+				column.NativeType = value.Type.String()
+				column.Nullable = true
+			} else if column.NativeType != value.Type.String() {
+				column.Type = ColumnTypeUnknown
+				column.NativeType = "mixed"
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	tableSchema := &TableSchema{
+		Name:        tableName,
+		Schema:      c.db(schema).Name(),
+		Type:        "collection",
+		PrimaryKeys: []string{"_id"},
+		ForeignKeys: []ForeignKey{},
+	}
+	for _, key := range order {
+		column := columns[key]
+		if seen[key] < documents {
+			column.Nullable = true
+		}
+		tableSchema.Columns = append(tableSchema.Columns, *column)
+	}
+
+	indexes, err := c.listIndexes(ctx, coll)
+	if err != nil {
+		return nil, err
+	}
+	tableSchema.Indexes = indexes
+
+	if count, err := coll.EstimatedDocumentCount(ctx); err == nil {
+		tableSchema.RowCount = &count
+	}
+
+	return tableSchema, nil
+}
+
+func (c *MongoDBClient) listIndexes(ctx context.Context, coll *mongo.Collection) ([]Index, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []Index
+	for cursor.Next(ctx) {
+		var spec struct {
+			Name   string `bson:"name"`
+			Key    bson.D `bson:"key"`
+			Unique bool   `bson:"unique"`
+		}
+		if err := cursor.Decode(&spec); err != nil {
+			return nil, err
+		}
+
+		index := Index{Name: spec.Name, IsUnique: spec.Unique || spec.Name == "_id_", Type: "btree"}
+		for _, key := range spec.Key {
+			index.Columns = append(index.Columns, key.Key)
+			if kind, ok := key.Value.(string); ok {
+				index.Type = kind
+			}
+		}
+		indexes = append(indexes, index)
+	}
+
+	return indexes, cursor.Err()
+}
+
+func (c *MongoDBClient) GetTableData(ctx context.Context, tableName, schema string, opts TableDataOptions) (*QueryResult, error) {
+	start := time.Now()
+	coll := c.db(schema).Collection(tableName)
+
+	filter, err := buildMongoFilter(opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find()
+	if len(opts.Sorts) > 0 {
+		sortDoc := bson.D{}
+		for _, s := range opts.Sorts {
+			direction := 1
+			if strings.ToUpper(s.Direction) == "DESC" {
+				direction = -1
+			}
+			sortDoc = append(sortDoc, bson.E{Key: s.Column, Value: direction})
+		}
+		findOptions.SetSort(sortDoc)
+	}
+	if opts.Limit > 0 {
+		findOptions.SetLimit(int64(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		findOptions.SetSkip(int64(opts.Offset))
+	}
+
+	cursor, err := coll.Find(ctx, filter, findOptions)
+	if err != nil {
+		return &QueryResult{Error: err.Error()}, err
+	}
+	defer cursor.Close(ctx)
+
+	result, err := mongoCursorResult(ctx, cursor, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	result.ExecutionTime = time.Since(start)
+	result.TotalCount = &totalCount
+	return result, nil
+}
+
+// ExecuteQuery runs a database command written as extended JSON against the
+// default database, e.g. {"find": "users", "filter": {"age": {"$gt": 30}}}.
+// Commands that return a cursor are read up to the limit; the reply of any
+// other command is returned as a single row. In read-only mode only commands
+// that read are accepted, and aggregations can't write with $out or $merge.
+// The timeout is sent as maxTimeMS on the commands that take it and bounds the
+// wait for the others.
+func (c *MongoDBClient) ExecuteQuery(ctx context.Context, query string, opts QueryOptions) (*QueryResult, error) {
+	start := time.Now()
+
+	var command bson.D
+	if err := bson.UnmarshalExtJSON([]byte(query), false, &command); err != nil {
+		err = fmt.Errorf("query must be a command document in extended JSON: %w", err)
+		return &QueryResult{Error: err.Error()}, err
+	}
+	if len(command) == 0 {
+		err := errors.New("command document is empty")
+		return &QueryResult{Error: err.Error()}, err
+	}
+
+	if opts.ReadOnly && !isMongoReadCommand(command) {
+		return &QueryResult{Error: ErrWriteNotAllowed.Error()}, ErrWriteNotAllowed
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+
+		if mongoMaxTimeCommands[command[0].Key] {
+			command = setDefault(command, "maxTimeMS", opts.Timeout.Milliseconds(), true)
+		}
+	}
+
+	db := c.db("")
+
+	switch command[0].Key {
+	case "find", "aggregate", "listCollections", "listIndexes":
+		if command[0].Key == "find" {
+			// One more than the limit tells whether the result was cut off
+			command = setDefault(command, "limit", int64(opts.Limit+1), opts.Limit > 0)
+			command = setDefault(command, "skip", int64(opts.Offset), opts.Offset > 0)
+		}
+		if command[0].Key == "aggregate" {
+			command = setDefault(command, "cursor", bson.D{}, true)
+		}
+
+		cursor, err := db.RunCommandCursor(ctx, command)
+		if err != nil {
+			return &QueryResult{Error: err.Error()}, err
+		}
+		defer cursor.Close(ctx)
+
+		result, err := mongoCursorResult(ctx, cursor, opts.Limit, opts.MaxBytes)
+		if err != nil {
+			return &QueryResult{Error: err.Error()}, err
+		}
+		result.ExecutionTime = time.Since(start)
+		return result, nil
+
+	default:
+		raw, err := db.RunCommand(ctx, command).Raw()
+		if err != nil {
+			return &QueryResult{Error: err.Error()}, err
+		}
+
+		row, columns, err := mongoDocumentRow(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		var affected int64
+		if n, ok := row["n"].(float64); ok {
+			affected = int64(n)
+		}
+
+		return &QueryResult{
+			Columns:       columns,
+			Rows:          []map[string]any{row},
+			RowsAffected:  affected,
+			ExecutionTime: time.Since(start),
+		}, nil
+	}
+}
+
+func (c *MongoDBClient) InsertRow(ctx context.Context, tableName, schema string, data map[string]any) error {
+	document, err := toBSONDocument(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db(schema).Collection(tableName).InsertOne(ctx, document)
+	return err
+}
+
+func (c *MongoDBClient) UpdateRow(ctx context.Context, tableName, schema string, primaryKey map[string]any, data map[string]any) error {
+	filter, err := mongoKeyFilter(primaryKey)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]any, len(data))
+	for key, value := range data {
+		if key != "_id" {
+			fields[key] = value
+		}
+	}
+	set, err := toBSONDocument(fields)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.db(schema).Collection(tableName).UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("document not found")
+	}
+	return nil
+}
+
+func (c *MongoDBClient) DeleteRow(ctx context.Context, tableName, schema string, primaryKey map[string]any) error {
+	filter, err := mongoKeyFilter(primaryKey)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.db(schema).Collection(tableName).DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("document not found")
+	}
+	return nil
+}
+
+func (c *MongoDBClient) GetDatabaseVersion(ctx context.Context) (string, error) {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	err := c.client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info)
+	return info.Version, err
+}
+
+func (c *MongoDBClient) GetDatabaseSize(ctx context.Context) (int64, error) {
+	var stats struct {
+		StorageSize float64 `bson:"storageSize"`
+		IndexSize   float64 `bson:"indexSize"`
+	}
+	err := c.db("").RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats)
+	return int64(stats.StorageSize + stats.IndexSize), err
+}
+
+func mapBSONType(t bson.Type) ColumnType {
+	switch t {
+	case bson.TypeString, bson.TypeObjectID, bson.TypeSymbol:
+		return ColumnTypeString
+	case bson.TypeInt32, bson.TypeInt64:
+		return ColumnTypeInteger
+	case bson.TypeDouble, bson.TypeDecimal128:
+		return ColumnTypeFloat
+	case bson.TypeBoolean:
+		return ColumnTypeBoolean
+	case bson.TypeDateTime:
+		return ColumnTypeDateTime
+	case bson.TypeTimestamp:
+		return ColumnTypeTimestamp
+	case bson.TypeEmbeddedDocument:
+		return ColumnTypeJSON
+	case bson.TypeArray:
+		return ColumnTypeArray
+	case bson.TypeBinary:
+		return ColumnTypeBinary
+	default:
+		return ColumnTypeUnknown
+	}
+}
+
+// mongoCursorResult reads the documents of a cursor into rows, stopping once
+// limit documents or maxBytes of BSON have been read when they are positive
+// and marking the result truncated if more were left. Columns are listed in
+// the order they first appear.
+func mongoCursorResult(ctx context.Context, cursor *mongo.Cursor, limit int, maxBytes int64) (*QueryResult, error) {
+	result := &QueryResult{Columns: []string{}, Rows: []map[string]any{}}
+	known := make(map[string]bool)
+	var size int64
+
+	for cursor.Next(ctx) {
+		size += int64(len(cursor.Current))
+		if (limit > 0 && len(result.Rows) >= limit) || (maxBytes > 0 && size > maxBytes) {
+			result.Truncated = true
+			break
+		}
+
+		row, columns, err := mongoDocumentRow(cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range columns {
+			if !known[column] {
+				known[column] = true
+				result.Columns = append(result.Columns, column)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	result.RowsAffected = int64(len(result.Rows))
+	return result, cursor.Err()
+}
+
+// mongoReadCommands are the commands read-only mode lets through
+var mongoReadCommands = map[string]bool{
+	"find":             true,
+	"aggregate":        true,
+	"count":            true,
+	"distinct":         true,
+	"listCollections":  true,
+	"listIndexes":      true,
+	"collStats":        true,
+	"dbStats":          true,
+	"buildInfo":        true,
+	"serverStatus":     true,
+	"hostInfo":         true,
+	"connectionStatus": true,
+	"ping":             true,
+	"explain":          true,
+}
+
+// mongoMaxTimeCommands accept a maxTimeMS field
+var mongoMaxTimeCommands = map[string]bool{
+	"find":      true,
+	"aggregate": true,
+	"count":     true,
+	"distinct":  true,
+}
+
+// isMongoReadCommand tells whether a command only reads. Aggregations and
+// explained aggregations may not end in a $out or $merge stage.
+func isMongoReadCommand(command bson.D) bool {
+	name := command[0].Key
+	if !mongoReadCommands[name] {
+		return false
+	}
+
+	if name == "explain" {
+		inner, ok := command[0].Value.(bson.D)
+		return ok && len(inner) > 0 && inner[0].Key != "explain" && isMongoReadCommand(inner)
+	}
+
+	if name == "aggregate" {
+		for _, element := range command {
+			if element.Key != "pipeline" {
+				continue
+			}
+			stages, ok := element.Value.(bson.A)
+			if !ok {
+				return false
+			}
+			for _, stage := range stages {
+				stage, ok := stage.(bson.D)
+				if !ok {
+					return false
+				}
+				for _, operator := range stage {
+					if operator.Key == "$out" || operator.Key == "$merge" {
+						return false
+					}
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// mongoDocumentRow converts a document to a row of relaxed extended JSON values
+func mongoDocumentRow(document bson.Raw) (map[string]any, []string, error) {
+	elements, err := document.Elements()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := bson.MarshalExtJSON(document, false, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	row := make(map[string]any, len(elements))
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, nil, err
+	}
+
+	columns := make([]string, len(elements))
+	for i, element := range elements {
+		columns[i] = element.Key()
+	}
+	return row, columns, nil
+}
+
+// toBSONDocument reads a JSON object as relaxed extended JSON, so values such
+// as {"$oid": "..."} and {"$date": "..."} become their BSON types
+func toBSONDocument(data map[string]any) (bson.D, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var document bson.D
+	if err := bson.UnmarshalExtJSON(encoded, false, &document); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	return document, nil
+}
+
+// toBSONValue converts a single JSON value like toBSONDocument. A 24 digit hex
+// string compared against _id is taken to be an ObjectID.
+func toBSONValue(column string, value any) (any, error) {
+	if s, ok := value.(string); ok && column == "_id" {
+		if id, err := bson.ObjectIDFromHex(s); err == nil {
+			return id, nil
+		}
+	}
+
+	document, err := toBSONDocument(map[string]any{"v": value})
+	if err != nil {
+		return nil, err
+	}
+	return document[0].Value, nil
+}
+
+func mongoKeyFilter(primaryKey map[string]any) (bson.D, error) {
+	if len(primaryKey) == 0 {
+		return nil, errors.New("primary key is required")
+	}
+
+	filter := bson.D{}
+	for column, value := range primaryKey {
+		converted, err := toBSONValue(column, value)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, bson.E{Key: column, Value: converted})
+	}
+	return filter, nil
+}
+
+func buildMongoFilter(filters []Filter) (bson.D, error) {
+	var clauses bson.A
+	for _, filter := range filters {
+		clause, err := buildMongoClause(filter)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	switch len(clauses) {
+	case 0:
+		return bson.D{}, nil
+	case 1:
+		return clauses[0].(bson.D), nil
+	default:
+		return bson.D{{Key: "$and", Value: clauses}}, nil
+	}
+}
+
+func buildMongoClause(filter Filter) (bson.D, error) {
+	match := func(value any) bson.D {
+		return bson.D{{Key: filter.Column, Value: value}}
+	}
+	pattern := func(expr string) bson.D {
+		return match(bson.D{{Key: "$regex", Value: expr}, {Key: "$options", Value: "i"}})
+	}
+	text := regexp.QuoteMeta(fmt.Sprintf("%v", filter.Value))
+
+	switch filter.Operator {
+	case "like", "contains":
+		return pattern(text), nil
+	case "starts_with":
+		return pattern("^" + text), nil
+	case "ends_with":
+		return pattern(text + "$"), nil
+	case "is_null":
+		return match(nil), nil
+	case "is_not_null":
+		return match(bson.D{{Key: "$ne", Value: nil}}), nil
+	}
+
+	value, err := toBSONValue(filter.Column, filter.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filter.Operator {
+	case "!=", "not_equals":
+		return match(bson.D{{Key: "$ne", Value: value}}), nil
+	case ">", "greater_than":
+		return match(bson.D{{Key: "$gt", Value: value}}), nil
+	case ">=", "greater_or_equal":
+		return match(bson.D{{Key: "$gte", Value: value}}), nil
+	case "<", "less_than":
+		return match(bson.D{{Key: "$lt", Value: value}}), nil
+	case "<=", "less_or_equal":
+		return match(bson.D{{Key: "$lte", Value: value}}), nil
+	default:
+		return match(value), nil
+	}
+}
+
+// setDefault adds key to a command unless it is already set or ok is false
+func setDefault(command bson.D, key string, value any, ok bool) bson.D {
+	if !ok {
+		return command
+	}
+	for _, element := range command {
+		if element.Key == key {
+			return command
+		}
+	}
+	return append(command, bson.E{Key: key, Value: value})
+}
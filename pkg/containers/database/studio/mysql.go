@@ -3,6 +3,7 @@ package studio
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -334,72 +335,139 @@ func (c *MySQLClient) GetTableData(ctx context.Context, tableName, schema string
 	}, rows.Err()
 }
 
+// mysqlReadKeywords lead the statements read-only mode runs. A read-only
+// transaction only stops writes to tables: DDL commits it implicitly and runs
+// anyway.
+var mysqlReadKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"TABLE":    true,
+	"VALUES":   true,
+	"SHOW":     true,
+	"EXPLAIN":  true,
+	"DESCRIBE": true,
+	"DESC":     true,
+}
+
+// isMySQLReadStatement tells whether a statement only reads, SELECT ... INTO
+// OUTFILE writes to the server's disk
+func isMySQLReadStatement(query string) bool {
+	stripped := mysqlDialect.strip(query)
+	if !mysqlReadKeywords[leadingKeyword(stripped)] {
+		return false
+	}
+	return !containsKeyword(stripped, "OUTFILE") && !containsKeyword(stripped, "DUMPFILE")
+}
+
+// ExecuteQuery runs a statement in its own transaction, read-only unless
+// opts allow writes. The driver refuses multiple statements. Once the timeout
+// passes or ctx is cancelled the statement is killed on the server, which
+// unlike max_execution_time covers writes as well.
 func (c *MySQLClient) ExecuteQuery(ctx context.Context, query string, opts QueryOptions) (*QueryResult, error) {
 	start := time.Now()
 
-	trimmedQuery := strings.TrimSpace(strings.ToUpper(query))
-	isSelect := strings.HasPrefix(trimmedQuery, "SELECT") || strings.HasPrefix(trimmedQuery, "SHOW") || strings.HasPrefix(trimmedQuery, "DESCRIBE")
+	if opts.ReadOnly && !isMySQLReadStatement(query) {
+		return &QueryResult{Error: ErrWriteNotAllowed.Error()}, ErrWriteNotAllowed
+	}
 
-	if isSelect {
-		hasLimit := strings.Contains(trimmedQuery, "LIMIT")
-		hasOffset := strings.Contains(trimmedQuery, "OFFSET")
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
 
-		if opts.Limit > 0 && !hasLimit {
-			query = fmt.Sprintf("%s LIMIT %d", query, opts.Limit)
-		}
-		if opts.Offset > 0 && !hasOffset {
-			query = fmt.Sprintf("%s OFFSET %d", query, opts.Offset)
+	tx, stop, err := c.begin(ctx, opts.ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		stop()
+		_ = tx.Rollback()
+	}()
+
+	result, err := runSQLStatement(ctx, tx, mysqlDialect, query, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("query cancelled: %w", ctx.Err())
 		}
+		return &QueryResult{Error: err.Error()}, err
+	}
 
-		rows, err := c.db.QueryContext(ctx, query)
-		if err != nil {
+	if !opts.ReadOnly {
+		if err := tx.Commit(); err != nil {
 			return &QueryResult{Error: err.Error()}, err
 		}
-		defer rows.Close()
+	}
 
-		columns, err := rows.Columns()
-		if err != nil {
-			return nil, err
-		}
+	result.ExecutionTime = time.Since(start)
+	return result, nil
+}
 
-		var resultRows []map[string]any
-		for rows.Next() {
-			values := make([]any, len(columns))
-			valuePtrs := make([]any, len(columns))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
+// ExportQuery streams the rows of a read-only query to w
+func (c *MySQLClient) ExportQuery(ctx context.Context, query string, timeout time.Duration, w RowWriter) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return nil, err
-			}
+	if !isMySQLReadStatement(query) {
+		return ErrWriteNotAllowed
+	}
 
-			row := make(map[string]any)
-			for i, col := range columns {
-				row[col] = values[i]
-			}
-			resultRows = append(resultRows, row)
-		}
+	tx, stop, err := c.begin(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		stop()
+		_ = tx.Rollback()
+	}()
 
-		return &QueryResult{
-			Columns:       columns,
-			Rows:          resultRows,
-			RowsAffected:  int64(len(resultRows)),
-			ExecutionTime: time.Since(start),
-		}, rows.Err()
-	} else {
-		result, err := c.db.ExecContext(ctx, query)
-		if err != nil {
-			return &QueryResult{Error: err.Error()}, err
+	if err := exportRows(ctx, tx, query, w); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("query cancelled: %w", ctx.Err())
 		}
+		return err
+	}
+	return nil
+}
 
-		rowsAffected, _ := result.RowsAffected()
+func (c *MySQLClient) ExportTable(ctx context.Context, tableName, schema string, w RowWriter) error {
+	query := "SELECT * FROM " + mysqlDialect.quoteIdentifier(tableName)
+	if schema != "" {
+		query = fmt.Sprintf("SELECT * FROM %s.%s", mysqlDialect.quoteIdentifier(schema), mysqlDialect.quoteIdentifier(tableName))
+	}
+	return c.ExportQuery(ctx, query, 0, w)
+}
 
-		return &QueryResult{
-			RowsAffected:  rowsAffected,
-			ExecutionTime: time.Since(start),
-		}, nil
+// begin starts the transaction a studio query runs in and arranges for its
+// statement to be killed once ctx is done. The returned func undoes that and
+// must be called before the transaction ends.
+func (c *MySQLClient) begin(ctx context.Context, readOnly bool) (*sql.Tx, func() bool, error) {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+
+	var connectionID int64
+	if err := tx.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connectionID); err != nil {
+		_ = tx.Rollback()
+		return nil, nil, fmt.Errorf("failed to get connection id: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		c.killQuery(connectionID)
+	})
+
+	return tx, stop, nil
+}
+
+// killQuery stops the statement running on a connection from another one,
+// since closing the client side leaves it running on the server
+func (c *MySQLClient) killQuery(connectionID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = c.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connectionID))
 }
 
 func (c *MySQLClient) InsertRow(ctx context.Context, tableName, schema string, data map[string]any) error {
@@ -564,3 +632,63 @@ func buildMySQLWhereClause(filter Filter) (string, any) {
 		return fmt.Sprintf("`%s` = ?", filter.Column), filter.Value
 	}
 }
+
+// PlanSchemaChange returns the statements of a change. Altering a column
+// rewrites its whole definition, so its auto increment, ON UPDATE, collation
+// and comment are read first and kept.
+func (c *MySQLClient) PlanSchemaChange(ctx context.Context, change SchemaChange) ([]string, error) {
+	if change.Kind != SchemaChangeAlterColumn {
+		return mysqlSchemaChange(change, nil)
+	}
+
+	current, err := c.columnAttributes(ctx, change.Schema, change.Table, change.Name)
+	if err != nil {
+		return nil, err
+	}
+	return mysqlSchemaChange(change, current)
+}
+
+// columnAttributes reads the attributes of a column that MODIFY COLUMN would
+// drop, nil when the column doesn't exist
+func (c *MySQLClient) columnAttributes(ctx context.Context, schema, table, column string) (*mysqlColumnAttributes, error) {
+	query := `
+		SELECT EXTRA, COALESCE(COLLATION_NAME, ''), COLUMN_COMMENT
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? AND COLUMN_NAME = ?
+	`
+
+	var extra string
+	attributes := &mysqlColumnAttributes{}
+	err := c.db.QueryRowContext(ctx, query, schema, table, column).Scan(&extra, &attributes.Collation, &attributes.Comment)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column %s: %w", column, err)
+	}
+
+	// EXTRA lists e.g. "auto_increment" or "DEFAULT_GENERATED on update CURRENT_TIMESTAMP(3)"
+	lower := strings.ToLower(extra)
+	attributes.AutoIncrement = strings.Contains(lower, "auto_increment")
+	if i := strings.Index(lower, "on update "); i >= 0 {
+		attributes.OnUpdate = strings.TrimSpace(extra[i+len("on update "):])
+	}
+	return attributes, nil
+}
+
+// ApplySchemaChange runs the statements of a change. MySQL commits DDL
+// implicitly, so statements can't be grouped in a transaction; every change
+// is built as a single statement instead.
+func (c *MySQLClient) ApplySchemaChange(ctx context.Context, change SchemaChange) ([]string, error) {
+	statements, err := c.PlanSchemaChange(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range statements {
+		if _, err := c.db.ExecContext(ctx, statement); err != nil {
+			return statements, err
+		}
+	}
+	return statements, nil
+}
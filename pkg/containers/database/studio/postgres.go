@@ -370,72 +370,80 @@ func (c *PostgreSQLClient) GetTableData(ctx context.Context, tableName, schema s
 	}, rows.Err()
 }
 
+// ExecuteQuery runs a statement in its own transaction, read-only unless
+// opts allow writes, with the timeout applied as the statement_timeout
 func (c *PostgreSQLClient) ExecuteQuery(ctx context.Context, query string, opts QueryOptions) (*QueryResult, error) {
 	start := time.Now()
 
-	trimmedQuery := strings.TrimSpace(strings.ToUpper(query))
-	isSelect := strings.HasPrefix(trimmedQuery, "SELECT")
+	// A second statement could end the read-only transaction and run outside it
+	if opts.ReadOnly && postgresDialect.hasMultipleStatements(query) {
+		return &QueryResult{Error: ErrMultipleStatements.Error()}, ErrMultipleStatements
+	}
 
-	if isSelect {
-		hasLimit := strings.Contains(trimmedQuery, "LIMIT")
-		hasOffset := strings.Contains(trimmedQuery, "OFFSET")
+	tx, err := c.begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-		if opts.Limit > 0 && !hasLimit {
-			query = fmt.Sprintf("%s LIMIT %d", query, opts.Limit)
-		}
-		if opts.Offset > 0 && !hasOffset {
-			query = fmt.Sprintf("%s OFFSET %d", query, opts.Offset)
-		}
+	result, err := runSQLStatement(ctx, tx, postgresDialect, query, opts)
+	if err != nil {
+		return &QueryResult{Error: err.Error()}, err
+	}
 
-		rows, err := c.db.QueryContext(ctx, query)
-		if err != nil {
+	if !opts.ReadOnly {
+		if err := tx.Commit(); err != nil {
 			return &QueryResult{Error: err.Error()}, err
 		}
-		defer rows.Close()
+	}
 
-		columns, err := rows.Columns()
-		if err != nil {
-			return nil, err
-		}
+	result.ExecutionTime = time.Since(start)
+	return result, nil
+}
 
-		var resultRows []map[string]any
-		for rows.Next() {
-			values := make([]any, len(columns))
-			valuePtrs := make([]any, len(columns))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
+// ExportQuery streams the rows of a read-only query to w
+func (c *PostgreSQLClient) ExportQuery(ctx context.Context, query string, timeout time.Duration, w RowWriter) error {
+	if postgresDialect.hasMultipleStatements(query) {
+		return ErrMultipleStatements
+	}
 
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return nil, err
-			}
+	tx, err := c.begin(ctx, QueryOptions{ReadOnly: true, Timeout: timeout})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-			row := make(map[string]any)
-			for i, col := range columns {
-				row[col] = values[i]
-			}
-			resultRows = append(resultRows, row)
-		}
+	return exportRows(ctx, tx, query, w)
+}
 
-		return &QueryResult{
-			Columns:       columns,
-			Rows:          resultRows,
-			RowsAffected:  int64(len(resultRows)),
-			ExecutionTime: time.Since(start),
-		}, rows.Err()
-	} else {
-		result, err := c.db.ExecContext(ctx, query)
-		if err != nil {
-			return &QueryResult{Error: err.Error()}, err
-		}
+func (c *PostgreSQLClient) ExportTable(ctx context.Context, tableName, schema string, w RowWriter) error {
+	if schema == "" {
+		schema = "public"
+	}
+	query := fmt.Sprintf("SELECT * FROM %s.%s", postgresDialect.quoteIdentifier(schema), postgresDialect.quoteIdentifier(tableName))
+	return c.ExportQuery(ctx, query, 0, w)
+}
 
-		rowsAffected, _ := result.RowsAffected()
+// begin starts the transaction a studio query runs in, with the timeout
+// applied as its statement_timeout
+func (c *PostgreSQLClient) begin(ctx context.Context, opts QueryOptions) (*sql.Tx, error) {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
 
-		return &QueryResult{
-			RowsAffected:  rowsAffected,
-			ExecutionTime: time.Since(start),
-		}, nil
+	if opts.Timeout > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", opts.Timeout.Milliseconds())); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("failed to set statement timeout: %w", err)
+		}
 	}
+
+	return tx, nil
 }
 
 func (c *PostgreSQLClient) InsertRow(ctx context.Context, tableName, schema string, data map[string]any) error {
@@ -594,3 +602,37 @@ func buildWhereClause(filter Filter, argIndex int) (string, any) {
 		return fmt.Sprintf("%s = %s", filter.Column, placeholder), filter.Value
 	}
 }
+
+// PlanSchemaChange returns the statements of a change, they don't depend on
+// the current schema
+func (c *PostgreSQLClient) PlanSchemaChange(ctx context.Context, change SchemaChange) ([]string, error) {
+	return postgresSchemaChange(change)
+}
+
+// ApplySchemaChange runs the statements of a change in a transaction, which
+// PostgreSQL allows for DDL
+func (c *PostgreSQLClient) ApplySchemaChange(ctx context.Context, change SchemaChange) ([]string, error) {
+	statements, err := postgresSchemaChange(change)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return statements, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return statements, fmt.Errorf("failed to commit schema change: %w", err)
+	}
+	return statements, nil
+}
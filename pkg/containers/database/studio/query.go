@@ -0,0 +1,269 @@
+package studio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrMultipleStatements = errors.New("read-only mode runs a single statement at a time")
+	ErrWriteNotAllowed    = errors.New("the query writes data; enable write mode to run it")
+)
+
+// rowKeywords are the leading keywords of SQL statements that return rows
+var rowKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"SHOW":     true,
+	"EXPLAIN":  true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXISTS":   true,
+}
+
+// sqlDialect holds the lexical rules that decide where comments, literals
+// and statements end, and how identifiers and binary values are written
+type sqlDialect struct {
+	hashComments     bool   // # starts a comment running to the end of the line
+	dollarQuotes     bool   // $tag$ ... $tag$ quotes a string
+	backslashEscapes bool   // \ escapes the next character in any string literal
+	identifierQuote  byte   // quotes table and column names
+	binaryLiteral    string // format of a hex encoded binary value
+}
+
+var (
+	postgresDialect = sqlDialect{
+		dollarQuotes:    true,
+		identifierQuote: '"',
+		binaryLiteral:   `'\x%s'`,
+	}
+	mysqlDialect = sqlDialect{
+		hashComments:     true,
+		backslashEscapes: true,
+		identifierQuote:  '`',
+		binaryLiteral:    `X'%s'`,
+	}
+	clickHouseDialect = sqlDialect{
+		hashComments:     true,
+		dollarQuotes:     true,
+		backslashEscapes: true,
+		identifierQuote:  '`',
+		binaryLiteral:    `unhex('%s')`,
+	}
+)
+
+// returnsRows tells whether a statement produces a result set, looking past
+// comments and parentheses to its first keyword. Writes with a RETURNING
+// clause return rows too.
+func (d sqlDialect) returnsRows(query string) bool {
+	stripped := d.strip(query)
+	if rowKeywords[leadingKeyword(stripped)] {
+		return true
+	}
+	return containsKeyword(stripped, "RETURNING")
+}
+
+// hasMultipleStatements reports whether a query holds more than one
+// statement separated by semicolons
+func (d sqlDialect) hasMultipleStatements(query string) bool {
+	statements := 0
+	for _, part := range strings.Split(d.strip(query), ";") {
+		if strings.TrimSpace(part) != "" {
+			statements++
+		}
+	}
+	return statements > 1
+}
+
+// strip removes comments and empties string literals and quoted identifiers,
+// so that keywords and semicolons left belong to the statement itself
+func (d sqlDialect) strip(query string) string {
+	var out strings.Builder
+	for i := 0; i < len(query); i++ {
+		switch ch := query[i]; {
+		case ch == '-' && strings.HasPrefix(query[i:], "--"), ch == '#' && d.hashComments:
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				return out.String()
+			}
+			i += end
+			out.WriteByte('\n')
+
+		case ch == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				return out.String()
+			}
+			i += end + 3
+			out.WriteByte(' ')
+
+		case ch == '\'' || ch == '"' || ch == '`':
+			// PostgreSQL only honours backslashes in E'...' strings
+			escapes := ch == '\'' && (d.backslashEscapes || i > 0 && (query[i-1] == 'E' || query[i-1] == 'e'))
+			end := i + 1
+			for end < len(query) && query[end] != ch {
+				if escapes && query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			out.WriteByte(ch)
+			out.WriteByte(ch)
+			i = end
+
+		case ch == '$' && d.dollarQuotes:
+			tagEnd := strings.IndexByte(query[i+1:], '$')
+			if tagEnd == -1 || strings.ContainsFunc(query[i+1:i+1+tagEnd], func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+			}) {
+				out.WriteByte(ch)
+				continue
+			}
+			tag := query[i : i+tagEnd+2]
+			end := strings.Index(query[i+len(tag):], tag)
+			if end == -1 {
+				return out.String()
+			}
+			i += len(tag) + end + len(tag) - 1
+			out.WriteString("''")
+
+		default:
+			out.WriteByte(ch)
+		}
+	}
+	return out.String()
+}
+
+// leadingKeyword returns the first word of a statement in upper case
+func leadingKeyword(query string) string {
+	query = strings.TrimLeftFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '('
+	})
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	})
+	if end == -1 {
+		end = len(query)
+	}
+	return strings.ToUpper(query[:end])
+}
+
+func containsKeyword(query, keyword string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToUpper(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	}) {
+		if word == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// collectRows reads a result set within the limits of opts: the first
+// opts.Offset rows are skipped and reading stops after opts.Limit rows or
+// once the values read exceed opts.MaxBytes, marking the result truncated
+func collectRows(rows *sql.Rows, opts QueryOptions) (*QueryResult, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Columns: columns, Rows: []map[string]any{}}
+	var skipped int
+	var size int64
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		valuePtrs := make([]any, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+
+		if skipped < opts.Offset {
+			skipped++
+			continue
+		}
+
+		if opts.Limit > 0 && len(result.Rows) >= opts.Limit {
+			result.Truncated = true
+			break
+		}
+
+		row := make(map[string]any)
+		for i, col := range columns {
+			row[col] = values[i]
+			size += valueSize(values[i])
+		}
+		if opts.MaxBytes > 0 && size > opts.MaxBytes {
+			result.Truncated = true
+			break
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	result.RowsAffected = int64(len(result.Rows))
+	return result, rows.Err()
+}
+
+// valueSize approximates the bytes a value takes in a result
+func valueSize(value any) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	case fmt.Stringer:
+		return int64(len(v.String()))
+	default:
+		return 8
+	}
+}
+
+// sqlRunner is a *sql.DB or *sql.Tx
+type sqlRunner interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// runSQLStatement runs a statement and reads its rows, if it returns any,
+// within the caps of opts. A read-only result that hits a cap is cancelled
+// rather than read to the end, so a transaction it ran in can only be rolled
+// back afterwards.
+func runSQLStatement(ctx context.Context, tx sqlRunner, dialect sqlDialect, query string, opts QueryOptions) (*QueryResult, error) {
+	if !dialect.returnsRows(query) {
+		result, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		affected, _ := result.RowsAffected()
+		return &QueryResult{RowsAffected: affected}, nil
+	}
+
+	queryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows, err := tx.QueryContext(queryCtx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := collectRows(rows, opts)
+	if err == nil && result.Truncated && opts.ReadOnly {
+		cancel()
+	}
+	_ = rows.Close()
+
+	return result, err
+}
@@ -0,0 +1,926 @@
+package studio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisKeysTable is the single table of a Redis-family schema; its rows
+	// are the keys of the logical database
+	redisKeysTable = "keys"
+
+	// redisMaxScanKeys caps how many matching keys GetTableData collects to
+	// sort and paginate, exports scan them all
+	redisMaxScanKeys = 10000
+
+	// redisMaxElements caps the elements GetKey returns for collection types
+	redisMaxElements = 1000
+)
+
+var ErrKeyNotFound = errors.New("key not found")
+
+// RedisClient serves Redis, KeyDB and Dragonfly. Logical databases are the
+// schemas, named db0, db1 and so on, and each has one table of keys with
+// their type, TTL and size. Values are read per key through GetKey.
+type RedisClient struct {
+	options *redis.Options
+	client  *redis.Client
+	others  map[int]*redis.Client
+}
+
+func NewRedisClient() *RedisClient {
+	return &RedisClient{others: make(map[int]*redis.Client)}
+}
+
+func (c *RedisClient) Connect(ctx context.Context, connectionString string) error {
+	opts, err := redis.ParseURL(connectionString)
+	if err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	c.options = opts
+	c.client = client
+	return nil
+}
+
+func (c *RedisClient) Close() error {
+	for _, other := range c.others {
+		_ = other.Close()
+	}
+	if c.client != nil {
+		return c.client.Close()
+	}
+	return nil
+}
+
+// db returns a client for a schema such as "db2", the connected database if
+// schema is empty
+func (c *RedisClient) db(schema string) (*redis.Client, error) {
+	if schema == "" {
+		return c.client, nil
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(schema, "db"))
+	if err != nil || index < 0 {
+		return nil, fmt.Errorf("invalid schema %q, expected db0, db1, ...", schema)
+	}
+	if index == c.options.DB {
+		return c.client, nil
+	}
+
+	if other, ok := c.others[index]; ok {
+		return other, nil
+	}
+	opts := *c.options
+	opts.DB = index
+	other := redis.NewClient(&opts)
+	c.others[index] = other
+	return other, nil
+}
+
+// ListDatabases returns the logical databases that hold keys, plus the one
+// connected to
+func (c *RedisClient) ListDatabases(ctx context.Context) ([]string, error) {
+	info, err := c.client.Info(ctx, "keyspace").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := map[int]bool{c.options.DB: true}
+	for _, line := range strings.Split(info, "\n") {
+		name, _, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || !strings.HasPrefix(name, "db") {
+			continue
+		}
+		if index, err := strconv.Atoi(strings.TrimPrefix(name, "db")); err == nil {
+			indexes[index] = true
+		}
+	}
+
+	sorted := make([]int, 0, len(indexes))
+	for index := range indexes {
+		sorted = append(sorted, index)
+	}
+	sort.Ints(sorted)
+
+	names := make([]string, len(sorted))
+	for i, index := range sorted {
+		names[i] = "db" + strconv.Itoa(index)
+	}
+	return names, nil
+}
+
+func (c *RedisClient) ListSchemas(ctx context.Context) ([]string, error) {
+	return c.ListDatabases(ctx)
+}
+
+func (c *RedisClient) ListTables(ctx context.Context, schema string) ([]string, error) {
+	if _, err := c.db(schema); err != nil {
+		return nil, err
+	}
+	return []string{redisKeysTable}, nil
+}
+
+func (c *RedisClient) GetTableSchema(ctx context.Context, tableName, schema string) (*TableSchema, error) {
+	client, err := c.keysTable(tableName, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := client.DBSize(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TableSchema{
+		Name:   redisKeysTable,
+		Schema: schema,
+		Type:   "keyspace",
+		Columns: []Column{
+			{Name: "key", Type: ColumnTypeString, NativeType: "string", IsPrimaryKey: true, IsUnique: true},
+			{Name: "type", Type: ColumnTypeString, NativeType: "string"},
+			{Name: "ttl", Type: ColumnTypeInteger, NativeType: "integer"},
+			{Name: "size", Type: ColumnTypeInteger, NativeType: "integer"},
+		},
+		PrimaryKeys: []string{"key"},
+		ForeignKeys: []ForeignKey{},
+		Indexes:     []Index{},
+		RowCount:    &count,
+	}, nil
+}
+
+// GetTableData lists keys with their type, TTL and size. Filters on the key
+// column become a SCAN pattern and filters on the type are applied to the
+// matches. Up to redisMaxScanKeys matches are sorted by key and paginated;
+// the total count is only reported when the scan saw every key.
+func (c *RedisClient) GetTableData(ctx context.Context, tableName, schema string, opts TableDataOptions) (*QueryResult, error) {
+	client, err := c.keysTable(tableName, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	match := "*"
+	var typeFilter string
+	for _, filter := range opts.Filters {
+		value := fmt.Sprintf("%v", filter.Value)
+		switch filter.Column {
+		case "key":
+			match = redisKeyPattern(filter.Operator, value)
+		case "type":
+			typeFilter = value
+		default:
+			return nil, fmt.Errorf("keys can only be filtered by key or type")
+		}
+	}
+
+	var keys []string
+	var cursor uint64
+	complete := false
+	for len(keys) < redisMaxScanKeys {
+		var batch []string
+		batch, cursor, err = client.Scan(ctx, cursor, match, 500).Result()
+		if err != nil {
+			return &QueryResult{Error: err.Error()}, err
+		}
+		keys = append(keys, batch...)
+		if cursor == 0 {
+			complete = true
+			break
+		}
+	}
+
+	rows, err := c.describeKeys(ctx, client, keys)
+	if err != nil {
+		return nil, err
+	}
+	if typeFilter != "" {
+		filtered := rows[:0]
+		for _, row := range rows {
+			if row["type"] == typeFilter {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	}
+
+	descending := len(opts.Sorts) > 0 && strings.ToUpper(opts.Sorts[0].Direction) == "DESC"
+	sort.Slice(rows, func(i, j int) bool {
+		if descending {
+			return rows[i]["key"].(string) > rows[j]["key"].(string)
+		}
+		return rows[i]["key"].(string) < rows[j]["key"].(string)
+	})
+
+	total := int64(len(rows))
+	rows = rows[min(opts.Offset, len(rows)):]
+	if opts.Limit > 0 && len(rows) > opts.Limit {
+		rows = rows[:opts.Limit]
+	}
+
+	result := &QueryResult{
+		Columns:       []string{"key", "type", "ttl", "size"},
+		Rows:          rows,
+		RowsAffected:  int64(len(rows)),
+		ExecutionTime: time.Since(start),
+	}
+	if complete {
+		result.TotalCount = &total
+	}
+	return result, nil
+}
+
+// ExportTable writes every key of the logical database. Unlike GetTableData
+// it follows the SCAN cursor to the end, so the keys come in scan order
+// rather than sorted.
+func (c *RedisClient) ExportTable(ctx context.Context, tableName, schema string, w RowWriter) error {
+	client, err := c.keysTable(tableName, schema)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"key", "type", "ttl", "size"}
+	if err := w.WriteHeader(columns); err != nil {
+		return err
+	}
+
+	// A key may be returned more than once while the keyspace is rehashed
+	seen := make(map[string]bool)
+	var cursor uint64
+	for {
+		var batch []string
+		batch, cursor, err = client.Scan(ctx, cursor, "*", 500).Result()
+		if err != nil {
+			return err
+		}
+
+		keys := batch[:0]
+		for _, key := range batch {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+
+		rows, err := c.describeKeys(ctx, client, keys)
+		if err != nil {
+			return err
+		}
+		if err := WriteResultRows(&QueryResult{Columns: columns, Rows: rows}, w); err != nil {
+			return err
+		}
+
+		if cursor == 0 {
+			break
+		}
+	}
+	return w.Close()
+}
+
+// ExportQuery writes the reply of a read-only command, in full
+func (c *RedisClient) ExportQuery(ctx context.Context, query string, timeout time.Duration, w RowWriter) error {
+	result, err := c.ExecuteQuery(ctx, query, QueryOptions{ReadOnly: true, Timeout: timeout})
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(result.Columns); err != nil {
+		return err
+	}
+	if err := WriteResultRows(result, w); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// describeKeys looks up the type, TTL and size of keys in two round trips
+func (c *RedisClient) describeKeys(ctx context.Context, client *redis.Client, keys []string) ([]map[string]any, error) {
+	if len(keys) == 0 {
+		return []map[string]any{}, nil
+	}
+
+	pipe := client.Pipeline()
+	types := make([]*redis.StatusCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		types[i] = pipe.Type(ctx, key)
+		ttls[i] = pipe.TTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	pipe = client.Pipeline()
+	sizes := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		sizes[i] = redisSizeCmd(ctx, pipe, types[i].Val(), key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	rows := make([]map[string]any, 0, len(keys))
+	for i, key := range keys {
+		keyType := types[i].Val()
+		if keyType == "none" {
+			// Expired or deleted since the scan
+			continue
+		}
+		var size int64
+		if sizes[i] != nil {
+			size = sizes[i].Val()
+		}
+		rows = append(rows, map[string]any{
+			"key":  key,
+			"type": keyType,
+			"ttl":  redisTTLSeconds(ttls[i].Val()),
+			"size": size,
+		})
+	}
+	return rows, nil
+}
+
+// ExecuteQuery runs a single command such as `HGETALL user:1`. Arguments are
+// split on whitespace; single or double quotes keep spaces in an argument.
+// Read-only mode accepts the commands in redisReadCommands. The server has no
+// command timeout, so opts.Timeout only bounds the wait for the reply.
+func (c *RedisClient) ExecuteQuery(ctx context.Context, query string, opts QueryOptions) (*QueryResult, error) {
+	start := time.Now()
+
+	args, err := splitRedisCommand(query)
+	if err != nil {
+		return &QueryResult{Error: err.Error()}, err
+	}
+	if len(args) == 0 {
+		err := errors.New("command is empty")
+		return &QueryResult{Error: err.Error()}, err
+	}
+
+	if opts.ReadOnly && !redisReadCommands[strings.ToLower(args[0])] {
+		return &QueryResult{Error: ErrWriteNotAllowed.Error()}, ErrWriteNotAllowed
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	commandArgs := make([]any, len(args))
+	for i, arg := range args {
+		commandArgs[i] = arg
+	}
+
+	reply, err := c.client.Do(ctx, commandArgs...).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return &QueryResult{Error: err.Error()}, err
+	}
+
+	result := &QueryResult{ExecutionTime: time.Since(start)}
+	var size int64
+	add := func(row map[string]any) bool {
+		for _, value := range row {
+			size += valueSize(value)
+		}
+		if (opts.Limit > 0 && len(result.Rows) >= opts.Limit) || (opts.MaxBytes > 0 && size > opts.MaxBytes) {
+			result.Truncated = true
+			return false
+		}
+		result.Rows = append(result.Rows, row)
+		return true
+	}
+
+	switch value := reply.(type) {
+	case []any:
+		result.Columns = []string{"index", "value"}
+		for i, element := range value {
+			if !add(map[string]any{"index": i, "value": redisValue(element)}) {
+				break
+			}
+		}
+	case map[any]any:
+		result.Columns = []string{"key", "value"}
+		for key, element := range value {
+			if !add(map[string]any{"key": redisValue(key), "value": redisValue(element)}) {
+				break
+			}
+		}
+	default:
+		result.Columns = []string{"result"}
+		result.Rows = []map[string]any{{"result": redisValue(value)}}
+	}
+	result.RowsAffected = int64(len(result.Rows))
+
+	return result, nil
+}
+
+// redisReadCommands are the commands read-only mode lets through. It is an
+// allowlist rather than a lookup of COMMAND INFO flags, which Dragonfly and
+// older servers report differently and which don't mark scripts as writes.
+var redisReadCommands = map[string]bool{
+	"get": true, "mget": true, "getrange": true, "strlen": true,
+	"exists": true, "type": true, "ttl": true, "pttl": true, "expiretime": true, "pexpiretime": true,
+	"keys": true, "scan": true, "randomkey": true, "dbsize": true, "dump": true,
+	"hget": true, "hmget": true, "hgetall": true, "hkeys": true, "hvals": true, "hlen": true,
+	"hexists": true, "hstrlen": true, "hscan": true, "hrandfield": true,
+	"lrange": true, "lindex": true, "llen": true, "lpos": true,
+	"smembers": true, "sismember": true, "smismember": true, "scard": true, "srandmember": true,
+	"sscan": true, "sinter": true, "sintercard": true, "sunion": true, "sdiff": true,
+	"zrange": true, "zrangebyscore": true, "zrangebylex": true, "zrevrange": true,
+	"zrevrangebyscore": true, "zrevrangebylex": true, "zscore": true, "zmscore": true,
+	"zrank": true, "zrevrank": true, "zcard": true, "zcount": true, "zlexcount": true,
+	"zscan": true, "zrandmember": true, "zinter": true, "zunion": true, "zdiff": true,
+	"xrange": true, "xrevrange": true, "xlen": true, "xread": true, "xinfo": true, "xpending": true,
+	"getbit": true, "bitcount": true, "bitpos": true, "pfcount": true,
+	"geopos": true, "geodist": true, "geohash": true, "geosearch": true,
+	"georadius_ro": true, "georadiusbymember_ro": true,
+	"info": true, "ping": true, "echo": true, "time": true, "lastsave": true,
+}
+
+// InsertRow creates a key from "key", "type" (string by default), "value" and
+// an optional "ttl" in seconds. Hash and sorted set values are objects of
+// fields or members to scores; list and set values are arrays.
+func (c *RedisClient) InsertRow(ctx context.Context, tableName, schema string, data map[string]any) error {
+	client, err := c.keysTable(tableName, schema)
+	if err != nil {
+		return err
+	}
+
+	key, ok := data["key"].(string)
+	if !ok || key == "" {
+		return errors.New("key is required")
+	}
+	keyType, _ := data["type"].(string)
+	if keyType == "" {
+		keyType = "string"
+	}
+
+	exists, err := client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return fmt.Errorf("key %s already exists", key)
+	}
+
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if err := writeRedisValue(ctx, pipe, keyType, key, data["value"]); err != nil {
+			return err
+		}
+		return expireRedisKey(ctx, pipe, key, data["ttl"])
+	})
+	return err
+}
+
+// UpdateRow replaces the value of a key, keeping its type unless "type" is
+// given, and sets its TTL when "ttl" is given
+func (c *RedisClient) UpdateRow(ctx context.Context, tableName, schema string, primaryKey map[string]any, data map[string]any) error {
+	client, err := c.keysTable(tableName, schema)
+	if err != nil {
+		return err
+	}
+
+	key, ok := primaryKey["key"].(string)
+	if !ok || key == "" {
+		return errors.New("key is required")
+	}
+
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if keyType == "none" {
+		return ErrKeyNotFound
+	}
+	if requested, _ := data["type"].(string); requested != "" {
+		keyType = requested
+	}
+
+	var ttl time.Duration
+	if _, ok := data["ttl"]; !ok {
+		if ttl, err = client.TTL(ctx, key).Result(); err != nil {
+			return err
+		}
+	}
+
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if value, ok := data["value"]; ok {
+			pipe.Del(ctx, key)
+			if err := writeRedisValue(ctx, pipe, keyType, key, value); err != nil {
+				return err
+			}
+			if ttl > 0 {
+				pipe.Expire(ctx, key, ttl)
+			}
+		}
+		if value, ok := data["ttl"]; ok {
+			return expireRedisKey(ctx, pipe, key, value)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *RedisClient) DeleteRow(ctx context.Context, tableName, schema string, primaryKey map[string]any) error {
+	client, err := c.keysTable(tableName, schema)
+	if err != nil {
+		return err
+	}
+
+	key, ok := primaryKey["key"].(string)
+	if !ok || key == "" {
+		return errors.New("key is required")
+	}
+
+	deleted, err := client.Del(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// GetDatabaseVersion reports the Dragonfly or KeyDB version where the server
+// has one, the Redis version otherwise
+func (c *RedisClient) GetDatabaseVersion(ctx context.Context) (string, error) {
+	info, err := c.client.Info(ctx, "server").Result()
+	if err != nil {
+		return "", err
+	}
+
+	fields := redisInfoFields(info)
+	for _, name := range []string{"dragonfly_version", "keydb_version"} {
+		if version := fields[name]; version != "" {
+			return version, nil
+		}
+	}
+	return fields["redis_version"], nil
+}
+
+func (c *RedisClient) GetDatabaseSize(ctx context.Context) (int64, error) {
+	info, err := c.client.Info(ctx, "memory").Result()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(redisInfoFields(info)["used_memory"], 10, 64)
+}
+
+// GetKey returns the value of a key according to its type. Collections are
+// read up to redisMaxElements elements.
+func (c *RedisClient) GetKey(ctx context.Context, schema, key string) (*KeyValue, error) {
+	client, err := c.db(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if keyType == "none" {
+		return nil, ErrKeyNotFound
+	}
+
+	ttl, err := client.TTL(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &KeyValue{Key: key, Type: keyType, TTL: redisTTLSeconds(ttl)}
+
+	switch keyType {
+	case "string":
+		value, err := client.Get(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		result.Size = int64(len(value))
+		result.Value = redisValue(value)
+
+	case "hash":
+		if result.Size, err = client.HLen(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		fields := make(map[string]any)
+		var cursor uint64
+		for {
+			var batch []string
+			batch, cursor, err = client.HScan(ctx, key, cursor, "*", 200).Result()
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i+1 < len(batch); i += 2 {
+				fields[batch[i]] = redisValue(batch[i+1])
+			}
+			if cursor == 0 || len(fields) >= redisMaxElements {
+				break
+			}
+		}
+		result.Value = fields
+
+	case "list":
+		if result.Size, err = client.LLen(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		items, err := client.LRange(ctx, key, 0, redisMaxElements-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, len(items))
+		for i, item := range items {
+			values[i] = redisValue(item)
+		}
+		result.Value = values
+
+	case "set":
+		if result.Size, err = client.SCard(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		var members []any
+		var cursor uint64
+		for {
+			var batch []string
+			batch, cursor, err = client.SScan(ctx, key, cursor, "*", 200).Result()
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range batch {
+				members = append(members, redisValue(member))
+			}
+			if cursor == 0 || len(members) >= redisMaxElements {
+				break
+			}
+		}
+		result.Value = members
+
+	case "zset":
+		if result.Size, err = client.ZCard(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		entries, err := client.ZRangeWithScores(ctx, key, 0, redisMaxElements-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]map[string]any, len(entries))
+		for i, entry := range entries {
+			values[i] = map[string]any{"member": redisValue(entry.Member), "score": entry.Score}
+		}
+		result.Value = values
+
+	case "stream":
+		if result.Size, err = client.XLen(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		messages, err := client.XRangeN(ctx, key, "-", "+", redisMaxElements).Result()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]map[string]any, len(messages))
+		for i, message := range messages {
+			values[i] = map[string]any{"id": message.ID, "values": message.Values}
+		}
+		result.Value = values
+
+	default:
+		return nil, fmt.Errorf("keys of type %s can't be displayed", keyType)
+	}
+
+	if keyType != "string" {
+		result.Truncated = result.Size > redisMaxElements
+	}
+	return result, nil
+}
+
+func (c *RedisClient) SetKeyTTL(ctx context.Context, schema, key string, ttl time.Duration) error {
+	client, err := c.db(schema)
+	if err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		exists, err := client.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return ErrKeyNotFound
+		}
+		_, err = client.Persist(ctx, key).Result()
+		return err
+	}
+
+	ok, err := client.Expire(ctx, key, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (c *RedisClient) keysTable(tableName, schema string) (*redis.Client, error) {
+	if tableName != redisKeysTable {
+		return nil, fmt.Errorf("unknown table %q, keys are listed in %q", tableName, redisKeysTable)
+	}
+	return c.db(schema)
+}
+
+func redisSizeCmd(ctx context.Context, pipe redis.Pipeliner, keyType, key string) *redis.IntCmd {
+	switch keyType {
+	case "string":
+		return pipe.StrLen(ctx, key)
+	case "hash":
+		return pipe.HLen(ctx, key)
+	case "list":
+		return pipe.LLen(ctx, key)
+	case "set":
+		return pipe.SCard(ctx, key)
+	case "zset":
+		return pipe.ZCard(ctx, key)
+	case "stream":
+		return pipe.XLen(ctx, key)
+	default:
+		return nil
+	}
+}
+
+func writeRedisValue(ctx context.Context, pipe redis.Pipeliner, keyType, key string, value any) error {
+	switch keyType {
+	case "string":
+		pipe.Set(ctx, key, fmt.Sprintf("%v", value), 0)
+
+	case "hash":
+		fields, ok := value.(map[string]any)
+		if !ok || len(fields) == 0 {
+			return errors.New("hash value must be an object of fields")
+		}
+		values := make([]any, 0, len(fields)*2)
+		for field, v := range fields {
+			values = append(values, field, fmt.Sprintf("%v", v))
+		}
+		pipe.HSet(ctx, key, values...)
+
+	case "list", "set":
+		items, ok := value.([]any)
+		if !ok || len(items) == 0 {
+			return fmt.Errorf("%s value must be a non-empty array", keyType)
+		}
+		values := make([]any, len(items))
+		for i, item := range items {
+			values[i] = fmt.Sprintf("%v", item)
+		}
+		if keyType == "list" {
+			pipe.RPush(ctx, key, values...)
+		} else {
+			pipe.SAdd(ctx, key, values...)
+		}
+
+	case "zset":
+		scores, ok := value.(map[string]any)
+		if !ok || len(scores) == 0 {
+			return errors.New("sorted set value must be an object of members to scores")
+		}
+		members := make([]redis.Z, 0, len(scores))
+		for member, score := range scores {
+			s, ok := score.(float64)
+			if !ok {
+				return fmt.Errorf("score of %s must be a number", member)
+			}
+			members = append(members, redis.Z{Member: member, Score: s})
+		}
+		pipe.ZAdd(ctx, key, members...)
+
+	default:
+		return fmt.Errorf("keys of type %s can't be written from the studio", keyType)
+	}
+	return nil
+}
+
+// expireRedisKey applies a TTL in seconds from a JSON value; zero or less
+// removes the expiry
+func expireRedisKey(ctx context.Context, pipe redis.Pipeliner, key string, ttl any) error {
+	if ttl == nil {
+		return nil
+	}
+	seconds, ok := ttl.(float64)
+	if !ok {
+		return errors.New("ttl must be a number of seconds")
+	}
+	if seconds <= 0 {
+		pipe.Persist(ctx, key)
+		return nil
+	}
+	pipe.Expire(ctx, key, time.Duration(seconds)*time.Second)
+	return nil
+}
+
+// redisTTLSeconds converts a TTL reply to seconds, -1 for keys without expiry
+func redisTTLSeconds(ttl time.Duration) int64 {
+	if ttl < 0 {
+		return -1
+	}
+	return int64(ttl / time.Second)
+}
+
+// redisValue returns strings that aren't valid UTF-8 as bytes, which encode
+// to base64 in JSON
+func redisValue(value any) any {
+	if s, ok := value.(string); ok && !utf8.ValidString(s) {
+		return []byte(s)
+	}
+	if items, ok := value.([]any); ok {
+		converted := make([]any, len(items))
+		for i, item := range items {
+			converted[i] = redisValue(item)
+		}
+		return converted
+	}
+	return value
+}
+
+// redisKeyPattern turns a filter on the key column into a SCAN pattern
+func redisKeyPattern(operator, value string) string {
+	escaped := redisGlobEscaper.Replace(value)
+	switch operator {
+	case "like":
+		return value
+	case "contains":
+		return "*" + escaped + "*"
+	case "starts_with":
+		return escaped + "*"
+	case "ends_with":
+		return "*" + escaped
+	default:
+		return escaped
+	}
+}
+
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+func redisInfoFields(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// splitRedisCommand splits a command line into arguments like redis-cli does
+// for simple input: whitespace separates arguments and quotes group them
+func splitRedisCommand(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unbalanced quotes")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"io"
 
 	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
 )
@@ -28,6 +29,59 @@ type DatabaseDeploymentService interface {
 
 	// GetLogs retrieves logs from a database container
 	GetLogs(ctx context.Context, database *databases.Database, follow bool) ([]byte, error)
+
+	// ListHostPorts returns the host ports published by containers other than
+	// the one of database, keyed by port
+	ListHostPorts(ctx context.Context, database *databases.Database) (map[int]string, error)
+
+	// Dump writes a logical dump of the database to outputDir using a sidecar container
+	Dump(ctx context.Context, database *databases.Database, outputDir string) (*DumpResult, error)
+
+	// BaseBackup writes a physical base backup of a PostgreSQL database to outputDir
+	BaseBackup(ctx context.Context, database *databases.Database, outputDir string) (*DumpResult, error)
+
+	// Restore loads a dump of the given format from workDir into the database,
+	// writing the output of the restore tool to progress
+	Restore(ctx context.Context, database *databases.Database, workDir, format string, progress io.Writer) error
+
+	// Import loads a dump staged in workDir by StageImport into the database
+	Import(ctx context.Context, database *databases.Database, workDir, format string, progress io.Writer) error
+
+	// Pull copies an external database reachable at sourceURL into the database
+	Pull(ctx context.Context, database *databases.Database, sourceURL, workDir string, progress io.Writer) error
+
+	// CheckUpgrade runs the compatibility checks of an upgrade against the running database
+	CheckUpgrade(ctx context.Context, database *databases.Database, plan *UpgradePlan, workDir string, progress io.Writer) error
+
+	// Fingerprint summarises the schema of the running database for verifying an upgrade
+	Fingerprint(ctx context.Context, database *databases.Database, workDir string) (string, error)
+
+	// Snapshot archives the data volumes of the database into workDir
+	Snapshot(ctx context.Context, database *databases.Database, workDir string, progress io.Writer) error
+
+	// RestoreSnapshot replaces the data volumes of a stopped database with the snapshot in workDir
+	RestoreSnapshot(ctx context.Context, database *databases.Database, workDir string, progress io.Writer) error
+
+	// PrepareUpgrade runs the steps of an upgrade that need the old server
+	PrepareUpgrade(ctx context.Context, database *databases.Database, plan *UpgradePlan, workDir string, progress io.Writer) error
+
+	// CompleteUpgrade runs the steps of an upgrade that need the new server
+	CompleteUpgrade(ctx context.Context, database *databases.Database, plan *UpgradePlan, workDir string, progress io.Writer) error
+
+	// PrepareReplicationSource creates the replication user on a running primary
+	PrepareReplicationSource(ctx context.Context, primary *databases.Database, workDir string, progress io.Writer) error
+
+	// SeedReplica copies the data of a primary into a replica and starts replication
+	SeedReplica(ctx context.Context, primary, replica *databases.Database, workDir string, progress io.Writer) error
+
+	// PromoteReplica turns a running replica into a writable standalone database
+	PromoteReplica(ctx context.Context, replica *databases.Database, workDir string, progress io.Writer) error
+
+	// DropReplicationSlot removes the slot of a replica from its primary
+	DropReplicationSlot(ctx context.Context, primary *databases.Database, replicaID, workDir string) error
+
+	// ReplicationStatus reports how far a replica trails its primary
+	ReplicationStatus(ctx context.Context, replica *databases.Database) (*ReplicationStatus, error)
 }
 
 // DeploymentResult contains information about a deployed database container
@@ -0,0 +1,536 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/databases"
+)
+
+// UpgradeMethod is how a database moves to another version of its engine
+type UpgradeMethod string
+
+const (
+	// UpgradeMethodImageSwap restarts the database on the new image. Used
+	// within a release series, where the data files don't change format.
+	UpgradeMethodImageSwap UpgradeMethod = "image_swap"
+	// UpgradeMethodInPlace restarts the database on the new image after
+	// compatibility checks and lets the server upgrade its data files
+	UpgradeMethodInPlace UpgradeMethod = "in_place"
+	// UpgradeMethodDumpRestore dumps the whole server, initializes an empty
+	// data directory with the new version and loads the dump into it
+	UpgradeMethodDumpRestore UpgradeMethod = "dump_restore"
+)
+
+const (
+	// SnapshotFileName is the archive of the data volumes taken before an
+	// upgrade, relative to the upgrade work directory
+	SnapshotFileName = "snapshot.tar"
+
+	upgradeLogFileName  = "upgrade.log"
+	upgradeDumpFileName = "dumpall.sql"
+	fingerprintFileName = "fingerprint"
+)
+
+// clearDataPaths empties the directories in DATA_PATHS, which are relative to
+// the root and usually volume mount points that can't be removed themselves
+const clearDataPaths = `for P in $DATA_PATHS; do
+  find "/$P" -mindepth 1 -maxdepth 1 -exec rm -rf {} \;
+done
+`
+
+// UpgradePlan describes how a database moves from one version to another
+type UpgradePlan struct {
+	Type        databases.DatabaseType
+	FromVersion string
+	ToVersion   string
+	Method      UpgradeMethod
+}
+
+// PlanUpgrade validates an upgrade between two versions and picks its method.
+// The target must be a supported version and newer than the current one.
+// PostgreSQL can't read the data directory of another major version, so major
+// upgrades dump and reload. MySQL and MongoDB upgrade their data files in
+// place, but only from the previous release series.
+func PlanUpgrade(dbType databases.DatabaseType, fromVersion, toVersion string) (*UpgradePlan, error) {
+	resolver := NewDefaultImageResolver()
+	if fromVersion == "" {
+		fromVersion = resolver.GetDefaultVersion(dbType)
+	}
+
+	supported := resolver.GetSupportedVersions(dbType)
+	if !slices.Contains(supported, toVersion) {
+		return nil, fmt.Errorf("version %s is not supported for %s (supported: %s)", toVersion, dbType, strings.Join(supported, ", "))
+	}
+	if fromVersion == toVersion {
+		return nil, fmt.Errorf("database already runs version %s", toVersion)
+	}
+
+	from, err := parseVersion(fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseVersion(toVersion)
+	if err != nil {
+		return nil, err
+	}
+	if compareVersions(from, to) > 0 {
+		return nil, fmt.Errorf("downgrading from %s to %s is not supported; restore a backup instead", fromVersion, toVersion)
+	}
+
+	plan := &UpgradePlan{
+		Type:        dbType,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+	}
+
+	fromSeries, toSeries := releaseSeries(dbType, from), releaseSeries(dbType, to)
+	if compareVersions(fromSeries, toSeries) == 0 {
+		plan.Method = UpgradeMethodImageSwap
+		return plan, nil
+	}
+
+	switch dbType {
+	case databases.DatabaseTypePostgreSQL:
+		plan.Method = UpgradeMethodDumpRestore
+
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMongoDB:
+		next := nextSeries(dbType, fromSeries)
+		if next == nil {
+			return nil, fmt.Errorf("no supported upgrade path from %s", fromVersion)
+		}
+		if compareVersions(next, toSeries) != 0 {
+			return nil, fmt.Errorf("%s upgrades one release series at a time; upgrade to %s first", dbType, formatVersion(next))
+		}
+		plan.Method = UpgradeMethodInPlace
+
+	default:
+		plan.Method = UpgradeMethodInPlace
+	}
+
+	return plan, nil
+}
+
+// parseVersion reads the numeric components of an image tag such as
+// "16-alpine", "8.4" or "v1.23.1"
+func parseVersion(version string) ([]int, error) {
+	numeric, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+
+	var parts []int
+	for _, field := range strings.Split(numeric, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func formatVersion(parts []int) string {
+	fields := make([]string, len(parts))
+	for i, part := range parts {
+		fields[i] = strconv.Itoa(part)
+	}
+	return strings.Join(fields, ".")
+}
+
+// releaseSeries truncates a version to the components within which the data
+// format is stable: the major version for PostgreSQL, major.minor for the
+// engines that number their series that way. Missing components are zero, so
+// a "7" tag is series 7.0.
+func releaseSeries(dbType databases.DatabaseType, version []int) []int {
+	n := 1
+	switch dbType {
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB, databases.DatabaseTypeMongoDB:
+		n = 2
+	}
+	series := make([]int, n)
+	copy(series, version)
+	return series
+}
+
+// nextSeries returns the supported release series following series, or nil
+func nextSeries(dbType databases.DatabaseType, series []int) []int {
+	var candidates [][]int
+	for _, version := range NewDefaultImageResolver().GetSupportedVersions(dbType) {
+		parts, err := parseVersion(version)
+		if err != nil {
+			continue
+		}
+		if s := releaseSeries(dbType, parts); compareVersions(s, series) > 0 {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return compareVersions(candidates[i], candidates[j]) < 0
+	})
+	return candidates[0]
+}
+
+// upgradeEnvironment returns the sidecar environment of an upgrade. Besides
+// the connection settings it lists the data volumes to snapshot and the
+// release series MongoDB's feature compatibility version moves between.
+func upgradeEnvironment(database *databases.Database, config *DatabaseContainerConfig, plan *UpgradePlan) (map[string]string, error) {
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, containerPath := range config.Volumes {
+		if containerPath != walArchiveMountPath {
+			paths = append(paths, strings.TrimPrefix(containerPath, "/"))
+		}
+	}
+	sort.Strings(paths)
+	environment["DATA_PATHS"] = strings.Join(paths, " ")
+
+	if plan != nil {
+		if from, err := parseVersion(plan.FromVersion); err == nil {
+			environment["FROM_SERIES"] = formatVersion(releaseSeries(database.Type(), from))
+		}
+		if to, err := parseVersion(plan.ToVersion); err == nil {
+			environment["TO_SERIES"] = formatVersion(releaseSeries(database.Type(), to))
+		}
+	}
+
+	return environment, nil
+}
+
+// targetImageConfig builds the container config of the database with the
+// image of the target version, so checks and dumps use the newer client tools
+func (s *Service) targetImageConfig(ctx context.Context, database *databases.Database, plan *UpgradePlan) (*DatabaseContainerConfig, error) {
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build container config: %w", err)
+	}
+
+	config.Image = s.imageResolver.ResolveImage(database.Type(), plan.ToVersion)
+	if err := s.containerService.PullImage(ctx, config.Image); err != nil {
+		return nil, fmt.Errorf("failed to pull image: %w", err)
+	}
+
+	return config, nil
+}
+
+// CheckUpgrade runs the compatibility checks of an upgrade against the running
+// database: MySQL and MariaDB tables must not need a repair, and MongoDB must
+// have finished moving its feature compatibility version to the current series.
+func (s *Service) CheckUpgrade(ctx context.Context, database *databases.Database, plan *UpgradePlan, workDir string, progress io.Writer) error {
+	if plan.Method != UpgradeMethodInPlace {
+		return nil
+	}
+
+	var script string
+	switch database.Type() {
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		script = mysqlClients + `CHECK=mysqlcheck
+command -v mariadb-check >/dev/null 2>&1 && CHECK=mariadb-check
+echo "Checking tables for upgrade"
+OUT=$($CHECK -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" --all-databases --check-upgrade 2>&1) || STATUS=$?
+echo "$OUT"
+if [ -n "$STATUS" ] || echo "$OUT" | grep -qiE '^error|upgrade required'; then
+  echo "Some tables must be repaired before upgrading"
+  exit 1
+fi`
+
+	case databases.DatabaseTypeMongoDB:
+		script = mongoArgs + `FCV=$(mongosh "$@" --quiet --eval 'db.adminCommand({getParameter: 1, featureCompatibilityVersion: 1}).featureCompatibilityVersion.version')
+echo "Feature compatibility version: $FCV"
+if [ "$FCV" != "$FROM_SERIES" ]; then
+  echo "The feature compatibility version must be $FROM_SERIES before upgrading to $TO_SERIES"
+  exit 1
+fi`
+
+	default:
+		return nil
+	}
+
+	config, err := s.targetImageConfig(ctx, database, plan)
+	if err != nil {
+		return err
+	}
+	environment, err := upgradeEnvironment(database, config, plan)
+	if err != nil {
+		return err
+	}
+
+	job := &sidecarJob{format: "upgrade check", script: script, environment: environment}
+	return s.runSidecar(ctx, config, "upgrade", job, workDir, upgradeLogFileName, progress)
+}
+
+// Fingerprint summarises the schema of the running database as one line per
+// database with its number of tables or collections. Comparing fingerprints
+// taken before and after an upgrade verifies nothing was lost. Key-value
+// stores only wait for the server to respond and return an empty fingerprint.
+func (s *Service) Fingerprint(ctx context.Context, database *databases.Database, workDir string) (string, error) {
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return "", fmt.Errorf("failed to build container config: %w", err)
+	}
+	environment, err := sidecarEnvironment(database, config)
+	if err != nil {
+		return "", err
+	}
+
+	out := sidecarMountPath + "/" + fingerprintFileName
+
+	var script string
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		script = `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+[ -s /backup/` + upgradeDumpFileName + ` ] || { echo "The dump taken before the upgrade is missing"; exit 1; }
+q() { psql -h 127.0.0.1 -p "$DB_PORT" -XAt "$@"; }
+for DB in $(q -d postgres -c "SELECT datname FROM pg_database WHERE NOT datistemplate ORDER BY datname"); do
+  q -d "$DB" -c "SELECT current_database() || ': ' || count(*) || ' tables' FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE c.relkind IN ('r', 'p') AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg_toast%'"
+done > ` + out
+
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		script = mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -N -B -e "SELECT CONCAT(table_schema, ': ', COUNT(*), ' tables') FROM information_schema.tables WHERE table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys') GROUP BY table_schema ORDER BY table_schema" > ` + out
+
+	case databases.DatabaseTypeMongoDB:
+		script = mongoPing + `wait_for ping
+` + mongoArgs + `mongosh "$@" --quiet --eval 'db.adminCommand({listDatabases: 1}).databases.filter(d => !["admin", "config", "local"].includes(d.name)).map(d => d.name + ": " + db.getSiblingDB(d.name).getCollectionNames().length + " collections").sort().join("\n")' > ` + out
+
+	case databases.DatabaseTypeRedis, databases.DatabaseTypeKeyDB, databases.DatabaseTypeDragonfly:
+		script = redisCLI + `wait_for cli ping
+: > ` + out
+
+	case databases.DatabaseTypeClickHouse:
+		script = clickHouseClient + `wait_for ch --query "SELECT 1"
+ch --query "SELECT database || ': ' || toString(count()) || ' tables' FROM system.tables WHERE database NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema') GROUP BY database ORDER BY database" > ` + out
+
+	default:
+		return "", fmt.Errorf("upgrades are not supported for database type: %s", database.Type())
+	}
+
+	job := &sidecarJob{format: "fingerprint", script: waitForReady + script, environment: environment}
+	if err := s.runSidecar(ctx, config, "upgrade", job, workDir, upgradeLogFileName, nil); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, fingerprintFileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read fingerprint: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Snapshot archives the data volumes of the database into workDir. The
+// container is stopped while the files are copied so the archive is
+// consistent, and started again afterwards.
+func (s *Service) Snapshot(ctx context.Context, database *databases.Database, workDir string, progress io.Writer) error {
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+	environment, err := upgradeEnvironment(database, config, nil)
+	if err != nil {
+		return err
+	}
+
+	job := &sidecarJob{
+		format: "snapshot",
+		script: `cd /
+tar -cf /backup/` + SnapshotFileName + `.tmp $DATA_PATHS
+mv /backup/` + SnapshotFileName + `.tmp /backup/` + SnapshotFileName + `
+echo "Snapshot written ($(du -h /backup/` + SnapshotFileName + ` | cut -f1))"`,
+		environment: environment,
+		mountData:   true,
+		offline:     true,
+	}
+
+	fmt.Fprintln(progress, "Stopping database container")
+	if err := s.Stop(ctx, database); err != nil {
+		return fmt.Errorf("failed to stop database container: %w", err)
+	}
+
+	snapshotErr := s.runSidecar(ctx, config, "upgrade", job, workDir, upgradeLogFileName, progress)
+
+	fmt.Fprintln(progress, "Starting database container")
+	if err := s.Start(ctx, database); err != nil {
+		return fmt.Errorf("failed to start database container: %w", err)
+	}
+
+	return snapshotErr
+}
+
+// RestoreSnapshot replaces the data volumes of the database with the snapshot
+// in workDir. The database container must be stopped or removed.
+func (s *Service) RestoreSnapshot(ctx context.Context, database *databases.Database, workDir string, progress io.Writer) error {
+	if _, err := os.Stat(filepath.Join(workDir, SnapshotFileName)); err != nil {
+		return fmt.Errorf("snapshot not found: %w", err)
+	}
+
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+	environment, err := upgradeEnvironment(database, config, nil)
+	if err != nil {
+		return err
+	}
+
+	job := &sidecarJob{
+		format: "snapshot restore",
+		script: clearDataPaths + `tar -xf /backup/` + SnapshotFileName + ` -C /
+echo "Data volumes restored from the snapshot"`,
+		environment: environment,
+		mountData:   true,
+		offline:     true,
+	}
+
+	return s.runSidecar(ctx, config, "upgrade", job, workDir, upgradeLogFileName, progress)
+}
+
+// PrepareUpgrade runs the steps of an upgrade that need the old server, before
+// the database is redeployed on the new version. PostgreSQL is dumped with
+// pg_dumpall of the new version and its data directory emptied, leaving the
+// container stopped. MySQL and MariaDB are asked to shut down slowly so no
+// undo or change buffer work is left for the new version.
+func (s *Service) PrepareUpgrade(ctx context.Context, database *databases.Database, plan *UpgradePlan, workDir string, progress io.Writer) error {
+	if plan.Method == UpgradeMethodImageSwap {
+		return nil
+	}
+
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		config, err := s.targetImageConfig(ctx, database, plan)
+		if err != nil {
+			return err
+		}
+		environment, err := upgradeEnvironment(database, config, plan)
+		if err != nil {
+			return err
+		}
+
+		dump := &sidecarJob{
+			format: "pg_dumpall",
+			script: `echo "Dumping all databases"
+pg_dumpall -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -f /backup/` + upgradeDumpFileName + `
+echo "Dump written ($(du -h /backup/` + upgradeDumpFileName + ` | cut -f1))"`,
+			environment: environment,
+		}
+		if err := s.runSidecar(ctx, config, "upgrade", dump, workDir, upgradeLogFileName, progress); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(progress, "Stopping database container")
+		if err := s.Stop(ctx, database); err != nil {
+			return fmt.Errorf("failed to stop database container: %w", err)
+		}
+
+		empty := &sidecarJob{
+			format:      "data directory cleanup",
+			script:      clearDataPaths + `echo "Data directory emptied for the new version"`,
+			environment: environment,
+			mountData:   true,
+			offline:     true,
+		}
+		return s.runSidecar(ctx, config, "upgrade", empty, workDir, upgradeLogFileName, progress)
+
+	case databases.DatabaseTypeMySQL, databases.DatabaseTypeMariaDB:
+		config, err := s.targetImageConfig(ctx, database, plan)
+		if err != nil {
+			return err
+		}
+		environment, err := upgradeEnvironment(database, config, plan)
+		if err != nil {
+			return err
+		}
+
+		job := &sidecarJob{
+			format: "slow shutdown",
+			script: mysqlClients + `$CLIENT -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" -e "SET GLOBAL innodb_fast_shutdown = 0" ||
+  echo "Warning: a slow shutdown could not be requested"`,
+			environment: environment,
+		}
+		return s.runSidecar(ctx, config, "upgrade", job, workDir, upgradeLogFileName, progress)
+
+	default:
+		return nil
+	}
+}
+
+// CompleteUpgrade runs the steps of an upgrade that need the new server, once
+// the database is redeployed on the new version. PostgreSQL loads the dump
+// taken by PrepareUpgrade, MariaDB upgrades its system tables and MongoDB
+// moves its feature compatibility version to the new series.
+func (s *Service) CompleteUpgrade(ctx context.Context, database *databases.Database, plan *UpgradePlan, workDir string, progress io.Writer) error {
+	if plan.Method == UpgradeMethodImageSwap {
+		return nil
+	}
+
+	var script string
+	switch database.Type() {
+	case databases.DatabaseTypePostgreSQL:
+		// The new server starts with the user and database of the old one,
+		// which the dump creates again. They are dropped or skipped so that
+		// any other error stops the load and fails the upgrade.
+		script = `wait_for pg_isready -h 127.0.0.1 -p "$DB_PORT"
+[ -s /backup/` + upgradeDumpFileName + ` ] || { echo "The dump taken before the upgrade is missing"; exit 1; }
+q() { psql -h 127.0.0.1 -p "$DB_PORT" -U "$PGUSER" -d postgres -X -q -v ON_ERROR_STOP=1 "$@"; }
+q -At -c "SELECT format('DROP DATABASE %I WITH (FORCE);', datname) FROM pg_database WHERE NOT datistemplate AND datname <> 'postgres'" | q
+echo "Loading dump"
+grep -v -F -x -e "CREATE ROLE $PGUSER;" -e "CREATE ROLE \"$PGUSER\";" /backup/` + upgradeDumpFileName + ` | q
+echo "Dump loaded"`
+
+	case databases.DatabaseTypeMariaDB:
+		script = mysqlClients + `wait_for $ADMIN -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER" ping
+UPGRADE=mysql_upgrade
+command -v mariadb-upgrade >/dev/null 2>&1 && UPGRADE=mariadb-upgrade
+$UPGRADE -h 127.0.0.1 -P "$DB_PORT" -u "$DB_USER"`
+
+	case databases.DatabaseTypeMongoDB:
+		script = mongoPing + `wait_for ping
+` + mongoArgs + `echo "Setting feature compatibility version to $TO_SERIES"
+mongosh "$@" --quiet --eval 'db.adminCommand({setFeatureCompatibilityVersion: process.env.TO_SERIES, confirm: true})'`
+
+	default:
+		return nil
+	}
+
+	config, err := s.configBuilder.BuildConfig(database)
+	if err != nil {
+		return fmt.Errorf("failed to build container config: %w", err)
+	}
+	environment, err := upgradeEnvironment(database, config, plan)
+	if err != nil {
+		return err
+	}
+
+	job := &sidecarJob{format: "upgrade", script: waitForReady + script, environment: environment}
+	return s.runSidecar(ctx, config, "upgrade", job, workDir, upgradeLogFileName, progress)
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -66,6 +67,61 @@ func (d *DockerManager) StreamLogs(ctx context.Context, containerID string, foll
 	})
 }
 
+// Stats samples the resource usage of a container. The non-streaming stats
+// call waits for a second sample so the CPU usage can be computed.
+func (d *DockerManager) Stats(ctx context.Context, containerID string) (*ContainerStats, error) {
+	response, err := d.client.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	defer response.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(response.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode stats: %w", err)
+	}
+
+	result := &ContainerStats{
+		MemoryUsage: stats.MemoryStats.Usage,
+		MemoryLimit: stats.MemoryStats.Limit,
+	}
+
+	// Like docker stats, page cache that can be reclaimed isn't counted as used
+	// (inactive_file on cgroup v2, total_inactive_file on cgroup v1)
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if cache, ok := stats.MemoryStats.Stats[key]; ok && cache < result.MemoryUsage {
+			result.MemoryUsage -= cache
+			break
+		}
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		result.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	for _, network := range stats.Networks {
+		result.NetworkRxBytes += network.RxBytes
+		result.NetworkTxBytes += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			result.BlockReadBytes += entry.Value
+		case "write":
+			result.BlockWriteBytes += entry.Value
+		}
+	}
+
+	return result, nil
+}
+
 func (d *DockerManager) Create(ctx context.Context, config ContainerConfig) (string, error) {
 	// Convert port mappings
 	portBindings := make(nat.PortMap)
@@ -73,9 +129,10 @@ func (d *DockerManager) Create(ctx context.Context, config ContainerConfig) (str
 
 	for hostPort, containerPort := range config.Ports {
 		port := nat.Port(containerPort + "/tcp")
+		hostIP, hostPort := SplitHostBinding(hostPort)
 		exposedPorts[port] = struct{}{}
 		portBindings[port] = []nat.PortBinding{
-			{HostPort: hostPort},
+			{HostIP: hostIP, HostPort: hostPort},
 		}
 	}
 
@@ -182,6 +239,8 @@ func (d *DockerManager) Inspect(ctx context.Context, containerID string) (*Conta
 		State:  inspect.State.Status,
 		Status: inspect.State.Status,
 		Ports:  ports,
+
+		RestartCount: inspect.RestartCount,
 	}, nil
 }
 
@@ -216,7 +216,8 @@ func (p *PodmanManager) Create(ctx context.Context, config ContainerConfig) (str
 	if len(config.Ports) > 0 {
 		portMappings := make([]types.PortMapping, 0, len(config.Ports))
 		for hostPort, containerPort := range config.Ports {
-			// Handle port ranges and protocol parsing
+			// Handle bind address, port ranges and protocol parsing
+			hostIP, hostPort := SplitHostBinding(hostPort)
 			hostPortNum, hostProtocol, err := parsePortSpec(hostPort)
 			if err != nil {
 				return "", fmt.Errorf("invalid host port %s: %w", hostPort, err)
@@ -235,6 +236,7 @@ func (p *PodmanManager) Create(ctx context.Context, config ContainerConfig) (str
 			}
 
 			portMappings = append(portMappings, types.PortMapping{
+				HostIP:        hostIP,
 				HostPort:      uint16(hostPortNum),
 				ContainerPort: uint16(containerPortNum),
 				Protocol:      protocol,
@@ -397,6 +399,8 @@ func (p *PodmanManager) Inspect(ctx context.Context, containerID string) (*Conta
 		State:  inspectData.State.Status,
 		Status: inspectData.State.Status,
 		Ports:  ports,
+
+		RestartCount: int(inspectData.RestartCount),
 	}, nil
 }
 
@@ -491,6 +495,34 @@ func (p *PodmanManager) GetStats(ctx context.Context, containerID string) (*enti
 	return nil, fmt.Errorf("no stats received")
 }
 
+// Stats samples the resource usage of a container
+func (p *PodmanManager) Stats(ctx context.Context, containerID string) (*ContainerStats, error) {
+	report, err := p.GetStats(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Stats) == 0 {
+		return nil, fmt.Errorf("no stats received")
+	}
+
+	stats := report.Stats[0]
+	result := &ContainerStats{
+		CPUPercent:      stats.CPU,
+		MemoryUsage:     stats.MemUsage,
+		MemoryLimit:     stats.MemLimit,
+		BlockReadBytes:  stats.BlockInput,
+		BlockWriteBytes: stats.BlockOutput,
+	}
+
+	for _, network := range stats.Network {
+		result.NetworkRxBytes += network.RxBytes
+		result.NetworkTxBytes += network.TxBytes
+	}
+
+	return result, nil
+}
+
 // Exec runs a command in a container
 func (p *PodmanManager) Exec(ctx context.Context, containerID string, cmd []string) error {
 	createConfig := &handlers.ExecCreateConfig{
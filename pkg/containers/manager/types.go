@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 )
 
 type ContainerManager interface {
@@ -17,6 +18,9 @@ type ContainerManager interface {
 	// Logging
 	StreamLogs(ctx context.Context, containerID string, follow bool) (io.ReadCloser, error)
 
+	// Resource usage
+	Stats(ctx context.Context, containerID string) (*ContainerStats, error)
+
 	// Exec operations
 	ExecInteractive(ctx context.Context, containerID string, cmd []string, stdin io.Reader, stdout, stderr io.Writer, resize <-chan TerminalSize) error
 
@@ -33,7 +37,7 @@ type ContainerManager interface {
 type ContainerConfig struct {
 	Image         string
 	Name          string
-	Ports         map[string]string // host:container
+	Ports         map[string]string // host:container, host may be prefixed with a bind address ("127.0.0.1:5432")
 	Environment   map[string]string
 	Volumes       map[string]string // host:container
 	Networks      []string
@@ -54,6 +58,21 @@ type ContainerInfo struct {
 	State  string
 	Status string
 	Ports  map[string]string
+	// RestartCount is how often the runtime restarted the container, it is
+	// only set by Inspect
+	RestartCount int
+}
+
+// ContainerStats is a point-in-time sample of the resources a container uses.
+// Network and block IO are counters since the container started.
+type ContainerStats struct {
+	CPUPercent      float64
+	MemoryUsage     uint64
+	MemoryLimit     uint64
+	NetworkRxBytes  uint64
+	NetworkTxBytes  uint64
+	BlockReadBytes  uint64
+	BlockWriteBytes uint64
 }
 
 type TerminalSize struct {
@@ -86,3 +105,12 @@ func NewContainerManager(managerType ManagerType) (ContainerManager, error) {
 		return nil, fmt.Errorf("unsupported container manager: %s", managerType)
 	}
 }
+
+// SplitHostBinding splits a host port key of ContainerConfig.Ports into its
+// bind address and port. The address is empty when the key is a bare port.
+func SplitHostBinding(hostPort string) (string, string) {
+	if i := strings.LastIndex(hostPort, ":"); i >= 0 {
+		return hostPort[:i], hostPort[i+1:]
+	}
+	return "", hostPort
+}
@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AccessLogEntry holds the fields of a Traefik JSON access log line we care about
+type AccessLogEntry struct {
+	StartUTC              time.Time `json:"StartUTC"`
+	RouterName            string    `json:"RouterName"`
+	ServiceName           string    `json:"ServiceName"`
+	RequestMethod         string    `json:"RequestMethod"`
+	RequestHost           string    `json:"RequestHost"`
+	RequestPath           string    `json:"RequestPath"`
+	DownstreamStatus      int       `json:"DownstreamStatus"`
+	DownstreamContentSize int64     `json:"DownstreamContentSize"`
+	Duration              int64     `json:"Duration"` // nanoseconds
+	ClientHost            string    `json:"ClientHost"`
+
+	// LoggedAt is when the runtime received the line, zero when the line has
+	// no timestamp prefix. Unlike StartUTC it grows with every line.
+	LoggedAt time.Time `json:"-"`
+}
+
+// logTimestampRegex matches the RFC3339 timestamp the container runtime
+// prefixes log lines with
+var logTimestampRegex = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\S+`)
+
+// Host returns the request host without its port
+func (e *AccessLogEntry) Host() string {
+	host := strings.ToLower(e.RequestHost)
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+	return host
+}
+
+// Path returns the request path without its query string
+func (e *AccessLogEntry) Path() string {
+	if i := strings.IndexByte(e.RequestPath, '?'); i >= 0 {
+		return e.RequestPath[:i]
+	}
+	return e.RequestPath
+}
+
+// ParseAccessLogLine extracts an access log entry from a line of the proxy
+// container output. Lines may carry the stream header and timestamp prefix
+// added by the container runtime; anything that isn't a JSON access log is skipped.
+func ParseAccessLogLine(line []byte) (*AccessLogEntry, bool) {
+	start := bytes.Index(line, []byte(`{"`))
+	if start < 0 {
+		return nil, false
+	}
+
+	var entry AccessLogEntry
+	if err := json.Unmarshal(line[start:], &entry); err != nil {
+		return nil, false
+	}
+
+	if entry.DownstreamStatus == 0 || entry.RequestHost == "" {
+		return nil, false
+	}
+
+	if prefix := logTimestampRegex.Find(line[:start]); prefix != nil {
+		if loggedAt, err := time.Parse(time.RFC3339Nano, string(prefix)); err == nil {
+			entry.LoggedAt = loggedAt
+		}
+	}
+
+	return &entry, true
+}
+
+// StreamAccessLogs follows the output of the proxy container, which carries the
+// JSON access logs configured in writeGlobalConfig
+func (ts *TraefikService) StreamAccessLogs(ctx context.Context) (io.ReadCloser, error) {
+	containerID := ts.containerID
+	if containerID == "" {
+		containers, err := ts.containerService.ListContainers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+		for _, container := range containers {
+			if container.Name == ts.containerName {
+				containerID = container.ID
+				break
+			}
+		}
+	}
+
+	if containerID == "" {
+		return nil, fmt.Errorf("proxy container %s not found", ts.containerName)
+	}
+
+	return ts.containerService.StreamContainerLogs(ctx, containerID, true)
+}
@@ -0,0 +1,336 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+	"github.com/mikrocloud/mikrocloud/pkg/containers/manager"
+	services "github.com/mikrocloud/mikrocloud/pkg/containers/service"
+)
+
+const (
+	ErrorPagesImage         = "nginx:alpine"
+	ErrorPagesContainerName = "mikrocloud-errorpages"
+
+	// ErrorPagesServiceName is the Traefik service of the error page container, as
+	// declared by its docker labels
+	ErrorPagesServiceName = ErrorPagesContainerName + "@docker"
+
+	maintenanceConfigFile = "maintenance.json"
+	maintenancePriority   = 10000
+)
+
+// ErrorPagesService runs the small nginx container serving the custom error and
+// maintenance pages. Pages live on disk under dataDir/html:
+//
+//	default/<name>.html            built-in pages
+//	projects/<project>/<name>.html pages uploaded for a project
+type ErrorPagesService struct {
+	containerService services.ContainerService
+	dataDir          string
+	networkMode      string
+}
+
+func NewErrorPagesService(cs services.ContainerService, dataDir string, networkMode string) *ErrorPagesService {
+	if networkMode == "" {
+		networkMode = "bridge"
+	}
+	return &ErrorPagesService{
+		containerService: cs,
+		dataDir:          dataDir,
+		networkMode:      networkMode,
+	}
+}
+
+// Start writes the nginx config and default pages and makes sure the container is running
+func (s *ErrorPagesService) Start(ctx context.Context) error {
+	if err := s.writeDefaults(); err != nil {
+		return err
+	}
+
+	containers, err := s.containerService.ListContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	for _, container := range containers {
+		if container.Name == ErrorPagesContainerName {
+			if container.State == "running" {
+				return nil
+			}
+			_ = s.containerService.DeleteContainer(ctx, container.ID)
+			break
+		}
+	}
+
+	if err := s.containerService.PullImage(ctx, ErrorPagesImage); err != nil {
+		return fmt.Errorf("failed to pull error pages image: %w", err)
+	}
+
+	containerConfig := manager.ContainerConfig{
+		Name:          ErrorPagesContainerName,
+		Image:         ErrorPagesImage,
+		RestartPolicy: "unless-stopped",
+		NetworkMode:   s.networkMode,
+		Volumes: map[string]string{
+			filepath.Join(s.dataDir, "html"):  "/usr/share/nginx/html:ro",
+			filepath.Join(s.dataDir, "nginx"): "/etc/nginx/conf.d:ro",
+		},
+		Labels: map[string]string{
+			"traefik.enable": "true",
+			"traefik.http.services." + ErrorPagesContainerName + ".loadbalancer.server.port": "80",
+		},
+	}
+
+	containerID, err := s.containerService.CreateContainer(ctx, containerConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create error pages container: %w", err)
+	}
+
+	if err := s.containerService.StartContainer(ctx, containerID); err != nil {
+		return fmt.Errorf("failed to start error pages container: %w", err)
+	}
+
+	return nil
+}
+
+// SavePage stores an uploaded page for a project
+func (s *ErrorPagesService) SavePage(projectID uuid.UUID, name string, html []byte) error {
+	if err := proxy.ValidateErrorPageName(name); err != nil {
+		return err
+	}
+	if len(html) > proxy.MaxErrorPageSize {
+		return fmt.Errorf("error page exceeds %d bytes", proxy.MaxErrorPageSize)
+	}
+
+	dir := s.projectDir(projectID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create error pages directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name+".html"), html, 0o644); err != nil {
+		return fmt.Errorf("failed to write error page: %w", err)
+	}
+
+	return nil
+}
+
+// GetPage returns an uploaded page of a project
+func (s *ErrorPagesService) GetPage(projectID uuid.UUID, name string) ([]byte, error) {
+	if err := proxy.ValidateErrorPageName(name); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(s.projectDir(projectID), name+".html"))
+}
+
+// DeletePage removes an uploaded page, falling back to the default one
+func (s *ErrorPagesService) DeletePage(projectID uuid.UUID, name string) error {
+	if err := proxy.ValidateErrorPageName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.projectDir(projectID), name+".html")); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListPages returns the names of the pages uploaded for a project
+func (s *ErrorPagesService) ListPages(projectID uuid.UUID) ([]string, error) {
+	entries, err := os.ReadDir(s.projectDir(projectID))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list error pages: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".html")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (s *ErrorPagesService) projectDir(projectID uuid.UUID) string {
+	return filepath.Join(s.dataDir, "html", "projects", projectID.String())
+}
+
+func (s *ErrorPagesService) writeDefaults() error {
+	files := map[string]string{
+		filepath.Join(s.dataDir, "nginx", "default.conf"):               errorPagesNginxConfig,
+		filepath.Join(s.dataDir, "html", "default", "error.html"):       defaultErrorPage,
+		filepath.Join(s.dataDir, "html", "default", "maintenance.html"): defaultMaintenancePage,
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create error pages directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	return nil
+}
+
+// ErrorPagesMiddlewareLabels returns the docker labels of an errors middleware
+// serving the project's pages for the given status ranges
+func ErrorPagesMiddlewareLabels(name string, projectID uuid.UUID, statuses string) map[string]string {
+	prefix := "traefik.http.middlewares." + name + ".errors."
+	return map[string]string{
+		prefix + "status":  statuses,
+		prefix + "service": ErrorPagesServiceName,
+		prefix + "query":   fmt.Sprintf("/errors/%s/{status}", projectID),
+	}
+}
+
+// WriteMaintenanceConfig routes the domains of applications in maintenance mode
+// to the maintenance page of their project. The routers take precedence over
+// the ones declared by application containers.
+func (ts *TraefikService) WriteMaintenanceConfig(routes []proxy.MaintenanceRoute) error {
+	configPath := filepath.Join(ts.configDir, "dynamic", maintenanceConfigFile)
+
+	if len(routes) == 0 {
+		if err := os.Remove(configPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove maintenance config: %w", err)
+		}
+		return nil
+	}
+
+	httpConfig := &HTTPConfig{
+		Routers:     make(map[string]Router),
+		Services:    make(map[string]Service),
+		Middlewares: make(map[string]Middleware),
+	}
+
+	for _, route := range routes {
+		name := "maintenance-" + route.ApplicationID
+
+		hosts := make([]string, len(route.Domains))
+		for i, domain := range route.Domains {
+			hosts[i] = fmt.Sprintf("Host(`%s`)", domain)
+		}
+
+		httpConfig.Middlewares[name] = Middleware{
+			ReplacePathRegex: &ReplacePathRegexMiddleware{
+				Regex:       "^.*$",
+				Replacement: "/maintenance/" + route.ProjectID.String(),
+			},
+		}
+		httpConfig.Routers[name] = Router{
+			Rule:        strings.Join(hosts, " || "),
+			Service:     ErrorPagesServiceName,
+			Middlewares: []string{name},
+			Priority:    maintenancePriority,
+		}
+	}
+
+	configBytes, err := json.MarshalIndent(map[string]any{"http": httpConfig}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance config: %w", err)
+	}
+
+	if err := ts.ensureConfigDir(); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(configPath, configBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write maintenance config: %w", err)
+	}
+
+	return nil
+}
+
+// errorPagesNginxConfig serves /errors/<project>/<status> for the Traefik errors
+// middleware and /maintenance/<project> as a 503 for the maintenance routers.
+// Project pages take precedence over the defaults; "{{status}}" in a page is
+// replaced by the status code.
+const errorPagesNginxConfig = `server {
+    listen 80 default_server;
+    root /usr/share/nginx/html;
+    default_type text/html;
+    sub_filter_once off;
+    sub_filter_types text/html;
+
+    location ~ "^/errors/(?<project>[0-9a-f-]{36})/(?<code>[45][0-9]{2})$" {
+        sub_filter '{{status}}' $code;
+        try_files /projects/$project/$code.html /projects/$project/error.html /default/$code.html /default/error.html =404;
+    }
+
+    location ~ "^/maintenance/(?<project>[0-9a-f-]{36})$" {
+        error_page 503 /pages/maintenance/$project;
+        return 503;
+    }
+
+    location ~ "^/pages/maintenance/(?<project>[0-9a-f-]{36})$" {
+        internal;
+        add_header Retry-After 300 always;
+        add_header Cache-Control no-store always;
+        sub_filter '{{status}}' 503;
+        try_files /projects/$project/maintenance.html /default/maintenance.html =503;
+    }
+
+    location / {
+        return 404;
+    }
+}
+`
+
+const defaultErrorPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Error {{status}}</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f7f7f8; color: #1f2328; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { text-align: center; padding: 2rem; }
+h1 { font-size: 4rem; margin: 0; }
+p { color: #59636e; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{status}}</h1>
+<p>Something went wrong while serving this page. Please try again later.</p>
+</main>
+</body>
+</html>
+`
+
+const defaultMaintenancePage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Under maintenance</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f7f7f8; color: #1f2328; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; }
+main { text-align: center; padding: 2rem; }
+h1 { font-size: 2rem; margin: 0 0 1rem; }
+p { color: #59636e; }
+</style>
+</head>
+<body>
+<main>
+<h1>We'll be back soon</h1>
+<p>This site is undergoing maintenance. Please check back in a few minutes.</p>
+</main>
+</body>
+</html>
+`
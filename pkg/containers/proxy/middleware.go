@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+// BuildMiddleware translates a domain middleware into its Traefik dynamic config form
+func BuildMiddleware(mw proxy.MiddlewareConfig) (Middleware, error) {
+	switch mw.Type() {
+	case proxy.MiddlewareTypeAuth:
+		return Middleware{BasicAuth: &BasicAuthMiddleware{Users: mw.GetStringSlice("users")}}, nil
+	case proxy.MiddlewareTypeIPAllowList:
+		return Middleware{IPAllowList: &IPAllowListMiddleware{SourceRange: mw.GetStringSlice("source_range")}}, nil
+	case proxy.MiddlewareTypeRateLimit:
+		return Middleware{RateLimit: &RateLimitMiddleware{
+			Average: mw.GetInt("average"),
+			Burst:   mw.GetInt("burst"),
+			Period:  mw.GetString("period"),
+		}}, nil
+	case proxy.MiddlewareTypeHeaders:
+		return Middleware{Headers: &HeadersMiddleware{
+			CustomRequestHeaders:  mw.GetStringMap("custom_request_headers"),
+			CustomResponseHeaders: mw.GetStringMap("custom_response_headers"),
+			STSSeconds:            mw.GetInt("sts_seconds"),
+			STSIncludeSubdomains:  mw.GetBool("sts_include_subdomains"),
+			STSPreload:            mw.GetBool("sts_preload"),
+			ForceSTSHeader:        mw.GetBool("force_sts_header"),
+			ContentSecurityPolicy: mw.GetString("content_security_policy"),
+			FrameDeny:             mw.GetBool("frame_deny"),
+			ContentTypeNosniff:    mw.GetBool("content_type_nosniff"),
+			BrowserXSSFilter:      mw.GetBool("browser_xss_filter"),
+			ReferrerPolicy:        mw.GetString("referrer_policy"),
+		}}, nil
+	case proxy.MiddlewareTypeCORS:
+		// Traefik handles CORS through the headers middleware
+		return Middleware{Headers: &HeadersMiddleware{
+			AccessControlAllowOriginList: mw.GetStringSlice("allow_origins"),
+			AccessControlAllowMethods:    mw.GetStringSlice("allow_methods"),
+			AccessControlAllowHeaders:    mw.GetStringSlice("allow_headers"),
+		}}, nil
+	case proxy.MiddlewareTypeReplacePath:
+		return Middleware{ReplacePathRegex: &ReplacePathRegexMiddleware{
+			Regex:       mw.GetString("regex"),
+			Replacement: mw.GetString("replacement"),
+		}}, nil
+	case proxy.MiddlewareTypeRedirect:
+		return Middleware{RedirectRegex: &RedirectRegexMiddleware{
+			Regex:       mw.GetString("regex"),
+			Replacement: mw.GetString("replacement"),
+			Permanent:   mw.GetBool("permanent"),
+		}}, nil
+	case proxy.MiddlewareTypeStripPrefix:
+		return Middleware{StripPrefix: &StripPrefixMiddleware{Prefixes: mw.GetStringSlice("prefixes")}}, nil
+	case proxy.MiddlewareTypeCompression:
+		return Middleware{Compress: &CompressMiddleware{}}, nil
+	default:
+		return Middleware{}, fmt.Errorf("unsupported middleware type: %s", mw.Type())
+	}
+}
+
+// MiddlewareLabels renders a middleware as Docker provider labels declared under name
+func MiddlewareLabels(name string, mw proxy.MiddlewareConfig) (map[string]string, error) {
+	middleware, err := BuildMiddleware(mw)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(middleware)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal middleware: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var tree map[string]any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to decode middleware: %w", err)
+	}
+
+	labels := make(map[string]string)
+	flattenLabels(labels, "traefik.http.middlewares."+name, tree)
+	return labels, nil
+}
+
+// flattenLabels walks a JSON tree and emits one label per leaf. Lists become
+// comma separated values and empty objects (e.g. compress) become "true".
+func flattenLabels(labels map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			labels[prefix] = "true"
+			return
+		}
+		for key, child := range v {
+			flattenLabels(labels, prefix+"."+key, child)
+		}
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		labels[prefix] = strings.Join(items, ",")
+	default:
+		labels[prefix] = fmt.Sprint(v)
+	}
+}
+
+// MiddlewareName namespaces a middleware under its router so the same name can be
+// reused across applications
+func MiddlewareName(routerName, middlewareName string) string {
+	return routerName + "-" + middlewareName
+}
@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/proxy"
+)
+
+const redirectsConfigFile = "redirects.json"
+
+// RedirectChainName is the file provider middleware chaining the redirect
+// rules of an application; routers reference it as RedirectChainName(id)+"@file"
+func RedirectChainName(applicationID string) string {
+	return "redirects-" + applicationID
+}
+
+// WriteRedirectConfig compiles redirect rules into redirectRegex and
+// replacePathRegex middlewares, chained per application in rule order. rules
+// must be grouped by application and sorted by priority.
+func (ts *TraefikService) WriteRedirectConfig(rules []*proxy.RedirectRule) error {
+	configPath := filepath.Join(ts.configDir, "dynamic", redirectsConfigFile)
+
+	if len(rules) == 0 {
+		if err := os.Remove(configPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove redirect config: %w", err)
+		}
+		return nil
+	}
+
+	middlewares := make(map[string]Middleware)
+	chains := make(map[string]*ChainMiddleware)
+
+	for _, rule := range rules {
+		if !rule.Enabled() {
+			continue
+		}
+
+		chainName := RedirectChainName(rule.ApplicationID())
+		chain, ok := chains[chainName]
+		if !ok {
+			chain = &ChainMiddleware{}
+			chains[chainName] = chain
+		}
+
+		name := fmt.Sprintf("%s-%d", chainName, len(chain.Middlewares))
+		if rule.Action() == proxy.RedirectActionRewrite {
+			middlewares[name] = Middleware{
+				ReplacePathRegex: &ReplacePathRegexMiddleware{
+					Regex:       rule.Regex(),
+					Replacement: rule.Replacement(),
+				},
+			}
+		} else {
+			middlewares[name] = Middleware{
+				RedirectRegex: &RedirectRegexMiddleware{
+					Regex:       rule.Regex(),
+					Replacement: rule.Replacement(),
+					Permanent:   rule.Permanent(),
+				},
+			}
+		}
+		chain.Middlewares = append(chain.Middlewares, name)
+	}
+
+	for name, chain := range chains {
+		middlewares[name] = Middleware{Chain: chain}
+	}
+
+	dynamicConfig := map[string]any{
+		"http": map[string]any{
+			"middlewares": middlewares,
+		},
+	}
+
+	configBytes, err := json.MarshalIndent(dynamicConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal redirect config: %w", err)
+	}
+
+	if err := ts.ensureConfigDir(); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(configPath, configBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write redirect config: %w", err)
+	}
+
+	return nil
+}
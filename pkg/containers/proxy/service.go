@@ -103,13 +103,17 @@ type StickyCookie struct {
 }
 
 type Middleware struct {
-	StripPrefix *StripPrefixMiddleware `json:"stripPrefix,omitempty"`
-	AddPrefix   *AddPrefixMiddleware   `json:"addPrefix,omitempty"`
-	Headers     *HeadersMiddleware     `json:"headers,omitempty"`
-	RateLimit   *RateLimitMiddleware   `json:"rateLimit,omitempty"`
-	BasicAuth   *BasicAuthMiddleware   `json:"basicAuth,omitempty"`
-	Compress    *CompressMiddleware    `json:"compress,omitempty"`
-	CORS        *CORSMiddleware        `json:"cors,omitempty"`
+	StripPrefix      *StripPrefixMiddleware      `json:"stripPrefix,omitempty"`
+	AddPrefix        *AddPrefixMiddleware        `json:"addPrefix,omitempty"`
+	Headers          *HeadersMiddleware          `json:"headers,omitempty"`
+	RateLimit        *RateLimitMiddleware        `json:"rateLimit,omitempty"`
+	BasicAuth        *BasicAuthMiddleware        `json:"basicAuth,omitempty"`
+	Compress         *CompressMiddleware         `json:"compress,omitempty"`
+	CORS             *CORSMiddleware             `json:"cors,omitempty"`
+	IPAllowList      *IPAllowListMiddleware      `json:"ipAllowList,omitempty"`
+	ReplacePathRegex *ReplacePathRegexMiddleware `json:"replacePathRegex,omitempty"`
+	RedirectRegex    *RedirectRegexMiddleware    `json:"redirectRegex,omitempty"`
+	Chain            *ChainMiddleware            `json:"chain,omitempty"`
 }
 
 type StripPrefixMiddleware struct {
@@ -123,11 +127,25 @@ type AddPrefixMiddleware struct {
 type HeadersMiddleware struct {
 	CustomRequestHeaders  map[string]string `json:"customRequestHeaders,omitempty"`
 	CustomResponseHeaders map[string]string `json:"customResponseHeaders,omitempty"`
+	STSSeconds            int               `json:"stsSeconds,omitempty"`
+	STSIncludeSubdomains  bool              `json:"stsIncludeSubdomains,omitempty"`
+	STSPreload            bool              `json:"stsPreload,omitempty"`
+	ForceSTSHeader        bool              `json:"forceSTSHeader,omitempty"`
+	ContentSecurityPolicy string            `json:"contentSecurityPolicy,omitempty"`
+	FrameDeny             bool              `json:"frameDeny,omitempty"`
+	ContentTypeNosniff    bool              `json:"contentTypeNosniff,omitempty"`
+	BrowserXSSFilter      bool              `json:"browserXssFilter,omitempty"`
+	ReferrerPolicy        string            `json:"referrerPolicy,omitempty"`
+
+	AccessControlAllowOriginList []string `json:"accessControlAllowOriginList,omitempty"`
+	AccessControlAllowMethods    []string `json:"accessControlAllowMethods,omitempty"`
+	AccessControlAllowHeaders    []string `json:"accessControlAllowHeaders,omitempty"`
 }
 
 type RateLimitMiddleware struct {
-	Average int `json:"average"`
-	Burst   int `json:"burst,omitempty"`
+	Average int    `json:"average"`
+	Burst   int    `json:"burst,omitempty"`
+	Period  string `json:"period,omitempty"`
 }
 
 type BasicAuthMiddleware struct {
@@ -136,6 +154,25 @@ type BasicAuthMiddleware struct {
 
 type CompressMiddleware struct{}
 
+type IPAllowListMiddleware struct {
+	SourceRange []string `json:"sourceRange"`
+}
+
+type ReplacePathRegexMiddleware struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement"`
+}
+
+type RedirectRegexMiddleware struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement"`
+	Permanent   bool   `json:"permanent,omitempty"`
+}
+
+type ChainMiddleware struct {
+	Middlewares []string `json:"middlewares"`
+}
+
 type CORSMiddleware struct {
 	AccessControlAllowOriginList []string `json:"accessControlAllowOriginList,omitempty"`
 	AccessControlAllowMethods    []string `json:"accessControlAllowMethods,omitempty"`
@@ -292,8 +329,9 @@ func (ts *TraefikService) UpdateDynamicConfig(ctx context.Context, configs []*pr
 	}
 
 	httpConfig := &HTTPConfig{
-		Routers:  make(map[string]Router),
-		Services: make(map[string]Service),
+		Routers:     make(map[string]Router),
+		Services:    make(map[string]Service),
+		Middlewares: make(map[string]Middleware),
 	}
 
 	for _, config := range configs {
@@ -317,6 +355,17 @@ func (ts *TraefikService) UpdateDynamicConfig(ctx context.Context, configs []*pr
 			router.TLS = &RouterTLS{}
 		}
 
+		for _, mw := range config.Middlewares() {
+			middleware, err := BuildMiddleware(mw)
+			if err != nil {
+				return fmt.Errorf("invalid middleware %s for router %s: %w", mw.Name(), routerName, err)
+			}
+
+			name := MiddlewareName(routerName, mw.Name())
+			httpConfig.Middlewares[name] = middleware
+			router.Middlewares = append(router.Middlewares, name)
+		}
+
 		httpConfig.Routers[routerName] = router
 
 		service := Service{
@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mikrocloud/mikrocloud/internal/domain/uptime"
+)
+
+const (
+	statusPagesConfigFile  = "status-pages.json"
+	statusPagesServiceName = "mikrocloud-status-pages"
+)
+
+// StatusPagePath is the control plane route rendering a status page
+func StatusPagePath(slug string) string {
+	return "/api/status-pages/" + slug + "/page"
+}
+
+// StatusPageSummaryPath is the control plane route returning the JSON summary
+// of a status page
+func StatusPageSummaryPath(slug string) string {
+	return "/api/status-pages/" + slug
+}
+
+// WriteStatusPageConfig serves status pages on their custom domain, over HTTP
+// and HTTPS, proxied to the control plane at upstream. Only "/" and
+// "/summary.json" are routed so that nothing else of the API is reachable on
+// those domains.
+func (ts *TraefikService) WriteStatusPageConfig(upstream string, routes []uptime.StatusPageRoute) error {
+	configPath := filepath.Join(ts.configDir, "dynamic", statusPagesConfigFile)
+
+	if len(routes) == 0 {
+		if err := os.Remove(configPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove status page config: %w", err)
+		}
+		return nil
+	}
+
+	httpConfig := &HTTPConfig{
+		Routers:     make(map[string]Router),
+		Services:    make(map[string]Service),
+		Middlewares: make(map[string]Middleware),
+	}
+
+	httpConfig.Services[statusPagesServiceName] = Service{
+		LoadBalancer: LoadBalancer{Servers: []Server{{URL: upstream}}},
+	}
+
+	for _, route := range routes {
+		name := "status-page-" + route.Slug
+		summaryName := name + "-summary"
+
+		httpConfig.Middlewares[name] = Middleware{
+			ReplacePathRegex: &ReplacePathRegexMiddleware{
+				Regex:       "^.*$",
+				Replacement: StatusPagePath(route.Slug),
+			},
+		}
+		httpConfig.Middlewares[summaryName] = Middleware{
+			ReplacePathRegex: &ReplacePathRegexMiddleware{
+				Regex:       "^.*$",
+				Replacement: StatusPageSummaryPath(route.Slug),
+			},
+		}
+
+		for router, path := range map[string]string{name: "/", summaryName: "/summary.json"} {
+			rule := fmt.Sprintf("Host(`%s`) && Path(`%s`)", route.Domain, path)
+
+			// A router serves either plain HTTP or TLS, the secure one is set
+			// up like the routers of HTTPS applications
+			httpConfig.Routers[router] = Router{
+				Rule:        rule,
+				Service:     statusPagesServiceName,
+				Middlewares: []string{router},
+			}
+			httpConfig.Routers[router+"-secure"] = Router{
+				Rule:        rule,
+				Service:     statusPagesServiceName,
+				Middlewares: []string{router},
+				TLS:         &RouterTLS{},
+			}
+		}
+	}
+
+	configBytes, err := json.MarshalIndent(map[string]any{"http": httpConfig}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status page config: %w", err)
+	}
+
+	if err := ts.ensureConfigDir(); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(configPath, configBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write status page config: %w", err)
+	}
+
+	return nil
+}
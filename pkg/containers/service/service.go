@@ -61,6 +61,11 @@ func (cs *ContainerService) DeleteContainer(ctx context.Context, containerID str
 	return cs.containerManager.Delete(ctx, containerID)
 }
 
+// WaitContainer blocks until the container exits and returns its exit code
+func (cs *ContainerService) WaitContainer(ctx context.Context, containerID string) (int64, error) {
+	return cs.containerManager.Wait(ctx, containerID)
+}
+
 func (cs *ContainerService) CreateContainer(ctx context.Context, config manager.ContainerConfig) (string, error) {
 	return cs.containerManager.Create(ctx, config)
 }
@@ -73,6 +78,11 @@ func (cs *ContainerService) InspectContainer(ctx context.Context, containerID st
 	return cs.containerManager.Inspect(ctx, containerID)
 }
 
+// ContainerStats samples the resource usage of a container
+func (cs *ContainerService) ContainerStats(ctx context.Context, containerID string) (*manager.ContainerStats, error) {
+	return cs.containerManager.Stats(ctx, containerID)
+}
+
 // Logging
 func (cs *ContainerService) StreamContainerLogs(ctx context.Context, containerID string, follow bool) (io.ReadCloser, error) {
 	logStream, err := cs.containerManager.StreamLogs(ctx, containerID, follow)
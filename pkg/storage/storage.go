@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist on the backend
+var ErrNotFound = errors.New("object not found")
+
+// Backend stores backup artifacts under slash separated keys
+type Backend interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type BackendType string
+
+const (
+	BackendTypeLocal BackendType = "local"
+	BackendTypeS3    BackendType = "s3"
+)
+
+// Config selects and configures a backend. It is stored as JSON alongside the
+// resources that use it.
+type Config struct {
+	Type BackendType `json:"type"`
+	// Path is a sub directory of the local backup directory, or a key prefix on S3
+	Path string    `json:"path,omitempty"`
+	S3   *S3Config `json:"s3,omitempty"`
+}
+
+// S3Config configures an S3-compatible endpoint (AWS S3, MinIO, ...)
+type S3Config struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region,omitempty"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	// PathStyle addresses buckets as endpoint/bucket instead of bucket.endpoint,
+	// which is what MinIO and most self-hosted stores expect
+	PathStyle bool `json:"path_style"`
+	Insecure  bool `json:"insecure,omitempty"` // plain http
+}
+
+func (c Config) Validate() error {
+	if err := validatePrefix(c.Path); err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case BackendTypeLocal:
+		return nil
+	case BackendTypeS3:
+		if c.S3 == nil {
+			return fmt.Errorf("s3 storage requires s3 settings")
+		}
+		if c.S3.Endpoint == "" || c.S3.Bucket == "" {
+			return fmt.Errorf("s3 storage requires an endpoint and a bucket")
+		}
+		if c.S3.AccessKey == "" || c.S3.SecretKey == "" {
+			return fmt.Errorf("s3 storage requires an access key and a secret key")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported storage type: %s", c.Type)
+	}
+}
+
+// Redacted returns a copy of the config without credentials, for API responses
+func (c Config) Redacted() Config {
+	if c.S3 != nil {
+		s3 := *c.S3
+		s3.SecretKey = ""
+		c.S3 = &s3
+	}
+	return c
+}
+
+// New creates the backend described by cfg. localRoot is the directory local
+// backends are rooted in.
+func New(cfg Config, localRoot string) (Backend, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Type {
+	case BackendTypeS3:
+		return NewS3Backend(*cfg.S3, cfg.Path), nil
+	default:
+		return NewLocalBackend(path.Join(localRoot, cfg.Path)), nil
+	}
+}
+
+func validatePrefix(prefix string) error {
+	for _, part := range strings.Split(prefix, "/") {
+		if part == ".." {
+			return fmt.Errorf("storage path cannot contain '..'")
+		}
+	}
+	return nil
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid object key: %q", key)
+	}
+	return validatePrefix(key)
+}